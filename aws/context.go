@@ -17,6 +17,10 @@ import (
 	"github.com/aws/aws-sdk-go/service/ram/ramiface"
 	"github.com/aws/aws-sdk-go/service/route53resolver"
 	"github.com/aws/aws-sdk-go/service/route53resolver/route53resolveriface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
 
 	"github.com/benbjohnson/clock"
 )
@@ -75,6 +79,8 @@ type AWSAccountAccess struct {
 	R53Rsvc           route53resolveriface.Route53ResolverAPI
 	CloudWatchLogssvc cloudwatchlogsiface.CloudWatchLogsAPI
 	NFsvc             networkfirewalliface.NetworkFirewallAPI
+	S3svc             s3iface.S3API
+	ServiceQuotassvc  servicequotasiface.ServiceQuotasAPI
 
 	mu sync.Mutex
 }
@@ -137,3 +143,21 @@ func (p *AWSAccountAccess) RAM() ramiface.RAMAPI {
 	}
 	return p.RAMsvc
 }
+
+func (p *AWSAccountAccess) S3() s3iface.S3API {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.S3svc == nil {
+		p.S3svc = s3.New(p.Session)
+	}
+	return p.S3svc
+}
+
+func (p *AWSAccountAccess) ServiceQuotas() servicequotasiface.ServiceQuotasAPI {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ServiceQuotassvc == nil {
+		p.ServiceQuotassvc = servicequotas.New(p.Session)
+	}
+	return p.ServiceQuotassvc
+}
@@ -141,7 +141,22 @@ func (ctx *Context) SubnetExists(id string) (bool, error) {
 }
 
 func (ctx *Context) CreateSubnets(info *ipcontrol.VPCInfo) error {
-	for _, subnet := range info.NewSubnets {
+	var ipv6CIDRs []string
+	if info.IPv6CIDRBlock != "" {
+		inUseBySubnetID, err := ctx.getSubnetIPv6CIDRs(info.ResourceID)
+		if err != nil {
+			return err
+		}
+		inUse := []string{}
+		for _, cidr := range inUseBySubnetID {
+			inUse = append(inUse, cidr)
+		}
+		ipv6CIDRs, err = NextIPv6SubnetCIDRs(info.IPv6CIDRBlock, inUse, len(info.NewSubnets))
+		if err != nil {
+			return err
+		}
+	}
+	for idx, subnet := range info.NewSubnets {
 		use := strings.ToLower(string(subnet.Type))
 		tags := []*ec2.Tag{
 			{Key: aws.String("Name"), Value: aws.String(subnet.Name)},
@@ -157,12 +172,17 @@ func (ctx *Context) CreateSubnets(info *ipcontrol.VPCInfo) error {
 			ResourceType: aws.String("subnet"),
 			Tags:         tags,
 		}
-		createSubnetOut, err := ctx.EC2().CreateSubnet(&ec2.CreateSubnetInput{
+		input := &ec2.CreateSubnetInput{
 			AvailabilityZone:  &subnet.AvailabilityZone,
 			CidrBlock:         &subnet.CIDR,
 			VpcId:             &info.ResourceID,
 			TagSpecifications: []*ec2.TagSpecification{tagSpecification},
-		})
+		}
+		if ipv6CIDRs != nil {
+			subnet.IPv6CIDR = ipv6CIDRs[idx]
+			input.Ipv6CidrBlock = &subnet.IPv6CIDR
+		}
+		createSubnetOut, err := ctx.EC2().CreateSubnet(input)
 		if err != nil {
 			return fmt.Errorf("Error creating subnet: %s", err)
 		}
@@ -385,6 +405,12 @@ func (ctx *Context) CreateVPC(info *ipcontrol.VPCInfo) error {
 		}
 		ctx.Logger.Log("Associated additional CIDR %s", info.NewCIDRs[idx])
 	}
+	if info.IPv6 != nil {
+		info.IPv6CIDRBlock, _, err = ctx.associateIPv6CIDRBlock(info.ResourceID, info.IPv6)
+		if err != nil {
+			return err
+		}
+	}
 	tags := []*ec2.Tag{
 		{Key: aws.String("Name"), Value: aws.String(info.Name)},
 		{Key: aws.String("stack"), Value: aws.String(info.Stack)},
@@ -490,6 +516,35 @@ func matchSubnetType(layer string) (database.SubnetType, error) {
 	return t, nil
 }
 
+// ClassifySubnet returns the V1 subnet type of a subnet and whether it already
+// has a use tag saying so. Subnets without a use tag are classified from their
+// Layer/vpc-conf-layer tag as for Legacy VPCs. Transitive subnets have no V1
+// equivalent.
+func ClassifySubnet(subnet *ec2.Subnet) (database.SubnetType, bool, error) {
+	split, err := SplitSubnets([]*ec2.Subnet{subnet})
+	if err == nil {
+		for t := range split {
+			return t, true, nil
+		}
+	}
+	for _, tag := range subnet.Tags {
+		if aws.StringValue(tag.Key) == "use" {
+			return "", true, err
+		}
+	}
+	split, err = splitLegacySubnets([]*ec2.Subnet{subnet})
+	if err != nil {
+		return "", false, err
+	}
+	for t := range split {
+		if t == database.SubnetTypeTransitive {
+			return "", false, fmt.Errorf("Transitive subnet %s has no V1 equivalent", aws.StringValue(subnet.SubnetId))
+		}
+		return t, false, nil
+	}
+	return "", false, fmt.Errorf("Unable to classify subnet %s", aws.StringValue(subnet.SubnetId))
+}
+
 func (ctx *Context) GetLegacySubnets() (map[database.SubnetType][]*ec2.Subnet, error) {
 	out, err := ctx.EC2().DescribeSubnets(&ec2.DescribeSubnetsInput{
 		Filters: []*ec2.Filter{
@@ -760,7 +815,7 @@ func (ctx *Context) LocalRouteWithDestinationExistsOnRouteTable(destination, rtI
 
 	found := false
 	for _, route := range out.RouteTables[0].Routes {
-		for _, dest := range []*string{route.DestinationCidrBlock, route.DestinationPrefixListId} {
+		for _, dest := range []*string{route.DestinationCidrBlock, route.DestinationIpv6CidrBlock, route.DestinationPrefixListId} {
 			if dest != nil && aws.StringValue(dest) == destination && aws.StringValue(route.GatewayId) == "local" {
 				found = true
 			}
@@ -1313,12 +1368,18 @@ func (ctx *Context) WaitForTransitGatewayVpcAttachmentStatus(id string, waitingF
 	}
 }
 
-func (ctx *Context) CreateTransitGatewayVPCAttachment(name, transitGatewayID string, subnetIDs []string) (string, error) {
-	createTGWOut, err := ctx.EC2().CreateTransitGatewayVpcAttachment(&ec2.CreateTransitGatewayVpcAttachmentInput{
+func (ctx *Context) CreateTransitGatewayVPCAttachment(name, transitGatewayID string, subnetIDs []string, ipv6Support bool) (string, error) {
+	input := &ec2.CreateTransitGatewayVpcAttachmentInput{
 		SubnetIds:        aws.StringSlice(subnetIDs),
 		TransitGatewayId: &transitGatewayID,
 		VpcId:            &ctx.VPCID,
-	})
+	}
+	if ipv6Support {
+		input.Options = &ec2.CreateTransitGatewayVpcAttachmentRequestOptions{
+			Ipv6Support: aws.String(ec2.Ipv6SupportValueEnable),
+		}
+	}
+	createTGWOut, err := ctx.EC2().CreateTransitGatewayVpcAttachment(input)
 	if err != nil {
 		return "", err
 	}
@@ -1748,16 +1809,7 @@ func (ctx *Context) EnsurePrincipalOnShare(sourceRAM ramiface.RAMAPI, accountID,
 
 // returns the ARN or nil if the policy doesn't exist
 func (ctx *Context) getDefaultFirewallPolicyARN() (*string, error) {
-	out, err := ctx.NetworkFirewall().ListFirewallPolicies(&networkfirewall.ListFirewallPoliciesInput{})
-	if err != nil {
-		return nil, fmt.Errorf("Error listing firewall policies: %s", err)
-	}
-	for _, fp := range out.FirewallPolicies {
-		if aws.StringValue(fp.Name) == ctx.defaultFirewallPolicyName() {
-			return fp.Arn, nil
-		}
-	}
-	return nil, nil
+	return ctx.getFirewallPolicyARN(ctx.defaultFirewallPolicyName())
 }
 
 // ARNs for the rule groups managed by
@@ -1873,13 +1925,15 @@ func (ctx *Context) EnsureDefaultFirewallPolicyExists(region database.Region) er
 	return nil
 }
 
-func (ctx *Context) CreateFirewall(subnetIDs []string) (*networkfirewall.Firewall, error) {
-	firewallPolicyARN, err := ctx.getDefaultFirewallPolicyARN()
+// CreateFirewall creates the VPC's firewall using the policy for the given
+// template, or the default policy if policyTemplateID is 0.
+func (ctx *Context) CreateFirewall(subnetIDs []string, policyTemplateID uint64) (*networkfirewall.Firewall, error) {
+	firewallPolicyARN, err := ctx.FirewallPolicyARN(policyTemplateID)
 	if err != nil {
-		return nil, fmt.Errorf("Error getting default firewall policy ARN: %s", err)
+		return nil, fmt.Errorf("Error getting firewall policy ARN: %s", err)
 	}
 	if firewallPolicyARN == nil {
-		return nil, fmt.Errorf("Can't create the firewall because the default firewall policy doesn't exist")
+		return nil, fmt.Errorf("Can't create the firewall because its firewall policy doesn't exist")
 	}
 
 	out, err := ctx.NetworkFirewall().CreateFirewall(&networkfirewall.CreateFirewallInput{
@@ -2007,6 +2061,26 @@ func (ctx *Context) GetPublicSubnetIDtoCIDR(azs map[string]*database.Availabilit
 	return publicSubnetIDtoCIDR, nil
 }
 
+// GetSubnetCIDRs returns the IPv4 CIDRs of all subnets in the VPC, by subnet ID.
+func (ctx *Context) GetSubnetCIDRs() (map[string]string, error) {
+	out, err := ctx.EC2().DescribeSubnets(&ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []*string{aws.String(ctx.VPCID)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing subnets: %s", err)
+	}
+	cidrs := map[string]string{}
+	for _, subnet := range out.Subnets {
+		cidrs[aws.StringValue(subnet.SubnetId)] = aws.StringValue(subnet.CidrBlock)
+	}
+	return cidrs, nil
+}
+
 func (ctx *Context) WaitForFirewallSubnetAssociations(addIDs []string) error {
 	w := &waiter.Waiter{
 		SleepDuration:  time.Second * 5,
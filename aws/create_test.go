@@ -756,6 +756,43 @@ func TestSplitSubnets(t *testing.T) {
 	}
 }
 
+func TestClassifySubnet(t *testing.T) {
+	testCases := []struct {
+		tags       map[string]string
+		subnetType database.SubnetType
+		hasUseTag  bool
+		error      bool
+	}{
+		{tags: map[string]string{"use": "private", "Layer": "app"}, subnetType: database.SubnetTypePrivate, hasUseTag: true},
+		{tags: map[string]string{"Layer": "dmz"}, subnetType: database.SubnetTypePublic},
+		{tags: map[string]string{"Layer": "web", "vpc-conf-layer": "data"}, subnetType: database.SubnetTypeData},
+		{tags: map[string]string{"use": "transitive"}, hasUseTag: true, error: true},
+		{tags: map[string]string{"Layer": "transitive"}, error: true},
+		{tags: map[string]string{"Layer": "bogus"}, error: true},
+		{tags: map[string]string{}, error: true},
+	}
+	for idx, tc := range testCases {
+		subnet := &ec2.Subnet{SubnetId: aws.String("subnet-1")}
+		for k, v := range tc.tags {
+			subnet.Tags = append(subnet.Tags, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		subnetType, hasUseTag, err := awsp.ClassifySubnet(subnet)
+		if tc.error {
+			if err == nil {
+				t.Errorf("Test case %d: expected an error but got %s", idx, subnetType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test case %d: unexpected error: %s", idx, err)
+			continue
+		}
+		if subnetType != tc.subnetType || hasUseTag != tc.hasUseTag {
+			t.Errorf("Test case %d: expected %s/%v but got %s/%v", idx, tc.subnetType, tc.hasUseTag, subnetType, hasUseTag)
+		}
+	}
+}
+
 func TestEnsureRouteTableAssociationExists(t *testing.T) {
 	type result struct {
 		AssocID     string
@@ -295,7 +295,7 @@ func (ctx *Context) DeleteAllResolverRuleQueryLogs() error {
 	return nil
 }
 
-// delete firewall and default policy
+// delete firewall and its policies
 func (ctx *Context) DeleteFirewallResources() error {
 	w := &waiter.Waiter{
 		SleepDuration:  time.Second * 5,
@@ -343,12 +343,24 @@ func (ctx *Context) DeleteFirewallResources() error {
 		ctx.Log("Deleting network firewall %s", ctx.FirewallName())
 	}
 
-	// default firewall policy
-	arn, err := ctx.getDefaultFirewallPolicyARN()
+	// default and template firewall policies
+	policies := []*networkfirewall.FirewallPolicyMetadata{}
+	err = ctx.NetworkFirewall().ListFirewallPoliciesPages(&networkfirewall.ListFirewallPoliciesInput{},
+		func(page *networkfirewall.ListFirewallPoliciesOutput, lastPage bool) bool {
+			for _, fp := range page.FirewallPolicies {
+				name := aws.StringValue(fp.Name)
+				if name == ctx.defaultFirewallPolicyName() || strings.HasPrefix(name, ctx.templateFirewallPolicyPrefix()) {
+					policies = append(policies, fp)
+				}
+			}
+			return true
+		})
 	if err != nil {
-		return fmt.Errorf("Error checking for existence of default firewall policy: %s", err)
+		return fmt.Errorf("Error listing firewall policies: %s", err)
 	}
-	if arn != nil {
+	for _, fp := range policies {
+		name := aws.StringValue(fp.Name)
+		arn := fp.Arn
 		// we wait here and explicitly check for a dependency exception since AWS doesn't reliably report the deletion of the firewall
 		err = w.Wait(func() waiter.Result {
 			_, err = ctx.NetworkFirewall().DeleteFirewallPolicy(&networkfirewall.DeleteFirewallPolicyInput{
@@ -360,20 +372,44 @@ func (ctx *Context) DeleteFirewallResources() error {
 						// this shouldn't be possible since we just checked if it existed
 						return waiter.Done()
 					} else if aerr.Code() == networkfirewall.ErrCodeInvalidOperationException {
-						// dependency exception: until firewall deletion is complete, it's still using the default policy
+						// dependency exception: until firewall deletion is complete, it's still using the policy
 						return waiter.Continue("Waiting for deletion of network firewall to be complete")
 					} else {
-						return waiter.Error(fmt.Errorf("Error deleting default firewall policy: %s", err))
+						return waiter.Error(fmt.Errorf("Error deleting firewall policy %s: %s", name, err))
 					}
 				} else {
-					return waiter.Error(fmt.Errorf("Error deleting default firewall policy: %s", err))
+					return waiter.Error(fmt.Errorf("Error deleting firewall policy %s: %s", name, err))
 				}
 			}
-			return waiter.DoneWithMessage("Deleting default firewall policy")
+			return waiter.DoneWithMessage("Deleting firewall policy " + name)
+		})
+		if err != nil {
+			return fmt.Errorf("Error waiting to delete firewall policy %s: %s", name, err)
+		}
+	}
+
+	// domain allow-lists, which can only go once the policies using them are gone
+	ruleGroupARNs := []*string{}
+	err = ctx.NetworkFirewall().ListRuleGroupsPages(&networkfirewall.ListRuleGroupsInput{},
+		func(page *networkfirewall.ListRuleGroupsOutput, lastPage bool) bool {
+			for _, rg := range page.RuleGroups {
+				if strings.HasPrefix(aws.StringValue(rg.Name), ctx.domainAllowListPrefix()) {
+					ruleGroupARNs = append(ruleGroupARNs, rg.Arn)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return fmt.Errorf("Error listing firewall rule groups: %s", err)
+	}
+	for _, arn := range ruleGroupARNs {
+		_, err = ctx.NetworkFirewall().DeleteRuleGroup(&networkfirewall.DeleteRuleGroupInput{
+			RuleGroupArn: arn,
 		})
 		if err != nil {
-			return fmt.Errorf("Error waiting to delete default firewall policy: %s", err)
+			return fmt.Errorf("Error deleting rule group %s: %s", aws.StringValue(arn), err)
 		}
+		ctx.Log("Deleting rule group %s", aws.StringValue(arn))
 	}
 
 	return nil
@@ -0,0 +1,147 @@
+package aws
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/networkfirewall"
+)
+
+var firewallLogTypes = []string{networkfirewall.LogTypeAlert, networkfirewall.LogTypeFlow}
+
+// FirewallLogDestinations returns the firewall's current logging configuration
+// ordered by log type. RetentionInDays is not filled in.
+func (ctx *Context) FirewallLogDestinations() ([]*database.FirewallLogDestination, error) {
+	out, err := ctx.NetworkFirewall().DescribeLoggingConfiguration(&networkfirewall.DescribeLoggingConfigurationInput{
+		FirewallName: aws.String(ctx.FirewallName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing firewall logging configuration: %s", err)
+	}
+	byType := map[string]*database.FirewallLogDestination{}
+	if out.LoggingConfiguration != nil {
+		for _, config := range out.LoggingConfiguration.LogDestinationConfigs {
+			byType[aws.StringValue(config.LogType)] = &database.FirewallLogDestination{
+				LogType:         aws.StringValue(config.LogType),
+				DestinationType: aws.StringValue(config.LogDestinationType),
+				Destination:     aws.StringValueMap(config.LogDestination),
+			}
+		}
+	}
+	return orderedLogDestinations(byType), nil
+}
+
+func orderedLogDestinations(byType map[string]*database.FirewallLogDestination) []*database.FirewallLogDestination {
+	var dests []*database.FirewallLogDestination
+	for _, logType := range firewallLogTypes {
+		if dest := byType[logType]; dest != nil {
+			dests = append(dests, dest)
+		}
+	}
+	return dests
+}
+
+// SameFirewallLogDestination compares everything AWS stores for a
+// destination, ignoring retention.
+func SameFirewallLogDestination(a, b *database.FirewallLogDestination) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.LogType == b.LogType && a.DestinationType == b.DestinationType && reflect.DeepEqual(a.Destination, b.Destination)
+}
+
+// firewallLoggingSteps returns the sequence of configurations that takes the
+// firewall from have to want. Network Firewall rejects any update that
+// adds, removes or changes more than one destination at a time.
+func firewallLoggingSteps(have, want []*database.FirewallLogDestination) [][]*database.FirewallLogDestination {
+	current := map[string]*database.FirewallLogDestination{}
+	for _, dest := range have {
+		current[dest.LogType] = dest
+	}
+	desired := map[string]*database.FirewallLogDestination{}
+	for _, dest := range want {
+		desired[dest.LogType] = dest
+	}
+	var steps [][]*database.FirewallLogDestination
+	for _, logType := range firewallLogTypes {
+		from, to := current[logType], desired[logType]
+		if SameFirewallLogDestination(from, to) {
+			continue
+		}
+		if from != nil && (to == nil || from.DestinationType != to.DestinationType) {
+			delete(current, logType)
+			steps = append(steps, orderedLogDestinations(current))
+		}
+		if to != nil {
+			current[logType] = to
+			steps = append(steps, orderedLogDestinations(current))
+		}
+	}
+	return steps
+}
+
+// UpdateFirewallLogDestinations changes the firewall's logging configuration
+// to match want.
+func (ctx *Context) UpdateFirewallLogDestinations(want []*database.FirewallLogDestination) error {
+	have, err := ctx.FirewallLogDestinations()
+	if err != nil {
+		return err
+	}
+	for _, step := range firewallLoggingSteps(have, want) {
+		configs := []*networkfirewall.LogDestinationConfig{}
+		for _, dest := range step {
+			configs = append(configs, &networkfirewall.LogDestinationConfig{
+				LogType:            aws.String(dest.LogType),
+				LogDestinationType: aws.String(dest.DestinationType),
+				LogDestination:     aws.StringMap(dest.Destination),
+			})
+		}
+		_, err := ctx.NetworkFirewall().UpdateLoggingConfiguration(&networkfirewall.UpdateLoggingConfigurationInput{
+			FirewallName: aws.String(ctx.FirewallName()),
+			LoggingConfiguration: &networkfirewall.LoggingConfiguration{
+				LogDestinationConfigs: configs,
+			},
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == networkfirewall.ErrCodeInvalidRequestException && aerr.Message() == NoLoggingConfigChanges {
+				continue
+			}
+			return fmt.Errorf("Error updating firewall logging configuration: %s", err)
+		}
+	}
+	return nil
+}
+
+// LogGroupRetention returns the retention setting of the given log group, 0
+// meaning logs never expire, and whether the log group exists.
+func (ctx *Context) LogGroupRetention(logGroupName string) (int64, bool, error) {
+	out, err := ctx.CloudWatchLogs().DescribeLogGroups(&cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(logGroupName),
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("Error listing CloudWatch Logs groups: %s", err)
+	}
+	for _, logGroup := range out.LogGroups {
+		if aws.StringValue(logGroup.LogGroupName) == logGroupName {
+			return aws.Int64Value(logGroup.RetentionInDays), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// SetLogGroupRetention sets how long the given log group keeps logs.
+func (ctx *Context) SetLogGroupRetention(logGroupName string, days int64) error {
+	_, err := ctx.CloudWatchLogs().PutRetentionPolicy(&cloudwatchlogs.PutRetentionPolicyInput{
+		LogGroupName:    aws.String(logGroupName),
+		RetentionInDays: aws.Int64(days),
+	})
+	if err != nil {
+		return fmt.Errorf("Error setting retention on log group %s: %s", logGroupName, err)
+	}
+	ctx.Log("Set retention on log group %s to %d days", logGroupName, days)
+	return nil
+}
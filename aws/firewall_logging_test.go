@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+var (
+	alertToCloudWatch = &database.FirewallLogDestination{
+		LogType:         "ALERT",
+		DestinationType: "CloudWatchLogs",
+		Destination:     map[string]string{"logGroup": "alerts"},
+	}
+	alertToOtherCloudWatch = &database.FirewallLogDestination{
+		LogType:         "ALERT",
+		DestinationType: "CloudWatchLogs",
+		Destination:     map[string]string{"logGroup": "other"},
+	}
+	alertToS3 = &database.FirewallLogDestination{
+		LogType:         "ALERT",
+		DestinationType: "S3",
+		Destination:     map[string]string{"bucketName": "bucket"},
+	}
+	flowToS3 = &database.FirewallLogDestination{
+		LogType:         "FLOW",
+		DestinationType: "S3",
+		Destination:     map[string]string{"bucketName": "bucket", "prefix": "firewall"},
+	}
+)
+
+var firewallLoggingStepsTests = []struct {
+	name     string
+	have     []*database.FirewallLogDestination
+	want     []*database.FirewallLogDestination
+	expected [][]*database.FirewallLogDestination
+}{
+	{
+		name: "Already configured",
+		have: []*database.FirewallLogDestination{alertToCloudWatch, flowToS3},
+		want: []*database.FirewallLogDestination{alertToCloudWatch, flowToS3},
+	},
+	{
+		name: "Add both one at a time",
+		want: []*database.FirewallLogDestination{alertToCloudWatch, flowToS3},
+		expected: [][]*database.FirewallLogDestination{
+			{alertToCloudWatch},
+			{alertToCloudWatch, flowToS3},
+		},
+	},
+	{
+		name: "Remove flow logs",
+		have: []*database.FirewallLogDestination{alertToCloudWatch, flowToS3},
+		want: []*database.FirewallLogDestination{alertToCloudWatch},
+		expected: [][]*database.FirewallLogDestination{
+			{alertToCloudWatch},
+		},
+	},
+	{
+		name: "Change log group in place",
+		have: []*database.FirewallLogDestination{alertToOtherCloudWatch, flowToS3},
+		want: []*database.FirewallLogDestination{alertToCloudWatch, flowToS3},
+		expected: [][]*database.FirewallLogDestination{
+			{alertToCloudWatch, flowToS3},
+		},
+	},
+	{
+		name: "Change destination type",
+		have: []*database.FirewallLogDestination{alertToS3},
+		want: []*database.FirewallLogDestination{alertToCloudWatch, flowToS3},
+		expected: [][]*database.FirewallLogDestination{
+			nil,
+			{alertToCloudWatch},
+			{alertToCloudWatch, flowToS3},
+		},
+	},
+}
+
+func TestFirewallLoggingSteps(t *testing.T) {
+	for _, tc := range firewallLoggingStepsTests {
+		t.Run(tc.name, func(t *testing.T) {
+			steps := firewallLoggingSteps(tc.have, tc.want)
+			if !reflect.DeepEqual(steps, tc.expected) {
+				t.Errorf("Expected %v but got %v", tc.expected, steps)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/networkfirewall"
+)
+
+// Domain list rule groups can't be resized after creation, so leave room to grow
+const domainAllowListCapacity = 1000
+
+func (ctx *Context) templateFirewallPolicyPrefix() string {
+	return fmt.Sprintf("cms-cloud-%s-fp-", ctx.VPCID)
+}
+
+func (ctx *Context) templateFirewallPolicyName(templateID uint64) string {
+	return fmt.Sprintf("%s%d", ctx.templateFirewallPolicyPrefix(), templateID)
+}
+
+func (ctx *Context) domainAllowListPrefix() string {
+	return fmt.Sprintf("cms-cloud-%s-domains-", ctx.VPCID)
+}
+
+// A rule group's rule order can't be changed after creation, so strict order
+// allow-lists get their own name.
+func (ctx *Context) domainAllowListName(templateID uint64, strictOrder bool) string {
+	if strictOrder {
+		return fmt.Sprintf("%s%d-strict", ctx.domainAllowListPrefix(), templateID)
+	}
+	return fmt.Sprintf("%s%d", ctx.domainAllowListPrefix(), templateID)
+}
+
+// returns the ARN or nil if the policy doesn't exist
+func (ctx *Context) getFirewallPolicyARN(name string) (*string, error) {
+	var arn *string
+	err := ctx.NetworkFirewall().ListFirewallPoliciesPages(&networkfirewall.ListFirewallPoliciesInput{},
+		func(page *networkfirewall.ListFirewallPoliciesOutput, lastPage bool) bool {
+			for _, fp := range page.FirewallPolicies {
+				if aws.StringValue(fp.Name) == name {
+					arn = fp.Arn
+					return false
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing firewall policies: %s", err)
+	}
+	return arn, nil
+}
+
+// FirewallPolicyARN returns the ARN of the policy for the given template, or
+// of the default policy if templateID is 0. It returns nil if the policy
+// doesn't exist.
+func (ctx *Context) FirewallPolicyARN(templateID uint64) (*string, error) {
+	if templateID == 0 {
+		return ctx.getDefaultFirewallPolicyARN()
+	}
+	return ctx.getFirewallPolicyARN(ctx.templateFirewallPolicyName(templateID))
+}
+
+// EnsureTemplateFirewallPolicy creates or updates this VPC's copy of the
+// given policy template and returns its ARN. With strict rule order, stateful
+// rule groups are evaluated in the order listed, then the domain allow-list,
+// and the template's rule groups must use strict order too.
+func (ctx *Context) EnsureTemplateFirewallPolicy(template *database.FirewallPolicyTemplate) (string, error) {
+	strictOrder := template.StatefulRuleOrder == networkfirewall.RuleOrderStrictOrder
+	policy := &networkfirewall.FirewallPolicy{
+		StatelessDefaultActions:         aws.StringSlice(template.StatelessDefaultActions),
+		StatelessFragmentDefaultActions: aws.StringSlice(template.StatelessFragmentDefaultActions),
+	}
+	if strictOrder {
+		policy.StatefulEngineOptions = &networkfirewall.StatefulEngineOptions{
+			RuleOrder: aws.String(networkfirewall.RuleOrderStrictOrder),
+		}
+		if len(template.StatefulDefaultActions) > 0 {
+			policy.StatefulDefaultActions = aws.StringSlice(template.StatefulDefaultActions)
+		}
+	}
+	if len(policy.StatelessDefaultActions) == 0 {
+		policy.StatelessDefaultActions = []*string{aws.String(forwardToSFEAction)}
+	}
+	if len(policy.StatelessFragmentDefaultActions) == 0 {
+		policy.StatelessFragmentDefaultActions = []*string{aws.String(forwardToSFEAction)}
+	}
+	addStatefulRuleGroup := func(arn string) {
+		ref := &networkfirewall.StatefulRuleGroupReference{
+			ResourceArn: aws.String(arn),
+		}
+		if strictOrder {
+			ref.Priority = aws.Int64(int64(len(policy.StatefulRuleGroupReferences) + 1))
+		}
+		policy.StatefulRuleGroupReferences = append(policy.StatefulRuleGroupReferences, ref)
+	}
+	for _, arn := range template.StatefulRuleGroupARNs {
+		addStatefulRuleGroup(arn)
+	}
+	for idx, arn := range template.StatelessRuleGroupARNs {
+		policy.StatelessRuleGroupReferences = append(policy.StatelessRuleGroupReferences, &networkfirewall.StatelessRuleGroupReference{
+			ResourceArn: aws.String(arn),
+			Priority:    aws.Int64(int64(idx + 1)),
+		})
+	}
+	if len(template.AllowedDomains) > 0 {
+		arn, err := ctx.ensureDomainAllowList(template.ID, strictOrder, template.AllowedDomains)
+		if err != nil {
+			return "", err
+		}
+		addStatefulRuleGroup(arn)
+	}
+
+	name := ctx.templateFirewallPolicyName(template.ID)
+	arn, err := ctx.getFirewallPolicyARN(name)
+	if err != nil {
+		return "", err
+	}
+	if arn != nil {
+		desc, err := ctx.NetworkFirewall().DescribeFirewallPolicy(&networkfirewall.DescribeFirewallPolicyInput{
+			FirewallPolicyArn: arn,
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error describing firewall policy %s: %s", name, err)
+		}
+		_, err = ctx.NetworkFirewall().UpdateFirewallPolicy(&networkfirewall.UpdateFirewallPolicyInput{
+			FirewallPolicyArn: arn,
+			FirewallPolicy:    policy,
+			Description:       aws.String(template.Name),
+			UpdateToken:       desc.UpdateToken,
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error updating firewall policy %s: %s", name, err)
+		}
+		ctx.Log("Updated firewall policy %s", name)
+		// The policy no longer references the allow-list with the other rule
+		// order, or either of them if there are no allowed domains
+		for _, strict := range []bool{false, true} {
+			if len(template.AllowedDomains) > 0 && strict == strictOrder {
+				continue
+			}
+			err = ctx.deleteDomainAllowListNamed(ctx.domainAllowListName(template.ID, strict))
+			if err != nil {
+				return "", fmt.Errorf("Error deleting unused domain allow-list: %s", err)
+			}
+		}
+		return aws.StringValue(arn), nil
+	}
+
+	out, err := ctx.NetworkFirewall().CreateFirewallPolicy(&networkfirewall.CreateFirewallPolicyInput{
+		FirewallPolicyName: aws.String(name),
+		FirewallPolicy:     policy,
+		Description:        aws.String(template.Name),
+	})
+	if err != nil {
+		if len(template.AllowedDomains) > 0 {
+			// Nothing references the allow-list without the policy
+			if err := ctx.deleteDomainAllowListNamed(ctx.domainAllowListName(template.ID, strictOrder)); err != nil {
+				ctx.Log("Error deleting domain allow-list: %s", err)
+			}
+		}
+		return "", fmt.Errorf("Error creating firewall policy %s: %s", name, err)
+	}
+	ctx.Log("Created firewall policy %s", name)
+	return aws.StringValue(out.FirewallPolicyResponse.FirewallPolicyArn), nil
+}
+
+func (ctx *Context) ensureDomainAllowList(templateID uint64, strictOrder bool, domains []string) (string, error) {
+	if len(domains) > domainAllowListCapacity {
+		return "", fmt.Errorf("At most %d allowed domains are supported", domainAllowListCapacity)
+	}
+	name := ctx.domainAllowListName(templateID, strictOrder)
+	ruleGroup := &networkfirewall.RuleGroup{
+		RulesSource: &networkfirewall.RulesSource{
+			RulesSourceList: &networkfirewall.RulesSourceList{
+				GeneratedRulesType: aws.String(networkfirewall.GeneratedRulesTypeAllowlist),
+				TargetTypes: aws.StringSlice([]string{
+					networkfirewall.TargetTypeTlsSni,
+					networkfirewall.TargetTypeHttpHost,
+				}),
+				Targets: aws.StringSlice(domains),
+			},
+		},
+	}
+	if strictOrder {
+		ruleGroup.StatefulRuleOptions = &networkfirewall.StatefulRuleOptions{
+			RuleOrder: aws.String(networkfirewall.RuleOrderStrictOrder),
+		}
+	}
+	desc, err := ctx.NetworkFirewall().DescribeRuleGroup(&networkfirewall.DescribeRuleGroupInput{
+		RuleGroupName: aws.String(name),
+		Type:          aws.String(networkfirewall.RuleGroupTypeStateful),
+	})
+	if err == nil {
+		_, err = ctx.NetworkFirewall().UpdateRuleGroup(&networkfirewall.UpdateRuleGroupInput{
+			RuleGroupArn: desc.RuleGroupResponse.RuleGroupArn,
+			RuleGroup:    ruleGroup,
+			Type:         aws.String(networkfirewall.RuleGroupTypeStateful),
+			UpdateToken:  desc.UpdateToken,
+		})
+		if err != nil {
+			return "", fmt.Errorf("Error updating domain allow-list %s: %s", name, err)
+		}
+		return aws.StringValue(desc.RuleGroupResponse.RuleGroupArn), nil
+	}
+	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != networkfirewall.ErrCodeResourceNotFoundException {
+		return "", fmt.Errorf("Error describing domain allow-list %s: %s", name, err)
+	}
+	out, err := ctx.NetworkFirewall().CreateRuleGroup(&networkfirewall.CreateRuleGroupInput{
+		RuleGroupName: aws.String(name),
+		RuleGroup:     ruleGroup,
+		Type:          aws.String(networkfirewall.RuleGroupTypeStateful),
+		Capacity:      aws.Int64(domainAllowListCapacity),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error creating domain allow-list %s: %s", name, err)
+	}
+	ctx.Log("Created domain allow-list %s", name)
+	return aws.StringValue(out.RuleGroupResponse.RuleGroupArn), nil
+}
+
+func (ctx *Context) deleteDomainAllowListNamed(name string) error {
+	_, err := ctx.NetworkFirewall().DeleteRuleGroup(&networkfirewall.DeleteRuleGroupInput{
+		RuleGroupName: aws.String(name),
+		Type:          aws.String(networkfirewall.RuleGroupTypeStateful),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == networkfirewall.ErrCodeResourceNotFoundException {
+		return nil
+	}
+	return err
+}
+
+// deletes the template's allow-lists with either rule order
+func (ctx *Context) deleteDomainAllowList(templateID uint64) error {
+	for _, strict := range []bool{false, true} {
+		err := ctx.deleteDomainAllowListNamed(ctx.domainAllowListName(templateID, strict))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTemplateFirewallPolicy removes this VPC's copy of a policy template
+// once the firewall no longer uses it.
+func (ctx *Context) DeleteTemplateFirewallPolicy(templateID uint64) error {
+	name := ctx.templateFirewallPolicyName(templateID)
+	arn, err := ctx.getFirewallPolicyARN(name)
+	if err != nil {
+		return err
+	}
+	if arn != nil {
+		_, err = ctx.NetworkFirewall().DeleteFirewallPolicy(&networkfirewall.DeleteFirewallPolicyInput{
+			FirewallPolicyArn: arn,
+		})
+		if err != nil {
+			return fmt.Errorf("Error deleting firewall policy %s: %s", name, err)
+		}
+		ctx.Log("Deleted firewall policy %s", name)
+	}
+	return ctx.deleteDomainAllowList(templateID)
+}
+
+// AssociateFirewallPolicy switches the VPC's firewall to the given policy.
+func (ctx *Context) AssociateFirewallPolicy(policyARN string) error {
+	_, err := ctx.NetworkFirewall().AssociateFirewallPolicy(&networkfirewall.AssociateFirewallPolicyInput{
+		FirewallName:      aws.String(ctx.FirewallName()),
+		FirewallPolicyArn: aws.String(policyARN),
+	})
+	if err != nil {
+		return fmt.Errorf("Error associating firewall policy: %s", err)
+	}
+	ctx.Log("Associated firewall %s with policy %s", ctx.FirewallName(), policyARN)
+	return nil
+}
+
+// AssociatedFirewallPolicyARN returns the ARN of the policy the VPC's
+// firewall is using.
+func (ctx *Context) AssociatedFirewallPolicyARN() (string, error) {
+	out, err := ctx.NetworkFirewall().DescribeFirewall(&networkfirewall.DescribeFirewallInput{
+		FirewallName: aws.String(ctx.FirewallName()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error describing network firewall: %s", err)
+	}
+	return aws.StringValue(out.Firewall.FirewallPolicyArn), nil
+}
@@ -0,0 +1,262 @@
+package aws
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// AWS only supports /64 subnets in IPv6
+const ipv6SubnetPrefixLength = 64
+
+func IsIPv6CIDR(dest string) bool {
+	return strings.Contains(dest, ":")
+}
+
+func IsEgressOnlyInternetGatewayID(id string) bool {
+	return strings.HasPrefix(id, "eigw-")
+}
+
+// RouteDestination returns the IPv4 CIDR, IPv6 CIDR or prefix list ID that
+// the route targets, or "" if it has none of them.
+func RouteDestination(route *ec2.Route) string {
+	if aws.StringValue(route.DestinationCidrBlock) != "" {
+		return aws.StringValue(route.DestinationCidrBlock)
+	} else if aws.StringValue(route.DestinationIpv6CidrBlock) != "" {
+		return aws.StringValue(route.DestinationIpv6CidrBlock)
+	}
+	return aws.StringValue(route.DestinationPrefixListId)
+}
+
+// NextIPv6SubnetCIDRs returns the first n /64s within vpcCIDR that are not
+// among the inUse CIDRs.
+func NextIPv6SubnetCIDRs(vpcCIDR string, inUse []string, n int) ([]string, error) {
+	_, network, err := net.ParseCIDR(vpcCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid IPv6 CIDR %q: %s", vpcCIDR, err)
+	}
+	if network.IP.To4() != nil {
+		return nil, fmt.Errorf("%s is not an IPv6 CIDR", vpcCIDR)
+	}
+	ones, _ := network.Mask.Size()
+	if ones > ipv6SubnetPrefixLength {
+		return nil, fmt.Errorf("IPv6 CIDR %s is smaller than a /%d", vpcCIDR, ipv6SubnetPrefixLength)
+	}
+
+	used := map[string]bool{}
+	for _, cidr := range inUse {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid IPv6 CIDR %q: %s", cidr, err)
+		}
+		used[ipNet.String()] = true
+	}
+
+	base := binary.BigEndian.Uint64(network.IP[:8])
+	count := uint64(1) << uint(ipv6SubnetPrefixLength-ones)
+	cidrs := []string{}
+	for i := uint64(0); i < count && len(cidrs) < n; i++ {
+		ip := make(net.IP, net.IPv6len)
+		binary.BigEndian.PutUint64(ip[:8], base+i)
+		subnet := &net.IPNet{IP: ip, Mask: net.CIDRMask(ipv6SubnetPrefixLength, 128)}
+		if !used[subnet.String()] {
+			cidrs = append(cidrs, subnet.String())
+		}
+	}
+	if len(cidrs) < n {
+		return nil, fmt.Errorf("Only %d free /%d subnets left in %s but %d are needed", len(cidrs), ipv6SubnetPrefixLength, vpcCIDR, n)
+	}
+	return cidrs, nil
+}
+
+// AssociatedIPv6CIDRBlock returns the IPv6 block currently associated with
+// the given VPC and its association ID, or "" if there is none.
+func AssociatedIPv6CIDRBlock(vpc *ec2.Vpc) (string, string) {
+	for _, assoc := range vpc.Ipv6CidrBlockAssociationSet {
+		if assoc.Ipv6CidrBlockState != nil && aws.StringValue(assoc.Ipv6CidrBlockState.State) == ec2.VpcCidrBlockStateCodeAssociated {
+			return aws.StringValue(assoc.Ipv6CidrBlock), aws.StringValue(assoc.AssociationId)
+		}
+	}
+	return "", ""
+}
+
+// SubnetIPv6CIDR returns the /64 currently associated with the given subnet, or "" if there is none.
+func SubnetIPv6CIDR(subnet *ec2.Subnet) string {
+	for _, assoc := range subnet.Ipv6CidrBlockAssociationSet {
+		if assoc.Ipv6CidrBlockState != nil && aws.StringValue(assoc.Ipv6CidrBlockState.State) == ec2.SubnetCidrBlockStateCodeAssociated {
+			return aws.StringValue(assoc.Ipv6CidrBlock)
+		}
+	}
+	return ""
+}
+
+func (ctx *Context) AddIPv6CIDRBlock(config *database.IPv6Config) (string, string, error) {
+	return ctx.associateIPv6CIDRBlock(ctx.VPCID, config)
+}
+
+func (ctx *Context) associateIPv6CIDRBlock(vpcID string, config *database.IPv6Config) (string, string, error) {
+	input := &ec2.AssociateVpcCidrBlockInput{
+		VpcId: &vpcID,
+	}
+	if config.Pool == "" {
+		input.AmazonProvidedIpv6CidrBlock = aws.Bool(true)
+	} else {
+		input.Ipv6Pool = aws.String(config.Pool)
+		if config.CIDRBlock != "" {
+			input.Ipv6CidrBlock = aws.String(config.CIDRBlock)
+		}
+	}
+	assoc, err := ctx.EC2().AssociateVpcCidrBlock(input)
+	if err != nil {
+		return "", "", fmt.Errorf("Error associating IPv6 CIDR block: %s", err)
+	}
+	assocID := aws.StringValue(assoc.Ipv6CidrBlockAssociation.AssociationId)
+	ctx.destructors = append(ctx.destructors, func() error {
+		_, err := ctx.EC2().DisassociateVpcCidrBlock(&ec2.DisassociateVpcCidrBlockInput{
+			AssociationId: &assocID,
+		})
+		if err == nil {
+			ctx.Logger.Log("Dissasociated IPv6 CIDR block %s", assocID)
+		}
+		return err
+	})
+
+	// Amazon-provided blocks aren't known until the association completes.
+	startedWaiting := ctx.clock().Now()
+	for {
+		out, err := ctx.EC2().DescribeVpcs(&ec2.DescribeVpcsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: []*string{aws.String(vpcID)},
+				},
+			},
+		})
+		if err != nil {
+			return "", "", err
+		}
+		for _, vpc := range out.Vpcs {
+			for _, a := range vpc.Ipv6CidrBlockAssociationSet {
+				if aws.StringValue(a.AssociationId) != assocID || a.Ipv6CidrBlockState == nil {
+					continue
+				}
+				state := aws.StringValue(a.Ipv6CidrBlockState.State)
+				if state == ec2.VpcCidrBlockStateCodeAssociated {
+					cidr := aws.StringValue(a.Ipv6CidrBlock)
+					ctx.Logger.Log("Associated IPv6 CIDR block %s", cidr)
+					return cidr, assocID, nil
+				} else if state == ec2.VpcCidrBlockStateCodeFailed {
+					return "", "", fmt.Errorf("Association of IPv6 CIDR block failed: %s", aws.StringValue(a.Ipv6CidrBlockState.StatusMessage))
+				}
+			}
+		}
+		if ctx.clock().Since(startedWaiting) > MaxExistenceWaitTime {
+			return "", "", fmt.Errorf("Timed out waiting for IPv6 CIDR block association %s", assocID)
+		}
+		ctx.clock().Sleep(1 * time.Second)
+	}
+}
+
+// GetSubnetIPv6CIDRs returns the IPv6 CIDRs of all subnets in the VPC, by subnet ID.
+func (ctx *Context) GetSubnetIPv6CIDRs() (map[string]string, error) {
+	return ctx.getSubnetIPv6CIDRs(ctx.VPCID)
+}
+
+func (ctx *Context) getSubnetIPv6CIDRs(vpcID string) (map[string]string, error) {
+	out, err := ctx.EC2().DescribeSubnets(&ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []*string{aws.String(vpcID)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing subnets: %s", err)
+	}
+	cidrs := map[string]string{}
+	for _, subnet := range out.Subnets {
+		if cidr := SubnetIPv6CIDR(subnet); cidr != "" {
+			cidrs[aws.StringValue(subnet.SubnetId)] = cidr
+		}
+	}
+	return cidrs, nil
+}
+
+func (ctx *Context) AssociateSubnetIPv6CIDR(subnetID, cidr string) error {
+	_, err := ctx.EC2().AssociateSubnetCidrBlock(&ec2.AssociateSubnetCidrBlockInput{
+		SubnetId:      &subnetID,
+		Ipv6CidrBlock: &cidr,
+	})
+	if err != nil {
+		return fmt.Errorf("Error associating IPv6 CIDR %s with subnet %s: %s", cidr, subnetID, err)
+	}
+	ctx.Logger.Log("Associated IPv6 CIDR %s with subnet %s", cidr, subnetID)
+	return nil
+}
+
+func (ctx *Context) EgressOnlyInternetGatewayExists(id string) (bool, error) {
+	out, err := ctx.EC2().DescribeEgressOnlyInternetGateways(&ec2.DescribeEgressOnlyInternetGatewaysInput{
+		EgressOnlyInternetGatewayIds: []*string{aws.String(id)},
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && strings.HasSuffix(aerr.Code(), ".NotFound") {
+			return false, nil
+		}
+		return false, err
+	}
+	return len(out.EgressOnlyInternetGateways) > 0, nil
+}
+
+func (ctx *Context) CreateEgressOnlyInternetGateway() (string, error) {
+	out, err := ctx.EC2().CreateEgressOnlyInternetGateway(&ec2.CreateEgressOnlyInternetGatewayInput{
+		VpcId: &ctx.VPCID,
+	})
+	if err != nil {
+		return "", err
+	}
+	id := aws.StringValue(out.EgressOnlyInternetGateway.EgressOnlyInternetGatewayId)
+	ctx.destructors = append(ctx.destructors, func() error {
+		return ctx.DeleteEgressOnlyInternetGateway(id)
+	})
+	return id, nil
+}
+
+func (ctx *Context) DeleteEgressOnlyInternetGateway(id string) error {
+	_, err := ctx.EC2().DeleteEgressOnlyInternetGateway(&ec2.DeleteEgressOnlyInternetGatewayInput{
+		EgressOnlyInternetGatewayId: &id,
+	})
+	if err != nil {
+		return err
+	}
+	ctx.Logger.Log("Deleted Egress-Only Internet Gateway %s", id)
+	return nil
+}
+
+// GetAttachedEgressOnlyInternetGateway returns the egress-only internet
+// gateway attached to the VPC, or nil if there is none.
+func (ctx *Context) GetAttachedEgressOnlyInternetGateway() (*ec2.EgressOnlyInternetGateway, error) {
+	var found *ec2.EgressOnlyInternetGateway
+	err := ctx.EC2().DescribeEgressOnlyInternetGatewaysPages(&ec2.DescribeEgressOnlyInternetGatewaysInput{}, func(out *ec2.DescribeEgressOnlyInternetGatewaysOutput, lastPage bool) bool {
+		for _, eigw := range out.EgressOnlyInternetGateways {
+			for _, attachment := range eigw.Attachments {
+				if aws.StringValue(attachment.VpcId) == ctx.VPCID && aws.StringValue(attachment.State) == ec2.AttachmentStatusAttached {
+					found = eigw
+					return false
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing egress-only internet gateways: %s", err)
+	}
+	return found, nil
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+)
+
+var nextIPv6SubnetCIDRsTests = []struct {
+	name        string
+	vpcCIDR     string
+	inUse       []string
+	n           int
+	expected    []string
+	expectError bool
+}{
+	{
+		name:     "Empty VPC",
+		vpcCIDR:  "2600:1f14:abc:de00::/56",
+		n:        2,
+		expected: []string{"2600:1f14:abc:de00::/64", "2600:1f14:abc:de01::/64"},
+	},
+	{
+		name:     "Skip CIDRs in use",
+		vpcCIDR:  "2600:1f14:abc:de00::/56",
+		inUse:    []string{"2600:1f14:abc:de00::/64", "2600:1f14:abc:de02::/64"},
+		n:        2,
+		expected: []string{"2600:1f14:abc:de01::/64", "2600:1f14:abc:de03::/64"},
+	},
+	{
+		name:        "Not enough space",
+		vpcCIDR:     "2600:1f14:abc:de00::/63",
+		inUse:       []string{"2600:1f14:abc:de00::/64"},
+		n:           2,
+		expectError: true,
+	},
+	{
+		name:        "IPv4 CIDR",
+		vpcCIDR:     "10.0.0.0/16",
+		n:           1,
+		expectError: true,
+	},
+}
+
+func TestNextIPv6SubnetCIDRs(t *testing.T) {
+	for _, test := range nextIPv6SubnetCIDRsTests {
+		t.Run(test.name, func(t *testing.T) {
+			cidrs, err := NextIPv6SubnetCIDRs(test.vpcCIDR, test.inUse, test.n)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected an error but got %v", cidrs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(cidrs, test.expected) {
+				t.Errorf("Expected %v but got %v", test.expected, cidrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
+)
+
+const (
+	RoutesPerRouteTableQuotaCode = "L-93826ACB"
+	DefaultRoutesPerRouteTable   = 50
+)
+
+// RoutesPerRouteTableQuota returns the account's "Routes per route table"
+// quota. Accounts that have never had it raised have no applied value, so
+// the AWS default is used for them.
+func RoutesPerRouteTableQuota(svc servicequotasiface.ServiceQuotasAPI) (int, error) {
+	out, err := svc.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+		QuotaCode:   aws.String(RoutesPerRouteTableQuotaCode),
+		ServiceCode: aws.String("vpc"),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != servicequotas.ErrCodeNoSuchResourceException {
+			return 0, err
+		}
+		def, err := svc.GetAWSDefaultServiceQuota(&servicequotas.GetAWSDefaultServiceQuotaInput{
+			QuotaCode:   aws.String(RoutesPerRouteTableQuotaCode),
+			ServiceCode: aws.String("vpc"),
+		})
+		if err != nil {
+			return 0, err
+		}
+		out = &servicequotas.GetServiceQuotaOutput{Quota: def.Quota}
+	}
+	if out.Quota == nil || out.Quota.Value == nil {
+		return DefaultRoutesPerRouteTable, nil
+	}
+	return int(aws.Float64Value(out.Quota.Value)), nil
+}
+
+// PrefixListMaxEntries returns the max entries of the given prefix list,
+// which is how many routes a route to it counts for against the routes per
+// route table quota. found is false if the prefix list isn't visible to the
+// account.
+func PrefixListMaxEntries(ec2svc ec2iface.EC2API, prefixListID string) (maxEntries int, found bool, err error) {
+	out, err := ec2svc.DescribeManagedPrefixLists(&ec2.DescribeManagedPrefixListsInput{
+		PrefixListIds: aws.StringSlice([]string{prefixListID}),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidPrefixListID.NotFound" {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if len(out.PrefixLists) == 0 {
+		return 0, false, nil
+	}
+	return int(aws.Int64Value(out.PrefixLists[0].MaxEntries)), true, nil
+}
@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// The functions in this file act on the transit gateway's side of an
+// attachment, so they take EC2 credentials for the account that owns the
+// transit gateway rather than using the VPC account's.
+
+// GetTransitGatewayAttachmentRouting returns the route table the attachment
+// is associated with, if any, and the route tables it propagates to.
+func (ctx *Context) GetTransitGatewayAttachmentRouting(ownerEC2 ec2iface.EC2API, attachmentID string) (string, []string, error) {
+	out, err := ownerEC2.DescribeTransitGatewayAttachments(&ec2.DescribeTransitGatewayAttachmentsInput{
+		TransitGatewayAttachmentIds: []*string{aws.String(attachmentID)},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("Error describing transit gateway attachment %s: %s", attachmentID, err)
+	}
+	if len(out.TransitGatewayAttachments) != 1 {
+		return "", nil, fmt.Errorf("Expected 1 transit gateway attachment for id %s but found %d", attachmentID, len(out.TransitGatewayAttachments))
+	}
+	associated := ""
+	if assoc := out.TransitGatewayAttachments[0].Association; assoc != nil {
+		state := aws.StringValue(assoc.State)
+		if state == ec2.TransitGatewayAssociationStateAssociated || state == ec2.TransitGatewayAssociationStateAssociating {
+			associated = aws.StringValue(assoc.TransitGatewayRouteTableId)
+		}
+	}
+
+	propagated := []string{}
+	err = ownerEC2.GetTransitGatewayAttachmentPropagationsPages(&ec2.GetTransitGatewayAttachmentPropagationsInput{
+		TransitGatewayAttachmentId: aws.String(attachmentID),
+	}, func(out *ec2.GetTransitGatewayAttachmentPropagationsOutput, lastPage bool) bool {
+		for _, p := range out.TransitGatewayAttachmentPropagations {
+			if aws.StringValue(p.State) == ec2.TransitGatewayPropagationStateEnabled || aws.StringValue(p.State) == ec2.TransitGatewayPropagationStateEnabling {
+				propagated = append(propagated, aws.StringValue(p.TransitGatewayRouteTableId))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("Error getting propagations for transit gateway attachment %s: %s", attachmentID, err)
+	}
+	sort.Strings(propagated)
+	return associated, propagated, nil
+}
+
+// AssociateTransitGatewayRouteTable associates the attachment with
+// routeTableID, first removing any association it already has since an
+// attachment can only be associated with one route table.
+func (ctx *Context) AssociateTransitGatewayRouteTable(ownerEC2 ec2iface.EC2API, attachmentID, currentRouteTableID, routeTableID string) error {
+	if currentRouteTableID != "" {
+		err := ctx.DisassociateTransitGatewayRouteTable(ownerEC2, attachmentID, currentRouteTableID)
+		if err != nil {
+			return err
+		}
+	}
+	ctx.Log("Associating transit gateway attachment %s with route table %s", attachmentID, routeTableID)
+	_, err := ownerEC2.AssociateTransitGatewayRouteTable(&ec2.AssociateTransitGatewayRouteTableInput{
+		TransitGatewayAttachmentId: aws.String(attachmentID),
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error associating transit gateway attachment %s with route table %s: %s", attachmentID, routeTableID, err)
+	}
+	return nil
+}
+
+// DisassociateTransitGatewayRouteTable removes the association and waits for
+// it to be gone so that a new one can be made.
+func (ctx *Context) DisassociateTransitGatewayRouteTable(ownerEC2 ec2iface.EC2API, attachmentID, routeTableID string) error {
+	ctx.Log("Disassociating transit gateway attachment %s from route table %s", attachmentID, routeTableID)
+	_, err := ownerEC2.DisassociateTransitGatewayRouteTable(&ec2.DisassociateTransitGatewayRouteTableInput{
+		TransitGatewayAttachmentId: aws.String(attachmentID),
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error disassociating transit gateway attachment %s from route table %s: %s", attachmentID, routeTableID, err)
+	}
+	startedWaiting := ctx.clock().Now()
+	maxWaitTime := time.Second * 300
+	for {
+		associated, _, err := ctx.GetTransitGatewayAttachmentRouting(ownerEC2, attachmentID)
+		if err != nil {
+			return err
+		}
+		if associated == "" {
+			return nil
+		}
+		if ctx.clock().Since(startedWaiting) > maxWaitTime {
+			return fmt.Errorf("Timed out waiting for transit gateway attachment %s to be disassociated", attachmentID)
+		}
+		ctx.clock().Sleep(1 * time.Second)
+	}
+}
+
+func (ctx *Context) EnableTransitGatewayRouteTablePropagation(ownerEC2 ec2iface.EC2API, attachmentID, routeTableID string) error {
+	ctx.Log("Propagating transit gateway attachment %s to route table %s", attachmentID, routeTableID)
+	_, err := ownerEC2.EnableTransitGatewayRouteTablePropagation(&ec2.EnableTransitGatewayRouteTablePropagationInput{
+		TransitGatewayAttachmentId: aws.String(attachmentID),
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error enabling propagation of transit gateway attachment %s to route table %s: %s", attachmentID, routeTableID, err)
+	}
+	return nil
+}
+
+func (ctx *Context) DisableTransitGatewayRouteTablePropagation(ownerEC2 ec2iface.EC2API, attachmentID, routeTableID string) error {
+	ctx.Log("Removing propagation of transit gateway attachment %s to route table %s", attachmentID, routeTableID)
+	_, err := ownerEC2.DisableTransitGatewayRouteTablePropagation(&ec2.DisableTransitGatewayRouteTablePropagationInput{
+		TransitGatewayAttachmentId: aws.String(attachmentID),
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error disabling propagation of transit gateway attachment %s to route table %s: %s", attachmentID, routeTableID, err)
+	}
+	return nil
+}
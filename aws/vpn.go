@@ -0,0 +1,293 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// The functions in this file manage Site-to-Site VPN connections on a
+// transit gateway, so the context must be for the account that owns the
+// transit gateway.
+
+func vpnTunnelOptionsSpecification(opts *database.VPNTunnelOptions) *ec2.VpnTunnelOptionsSpecification {
+	spec := &ec2.VpnTunnelOptionsSpecification{}
+	if opts.InsideCIDR != "" {
+		spec.TunnelInsideCidr = aws.String(opts.InsideCIDR)
+	}
+	for _, version := range opts.IKEVersions {
+		spec.IKEVersions = append(spec.IKEVersions, &ec2.IKEVersionsRequestListValue{Value: aws.String(version)})
+	}
+	if opts.DPDTimeoutAction != "" {
+		spec.DPDTimeoutAction = aws.String(opts.DPDTimeoutAction)
+	}
+	if opts.StartupAction != "" {
+		spec.StartupAction = aws.String(opts.StartupAction)
+	}
+	return spec
+}
+
+// VPNTunnelOptionsMatch is false if any option set in opts differs from the
+// tunnel's. Options left empty are whatever AWS defaulted them to.
+func VPNTunnelOptionsMatch(opts *database.VPNTunnelOptions, tunnel *ec2.TunnelOption) bool {
+	if opts.InsideCIDR != "" && opts.InsideCIDR != aws.StringValue(tunnel.TunnelInsideCidr) {
+		return false
+	}
+	if len(opts.IKEVersions) > 0 {
+		actual := []string{}
+		for _, v := range tunnel.IkeVersions {
+			actual = append(actual, aws.StringValue(v.Value))
+		}
+		if len(actual) != len(opts.IKEVersions) {
+			return false
+		}
+		for _, version := range opts.IKEVersions {
+			if !stringInSlice(version, actual) {
+				return false
+			}
+		}
+	}
+	if opts.DPDTimeoutAction != "" && opts.DPDTimeoutAction != aws.StringValue(tunnel.DpdTimeoutAction) {
+		return false
+	}
+	if opts.StartupAction != "" && opts.StartupAction != aws.StringValue(tunnel.StartupAction) {
+		return false
+	}
+	return true
+}
+
+// CreateCustomerGateway creates and tags a customer gateway and waits for it
+// to be available.
+func (ctx *Context) CreateCustomerGateway(spec *database.CustomerGatewayConfig, name string) (string, error) {
+	input := &ec2.CreateCustomerGatewayInput{
+		BgpAsn:   aws.Int64(spec.BGPASN),
+		PublicIp: aws.String(spec.IPAddress),
+		Type:     aws.String(ec2.GatewayTypeIpsec1),
+	}
+	if spec.DeviceName != "" {
+		input.DeviceName = aws.String(spec.DeviceName)
+	}
+	out, err := ctx.EC2().CreateCustomerGateway(input)
+	if err != nil {
+		return "", fmt.Errorf("Error creating customer gateway for %s: %s", spec.IPAddress, err)
+	}
+	id := aws.StringValue(out.CustomerGateway.CustomerGatewayId)
+	ctx.Log("Created customer gateway %s for %s", id, spec.IPAddress)
+	err = ctx.SetNameAndAutomated(id, name)
+	if err != nil {
+		return id, fmt.Errorf("Error tagging customer gateway %s: %s", id, err)
+	}
+
+	startedWaiting := ctx.clock().Now()
+	maxWaitTime := time.Second * 300
+	for {
+		out, err := ctx.EC2().DescribeCustomerGateways(&ec2.DescribeCustomerGatewaysInput{
+			CustomerGatewayIds: []*string{aws.String(id)},
+		})
+		if err != nil {
+			return id, fmt.Errorf("Error describing customer gateway %s: %s", id, err)
+		}
+		if len(out.CustomerGateways) == 1 && aws.StringValue(out.CustomerGateways[0].State) == "available" {
+			return id, nil
+		}
+		if ctx.clock().Since(startedWaiting) > maxWaitTime {
+			return id, fmt.Errorf("Timed out waiting for customer gateway %s", id)
+		}
+		ctx.clock().Sleep(1 * time.Second)
+	}
+}
+
+func (ctx *Context) DeleteCustomerGateway(id string) error {
+	_, err := ctx.EC2().DeleteCustomerGateway(&ec2.DeleteCustomerGatewayInput{
+		CustomerGatewayId: aws.String(id),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidCustomerGatewayID.NotFound" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error deleting customer gateway %s: %s", id, err)
+	}
+	ctx.Log("Deleted customer gateway %s", id)
+	return nil
+}
+
+// GetVPNConnection returns nil if AWS doesn't know about the connection.
+// Deleted connections are still returned for a while after deletion.
+func (ctx *Context) GetVPNConnection(id string) (*ec2.VpnConnection, error) {
+	out, err := ctx.EC2().DescribeVpnConnections(&ec2.DescribeVpnConnectionsInput{
+		VpnConnectionIds: []*string{aws.String(id)},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidVpnConnectionID.NotFound" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error describing VPN connection %s: %s", id, err)
+	}
+	if len(out.VpnConnections) == 0 {
+		return nil, nil
+	}
+	return out.VpnConnections[0], nil
+}
+
+func (ctx *Context) WaitForVPNConnectionState(id string, waitingFor, validWhileWaiting []string) (*ec2.VpnConnection, error) {
+	startedWaiting := ctx.clock().Now()
+	maxWaitTime := time.Second * 900
+	for {
+		vpn, err := ctx.GetVPNConnection(id)
+		if err != nil {
+			return nil, err
+		}
+		state := ec2.VpnStateDeleted
+		if vpn != nil {
+			state = aws.StringValue(vpn.State)
+		}
+		if stringInSlice(state, waitingFor) {
+			return vpn, nil
+		} else if !stringInSlice(state, validWhileWaiting) {
+			return vpn, fmt.Errorf("Unexpected state %s for VPN connection %s", state, id)
+		}
+		if ctx.clock().Since(startedWaiting) > maxWaitTime {
+			return nil, fmt.Errorf("Timed out waiting for VPN connection %s", id)
+		}
+		ctx.clock().Sleep(5 * time.Second)
+	}
+}
+
+// CreateVPNConnection creates a VPN connection on the transit gateway and
+// waits for it to be available.
+func (ctx *Context) CreateVPNConnection(customerGatewayID, transitGatewayID string, staticRoutesOnly bool, tunnels []*database.VPNTunnelOptions, name string) (*ec2.VpnConnection, error) {
+	options := &ec2.VpnConnectionOptionsSpecification{
+		StaticRoutesOnly: aws.Bool(staticRoutesOnly),
+	}
+	for _, tunnel := range tunnels {
+		options.TunnelOptions = append(options.TunnelOptions, vpnTunnelOptionsSpecification(tunnel))
+	}
+	out, err := ctx.EC2().CreateVpnConnection(&ec2.CreateVpnConnectionInput{
+		CustomerGatewayId: aws.String(customerGatewayID),
+		TransitGatewayId:  aws.String(transitGatewayID),
+		Type:              aws.String(ec2.GatewayTypeIpsec1),
+		Options:           options,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error creating VPN connection on transit gateway %s: %s", transitGatewayID, err)
+	}
+	id := aws.StringValue(out.VpnConnection.VpnConnectionId)
+	ctx.Log("Created VPN connection %s on transit gateway %s", id, transitGatewayID)
+	err = ctx.SetNameAndAutomated(id, name)
+	if err != nil {
+		return out.VpnConnection, fmt.Errorf("Error tagging VPN connection %s: %s", id, err)
+	}
+	vpn, err := ctx.WaitForVPNConnectionState(id, []string{ec2.VpnStateAvailable}, []string{ec2.VpnStatePending})
+	if err != nil {
+		return out.VpnConnection, err
+	}
+	return vpn, nil
+}
+
+// ReplaceVPNConnectionCustomerGateway points the VPN connection at a new
+// customer gateway, which re-establishes both tunnels.
+func (ctx *Context) ReplaceVPNConnectionCustomerGateway(id, customerGatewayID string) error {
+	_, err := ctx.EC2().ModifyVpnConnection(&ec2.ModifyVpnConnectionInput{
+		VpnConnectionId:   aws.String(id),
+		CustomerGatewayId: aws.String(customerGatewayID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error changing customer gateway of VPN connection %s: %s", id, err)
+	}
+	ctx.Log("Changed customer gateway of VPN connection %s to %s", id, customerGatewayID)
+	_, err = ctx.WaitForVPNConnectionState(id, []string{ec2.VpnStateAvailable}, []string{ec2.VpnStatePending, "modifying"})
+	return err
+}
+
+func (ctx *Context) ModifyVPNTunnelOptions(id, outsideIPAddress string, opts *database.VPNTunnelOptions) error {
+	spec := vpnTunnelOptionsSpecification(opts)
+	_, err := ctx.EC2().ModifyVpnTunnelOptions(&ec2.ModifyVpnTunnelOptionsInput{
+		VpnConnectionId:           aws.String(id),
+		VpnTunnelOutsideIpAddress: aws.String(outsideIPAddress),
+		TunnelOptions: &ec2.ModifyVpnTunnelOptionsSpecification{
+			TunnelInsideCidr: spec.TunnelInsideCidr,
+			IKEVersions:      spec.IKEVersions,
+			DPDTimeoutAction: spec.DPDTimeoutAction,
+			StartupAction:    spec.StartupAction,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Error modifying tunnel %s of VPN connection %s: %s", outsideIPAddress, id, err)
+	}
+	ctx.Log("Modified tunnel %s of VPN connection %s", outsideIPAddress, id)
+	_, err = ctx.WaitForVPNConnectionState(id, []string{ec2.VpnStateAvailable}, []string{ec2.VpnStatePending, "modifying"})
+	return err
+}
+
+// DeleteVPNConnection deletes the VPN connection and waits for it to be
+// gone so that its customer gateway can be deleted.
+func (ctx *Context) DeleteVPNConnection(id string) error {
+	_, err := ctx.EC2().DeleteVpnConnection(&ec2.DeleteVpnConnectionInput{
+		VpnConnectionId: aws.String(id),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidVpnConnectionID.NotFound" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error deleting VPN connection %s: %s", id, err)
+	}
+	ctx.Log("Deleted VPN connection %s", id)
+	_, err = ctx.WaitForVPNConnectionState(id, []string{ec2.VpnStateDeleted}, []string{ec2.VpnStateAvailable, ec2.VpnStateDeleting})
+	return err
+}
+
+// GetVPNTransitGatewayAttachmentID returns the attachment that AWS created
+// on the transit gateway for a VPN connection.
+func (ctx *Context) GetVPNTransitGatewayAttachmentID(vpnConnectionID string) (string, error) {
+	out, err := ctx.EC2().DescribeTransitGatewayAttachments(&ec2.DescribeTransitGatewayAttachmentsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("resource-id"),
+				Values: []*string{aws.String(vpnConnectionID)},
+			},
+			{
+				Name:   aws.String("resource-type"),
+				Values: []*string{aws.String(ec2.TransitGatewayAttachmentResourceTypeVpn)},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error finding transit gateway attachment for VPN connection %s: %s", vpnConnectionID, err)
+	}
+	if len(out.TransitGatewayAttachments) != 1 {
+		return "", fmt.Errorf("Expected 1 transit gateway attachment for VPN connection %s but found %d", vpnConnectionID, len(out.TransitGatewayAttachments))
+	}
+	return aws.StringValue(out.TransitGatewayAttachments[0].TransitGatewayAttachmentId), nil
+}
+
+func (ctx *Context) CreateTransitGatewayRoute(routeTableID, attachmentID, cidr string) error {
+	_, err := ctx.EC2().CreateTransitGatewayRoute(&ec2.CreateTransitGatewayRouteInput{
+		DestinationCidrBlock:       aws.String(cidr),
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+		TransitGatewayAttachmentId: aws.String(attachmentID),
+	})
+	if err != nil {
+		return fmt.Errorf("Error adding route %s to transit gateway route table %s: %s", cidr, routeTableID, err)
+	}
+	ctx.Log("Added route %s to %s in transit gateway route table %s", cidr, attachmentID, routeTableID)
+	return nil
+}
+
+func (ctx *Context) DeleteTransitGatewayRoute(routeTableID, cidr string) error {
+	_, err := ctx.EC2().DeleteTransitGatewayRoute(&ec2.DeleteTransitGatewayRouteInput{
+		DestinationCidrBlock:       aws.String(cidr),
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidRoute.NotFound" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error deleting route %s from transit gateway route table %s: %s", cidr, routeTableID, err)
+	}
+	ctx.Log("Deleted route %s from transit gateway route table %s", cidr, routeTableID)
+	return nil
+}
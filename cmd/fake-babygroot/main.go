@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet/fake"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+func main() {
+	listen := flag.String("listen", "localhost:8086", "address to listen on")
+	apiID := flag.String("api-id", "fake-babygroot", "expected x-apigw-api-id header")
+	steps := flag.Int("steps", 3, "number of reads before a request or deletion finishes")
+	regions := flag.String("regions", "us-east-1,us-west-2", "comma-separated regions to include in the printed CMSNET_CONFIG")
+	tgwAccountID := flag.String("tgw-account-id", "123456789012", "TGWAccountID to include in the printed CMSNET_CONFIG")
+	flag.Parse()
+
+	if *steps < 1 {
+		fmt.Fprintf(os.Stderr, "-steps must be at least 1\n")
+		os.Exit(2)
+	}
+
+	server := fake.NewServer(*apiID)
+	server.Steps = *steps
+
+	config := cmsnet.Config{}
+	for _, region := range strings.Split(*regions, ",") {
+		config[database.Region(strings.TrimSpace(region))] = &cmsnet.RegionConfig{
+			BaseURL:      fmt.Sprintf("http://%s/", *listen),
+			APIID:        *apiID,
+			TGWAccountID: *tgwAccountID,
+		}
+	}
+	buf, err := json.Marshal(config)
+	if err != nil {
+		log.Fatalf("Error marshalling config: %s", err)
+	}
+	log.Printf("CMSNET_CONFIG='%s'", buf)
+
+	log.Printf("Listening on %s", *listen)
+	log.Fatal(http.ListenAndServe(*listen, server))
+}
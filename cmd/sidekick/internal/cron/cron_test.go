@@ -0,0 +1,64 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@often",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected an error parsing %q", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	start := time.Date(2021, time.March, 10, 14, 37, 20, 0, time.UTC) // a Wednesday
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2021, time.March, 10, 14, 38, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, time.March, 10, 14, 45, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2021, time.March, 11, 2, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2021, time.March, 10, 15, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2021, time.March, 11, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * sat,7", time.Date(2021, time.March, 13, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match
+		{"0 0 15 * fri", time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC)},
+		// Day of week stepped rather than *: still restricts
+		{"0 0 1 * */7", time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tc := range testCases {
+		schedule, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.expr, err)
+			continue
+		}
+		next := schedule.Next(start)
+		if !next.Equal(tc.expected) {
+			t.Errorf("%s: expected next run at %s but got %s", tc.expr, tc.expected, next)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package database
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -165,7 +167,7 @@ func initialSchema(db *sqlx.DB) error {
 	return nil
 }
 
-const targetMigrationIdx int = 3
+const targetMigrationIdx int = 5
 
 // These first few migrations are mostly to test additions and unwinding
 func getMigrations() []migration {
@@ -215,5 +217,139 @@ func getMigrations() []migration {
 				return err
 			},
 		},
+		&stringMigration{ // 4
+			do: []string{
+				`CREATE TABLE jobs (
+					id serial PRIMARY KEY,
+					name varchar UNIQUE NOT NULL,
+					schedule varchar NOT NULL,
+					task_types bigint NOT NULL,
+					verify_spec jsonb NOT NULL DEFAULT '{}',
+					selector jsonb NOT NULL DEFAULT '{}',
+					is_enabled boolean NOT NULL DEFAULT true,
+					created_at timestamp with time zone DEFAULT current_timestamp
+				)`,
+				`ALTER TABLE tasks
+					ADD COLUMN job_id int REFERENCES jobs (id) ON DELETE CASCADE,
+					ADD COLUMN vpc_count int NOT NULL DEFAULT 0,
+					ADD COLUMN failed_count int NOT NULL DEFAULT 0,
+					ADD COLUMN issues_before int,
+					ADD COLUMN issues_found int,
+					ADD COLUMN issues_fixed int,
+					ADD COLUMN error text`,
+				// A job run is claimed before its batch task is submitted
+				`ALTER TABLE tasks ALTER COLUMN batch_task_id DROP NOT NULL`,
+				// At most one incomplete run per job
+				`CREATE UNIQUE INDEX tasks_incomplete_job_id ON tasks (job_id) WHERE completed_at IS NULL`,
+			},
+			undo: []string{
+				`DROP INDEX IF EXISTS tasks_incomplete_job_id`,
+				`DELETE FROM tasks WHERE batch_task_id IS NULL`,
+				`ALTER TABLE tasks ALTER COLUMN batch_task_id SET NOT NULL`,
+				`ALTER TABLE tasks
+					DROP COLUMN IF EXISTS job_id,
+					DROP COLUMN IF EXISTS vpc_count,
+					DROP COLUMN IF EXISTS failed_count,
+					DROP COLUMN IF EXISTS issues_before,
+					DROP COLUMN IF EXISTS issues_found,
+					DROP COLUMN IF EXISTS issues_fixed,
+					DROP COLUMN IF EXISTS error`,
+				`DROP TABLE jobs`,
+			},
+		},
+		&funcMigration{ // 5
+			do: migrateJobSelectors,
+			undo: func(tx *sqlx.Tx) error {
+				for _, sql := range []string{
+					`ALTER TABLE jobs ADD COLUMN selector jsonb NOT NULL DEFAULT '{}'`,
+					// Expressions can't be turned back into lists, so rather than
+					// run on every VPC, jobs with a selector are disabled
+					`UPDATE jobs SET is_enabled = false WHERE selector_expression != '' OR selector_name != ''`,
+					`ALTER TABLE jobs DROP COLUMN selector_expression, DROP COLUMN selector_name`,
+				} {
+					_, err := tx.Exec(sql)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
 	}
 }
+
+// legacySelector is how jobs selected VPCs before they used vpc-conf
+// selector expressions
+type legacySelector struct {
+	Labels     []string
+	Stacks     []string
+	Regions    []string
+	AccountIDs []string
+}
+
+// expression returns the selector expression that matches the same VPCs
+func (ls *legacySelector) expression() string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	terms := []string{}
+	add := func(field string, values []string) {
+		alternatives := []string{}
+		for _, value := range values {
+			alternatives = append(alternatives, field+`="`+escape.Replace(value)+`"`)
+		}
+		if len(alternatives) == 1 {
+			terms = append(terms, alternatives[0])
+		} else if len(alternatives) > 1 {
+			terms = append(terms, "("+strings.Join(alternatives, " OR ")+")")
+		}
+	}
+	add("label", ls.Labels)
+	add("stack", ls.Stacks)
+	add("region", ls.Regions)
+	add("account", ls.AccountIDs)
+	return strings.Join(terms, " AND ")
+}
+
+// migrateJobSelectors replaces each job's lists of labels, stacks, regions
+// and accounts with the equivalent selector expression
+func migrateJobSelectors(tx *sqlx.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE jobs
+		ADD COLUMN selector_expression text NOT NULL DEFAULT '',
+		ADD COLUMN selector_name text NOT NULL DEFAULT ''`)
+	if err != nil {
+		return err
+	}
+
+	selectors := make(map[int][]byte)
+	rows, err := tx.Query(`SELECT id, selector FROM jobs`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var selector []byte
+		err := rows.Scan(&id, &selector)
+		if err != nil {
+			return err
+		}
+		selectors[id] = selector
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, selector := range selectors {
+		ls := &legacySelector{}
+		err := json.Unmarshal(selector, ls)
+		if err != nil {
+			return fmt.Errorf("Error unmarshaling selector of job %d: %s", id, err)
+		}
+		_, err = tx.Exec(`UPDATE jobs SET selector_expression = $2 WHERE id = $1`, id, ls.expression())
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`ALTER TABLE jobs DROP COLUMN selector`)
+	return err
+}
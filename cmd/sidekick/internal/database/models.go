@@ -1,8 +1,11 @@
 package database
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
+	vpcconf "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -13,7 +16,8 @@ type Migration struct {
 	AppliedAt      time.Time `json:"applied_at"`
 }
 
-// Task is the internal representation and tracking of initiated tasks
+// Task is the internal representation and tracking of initiated tasks. Tasks
+// started by a job are that job's run history.
 type Task struct {
 	ID            int        `json:"id"`
 	BatchTaskID   int        `json:"batch_task_id"`
@@ -21,6 +25,42 @@ type Task struct {
 	CompletedAt   *time.Time `json:"completed_at"`
 	InitiatedAt   time.Time  `json:"initiated_at"`
 	Success       bool       `json:"success"`
+	JobID         *int       `json:"job_id"`
+	VPCCount      int        `json:"vpc_count"`
+	FailedCount   int        `json:"failed_count"`
+	IssuesBefore  *int       `json:"issues_before"`
+	IssuesFound   *int       `json:"issues_found"`
+	IssuesFixed   *int       `json:"issues_fixed"`
+	Error         *string    `json:"error"`
+}
+
+// Job is a batch task run on a cron schedule
+type Job struct {
+	ID         int                `json:"id"`
+	Name       string             `json:"name"`
+	Schedule   string             `json:"schedule"`
+	TaskTypes  vpcconf.TaskTypes  `json:"task_types"`
+	VerifySpec vpcconf.VerifySpec `json:"verify_spec"`
+	// At most one of a vpc-conf selector expression or the name of a
+	// selector saved in vpc-conf picks the automated VPCs the job runs
+	// against. With neither, it runs against all of them.
+	Selector     string    `json:"selector"`
+	SelectorName string    `json:"selector_name"`
+	IsEnabled    bool      `json:"is_enabled"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (j *Job) HasSelector() bool {
+	return j.Selector != "" || j.SelectorName != ""
+}
+
+// RunResult is what a job run found once its batch task finished
+type RunResult struct {
+	Success     bool
+	FailedCount int
+	IssuesFound *int
+	IssuesFixed *int
+	Error       string
 }
 
 type Models interface {
@@ -28,7 +68,18 @@ type Models interface {
 	GetAllTasks() ([]*Task, error)
 	GetTaskByID(id int) (*Task, error)
 	GetIncompleteTasks() ([]*Task, error)
-	CompleteTask(task *Task, success bool) error
+	CompleteTask(taskID int, success bool) error
+
+	SaveJob(job *Job) (*int, error)
+	GetJobs() ([]*Job, error)
+	GetJobByName(name string) (*Job, error)
+	SetJobEnabled(name string, enabled bool) error
+	DeleteJob(name string) error
+
+	ClaimJobRun(jobID int) (*int, error)
+	SetJobRunBatchTask(taskID, batchTaskID int, batchTaskName string, vpcCount int, issuesBefore *int) error
+	CompleteJobRun(taskID int, result *RunResult) error
+	GetJobRuns(jobID, limit int) ([]*Task, error)
 }
 
 type SQLModels struct {
@@ -75,28 +126,43 @@ func (s *SQLModels) CreateTask(batchTaskID int, batchTaskName string) (*int, err
 	return taskID, nil
 }
 
+// batch_task_id is NULL while a job run is being submitted
+const taskColumns = `id, COALESCE(batch_task_id, 0), batch_task_name, completed_at, initiated_at, success,
+	job_id, vpc_count, failed_count, issues_before, issues_found, issues_fixed, error`
+
+func (t *Task) fields() []interface{} {
+	return []interface{}{
+		&t.ID, &t.BatchTaskID, &t.BatchTaskName, &t.CompletedAt, &t.InitiatedAt, &t.Success,
+		&t.JobID, &t.VPCCount, &t.FailedCount, &t.IssuesBefore, &t.IssuesFound, &t.IssuesFixed, &t.Error,
+	}
+}
+
 func (s *SQLModels) GetAllTasks() ([]*Task, error) {
 	tasks := []*Task{}
 
-	rows, err := s.DB.Queryx(`SELECT id, batch_task_id, batch_task_name, completed_at, initiated_at, success FROM tasks ORDER BY id DESC`)
+	rows, err := s.DB.Queryx(`SELECT ` + taskColumns + ` FROM tasks ORDER BY id DESC`)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		t := &Task{}
-		rows.Scan(&t.ID, &t.BatchTaskID, &t.BatchTaskName, &t.CompletedAt, &t.InitiatedAt, &t.Success)
+		err := rows.Scan(t.fields()...)
+		if err != nil {
+			return nil, err
+		}
 		tasks = append(tasks, t)
 	}
 
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 func (s *SQLModels) GetTaskByID(id int) (*Task, error) {
 	task := &Task{}
 
-	q := `SELECT id, batch_task_id, batch_task_name, completed_at, initiated_at, success FROM tasks WHERE id = $1`
-	err := s.DB.QueryRow(q, id).Scan(&task.ID, &task.BatchTaskID, &task.BatchTaskName, &task.CompletedAt, &task.InitiatedAt, &task.Success)
+	q := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1`
+	err := s.DB.QueryRow(q, id).Scan(task.fields()...)
 	if err != nil {
 		return nil, err
 	}
@@ -108,18 +174,22 @@ func (s *SQLModels) GetTaskByID(id int) (*Task, error) {
 func (s *SQLModels) GetIncompleteTasks() ([]*Task, error) {
 	tasks := []*Task{}
 
-	rows, err := s.DB.Queryx(`SELECT id, batch_task_id, batch_task_name, completed_at, initiated_at, success FROM tasks WHERE completed_at IS NULL ORDER BY id ASC`)
+	rows, err := s.DB.Queryx(`SELECT ` + taskColumns + ` FROM tasks WHERE completed_at IS NULL ORDER BY id ASC`)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		t := &Task{}
-		rows.Scan(&t.ID, &t.BatchTaskID, &t.BatchTaskName, &t.CompletedAt, &t.InitiatedAt, &t.Success)
+		err := rows.Scan(t.fields()...)
+		if err != nil {
+			return nil, err
+		}
 		tasks = append(tasks, t)
 	}
 
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 // CompleteTask sets completed_at to the current time and the success flag for the given taskID
@@ -132,3 +202,154 @@ func (s *SQLModels) CompleteTask(taskID int, success bool) error {
 
 	return err
 }
+
+const jobColumns = `id, name, schedule, task_types, verify_spec, selector_expression, selector_name, is_enabled, created_at`
+
+func scanJob(row interface{ Scan(...interface{}) error }) (*Job, error) {
+	job := &Job{}
+	var verifySpec []byte
+	err := row.Scan(&job.ID, &job.Name, &job.Schedule, &job.TaskTypes, &verifySpec, &job.Selector, &job.SelectorName, &job.IsEnabled, &job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(verifySpec, &job.VerifySpec)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// SaveJob creates the job, or replaces the schedule, task types, verify spec
+// and selector of the job with the same name, and returns its ID
+func (s *SQLModels) SaveJob(job *Job) (*int, error) {
+	verifySpec, err := json.Marshal(job.VerifySpec)
+	if err != nil {
+		return nil, err
+	}
+	var jobID *int
+
+	err = s.DB.Get(&jobID, `INSERT INTO jobs (name, schedule, task_types, verify_spec, selector_expression, selector_name)
+							VALUES ($1, $2, $3, $4, $5, $6)
+							ON CONFLICT (name) DO UPDATE SET
+								schedule = EXCLUDED.schedule,
+								task_types = EXCLUDED.task_types,
+								verify_spec = EXCLUDED.verify_spec,
+								selector_expression = EXCLUDED.selector_expression,
+								selector_name = EXCLUDED.selector_name
+							RETURNING id`,
+		job.Name, job.Schedule, job.TaskTypes, verifySpec, job.Selector, job.SelectorName)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobID, nil
+}
+
+// GetJobs returns every job, enabled or not, ordered by name
+func (s *SQLModels) GetJobs() ([]*Job, error) {
+	jobs := []*Job{}
+
+	rows, err := s.DB.Query(`SELECT ` + jobColumns + ` FROM jobs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (s *SQLModels) GetJobByName(name string) (*Job, error) {
+	return scanJob(s.DB.QueryRow(`SELECT `+jobColumns+` FROM jobs WHERE name = $1`, name))
+}
+
+func (s *SQLModels) SetJobEnabled(name string, enabled bool) error {
+	return expectOneRow(s.DB.Exec(`UPDATE jobs SET is_enabled = $2 WHERE name = $1`, name, enabled))
+}
+
+// DeleteJob deletes the job and its run history
+func (s *SQLModels) DeleteJob(name string) error {
+	return expectOneRow(s.DB.Exec(`DELETE FROM jobs WHERE name = $1`, name))
+}
+
+func expectOneRow(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ClaimJobRun records the start of a run of the job and returns its task ID,
+// or nil if the job's previous run has not completed yet
+func (s *SQLModels) ClaimJobRun(jobID int) (*int, error) {
+	var taskID *int
+
+	err := s.DB.Get(&taskID, `INSERT INTO tasks (job_id, batch_task_name)
+							  SELECT id, name FROM jobs WHERE id = $1
+							  ON CONFLICT (job_id) WHERE completed_at IS NULL DO NOTHING
+							  RETURNING id`, jobID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return taskID, nil
+}
+
+// SetJobRunBatchTask records the batch task submitted for a claimed job run
+func (s *SQLModels) SetJobRunBatchTask(taskID, batchTaskID int, batchTaskName string, vpcCount int, issuesBefore *int) error {
+	_, err := s.DB.Exec(`UPDATE tasks SET batch_task_id = $2, batch_task_name = $3, vpc_count = $4, issues_before = $5 WHERE id = $1`,
+		taskID, batchTaskID, batchTaskName, vpcCount, issuesBefore)
+
+	return err
+}
+
+// CompleteJobRun sets completed_at to the current time and records the result of the run
+func (s *SQLModels) CompleteJobRun(taskID int, result *RunResult) error {
+	var errorText *string
+	if result.Error != "" {
+		errorText = &result.Error
+	}
+	_, err := s.DB.Exec(`UPDATE tasks SET completed_at = NOW(), success = $2, failed_count = $3, issues_found = $4, issues_fixed = $5, error = $6 WHERE id = $1`,
+		taskID, result.Success, result.FailedCount, result.IssuesFound, result.IssuesFixed, errorText)
+
+	return err
+}
+
+// GetJobRuns returns the job's most recent runs, newest first
+func (s *SQLModels) GetJobRuns(jobID, limit int) ([]*Task, error) {
+	tasks := []*Task{}
+
+	rows, err := s.DB.Queryx(`SELECT `+taskColumns+` FROM tasks WHERE job_id = $1 ORDER BY id DESC LIMIT $2`, jobID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t := &Task{}
+		err := rows.Scan(t.fields()...)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
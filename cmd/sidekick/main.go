@@ -1,16 +1,20 @@
 package main
 
 import (
+	"database/sql"
 	"flag"
 	"fmt"
 	"log"
 	"os"
-	"reflect"
-	"strings"
-	"sync"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmd/sidekick/internal/conf"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmd/sidekick/internal/cron"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmd/sidekick/internal/database"
 	vpcconf "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/vpcconfapi"
@@ -22,135 +26,301 @@ const (
 	exitBadConfiguration = iota + 1
 	exitVPCConfAPIError
 	exitDBError
+	exitUsage
+	exitCommandFailed
 )
 
-func observeProgress(sql *database.SQLModels, task *database.Task, vpcConfAPI *vpcconfapi.VPCConfAPI, wg *sync.WaitGroup) {
-	defer wg.Done()
-	lastResponse := &vpcconfapi.BatchTaskInfo{}
+type command struct {
+	usage string
+	// needsAPI commands get a logged in vpc-conf API client
+	needsAPI bool
+	run      func(c *commandContext, args []string) error
+}
 
-	for range time.Tick(1 * time.Second) {
-		batchTaskInfo, err := vpcConfAPI.GetBatchTaskByID(task.BatchTaskID)
-		if err != nil {
-			log.Printf("Failed to fetch batch task info from vpc-conf: %s", err)
-			err = sql.CompleteTask(task.ID, false)
-			if err != nil {
-				log.Printf("Failed to mark errored Task %d as as complete: %s", task.ID, err)
-			}
-			return
-		}
+type commandContext struct {
+	models database.Models
+	api    *vpcconfapi.VPCConfAPI
+}
 
-		if !reflect.DeepEqual(*lastResponse, *batchTaskInfo) {
-			*lastResponse = *batchTaskInfo
+var commands = map[string]*command{
+	"run": {
+		usage:    "run [-interval=30s] - run jobs on their schedules until interrupted (the default)",
+		needsAPI: true,
+		run:      runScheduler,
+	},
+	"jobs": {
+		usage: "jobs - list the jobs and when they next run",
+		run:   listJobs,
+	},
+	"set-job": {
+		usage:    "set-job -name= -schedule= -tasks= [-verify=all] [-selector= | -selector-name=] - create or replace a job",
+		needsAPI: true,
+		run:      setJob,
+	},
+	"enable-job": {
+		usage: "enable-job <name>",
+		run:   func(c *commandContext, args []string) error { return setJobEnabled(c, args, true) },
+	},
+	"disable-job": {
+		usage: "disable-job <name>",
+		run:   func(c *commandContext, args []string) error { return setJobEnabled(c, args, false) },
+	},
+	"delete-job": {
+		usage: "delete-job <name> - delete a job and its run history",
+		run:   deleteJob,
+	},
+	"run-job": {
+		usage:    "run-job <name> - run a job now and wait for it to finish",
+		needsAPI: true,
+		run:      runJob,
+	},
+	"history": {
+		usage: "history [-n=20] <name> - show a job's recent runs",
+		run:   showHistory,
+	},
+}
 
-			progress := batchTaskInfo.GetBatchTaskProgress()
+func usage() {
+	log.Println("USAGE: sidekick [command] [flags]")
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		log.Printf("\t%s", commands[name].usage)
+	}
+	log.Println("EXAMPLE: sidekick set-job -name=nightly-verify -schedule='0 2 * * *' -tasks=verify -selector='stack=dev OR stack=test'")
+}
 
-			log.Printf("[Task %d] %s %d - %s", task.ID, task.BatchTaskName, task.BatchTaskID, progress)
+func runScheduler(c *commandContext, args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	interval := flags.Duration("interval", 30*time.Second, "how often to check for due jobs")
+	flags.Parse(args)
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Printf("Stopping; runs in progress will be resumed on the next start")
+		close(stop)
+	}()
+
+	return newScheduler(c.models, c.api).loop(*interval, stop)
+}
 
-			if progress.Remaining() == 0 {
-				err := sql.CompleteTask(task.ID, progress.Failed == 0)
-				if err != nil {
-					log.Printf("Unable to mark Task %d as complete, will attempt on next invocation", task.ID)
-				}
-				break
-			}
-		}
+// validateSelector resolves the job's selector against the automated VPCs
+// vpc-conf knows about now, so a typo is caught when the job is saved
+func validateSelector(api vpcConfAPI, job *database.Job) error {
+	if job.Selector != "" && job.SelectorName != "" {
+		return fmt.Errorf("Only one of -selector and -selector-name can be given")
 	}
+	if !job.HasSelector() {
+		return nil
+	}
+	resolved, err := api.SelectVPCs(job.SelectorName, job.Selector)
+	if err != nil {
+		return err
+	}
+	log.Printf("The selector matches %d VPCs now", len(resolved.VPCs))
+	return nil
 }
 
-func resumeIncompleteTasks(sql *database.SQLModels, vpcConfAPI *vpcconfapi.VPCConfAPI, wg *sync.WaitGroup) bool {
-	incompleteTasks, err := sql.GetIncompleteTasks()
+func setJob(c *commandContext, args []string) error {
+	flags := flag.NewFlagSet("set-job", flag.ExitOnError)
+	name := flags.String("name", "", "job name")
+	schedule := flags.String("schedule", "", "cron expression, e.g. '0 2 * * *' or @daily")
+	tasks := flags.String("tasks", "verify", "task types - comma separated")
+	verify := flags.String("verify", "all", "what to verify or repair - comma separated")
+	selector := flags.String("selector", "", "only VPCs matching this selector expression, e.g. 'stack=prod AND label:pilot'")
+	selectorName := flags.String("selector-name", "", "only VPCs matching this selector saved in VPC Conf")
+	flags.Parse(args)
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+	if _, err := cron.Parse(*schedule); err != nil {
+		return err
+	}
+	taskTypes, err := vpcconfapi.ParseTaskTypes(*tasks)
 	if err != nil {
-		log.Printf("Unable to fetch incomplete tasks: %s", err)
+		return err
 	}
-
-	if len(incompleteTasks) == 0 {
-		return false
+	if taskTypes.Includes(vpcconf.TaskTypeVerifyState) && taskTypes != vpcconf.TaskTypeVerifyState {
+		return fmt.Errorf("verify must be scheduled as a job of its own")
+	}
+	verifySpec, err := vpcconfapi.ParseVerifySpec(*verify)
+	if err != nil {
+		return err
+	}
+	job := &database.Job{
+		Name:         *name,
+		Schedule:     *schedule,
+		TaskTypes:    taskTypes,
+		VerifySpec:   verifySpec,
+		Selector:     *selector,
+		SelectorName: *selectorName,
+	}
+	err = validateSelector(c.api, job)
+	if err != nil {
+		return err
 	}
 
-	log.Printf("Found %d incomplete tasks", len(incompleteTasks))
-
-	for _, task := range incompleteTasks {
-		wg.Add(1)
-		go observeProgress(sql, task, vpcConfAPI, wg)
+	jobID, err := c.models.SaveJob(job)
+	if err != nil {
+		return err
 	}
+	log.Printf("Saved job %d %s", *jobID, job.Name)
+	return nil
+}
 
-	return true
+func jobName(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("Please provide the job name")
+	}
+	return args[0], nil
 }
 
-func verifyStateTask(vpcs []vpcconfapi.VPC, sql *database.SQLModels, vpcConfAPI *vpcconfapi.VPCConfAPI, wg *sync.WaitGroup) error {
-	batchTaskResult, err := vpcConfAPI.SubmitVerifyBatchTask(vpcs, vpcconf.VerifyAllSpec())
+func setJobEnabled(c *commandContext, args []string, enabled bool) error {
+	name, err := jobName(args)
 	if err != nil {
 		return err
 	}
+	err = c.models.SetJobEnabled(name, enabled)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("No job named %q", name)
+	}
+	return err
+}
 
-	batchTaskInfo, err := vpcConfAPI.GetBatchTaskByID(batchTaskResult.BatchTaskID)
+func deleteJob(c *commandContext, args []string) error {
+	name, err := jobName(args)
 	if err != nil {
 		return err
 	}
+	err = c.models.DeleteJob(name)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("No job named %q", name)
+	}
+	return err
+}
 
-	taskID, err := sql.CreateTask(batchTaskInfo.ID, batchTaskInfo.Description)
+func getJob(c *commandContext, args []string) (*database.Job, error) {
+	name, err := jobName(args)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	job, err := c.models.GetJobByName(name)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("No job named %q", name)
 	}
+	return job, err
+}
 
-	task, err := sql.GetTaskByID(*taskID)
+func runJob(c *commandContext, args []string) error {
+	job, err := getJob(c, args)
 	if err != nil {
 		return err
 	}
-
-	if task.ID != *taskID {
-		return fmt.Errorf("Failed to fetch Task %d", taskID)
+	s := newScheduler(c.models, c.api)
+	started, err := s.start(job)
+	if err != nil {
+		return err
 	}
-
-	wg.Add(1)
-	go observeProgress(sql, task, vpcConfAPI, wg)
-
+	if !started {
+		return fmt.Errorf("Job %s is already running", job.Name)
+	}
+	s.wg.Wait()
 	return nil
 }
 
-func contains(needle string, haystack []string) bool {
-	for _, item := range haystack {
-		if item == needle {
-			return true
-		}
+func describeSelector(job *database.Job) string {
+	if job.SelectorName != "" {
+		return job.SelectorName
+	}
+	if job.Selector != "" {
+		return job.Selector
 	}
-	return false
+	return "all"
 }
 
-func filterVPCs(vpcs []vpcconfapi.VPC, regions []string, stacks []string) []vpcconfapi.VPC {
-	filteredVPCs := []vpcconfapi.VPC{}
-
-	for _, vpc := range vpcs {
-		regionOK := contains(string(vpc.Region), regions)
-		stackOK := contains(vpc.Stack, stacks)
-		if regionOK && stackOK {
-			filteredVPCs = append(filteredVPCs, vpc)
+func listJobs(c *commandContext, args []string) error {
+	jobs, err := c.models.GetJobs()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSCHEDULE\tTASKS\tVERIFY\tVPCS\tENABLED\tNEXT RUN")
+	for _, job := range jobs {
+		next := "-"
+		if schedule, err := cron.Parse(job.Schedule); err != nil {
+			next = "invalid schedule"
+		} else if job.IsEnabled {
+			if t := schedule.Next(time.Now()); !t.IsZero() {
+				next = t.Format(time.RFC3339)
+			}
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", job.Name, job.Schedule, vpcconfapi.FormatTaskTypes(job.TaskTypes), vpcconfapi.FormatVerifySpec(job.VerifySpec), describeSelector(job), job.IsEnabled, next)
 	}
+	return w.Flush()
+}
 
-	return filteredVPCs
+func formatCount(n *int) string {
+	if n == nil {
+		return "-"
+	}
+	return strconv.Itoa(*n)
 }
 
-func validate(input []string, valid []string) error {
-	for _, in := range input {
-		if !contains(in, valid) {
-			return fmt.Errorf("%q is not one of these valid options: %s", in, strings.Join(valid, ", "))
+func showHistory(c *commandContext, args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	limit := flags.Int("n", 20, "number of runs to show")
+	flags.Parse(args)
+
+	job, err := getJob(c, flags.Args())
+	if err != nil {
+		return err
+	}
+	runs, err := c.models.GetJobRuns(job.ID, *limit)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tCOMPLETED\tBATCH TASK\tVPCS\tFAILED\tISSUES FOUND\tISSUES FIXED\tRESULT")
+	for _, run := range runs {
+		completed := "-"
+		result := "running"
+		if run.CompletedAt != nil {
+			completed = run.CompletedAt.Format(time.RFC3339)
+			result = "success"
+			if !run.Success {
+				result = "failed"
+			}
+			if run.Error != nil {
+				result += ": " + *run.Error
+			}
 		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\n", run.InitiatedAt.Format(time.RFC3339), completed, run.BatchTaskID, run.VPCCount, run.FailedCount, formatCount(run.IssuesFound), formatCount(run.IssuesFixed), result)
 	}
-	return nil
+	return w.Flush()
 }
 
 func main() {
-	flag.Usage = func() {
-		log.Println("USAGE: sidekick [-regions=] [-stacks=]")
-		log.Println("EXAMPLE: sidekick -regions=us-east-1,use-west-2 -stacks=sandbox,dev,test")
-	}
-
-	flagStacks := flag.String("stacks", "", "override target stacks - comma separated")
-	flagRegions := flag.String("regions", "", "overide target regions - comma separated")
-
+	flag.Usage = usage
 	flag.Parse()
 
+	args := flag.Args()
+	name := "run"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(exitUsage)
+	}
+
 	conf := &conf.Conf{}
 	result := conf.Load()
 	if !result {
@@ -158,76 +328,35 @@ func main() {
 		os.Exit(exitBadConfiguration)
 	}
 
-	db := sqlx.MustConnect("postgres", conf.DBConnect)
-	err := database.Migrate(db)
+	db, err := sqlx.Connect("postgres", conf.DBConnect)
 	if err != nil {
-		log.Panicf("Failed to migrate database: %s", err)
-	}
-
-	sql := &database.SQLModels{DB: db}
-
-	vpcConfAPI := &vpcconfapi.VPCConfAPI{
-		Username: conf.Username,
-		Password: conf.Password,
-		BaseURL:  conf.VPCConfBaseURL,
+		log.Printf("Failed to connect to database: %s", err)
+		os.Exit(exitDBError)
 	}
-
-	err = vpcConfAPI.VerifySession()
+	err = database.Migrate(db)
 	if err != nil {
-		log.Printf("Failed to authenticate to VPC Conf: %s", err)
-		os.Exit(exitVPCConfAPIError)
-	}
-
-	var wg sync.WaitGroup
-
-	if resumeIncompleteTasks(sql, vpcConfAPI, &wg) {
-		wg.Wait()
-		os.Exit(0)
-	}
-
-	automatedVPCs, automatedRegions, err := vpcConfAPI.GetAutomatedVPCsAndRegions()
-	if err != nil {
-		log.Println(err)
-		os.Exit(exitVPCConfAPIError)
-	}
-	if len(automatedVPCs) == 0 {
-		log.Printf("VPC Conf returned 0 VPCs")
-		os.Exit(exitVPCConfAPIError)
+		log.Panicf("Failed to migrate database: %s", err)
 	}
 
-	validRegions := []string{"us-east-1", "us-west-2", "us-gov-west-1"}
-	validStacks := []string{"sandbox", "dev", "test", "impl", "prod"}
-	var regions = automatedRegions
-	var stacks = validStacks
+	c := &commandContext{models: &database.SQLModels{DB: db}}
 
-	if *flagStacks != "" {
-		stacks = strings.Split(*flagStacks, ",")
-		err = validate(stacks, validStacks)
-		if err != nil {
-			log.Fatal(err)
+	if cmd.needsAPI {
+		c.api = &vpcconfapi.VPCConfAPI{
+			Username: conf.Username,
+			Password: conf.Password,
+			BaseURL:  conf.VPCConfBaseURL,
 		}
-	}
-	if *flagRegions != "" {
-		regions = strings.Split(*flagRegions, ",")
-		err = validate(regions, validRegions)
+
+		err = c.api.VerifySession()
 		if err != nil {
-			log.Fatal(err)
+			log.Printf("Failed to authenticate to VPC Conf: %s", err)
+			os.Exit(exitVPCConfAPIError)
 		}
 	}
 
-	filteredVPCs := filterVPCs(automatedVPCs, regions, stacks)
-
-	if len(filteredVPCs) == 0 {
-		log.Println("No VPCs remain after filtering for region and stack:")
-		log.Printf("\tRegions: %s", strings.Join(regions, ", "))
-		log.Printf("\tStacks: %s", strings.Join(stacks, ", "))
-		os.Exit(0)
-	}
-
-	err = verifyStateTask(filteredVPCs, sql, vpcConfAPI, &wg)
+	err = cmd.run(c, args)
 	if err != nil {
 		log.Println(err)
+		os.Exit(exitCommandFailed)
 	}
-
-	wg.Wait()
 }
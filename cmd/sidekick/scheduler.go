@@ -0,0 +1,324 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmd/sidekick/internal/cron"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmd/sidekick/internal/database"
+	vpcconf "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/vpcconfapi"
+)
+
+// vpcConfAPI is the part of vpcconfapi.VPCConfAPI the scheduler uses
+type vpcConfAPI interface {
+	GetAutomatedVPCsAndRegions() ([]vpcconfapi.VPC, []string, error)
+	SelectVPCs(name, expression string) (*vpcconf.BatchTaskSelector, error)
+	SubmitBatchTask(batch *vpcconfapi.BatchTaskRequest) (*vpcconfapi.BatchTaskResult, error)
+	GetBatchTaskByID(batchTaskID int) (*vpcconfapi.BatchTaskInfo, error)
+	GetVPCIssues(vpc vpcconfapi.VPC) ([]*vpcconf.Issue, error)
+}
+
+// Polling backs off up to this long while vpc-conf can't be reached
+const maxPollBackoff = time.Minute
+
+type scheduler struct {
+	models       database.Models
+	api          vpcConfAPI
+	pollInterval time.Duration
+	now          func() time.Time
+
+	// Jobs whose next run after lastCheck is no later than now are due
+	lastCheck time.Time
+	wg        sync.WaitGroup
+}
+
+func newScheduler(models database.Models, api vpcConfAPI) *scheduler {
+	return &scheduler{
+		models:       models,
+		api:          api,
+		pollInterval: time.Second,
+		now:          time.Now,
+	}
+}
+
+// selectVPCs returns the VPCs the job's selector matches now, or every
+// automated VPC if the job has no selector
+func (s *scheduler) selectVPCs(job *database.Job) ([]vpcconfapi.VPC, error) {
+	if !job.HasSelector() {
+		vpcs, _, err := s.api.GetAutomatedVPCsAndRegions()
+		return vpcs, err
+	}
+	resolved, err := s.api.SelectVPCs(job.SelectorName, job.Selector)
+	if err != nil {
+		return nil, err
+	}
+	vpcs := []vpcconfapi.VPC{}
+	for _, vpc := range resolved.VPCs {
+		vpcs = append(vpcs, vpcconfapi.VPC{ID: vpc.ID, Name: vpc.Name, AccountID: vpc.AccountID, Region: vpc.Region})
+	}
+	return vpcs, nil
+}
+
+// countIssues returns the total number of issues vpc-conf has recorded for
+// the VPCs, or nil if they could not all be fetched
+func (s *scheduler) countIssues(vpcs []vpcconfapi.VPC) *int {
+	total := 0
+	for _, vpc := range vpcs {
+		issues, err := s.api.GetVPCIssues(vpc)
+		if err != nil {
+			log.Printf("Failed to fetch issues for %s: %s", vpc, err)
+			return nil
+		}
+		total += len(issues)
+	}
+	return &total
+}
+
+// resume picks up the runs that were in progress when sidekick last stopped
+func (s *scheduler) resume() error {
+	incompleteTasks, err := s.models.GetIncompleteTasks()
+	if err != nil {
+		return fmt.Errorf("Unable to fetch incomplete tasks: %s", err)
+	}
+	if len(incompleteTasks) == 0 {
+		return nil
+	}
+	log.Printf("Found %d incomplete tasks", len(incompleteTasks))
+
+	jobs, err := s.models.GetJobs()
+	if err != nil {
+		return fmt.Errorf("Unable to fetch jobs: %s", err)
+	}
+	taskTypes := make(map[int]vpcconf.TaskTypes)
+	for _, job := range jobs {
+		taskTypes[job.ID] = job.TaskTypes
+	}
+
+	for _, task := range incompleteTasks {
+		if task.BatchTaskID == 0 {
+			// Whether or not vpc-conf got the batch task, we don't know its ID
+			s.complete(task.ID, &database.RunResult{Error: "sidekick stopped before the batch task was recorded"})
+			continue
+		}
+		var types vpcconf.TaskTypes
+		if task.JobID != nil {
+			types = taskTypes[*task.JobID]
+		}
+		s.wg.Add(1)
+		go func(task *database.Task) {
+			defer s.wg.Done()
+			s.observe(task, types)
+		}(task)
+	}
+	return nil
+}
+
+// tick starts the runs of every enabled job that has come due since the last tick
+func (s *scheduler) tick() {
+	now := s.now()
+	since := s.lastCheck
+	s.lastCheck = now
+
+	jobs, err := s.models.GetJobs()
+	if err != nil {
+		log.Printf("Unable to fetch jobs: %s", err)
+		return
+	}
+	for _, job := range jobs {
+		if !job.IsEnabled {
+			continue
+		}
+		schedule, err := cron.Parse(job.Schedule)
+		if err != nil {
+			log.Printf("[Job %s] Invalid schedule: %s", job.Name, err)
+			continue
+		}
+		next := schedule.Next(since)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		s.start(job)
+	}
+}
+
+// start claims a run of the job and runs it in the background. It returns
+// false if the job's previous run has not finished.
+func (s *scheduler) start(job *database.Job) (bool, error) {
+	taskID, err := s.models.ClaimJobRun(job.ID)
+	if err != nil {
+		log.Printf("[Job %s] Unable to start run: %s", job.Name, err)
+		return false, err
+	}
+	if taskID == nil {
+		log.Printf("[Job %s] Previous run is still in progress; skipping", job.Name)
+		return false, nil
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(job, *taskID)
+	}()
+	return true, nil
+}
+
+func (s *scheduler) run(job *database.Job, taskID int) {
+	fail := func(err error) {
+		log.Printf("[Job %s] %s", job.Name, err)
+		s.complete(taskID, &database.RunResult{Error: err.Error()})
+	}
+
+	vpcs, err := s.selectVPCs(job)
+	if err != nil {
+		fail(fmt.Errorf("Failed to select VPCs: %s", err))
+		return
+	}
+	if len(vpcs) == 0 {
+		log.Printf("[Job %s] No VPCs match the selector", job.Name)
+		zero := 0
+		s.complete(taskID, &database.RunResult{Success: true, IssuesFound: &zero})
+		return
+	}
+
+	issuesBefore := s.countIssues(vpcs)
+
+	// vpc-conf resolves the selector again and records the VPCs it matched
+	// on the batch task
+	batch := &vpcconfapi.BatchTaskRequest{
+		TaskTypes:    job.TaskTypes,
+		Selector:     job.Selector,
+		SelectorName: job.SelectorName,
+	}
+	if !job.HasSelector() {
+		batch.VPCs = vpcs
+	}
+	if job.TaskTypes.Includes(vpcconf.TaskTypeVerifyState) || job.TaskTypes.Includes(vpcconf.TaskTypeRepair) {
+		batch.VerifySpec = job.VerifySpec
+	}
+	batchTaskResult, err := s.api.SubmitBatchTask(batch)
+	if err != nil {
+		fail(fmt.Errorf("Failed to submit batch task: %s", err))
+		return
+	}
+
+	task := &database.Task{
+		ID:            taskID,
+		JobID:         &job.ID,
+		BatchTaskID:   batchTaskResult.BatchTaskID,
+		BatchTaskName: batchTaskResult.Description,
+		VPCCount:      len(vpcs),
+		IssuesBefore:  issuesBefore,
+	}
+	err = s.models.SetJobRunBatchTask(taskID, task.BatchTaskID, task.BatchTaskName, task.VPCCount, task.IssuesBefore)
+	if err != nil {
+		// Completing the run now would let the job start again while the
+		// batch task is still running, so wait for it either way
+		log.Printf("[Job %s] Failed to record batch task %d; waiting for it anyway: %s", job.Name, task.BatchTaskID, err)
+	}
+	s.observe(task, job.TaskTypes)
+}
+
+// observe waits for the task's batch task to finish and records the result.
+// The run stays in progress while vpc-conf can't be reached, so that the job
+// doesn't start another batch task while this one may still be running, but
+// fails if vpc-conf says the batch task can't be fetched at all.
+func (s *scheduler) observe(task *database.Task, taskTypes vpcconf.TaskTypes) {
+	lastResponse := &vpcconfapi.BatchTaskInfo{}
+	backoff := s.pollInterval
+
+	for {
+		batchTaskInfo, err := s.api.GetBatchTaskByID(task.BatchTaskID)
+		if vpcconfapi.IsPermanent(err) {
+			log.Printf("[Task %d] Failed to fetch batch task %d from vpc-conf: %s", task.ID, task.BatchTaskID, err)
+			s.complete(task.ID, &database.RunResult{Error: fmt.Sprintf("Failed to fetch batch task %d: %s", task.BatchTaskID, err)})
+			return
+		} else if err != nil {
+			log.Printf("[Task %d] Failed to fetch batch task %d from vpc-conf; retrying in %s: %s", task.ID, task.BatchTaskID, backoff, err)
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > maxPollBackoff {
+				backoff = maxPollBackoff
+			}
+			continue
+		}
+		backoff = s.pollInterval
+
+		if !reflect.DeepEqual(*lastResponse, *batchTaskInfo) {
+			*lastResponse = *batchTaskInfo
+
+			progress := batchTaskInfo.GetBatchTaskProgress()
+
+			log.Printf("[Task %d] %s %d - %s", task.ID, task.BatchTaskName, task.BatchTaskID, progress)
+
+			if progress.Remaining() == 0 {
+				result := &database.RunResult{
+					Success:     progress.Failed == 0 && progress.Cancelled == 0,
+					FailedCount: progress.Failed + progress.Cancelled,
+				}
+				if task.JobID != nil {
+					s.summarize(result, task, batchTaskInfo, taskTypes)
+				}
+				s.complete(task.ID, result)
+				return
+			}
+		}
+
+		time.Sleep(s.pollInterval)
+	}
+}
+
+// summarize counts the issues the batch task's VPCs have now. For repairs,
+// the reduction from the count before the run is the number fixed.
+func (s *scheduler) summarize(result *database.RunResult, task *database.Task, batchTaskInfo *vpcconfapi.BatchTaskInfo, taskTypes vpcconf.TaskTypes) {
+	vpcs := []vpcconfapi.VPC{}
+	seen := make(map[string]bool)
+	for _, t := range batchTaskInfo.Tasks {
+		key := t.VPCRegion + "/" + t.VPCID
+		if t.VPCID == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		vpcs = append(vpcs, vpcconfapi.VPC{ID: t.VPCID, AccountID: t.AccountID, Region: vpcconf.Region(t.VPCRegion)})
+	}
+	result.IssuesFound = s.countIssues(vpcs)
+	if result.IssuesFound == nil || task.IssuesBefore == nil || !taskTypes.Includes(vpcconf.TaskTypeRepair) {
+		return
+	}
+	fixed := *task.IssuesBefore - *result.IssuesFound
+	if fixed < 0 {
+		fixed = 0
+	}
+	result.IssuesFixed = &fixed
+}
+
+func (s *scheduler) complete(taskID int, result *database.RunResult) {
+	err := s.models.CompleteJobRun(taskID, result)
+	if err != nil {
+		log.Printf("Unable to mark Task %d as complete: %s", taskID, err)
+	}
+}
+
+// loop resumes incomplete runs and then checks for due jobs every interval
+// until stop is closed. Runs still in progress then are resumed the next time
+// sidekick starts.
+func (s *scheduler) loop(interval time.Duration, stop <-chan struct{}) error {
+	err := s.resume()
+	if err != nil {
+		return err
+	}
+	s.lastCheck = s.now()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
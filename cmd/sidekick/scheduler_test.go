@@ -0,0 +1,321 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmd/sidekick/internal/database"
+	vpcconf "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/vpcconfapi"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeModels struct {
+	database.Models
+
+	lock  sync.Mutex
+	jobs  []*database.Job
+	tasks []*database.Task
+	// SetJobRunBatchTask fails with this if set
+	setBatchTaskErr error
+}
+
+func (m *fakeModels) GetJobs() ([]*database.Job, error) {
+	return m.jobs, nil
+}
+
+func (m *fakeModels) GetIncompleteTasks() ([]*database.Task, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	tasks := []*database.Task{}
+	for _, task := range m.tasks {
+		if task.CompletedAt == nil {
+			t := *task
+			tasks = append(tasks, &t)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *fakeModels) ClaimJobRun(jobID int) (*int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, task := range m.tasks {
+		if task.JobID != nil && *task.JobID == jobID && task.CompletedAt == nil {
+			return nil, nil
+		}
+	}
+	id := len(m.tasks) + 1
+	m.tasks = append(m.tasks, &database.Task{ID: id, JobID: &jobID})
+	return &id, nil
+}
+
+func (m *fakeModels) GetTaskByID(id int) (*database.Task, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if id < 1 || id > len(m.tasks) {
+		return nil, sql.ErrNoRows
+	}
+	t := *m.tasks[id-1]
+	return &t, nil
+}
+
+func (m *fakeModels) SetJobRunBatchTask(taskID, batchTaskID int, batchTaskName string, vpcCount int, issuesBefore *int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.setBatchTaskErr != nil {
+		return m.setBatchTaskErr
+	}
+	task := m.tasks[taskID-1]
+	task.BatchTaskID = batchTaskID
+	task.BatchTaskName = batchTaskName
+	task.VPCCount = vpcCount
+	task.IssuesBefore = issuesBefore
+	return nil
+}
+
+func (m *fakeModels) CompleteJobRun(taskID int, result *database.RunResult) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	task := m.tasks[taskID-1]
+	now := time.Now()
+	task.CompletedAt = &now
+	task.Success = result.Success
+	task.FailedCount = result.FailedCount
+	task.IssuesFound = result.IssuesFound
+	task.IssuesFixed = result.IssuesFixed
+	if result.Error != "" {
+		task.Error = &result.Error
+	}
+	return nil
+}
+
+type fakeVPCConf struct {
+	lock sync.Mutex
+	vpcs []vpcconfapi.VPC
+	// selector expression or name -> the VPCs it matches
+	selectors map[string][]vpcconfapi.VPC
+	issues    map[string]int
+	submitted []*vpcconfapi.BatchTaskRequest
+	// the batch task's status is returned while release is open
+	status  string
+	release chan struct{}
+	// issues after the batch task finishes
+	issuesAfter map[string]int
+	// GetBatchTaskByID fails this many times first
+	pollErrors int
+}
+
+func (f *fakeVPCConf) GetAutomatedVPCsAndRegions() ([]vpcconfapi.VPC, []string, error) {
+	return f.vpcs, []string{"us-east-1", "us-west-2"}, nil
+}
+
+func (f *fakeVPCConf) SelectVPCs(name, expression string) (*vpcconf.BatchTaskSelector, error) {
+	vpcs, ok := f.selectors[name+expression]
+	if !ok {
+		return nil, &vpcconfapi.HTTPError{StatusCode: http.StatusBadRequest, Status: "400 Bad Request"}
+	}
+	resolved := &vpcconf.BatchTaskSelector{Name: name, Expression: expression}
+	for _, vpc := range vpcs {
+		resolved.VPCs = append(resolved.VPCs, &vpcconf.BatchTaskSelectedVPC{ID: vpc.ID, AccountID: vpc.AccountID, Region: vpc.Region})
+	}
+	return resolved, nil
+}
+
+func (f *fakeVPCConf) SubmitBatchTask(batch *vpcconfapi.BatchTaskRequest) (*vpcconfapi.BatchTaskResult, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.submitted = append(f.submitted, batch)
+	return &vpcconfapi.BatchTaskResult{BatchTaskID: 100 + len(f.submitted), Description: "batch"}, nil
+}
+
+func (f *fakeVPCConf) GetBatchTaskByID(batchTaskID int) (*vpcconfapi.BatchTaskInfo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.pollErrors > 0 {
+		f.pollErrors--
+		return nil, fmt.Errorf("vpc-conf is down")
+	}
+	if batchTaskID-101 >= len(f.submitted) {
+		return nil, &vpcconfapi.HTTPError{StatusCode: http.StatusNotFound, Status: "404 Not Found"}
+	}
+	info := &vpcconfapi.BatchTaskInfo{ID: batchTaskID, Description: "batch"}
+	batch := f.submitted[batchTaskID-101]
+	vpcs := batch.VPCs
+	if batch.Selector != "" || batch.SelectorName != "" {
+		vpcs = f.selectors[batch.SelectorName+batch.Selector]
+	}
+	status := vpcconf.TaskStatusSuccessful.String()
+	select {
+	case <-f.release:
+		f.issues = f.issuesAfter
+	default:
+		status = f.status
+	}
+	for _, vpc := range vpcs {
+		info.Tasks = append(info.Tasks, &vpcconfapi.Task{VPCID: vpc.ID, AccountID: vpc.AccountID, VPCRegion: string(vpc.Region), Status: status})
+	}
+	return info, nil
+}
+
+func (f *fakeVPCConf) GetVPCIssues(vpc vpcconfapi.VPC) ([]*vpcconf.Issue, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return make([]*vpcconf.Issue, f.issues[vpc.ID]), nil
+}
+
+var testVPCs = []vpcconfapi.VPC{
+	{ID: "vpc-1", AccountID: "111", Region: "us-east-1", Stack: "dev"},
+	{ID: "vpc-2", AccountID: "111", Region: "us-west-2", Stack: "prod"},
+	{ID: "vpc-3", AccountID: "222", Region: "us-east-1", Stack: "prod"},
+}
+
+func TestSchedulerRunsDueJobsWithoutOverlap(t *testing.T) {
+	models := &fakeModels{
+		jobs: []*database.Job{
+			{ID: 1, Name: "repair", Schedule: "*/10 * * * *", TaskTypes: vpcconf.TaskTypeRepair, VerifySpec: vpcconf.VerifyAllSpec(), Selector: "stack=prod", IsEnabled: true},
+			{ID: 2, Name: "disabled", Schedule: "* * * * *", TaskTypes: vpcconf.TaskTypeVerifyState, IsEnabled: false},
+			{ID: 3, Name: "hourly", Schedule: "@hourly", TaskTypes: vpcconf.TaskTypeVerifyState, IsEnabled: true},
+		},
+	}
+	api := &fakeVPCConf{
+		vpcs:        testVPCs,
+		selectors:   map[string][]vpcconfapi.VPC{"stack=prod": testVPCs[1:]},
+		issues:      map[string]int{"vpc-2": 2, "vpc-3": 3},
+		status:      vpcconf.TaskStatusInProgress.String(),
+		release:     make(chan struct{}),
+		issuesAfter: map[string]int{"vpc-3": 1},
+	}
+	now := time.Date(2021, time.March, 10, 14, 5, 0, 0, time.UTC)
+	s := newScheduler(models, api)
+	s.pollInterval = time.Millisecond
+	s.now = func() time.Time { return now }
+	s.lastCheck = now
+
+	// 14:10 is due for the repair job only
+	now = now.Add(5 * time.Minute)
+	s.tick()
+	// Still running at 14:20 so the repair job is skipped
+	now = now.Add(10 * time.Minute)
+	s.tick()
+	close(api.release)
+	s.wg.Wait()
+
+	if len(api.submitted) != 1 {
+		t.Fatalf("Expected 1 batch task but got %d", len(api.submitted))
+	}
+	submitted := api.submitted[0]
+	if submitted.TaskTypes != vpcconf.TaskTypeRepair || submitted.VerifySpec != vpcconf.VerifyAllSpec() {
+		t.Errorf("Expected a repair of everything but got %+v", submitted)
+	}
+	if submitted.Selector != "stack=prod" || len(submitted.VPCs) != 0 {
+		t.Errorf("Expected the job's selector to be submitted but got %+v", submitted)
+	}
+
+	four, five, one := 4, 5, 1
+	expected := []*database.Task{
+		{ID: 1, JobID: &models.jobs[0].ID, BatchTaskID: 101, BatchTaskName: "batch", Success: true, VPCCount: 2, IssuesBefore: &five, IssuesFound: &one, IssuesFixed: &four},
+	}
+	if diff := cmp.Diff(expected, models.tasks, cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".CompletedAt"
+	}, cmp.Ignore())); diff != "" {
+		t.Errorf("Expected runs did not match actual: \n%s", diff)
+	}
+	if models.tasks[0].CompletedAt == nil {
+		t.Errorf("Expected the run to be completed")
+	}
+
+	// Done now, so the next due time runs again
+	now = now.Add(10 * time.Minute)
+	s.tick()
+	s.wg.Wait()
+	if len(models.tasks) != 2 || models.tasks[1].CompletedAt == nil {
+		t.Errorf("Expected a second completed run but got %d runs", len(models.tasks))
+	}
+}
+
+func TestSchedulerResume(t *testing.T) {
+	jobID := 1
+	models := &fakeModels{
+		jobs: []*database.Job{{ID: jobID, Name: "verify", TaskTypes: vpcconf.TaskTypeVerifyState}},
+		tasks: []*database.Task{
+			{ID: 1, JobID: &jobID},
+			{ID: 2, JobID: &jobID, BatchTaskID: 101},
+		},
+	}
+	api := &fakeVPCConf{
+		submitted:  []*vpcconfapi.BatchTaskRequest{{VPCs: testVPCs[:1]}},
+		release:    make(chan struct{}),
+		pollErrors: 2,
+	}
+	close(api.release)
+	s := newScheduler(models, api)
+	s.pollInterval = time.Millisecond
+	err := s.resume()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	s.wg.Wait()
+
+	if models.tasks[0].CompletedAt == nil || models.tasks[0].Success || models.tasks[0].Error == nil {
+		t.Errorf("Expected the run without a batch task to fail but got %+v", models.tasks[0])
+	}
+	if models.tasks[1].CompletedAt == nil || !models.tasks[1].Success || models.tasks[1].IssuesFixed != nil {
+		t.Errorf("Expected the resumed verify run to succeed after retrying but got %+v", models.tasks[1])
+	}
+}
+
+func TestSchedulerFailsRunWhenBatchTaskIsGone(t *testing.T) {
+	jobID := 1
+	models := &fakeModels{
+		jobs:  []*database.Job{{ID: jobID, Name: "verify", TaskTypes: vpcconf.TaskTypeVerifyState}},
+		tasks: []*database.Task{{ID: 1, JobID: &jobID, BatchTaskID: 101}},
+	}
+	s := newScheduler(models, &fakeVPCConf{})
+	s.pollInterval = time.Millisecond
+	err := s.resume()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	s.wg.Wait()
+
+	if models.tasks[0].CompletedAt == nil || models.tasks[0].Success || models.tasks[0].Error == nil {
+		t.Errorf("Expected the run to fail but got %+v", models.tasks[0])
+	}
+}
+
+func TestSchedulerWaitsForUnrecordedBatchTask(t *testing.T) {
+	models := &fakeModels{
+		jobs:            []*database.Job{{ID: 1, Name: "verify", TaskTypes: vpcconf.TaskTypeVerifyState, IsEnabled: true}},
+		setBatchTaskErr: fmt.Errorf("database is down"),
+	}
+	api := &fakeVPCConf{
+		vpcs:    testVPCs,
+		status:  vpcconf.TaskStatusInProgress.String(),
+		release: make(chan struct{}),
+	}
+	s := newScheduler(models, api)
+	s.pollInterval = time.Millisecond
+
+	started, err := s.start(models.jobs[0])
+	if !started || err != nil {
+		t.Fatalf("Expected the job to start but got %v, %v", started, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if started, _ := s.start(models.jobs[0]); started {
+		t.Errorf("Expected the job not to start again while its batch task is running")
+	}
+	close(api.release)
+	s.wg.Wait()
+
+	if len(api.submitted) != 1 {
+		t.Errorf("Expected 1 batch task but got %d", len(api.submitted))
+	}
+	if models.tasks[0].CompletedAt == nil || !models.tasks[0].Success {
+		t.Errorf("Expected the run to succeed once its batch task finished but got %+v", models.tasks[0])
+	}
+}
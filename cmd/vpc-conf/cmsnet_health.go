@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// Health checks run as this user, like the CMSNet connectivity check in main
+const cmsnetHealthCheckUser = "VPCConfHealthCheck"
+
+type zonedSubnet struct {
+	SubnetType   database.SubnetType
+	RouteTableID string
+}
+
+func isCMSNetSubnetType(subnetType database.SubnetType) bool {
+	return subnetType != database.SubnetTypeTransitive &&
+		subnetType != database.SubnetTypePublic &&
+		subnetType != database.SubnetTypePrivate &&
+		subnetType != database.SubnetTypeUnroutable &&
+		subnetType != database.SubnetTypeFirewall
+}
+
+// zonedSubnetsByID returns the subnets that can be connected to CMSNet
+func zonedSubnetsByID(vpc *database.VPC) map[string]*zonedSubnet {
+	subnets := map[string]*zonedSubnet{}
+	for _, az := range vpc.State.AvailabilityZones {
+		for subnetType, infos := range az.Subnets {
+			if !isCMSNetSubnetType(subnetType) {
+				continue
+			}
+			for _, info := range infos {
+				subnets[info.SubnetID] = &zonedSubnet{SubnetType: subnetType, RouteTableID: info.CustomRouteTableID}
+			}
+		}
+	}
+	return subnets
+}
+
+// cmsnetAttachmentSubnetIDs returns one private subnet per AZ, which is what
+// CMSNet attaches its transit gateway to.
+func cmsnetAttachmentSubnetIDs(vpc *database.VPC) []string {
+	ids := []string{}
+	for _, az := range vpc.State.AvailabilityZones {
+		subnets := az.Subnets[database.SubnetTypePrivate]
+		if len(subnets) > 0 {
+			ids = append(ids, subnets[0].SubnetID)
+		}
+	}
+	return ids
+}
+
+func cmsnetRequestFailed(status cmsnet.ConnectionStatus) bool {
+	return status != cmsnet.ConnectionStatusInProgress &&
+		status != cmsnet.ConnectionStatusRequested &&
+		status != cmsnet.ConnectionStatusSuccessful
+}
+
+// verifyCMSNet compares what babygroot has for the VPC with the zoned subnets
+// and routes in the VPC state. If fix is true, broken activations are deleted
+// and requested again instead of being reported.
+func verifyCMSNet(logger awsp.Logger, cmsNet cmsnet.ClientInterface, vpc *database.VPC, asUser string, fix bool) ([]*database.Issue, error) {
+	issues := []*database.Issue{}
+	addIssue := func(subnetIDs []string, isFixable bool, format string, args ...interface{}) {
+		issues = append(issues, &database.Issue{
+			AffectedSubnetIDs: subnetIDs,
+			Description:       fmt.Sprintf(format, args...),
+			IsFixable:         isFixable,
+			Type:              database.VerifyCMSNet,
+		})
+	}
+
+	connections, err := cmsNet.GetAllConnectionRequests(vpc.AccountID, vpc.Region, vpc.ID, asUser)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting CMSNet connections: %s", err)
+	}
+	nats, err := cmsNet.GetAllNATRequests(vpc.AccountID, vpc.Region, vpc.ID, asUser)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting CMSNet NATs: %s", err)
+	}
+	broken, err := cmsNet.GetBrokenActivations(vpc.AccountID, vpc.Region, vpc.ID, asUser)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting CMSNet issues: %s", err)
+	}
+
+	zoned := zonedSubnetsByID(vpc)
+	activeKeys := map[string]bool{}
+	for _, activation := range connections.Activations {
+		activeKeys[activation.SubnetID+" "+activation.DestinationCIDR] = true
+		subnet, ok := zoned[activation.SubnetID]
+		if !ok {
+			addIssue([]string{}, false, "CMSNet connection to %s is for subnet %s, which is not a zoned subnet of this VPC", activation.DestinationCIDR, activation.SubnetID)
+			continue
+		}
+		if expected := subnet.SubnetType.VRFName(vpc.Stack); activation.VRF != expected {
+			addIssue([]string{activation.SubnetID}, false, "Subnet %s's connection to CMSNet CIDR %s uses VRF %s but %s subnets should use %s", activation.SubnetID, activation.DestinationCIDR, activation.VRF, subnet.SubnetType, expected)
+		}
+		if rt := vpc.State.RouteTables[subnet.RouteTableID]; rt != nil {
+			for _, route := range rt.Routes {
+				if route.Destination == activation.DestinationCIDR {
+					addIssue([]string{activation.SubnetID}, false, "Subnet %s's connection to CMSNet CIDR %s conflicts with a route managed by vpc-conf in %s", activation.SubnetID, activation.DestinationCIDR, subnet.RouteTableID)
+				}
+			}
+		}
+	}
+
+	// Only the most recent request for a subnet and CIDR matters
+	latestRequests := map[string]cmsnet.ConnectionRequest{}
+	for _, request := range connections.Requests {
+		latestRequests[request.Params.SubnetID+" "+request.Params.DestinationCIDR] = request
+	}
+	for key, request := range latestRequests {
+		if activeKeys[key] || !cmsnetRequestFailed(request.ConnectionStatus) {
+			continue
+		}
+		addIssue([]string{request.Params.SubnetID}, false, "CMSNet connection request for subnet %s to %s failed: %s", request.Params.SubnetID, request.Params.DestinationCIDR, request.ConnectionFailureMessage)
+	}
+	for _, request := range nats.Requests {
+		if !cmsnetRequestFailed(request.ConnectionStatus) {
+			continue
+		}
+		found := false
+		for _, nat := range nats.NATs {
+			if nat.InsideNetwork == request.Params.InsideNetwork {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addIssue([]string{}, false, "CMSNet NAT request for %s failed: %s", request.Params.InsideNetwork, request.ConnectionFailureMessage)
+		}
+	}
+
+	for _, activation := range broken {
+		if !fix {
+			addIssue([]string{activation.SubnetID}, true, "Subnet %s's connection to CMSNet CIDR %s is broken", activation.SubnetID, activation.DestinationCIDR)
+			continue
+		}
+		_, err := cmsNet.DeleteActivation(activation.RequestID, vpc.AccountID, vpc.Region, vpc.ID, asUser)
+		if err != nil {
+			return nil, fmt.Errorf("Error deleting broken CMSNet connection %q: %s", activation.RequestID, err)
+		}
+		_, err = cmsNet.MakeConnectionRequest(vpc.AccountID, vpc.Region, vpc.ID, &cmsnet.ConnectionRequestParams{
+			SubnetID:            activation.SubnetID,
+			DestinationCIDR:     activation.DestinationCIDR,
+			VRF:                 activation.VRF,
+			AttachmentSubnetIDs: cmsnetAttachmentSubnetIDs(vpc),
+		}, asUser)
+		if err != nil {
+			return nil, fmt.Errorf("Error requesting CMSNet connection for subnet %s to %s again: %s", activation.SubnetID, activation.DestinationCIDR, err)
+		}
+		logger.Log("Requested broken CMSNet connection for subnet %s to %s again", activation.SubnetID, activation.DestinationCIDR)
+	}
+	return issues, nil
+}
+
+// Health check runs are batch tasks with one of these descriptions, which
+// is how the last run is found again after a restart
+const (
+	cmsnetHealthCheckDescription  = "CMSNet health check"
+	cmsnetHealthRepairDescription = "CMSNet health check and repair"
+)
+
+type cmsnetHealthRun struct {
+	StartedAt   time.Time
+	BatchTaskID uint64
+	VPCCount    int
+	Remediate   bool
+}
+
+type cmsnetHealthVPC struct {
+	VPCID     string
+	Name      string
+	AccountID string
+	Region    database.Region
+	Issues    []*database.Issue
+}
+
+type cmsnetHealthStatus struct {
+	LastRun *cmsnetHealthRun
+	VPCs    []*cmsnetHealthVPC
+}
+
+// MonitorCMSNetHealth periodically queues a CMSNet verification for every
+// automated VPC with CMSNet connections. With remediate, repair tasks are
+// queued instead so that broken activations are requested again. A pass is
+// skipped while tasks from the previous one are still queued or running.
+func (s *Server) MonitorCMSNetHealth(interval time.Duration, remediate bool) {
+	go func() {
+		for range time.Tick(interval) {
+			lastRun, err := s.lastCMSNetHealthRun()
+			if err != nil {
+				log.Printf("Error checking the previous CMSNet health check: %s", err)
+				continue
+			}
+			if lastRun != nil && lastRun.Pending {
+				log.Printf("Skipping CMSNet health checks because the previous ones are still running")
+				continue
+			}
+			err = s.queueCMSNetHealthChecks(remediate)
+			if err != nil {
+				log.Printf("Error queuing CMSNet health checks: %s", err)
+			}
+		}
+	}()
+}
+
+type cmsnetHealthRunStatus struct {
+	cmsnetHealthRun
+	Pending bool // some task has not finished yet
+}
+
+// lastCMSNetHealthRun returns the most recent health check run, or nil if
+// there hasn't been one
+func (s *Server) lastCMSNetHealthRun() (*cmsnetHealthRunStatus, error) {
+	batchTaskID, ok, err := s.TaskDatabase.GetLatestBatchTaskID(cmsnetHealthCheckDescription, cmsnetHealthRepairDescription)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting the last health check: %s", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	batchTask, err := s.TaskDatabase.GetBatchTaskByID(batchTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting batch task %d: %s", batchTaskID, err)
+	}
+	return &cmsnetHealthRunStatus{
+		cmsnetHealthRun: cmsnetHealthRun{
+			StartedAt:   batchTask.AddedAt,
+			BatchTaskID: batchTask.ID,
+			VPCCount:    len(batchTask.Tasks),
+			Remediate:   batchTask.Description == cmsnetHealthRepairDescription,
+		},
+		Pending: batchTaskPending(batchTask),
+	}, nil
+}
+
+func batchTaskPending(batchTask *database.BatchTask) bool {
+	for _, task := range batchTask.Tasks {
+		if task.Status == database.TaskStatusQueued || task.Status == database.TaskStatusInProgress {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCMSNetConnections returns whether babygroot has any connections or
+// connection requests for the VPC, which are all a health check looks at.
+// VPCs without zoned subnets are skipped without asking.
+func hasCMSNetConnections(cmsNet cmsnet.ClientInterface, vpc *database.VPC, asUser string) (bool, error) {
+	if len(zonedSubnetsByID(vpc)) == 0 {
+		return false, nil
+	}
+	connections, err := cmsNet.GetAllConnectionRequests(vpc.AccountID, vpc.Region, vpc.ID, asUser)
+	if err != nil {
+		return false, err
+	}
+	return len(connections.Activations) > 0 || len(connections.Requests) > 0, nil
+}
+
+func (s *Server) queueCMSNetHealthChecks(remediate bool) error {
+	vpcs, err := s.ModelsManager.ListAutomatedVPCs()
+	if err != nil {
+		return fmt.Errorf("Error listing VPCs: %s", err)
+	}
+	cmsNet := s.getCMSNetClient(s.CredentialService)
+	description := cmsnetHealthCheckDescription
+	if remediate {
+		description = cmsnetHealthRepairDescription
+	}
+	spec := database.VerifySpec{VerifyCMSNet: true, RepairCMSNetActivations: remediate}
+	var batchTaskID uint64
+	count := 0
+	for _, vpc := range vpcs {
+		if !vpc.State.VPCType.CanUpdateCMSNet() || !cmsNet.SupportsRegion(vpc.Region) {
+			continue
+		}
+		ok, err := hasCMSNetConnections(cmsNet, vpc, cmsnetHealthCheckUser)
+		if err != nil {
+			log.Printf("Skipping CMSNet health check of %s: error getting CMSNet connections: %s", vpc.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if batchTaskID == 0 {
+			batchTaskID, err = s.TaskDatabase.AddBatchTask(description, nil)
+			if err != nil {
+				return fmt.Errorf("Error adding batch task: %s", err)
+			}
+		}
+		taskData := &database.TaskData{AsUser: cmsnetHealthCheckUser}
+		taskDescription := "Verify CMSNet connections"
+		if remediate {
+			taskData.RepairVPCTaskData = &database.RepairVPCTaskData{VPCID: vpc.ID, Region: vpc.Region, Spec: spec}
+			taskDescription = "Repair CMSNet connections"
+		} else {
+			taskData.VerifyVPCTaskData = &database.VerifyVPCTaskData{VPCID: vpc.ID, Region: vpc.Region, Spec: spec}
+		}
+		taskBytes, err := json.Marshal(taskData)
+		if err != nil {
+			return fmt.Errorf("Error marshaling: %s", err)
+		}
+		_, err = s.TaskDatabase.AddVPCTask(vpc.AccountID, vpc.ID, taskDescription, taskBytes, database.TaskStatusQueued, &batchTaskID)
+		if err != nil {
+			return fmt.Errorf("Error adding task for %s: %s", vpc.ID, err)
+		}
+		count++
+	}
+	log.Printf("Queued CMSNet health checks for %d VPCs", count)
+	return nil
+}
+
+var handleCMSNetHealth = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleCMSNetHealth but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpcs, err := s.ModelsManager.ListVPCsWithIssueType(database.VerifyCMSNet)
+	if err != nil {
+		log.Printf("Error listing VPCs with CMSNet issues: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	status := &cmsnetHealthStatus{VPCs: []*cmsnetHealthVPC{}}
+	lastRun, err := s.lastCMSNetHealthRun()
+	if err != nil {
+		log.Printf("%s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if lastRun != nil {
+		status.LastRun = &lastRun.cmsnetHealthRun
+	}
+	for _, vpc := range vpcs {
+		info := &cmsnetHealthVPC{
+			VPCID:     vpc.ID,
+			Name:      vpc.Name,
+			AccountID: vpc.AccountID,
+			Region:    vpc.Region,
+			Issues:    []*database.Issue{},
+		}
+		for _, issue := range vpc.Issues {
+			if issue.Type == database.VerifyCMSNet {
+				info.Issues = append(info.Issues, issue)
+			}
+		}
+		status.VPCs = append(status.VPCs, info)
+	}
+
+	buf, err := json.Marshal(status)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestVerifyCMSNet(t *testing.T) {
+	vpc := &database.VPC{
+		ID:        "vpc-abc",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		Stack:     "dev",
+		State: &database.VPCState{
+			AvailabilityZones: database.AZMap{
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-a"}},
+						database.SubnetTypeApp:     {{SubnetID: "subnet-app-a", GroupName: "app", CustomRouteTableID: "rtb-app-a"}},
+					},
+				},
+				"us-east-1b": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-b"}},
+						database.SubnetTypeData:    {{SubnetID: "subnet-data-b", GroupName: "data", CustomRouteTableID: "rtb-data-b"}},
+					},
+				},
+			},
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-app-a":  {RouteTableID: "rtb-app-a"},
+				"rtb-data-b": {RouteTableID: "rtb-data-b", Routes: []*database.RouteInfo{{Destination: "10.1.0.0/16", TransitGatewayID: "tgw-1"}}},
+			},
+		},
+	}
+	activation := func(subnetID, cidr, vrf string) cmsnet.Activation {
+		return cmsnet.Activation{RequestID: subnetID + "-" + cidr, SubnetID: subnetID, DestinationCIDR: cidr, VRF: vrf}
+	}
+	request := func(subnetID, cidr string, status cmsnet.ConnectionStatus) cmsnet.ConnectionRequest {
+		return cmsnet.ConnectionRequest{
+			ConnectionStatus:         status,
+			ConnectionFailureMessage: "boom",
+			Params:                   cmsnet.ConnectionRequestParams{SubnetID: subnetID, DestinationCIDR: cidr},
+		}
+	}
+
+	testCases := []struct {
+		Name             string
+		CMSNet           testmocks.MockCMSNet
+		Fix              bool
+		ExpectedIssues   []string
+		ExpectedRequests []*cmsnet.ConnectionRequestParams
+	}{
+		{
+			Name: "Healthy",
+			CMSNet: testmocks.MockCMSNet{
+				Connections: cmsnet.ConnectionData{
+					Activations: []cmsnet.Activation{activation("subnet-app-a", "10.0.0.0/8", "vpn_app_unix_imp")},
+					Requests:    []cmsnet.ConnectionRequest{request("subnet-app-a", "10.0.0.0/8", cmsnet.ConnectionStatusSuccessful)},
+				},
+			},
+			ExpectedIssues: []string{},
+		},
+		{
+			Name: "Mismatches with state",
+			CMSNet: testmocks.MockCMSNet{
+				Connections: cmsnet.ConnectionData{
+					Activations: []cmsnet.Activation{
+						activation("subnet-gone", "10.0.0.0/8", "vpn_app_unix_imp"),
+						activation("subnet-app-a", "10.0.0.0/8", "vpn_data_unix_imp"),
+						activation("subnet-data-b", "10.1.0.0/16", "vpn_data_unix_imp"),
+					},
+				},
+			},
+			ExpectedIssues: []string{
+				"CMSNet connection to 10.0.0.0/8 is for subnet subnet-gone, which is not a zoned subnet of this VPC",
+				"Subnet subnet-app-a's connection to CMSNet CIDR 10.0.0.0/8 uses VRF vpn_data_unix_imp but App subnets should use vpn_app_unix_imp",
+				"Subnet subnet-data-b's connection to CMSNet CIDR 10.1.0.0/16 conflicts with a route managed by vpc-conf in rtb-data-b",
+			},
+		},
+		{
+			Name: "Failed requests",
+			CMSNet: testmocks.MockCMSNet{
+				Connections: cmsnet.ConnectionData{
+					Requests: []cmsnet.ConnectionRequest{
+						request("subnet-app-a", "10.2.0.0/16", "FAILED"),
+						request("subnet-app-a", "10.3.0.0/16", "FAILED"),
+						request("subnet-app-a", "10.3.0.0/16", cmsnet.ConnectionStatusInProgress),
+					},
+				},
+				NATs: cmsnet.NATData{
+					Requests: []cmsnet.NATRequest{
+						{ConnectionStatus: "FAILED", ConnectionFailureMessage: "no space", Params: cmsnet.NATRequestParams{InsideNetwork: "10.10.0.5/32"}},
+					},
+				},
+			},
+			ExpectedIssues: []string{
+				"CMSNet connection request for subnet subnet-app-a to 10.2.0.0/16 failed: boom",
+				"CMSNet NAT request for 10.10.0.5/32 failed: no space",
+			},
+		},
+		{
+			Name: "Broken activation",
+			CMSNet: testmocks.MockCMSNet{
+				BrokenActivations: []cmsnet.Activation{activation("subnet-app-a", "10.0.0.0/8", "vpn_app_unix_imp")},
+			},
+			ExpectedIssues: []string{"Subnet subnet-app-a's connection to CMSNet CIDR 10.0.0.0/8 is broken"},
+		},
+		{
+			Name: "Broken activation is requested again",
+			CMSNet: testmocks.MockCMSNet{
+				BrokenActivations: []cmsnet.Activation{activation("subnet-app-a", "10.0.0.0/8", "vpn_app_unix_imp")},
+			},
+			Fix:            true,
+			ExpectedIssues: []string{},
+			ExpectedRequests: []*cmsnet.ConnectionRequestParams{
+				{
+					SubnetID:            "subnet-app-a",
+					DestinationCIDR:     "10.0.0.0/8",
+					VRF:                 "vpn_app_unix_imp",
+					AttachmentSubnetIDs: []string{"subnet-private-a", "subnet-private-b"},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			issues, err := verifyCMSNet(&testLogger{}, &tc.CMSNet, vpc, "tester", tc.Fix)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			descriptions := []string{}
+			for _, issue := range issues {
+				if issue.Type != database.VerifyCMSNet {
+					t.Errorf("Wrong type for issue %q: %d", issue.Description, issue.Type)
+				}
+				descriptions = append(descriptions, issue.Description)
+			}
+			if diff := cmp.Diff(tc.ExpectedIssues, descriptions, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("Expected issues did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ExpectedRequests, tc.CMSNet.RequestsMade, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("Expected connection requests did not match actual: \n%s", diff)
+			}
+			if tc.Fix && len(tc.CMSNet.ActivationsDeleted) != len(tc.CMSNet.BrokenActivations) {
+				t.Errorf("Expected broken activations to be deleted but got %v", tc.CMSNet.ActivationsDeleted)
+			}
+		})
+	}
+}
+
+func TestBatchTaskPending(t *testing.T) {
+	batchTask := func(statuses ...database.TaskStatus) *database.BatchTask {
+		bt := &database.BatchTask{}
+		for _, status := range statuses {
+			bt.Tasks = append(bt.Tasks, &database.Task{Status: status})
+		}
+		return bt
+	}
+	testCases := []struct {
+		Name      string
+		BatchTask *database.BatchTask
+		Expected  bool
+	}{
+		{"Empty", batchTask(), false},
+		{"All done", batchTask(database.TaskStatusSuccessful, database.TaskStatusFailed), false},
+		{"One queued", batchTask(database.TaskStatusSuccessful, database.TaskStatusQueued), true},
+		{"One running", batchTask(database.TaskStatusInProgress, database.TaskStatusFailed), true},
+	}
+	for _, tc := range testCases {
+		if pending := batchTaskPending(tc.BatchTask); pending != tc.Expected {
+			t.Errorf("%s: expected pending %v but got %v", tc.Name, tc.Expected, pending)
+		}
+	}
+}
+
+func TestHasCMSNetConnections(t *testing.T) {
+	zoned := &database.VPC{
+		ID: "vpc-abc",
+		State: &database.VPCState{
+			AvailabilityZones: database.AZMap{
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-a"}},
+						database.SubnetTypeApp:     {{SubnetID: "subnet-app-a"}},
+					},
+				},
+			},
+		},
+	}
+	unzoned := &database.VPC{
+		ID: "vpc-def",
+		State: &database.VPCState{
+			AvailabilityZones: database.AZMap{
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-a"}},
+					},
+				},
+			},
+		},
+	}
+	connected := &testmocks.MockCMSNet{
+		Connections: cmsnet.ConnectionData{
+			Activations: []cmsnet.Activation{{SubnetID: "subnet-app-a", DestinationCIDR: "10.0.0.0/8"}},
+		},
+	}
+	testCases := []struct {
+		Name     string
+		CMSNet   *testmocks.MockCMSNet
+		VPC      *database.VPC
+		Expected bool
+	}{
+		{"Connected", connected, zoned, true},
+		{"No connections", &testmocks.MockCMSNet{}, zoned, false},
+		{"No zoned subnets", connected, unzoned, false},
+	}
+	for _, tc := range testCases {
+		ok, err := hasCMSNetConnections(tc.CMSNet, tc.VPC, "tester")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.Name, err)
+		}
+		if ok != tc.Expected {
+			t.Errorf("%s: expected %v but got %v", tc.Name, tc.Expected, ok)
+		}
+	}
+}
@@ -0,0 +1,616 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// Stacks that import accepts
+var importableStacks = []string{"sandbox", "test", "dev", "impl", "mgmt", "nonprod", "qa", "prod"}
+
+// ComplianceProblem is one way a VPC differs from the V1 structure
+type ComplianceProblem struct {
+	Description string
+	Remediation string
+	Automatic   bool // migrating to V1 fixes it
+}
+
+// ComplianceCheck is one part of the V1 structure
+type ComplianceCheck struct {
+	Name     string
+	Passed   bool
+	Problems []*ComplianceProblem
+}
+
+func (c *ComplianceCheck) problem(automatic bool, remediation, format string, args ...interface{}) {
+	c.Problems = append(c.Problems, &ComplianceProblem{
+		Description: fmt.Sprintf(format, args...),
+		Remediation: remediation,
+		Automatic:   automatic,
+	})
+}
+
+// ComplianceReport scores a VPC against the structure import expects of a V1
+// VPC. The remediation plan is the Remediation of every problem; if all of
+// them are Automatic the VPC can be migrated to V1 as it is.
+type ComplianceReport struct {
+	VPCID           string
+	Score           int // percentage of checks passed
+	CanMigrate      bool
+	Checks          []*ComplianceCheck
+	UntaggedSubnets map[string]database.SubnetType // subnet ID -> use tag that migrating adds
+}
+
+// BlockingProblems returns the problems that have to be fixed by hand before
+// migrating.
+func (r *ComplianceReport) BlockingProblems() []*ComplianceProblem {
+	problems := []*ComplianceProblem{}
+	for _, check := range r.Checks {
+		for _, p := range check.Problems {
+			if !p.Automatic {
+				problems = append(problems, p)
+			}
+		}
+	}
+	return problems
+}
+
+func hasTag(tags []*ec2.Tag, key, value string) bool {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key && aws.StringValue(tag.Value) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func defaultRouteTarget(rt *ec2.RouteTable) string {
+	for _, route := range rt.Routes {
+		if aws.StringValue(route.DestinationCidrBlock) == internetRoute {
+			return routeTarget(route)
+		}
+	}
+	return ""
+}
+
+// routeTarget is the ID of whatever the route sends traffic to.
+func routeTarget(route *ec2.Route) string {
+	for _, id := range []*string{route.GatewayId, route.NatGatewayId, route.TransitGatewayId, route.VpcPeeringConnectionId, route.NetworkInterfaceId, route.InstanceId} {
+		if aws.StringValue(id) != "" {
+			return aws.StringValue(id)
+		}
+	}
+	return "blackhole"
+}
+
+// analyzeCompliance compares a VPC as it is in AWS to the V1 structure:
+// subnets tagged by use, a public subnet and a private subnet in each AZ, a
+// shared public route table routing to an attached internet gateway, and a
+// private route table per AZ routing to a NAT gateway in that AZ. Nothing is
+// changed.
+func analyzeCompliance(ec2svc ec2iface.EC2API, vpcID string) (*ComplianceReport, error) {
+	vpcOut, err := ec2svc.DescribeVpcs(&ec2.DescribeVpcsInput{
+		VpcIds: aws.StringSlice([]string{vpcID}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing VPC %s: %s", vpcID, err)
+	}
+	if len(vpcOut.Vpcs) != 1 {
+		return nil, fmt.Errorf("VPC %s not found", vpcID)
+	}
+	vpcFilter := []*ec2.Filter{{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{vpcID})}}
+	subnetsOut, err := ec2svc.DescribeSubnets(&ec2.DescribeSubnetsInput{Filters: vpcFilter})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing subnets: %s", err)
+	}
+	rtOut, err := ec2svc.DescribeRouteTables(&ec2.DescribeRouteTablesInput{Filters: vpcFilter})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing route tables: %s", err)
+	}
+	natOut, err := ec2svc.DescribeNatGateways(&ec2.DescribeNatGatewaysInput{Filter: vpcFilter})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing NAT gateways: %s", err)
+	}
+	igwOut, err := ec2svc.DescribeInternetGateways(&ec2.DescribeInternetGatewaysInput{
+		Filters: []*ec2.Filter{{Name: aws.String("attachment.vpc-id"), Values: aws.StringSlice([]string{vpcID})}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing internet gateways: %s", err)
+	}
+
+	report := &ComplianceReport{
+		VPCID:           vpcID,
+		UntaggedSubnets: map[string]database.SubnetType{},
+	}
+	check := func(name string) *ComplianceCheck {
+		c := &ComplianceCheck{Name: name}
+		report.Checks = append(report.Checks, c)
+		return c
+	}
+
+	// Tags
+	tags := check("Name and stack tags")
+	name, stack := "", ""
+	for _, tag := range vpcOut.Vpcs[0].Tags {
+		key := aws.StringValue(tag.Key)
+		if key == "Name" {
+			name = aws.StringValue(tag.Value)
+		} else if (key == "stack" && stack == "") || key == "vpc-conf-stack" {
+			stack = aws.StringValue(tag.Value)
+		}
+	}
+	if name == "" {
+		tags.problem(false, "Tag the VPC with a Name", "VPC has no Name tag")
+	}
+	if stack == "" {
+		tags.problem(false, "Tag the VPC with a stack", "VPC has no stack tag")
+	} else if !stringInSlice(stack, importableStacks) {
+		tags.problem(false, fmt.Sprintf("Change the stack tag to one of %s", strings.Join(importableStacks, ", ")), "Stack %q is not valid", stack)
+	}
+	if hasTag(vpcOut.Vpcs[0].Tags, awsp.FirewallTypeKey, awsp.FirewallTypeValue) {
+		tags.problem(false, "Remove the tag and add the network firewall after migrating", "VPC is tagged %s=%s", awsp.FirewallTypeKey, awsp.FirewallTypeValue)
+	}
+
+	// Subnets
+	subnets := subnetsOut.Subnets
+	sort.Slice(subnets, func(i, j int) bool {
+		return aws.StringValue(subnets[i].SubnetId) < aws.StringValue(subnets[j].SubnetId)
+	})
+	layers := check("Subnet layers")
+	useTags := check("Subnet use tags")
+	subnetTypes := map[string]database.SubnetType{}
+	subnetAZs := map[string]string{}
+	azSubnetTypes := map[string]map[database.SubnetType]bool{}
+	for _, subnet := range subnets {
+		subnetID := aws.StringValue(subnet.SubnetId)
+		az := aws.StringValue(subnet.AvailabilityZone)
+		subnetAZs[subnetID] = az
+		subnetType, hasUseTag, err := awsp.ClassifySubnet(subnet)
+		if err != nil {
+			layers.problem(false, "Tag the subnet with use (public, private, app, data, web, etc.) or remove it", "%s", err)
+			continue
+		}
+		if !hasUseTag {
+			use := strings.ToLower(string(subnetType))
+			useTags.problem(true, fmt.Sprintf("Migrating tags it use=%s", use), "Subnet %s has no use tag but its layer makes it %s", subnetID, use)
+			report.UntaggedSubnets[subnetID] = subnetType
+		}
+		subnetTypes[subnetID] = subnetType
+		if azSubnetTypes[az] == nil {
+			azSubnetTypes[az] = map[database.SubnetType]bool{}
+		}
+		azSubnetTypes[az][subnetType] = true
+	}
+	azs := []string{}
+	for az := range azSubnetTypes {
+		azs = append(azs, az)
+	}
+	sort.Strings(azs)
+
+	publicInAZ := check("Public subnet in each AZ")
+	privateInAZ := check("Private subnet in each AZ")
+	for _, az := range azs {
+		if !azSubnetTypes[az][database.SubnetTypePublic] {
+			publicInAZ.problem(false, fmt.Sprintf("Add a public subnet in %s or remove the other subnets from it", az), "AZ %s has no public subnet", az)
+		}
+		if !azSubnetTypes[az][database.SubnetTypePrivate] {
+			privateInAZ.problem(false, fmt.Sprintf("Add a private subnet in %s or tag an existing one use=private; transit gateway attachments use the private subnets", az), "AZ %s has no private subnet", az)
+		}
+	}
+
+	// Route tables
+	routeTablesByID := map[string]*ec2.RouteTable{}
+	routeTableBySubnet := map[string]string{}
+	for _, rt := range rtOut.RouteTables {
+		rtID := aws.StringValue(rt.RouteTableId)
+		routeTablesByID[rtID] = rt
+		for _, assoc := range rt.Associations {
+			if subnetID := aws.StringValue(assoc.SubnetId); subnetID != "" {
+				routeTableBySubnet[subnetID] = rtID
+			}
+		}
+	}
+	layout := check("Route table layout")
+	sharing := check("Route tables not shared between subnet types")
+	publicRTs := map[string]bool{}
+	privateRTsByAZ := map[string]map[string]bool{}
+	privateRTAZs := map[string]map[string]bool{}
+	rtSubnetTypes := map[string]map[string]bool{}
+	for _, subnet := range subnets {
+		subnetID := aws.StringValue(subnet.SubnetId)
+		subnetType, ok := subnetTypes[subnetID]
+		if !ok {
+			continue
+		}
+		az := subnetAZs[subnetID]
+		rtID, ok := routeTableBySubnet[subnetID]
+		if !ok {
+			layout.problem(true, fmt.Sprintf("Migrating associates it with a %s route table", subnetType), "Subnet %s uses the main route table", subnetID)
+			continue
+		}
+		if rtSubnetTypes[rtID] == nil {
+			rtSubnetTypes[rtID] = map[string]bool{}
+		}
+		rtSubnetTypes[rtID][string(subnetType)] = true
+		if subnetType == database.SubnetTypePublic {
+			publicRTs[rtID] = true
+		} else if subnetType == database.SubnetTypePrivate {
+			if privateRTsByAZ[az] == nil {
+				privateRTsByAZ[az] = map[string]bool{}
+			}
+			privateRTsByAZ[az][rtID] = true
+			if privateRTAZs[rtID] == nil {
+				privateRTAZs[rtID] = map[string]bool{}
+			}
+			privateRTAZs[rtID][az] = true
+		}
+	}
+	if len(publicRTs) > 1 {
+		layout.problem(false, "Associate every public subnet with one shared public route table", "Public subnets use %d route tables: %s", len(publicRTs), strings.Join(sortedKeys(publicRTs), ", "))
+	}
+	for _, az := range azs {
+		if len(privateRTsByAZ[az]) > 1 {
+			layout.problem(false, fmt.Sprintf("Associate every private subnet in %s with one route table", az), "Private subnets in AZ %s use %d route tables: %s", az, len(privateRTsByAZ[az]), strings.Join(sortedKeys(privateRTsByAZ[az]), ", "))
+		}
+	}
+	rtIDs := []string{}
+	for rtID := range rtSubnetTypes {
+		rtIDs = append(rtIDs, rtID)
+	}
+	sort.Strings(rtIDs)
+	for _, rtID := range rtIDs {
+		if len(privateRTAZs[rtID]) > 1 {
+			layout.problem(false, "Give each AZ its own private route table so that it can use its own NAT gateway", "Route table %s is used by private subnets in AZs %s", rtID, strings.Join(sortedKeys(privateRTAZs[rtID]), ", "))
+		}
+	}
+	for _, rtID := range rtIDs {
+		if len(rtSubnetTypes[rtID]) > 1 {
+			sharing.problem(false, "Give each subnet type its own route table", "Route table %s is shared by %s subnets", rtID, strings.Join(sortedKeys(rtSubnetTypes[rtID]), " and "))
+		}
+	}
+
+	// Internet gateway
+	igw := check("Internet gateway")
+	igwID := ""
+	if len(igwOut.InternetGateways) == 0 {
+		igw.problem(true, "Migrating creates and attaches one", "No internet gateway is attached")
+	} else {
+		igwID = aws.StringValue(igwOut.InternetGateways[0].InternetGatewayId)
+	}
+	if len(publicRTs) == 1 {
+		rtID := sortedKeys(publicRTs)[0]
+		target := defaultRouteTarget(routeTablesByID[rtID])
+		if target == "" {
+			igw.problem(true, "Migrating adds a default route to the internet gateway", "Public route table %s has no default route", rtID)
+		} else if target != igwID {
+			igw.problem(false, "Remove the route so that migrating can point it at the internet gateway", "Public route table %s sends %s to %s", rtID, internetRoute, target)
+		}
+	}
+
+	// NAT gateways
+	nat := check("NAT gateway per AZ")
+	natsByAZ := map[string][]string{}
+	for _, ng := range natOut.NatGateways {
+		state := aws.StringValue(ng.State)
+		if state != ec2.NatGatewayStateAvailable && state != ec2.NatGatewayStatePending {
+			continue
+		}
+		subnetID := aws.StringValue(ng.SubnetId)
+		if subnetTypes[subnetID] != database.SubnetTypePublic {
+			continue
+		}
+		az := subnetAZs[subnetID]
+		natsByAZ[az] = append(natsByAZ[az], aws.StringValue(ng.NatGatewayId))
+	}
+	defaultRoutes := check("Private default routes")
+	for _, az := range azs {
+		nats := natsByAZ[az]
+		if len(nats) == 0 {
+			if azSubnetTypes[az][database.SubnetTypePublic] {
+				nat.problem(true, fmt.Sprintf("Migrating creates one in the public subnet in %s", az), "AZ %s has no NAT gateway", az)
+			}
+		} else if len(nats) > 1 {
+			nat.problem(false, fmt.Sprintf("Delete all but one of the NAT gateways in %s", az), "AZ %s has %d NAT gateways: %s", az, len(nats), strings.Join(nats, ", "))
+		}
+		if len(privateRTsByAZ[az]) != 1 {
+			continue
+		}
+		rtID := sortedKeys(privateRTsByAZ[az])[0]
+		target := defaultRouteTarget(routeTablesByID[rtID])
+		if target == "" {
+			defaultRoutes.problem(true, "Migrating adds a default route to the AZ's NAT gateway", "Private route table %s has no default route", rtID)
+		} else if len(nats) != 1 || target != nats[0] {
+			defaultRoutes.problem(false, "Remove the route so that migrating can point it at the AZ's NAT gateway", "Private route table %s sends %s to %s instead of a NAT gateway in %s", rtID, internetRoute, target, az)
+		}
+	}
+
+	passed := 0
+	report.CanMigrate = true
+	for _, c := range report.Checks {
+		c.Passed = len(c.Problems) == 0
+		if c.Passed {
+			passed++
+		}
+		for _, p := range c.Problems {
+			if !p.Automatic {
+				report.CanMigrate = false
+			}
+		}
+	}
+	report.Score = passed * 100 / len(report.Checks)
+	return report, nil
+}
+
+// performMigrateLegacyToV1Task is the first step of migrating: it tags the
+// subnets for V1 so that the import that follows can read the VPC as V1.
+func (taskContext *TaskContext) performMigrateLegacyToV1Task(config *database.MigrateLegacyToV1TaskData) {
+	t := taskContext.Task
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+	t.Log("Preparing VPC for migration to V1")
+
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.AWSRegion, config.VPCID)
+	if err != nil {
+		t.Log("Error loading state: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State == nil {
+		t.Log("VPC %s is not managed", vpc.ID)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if !vpc.State.VPCType.CanMigrateToV1() {
+		t.Log("This is not allowed for this type of VPC")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	ctx := &awsp.Context{
+		AWSAccountAccess: taskContext.BaseAWSAccountAccess,
+		Logger:           t,
+		VPCName:          vpc.Name,
+		VPCID:            vpc.ID,
+	}
+	report, err := analyzeCompliance(ctx.EC2(), vpc.ID)
+	if err != nil {
+		t.Log("Error analyzing VPC: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if !report.CanMigrate {
+		for _, p := range report.BlockingProblems() {
+			t.Log("%s. %s", p.Description, p.Remediation)
+		}
+		t.Log("VPC cannot be migrated until these are fixed")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	subnetIDs := []string{}
+	for subnetID := range report.UntaggedSubnets {
+		subnetIDs = append(subnetIDs, subnetID)
+	}
+	sort.Strings(subnetIDs)
+	for _, subnetID := range subnetIDs {
+		use := strings.ToLower(string(report.UntaggedSubnets[subnetID]))
+		_, err := ctx.EC2().CreateTags(&ec2.CreateTagsInput{
+			Resources: aws.StringSlice([]string{subnetID}),
+			Tags:      []*ec2.Tag{{Key: aws.String("use"), Value: aws.String(use)}},
+		})
+		if err != nil {
+			t.Log("Error tagging subnet %s: %s", subnetID, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		t.Log("Tagged subnet %s with use=%s", subnetID, use)
+	}
+
+	vpc.State.VPCType = database.VPCTypeMigratingLegacyToV1
+	err = vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		t.Log("Error updating state: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	t.Log("Updated VPC type to %s", vpc.State.VPCType.String())
+
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+// Schedule tasks, each dependent on the previous task, and return the task ID of the last task
+func (s *Server) scheduleLegacyToV1Tasks(vpcID, accountID, asUser string, region database.Region, vpc *database.VPC) (uint64, error) {
+	// Tag subnets and update VPC Type (MigratingLegacyToV1)
+	taskData := &database.TaskData{
+		MigrateLegacyToV1TaskData: &database.MigrateLegacyToV1TaskData{
+			VPCID:     vpcID,
+			AWSRegion: region,
+		},
+		AsUser: asUser,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		return 0, fmt.Errorf("Error marshaling: %s", err)
+	}
+	firstTask, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, fmt.Sprintf("Prepare VPC %s for migration to V1", vpcID), taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		return 0, fmt.Errorf("Error adding task: %s", err)
+	}
+
+	// Import as V1, keeping the config
+	taskData = &database.TaskData{
+		ImportVPCTaskData: &database.ImportVPCTaskData{
+			VPCID:     vpcID,
+			VPCType:   database.VPCTypeV1,
+			Region:    region,
+			AccountID: accountID,
+		},
+		AsUser: asUser,
+	}
+	prereqID, err := s.scheduleDependentVPCTask(taskData, "Import VPC "+vpcID, accountID, vpcID, firstTask.ID)
+	if err != nil {
+		return 0, fmt.Errorf("Error scheduling dependent VPC task: %s", err)
+	}
+
+	// Update Networking
+	updateNetworkingData := &database.UpdateNetworkingTaskData{
+		VPCID:     vpcID,
+		AWSRegion: region,
+		NetworkingConfig: database.NetworkingConfig{
+			ConnectPublic:                      true,
+			ConnectPrivate:                     true,
+			ManagedTransitGatewayAttachmentIDs: vpc.Config.ManagedTransitGatewayAttachmentIDs,
+			PeeringConnections:                 vpc.Config.PeeringConnections,
+			ExternalPeeringConnections:         vpc.Config.ExternalPeeringConnections,
+			VPNConnectionIDs:                   vpc.Config.VPNConnectionIDs,
+		},
+	}
+	taskData = &database.TaskData{
+		UpdateNetworkingTaskData: updateNetworkingData,
+		AsUser:                   asUser,
+	}
+	prereqID, err = s.scheduleDependentVPCTask(taskData, fmt.Sprintf("Update VPC %s networking", vpcID), accountID, vpcID, prereqID)
+	if err != nil {
+		return 0, fmt.Errorf("Error scheduling dependent VPC task: %s", err)
+	}
+
+	// Update Logging
+	taskData = &database.TaskData{
+		UpdateLoggingTaskData: &database.UpdateLoggingTaskData{
+			VPCID:  vpcID,
+			Region: region,
+		},
+		AsUser: asUser,
+	}
+	lastTaskID, err := s.scheduleDependentVPCTask(taskData, fmt.Sprintf("Update VPC %s logging", vpcID), accountID, vpcID, prereqID)
+	if err != nil {
+		return 0, fmt.Errorf("Error scheduling dependent VPC task: %s", err)
+	}
+
+	return lastTaskID, nil
+}
+
+var handleVPCCompliance = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleVPCCompliance but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	sess, err := s.CachedCredentials.GetAWSSession(accountID, region, s.getSession(r).Username)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+		return
+	}
+	report, err := analyzeCompliance(ec2.New(sess), vpcID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleVPCMigrateToV1 = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleVPCMigrateToV1 but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil {
+		http.Error(w, fmt.Sprintf("VPC %s is not automated", vpcID), http.StatusBadRequest)
+		return
+	}
+	if !vpc.State.VPCType.CanMigrateToV1() {
+		http.Error(w, "Not available for this type of VPC", http.StatusBadRequest)
+		return
+	}
+
+	asUser := s.getSession(r).Username
+	sess, err := s.CachedCredentials.GetAWSSession(accountID, region, asUser)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+		return
+	}
+	report, err := analyzeCompliance(ec2.New(sess), vpcID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !report.CanMigrate {
+		problems := []string{}
+		for _, p := range report.BlockingProblems() {
+			problems = append(problems, fmt.Sprintf("%s. %s", p.Description, p.Remediation))
+		}
+		http.Error(w, "The VPC cannot be migrated until these are fixed:\n"+strings.Join(problems, "\n"), http.StatusBadRequest)
+		return
+	}
+
+	// The import keeps the config, so later networking tasks keep the NAT
+	// gateways. It's saved first so a task that starts right away sees it.
+	vpc.Config.ConnectPublic = true
+	vpc.Config.ConnectPrivate = true
+	err = s.ModelsManager.UpdateVPCConfig(database.Region(region), vpcID, *vpc.Config)
+	if err != nil {
+		log.Printf("Error updating VPC config: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	taskID, err := s.scheduleLegacyToV1Tasks(vpcID, accountID, asUser, database.Region(region), vpc)
+	if err != nil {
+		log.Printf("Error scheduling tasks for Legacy to V1: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": taskID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/google/go-cmp/cmp"
+)
+
+type complianceEC2 struct {
+	ec2iface.EC2API
+
+	vpc         *ec2.Vpc
+	subnets     []*ec2.Subnet
+	routeTables []*ec2.RouteTable
+	nats        []*ec2.NatGateway
+	igws        []*ec2.InternetGateway
+}
+
+func (m *complianceEC2) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{Vpcs: []*ec2.Vpc{m.vpc}}, nil
+}
+
+func (m *complianceEC2) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	return &ec2.DescribeSubnetsOutput{Subnets: m.subnets}, nil
+}
+
+func (m *complianceEC2) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	return &ec2.DescribeRouteTablesOutput{RouteTables: m.routeTables}, nil
+}
+
+func (m *complianceEC2) DescribeNatGateways(input *ec2.DescribeNatGatewaysInput) (*ec2.DescribeNatGatewaysOutput, error) {
+	return &ec2.DescribeNatGatewaysOutput{NatGateways: m.nats}, nil
+}
+
+func (m *complianceEC2) DescribeInternetGateways(input *ec2.DescribeInternetGatewaysInput) (*ec2.DescribeInternetGatewaysOutput, error) {
+	return &ec2.DescribeInternetGatewaysOutput{InternetGateways: m.igws}, nil
+}
+
+func complianceSubnet(id, az string, tags ...string) *ec2.Subnet {
+	subnet := &ec2.Subnet{SubnetId: aws.String(id), AvailabilityZone: aws.String(az)}
+	for i := 0; i < len(tags); i += 2 {
+		subnet.Tags = append(subnet.Tags, &ec2.Tag{Key: aws.String(tags[i]), Value: aws.String(tags[i+1])})
+	}
+	return subnet
+}
+
+func complianceRouteTable(id, defaultTarget string, subnetIDs ...string) *ec2.RouteTable {
+	rt := &ec2.RouteTable{RouteTableId: aws.String(id)}
+	for _, subnetID := range subnetIDs {
+		rt.Associations = append(rt.Associations, &ec2.RouteTableAssociation{SubnetId: aws.String(subnetID)})
+	}
+	if defaultTarget != "" {
+		route := &ec2.Route{DestinationCidrBlock: aws.String(internetRoute)}
+		if defaultTarget[:4] == "nat-" {
+			route.NatGatewayId = aws.String(defaultTarget)
+		} else {
+			route.GatewayId = aws.String(defaultTarget)
+		}
+		rt.Routes = append(rt.Routes, route)
+	}
+	return rt
+}
+
+// A legacy VPC with two AZs, laid out like V1 except for the subnet tags
+func legacyComplianceEC2() *complianceEC2 {
+	return &complianceEC2{
+		vpc: &ec2.Vpc{
+			VpcId: aws.String("vpc-1"),
+			Tags: []*ec2.Tag{
+				{Key: aws.String("Name"), Value: aws.String("legacy-dev")},
+				{Key: aws.String("stack"), Value: aws.String("dev")},
+			},
+		},
+		subnets: []*ec2.Subnet{
+			complianceSubnet("subnet-pub-a", "us-east-1a", "Layer", "dmz"),
+			complianceSubnet("subnet-pub-b", "us-east-1b", "Layer", "dmz"),
+			complianceSubnet("subnet-priv-a", "us-east-1a", "use", "private"),
+			complianceSubnet("subnet-priv-b", "us-east-1b", "use", "private"),
+			complianceSubnet("subnet-app-a", "us-east-1a", "Layer", "app"),
+		},
+		routeTables: []*ec2.RouteTable{
+			complianceRouteTable("rtb-pub", "igw-1", "subnet-pub-a", "subnet-pub-b"),
+			complianceRouteTable("rtb-priv-a", "nat-a", "subnet-priv-a"),
+			complianceRouteTable("rtb-priv-b", "", "subnet-priv-b"),
+			complianceRouteTable("rtb-app-a", "nat-a", "subnet-app-a"),
+		},
+		nats: []*ec2.NatGateway{
+			{NatGatewayId: aws.String("nat-a"), SubnetId: aws.String("subnet-pub-a"), State: aws.String(ec2.NatGatewayStateAvailable)},
+			{NatGatewayId: aws.String("nat-old"), SubnetId: aws.String("subnet-pub-b"), State: aws.String(ec2.NatGatewayStateDeleted)},
+		},
+		igws: []*ec2.InternetGateway{
+			{InternetGatewayId: aws.String("igw-1")},
+		},
+	}
+}
+
+func failedChecks(report *ComplianceReport) map[string][]string {
+	failed := map[string][]string{}
+	for _, check := range report.Checks {
+		if check.Passed {
+			continue
+		}
+		for _, p := range check.Problems {
+			description := p.Description
+			if !p.Automatic {
+				description += " (blocking)"
+			}
+			failed[check.Name] = append(failed[check.Name], description)
+		}
+	}
+	return failed
+}
+
+func TestAnalyzeComplianceMigratable(t *testing.T) {
+	report, err := analyzeCompliance(legacyComplianceEC2(), "vpc-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[string][]string{
+		"Subnet use tags": {
+			"Subnet subnet-app-a has no use tag but its layer makes it app",
+			"Subnet subnet-pub-a has no use tag but its layer makes it public",
+			"Subnet subnet-pub-b has no use tag but its layer makes it public",
+		},
+		"NAT gateway per AZ": {
+			"AZ us-east-1b has no NAT gateway",
+		},
+		"Private default routes": {
+			"Private route table rtb-priv-b has no default route",
+		},
+	}
+	if diff := cmp.Diff(expected, failedChecks(report)); diff != "" {
+		t.Errorf("Expected problems did not match actual: \n%s", diff)
+	}
+	if !report.CanMigrate {
+		t.Errorf("Expected the VPC to be migratable")
+	}
+	if report.Score != 70 {
+		t.Errorf("Expected a score of 70 but got %d", report.Score)
+	}
+	expectedTags := map[string]database.SubnetType{
+		"subnet-app-a": database.SubnetTypeApp,
+		"subnet-pub-a": database.SubnetTypePublic,
+		"subnet-pub-b": database.SubnetTypePublic,
+	}
+	if diff := cmp.Diff(expectedTags, report.UntaggedSubnets); diff != "" {
+		t.Errorf("Expected subnets to tag did not match actual: \n%s", diff)
+	}
+}
+
+func TestAnalyzeComplianceBlocking(t *testing.T) {
+	m := legacyComplianceEC2()
+	m.vpc.Tags = m.vpc.Tags[:1]
+	m.subnets = append(m.subnets,
+		complianceSubnet("subnet-transit-c", "us-east-1c", "Layer", "transitive"),
+		complianceSubnet("subnet-data-c", "us-east-1c", "Layer", "data"),
+	)
+	m.routeTables[1].Associations = append(m.routeTables[1].Associations, &ec2.RouteTableAssociation{SubnetId: aws.String("subnet-app-a")})
+	m.routeTables[3].Associations = nil
+	m.routeTables[2].Routes = []*ec2.Route{{DestinationCidrBlock: aws.String(internetRoute), TransitGatewayId: aws.String("tgw-1")}}
+
+	report, err := analyzeCompliance(m, "vpc-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := map[string][]string{
+		"Name and stack tags": {
+			"VPC has no stack tag (blocking)",
+		},
+		"Subnet layers": {
+			"Transitive subnet subnet-transit-c has no V1 equivalent (blocking)",
+		},
+		"Subnet use tags": {
+			"Subnet subnet-app-a has no use tag but its layer makes it app",
+			"Subnet subnet-data-c has no use tag but its layer makes it data",
+			"Subnet subnet-pub-a has no use tag but its layer makes it public",
+			"Subnet subnet-pub-b has no use tag but its layer makes it public",
+		},
+		"Public subnet in each AZ": {
+			"AZ us-east-1c has no public subnet (blocking)",
+		},
+		"Private subnet in each AZ": {
+			"AZ us-east-1c has no private subnet (blocking)",
+		},
+		"Route table layout": {
+			"Subnet subnet-data-c uses the main route table",
+		},
+		"Route tables not shared between subnet types": {
+			"Route table rtb-priv-a is shared by App and Private subnets (blocking)",
+		},
+		"NAT gateway per AZ": {
+			"AZ us-east-1b has no NAT gateway",
+		},
+		"Private default routes": {
+			"Private route table rtb-priv-b sends 0.0.0.0/0 to tgw-1 instead of a NAT gateway in us-east-1b (blocking)",
+		},
+	}
+	if diff := cmp.Diff(expected, failedChecks(report)); diff != "" {
+		t.Errorf("Expected problems did not match actual: \n%s", diff)
+	}
+	if report.CanMigrate {
+		t.Errorf("Expected the VPC not to be migratable")
+	}
+	if len(report.BlockingProblems()) != 6 {
+		t.Errorf("Expected 6 blocking problems but got %d", len(report.BlockingProblems()))
+	}
+}
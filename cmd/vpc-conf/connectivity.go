@@ -0,0 +1,457 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/awscreds"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/connection"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// Probes are rolled back once this passes even if the test hasn't finished
+const connectivityTestTimeout = 15 * time.Minute
+
+// Destinations in these ranges are reached over the WAN rather than the internet
+var sharedServiceRanges = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10"}
+
+// accountAccessCredentials gives the connection package credentials through
+// the task's AWSAccountAccessProvider.
+type accountAccessCredentials struct {
+	provider AWSAccountAccessProvider
+	region   string
+	asUser   string
+}
+
+func (c *accountAccessCredentials) GetCredentialsForAccount(accountID string) (*credentials.Credentials, error) {
+	access, err := c.provider.AccessAccount(accountID, c.region, c.asUser)
+	if err != nil {
+		return nil, err
+	}
+	return access.Session.Config.Credentials, nil
+}
+
+func (c *accountAccessCredentials) GetAuthorizedAccounts() ([]*awscreds.AccountInfo, error) {
+	return nil, fmt.Errorf("Listing accounts is not supported")
+}
+
+func validateConnectivityTestSpec(spec *database.ConnectivityTestSpec) error {
+	if spec.SourceSubnetID == "" {
+		return fmt.Errorf("SourceSubnetID is required")
+	}
+	destinations := 0
+	if spec.DestinationIP != "" {
+		destinations++
+		if net.ParseIP(spec.DestinationIP) == nil {
+			return fmt.Errorf("Invalid DestinationIP %q", spec.DestinationIP)
+		}
+	}
+	if spec.DestinationSubnetID != "" || spec.DestinationVPCID != "" {
+		destinations++
+		if spec.DestinationSubnetID == "" || spec.DestinationVPCID == "" {
+			return fmt.Errorf("DestinationVPCID and DestinationSubnetID must be given together")
+		}
+		if len(spec.DestinationSecurityGroupIDs) == 0 {
+			return fmt.Errorf("DestinationSecurityGroupIDs are required to test a connection to a subnet")
+		}
+	}
+	if spec.SharedServiceEndpointName != "" {
+		destinations++
+	}
+	if destinations != 1 {
+		return fmt.Errorf("Exactly one of DestinationIP, DestinationSubnetID or SharedServiceEndpointName must be given")
+	}
+	if spec.SharedServiceEndpointName == "" && (spec.Port < 1 || spec.Port > 65535) {
+		return fmt.Errorf("Port must be between 1 and 65535")
+	}
+	return nil
+}
+
+func describeConnectivityTestDestination(spec *database.ConnectivityTestSpec) string {
+	if spec.SharedServiceEndpointName != "" {
+		return spec.SharedServiceEndpointName
+	} else if spec.DestinationSubnetID != "" {
+		return fmt.Sprintf("%s port %d", spec.DestinationSubnetID, spec.Port)
+	}
+	return fmt.Sprintf("%s port %d", spec.DestinationIP, spec.Port)
+}
+
+func ipAddressForConnectivityTest(ip net.IP) *connection.IPAddress {
+	for _, cidr := range sharedServiceRanges {
+		_, ipnet, _ := net.ParseCIDR(cidr)
+		if ipnet.Contains(ip) {
+			return &connection.IPAddress{IP: ip, IPType: connection.IPTypeSharedService}
+		}
+	}
+	return &connection.IPAddress{IP: ip, IPType: connection.IPTypeInternet}
+}
+
+// connectivityTestConnectionSpec turns a connectivity test into the spec the
+// connection package runs. destinationVPC is only needed for tests to a
+// subnet and endpoint only for tests to a shared service endpoint.
+func connectivityTestConnectionSpec(
+	vpc *database.VPC,
+	spec *database.ConnectivityTestSpec,
+	destinationVPC *database.VPC,
+	endpoint *database.SharedServiceEndpoint,
+	lookupHost func(host string) ([]string, error)) (*connection.ConnectionSpec, error) {
+	if _, ok := selectBySubnetID(vpc.State, spec.SourceSubnetID); !ok {
+		return nil, fmt.Errorf("Subnet %s is not in VPC %s", spec.SourceSubnetID, vpc.ID)
+	}
+	source := &connection.NetworkInterfaceSpec{
+		AccountID:                 vpc.AccountID,
+		Region:                    string(vpc.Region),
+		VPCID:                     vpc.ID,
+		SubnetID:                  spec.SourceSubnetID,
+		SecurityGroupIDs:          spec.SourceSecurityGroupIDs,
+		CreateEgressSecurityGroup: len(spec.SourceSecurityGroupIDs) == 0,
+	}
+	if len(spec.SourceSecurityGroupIDs) == 0 {
+		source.SecurityGroupIDs = nil
+	}
+	connectionSpec := &connection.ConnectionSpec{
+		Source:      &connection.Endpoint{NetworkInterfaceSpec: source},
+		Destination: &connection.Endpoint{},
+		Port:        spec.Port,
+		PerformTest: true,
+	}
+
+	if spec.SharedServiceEndpointName != "" {
+		ipString := endpoint.IP
+		if endpoint.Hostname != "" {
+			addrs, err := lookupHost(endpoint.Hostname)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to look up %s: %s", endpoint.Hostname, err)
+			}
+			if len(addrs) == 0 {
+				return nil, fmt.Errorf("No addresses returned for %s", endpoint.Hostname)
+			}
+			ipString = addrs[0]
+		}
+		ip := net.ParseIP(ipString)
+		if ip == nil {
+			return nil, fmt.Errorf("Shared service endpoint %s has no valid IP", endpoint.Name)
+		}
+		connectionSpec.Destination.IPAddress = &connection.IPAddress{IP: ip, IPType: connection.IPTypeSharedService}
+		connectionSpec.Port = endpoint.Port
+	} else if spec.DestinationSubnetID != "" {
+		if destinationVPC.State == nil {
+			return nil, fmt.Errorf("VPC %s is not automated", destinationVPC.ID)
+		}
+		if destinationVPC.Region != vpc.Region {
+			return nil, fmt.Errorf("VPC %s is not in %s", destinationVPC.ID, vpc.Region)
+		}
+		if _, ok := selectBySubnetID(destinationVPC.State, spec.DestinationSubnetID); !ok {
+			return nil, fmt.Errorf("Subnet %s is not in VPC %s", spec.DestinationSubnetID, destinationVPC.ID)
+		}
+		connectionSpec.Destination.NetworkInterfaceSpec = &connection.NetworkInterfaceSpec{
+			AccountID:        destinationVPC.AccountID,
+			Region:           string(destinationVPC.Region),
+			VPCID:            destinationVPC.ID,
+			SubnetID:         spec.DestinationSubnetID,
+			SecurityGroupIDs: spec.DestinationSecurityGroupIDs,
+		}
+	} else {
+		connectionSpec.Destination.IPAddress = ipAddressForConnectivityTest(net.ParseIP(spec.DestinationIP))
+	}
+	return connectionSpec, nil
+}
+
+func (taskContext *TaskContext) performTestConnectivityTask(config *database.TestConnectivityTaskData) {
+	t := taskContext.Task
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+
+	vpc, _, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.Region, config.VPCID)
+	if err != nil {
+		t.Log("Error getting VPC info: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State == nil {
+		t.Log("VPC %s is not managed", vpc.ID)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	var destinationVPC *database.VPC
+	if config.Spec.DestinationVPCID != "" {
+		destinationVPC, _, err = taskContext.ModelsManager.GetOperableVPC(lockSet, config.Region, config.Spec.DestinationVPCID)
+		if err != nil {
+			t.Log("Error getting destination VPC info: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+	var endpoint *database.SharedServiceEndpoint
+	if config.Spec.SharedServiceEndpointName != "" {
+		endpoint, err = taskContext.ModelsManager.GetSharedServiceEndpointByName(config.Spec.SharedServiceEndpointName)
+		if err != nil {
+			t.Log("Error getting shared service endpoint %q: %s", config.Spec.SharedServiceEndpointName, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
+	spec, err := connectivityTestConnectionSpec(vpc, &config.Spec, destinationVPC, endpoint, net.LookupHost)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	t.Log("Testing connectivity from %s to %s", config.Spec.SourceSubnetID, describeConnectivityTestDestination(&config.Spec))
+	ctx, cancel := context.WithTimeout(context.Background(), connectivityTestTimeout)
+	defer cancel()
+	test := &connection.NetworkConnectionTest{
+		Context: ctx,
+		Logger:  t,
+		Credentials: &accountAccessCredentials{
+			provider: taskContext.AWSAccountAccessProvider,
+			region:   string(vpc.Region),
+			asUser:   taskContext.AsUser,
+		},
+	}
+	// Verify always rolls back whatever it created, even if it fails or panics
+	verifyErr, rollbackErr := test.Verify(spec)
+
+	taskID := t.GetID()
+	result := &database.ConnectivityTestResult{
+		VPCID:     vpc.ID,
+		Region:    vpc.Region,
+		TaskID:    &taskID,
+		Spec:      config.Spec,
+		Succeeded: verifyErr == nil,
+	}
+	if verifyErr != nil {
+		result.Error = verifyErr.Error()
+	}
+	if rollbackErr != nil {
+		if rbErr, ok := rollbackErr.(*connection.RollbackError); ok {
+			result.ResourcesNotRolledBack = rbErr.ResourcesNotRolledBack
+		}
+		t.Log("Failed to remove test resources, which must be deleted by hand: %s", rollbackErr)
+	}
+	err = taskContext.ModelsManager.CreateConnectivityTestResult(result)
+	if err != nil {
+		t.Log("Error saving test result: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	if verifyErr != nil {
+		t.Log("Connectivity test failed: %s", verifyErr)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if rollbackErr != nil {
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	t.Log("Connectivity test succeeded")
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+var handleTestConnectivity = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleTestConnectivity but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	spec := new(database.ConnectivityTestSpec)
+	err := json.NewDecoder(r.Body).Decode(spec)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	err = validateConnectivityTestSpec(spec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if spec.SharedServiceEndpointName != "" {
+		_, err := s.ModelsManager.GetSharedServiceEndpointByName(spec.SharedServiceEndpointName)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Unknown shared service endpoint %q", spec.SharedServiceEndpointName), http.StatusBadRequest)
+			return
+		}
+	}
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil {
+		http.Error(w, fmt.Sprintf("VPC %s is not automated", vpcID), http.StatusBadRequest)
+		return
+	}
+	if _, ok := selectBySubnetID(vpc.State, spec.SourceSubnetID); !ok {
+		http.Error(w, fmt.Sprintf("Subnet %s is not in VPC %s", spec.SourceSubnetID, vpcID), http.StatusBadRequest)
+		return
+	}
+
+	taskData := &database.TaskData{
+		TestConnectivityTaskData: &database.TestConnectivityTaskData{
+			VPCID:  vpcID,
+			Region: database.Region(region),
+			Spec:   *spec,
+		},
+		AsUser: s.getSession(r).Username,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		log.Printf("Error marshaling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	description := fmt.Sprintf("Test connectivity from %s to %s", spec.SourceSubnetID, describeConnectivityTestDestination(spec))
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, description, taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleConnectivityTestResults = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleConnectivityTestResults but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	vpcID := args[2]
+
+	results, err := s.ModelsManager.GetConnectivityTestResults(database.Region(region), vpcID)
+	if err != nil {
+		log.Printf("Error getting connectivity test results: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleSharedServiceEndpointList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleSharedServiceEndpointList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	endpoints, err := s.ModelsManager.GetSharedServiceEndpoints()
+	if err != nil {
+		log.Printf("Error getting shared service endpoints: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(endpoints)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleCreateSharedServiceEndpoint = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleCreateSharedServiceEndpoint but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	endpoint := new(database.SharedServiceEndpoint)
+	err := json.NewDecoder(r.Body).Decode(endpoint)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if endpoint.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+	if (endpoint.Hostname == "") == (endpoint.IP == "") {
+		http.Error(w, "Exactly one of Hostname or IP must be given", http.StatusBadRequest)
+		return
+	}
+	if endpoint.IP != "" && net.ParseIP(endpoint.IP) == nil {
+		http.Error(w, fmt.Sprintf("Invalid IP %q", endpoint.IP), http.StatusBadRequest)
+		return
+	}
+	if endpoint.Port < 1 || endpoint.Port > 65535 {
+		http.Error(w, "Port must be between 1 and 65535", http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.CreateSharedServiceEndpoint(endpoint)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating shared service endpoint: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]uint64{
+		"ID": endpoint.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteSharedServiceEndpoint = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteSharedServiceEndpoint but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.DeleteSharedServiceEndpoint(id)
+	if err != nil {
+		log.Printf("Error deleting shared service endpoint %d: %s", id, err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
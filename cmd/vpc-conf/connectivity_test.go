@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/connection"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestConnectivityTestConnectionSpec(t *testing.T) {
+	vpcWithSubnet := func(vpcID, accountID, subnetID string) *database.VPC {
+		return &database.VPC{
+			ID:        vpcID,
+			AccountID: accountID,
+			Region:    "us-east-1",
+			State: &database.VPCState{
+				AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+					"us-east-1a": {
+						Subnets: map[database.SubnetType][]*database.SubnetInfo{
+							database.SubnetTypePrivate: {{SubnetID: subnetID}},
+						},
+					},
+				},
+			},
+		}
+	}
+	vpc := vpcWithSubnet("vpc-src", "111111111111", "subnet-src")
+	destinationVPC := vpcWithSubnet("vpc-dst", "222222222222", "subnet-dst")
+	endpoint := &database.SharedServiceEndpoint{Name: "AD", Hostname: "ad.example.com", Port: 636}
+	lookupHost := func(host string) ([]string, error) {
+		if host == "ad.example.com" {
+			return []string{"10.244.112.49"}, nil
+		}
+		return nil, fmt.Errorf("no such host")
+	}
+	source := &connection.Endpoint{
+		NetworkInterfaceSpec: &connection.NetworkInterfaceSpec{
+			AccountID:                 "111111111111",
+			Region:                    "us-east-1",
+			VPCID:                     "vpc-src",
+			SubnetID:                  "subnet-src",
+			CreateEgressSecurityGroup: true,
+		},
+	}
+
+	type testCase struct {
+		name         string
+		spec         database.ConnectivityTestSpec
+		invalid      bool
+		errorOnBuild bool
+		expected     *connection.ConnectionSpec
+	}
+	testCases := []testCase{
+		{
+			name: "internet IP",
+			spec: database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", DestinationIP: "8.8.8.8", Port: 443},
+			expected: &connection.ConnectionSpec{
+				Source:      source,
+				Destination: &connection.Endpoint{IPAddress: &connection.IPAddress{IPType: connection.IPTypeInternet, IP: net.ParseIP("8.8.8.8")}},
+				Port:        443,
+				PerformTest: true,
+			},
+		},
+		{
+			name: "private IP",
+			spec: database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", DestinationIP: "10.1.2.3", Port: 22},
+			expected: &connection.ConnectionSpec{
+				Source:      source,
+				Destination: &connection.Endpoint{IPAddress: &connection.IPAddress{IPType: connection.IPTypeSharedService, IP: net.ParseIP("10.1.2.3")}},
+				Port:        22,
+				PerformTest: true,
+			},
+		},
+		{
+			name: "shared service endpoint",
+			spec: database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", SharedServiceEndpointName: "AD"},
+			expected: &connection.ConnectionSpec{
+				Source:      source,
+				Destination: &connection.Endpoint{IPAddress: &connection.IPAddress{IPType: connection.IPTypeSharedService, IP: net.ParseIP("10.244.112.49")}},
+				Port:        636,
+				PerformTest: true,
+			},
+		},
+		{
+			name: "other VPC",
+			spec: database.ConnectivityTestSpec{
+				SourceSubnetID:              "subnet-src",
+				SourceSecurityGroupIDs:      []string{"sg-src"},
+				DestinationVPCID:            "vpc-dst",
+				DestinationSubnetID:         "subnet-dst",
+				DestinationSecurityGroupIDs: []string{"sg-dst"},
+				Port:                        8080,
+			},
+			expected: &connection.ConnectionSpec{
+				Source: &connection.Endpoint{
+					NetworkInterfaceSpec: &connection.NetworkInterfaceSpec{
+						AccountID:        "111111111111",
+						Region:           "us-east-1",
+						VPCID:            "vpc-src",
+						SubnetID:         "subnet-src",
+						SecurityGroupIDs: []string{"sg-src"},
+					},
+				},
+				Destination: &connection.Endpoint{
+					NetworkInterfaceSpec: &connection.NetworkInterfaceSpec{
+						AccountID:        "222222222222",
+						Region:           "us-east-1",
+						VPCID:            "vpc-dst",
+						SubnetID:         "subnet-dst",
+						SecurityGroupIDs: []string{"sg-dst"},
+					},
+				},
+				Port:        8080,
+				PerformTest: true,
+			},
+		},
+		{
+			name:         "source subnet not in VPC",
+			spec:         database.ConnectivityTestSpec{SourceSubnetID: "subnet-dst", DestinationIP: "8.8.8.8", Port: 443},
+			errorOnBuild: true,
+		},
+		{
+			name:         "destination subnet not in destination VPC",
+			spec:         database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", DestinationVPCID: "vpc-dst", DestinationSubnetID: "subnet-src", DestinationSecurityGroupIDs: []string{"sg-dst"}, Port: 80},
+			errorOnBuild: true,
+		},
+		{
+			name:    "two destinations",
+			spec:    database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", DestinationIP: "8.8.8.8", SharedServiceEndpointName: "AD", Port: 443},
+			invalid: true,
+		},
+		{
+			name:    "no port",
+			spec:    database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", DestinationIP: "8.8.8.8"},
+			invalid: true,
+		},
+		{
+			name:    "destination subnet without security groups",
+			spec:    database.ConnectivityTestSpec{SourceSubnetID: "subnet-src", DestinationVPCID: "vpc-dst", DestinationSubnetID: "subnet-dst", Port: 80},
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := validateConnectivityTestSpec(&tc.spec)
+		if tc.invalid {
+			if err == nil {
+				t.Errorf("Test case %s: expected spec to be invalid", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test case %s: unexpected validation error: %s", tc.name, err)
+			continue
+		}
+		spec, err := connectivityTestConnectionSpec(vpc, &tc.spec, destinationVPC, endpoint, lookupHost)
+		if tc.errorOnBuild {
+			if err == nil {
+				t.Errorf("Test case %s: expected an error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test case %s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, spec, cmpopts.IgnoreUnexported(connection.NetworkInterfaceSpec{})); diff != "" {
+			t.Errorf("Test case %s: expected spec did not match actual: \n%s", tc.name, diff)
+		}
+	}
+}
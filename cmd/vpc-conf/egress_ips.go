@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// currentEgressIPs looks up the public IP of each NAT gateway EIP in vpc's
+// state.
+func currentEgressIPs(ctx *awsp.Context, vpc *database.VPC) ([]*database.EgressIP, error) {
+	azNames := []string{}
+	for azName := range vpc.State.AvailabilityZones {
+		azNames = append(azNames, azName)
+	}
+	sort.Strings(azNames)
+
+	ips := []*database.EgressIP{}
+	for _, azName := range azNames {
+		az := vpc.State.AvailabilityZones[azName]
+		if az.NATGateway.EIPID == "" {
+			continue
+		}
+		eip, err := ctx.GetEIPByAllocationID(az.NATGateway.EIPID)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting EIP %s: %s", az.NATGateway.EIPID, err)
+		}
+		if eip == nil || eip.PublicIp == nil {
+			ctx.Log("EIP %s in %s no longer exists", az.NATGateway.EIPID, azName)
+			continue
+		}
+		ips = append(ips, &database.EgressIP{
+			VPCID:        vpc.ID,
+			Region:       vpc.Region,
+			AccountID:    vpc.AccountID,
+			AZName:       azName,
+			NATGatewayID: az.NATGateway.NATGatewayID,
+			AllocationID: az.NATGateway.EIPID,
+			PublicIP:     aws.StringValue(eip.PublicIp),
+		})
+	}
+	return ips, nil
+}
+
+// recordEgressIPs updates the egress IP inventory after a task that may have
+// created or destroyed NAT gateways. Networking verification calls it too,
+// so a verify batch task fills in VPCs that predate the inventory. Failing
+// to do so doesn't fail the task.
+func (taskContext *TaskContext) recordEgressIPs(ctx *awsp.Context, vpc *database.VPC) {
+	ips, err := currentEgressIPs(ctx, vpc)
+	if err != nil {
+		ctx.Log("Error updating egress IP inventory: %s", err)
+		return
+	}
+	err = taskContext.saveEgressIPs(ctx, vpc.Region, vpc.ID, ips)
+	if err != nil {
+		ctx.Log("Error updating egress IP inventory: %s", err)
+	}
+}
+
+// clearEgressIPs records every egress IP of a deleted VPC as removed.
+func (taskContext *TaskContext) clearEgressIPs(logger awsp.Logger, region database.Region, vpcID string) error {
+	return taskContext.saveEgressIPs(logger, region, vpcID, []*database.EgressIP{})
+}
+
+// saveEgressIPs replaces the egress IP inventory of a VPC with ips, then logs
+// and sends a notification about whatever changed.
+func (taskContext *TaskContext) saveEgressIPs(logger awsp.Logger, region database.Region, vpcID string, ips []*database.EgressIP) error {
+	taskID := taskContext.Task.GetID()
+	events, err := taskContext.ModelsManager.UpdateEgressIPs(region, vpcID, ips, &taskID)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		logger.Log("Egress IP %s (%s) in %s: %s", event.PublicIP, event.AllocationID, event.AZName, strings.ToLower(string(event.Type)))
+	}
+	if len(events) > 0 && taskContext.EgressIPNotifier != nil {
+		err := taskContext.EgressIPNotifier.NotifyEgressIPChanges(events)
+		if err != nil {
+			logger.Log("Error sending egress IP change notification: %s", err)
+		}
+	}
+	return nil
+}
+
+// EgressIPNotifier tells people who allowlist our egress IPs that some have
+// been added or removed.
+type EgressIPNotifier interface {
+	NotifyEgressIPChanges(events []*database.EgressIPEvent) error
+}
+
+// SlackEgressIPNotifier posts egress IP changes to a Slack incoming webhook.
+type SlackEgressIPNotifier struct {
+	WebhookURL string
+}
+
+func egressIPChangeMessage(events []*database.EgressIPEvent) string {
+	lines := []string{"Egress IPs changed:"}
+	for _, event := range events {
+		lines = append(lines, fmt.Sprintf("• %s %s (%s) in %s %s, account %s", strings.ToLower(string(event.Type)), event.PublicIP, event.AllocationID, event.VPCID, event.AZName, event.AccountID))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (n *SlackEgressIPNotifier) NotifyEgressIPChanges(events []*database.EgressIPEvent) error {
+	buf := new(bytes.Buffer)
+	err := json.NewEncoder(buf).Encode(struct {
+		Text string `json:"text"`
+	}{Text: egressIPChangeMessage(events)})
+	if err != nil {
+		return fmt.Errorf("Error encoding message: %s", err)
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Post(n.WebhookURL, "application/json", buf)
+	if err != nil {
+		return fmt.Errorf("Error posting to Slack: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack returned %s", resp.Status)
+	}
+	return nil
+}
+
+func egressIPFilterFromQuery(r *http.Request) *database.EgressIPFilter {
+	query := r.URL.Query()
+	return &database.EgressIPFilter{
+		Region:      database.Region(query.Get("region")),
+		VPCID:       query.Get("vpc"),
+		AccountID:   query.Get("account"),
+		ProjectName: query.Get("project"),
+	}
+}
+
+// handleEgressIPList lists egress IPs, optionally limited with the region,
+// vpc, account and project query parameters, as JSON or as CSV for handing
+// to partners.
+var handleEgressIPList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleEgressIPList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	format := args[0]
+
+	ips, err := s.ModelsManager.GetEgressIPs(egressIPFilterFromQuery(r))
+	if err != nil {
+		log.Printf("Error getting egress IPs: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="egress-ips.csv"`)
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"Public IP", "Project", "Account ID", "Region", "VPC ID", "VPC Name", "Availability Zone", "NAT Gateway ID", "Allocation ID", "First Seen"})
+		for _, ip := range ips {
+			writer.Write([]string{
+				ip.PublicIP,
+				ip.ProjectName,
+				ip.AccountID,
+				string(ip.Region),
+				ip.VPCID,
+				ip.VPCName,
+				ip.AZName,
+				ip.NATGatewayID,
+				ip.AllocationID,
+				ip.FirstSeenAt.UTC().Format(time.RFC3339),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			log.Printf("Error writing CSV: %s", err)
+		}
+		return
+	}
+
+	buf, err := json.Marshal(ips)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleEgressIPEventList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleEgressIPEventList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	events, err := s.ModelsManager.GetEgressIPEvents(egressIPFilterFromQuery(r))
+	if err != nil {
+		log.Printf("Error getting egress IP events: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(events)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestRecordEgressIPs(t *testing.T) {
+	ec2svc := &testmocks.MockEC2{
+		EIPsAllocated: []string{"eipalloc-a", "eipalloc-b", "eipalloc-c"},
+		EIPPublicIPs: map[string]string{
+			"eipalloc-a": "3.3.3.1",
+			"eipalloc-b": "3.3.3.2",
+			"eipalloc-c": "3.3.3.3",
+		},
+	}
+	vpc := &database.VPC{
+		ID:        "vpc-abc",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		State: &database.VPCState{
+			AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+				"us-east-1a": {NATGateway: database.NATGatewayInfo{NATGatewayID: "nat-a", EIPID: "eipalloc-a"}},
+				"us-east-1b": {NATGateway: database.NATGatewayInfo{NATGatewayID: "nat-b", EIPID: "eipalloc-b"}},
+				"us-east-1c": {},
+			},
+		},
+	}
+	mm := &testmocks.MockModelsManager{}
+	notifier := &fakeEgressIPNotifier{}
+	taskContext := &TaskContext{
+		Task:             &testmocks.MockTask{ID: 7},
+		ModelsManager:    mm,
+		EgressIPNotifier: notifier,
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		VPCID:            vpc.ID,
+	}
+	type event struct {
+		Type     database.EgressIPEventType
+		PublicIP string
+	}
+	events := func() []event {
+		got := []event{}
+		for _, e := range mm.EgressIPEvents {
+			got = append(got, event{e.Type, e.PublicIP})
+		}
+		return got
+	}
+	ignoreOrder := cmpopts.SortSlices(func(a, b event) bool { return a.PublicIP < b.PublicIP })
+
+	taskContext.recordEgressIPs(ctx, vpc)
+	expected := []event{{database.EgressIPEventTypeAdded, "3.3.3.1"}, {database.EgressIPEventTypeAdded, "3.3.3.2"}}
+	if diff := cmp.Diff(expected, events(), ignoreOrder); diff != "" {
+		t.Fatalf("Expected events did not match actual: \n%s", diff)
+	}
+	if taskID := mm.EgressIPEvents[0].TaskID; taskID == nil || *taskID != 7 {
+		t.Errorf("Expected events to be recorded for task 7 but got %v", taskID)
+	}
+
+	// Nothing changed
+	taskContext.recordEgressIPs(ctx, vpc)
+	if len(mm.EgressIPEvents) != 2 {
+		t.Fatalf("Expected no new events but got %d in total", len(mm.EgressIPEvents))
+	}
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("Expected 1 notification but got %d", len(notifier.notifications))
+	}
+
+	// us-east-1b is removed and its NAT gateway replaced in us-east-1c
+	vpc.State.AvailabilityZones["us-east-1b"].NATGateway = database.NATGatewayInfo{}
+	vpc.State.AvailabilityZones["us-east-1c"].NATGateway = database.NATGatewayInfo{NATGatewayID: "nat-c", EIPID: "eipalloc-c"}
+	taskContext.recordEgressIPs(ctx, vpc)
+	expected = append(expected, event{database.EgressIPEventTypeRemoved, "3.3.3.2"}, event{database.EgressIPEventTypeAdded, "3.3.3.3"})
+	if diff := cmp.Diff(expected, events(), ignoreOrder); diff != "" {
+		t.Errorf("Expected events did not match actual: \n%s", diff)
+	}
+
+	ips, err := mm.GetEgressIPs(&database.EgressIPFilter{AccountID: "123456789012"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expectedIPs := []*database.EgressIP{
+		{VPCID: "vpc-abc", Region: "us-east-1", AccountID: "123456789012", AZName: "us-east-1a", NATGatewayID: "nat-a", AllocationID: "eipalloc-a", PublicIP: "3.3.3.1"},
+		{VPCID: "vpc-abc", Region: "us-east-1", AccountID: "123456789012", AZName: "us-east-1c", NATGatewayID: "nat-c", AllocationID: "eipalloc-c", PublicIP: "3.3.3.3"},
+	}
+	if diff := cmp.Diff(expectedIPs, ips); diff != "" {
+		t.Errorf("Expected IPs did not match actual: \n%s", diff)
+	}
+	if len(notifier.notifications) != 2 || len(notifier.notifications[1]) != 2 {
+		t.Errorf("Expected a second notification with 2 events but got %v", notifier.notifications)
+	}
+
+	// The VPC is deleted
+	err = taskContext.clearEgressIPs(ctx, vpc.Region, vpc.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected = append(expected, event{database.EgressIPEventTypeRemoved, "3.3.3.1"}, event{database.EgressIPEventTypeRemoved, "3.3.3.3"})
+	if diff := cmp.Diff(expected, events(), ignoreOrder); diff != "" {
+		t.Errorf("Expected events did not match actual: \n%s", diff)
+	}
+	ips, err = mm.GetEgressIPs(&database.EgressIPFilter{AccountID: "123456789012"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(ips) != 0 {
+		t.Errorf("Expected no IPs after deletion but got %d", len(ips))
+	}
+}
+
+type fakeEgressIPNotifier struct {
+	notifications [][]*database.EgressIPEvent
+}
+
+func (n *fakeEgressIPNotifier) NotifyEgressIPChanges(events []*database.EgressIPEvent) error {
+	n.notifications = append(n.notifications, events)
+	return nil
+}
+
+func TestSlackEgressIPNotifier(t *testing.T) {
+	var text string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct{ Text string }{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Error decoding request: %s", err)
+		}
+		text = body.Text
+	}))
+	defer server.Close()
+
+	notifier := &SlackEgressIPNotifier{WebhookURL: server.URL}
+	err := notifier.NotifyEgressIPChanges([]*database.EgressIPEvent{
+		{Type: database.EgressIPEventTypeAdded, VPCID: "vpc-abc", AccountID: "123456789012", AZName: "us-east-1a", AllocationID: "eipalloc-a", PublicIP: "3.3.3.1"},
+		{Type: database.EgressIPEventTypeRemoved, VPCID: "vpc-abc", AccountID: "123456789012", AZName: "us-east-1b", AllocationID: "eipalloc-b", PublicIP: "3.3.3.2"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := "Egress IPs changed:\n" +
+		"• added 3.3.3.1 (eipalloc-a) in vpc-abc us-east-1a, account 123456789012\n" +
+		"• removed 3.3.3.2 (eipalloc-b) in vpc-abc us-east-1b, account 123456789012"
+	if text != expected {
+		t.Errorf("Expected message %q but got %q", expected, text)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	})
+	err = notifier.NotifyEgressIPChanges([]*database.EgressIPEvent{{Type: database.EgressIPEventTypeAdded}})
+	if err == nil {
+		t.Errorf("Expected an error when Slack rejects the message")
+	}
+}
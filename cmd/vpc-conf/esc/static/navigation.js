@@ -48,6 +48,7 @@ class VPCConfNavigation extends LitElement {
                         <li><a id="vpc-requests" href="/provision/vpcreqs">VPC Requests</a></li>
                         <li><a id="batch-tasks" href="/provision/batch">Batch Tasks</a></li>
                         <li><a id="ip-usage" href="/provision/usage">IP Usage</a></li>
+                        <li><a id="cmsnet-health" href="/provision/cmsnet">CMSNet Health</a></li>
                         <li class="dropdown">
                             <a id="attachment-templates" href="javascript:void(0)" class="dropdown-btn">Templates</a>
                             <div class="dropdown-content">
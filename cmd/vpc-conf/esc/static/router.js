@@ -8,6 +8,7 @@ import {SecurityGroupSetsPage} from './view/sgs.js';
 import {BatchTasksPage} from './view/batch.js';
 import {VPCRequestPage} from './view/vpcreqs.js';
 import {IPUsagePage} from './view/usage.js';
+import {CMSNetHealthPage} from './view/cmsnet.js';
 import {SearchPage} from './view/search.js';
 
 const serverPrefix = '/provision/';
@@ -32,6 +33,13 @@ const routes = [
 				ServerPrefix: serverPrefix,
 			})
 	},
+	{
+		re: /^cmsnet$/,
+		getView: () =>
+			new CMSNetHealthPage({
+				ServerPrefix: serverPrefix,
+			})
+	},
 	{
 		re: /^accounts$/,
 		getView: () =>
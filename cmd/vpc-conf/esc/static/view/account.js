@@ -98,6 +98,7 @@ export function AccountPage(info) {
 	this._getWarnings = function(vpc) {
 		var warnings = [];
 		if (vpc.IsMissing) warnings.push('Missing in AWS');
+		if (vpc.ExceptionTransitGateway && (vpc.ExceptionTransitGateway.Routes || []).some(r => r.Status === 'conflict')) warnings.push('Conflicting transit gateway routes');
 
 		return warnings.join(", ");
 	}
@@ -152,6 +153,7 @@ export function AccountPage(info) {
 					CanProvision: User.isAdmin(),
 					AddContainersSubnets: false,
 					AddFirewall: false,
+					IPv6: null,
 				});
 		}
 
@@ -178,15 +180,16 @@ export function AccountPage(info) {
 										? html`<a href="${info.ServerPrefix}/accounts/${info.AccountID}/vpc/${region.Name}/${vpc.VPCID}">${vpc.VPCID}</a>`
 										: vpc.VPCID}
 									</td>
-									<td>${VPCType.getStyled(vpc.VPCType)}</td>
+									<td>${VPCType.getStyled(vpc.VPCType)}${this._getExceptionTransitGatewayStatus(vpc)}</td>
 									${User.isAdmin() ? html`
 									<td nowrap>
-										<button @click="${() => view._importVPC(region.Name, vpc.VPCID)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated || vpc.IsException ? 'ds-c-button--disabled' : nothing}">Import</button>
+										<button @click="${() => view._showImportForm(region.Name, vpc.VPCID)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated || vpc.IsException ? 'ds-c-button--disabled' : nothing}">Import</button>
 										<button @click="${() => view._importVPC(region.Name, vpc.VPCID, true)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated || vpc.IsException ? 'ds-c-button--disabled' : nothing}">Import Legacy</button>
 										<button @click="${() => view._establishExceptionVPC(region.Name, vpc.VPCID)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated || vpc.IsException ? 'ds-c-button--disabled' : nothing}">Establish Exception</button>
 										<button @click="${() => view._renameVPC(region.Name, vpc.VPCID, vpc.Stack, vpc.Name)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated ? nothing : 'ds-c-button--disabled' }">Rename</button>
 										<button @click="${() => view._deleteVPC(region.Name, vpc.VPCID, vpc.Name)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated ? nothing : 'ds-c-button--disabled'}">Delete</button>
 										<button @click="${() => view._unimportVPC(region.Name, info.AccountID, vpc.VPCID, vpc.Name, vpc.VPCType)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsAutomated ? nothing : 'ds-c-button--disabled'}">Unimport</button>
+										<button @click="${() => view._updateExceptionTransitGateway(region.Name, vpc)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsException ? nothing : 'ds-c-button--disabled'}">Transit Gateway</button>
 										<button @click="${() => view._removeExceptionVPC(region.Name, info.AccountID, vpc.VPCID, vpc.Name)}" class="ds-c-button ds-c-button--hover ds-c-button--primary ds-c-button--small ${vpc.IsException ? nothing : 'ds-c-button--disabled'}">Remove Exception</button>
 									</td>
 									` : nothing}
@@ -341,10 +344,39 @@ export function AccountPage(info) {
 		this._showTask(response.json.TaskID);
 	}
 
-	this._importVPC = async function(region, vpcID, isLegacy) {
+	this._showImportForm = function(region, vpcID) {
+		this._showModal();
+		render(
+			html`
+			<div class="modalContainer">
+				<div class="modalTitle" style="text-align: left">Import ${vpcID}</div>
+				<div class="modalBody">
+					<form id="importVPC" @submit="${(e) => { e.preventDefault(); this._importVPC(region, vpcID, false, e.target); }}">
+						<input type="checkbox" id="importIPv6" name="ipv6" value="1" class="ds-c-choice ds-c-choice--small">
+						<label for="importIPv6">Add an IPv6 CIDR block if the VPC doesn't have one</label>
+						<label for="importIPv6Pool" class="ds-c-label">BYOIP pool</label>
+						<input type="text" id="importIPv6Pool" name="ipv6Pool" class="ds-c-field" placeholder="Amazon-provided /56">
+						<label for="importIPv6CIDR" class="ds-c-label">CIDR in pool</label>
+						<input type="text" id="importIPv6CIDR" name="ipv6CIDR" class="ds-c-field" placeholder="Any">
+						<div style="margin-top: 10px"><input type="submit" value="Import" class="ds-c-button ds-c-button--primary"></div>
+					</form>
+				</div>
+			</div>
+			`,
+			this._modal);
+	}
+
+	this._importVPC = async function(region, vpcID, isLegacy, form) {
+		const params = new URLSearchParams({legacy: isLegacy ? '1' : '0'});
+		if (form && form.ipv6.checked) {
+			params.set('ipv6', '1');
+			params.set('ipv6Pool', form.ipv6Pool.value.trim());
+			params.set('ipv6CIDR', form.ipv6CIDR.value.trim());
+		}
+		if (form) this._closeModal();
 		let response;
 		try {
-			response = await this._fetchJSON(info.ServerPrefix + region + "/vpc/" + info.AccountID + "/" + vpcID + '/import?legacy=' + (isLegacy ? '1' : '0'), {method: 'POST'});
+			response = await this._fetchJSON(info.ServerPrefix + region + "/vpc/" + info.AccountID + "/" + vpcID + '/import?' + params, {method: 'POST'});
 		} catch (err) {
 			alert('Error importing VPC: ' + err);
 			return;
@@ -363,6 +395,66 @@ export function AccountPage(info) {
 		this._showTask(response.json.TaskID);
 	}
 
+	this._getExceptionTransitGatewayStatus = function(vpc) {
+		const status = vpc.ExceptionTransitGateway;
+		if (!status) return nothing;
+		const routes = status.Routes || [];
+		const count = (routeStatus) => routes.filter(r => r.Status === routeStatus).length;
+		const attachment = status.TransitGatewayAttachmentID
+			? `${status.TransitGatewayAttachmentID} ${status.AttachmentState}`
+			: `Not attached to ${status.TransitGatewayID}`;
+		return html`
+			<div style="font-size: small" title="Checked ${new Date(status.CheckedAt).toLocaleString()}${status.DryRun ? ' (dry run)' : ''}">
+				${attachment}, ${count('present') + count('created')}/${routes.length} routes
+				${count('missing') ? html`, ${count('missing')} missing` : nothing}
+				${count('conflict') ? html`, <span title="${routes.filter(r => r.Status === 'conflict').map(r => `${r.RouteTableID}: ${r.Destination} -> ${r.Target}`).join('\n')}">${count('conflict')} conflicting</span>` : nothing}
+			</div>`;
+	}
+
+	this._updateExceptionTransitGateway = async function(region, vpc) {
+		let response;
+		try {
+			response = await this._fetchJSON(info.ServerPrefix + 'mtgas.json');
+		} catch (err) {
+			Growl.error('Error getting transit gateway templates: ' + err);
+			return;
+		}
+		const mtgas = (response.json || []).filter(mtga => mtga.Region === region);
+		if (!mtgas.length) {
+			Growl.error('There are no transit gateway templates in ' + region);
+			return;
+		}
+		const current = vpc.ExceptionTransitGateway;
+		const choice = prompt(`Which transit gateway template should ${vpc.Name} use? Enter its ID:\n${mtgas.map(mtga => mtga.ID + ': ' + mtga.Name).join('\n')}`, current ? current.ManagedTransitGatewayAttachmentID : mtgas[0].ID);
+		if (choice === null) {
+			// Cancelled
+			return;
+		}
+		const mtga = mtgas.find(mtga => String(mtga.ID) === choice.trim());
+		if (!mtga) {
+			Growl.error('Unknown transit gateway template ' + choice);
+			return;
+		}
+		const subnets = prompt('Which subnets should be attached? Separate subnet IDs with commas, or leave empty for the subnets tagged vpc-conf-layer=transitive.', current ? (current.SubnetIDs || []).join(', ') : '');
+		if (subnets === null) {
+			// Cancelled
+			return;
+		}
+		const dryRun = !confirm(`Attach ${vpc.Name} to ${mtga.Name} and add any missing routes? Cancel to only verify what is missing.`);
+		const req = {
+			ManagedTransitGatewayAttachmentID: mtga.ID,
+			SubnetIDs: subnets.split(',').map(id => id.trim()).filter(id => id),
+			DryRun: dryRun,
+		};
+		try {
+			response = await this._fetchJSON(info.ServerPrefix + region + "/vpc/" + info.AccountID + "/" + vpc.VPCID + '/exceptionTransitGateway', {method: 'POST', body: JSON.stringify(req)});
+		} catch (err) {
+			Growl.error('Error updating exception VPC transit gateway: ' + err);
+			return;
+		}
+		this._showTask(response.json.TaskID);
+	}
+
 	this._getRegionShortName = function(region) {
 		const shortRegion = {
 			'us-east-1': 'east',
@@ -0,0 +1,84 @@
+import {html, nothing, render} from '../lit-html/lit-html.js';
+import {Breadcrumb} from './components/shared/breadcrumb.js'
+import {Growl} from './components/shared/growl.js'
+import {HasModal, MakesAuthenticatedAJAXRequests} from './mixins.js';
+
+export function CMSNetHealthPage(info) {
+    this._loginURL = info.ServerPrefix + 'oauth/callback';
+    Object.assign(this, HasModal, MakesAuthenticatedAJAXRequests);
+
+    this.init = async function(container) {
+        Breadcrumb.set([{name: "CMSNet Health"}]);
+        render(
+            html`
+                <div id="background" class="hidden"></div>
+                <div id="modal" class="hidden"></div>
+                <div class="ds-l-container ds-u-padding--0">
+                    <div class="ds-l-row">
+                        <div class="ds-l-col--12">
+                            <div class="section-header ds-u-padding--1">CMSNet Health</div>
+                            <div id="health"></div>
+                        </div>
+                    </div>
+                </div>
+            `, container
+        );
+
+        this._modal = document.getElementById('modal');
+        this._background = document.getElementById('background');
+        this._health = document.getElementById('health');
+
+        this._loadHealth();
+        window.setInterval(() => this._loadHealth(), 30000);
+    }
+
+    this._loadHealth = async () => {
+        const url = info.ServerPrefix + 'cmsnet/health.json';
+        let response;
+        try {
+            response = await this._fetchJSON(url);
+        } catch (err) {
+            Growl.error('Error fetching CMSNet health: ' + err);
+            return;
+        }
+        const status = response.json;
+        const lastRun = status.LastRun;
+        render(
+            html`
+                <div class="ds-u-padding--1">
+                    ${lastRun
+                        ? html`
+                            <strong>Last check:</strong> ${new Date(lastRun.StartedAt).toLocaleString()}
+                            ${lastRun.Remediate ? '(with repair)' : nothing}
+                            - ${lastRun.VPCCount} VPCs
+                            ${lastRun.BatchTaskID ? html`in <a href="${info.ServerPrefix}batch">batch task ${lastRun.BatchTaskID}</a>` : nothing}`
+                        : html`<strong>Last check:</strong> none since vpc-conf started`}
+                </div>
+                ${status.VPCs.length
+                    ? html`
+                        <table>
+                            <thead>
+                                <th>VPC</th>
+                                <th>Account</th>
+                                <th>Region</th>
+                                <th>Issues</th>
+                            </thead>
+                            <tbody>
+                                ${status.VPCs.map(vpc => html`
+                                <tr>
+                                    <td><a href="${info.ServerPrefix}accounts/${vpc.AccountID}/vpc/${vpc.Region}/${vpc.VPCID}">${vpc.Name} (${vpc.VPCID})</a></td>
+                                    <td>${vpc.AccountID}</td>
+                                    <td>${vpc.Region}</td>
+                                    <td>
+                                        ${vpc.Issues.map(issue => html`<div>${issue.Description}${issue.IsFixable ? ' (fixable)' : ''}</div>`)}
+                                    </td>
+                                </tr>
+                                `)}
+                            </tbody>
+                        </table>`
+                    : html`<div class="ds-u-padding--1">No VPCs have CMSNet issues.</div>`}
+            `,
+            this._health
+        );
+    }
+}
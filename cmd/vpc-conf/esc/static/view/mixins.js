@@ -363,6 +363,7 @@ export var HasNewVPCForm = {
         CanProvision,
         AddContainersSubnets,
         AddFirewall,
+        IPv6,
     }) {
         const stacks = ['sandbox', 'dev', 'test', 'impl', 'mgmt', 'nonprod', 'qa', 'prod'];
         const subnetSizes = [20, 21, 22, 23, 24, 25, 26, 27, 28];
@@ -503,6 +504,22 @@ export var HasNewVPCForm = {
                         <label for="firewall">Add</label>
                     </div>
                 </div>
+
+                <div class="ds-l-form-row">
+                    <div class="ds-l-col--3">
+                        <label for="ipv6" class="ds-c-label">IPv6</label>
+                        <input type="checkbox" id="ipv6" name="ipv6" class="ds-c-choice ds-c-choice--small" value="1" ?checked="${!!IPv6}" ?disabled="${!CanProvision}">
+                        <label for="ipv6">Add (not with Network Firewall)</label>
+                    </div>
+                    <div class="ds-l-col--4">
+                        <label for="ipv6Pool" class="ds-c-label">BYOIP pool</label>
+                        <input type="text" id="ipv6Pool" name="ipv6Pool" class="ds-c-field" placeholder="Amazon-provided /56" .value="${IPv6 ? IPv6.Pool : ''}" ?disabled="${!CanProvision}">
+                    </div>
+                    <div class="ds-l-col--4">
+                        <label for="ipv6CIDR" class="ds-c-label">CIDR in pool</label>
+                        <input type="text" id="ipv6CIDR" name="ipv6CIDR" class="ds-c-field" placeholder="Any" .value="${IPv6 ? IPv6.CIDRBlock : ''}" ?disabled="${!CanProvision}">
+                    </div>
+                </div>
                 <div class="ds-l-form-row ds-u-align-items--end">
                     <div class="ds-l-col--12" style="text-align: right">
                         <input type="submit" class="ds-c-button ds-c-button--primary" value="Create VPC" ?disabled="${!CanProvision}">
@@ -542,6 +559,9 @@ export var HasNewVPCForm = {
                     IsDefaultDedicated: !!this._createVPCForm.dedicated.checked,
                     AddContainersSubnets: !!this._createVPCForm.containers.checked,
                     AddFirewall: !!this._createVPCForm.firewall.checked,
+                    IPv6: this._createVPCForm.ipv6.checked
+                        ? {Pool: this._createVPCForm.ipv6Pool.value.trim(), CIDRBlock: this._createVPCForm.ipv6CIDR.value.trim()}
+                        : null,
                 };
     
                 this._createVPCForm.reset();
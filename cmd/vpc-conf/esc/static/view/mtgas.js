@@ -73,6 +73,8 @@ export function ManagedTransitGatewayAttachmentsPage(info) {
             Routes: [...new Set([...prefixLists, ...CIDRS])],
             SubnetTypes: Array.from(f.querySelectorAll("[name=subnetType]")).filter(c => c.checked).map(c => c.value),
             IsDefault: f.isDefault.checked,
+            AssociateRouteTableID: f.associateRouteTableID.value.trim(),
+            PropagateRouteTableIDs: f.propagateRouteTableIDs.value.split(/[\s,]+/).filter(id => !!id),
         }
         Array.from(f.querySelectorAll("button")).forEach(b => b.disabled = true);
         Array.from(f.querySelectorAll("input")).forEach(b => b.disabled = true);
@@ -103,6 +105,8 @@ export function ManagedTransitGatewayAttachmentsPage(info) {
             TransitGatewayID: "",
             Region: "",
             Routes: [''],
+            AssociateRouteTableID: "",
+            PropagateRouteTableIDs: [],
             editing: true,
             SubnetTypes: ['Private','App','Data','Web','Transport','Security','Management','Shared','Shared-OC','Transitive'],
         });
@@ -301,6 +305,16 @@ export function ManagedTransitGatewayAttachmentsPage(info) {
                                     </select>
                                 </div>
                             </div>
+                            <div class="ds-l-row ds-u-padding--1">
+                                <div class="ds-l-col--2">
+                                    <label for="associateRouteTableID" class="ds-c-label ds-u-margin--0"><span class="tooltip" data-tooltip="Transit gateway route table to associate attachments with; leave blank to leave them alone">Associate Route Table</span></label>
+                                    <input id="associateRouteTableID" name="associateRouteTableID" class="ds-c-field input-medium" value="${mtga.AssociateRouteTableID || ''}" placeholder="tgw-rtb-">
+                                </div>
+                                <div class="ds-l-col--4">
+                                    <label for="propagateRouteTableIDs" class="ds-c-label ds-u-margin--0"><span class="tooltip" data-tooltip="Transit gateway route tables to propagate attachments' routes to, separated by commas">Propagate To Route Tables</span></label>
+                                    <input id="propagateRouteTableIDs" name="propagateRouteTableIDs" class="ds-c-field" value="${(mtga.PropagateRouteTableIDs || []).join(', ')}" placeholder="tgw-rtb-">
+                                </div>
+                            </div>
                             <div class="ds-l-row ds-u-padding--1">
                                 <div class="ds-l-col--auto">
                                     <span class="psuedo-ds-c-label">Connected Subnets</span>
@@ -390,6 +404,8 @@ export function ManagedTransitGatewayAttachmentsPage(info) {
                                 <div class="subnet-types">
                                     Connected Subnets: ${mtga.SubnetTypes.join(', ')}
                                 </div>
+                                ${mtga.AssociateRouteTableID ? html`<div class="subnet-types">Associated Route Table: ${mtga.AssociateRouteTableID}</div>` : nothing}
+                                ${(mtga.PropagateRouteTableIDs && mtga.PropagateRouteTableIDs.length) ? html`<div class="subnet-types">Propagates To: ${mtga.PropagateRouteTableIDs.join(', ')}</div>` : nothing}
                                 <div>
                                     ${mtga.Routes.map(route => html`<div class="alternating-row">${route}</div>`)}
                                 </div>
@@ -52,6 +52,103 @@ export function VPCPage(info) {
 		}
 		this._showTask(response.json.TaskID);
 	}
+
+	this._complianceReport = null;
+
+	this._checkCompliance = async () => {
+		try {
+			const response = await this._fetchJSON(info.ServerPrefix + info.Region + '/vpc/' + info.AccountID + '/' + info.VPCID + '/compliance.json');
+			this._complianceReport = response.json;
+		} catch (err) {
+			alert('Error checking V1 compliance: ' + err);
+			return;
+		}
+		this._renderV1Migration(this._migrationVPCType);
+	}
+
+	this._migrateToV1 = async () => {
+		const confirmed = confirm('Are you sure you want to migrate this VPC to V1? Untagged subnets will be tagged and the VPC will be imported as V1.');
+		if (!confirmed) return;
+
+		let response;
+		try {
+			response = await this._fetchJSON(info.ServerPrefix + info.Region + '/vpc/' + info.AccountID + '/' + info.VPCID + '/migrateToV1', {method: 'POST'});
+		} catch (err) {
+			alert('Error migrating VPC to V1: ' + err);
+			return;
+		}
+		this._complianceReport = null;
+		this._showTask(response.json.TaskID);
+	}
+
+	this._renderV1Migration = (vpcType) => {
+		this._migrationVPCType = vpcType;
+		const report = this._complianceReport;
+		render(
+			html`
+				${!VPCType.canMigrateToV1(vpcType)
+					? html`
+					<div class="ds-c-alert ds-c-alert--hide-icon">
+						<div class="ds-c-alert__body">
+							<p>Only Legacy VPCs can be migrated to V1</p>
+						</div>
+					</div>
+					`
+					: html`
+					<p>Checks how far this VPC is from the V1 structure and what migrating would change. Nothing is changed until you migrate.</p>
+					<div class="ds-l-form-row">
+						<div class="ds-l-col--auto">
+							<button type="button" @click="${() => this._checkCompliance()}" class="ds-c-button">Check Compliance</button>
+						</div>
+						<div class="ds-l-col--auto">
+							<button type="button" @click="${() => this._migrateToV1()}" class="ds-c-button ds-c-button--primary" ?disabled="${!User.isAdmin() || !report || !report.CanMigrate}">Migrate to V1</button>
+						</div>
+					</div>
+					${report
+						? html`
+						<div class="section-header-secondary">Compliance: ${report.Score}%</div>
+						${report.CanMigrate
+							? nothing
+							: html`
+							<div class="ds-c-alert ds-c-alert--warn" style="margin-top: 10px">
+								<div class="ds-c-alert__body">
+									<p class="ds-c-alert__text">The problems marked "Manual" have to be fixed before this VPC can be migrated.</p>
+								</div>
+							</div>
+						`}
+						<table class="standard-table">
+							<thead>
+								<tr style="background-color: #112E51;text-align: left">
+									<th>Check</th>
+									<th>Result</th>
+									<th>Problems</th>
+								</tr>
+							</thead>
+							<tbody>
+							${report.Checks.map(check => html`
+								<tr>
+									<td>${check.Name}</td>
+									<td>${check.Passed ? "Passed" : "Failed"}</td>
+									<td>
+										<ul>
+										${(check.Problems || []).map(p => html`
+											<li>${p.Description}. ${p.Remediation} (${p.Automatic ? "Automatic" : "Manual"})</li>
+										`)}
+										</ul>
+									</td>
+								</tr>
+							`)}
+							</tbody>
+						</table>
+						`
+						: nothing
+					}
+					`
+				}
+			`,
+			this._v1Migration
+		)
+	}
 	
 	this._setBreadcrumbs = (vpc) => {
 		Breadcrumb.set([{name: "Accounts", link: "/provision/accounts"},
@@ -172,9 +269,10 @@ export function VPCPage(info) {
 		const sgsURL = info.ServerPrefix + 'sgs.json';
 		const mrrsURL = info.ServerPrefix + 'mrrs.json';
 		const accountsURL = info.ServerPrefix + 'accounts/accounts.json';
+		const vpnsURL = info.ServerPrefix + 'vpns.json';
 		let responses;
 		try {
-			responses = await Promise.all([this._fetchJSON(vpcURL), this._fetchJSON(mtgaURL), this._fetchJSON(accountsURL), this._fetchJSON(sgsURL), this._fetchJSON(mrrsURL)]);
+			responses = await Promise.all([this._fetchJSON(vpcURL), this._fetchJSON(mtgaURL), this._fetchJSON(accountsURL), this._fetchJSON(sgsURL), this._fetchJSON(mrrsURL), this._fetchJSON(vpnsURL)]);
 		} catch (err) {
 			Growl.error('Error fetching account info: ' + err);
 			return;
@@ -183,6 +281,7 @@ export function VPCPage(info) {
 		const mtgasResponse = responses[1];
 		const sgsResponse = responses[3];
 		const mrrsResponse = responses[4];
+		const vpnsResponse = responses[5];
 		this._accounts = responses[2].json || [];
 		if (vpcResponse.text == vpcInfo) {
 			// No change to table.
@@ -191,7 +290,7 @@ export function VPCPage(info) {
 		}
 		vpcInfo = vpcResponse.text;
 		loadingVPCInfo = false;
-		this._renderVPCInfo(vpcResponse.json, mtgasResponse.json, sgsResponse.json, mrrsResponse.json, info.Region, info.ServerPrefix, info.AccountID, info.VPCID);
+		this._renderVPCInfo(vpcResponse.json, mtgasResponse.json, sgsResponse.json, mrrsResponse.json, vpnsResponse.json || [], info.Region, info.ServerPrefix, info.AccountID, info.VPCID);
 	}
 
 	const verifyCheckboxNames = [
@@ -459,6 +558,164 @@ export function VPCPage(info) {
 		);
 	}
 
+	this._addExternalPeeringConnection = function() {
+		const f = this._updateNetworking;
+		const epc = {
+			Keep: true,
+			Name: f.epcxName.value.trim(),
+			OtherVPCID: f.epcxOtherVPCID.value.trim(),
+			OtherVPCAccountID: f.epcxOtherVPCAccountID.value.trim(),
+			OtherVPCRegion: f.epcxOtherVPCRegion.value.trim(),
+			OtherVPCCIDRs: f.epcxOtherVPCCIDRs.value.split(/[\s,]+/).filter(cidr => !!cidr),
+			ConnectPrivate: !!(f.epcxConnectPrivate && f.epcxConnectPrivate.checked),
+			ConnectSubnetGroups: Array.from(f.querySelectorAll('[name="epcxConnectSubnetGroups"]')).filter(c => c.checked).map(c => c.value),
+		}
+		if (!epc.OtherVPCID || !epc.OtherVPCAccountID || !epc.OtherVPCRegion || !epc.OtherVPCCIDRs.length) {
+			alert('You must enter the other VPC\'s ID, account, region and CIDRs');
+			return;
+		}
+		if (!epc.ConnectPrivate && !epc.ConnectSubnetGroups.length) {
+			alert('You must select some subnets to connect');
+			return;
+		}
+		if (this._externalPeeringConnections.some(other => other.OtherVPCID == epc.OtherVPCID && other.OtherVPCRegion == epc.OtherVPCRegion)) {
+			alert('A peering connection to ' + epc.OtherVPCID + ' is already configured');
+			return;
+		}
+		this._externalPeeringConnections.push(epc);
+		this._showAddNewExternalPeeringConnection = false;
+		this._renderExternalPeeringConnections();
+	}
+
+	this._renderExternalPeeringConnections = function() {
+		function getSubnetGroupString(groups, connectPrivate) {
+			const sn = (groups || []).slice();
+			if (connectPrivate) sn.unshift('Private');
+			return sn.join(', ')
+		}
+		const terminalStatuses = ['deleted', 'deleting', 'rejected', 'expired', 'failed'];
+		const stateFor = epc => (this._externalPeeringConnectionStates || []).find(state =>
+			state.OtherVPCID == epc.OtherVPCID && state.OtherVPCRegion == epc.OtherVPCRegion && state.OtherVPCAccountID == epc.OtherVPCAccountID);
+		render(
+			html`
+			<table>
+				${this._externalPeeringConnections.length
+					? html`<thead>
+							<th>Subnets</th>
+							<th>Name</th>
+							<th>VPC</th>
+							<th>Account</th>
+							<th>CIDRs</th>
+							<th>Status</th>
+							<th style="text-align: center">Keep?</th>
+						</thead>`
+					: ''}
+				<tbody>
+					${this._externalPeeringConnections.map(epc => {
+						const state = stateFor(epc);
+						const isDead = state && terminalStatuses.indexOf(state.Status) != -1;
+						return html`
+						<tr>
+							<td>${getSubnetGroupString(epc.ConnectSubnetGroups, epc.ConnectPrivate)}</td>
+							<td>${epc.Name}</td>
+							<td>${epc.OtherVPCID} (${epc.OtherVPCRegion})</td>
+							<td>${epc.OtherVPCAccountID}</td>
+							<td>${epc.OtherVPCCIDRs.join(', ')}</td>
+							<td>
+								${state ? html`${state.PeeringConnectionID}: ${state.Status || (state.IsAccepted ? 'active' : 'pending-acceptance')}` : 'Not requested yet'}
+								${isDead
+									? html`<br><input type="checkbox" id="renew-${epc.OtherVPCID}" ?checked=${epc.Renew} @change="${(e) => epc.Renew = !!e.target.checked}" class="ds-c-choice ds-c-choice--small disableIfMigrating" ?disabled="${!User.isAdmin()}">
+										<label for="renew-${epc.OtherVPCID}" class="ds-c-label">Request again</label>`
+									: nothing}
+							</td>
+							<td class="disableIfMigrating" style="text-align: center"><input type="checkbox" ?checked=${epc.Keep} @change="${(e) => epc.Keep = !!e.target.checked}" ?disabled="${!User.isAdmin()}"></td>
+						</tr>
+					`})}
+				</tbody>
+			</table>
+			${this._showAddNewExternalPeeringConnection
+				? html`
+					<div class="ds-l-form-row">
+						<div class="ds-l-col--auto">
+							<label for="epcxName" class="ds-c-label ds-u-margin--0">Name</label>
+							<input id="epcxName" name="epcxName" class="ds-c-field input-medium" placeholder="vendor-prod">
+						</div>
+						<div class="ds-l-col--auto">
+							<label for="epcxOtherVPCID" class="ds-c-label ds-u-margin--0">VPC ID</label>
+							<input id="epcxOtherVPCID" name="epcxOtherVPCID" class="ds-c-field input-medium" placeholder="vpc-">
+						</div>
+						<div class="ds-l-col--auto">
+							<label for="epcxOtherVPCAccountID" class="ds-c-label ds-u-margin--0">Account ID</label>
+							<input id="epcxOtherVPCAccountID" name="epcxOtherVPCAccountID" class="ds-c-field input-medium">
+						</div>
+						<div class="ds-l-col--auto">
+							<label for="epcxOtherVPCRegion" class="ds-c-label ds-u-margin--0">Region</label>
+							<input id="epcxOtherVPCRegion" name="epcxOtherVPCRegion" class="ds-c-field input-medium" value="${info.Region}">
+						</div>
+						<div class="ds-l-col--auto">
+							<label for="epcxOtherVPCCIDRs" class="ds-c-label ds-u-margin--0"><span class="tooltip" data-tooltip="Separated by commas; routes are added once the connection is accepted">CIDRs</span></label>
+							<input id="epcxOtherVPCCIDRs" name="epcxOtherVPCCIDRs" class="ds-c-field input-medium">
+						</div>
+					</div>
+					<div style="margin-top: 10px">
+						${this._subnetGroups.some(group => group.SubnetType == "Private")
+							? html`
+								<input type="checkbox" id="epcxConnectPrivate" name="epcxConnectPrivate" class="ds-c-choice ds-c-choice--small">
+								<label for="epcxConnectPrivate" class="ds-c-label">Private</label>
+							`
+							: nothing
+						}
+						${this._validPeeringGroups(this._subnetGroups).map((group, idx) => html`
+							<input type="checkbox" id="epcxConnectSubnetGroup-${idx}" name="epcxConnectSubnetGroups" value="${group.Name}" class="ds-c-choice ds-c-choice--small">
+							<label for="epcxConnectSubnetGroup-${idx}" class="ds-c-label">${group.Name}</label>
+						`)}
+					</div>
+					<input type="button" value="Cancel" @click="${() => {this._showAddNewExternalPeeringConnection = false; this._renderExternalPeeringConnections()}}" class="ds-c-button ds-c-button--primary disableIfMigrating" ?disabled="${!User.isAdmin()}">
+					<input type="button" value="Add" @click="${() => this._addExternalPeeringConnection()}" class="ds-c-button ds-c-button--primary disableIfMigrating" ?disabled="${!User.isAdmin()}">
+				`
+				: html`<button @click="${(e) => {e.preventDefault(); this._showAddNewExternalPeeringConnection = true; this._renderExternalPeeringConnections()}}" class="ds-c-button ds-c-button--primary disableIfMigrating" ?disabled="${!User.isAdmin()}">Add New</button>`}
+			`,
+			this._externalPeeringConnectionsContainer
+		);
+	}
+
+	this._renderVPNConnectionStatus = function(info) {
+		const vpnConnections = info.VPNConnections || [];
+		render(
+			html`
+			${info.VPNError
+				? html`<div class="ds-c-alert ds-c-alert--error" style="margin-top: 10px"><div class="ds-c-alert__body">${info.VPNError}</div></div>`
+				: nothing}
+			${vpnConnections.length
+				? html`
+				<table style="margin-top: 10px">
+					<thead>
+						<th>Name</th>
+						<th>VPN Connection</th>
+						<th>State</th>
+						<th>Tunnels</th>
+					</thead>
+					<tbody>
+						${vpnConnections.map(vpn => html`
+						<tr>
+							<td>${vpn.Name}</td>
+							<td>${vpn.VPNConnectionID || 'Not created yet'}</td>
+							<td>${vpn.State || '-'}</td>
+							<td>
+								${vpn.Tunnels.map(tunnel => html`
+									<div>${tunnel.OutsideIPAddress}: ${tunnel.Status}${tunnel.StatusMessage ? ' (' + tunnel.StatusMessage + ')' : ''}</div>
+								`)}
+							</td>
+						</tr>
+						`)}
+					</tbody>
+				</table>`
+				: nothing}
+			`,
+			this._vpnConnectionStatusContainer
+		);
+	}
+
 	this._toggleUseReservedIP = function(useReservedIP) {
 		const container = document.getElementById('reservedPublicIPInput');
 		if (useReservedIP) {
@@ -530,6 +787,7 @@ export function VPCPage(info) {
 					{"name": "Security Groups", "id": "security-groups-tab", "adminOnly": false },
 					{"name": "Resolver Rules", "id": "resolver-rules-tab", "adminOnly": false},
 					{"name": "Network Firewall", "id": "network-firewall-tab", "adminOnly": false},
+					{"name": "V1 Migration", "id": "v1-migration-tab", "adminOnly": false},
 					{"name": "Task Logs", "id": "task-logs-tab", "adminOnly": false},
 					{"name": "Labels", "id": "labels-tab", "adminOnly": false}]
 
@@ -767,6 +1025,11 @@ export function VPCPage(info) {
 							</div>
 						</div>
 
+						<div id="v1-migration-tab" class="tab-content">
+							<div class="ds-l-col--8 ds-u-padding--0" id="v1Migration">
+							</div>
+						</div>
+
 						<div id="task-logs-tab" class="tab-content ds-l-row ds-u-margin--0">
 							<div class="ds-l-col--8 ds-u-padding--0">
 								<div id="tasks"></div>
@@ -813,6 +1076,7 @@ export function VPCPage(info) {
 		this._verifyForm = document.getElementById('verify');
 		this._vpcType = document.getElementById('vpcType');
 		this._networkFirewall = document.getElementById('networkFirewall');
+		this._v1Migration = document.getElementById('v1Migration');
 
 		this._listenForCancelEvent(container);
 		this._listenForShowTaskEvents(container);
@@ -834,11 +1098,15 @@ export function VPCPage(info) {
 				}
 			}
 
+			const externalPeeringConnections = this._externalPeeringConnections.filter(epc => epc.Keep);
 			const config = {
 				ConnectPublic: this._updateNetworking.connectPublic && !!this._updateNetworking.connectPublic.checked,
 				ConnectPrivate: this._updateNetworking.connectPrivate && !!this._updateNetworking.connectPrivate.checked,
 				ManagedTransitGatewayAttachmentIDs: Array.from(this._updateNetworking.querySelectorAll("[name=mtga]")).filter(inp => inp.checked).map(inp => +inp.value),
+				VPNConnectionIDs: Array.from(this._updateNetworking.querySelectorAll("[name=vpn]")).filter(inp => inp.checked).map(inp => +inp.value),
 				PeeringConnections: peeringConnections,
+				ExternalPeeringConnections: externalPeeringConnections,
+				RenewExternalPeeringConnections: externalPeeringConnections.filter(epc => epc.Renew).map(epc => epc.OtherVPCID),
 			};
 
 			let response;
@@ -1064,8 +1332,9 @@ export function VPCPage(info) {
 	}
 
 	let firstRender = true;
-	this._renderVPCInfo = function(info, mtgas, sgss, mrrs, region, serverPrefix, accountID, vpcID) {
+	this._renderVPCInfo = function(info, mtgas, sgss, mrrs, vpns, region, serverPrefix, accountID, vpcID) {
 		const allMTGAs = mtgas.filter(mtga => mtga.Region == region);
+		const allVPNs = vpns.filter(vpn => vpn.Region == region && vpn.IsGovCloud == info.IsGovCloud);
 		const allMRRs = mrrs.filter(mrr => mrr.IsGovCloud == info.IsGovCloud).filter(mrr => mrr.AccountID != 0);
 		const allSGSs = sgss.filter(sgs => sgs.Region == region) || [];
 		const mtgasByTGID = this._mtgasByTGID(mtgas, info);
@@ -1095,6 +1364,7 @@ export function VPCPage(info) {
 			this._updateZonedSubnetGroupName();
 			firstRender = false;
 			this._peeringConnections = [];
+			this._externalPeeringConnections = (info.Config.ExternalPeeringConnections || []).map(epc => Object.assign({Keep: true}, epc));
 			render(
 				html`
 					${info.IsLegacy
@@ -1118,11 +1388,25 @@ export function VPCPage(info) {
 					</div>
 
 
+					<div class="section-header-secondary">VPN Connections</div>
+					<div class="section-body-bordered">
+					${allVPNs.map(vpn => html`
+						<input type="checkbox" id="vpn${vpn.ID}" name="vpn" value="${vpn.ID}" ?checked="${(info.Config.VPNConnectionIDs || []).indexOf(vpn.ID) != -1}" class="ds-c-choice ds-c-choice--small disableIfMigrating" ?disabled="${!User.isAdmin()}">
+						<label for="vpn${vpn.ID}" class="ds-c-label">${vpn.Name}</label>
+					`)}
+						<div id="vpnConnectionStatus"></div>
+					</div>
+
 					<div class="section-header-secondary">Peering Connections</div>
 					<div class="section-body-bordered">
 						<div id="peeringConnections"></div>
 					</div>
 
+					<div class="section-header-secondary">External Peering Connections</div>
+					<div class="section-body-bordered">
+						<div id="externalPeeringConnections"></div>
+					</div>
+
 				<input type="submit" value="Update" class="ds-c-button ds-c-button--primary disableIfMigrating" ?disabled="${!User.isAdmin()}">
 				`,
 				this._updateNetworking
@@ -1156,6 +1440,8 @@ export function VPCPage(info) {
 				this._updateResolverRules
 			);
 			this._peeringConnectionsContainer = document.getElementById('peeringConnections');
+			this._externalPeeringConnectionsContainer = document.getElementById('externalPeeringConnections');
+			this._vpnConnectionStatusContainer = document.getElementById('vpnConnectionStatus');
 		}
 		(info.Config.PeeringConnections || []).forEach(pc => {
 			let alreadyAdded = false;
@@ -1211,6 +1497,7 @@ export function VPCPage(info) {
 			`,
 			this._networkFirewall
 		)
+		this._renderV1Migration(info.VPCType);
 
 		// Show "update networking" and "add zoned subnets" form.
 		this._updateNetworking.className = '';
@@ -1455,6 +1742,9 @@ export function VPCPage(info) {
 			}
 		}
 		if (this._hasValidPeeringGroups(info.SubnetGroups)) { this._renderPeeringConnections() };
+		this._externalPeeringConnectionStates = info.ExternalPeeringConnections;
+		this._renderExternalPeeringConnections();
+		this._renderVPNConnectionStatus(info);
 		this._disableIfMigrating(info.VPCType);
 	}
 
@@ -228,6 +228,7 @@ export function VPCRequestPage(info) {
                     CanProvision: this._describeRequestStatus(req.Status) == "Approved" && User.isAdmin(),
                     AddContainersSubnets: req.RequestedConfig.AddContainersSubnets,
                     AddFirewall: req.RequestedConfig.AddFirewall,
+                    IPv6: req.RequestedConfig.IPv6,
                 },
             )
         }
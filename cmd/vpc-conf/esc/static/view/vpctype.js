@@ -7,6 +7,7 @@ const vpcTypeException = 2;
 const vpcTypeV1Firewall = 3;
 const vpcTypeMigratingV1ToV1Firewall = 4;
 const vpcTypeMigratingV1FirewallToV1 = 5;
+const vpcTypeMigratingLegacyToV1 = 6;
 
 export const VPCType = {
      canAddFirewall: function(vpcType) {
@@ -14,6 +15,9 @@ export const VPCType = {
     },
      canRemoveFirewall: function(vpcType) {
         return vpcType === vpcTypeV1Firewall || this.isFirewallMigrationType(vpcType);   
+    },
+     canMigrateToV1: function(vpcType) {
+        return vpcType === vpcTypeLegacy;
     },
      isFirewallMigrationType: function(vpcType) {
         return vpcType === vpcTypeMigratingV1ToV1Firewall || vpcType === vpcTypeMigratingV1FirewallToV1;
@@ -34,6 +38,8 @@ export const VPCType = {
             template = html`<span class="tooltip warn" data-tooltip="Migrating from Version 1 to Version 1 Firewall">V1&#x2192V1FW</span>`;
         } else if (vpcType === vpcTypeMigratingV1FirewallToV1) {
             template = html`<span class="tooltip warn" data-tooltip="Migrating from Version 1 Firewall to Version 1">V1FW&#x2192V1</span>`;
+        } else if (vpcType === vpcTypeMigratingLegacyToV1) {
+            template = html`<span class="tooltip warn" data-tooltip="Migrating from Legacy to Version 1">LEG&#x2192V1</span>`;
         }
         
         return template;
@@ -54,6 +60,8 @@ export const VPCType = {
             template = html`<span class="ds-c-badge ds-c-badge--warn">Migrating Version 1 &#x2192 Version 1 Firewall</span>`;
         } else if (vpcType === vpcTypeMigratingV1FirewallToV1) {
             template = html`<span class="ds-c-badge ds-c-badge--warn">Migrating Version 1 Firewall &#x2192 Version 1</span>`;
+        } else if (vpcType === vpcTypeMigratingLegacyToV1) {
+            template = html`<span class="ds-c-badge ds-c-badge--warn">Migrating Legacy &#x2192 Version 1</span>`;
         }
         
         return template;
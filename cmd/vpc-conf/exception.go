@@ -0,0 +1,487 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ram/ramiface"
+)
+
+// exceptionVPCSubnets returns the subnets to attach to the transit gateway:
+// the requested ones, or if none were requested the subnets tagged
+// vpc-conf-layer=transitive. An attachment can only use one subnet per AZ.
+func exceptionVPCSubnets(ec2svc ec2iface.EC2API, vpcID string, requested []string) ([]string, error) {
+	input := &ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: aws.StringSlice([]string{vpcID}),
+			},
+		},
+	}
+	if len(requested) == 0 {
+		input.Filters = append(input.Filters, &ec2.Filter{
+			Name:   aws.String("tag:vpc-conf-layer"),
+			Values: aws.StringSlice([]string{"transitive"}),
+		})
+	}
+	out, err := ec2svc.DescribeSubnets(input)
+	if err != nil {
+		return nil, fmt.Errorf("Error describing subnets: %s", err)
+	}
+	azBySubnetID := map[string]string{}
+	for _, subnet := range out.Subnets {
+		azBySubnetID[aws.StringValue(subnet.SubnetId)] = aws.StringValue(subnet.AvailabilityZone)
+	}
+
+	subnetIDs := requested
+	if len(requested) == 0 {
+		if len(azBySubnetID) == 0 {
+			return nil, fmt.Errorf("No subnets were selected and none are tagged vpc-conf-layer=transitive")
+		}
+		subnetIDs = []string{}
+		for subnetID := range azBySubnetID {
+			subnetIDs = append(subnetIDs, subnetID)
+		}
+	}
+	subnetIDs = append([]string{}, subnetIDs...) // copy
+	sort.Strings(subnetIDs)
+
+	subnetIDByAZ := map[string]string{}
+	for _, subnetID := range subnetIDs {
+		az, ok := azBySubnetID[subnetID]
+		if !ok {
+			return nil, fmt.Errorf("Subnet %s is not in VPC %s", subnetID, vpcID)
+		}
+		if other, ok := subnetIDByAZ[az]; ok {
+			return nil, fmt.Errorf("Subnets %s and %s are both in %s but a transit gateway attachment can only use one subnet per AZ", other, subnetID, az)
+		}
+		subnetIDByAZ[az] = subnetID
+	}
+	return subnetIDs, nil
+}
+
+func routeDestination(route *ec2.Route) string {
+	if id := aws.StringValue(route.DestinationPrefixListId); id != "" {
+		return id
+	}
+	if cidr := aws.StringValue(route.DestinationIpv6CidrBlock); cidr != "" {
+		return cidr
+	}
+	return aws.StringValue(route.DestinationCidrBlock)
+}
+
+// inspectExceptionVPCRoutes checks every route table in the VPC, including the
+// main route table whether or not any subnet uses it, for a route to the
+// transit gateway for each destination.
+func inspectExceptionVPCRoutes(ec2svc ec2iface.EC2API, vpcID, transitGatewayID string, destinations []string) ([]*database.ExceptionRouteStatus, error) {
+	out, err := ec2svc.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: aws.StringSlice([]string{vpcID}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing route tables: %s", err)
+	}
+	routeTables := out.RouteTables
+	sort.Slice(routeTables, func(i, j int) bool {
+		return aws.StringValue(routeTables[i].RouteTableId) < aws.StringValue(routeTables[j].RouteTableId)
+	})
+
+	statuses := []*database.ExceptionRouteStatus{}
+	for _, rt := range routeTables {
+		targets := map[string]string{}
+		for _, route := range rt.Routes {
+			targets[routeDestination(route)] = routeTarget(route)
+		}
+		for _, destination := range destinations {
+			status := &database.ExceptionRouteStatus{
+				RouteTableID: aws.StringValue(rt.RouteTableId),
+				Destination:  destination,
+				Status:       database.ExceptionRouteMissing,
+			}
+			if target, ok := targets[destination]; ok {
+				if target == transitGatewayID {
+					status.Status = database.ExceptionRoutePresent
+				} else {
+					status.Status = database.ExceptionRouteConflict
+					status.Target = target
+				}
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+// recordExceptionVPCAttachment keeps the attachment in the state like a
+// managed attachment, replacing any earlier one to the same transit gateway.
+func recordExceptionVPCAttachment(state *database.VPCState, status *database.ExceptionTransitGatewayStatus) {
+	attachment := &database.TransitGatewayAttachment{
+		ManagedTransitGatewayAttachmentIDs: []uint64{status.ManagedTransitGatewayAttachmentID},
+		TransitGatewayID:                   status.TransitGatewayID,
+		TransitGatewayAttachmentID:         status.TransitGatewayAttachmentID,
+		SubnetIDs:                          status.SubnetIDs,
+	}
+	for idx, tga := range state.TransitGatewayAttachments {
+		if tga.TransitGatewayID == status.TransitGatewayID {
+			state.TransitGatewayAttachments[idx] = attachment
+			return
+		}
+	}
+	state.TransitGatewayAttachments = append(state.TransitGatewayAttachments, attachment)
+}
+
+func (taskContext *TaskContext) performUpdateExceptionVPCTask(config *database.UpdateExceptionVPCTaskData) {
+	t := taskContext.Task
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+	if config.DryRun {
+		t.Log("Verifying exception VPC transit gateway attachment and routes. Nothing will be changed.")
+	}
+
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.Region, config.VPCID)
+	if err != nil {
+		t.Log("Error loading state: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State == nil || vpc.State.VPCType != database.VPCTypeException {
+		t.Log("This is only allowed for Exception VPCs")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	managedAttachments, err := taskContext.ModelsManager.GetManagedTransitGatewayAttachments()
+	if err != nil {
+		t.Log("Error getting transit gateway configuration info: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	var ma *database.ManagedTransitGatewayAttachment
+	for _, managed := range managedAttachments {
+		if managed.ID == config.ManagedTransitGatewayAttachmentID {
+			ma = managed
+		}
+	}
+	if ma == nil {
+		t.Log("Invalid managed attachment ID: %d", config.ManagedTransitGatewayAttachmentID)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if ma.Region != vpc.Region {
+		t.Log("Managed attachment %s is in %s, not %s", ma.Name, ma.Region, vpc.Region)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	ctx := &awsp.Context{
+		AWSAccountAccess: taskContext.BaseAWSAccountAccess,
+		Logger:           t,
+		VPCName:          vpc.Name,
+		VPCID:            vpc.ID,
+	}
+	status := &database.ExceptionTransitGatewayStatus{
+		ManagedTransitGatewayAttachmentID: ma.ID,
+		TransitGatewayID:                  ma.TransitGatewayID,
+		DryRun:                            config.DryRun,
+		CheckedAt:                         time.Now(),
+	}
+	err = taskContext.updateExceptionVPC(ctx, vpc, ma, config, status)
+
+	vpc.State.ExceptionTransitGateway = status
+	if status.TransitGatewayAttachmentID != "" && !config.DryRun {
+		recordExceptionVPCAttachment(vpc.State, status)
+	}
+	stateErr := vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if stateErr != nil {
+		t.Log("Error updating state: %s", stateErr)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	conflicts := status.Conflicts()
+	for _, route := range conflicts {
+		t.Log("Route %s on route table %s goes to %s instead of %s", route.Destination, route.RouteTableID, route.Target, ma.TransitGatewayID)
+	}
+	if len(conflicts) > 0 {
+		t.Log("%d routes were left alone and must be fixed by hand", len(conflicts))
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+// updateExceptionVPC shares the transit gateway, attaches the subnets and adds
+// the routes, filling in status as it goes. Nothing is ever removed.
+func (taskContext *TaskContext) updateExceptionVPC(
+	ctx *awsp.Context,
+	vpc *database.VPC,
+	ma *database.ManagedTransitGatewayAttachment,
+	config *database.UpdateExceptionVPCTaskData,
+	status *database.ExceptionTransitGatewayStatus) error {
+
+	asUser := taskContext.AsUser
+	tgID := ma.TransitGatewayID
+
+	subnetIDs, err := exceptionVPCSubnets(ctx.EC2(), vpc.ID, config.SubnetIDs)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetTransitGatewayVPCAttachment(tgID)
+	if err != nil {
+		return fmt.Errorf("Error getting transit gateway attachment: %s", err)
+	}
+	attachedSubnetIDs := []string{}
+	if existing != nil {
+		status.TransitGatewayAttachmentID = aws.StringValue(existing.TransitGatewayAttachmentId)
+		status.AttachmentState = aws.StringValue(existing.State)
+		attachedSubnetIDs = aws.StringValueSlice(existing.SubnetIds)
+	}
+	missingSubnetIDs := []string{}
+	for _, subnetID := range subnetIDs {
+		if !stringInSlice(subnetID, attachedSubnetIDs) {
+			missingSubnetIDs = append(missingSubnetIDs, subnetID)
+		}
+	}
+
+	if config.DryRun {
+		status.SubnetIDs = attachedSubnetIDs
+		if existing == nil {
+			ctx.Log("Transit gateway %s is not attached. Would attach subnets %s", tgID, strings.Join(subnetIDs, ", "))
+		} else if len(missingSubnetIDs) > 0 {
+			ctx.Log("Would add subnets %s to transit gateway attachment %s", strings.Join(missingSubnetIDs, ", "), status.TransitGatewayAttachmentID)
+		}
+	} else {
+		share, shareEC2, err := shareTransitGateway(ctx, taskContext.ModelsManager, vpc.Region, tgID, vpc.AccountID,
+			func(accountID string) (ec2iface.EC2API, ramiface.RAMAPI, error) {
+				access, err := taskContext.AWSAccountAccessProvider.AccessAccount(accountID, string(vpc.Region), asUser)
+				if err != nil {
+					return nil, nil, fmt.Errorf("Error getting credentials for account %s: %s", accountID, err)
+				}
+				return access.EC2(), access.RAM(), nil
+			})
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			// If we just shared it we might need to wait for it to appear
+			ctx.Log("Waiting for transit gateway to be available")
+			err := ctx.WaitForTransitGatewayStatus(tgID, "available")
+			if err != nil {
+				return fmt.Errorf("Error waiting for transit gateway status: %s", err)
+			}
+			name := transitGatewayAttachmentName(vpc.Name, ma.Name)
+			status.TransitGatewayAttachmentID, err = ctx.CreateTransitGatewayVPCAttachment(name, tgID, subnetIDs, false)
+			if err != nil {
+				return fmt.Errorf("Error creating transit gateway attachment %s: %s", name, err)
+			}
+			attachedSubnetIDs = subnetIDs
+		} else if len(missingSubnetIDs) > 0 {
+			ctx.Log("Adding subnets %s to transit gateway attachment %s", strings.Join(missingSubnetIDs, ", "), status.TransitGatewayAttachmentID)
+			_, err := ctx.EC2().ModifyTransitGatewayVpcAttachment(&ec2.ModifyTransitGatewayVpcAttachmentInput{
+				TransitGatewayAttachmentId: aws.String(status.TransitGatewayAttachmentID),
+				AddSubnetIds:               aws.StringSlice(missingSubnetIDs),
+			})
+			if err != nil {
+				return fmt.Errorf("Error updating transit gateway attachment: %s", err)
+			}
+			ctx.Log("Waiting for transit gateway attachment to become available")
+			_, err = ctx.WaitForTransitGatewayVpcAttachmentStatus(status.TransitGatewayAttachmentID, []string{"available"}, []string{"modifying"})
+			if err != nil {
+				return fmt.Errorf("Error waiting for transit gateway attachment status: %s", err)
+			}
+			attachedSubnetIDs = append(attachedSubnetIDs, missingSubnetIDs...)
+		}
+		status.SubnetIDs = attachedSubnetIDs
+
+		managedAttachmentsByID := map[uint64]*database.ManagedTransitGatewayAttachment{ma.ID: ma}
+		err = addOrUpdateTGAttachmentTags(ctx, managedAttachmentsByID, []uint64{ma.ID}, status.TransitGatewayAttachmentID)
+		if err != nil {
+			return fmt.Errorf("Error updating tags on transit gateway attachment %s: %s", status.TransitGatewayAttachmentID, err)
+		}
+		err = acceptTransitGatewayAttachment(ctx, tgID, status.TransitGatewayAttachmentID, share, shareEC2)
+		if err != nil {
+			return err
+		}
+		status.AttachmentState = "available"
+	}
+
+	// Routes
+	vpcOut, err := ctx.EC2().DescribeVpcs(&ec2.DescribeVpcsInput{
+		VpcIds: aws.StringSlice([]string{vpc.ID}),
+	})
+	if err != nil {
+		return fmt.Errorf("Error describing VPC: %s", err)
+	}
+	hasIPv6 := len(vpcOut.Vpcs) == 1 && len(vpcOut.Vpcs[0].Ipv6CidrBlockAssociationSet) > 0
+	destinations := []string{}
+	for _, route := range ma.Routes {
+		if awsp.IsIPv6CIDR(route) && !hasIPv6 {
+			ctx.Log("Skipping IPv6 route %s because VPC %s is not dual-stack", route, vpc.ID)
+			continue
+		}
+		destinations = append(destinations, route)
+	}
+	status.Routes, err = inspectExceptionVPCRoutes(ctx.EC2(), vpc.ID, tgID, destinations)
+	if err != nil {
+		return err
+	}
+
+	missing := []*database.ExceptionRouteStatus{}
+	prefixListIDs := []string{}
+	for _, route := range status.Routes {
+		if route.Status != database.ExceptionRouteMissing {
+			continue
+		}
+		missing = append(missing, route)
+		if awsp.IsPrefixListID(route.Destination) && !stringInSlice(route.Destination, prefixListIDs) {
+			prefixListIDs = append(prefixListIDs, route.Destination)
+		}
+	}
+	if config.DryRun {
+		for _, route := range missing {
+			ctx.Log("Would create route %s -> %s on route table %s", route.Destination, tgID, route.RouteTableID)
+		}
+		return nil
+	}
+
+	if len(prefixListIDs) > 0 {
+		prefixListAccountID := prefixListAccountIDCommercial
+		if vpc.Region.IsGovCloud() {
+			prefixListAccountID = prefixListAccountIDGovCloud
+		}
+		access, err := taskContext.AWSAccountAccessProvider.AccessAccount(prefixListAccountID, string(vpc.Region), asUser)
+		if err != nil {
+			return fmt.Errorf("Error getting credentials for account %s: %s", prefixListAccountID, err)
+		}
+		err = ensurePrefixListSharedWithAccount(ctx, access.RAM(), prefixListIDs, vpc.Region, vpc.AccountID)
+		if err != nil {
+			return fmt.Errorf("Error ensuring configured Prefix Lists are shared via RAM: %s", err)
+		}
+	}
+	for _, route := range missing {
+		_, err := setRoute(ctx, route.RouteTableID, route.Destination, nil, &database.RouteInfo{
+			TransitGatewayID: tgID,
+		})
+		if err != nil {
+			return fmt.Errorf("Error updating route table %s: %s", route.RouteTableID, err)
+		}
+		route.Status = database.ExceptionRouteCreated
+	}
+	return nil
+}
+
+// ExceptionTransitGatewayRequest is the body of a request to attach an
+// Exception VPC to a transit gateway.
+type ExceptionTransitGatewayRequest struct {
+	ManagedTransitGatewayAttachmentID uint64
+	SubnetIDs                         []string
+	DryRun                            bool
+}
+
+var handleVPCUpdateExceptionTransitGateway = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleVPCUpdateExceptionTransitGateway but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	req := new(ExceptionTransitGatewayRequest)
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil || vpc.State.VPCType != database.VPCTypeException {
+		http.Error(w, fmt.Sprintf("VPC %s is not an exception VPC", vpcID), http.StatusBadRequest)
+		return
+	}
+	managedAttachments, err := s.ModelsManager.GetManagedTransitGatewayAttachments()
+	if err != nil {
+		log.Printf("Error getting managed transit gateway attachments: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	var ma *database.ManagedTransitGatewayAttachment
+	for _, managed := range managedAttachments {
+		if managed.ID == req.ManagedTransitGatewayAttachmentID {
+			ma = managed
+		}
+	}
+	if ma == nil {
+		http.Error(w, fmt.Sprintf("Unknown managed transit gateway attachment %d", req.ManagedTransitGatewayAttachmentID), http.StatusBadRequest)
+		return
+	}
+	if ma.Region != vpc.Region {
+		http.Error(w, fmt.Sprintf("Managed transit gateway attachment %s is in %s, not %s", ma.Name, ma.Region, vpc.Region), http.StatusBadRequest)
+		return
+	}
+
+	taskData := &database.TaskData{
+		UpdateExceptionVPCTaskData: &database.UpdateExceptionVPCTaskData{
+			VPCID:                             vpcID,
+			Region:                            database.Region(region),
+			ManagedTransitGatewayAttachmentID: ma.ID,
+			SubnetIDs:                         req.SubnetIDs,
+			DryRun:                            req.DryRun,
+		},
+		AsUser: s.getSession(r).Username,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		log.Printf("Error marshaling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	description := fmt.Sprintf("Attach exception VPC %s to %s", vpcID, ma.Name)
+	if req.DryRun {
+		description = fmt.Sprintf("Verify exception VPC %s attachment to %s", vpcID, ma.Name)
+	}
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, description, taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
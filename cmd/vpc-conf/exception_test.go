@@ -0,0 +1,139 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/google/go-cmp/cmp"
+)
+
+type exceptionEC2 struct {
+	ec2iface.EC2API
+
+	subnets     []*ec2.Subnet
+	routeTables []*ec2.RouteTable
+}
+
+func (m *exceptionEC2) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	out := &ec2.DescribeSubnetsOutput{}
+	for _, subnet := range m.subnets {
+		matches := true
+		for _, filter := range input.Filters {
+			if aws.StringValue(filter.Name) == "tag:vpc-conf-layer" && !hasTag(subnet.Tags, "vpc-conf-layer", aws.StringValue(filter.Values[0])) {
+				matches = false
+			}
+		}
+		if matches {
+			out.Subnets = append(out.Subnets, subnet)
+		}
+	}
+	return out, nil
+}
+
+func (m *exceptionEC2) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	return &ec2.DescribeRouteTablesOutput{RouteTables: m.routeTables}, nil
+}
+
+func TestExceptionVPCSubnets(t *testing.T) {
+	m := &exceptionEC2{
+		subnets: []*ec2.Subnet{
+			complianceSubnet("subnet-transit-b", "us-east-1b", "vpc-conf-layer", "transitive"),
+			complianceSubnet("subnet-transit-a", "us-east-1a", "vpc-conf-layer", "transitive"),
+			complianceSubnet("subnet-app-a", "us-east-1a", "vpc-conf-layer", "app"),
+			complianceSubnet("subnet-app-c", "us-east-1c"),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		requested     []string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:     "Transitive subnets by default",
+			expected: []string{"subnet-transit-a", "subnet-transit-b"},
+		},
+		{
+			name:      "Requested subnets",
+			requested: []string{"subnet-app-c", "subnet-app-a"},
+			expected:  []string{"subnet-app-a", "subnet-app-c"},
+		},
+		{
+			name:          "Two subnets in one AZ",
+			requested:     []string{"subnet-app-a", "subnet-transit-a"},
+			expectedError: "Subnets subnet-app-a and subnet-transit-a are both in us-east-1a but a transit gateway attachment can only use one subnet per AZ",
+		},
+		{
+			name:          "Subnet in another VPC",
+			requested:     []string{"subnet-other"},
+			expectedError: "Subnet subnet-other is not in VPC vpc-1",
+		},
+	}
+	for _, tc := range testCases {
+		subnetIDs, err := exceptionVPCSubnets(m, "vpc-1", tc.requested)
+		if tc.expectedError != "" {
+			if err == nil || err.Error() != tc.expectedError {
+				t.Errorf("%s: expected error %q but got %v", tc.name, tc.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, subnetIDs); diff != "" {
+			t.Errorf("%s: expected subnets did not match actual: \n%s", tc.name, diff)
+		}
+	}
+
+	_, err := exceptionVPCSubnets(&exceptionEC2{}, "vpc-1", nil)
+	if err == nil {
+		t.Errorf("Expected an error when no subnets are tagged transitive")
+	}
+}
+
+func TestInspectExceptionVPCRoutes(t *testing.T) {
+	m := &exceptionEC2{
+		routeTables: []*ec2.RouteTable{
+			{
+				RouteTableId: aws.String("rtb-main"),
+				Routes: []*ec2.Route{
+					{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local")},
+				},
+			},
+			{
+				RouteTableId: aws.String("rtb-app"),
+				Routes: []*ec2.Route{
+					{DestinationPrefixListId: aws.String("pl-1"), TransitGatewayId: aws.String("tgw-new")},
+					{DestinationPrefixListId: aws.String("pl-2"), TransitGatewayId: aws.String("tgw-old")},
+					{DestinationCidrBlock: aws.String("10.128.0.0/9"), VpcPeeringConnectionId: aws.String("pcx-1")},
+				},
+			},
+		},
+	}
+
+	statuses, err := inspectExceptionVPCRoutes(m, "vpc-1", "tgw-new", []string{"pl-1", "pl-2", "10.128.0.0/9"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []*database.ExceptionRouteStatus{
+		{RouteTableID: "rtb-app", Destination: "pl-1", Status: database.ExceptionRoutePresent},
+		{RouteTableID: "rtb-app", Destination: "pl-2", Status: database.ExceptionRouteConflict, Target: "tgw-old"},
+		{RouteTableID: "rtb-app", Destination: "10.128.0.0/9", Status: database.ExceptionRouteConflict, Target: "pcx-1"},
+		{RouteTableID: "rtb-main", Destination: "pl-1", Status: database.ExceptionRouteMissing},
+		{RouteTableID: "rtb-main", Destination: "pl-2", Status: database.ExceptionRouteMissing},
+		{RouteTableID: "rtb-main", Destination: "10.128.0.0/9", Status: database.ExceptionRouteMissing},
+	}
+	if diff := cmp.Diff(expected, statuses); diff != "" {
+		t.Errorf("Expected route statuses did not match actual: \n%s", diff)
+	}
+
+	status := &database.ExceptionTransitGatewayStatus{Routes: statuses}
+	if len(status.Conflicts()) != 2 {
+		t.Errorf("Expected 2 conflicts but got %d", len(status.Conflicts()))
+	}
+}
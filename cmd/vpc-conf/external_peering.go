@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// Peering connection statuses after which the connection can never become
+// active, whichever side caused them.
+var terminalPeeringConnectionStatuses = []string{
+	ec2.VpcPeeringConnectionStateReasonCodeDeleted,
+	ec2.VpcPeeringConnectionStateReasonCodeDeleting,
+	ec2.VpcPeeringConnectionStateReasonCodeRejected,
+	ec2.VpcPeeringConnectionStateReasonCodeExpired,
+	ec2.VpcPeeringConnectionStateReasonCodeFailed,
+}
+
+func externalPeeringConnectionName(vpcName string, config *database.ExternalPeeringConnectionConfig) string {
+	otherName := config.Name
+	if otherName == "" {
+		otherName = config.OtherVPCID
+	}
+	return peeringConnectionName(vpcName, otherName)
+}
+
+func isExternalPeer(state *database.ExternalPeeringConnection, config *database.ExternalPeeringConnectionConfig) bool {
+	return state.OtherVPCID == config.OtherVPCID && state.OtherVPCRegion == config.OtherVPCRegion && state.OtherVPCAccountID == config.OtherVPCAccountID
+}
+
+// getPeeringConnectionStatus treats a peering connection that AWS no longer
+// knows about as deleted.
+func getPeeringConnectionStatus(ctx *awsp.Context, pcxID string) (string, error) {
+	out, err := ctx.EC2().DescribeVpcPeeringConnections(&ec2.DescribeVpcPeeringConnectionsInput{
+		VpcPeeringConnectionIds: []*string{aws.String(pcxID)},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidVpcPeeringConnectionID.NotFound" {
+		return ec2.VpcPeeringConnectionStateReasonCodeDeleted, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error describing peering connection %s: %s", pcxID, err)
+	}
+	if len(out.VpcPeeringConnections) == 0 {
+		return ec2.VpcPeeringConnectionStateReasonCodeDeleted, nil
+	}
+	return aws.StringValue(out.VpcPeeringConnections[0].Status.Code), nil
+}
+
+// handleExternalPeeringConnections requests, tracks and deletes peering
+// connections to unmanaged VPCs. It returns them in the same form as
+// handlePeeringConnections so that the same code updates their routes; ones
+// that haven't been accepted yet have no CIDRs so they get no routes.
+func handleExternalPeeringConnections(
+	ctx *awsp.Context,
+	vpc *database.VPC,
+	vpcWriter database.VPCWriter,
+	networkConfig *database.UpdateNetworkingTaskData) ([]*peeringConnection, error) {
+
+	// Delete the ones that are no longer configured
+	var keep []*database.ExternalPeeringConnection
+	for _, state := range vpc.State.ExternalPeeringConnections {
+		configured := false
+		for _, config := range networkConfig.ExternalPeeringConnections {
+			if isExternalPeer(state, config) {
+				configured = true
+				break
+			}
+		}
+		if configured {
+			keep = append(keep, state)
+			continue
+		}
+		err := deletePeeringConnectionRoutes(ctx, vpc, vpcWriter, state.PeeringConnectionID)
+		if err != nil {
+			return nil, fmt.Errorf("Error deleting routes to %s: %s", state.PeeringConnectionID, err)
+		}
+		status, err := getPeeringConnectionStatus(ctx, state.PeeringConnectionID)
+		if err != nil {
+			return nil, err
+		}
+		if !stringInSlice(status, terminalPeeringConnectionStatuses) {
+			_, err = ctx.EC2().DeleteVpcPeeringConnection(&ec2.DeleteVpcPeeringConnectionInput{
+				VpcPeeringConnectionId: aws.String(state.PeeringConnectionID),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Error deleting peering connection %s: %s", state.PeeringConnectionID, err)
+			}
+			ctx.Log("Deleted peering connection %s to external VPC %s", state.PeeringConnectionID, state.OtherVPCID)
+		}
+	}
+	vpc.State.ExternalPeeringConnections = keep
+	err := vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		return nil, fmt.Errorf("Error updating state: %s", err)
+	}
+
+	peeringConnections := []*peeringConnection{}
+	for _, config := range networkConfig.ExternalPeeringConnections {
+		err := validatePeeringConnectionSubnetGroups(vpc, config.ConnectSubnetGroups)
+		if err != nil {
+			return nil, fmt.Errorf("Error validating peering connection subnet groups for %s: %s", vpc.ID, err)
+		}
+		var state *database.ExternalPeeringConnection
+		for _, s := range vpc.State.ExternalPeeringConnections {
+			if isExternalPeer(s, config) {
+				state = s
+				break
+			}
+		}
+
+		if state != nil {
+			status, err := getPeeringConnectionStatus(ctx, state.PeeringConnectionID)
+			if err != nil {
+				return nil, err
+			}
+			if stringInSlice(status, terminalPeeringConnectionStatuses) && !stringInSlice(config.OtherVPCID, networkConfig.RenewExternalPeeringConnections) {
+				// The other side may have meant to end it, so only ask again when told to
+				ctx.Log("Peering connection %s to external VPC %s has status %s; it will only be requested again if asked to", state.PeeringConnectionID, config.OtherVPCID, status)
+				if state.Status != status || state.IsAccepted {
+					err := deletePeeringConnectionRoutes(ctx, vpc, vpcWriter, state.PeeringConnectionID)
+					if err != nil {
+						return nil, fmt.Errorf("Error deleting routes to %s: %s", state.PeeringConnectionID, err)
+					}
+					state.Status = status
+					state.IsAccepted = false
+					err = vpcWriter.UpdateState(vpc.State)
+					if err != nil {
+						return nil, fmt.Errorf("Error updating state: %s", err)
+					}
+				}
+				continue
+			}
+			if stringInSlice(status, terminalPeeringConnectionStatuses) {
+				ctx.Log("Peering connection %s to external VPC %s has status %s; requesting a new one", state.PeeringConnectionID, config.OtherVPCID, status)
+				err := deletePeeringConnectionRoutes(ctx, vpc, vpcWriter, state.PeeringConnectionID)
+				if err != nil {
+					return nil, fmt.Errorf("Error deleting routes to %s: %s", state.PeeringConnectionID, err)
+				}
+				var remaining []*database.ExternalPeeringConnection
+				for _, s := range vpc.State.ExternalPeeringConnections {
+					if s != state {
+						remaining = append(remaining, s)
+					}
+				}
+				vpc.State.ExternalPeeringConnections = remaining
+				err = vpcWriter.UpdateState(vpc.State)
+				if err != nil {
+					return nil, fmt.Errorf("Error updating state: %s", err)
+				}
+				state = nil
+			} else if isAccepted := status == ec2.VpcPeeringConnectionStateReasonCodeActive; isAccepted != state.IsAccepted || status != state.Status {
+				state.IsAccepted = isAccepted
+				state.Status = status
+				err = vpcWriter.UpdateState(vpc.State)
+				if err != nil {
+					return nil, fmt.Errorf("Error updating state: %s", err)
+				}
+			}
+		}
+		if state == nil {
+			otherRegion := string(config.OtherVPCRegion)
+			out, err := ctx.EC2().CreateVpcPeeringConnection(&ec2.CreateVpcPeeringConnectionInput{
+				VpcId:       aws.String(vpc.ID),
+				PeerVpcId:   aws.String(config.OtherVPCID),
+				PeerOwnerId: aws.String(config.OtherVPCAccountID),
+				PeerRegion:  aws.String(otherRegion),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Error creating peering connection to external VPC %s: %s", config.OtherVPCID, err)
+			}
+			state = &database.ExternalPeeringConnection{
+				OtherVPCID:          config.OtherVPCID,
+				OtherVPCRegion:      config.OtherVPCRegion,
+				OtherVPCAccountID:   config.OtherVPCAccountID,
+				PeeringConnectionID: aws.StringValue(out.VpcPeeringConnection.VpcPeeringConnectionId),
+			}
+			vpc.State.ExternalPeeringConnections = append(vpc.State.ExternalPeeringConnections, state)
+			err = vpcWriter.UpdateState(vpc.State)
+			if err != nil {
+				return nil, fmt.Errorf("Error updating state: %s", err)
+			}
+			ctx.Log("Created peering connection %s to external VPC %s", state.PeeringConnectionID, config.OtherVPCID)
+			status, err := ctx.WaitForPeeringConnectionStatus(
+				state.PeeringConnectionID,
+				[]string{
+					ec2.VpcPeeringConnectionStateReasonCodePendingAcceptance, ec2.VpcPeeringConnectionStateReasonCodeActive,
+				},
+				[]string{
+					ec2.VpcPeeringConnectionStateReasonCodeInitiatingRequest,
+					ec2.VpcPeeringConnectionStateReasonCodeProvisioning,
+				})
+			if err != nil {
+				return nil, fmt.Errorf("Error waiting for peering connection %s: %s", state.PeeringConnectionID, err)
+			}
+			state.Status = status
+			state.IsAccepted = status == ec2.VpcPeeringConnectionStateReasonCodeActive
+			err = vpcWriter.UpdateState(vpc.State)
+			if err != nil {
+				return nil, fmt.Errorf("Error updating state: %s", err)
+			}
+		}
+
+		_, err = ctx.EC2().CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(state.PeeringConnectionID)},
+			Tags: []*ec2.Tag{
+				{
+					Key:   aws.String("Name"),
+					Value: aws.String(externalPeeringConnectionName(vpc.Name, config)),
+				},
+				{
+					Key:   aws.String("Automated"),
+					Value: aws.String("true"),
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error tagging peering connection %s: %s", state.PeeringConnectionID, err)
+		}
+
+		pc := &peeringConnection{
+			State: &database.PeeringConnection{
+				RequesterVPCID:      vpc.ID,
+				RequesterRegion:     vpc.Region,
+				AccepterVPCID:       config.OtherVPCID,
+				AccepterRegion:      config.OtherVPCRegion,
+				PeeringConnectionID: state.PeeringConnectionID,
+				IsAccepted:          state.IsAccepted,
+			},
+			Config: &database.PeeringConnectionConfig{
+				IsRequester:         true,
+				OtherVPCID:          config.OtherVPCID,
+				OtherVPCRegion:      config.OtherVPCRegion,
+				OtherVPCAccountID:   config.OtherVPCAccountID,
+				ConnectPrivate:      config.ConnectPrivate,
+				ConnectSubnetGroups: config.ConnectSubnetGroups,
+			},
+			SubnetIDs: getSubnetIDsForPeeringConnection(vpc, config.ConnectPrivate, config.ConnectSubnetGroups),
+		}
+		if state.IsAccepted {
+			pc.OtherVPCCIDRs = config.OtherVPCCIDRs
+		} else {
+			ctx.Log("Peering connection %s is waiting for account %s to accept it; routes will be added once it is accepted", state.PeeringConnectionID, config.OtherVPCAccountID)
+		}
+		peeringConnections = append(peeringConnections, pc)
+	}
+	return peeringConnections, nil
+}
+
+// verifyExternalPeeringConnections reports external peering connections that
+// the other side deleted or rejected, or that were accepted since the last
+// networking update and still need routes. The networking task adds the
+// routes, but dead connections are only requested again from the VPC page.
+func verifyExternalPeeringConnections(ctx *awsp.Context, vpc *database.VPC, affectedSubnetIDs []string, fix bool) ([]*database.Issue, error) {
+	issues := []*database.Issue{}
+	for _, state := range vpc.State.ExternalPeeringConnections {
+		status, err := getPeeringConnectionStatus(ctx, state.PeeringConnectionID)
+		if err != nil {
+			return nil, err
+		}
+		if stringInSlice(status, terminalPeeringConnectionStatuses) {
+			if !fix {
+				issues = append(issues, &database.Issue{
+					AffectedSubnetIDs: affectedSubnetIDs,
+					Description:       fmt.Sprintf("Peering connection %s to external VPC %s in account %s has status %s; request it again from the VPC page if it is still needed", state.PeeringConnectionID, state.OtherVPCID, state.OtherVPCAccountID, status),
+					IsFixable:         false,
+					Type:              database.VerifyNetworking,
+				})
+			}
+			continue
+		}
+		isAccepted := status == ec2.VpcPeeringConnectionStateReasonCodeActive
+		if state.IsAccepted != isAccepted {
+			if fix {
+				state.IsAccepted = isAccepted
+			} else if isAccepted {
+				issues = append(issues, &database.Issue{
+					AffectedSubnetIDs: affectedSubnetIDs,
+					Description:       fmt.Sprintf("Peering connection %s to external VPC %s has been accepted but has no routes yet", state.PeeringConnectionID, state.OtherVPCID),
+					IsFixable:         true,
+					Type:              database.VerifyNetworking,
+				})
+			} else {
+				issues = append(issues, &database.Issue{
+					AffectedSubnetIDs: affectedSubnetIDs,
+					Description:       fmt.Sprintf("Peering connection %s to external VPC %s has status %s", state.PeeringConnectionID, state.OtherVPCID, status),
+					IsFixable:         true,
+					Type:              database.VerifyNetworking,
+				})
+			}
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandleExternalPeeringConnections(t *testing.T) {
+	speedUpTime()
+	vpc := &database.VPC{
+		ID:        "vpc-abc",
+		Name:      "chris-east-dev",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		State: &database.VPCState{
+			AvailabilityZones: database.AZMap{
+				"us-east-1a": {
+					PrivateRouteTableID: "rtb-private",
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private"}},
+						database.SubnetTypeData:    {{SubnetID: "subnet-data", GroupName: "data", CustomRouteTableID: "rtb-data"}},
+					},
+				},
+			},
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-private": {RouteTableID: "rtb-private"},
+				"rtb-data":    {RouteTableID: "rtb-data"},
+			},
+		},
+	}
+	mm := &testmocks.MockModelsManager{VPCs: map[string]*database.VPC{}}
+	vpcWriter := &testmocks.MockVPCWriter{MM: mm, Region: vpc.Region, VPCID: vpc.ID}
+	ec2svc := &testmocks.MockEC2{
+		AccountID:                 vpc.AccountID,
+		Region:                    string(vpc.Region),
+		PeeringConnections:        &[]*ec2.VpcPeeringConnection{},
+		PeeringConnectionsCreated: &[]*ec2.VpcPeeringConnection{},
+		PeeringConnectionStatus:   map[string]string{},
+		RouteTables: []*ec2.RouteTable{
+			{
+				RouteTableId: aws.String("rtb-data"),
+				Routes: []*ec2.Route{
+					{DestinationCidrBlock: aws.String("172.16.0.0/16"), VpcPeeringConnectionId: aws.String("pcx-1")},
+				},
+			},
+		},
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		Clock:            testClock,
+		VPCID:            vpc.ID,
+	}
+	networkConfig := &database.UpdateNetworkingTaskData{
+		VPCID:     vpc.ID,
+		AWSRegion: vpc.Region,
+		NetworkingConfig: database.NetworkingConfig{
+			ExternalPeeringConnections: []*database.ExternalPeeringConnectionConfig{
+				{
+					Name:                "vendor-prod",
+					OtherVPCID:          "vpc-vendor",
+					OtherVPCRegion:      "us-west-2",
+					OtherVPCAccountID:   "999988887777",
+					OtherVPCCIDRs:       []string{"172.16.0.0/16"},
+					ConnectSubnetGroups: []string{"data"},
+				},
+			},
+		},
+	}
+	verify := func() []*database.Issue {
+		issues, err := verifyExternalPeeringConnections(ctx, vpc, nil, false)
+		if err != nil {
+			t.Fatalf("Unexpected error verifying: %s", err)
+		}
+		return issues
+	}
+
+	// Requested but not accepted yet: no routes
+	pcs, err := handleExternalPeeringConnections(ctx, vpc, vpcWriter, networkConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(*ec2svc.PeeringConnectionsCreated) != 1 {
+		t.Fatalf("Expected 1 peering connection to be created but got %d", len(*ec2svc.PeeringConnectionsCreated))
+	}
+	created := (*ec2svc.PeeringConnectionsCreated)[0]
+	if *created.AccepterVpcInfo.OwnerId != "999988887777" || *created.AccepterVpcInfo.Region != "us-west-2" {
+		t.Errorf("Wrong accepter for created peering connection: %+v", created.AccepterVpcInfo)
+	}
+	expectedState := []*database.ExternalPeeringConnection{
+		{
+			OtherVPCID:          "vpc-vendor",
+			OtherVPCRegion:      "us-west-2",
+			OtherVPCAccountID:   "999988887777",
+			PeeringConnectionID: "pcx-1",
+			Status:              ec2.VpcPeeringConnectionStateReasonCodePendingAcceptance,
+		},
+	}
+	if diff := cmp.Diff(expectedState, vpc.State.ExternalPeeringConnections); diff != "" {
+		t.Fatalf("Expected state did not match actual: \n%s", diff)
+	}
+	if len(pcs) != 1 || len(pcs[0].OtherVPCCIDRs) != 0 || !cmp.Equal(pcs[0].SubnetIDs, []string{"subnet-data"}) {
+		t.Fatalf("Expected a peering connection without routes for subnet-data but got %+v", pcs[0])
+	}
+	if tags := ec2svc.TagsCreated["pcx-1"]; !stringInSlice(testmocks.FormatTag("Name", "chris-east-dev-vendor-prod"), tags) {
+		t.Errorf("Expected Name tag but got %v", tags)
+	}
+	if issues := verify(); len(issues) != 0 {
+		t.Errorf("Expected no issues while pending acceptance but got %v", issues)
+	}
+
+	// The vendor accepts
+	ec2svc.PeeringConnectionStatus["pcx-1"] = ec2.VpcPeeringConnectionStateReasonCodeActive
+	if issues := verify(); len(issues) != 1 {
+		t.Errorf("Expected an issue for the accepted connection without routes but got %v", issues)
+	}
+	pcs, err = handleExternalPeeringConnections(ctx, vpc, vpcWriter, networkConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !vpc.State.ExternalPeeringConnections[0].IsAccepted {
+		t.Errorf("Expected peering connection to be accepted in state")
+	}
+	if !cmp.Equal(pcs[0].OtherVPCCIDRs, []string{"172.16.0.0/16"}) {
+		t.Errorf("Expected routes to the external VPC's CIDRs but got %v", pcs[0].OtherVPCCIDRs)
+	}
+	vpc.State.RouteTables["rtb-data"].Routes = []*database.RouteInfo{{Destination: "172.16.0.0/16", PeeringConnectionID: "pcx-1"}}
+	if issues := verify(); len(issues) != 0 {
+		t.Errorf("Expected no issues once accepted but got %v", issues)
+	}
+
+	// The vendor deletes it: report it and remove its routes, but don't ask again
+	ec2svc.PeeringConnectionStatus["pcx-1"] = ec2.VpcPeeringConnectionStateReasonCodeDeleted
+	if issues := verify(); len(issues) != 1 || issues[0].IsFixable {
+		t.Errorf("Expected an unfixable issue for the deleted connection but got %v", issues)
+	}
+	pcs, err = handleExternalPeeringConnections(ctx, vpc, vpcWriter, networkConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !cmp.Equal(ec2svc.RoutesDeleted["rtb-data"], []string{"172.16.0.0/16"}) {
+		t.Errorf("Expected route to deleted peering connection to be removed but got %v", ec2svc.RoutesDeleted)
+	}
+	if len(pcs) != 0 || len(*ec2svc.PeeringConnectionsCreated) != 1 {
+		t.Errorf("Expected no peering connections to be requested or routed but got %+v", pcs)
+	}
+	expectedState[0].Status = ec2.VpcPeeringConnectionStateReasonCodeDeleted
+	if diff := cmp.Diff(expectedState, vpc.State.ExternalPeeringConnections); diff != "" {
+		t.Fatalf("Expected state did not match actual: \n%s", diff)
+	}
+
+	// Someone asks for it again
+	networkConfig.RenewExternalPeeringConnections = []string{"vpc-vendor"}
+	_, err = handleExternalPeeringConnections(ctx, vpc, vpcWriter, networkConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(vpc.State.ExternalPeeringConnections) != 1 || vpc.State.ExternalPeeringConnections[0].PeeringConnectionID != "pcx-2" {
+		t.Fatalf("Expected new peering connection pcx-2 in state but got %+v", vpc.State.ExternalPeeringConnections)
+	}
+
+	// Removed from config
+	networkConfig.ExternalPeeringConnections = nil
+	_, err = handleExternalPeeringConnections(ctx, vpc, vpcWriter, networkConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !cmp.Equal(ec2svc.PeeringConnectionIDsDeleted, []string{"pcx-2"}) {
+		t.Errorf("Expected pcx-2 to be deleted but got %v", ec2svc.PeeringConnectionIDsDeleted)
+	}
+	if len(vpc.State.ExternalPeeringConnections) != 0 {
+		t.Errorf("Expected no external peering connections in state but got %+v", vpc.State.ExternalPeeringConnections)
+	}
+}
+
+func TestValidateExternalPeeringConnections(t *testing.T) {
+	primary := "10.0.0.0/16"
+	mm := &testmocks.MockModelsManager{
+		VPCs:               map[string]*database.VPC{},
+		VPCsPrimaryCIDR:    map[string]*string{"us-east-1vpc-abc": &primary},
+		VPCsSecondaryCIDRs: map[string][]string{"us-east-1vpc-abc": {"10.1.0.0/24"}},
+	}
+	s := &Server{ModelsManager: mm}
+	vpc := &database.VPC{ID: "vpc-abc", Region: "us-east-1", State: &database.VPCState{}}
+	config := func(cidrs ...string) *database.ExternalPeeringConnectionConfig {
+		return &database.ExternalPeeringConnectionConfig{
+			OtherVPCID:        "vpc-vendor",
+			OtherVPCRegion:    "us-west-2",
+			OtherVPCAccountID: "999988887777",
+			OtherVPCCIDRs:     cidrs,
+		}
+	}
+
+	valid := config("172.16.5.0/16", "192.168.1.0/24")
+	err := s.validateExternalPeeringConnections(vpc, []*database.ExternalPeeringConnectionConfig{valid})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !cmp.Equal(valid.OtherVPCCIDRs, []string{"172.16.0.0/16", "192.168.1.0/24"}) {
+		t.Errorf("Expected canonical CIDRs but got %v", valid.OtherVPCCIDRs)
+	}
+
+	for _, cidrs := range [][]string{
+		{"0.0.0.0/0"},
+		{"172.0.0.0/8"},
+		{"10.0.128.0/20"},
+		{"10.1.0.128/25"},
+		{"10.0.0.0/8"},
+		{"172.16.0.0/16", "172.16.1.0/24"},
+		{"not a cidr"},
+	} {
+		err := s.validateExternalPeeringConnections(vpc, []*database.ExternalPeeringConnectionConfig{config(cidrs...)})
+		if err == nil {
+			t.Errorf("Expected %v to be rejected", cidrs)
+		}
+	}
+}
@@ -11690,139 +11690,161 @@ XrHa/9//HwAA//8jgPpzxe4CAA==
 	"/static/navigation.js": {
 		name:    "navigation.js",
 		local:   "esc/static/navigation.js",
-		size:    3479,
-		modtime: 1677521893,
+		size:    3577,
+		modtime: 1792366058,
 		compressed: `
-H4sIAAAAAAAC/7xX32/buA9//uavIIQvkBSoku0e08S7rTcMA4atSLu93lSJcdTKkifRToMi//tBtpM4
-rvsLh11epFAfkR9SFEXrLHee4B6+aPpoMENLp7CizMAWlt5lMBxPjCaO9Vp7Pr4Jw7PBXoF1tNI27eyL
-qvaTzo7vAX0LXmpcT4qAvoYNpBEhwI+L83Nnl19FqVNB2lnAO0KrQosx3A8AAKSzgXwhyfnRSSOLv1Dk
-6EcnZ3sBrXQYW5EhzIGxjlyH9yrTFuawFCZgZ/XvlbDK4ALJayzxq8jwgeboA8zB4rpycbe+HVRDIEFa
-QooEuXc5etIYjvh6pMLbliD+It0p3ANtcpzCJfkq1qdHmIb6AfbBOYPCtnHbIzLSWYuSUJ0LY66FvH0Y
-uHEP5uDwWlvl1mOh1McSLX3RgdCiHw1jELhHoTbD00dDdxwZpcPTfBpjHjNX4r+wd/Ct3+QRqx41MIfR
-CcyTFrV2SsVDr+YPMuOQWxWm+dsxaFz6raBoAztGqk3SoPB/IQltQmenR6vQ9+VSvH0/98KZ0iVoNWdW
-lFw6S0Jb9AyqGzdnKnBzEIMKvOC5UErblPM3LPnfUdJVyto7vVuz5AjStdm1ZDh/+6a2o3TIjdhwvjR4
-V4tuikB6uakYoSXOAwlP9VpE8bUXOefWxbEWC6NTyzVhFjiXaAl9D6WKloCVx+WcTXLvSh20swwCbQzO
-GeEdcYXS+arwTME6iyyZhVzYypUyl5HVsu3PIVR3nL9lyY+Lc4gFbDaJ25LZRDxCpDBHWqQzznO+XmlC
-+P99K7/mUFiFS21RwTsYahtpXxscwnRXhLePeFsZMjqZifoocM09/iowEHsQhomQ0hWWAkveN7NIfjYx
-+kXKY3Aa5aFHe5lLj79CHaBFg3uVgWtBcsVJhNs+/dUqSz7EAa4i6FXKdc6LIFLs0VzLk88X8D3OXqR2
-f7Te5cqt7RPn02RlJCGIhFzFF44TZrkRhHtXb0QpgvQ6p2nptBq9OWFdI/yaLEuudjsfTb3em7zT0dy6
-Zwi3SHsMzpSxGBcG+04m8z6wZNHAYBFhz3JrGQgoC69pw1PvirzPQkgDSy4bGHyqYK+xQF7YoImngnAt
-Nr1OUCoCS65qJHxqkM/HeKJ0+USqvDQ/AwovV32+1wvJZTU+nZyzSWF6KnU/xWcK+B8vr99o1avL9BPV
-kf2Gmxe7iVhtX33bDo9D7POK8FnGB6WBivjaR8muoO/6gXf77nkKw5VWCu1wy4A0xXeowuhAXpDz8F5K
-DIElzYPSehq2/9ENrxsUBn9Ko+XtnDUUavG2JyeNS11BLPniUvhW0O+7JC9O6I6o8/fncY/sURAuqt5q
-4Rz19VcxAM2ms8F2MJBFIJc1nydhXD/Xo+GuYeB2/zkzPH34iXNy9k8AAAD//4cwloKXDQAA
+H4sIAAAAAAAC/71X3W/bNhB/nv8KghjgBAjtbI+Ora7Niq5AlhVO1teWoc4yE4rUSEqOEfh/31GSbVlW
+HBtD5xdJx/v43fG+LNPMWE9eyI30HxWkoP0FmftUkRWZWZOS/mCopGdQnTXfB4+uf9WTawXa+LnUSUsu
+qNq8tCT+dmAb7IWExTBHWsXWE4o7R75+ub42enbLC5lwL40m8OxBx66BmLz0CP6E0c7bXHhjz85rWvi5
+PAOkXG0ICNQNNE+BTAilLbp07+NUajyaceWgdfptznWsYAreSijgFpXsaQ4+oLiGReni+nzVKx/OoxuC
+JOBJZg0i8xLcDl4LPre6QQi/AHeEQfPLDJ93aD7E+mKHp4a+ZftgjAKum3yrHTAYMg3CQ3zNlXrg4mk/
+cIMOnq3DC6ljsxjwOP5Y4E3cSIeXgz73QxCYBR4v+xevhm43MrF0h/HUxiykpoD/YG/rW7fJHVQdavBy
+EdckakBrplS49PJ9LzO2uVXy1J8tg8okf+U+2ICWkVJI4I3a38FzqVxL0mJdgO3KpVB93zfEcSwLIuMJ
+1bxg6D/qwhhSUlbchMaOqS2Z4GfOMrxhzDjGLmn0007SlcqaktYsaLTD0rbZtqQY++WysoMXkim+ZGym
+4LkiPebOy9myRIRXzhhWEPaP8ixwsYXlGWPahGdF5kommkkPqWNMoBB6tw+phMXJ3MJsQodYjIV02GAo
+luhSwYR67DQsBmFs2XhG2OI00GjsMiyp4EqRiYBq1vRnG6pn9IpG2L9IaGDjYRCLxkP+CpBc7WjBqBjL
+2GKOXpCfXxr5NSE5XvMM7yYm70hf6gD7QUGfjNZNePWKt6UhJSP0urwKWGDJ/JOD83QvDEMuhMm1dzR6
+X78F8GPs5kcpD8GplbsO7XiMp64K0LTmO8nAA/dizjx3T136y1MafQgPch+YTlIuM5Y7nkCH5ooeff6C
+9YhvJ6kVqdOAwxC48vMO3dU5ja7/vLvFAfFHyXeUhU3y4EzBJqkPZECd9wEP956LeZihDIsFK8/DJpiP
+vOBOWJn5UWFkfHZ5TttG2INHQ/dryVeTu7NXrHXUdf0G4AZoC86oIrT7XEHX3afWYmJNazYyDWxvYmsY
+cCByK/2SJdbkWZcFl6CBu5qNfCrZTrHgLdcOtyJcaWDBl51O+ISjkfuKk3yqOd+O8RCDfCBVjk1Vh2NG
+dOVofYDuh+fh5BwPc9UxC7ohvjEifj1+QuAcPHkQHOi/9AdUXthXQj8/udq24ydskrn7LMLIqll52CcC
+ZT0y1hvHu81+PiL9uYxj0P0VJV76MOlKHlylcNAZS7Ddg8PUq0dWY/is/qcKr1YgSn4TSoqnCa0hVORV
+R07iiUGB6MYkBFl+XJEcndAtUuvz++4Wjnurxw0zbG9TY3zXBhcCUAtd9VBOYM6btP4D5AbVQnDWX68k
+TG/+MOE+vPcn6vzqXxVc0WL5DQAA
 `,
 	},
 
 	"/static/router.js": {
 		name:    "router.js",
 		local:   "esc/static/router.js",
-		size:    3487,
-		modtime: 1677521893,
+		size:    3644,
+		modtime: 1792366058,
 		compressed: `
-H4sIAAAAAAAC/5xW32/bNhB+Jv+Ka1BUEpJK3fa0aF7RXygCbEOQdH0JXICWzhJbiVTJk52s8P8+kJIc
-K1Fd12+CeHffd98deSfrRhuCb2+FLRdamPxSFLiBpdE1BHGykrhO8uEs/myDlA8ur7JMt4omHER3Mmlu
-v29vxw4fL99M2K6abGz2t1CiwPyDEcpKei8I1+LuFZHIyhqn8WoqhJ2McoVWVys0V22FU57GPPC7xqw1
-ku7eG9021zgJZ4sHTq8FZeUHYb9MWS/c4SMlrvBri5amBTH49QHCxeW/VhQ4Yd66/w9zECYrp4j7g86Y
-Z1pZAotmhebS4FLewgyCpDF6Ja3UKgnS3ibTilDRW7mCGeQ6a10d4gLpXYXu8/XdRR4GtZDqTWcZRClP
-EvhQIjhcMEitUZjD4g5OCqSPEtcnULeWQNZNF+TceQBIJSm8B4zguf8lRSX/Q6AholA5GFQ5GpAEpHc4
-ujgXy3vT1qKFtVS5XsdNa8trEuQjKefqSficcwQBSqvnqq0qF8R6Q4c02G3Jdlxb5dk6jo3BRhh0TBYI
-BptKZF2+AhSuOyYhxkUMWYXCAMkadUs26jU2uiW0MIMbzr5xxgyeQ/LpaXLGGesVO4cwgtmfnDHmQo5u
-eOh8GLveqeb5qLYuDttEnG3OdgF89+xD2Wm8YzGG12AfzO5j8jM4jN0juThXWLy7bcJgC5qEN5+S+Wn0
-NIjG6AZtW9EEh8MpsN7j4u05dOFufplPirCXmrvx2+8duswlt5dx/6IeyPYKC6nVluqv8x8kwT5evtn5
-+9v8O/X1r+++4h7yoh/bXLUxh2A/mgPH4tliL9zk+DgWy0+OfWjjuXMczKg1+9mTvAxvXjz/fX4avTzg
-3oyn2cHN6D1+fHWST93U2q/6MPB+6u3gc84rJMhaY1D5wDAD9/ynnC9blZHUqnuZwwi+cSaXEI4G5pPZ
-MFkqnQlnHjeCSiVqjG27sGSkKsIXZyMKcYWqoDLyIZmo0FAYXAoqIddoQWmC2hUW8LbBjDB308WhBXA6
-ChSlXiM3W1PONpyzbpY4CnAQsylaMRlZhy42Z0ttIHQSSZjBixQk/NFPqt46BXl62iWyM8dg1lvdyHl6
-f+TrPJzFBmO8xSx0tKKUc+blHdrL19H92C3Ok1lXHnj2bLdocTeIeyf2+MRnw9iGj0+3VPquGsDTB3bx
-w6XEG2yFd3E3fMN5L7jI83crVPSXtIQKTRhUWuTBWdezjmTfUik/KkO/WUm7s92cuL3GrysncWdwjQRC
-DatTv8pYDVQKgpOFyL6cuF1luyQtpbEEjifmXYS1rCrfiwsEMihcHwoLAkphS8hKoQp0YKW0pM1d3O88
-frsKgx44OLvfF0lShWePurI0uIx8+26i9PsSNrrxSTgZcdDRqYdxl9ysV26r0T96SBsJahTKdtmT005a
-+Ow2ulE6EC4wE63tNLFdGLfVGfzaSoO5k8xFE33ktVsiWytVAdvNMorHl/K+2C6//wMAAP//t00sUZ8N
-AAA=
+H4sIAAAAAAAC/51WbW/bNhD+LP0K1igqGUnkbPu0eF7RJkUbYC2CJOuXwAVo6WyxlUiVpOxkhf/77ijJ
+kRzFdQ0EsSA+d/fci/icyAulLftxwU06U1wnV3wBazbXKmdBNFoKWI2S5iz6aoKxL2qTN3GsSml7DHh1
+0gs3z+NN1+Dz1XkPdlnEXdhHLhGV3GoujbDvuYUVf3hjLY/THPrj5XbBTa+XazAqW4K+LjPos9R6y+4G
+4lIL+/Beq7K4gd5wZrFl9JbbOL3l5lsfekaHTypxDd9LMLa/IBq+b0W4vPrXILQHXtL7Lvj8480nsB+A
+ZzbtsYhzI8Fup8113Ac27qAC+7GSxjIDGit6pWEu7tmEBaNCq6UwQskRgioM/rfYrAuxRESi4pJaFy3A
+vsuAHt8+XCZhkHMhzytkMBz7oxG7TYFRXKbBllpCwmYPbIB2n/HlgOUl+kbOlZMzsmBMSGHDx4BDduJe
+CZ6J/4DZxiOXCXqVCWgmLLOqxZH8XM4foaUBw1ZCJmoVFaVJbyxOIR1LMnUkXM4JMM6kkieyzDJyYhyQ
+IjW4DdmKaykdW+JYaCi4BmIyA2RWZDyu8kWfSMIxCSFaRCzOsAvMihxUac2wrjFOqEWeE3bnez98z9Nw
+xkZfXo6O8bmu2BnDUJO/8YVHLjuXQkg2nnfT6uZZp7fkx1sPfW993A7gBm5XlNasHhqjGtFdQbZn/NBI
+zVW1K1b7pvuVOJ73GIn8XMPi3X0RBpugo/Duy2h6NHwZDLvRNZgysz0c9qfg1RaXF2escnf327S3CDup
+0XW0eW7R9Si5nYzr635PtkgAL5AN1d+nP0nCQ/ett39Mn+mvk4Zdzd1Hbg4drhzlZY/YT0Tq0HgoTLvC
+9WrbobGcrO2K1hXFw8J0RrMWxtHr8O705E+cw9d7fDddqd17GJ3Fzz8dLLnTx91Vb6T1l+4Of+r7GaCS
+lhpVyznGq56EBoV4XsrY4vdSaQCGQ89izsKONL+YNBqWqZgTPCq4TSXPITLlzFgt5CI8Pe5QiDKQC5sO
+nUuPZ6BtGFyhGao4io1UqGvUWAb3BcQWBauoogXsqOMI9ZxqRCqOT2vf9yrVIgpsL2Z9tCI8ykPy7Xtz
+pVlIJRLo73SMP3/Vmlij8dXRUZVISzERXKHuxHT8eOT63JxFGiK4hzgkWhgNYVTeZrxcH+lFuzkvJlV7
+2KtX7aZFleTXRt7TE5cNtt3vnm6o1FPVBB9v4aLt9ccBNoUnv2sfy18XnCfJuyUi/xEGDUCHQaZ4EhxX
+M0sk65Ea+wdl6HY4YVp71IA2KLcYDaIKgFcP7kjNklYvTUbhgsUtG8x4/G1AW9FmHZsLjR0inpBUHlYC
+WdAs4uZkNXCaQ25wcUpxw2FxyiXuxAhFJlbph6jertweFwZ1YEx6s5laYTM4fjKVKQ7f0I3vGn+eLWGh
+CpcElRGaOlL1cJxdcpO6cpsafVJN2liLHFB7quwt1Q7/vtLu2EmHhTOIOVbU1cRUbmh/xDuxFBoLgCUj
+b7z2vKJ1tTT4JbHNDjuMuh/lY7Mpv/8BKGfMlTwOAAA=
 `,
 	},
 
 	"/static/view/account.js": {
 		name:    "account.js",
 		local:   "esc/static/view/account.js",
-		size:    18521,
-		modtime: 1677521893,
+		size:    23168,
+		modtime: 1792369683,
 		compressed: `
-H4sIAAAAAAAC/9Q7f3PbtpJ/y59iw8uF0kSi7HTu+ka27HNtt+c3SeOJ0/RmMp0aIlcSagrUAaBknaPv
-foNfJEhRsp00r6n/sQjsLnYXi90FsKCzecYl3E/lLO0Cy+SUskkXOLIE+RrGPJtBGEX9lMqegil+RH+I
-8HDPof/AkSQxz2ejAqcfZ7N5xpBJ0RdTwjHpjwoohV0g/8SzZboLb6IAKii/CI+7fi6Q+91VImN6h0lP
-EnHbS6mQFcarkJKMejndAZCSEaZ1kPsPV2fvV3Ms+VnMY7mao2apgDqnYp6SlXhPxK3owhlhMabu67+J
-eJMlJO3CG3KL4jSXU2SSxkRicvrP0/95h/+bo5AG8mdcfrg6+zHjsy78hFKccUwUNElFF97OkYmzjIks
-9Tia0TvKhGF7D+80R+OcxZJmDE7jOMuZvCITbFM2zjpwv9eSUyqi32PNpWbyl3evYQiqP7pGvkB+xXFM
-7+AlhEq3fQMaHjrUERGoEB/CC+Gl6bVsXJ6rrn5JKM0mlO2gkpFcTvsxSdMRiW8V3tvRHxjLiAhBJ6yt
-qHThL1B/53Bvr5WiBGIlY+MMhsDyND00HWlGEsomp5X+MUkFKlQjvkBZri4BQ2hbch0YHquZapXdkUDZ
-/njPyAwHEFiyIuhCStntAIL+nGcLKmjG+sR1rrt7Lf1n0WxHdMUzpcWfyQzhJQTQgwBeFr2W9u7ey/P1
-b53DvdZ6r5xLklSlJWLF4sIW29r2WnQM7U3ddICjzDk7BOj34S1LV5AxBCKBgKQzhHmKROBeq9WoV8lz
-PNxrteKMCQk5T7cZlFPNFtOM/hAZU2amp5CjmGdMaMqSrzT7Ldeo5FsSKsFIP0YZT/95/fbnds5TpZjW
-GmIi4ym0kXMjekt7wwg5z3g7vFD/YIJSUjZx6tU8DUBxp9AOzYhaM4qk1Z/jIZJ4J2E49K3QDtXvw88Z
-xFPCJggyA0lGKUaqZ5dhboxWNe/KuId7D9FqMvKSd6XqTgFlopJHqAZYMbXC1DeNLM7YmE6MEsxvb4aH
-tSn/soluMjA35K/X73CiGFT+bjGP+2EX7mcop1kygPDq7fX7sAujLFkNQNGKhOSUTeh45QRY77ahJeGM
-somzopgj0WbEcAkfrs52WpChIPI4RiHawZnCRYUFymmDyEczKiUmQcebxGm2VA61OiuRaro8r05OzgTK
-dyiynMd4raL85ixxrZwuyMmyC6NcyowZ8czvKKFC2WvyhKXNS31zsTXywEs1ZvSeEyao/IlIXJKVF5bK
-6d+6uP2JPL94ffH+Ilw/YcFr9ei54lZHoFOh2pRtKmLrEvUYrfngtqa1ndTaj0SfO2VmYriPrZfanGez
-uWyHF0wiBznFmsBweQ7jjEPznNjoE0J715x1gDJNwHCnxVUO8lmNnSK8lA7U745EPjIrsL3fhe868GwI
-IRe90E4jSZHLdnilYxCgFog0iDPCCWVqXcKSyikEXPSCsGkJ/uVmboYZZ3x2TiRRmQsu4Uf7aczGdUZk
-PkeWtMN3VaWF3fqkd740bm71kY6Xpyy0b3GZTVD+ajy3yvaKtbWYx0aOBeGwLAE+/uYMdjGPo0vxhgpB
-2aRTwETzXEzboW1Xi+H01+tQJ6eW/RL0j4yydtCFwLlrNVWsSHgvGZUVrrfEZZ/vYkfR0kaoM25nt+bj
-0ycrhDG4BcWlMvYpFS72suzD1ZnwVoDGNtGzIOY+C3J+azTO+AWJp23ro4rkuWW+IztA2//UlDrRmKYS
-edvMwPAYnhlFn+OY5Kl0S7dAi0TGZbu9OOjC4lU5jpmig0g7rRcv4Nnilf7t3A70Dg5LuFce3EEVzgez
-5I7gMcSOYAsp+7U4UPxfnhty5ucJ9A5gYAHXnbqoTquLeawENYoROSpVeh9Gkb6iql7H13y950Fcq295
-0AVZ17c8aAoZSqvyVUNPo/oaIQ2Ng100fBKNbBzBlzJxBI9iwX5twOqp3qDcPOvFErQ/XrwA3xBSZBM5
-VduMfe0MOxsL0MySwTnoWmRvvkzI1b0Vks+GDtZvti7didYAAT1oIGck2qtjFuvIEdKfvhrWxmHqxKHu
-EC0zDX7S+SvjKCmjsjw9aGsBjAc1qGcZk4Qy5GZLXlHfjMytepTGrOr1ZBtgo46WAR8Yj2g9lGmz2/xr
-SeLbAYQShQxtmyJjkocBhEVjPrvidEEkXucjhlIM4DuvKx+lNN7ocRj0/3AAr753rQa42lg40HNMzEnL
-wIQV239G2JXbwg3gF4E8ouI0mVHWtiK3TpOkUJkoePGJnCbJj5TjkqRppcPmCCYEquClJ2MqZ+mN7n9+
-v6F8P3AY4pv2b9pPoCTUah0ldAFxSoQYBgJ1TOxNkSTIg+Pn995EruHIpAcOOhG9uGebvN+9HmUL5AKr
-jWJG0jQAIVcpDoMZ4RPKeimO5QAO9ud3AUgqVc/bOeoMQG1DRZaiSghU4l3jxXwE8F9xSuPbYfD8vq1F
-N/aazZHZU67NLW7XN89uLffsrINjNbrFPuob/o+P+gldHLdaTmv6LKLQmyQsITzp6dZCyFHGE+QDEFlK
-EziY38G/HRy8uviPg0Ow8o8yKbOZ0cAhLGkip+pj/9+DYztQ60iq2Sg+W0eSHx/J6bHa6yoBjvpyWjRc
-nhef71dz0/f8vmqcYOdfAZ3q+RYa7gYG7oB9rfpckmco9iUvWepXeTqSKsMtPouZ0panbdOlJr7hGVGK
-j9aRTIBlS07mTq3P78sY7bzmicsjdaNNfejw+BmNLsWP9E5NQKcEDnM2No0hDCB0vzuerIHHgxvxNJfZ
-TK15k+H4iWsJ7BbSEYEpx7Hid8PW1sVBZt/2lmeP+kylatZ9w4DObdZq/akv3XPUJ8c33tgDKLo8FfZl
-0qjRb11E89Uso+nbJeTxc3fHEU1QXqvFl7QtqmrtrGs425bEA6prOQdYdzpqSxD9bm5SPlydtSv+pZCh
-sw4edJ3TbIG82jTndEb4qsGbwuZcfvpk89qLuxjn+vrkBMIKqtvchZU1cKmZL9zdnyZ0V+cYfwPR4TVO
-SLx6ugZQKK9PxbQY929qAhdOECgwn64NjozMcJc9qJ86y+sWLuxfoZYTJ6mKA1u0sQ6O32n+ny53ginK
-B+X+hoRdB8fnmueny5qzLau+6v0bpS8ajWP+VlTxixXpcwx+li1w+9p/hE6+nhp8F/AYNbzTwuxc/7VQ
-6meN9aC8cV6o88BaMK5kljedtZdk+lnlUV/n1vbTRupi6M76pltsY39fzGPhzg+/cPNUP8v5M/ZRZivx
-zWwkKpsIbwPhjsrBXKZUwGp9Fuuzdhqfs7Goz4qeQjlZPrTHsGbZeFFTN8stoDpNbQas3C3sJFm7hajB
-PZidHlUT4Efuyp/kQSoba/TcXf2Srers9MUaRpLwCUq1lb5GCVVjaXIpX0MCaFT14/zgNuE3r4UfEP8X
-hfCgAqrT3+hRv8xLmlW41UH1BMaZ8jtaj3nP+pRVr3cQHP+cgV0BUJx5Z0uGCYxW+rTFK/uAJkd3U3XO
-srp2d/jpo1plHETqpyg2ofp2Zh0cH9VL6Iz4lUEV6F6rZUYz9zi6WO5MVyrAEO4hmJMJBgMIrDxBtyhR
-ujwPBhuBHAJ/F+wAKled6wbRjlyRHkQKQUnjcbIOICquE1Vf7YYxGlGW6JKxjpbcETv2pdWNpbhl9cPb
-NEGu1faDuegjSXKxQCZfUyGRIW+H2vDDLli718e2GM05KjB7ImruChvptu35W5qgkGVlhX88rdV0Kd5k
-XNffCXs6vYNLbbSKxcgkW+0tC9dny+egcp2ng4XUR4XR5Xkn4pjkMbbbiy7QRMv8hshppDyvaero21pM
-BT6BUZIkO7lcezeqlFHp30jG7szYaCbJ4nyGTEb6gBReDiGEniuKhM3b8/KmUhJdkPfxPlB7GmXaH67O
-hLJrmqgvlSepL6LizFuWrgJ7CO1q7gBK1LoTKMnUVvSjKZZFOyUtc9FQJyF5jtDIFBG38Dqb+Nyo+SiO
-3R/NzGu9ZkoyZg09QEdf4zb7LuVsaTIMRiS+nfAsZ0mR409pkiALjr08sACfZQlJH4QsA2XaK6zF+O45
-SRLKJr3evsv8CtoFZJET6q7KifwA/nN+ByqJ9BLHR4bnR2YT3jH9BKVXndp0Uu9l6k0n9T+hLFaCR6ke
-Yjcybu1+1fLQLpaMTBgxHaXcTnF6nTjxFZDSJDIZHL82tYNRFNkhNnDri+Mzydh10YTtJtAm/f/Y35/f
-eWfau/YlH67OdMFMRT8bo5aLYMd+ZH//+4MfzhpEaVWmYFM/1eXaICBoM+fZcotQdhWkvd73/lF+dYTK
-GmoiUc16nKAS72SPpHTCBsDpZCqDpoMBNUo1HgSft1hcjAiOr6fZEjQ90AQbN+LVSXuUuuturdEcH1Dz
-P7Z5moaRgs9j2O/znZ9JKLX7LqNkWeFZOlsYQhE3JygvUlQ/f1hdJu2whAo9XO15d6FpAB9DuYVdCKrf
-h5cbNSVbUWugFSq2QGk7rgLwMcxM7EIxEGGtVrae3uyiUIX3KdV8yC4iNdDQT19r+Zx5pFCKqPPXHzNu
-3k3onLbdZCEF4LUtBtagogL7baXMO6r1lpQl2TISKC+ZRL4gaduPrhs4Xfhuf3+/WulcnFtvr5ZdzGO1
-32G6cqesltVF3nym60hcnewpR1hlOYjc/lgSJkFmYIbRBdoqY2W2MvYE3meOUBfkao4Q+P0BjDDNllFY
-VMWWoz4bDg3cixdQbQ2rNMLyOYExjxSTzdrIP7eGvihxDfQVaNBU4xroZqvdx9dj20JeUyCq9UrZ5MGC
-+SeXwBdH/A8aBhXm6szw95fqURcHG85PUs3UUOmk7ViEEwgPdCXAftjZqNR9nMoN+a+i88arxAf0/61o
-HTXL4eOV2lDuXMhvtQtEADpV/LmqnqCtO7ueZty8ExvWNex7O6HA7HuYoREgzEUPiZC9g3AAofplatNU
-+xKF7L1S7Uv02yfZwsNxX9V+jVv0F/jrj4ar3+DTJztDh2WJtMdfRc7iPvbBZSzMnWzdzZugPDemUXp6
-+46A6KJ77WotSHtJ0xSmZIHOjAhLDHEwVfiYdEp/XiU/HEIQlO76VIh8hhA7r90FlunBNg3AZ5aZ2ayQ
-fglBTxnsttkvptwBao4PPy/aGaVvRDvVpb9LLjdiIEcdBeUUS80Gm0gPBsYSelt4rNL7GwRJ82DE2fP2
-x2jN8j3OuWvyX8W3e5fm25ciqdwTu6xLf+j78q/g66vPSUnlDY5+9Fd9sBP6s6GlPUlGb1m6Cj/jwajS
-xQOPRa28MxSCTJRkYVicJLuiLypcEe0bOuFEaVS1t53WLCOOxsshBO+nVJjhhSswndEkSRGyMRAYW3ow
-cwS1F5uRFbBMab9nn83HGecYy3QVQWDk36u6jIn/5FQbhznoLz3LjDAywcRdEpVvZuwz8VtcCfeisnjQ
-cIurSmW66f94i6vfzPrP07RY+abdFUUew34H7qEo3deklFt4UzKSLpC/y1O8Rnl5LpxvaLXqvNq3Ow4F
-FI7d/RWH5pUxrjHOOZWrn3iWzx9J3eGARhJhZyv1K0RO2eQsY8ycdD1M3KJABccM4Erx14WP3aBRUWnN
-xm7eTxHGWZpmS/8Jl4A45xyZTFdu6mG00raoLAMIx6qNkVGKA3h+vzG4fgwVdiHsrCO4KZbL1nh1szVe
-Od+kuXiuH9qvT9SQRpr15ibNArkwdPP192f/+lDjtPJlOa2j8lWCymbx0VNjywM7+psdOY4uFfIsRmXr
-hJUJu+o7Ae1pdU6YokoKlatVSDnzrJ+wFcgsSyP4Fkztq2c55KtbnZ6dr7+LmhJXM25Mr3EDVXnJ5J4g
-zrD6KM89C61Rr5n259MvCOkR1nv/HwAA//8SnnINWUgAAA==
+H4sIAAAAAAAC/9U8a3PjNpKfPb8Co5sLpYpE2ZPaZMvP89iTnLfm4bI9l7vKptY0CUvMUKSWgKzRevXf
+r7vxIEBSku3JbGZTNbHwavQL3Q2gwXQyLUrJ7sdykvVZXshxmo/6rOR5wssluy2LCQvCcJilcoB97I/w
+NxHsPUv18Fclj5K4nE1u7JhhXEBjznMphmIclTwZ3theONoO/qks5tm6cSPs4A35IBzshjMouc0+kNv0
+E08GMhIfB1kqpIe431NGN4NZuqZDFt3wrN7l/n/OT64WU17hczeNJVQQSrbXaSqmWbQQV4CI6LOTKI95
+Zkr/HYm3RRKBCN5GH7k4nskxTJjGkeTJ8V+O//eC/33GhVQ93/E5TPljUU767CcuxQnwCHtHGbS/n/Jc
+nBS5KDIHo0n6Kc2FQvsZ/0QY3c7yWKZFzo7juJjl8jwa8W6a3xY9dv9sCxRBhH+LCUtC8sPFG3bAsD28
+5OUdL89LDqxl37IAeTtUXQG+HnoTCY4DN40L4Be1ajTOTrFpWAHKilGar4FSRMAtmD7LbqL4I457f/Mb
+j2UYCZGO8i5C6bM/gP094PVWxiWLNGWAPRCRz7JsTzVkRZTAejv22m8BEMehinzBZbW6BLR3NbgeOzhE
+SW1VzSF07v5yn0cTvss6Gqzo9FmW5h+hZjgti7tUgNCHkWlc9gEE/qeH6YbwvCyQi++gErjcYQP4961t
+1bDXt56dLn8FJmwtn1WyjBKf2kgs8tjqYpd0byu9Zd0mb3pgluSszPcYGw7Z+zxbMFiXLAIGM5kCItOM
+g9LB+Fa+ynIGbN3aikE6ks3KbJVCGdasUE1YRUWOakYiLLkA6yAIsiwXhP6WqUT65lEqmaL+lst4/JfL
+9++6MDsyZmvJQMfiMevyslSkb5E1DKFclN3gNf5hIy4lkGPYSzjtMsQOh+2pGYkzCFLzz+AQSv5JsoMD
+Vwv1VMDFdwWLx1E+4kwWDOxfxkNsWaeYjdl89fbm3Xu2CVabkle4I6t7tpfySg6gWkdP1ayqN5UMNOA2
+HSkmqN+OhA9qIv88QbcpmJny58sLPkIE0d6BzxgGfXY/4XJcJCDd8/eXV1BxUySLXYawQiFL4GN6uzAE
+LNfr0DwqcxhgtCgGBpMa5XzOwIKt1SAFQczimAvR7ZzgWI6jGBptJmY3k1SCdez0HCGOizkaVF8qIVad
+nfrCmUGrvOCimJUxv0Qv35RSSczpMzmaAxtmUoKEiTz1O0xSgfqaPGJplxW/S7HS88D/Yc7wqoxykcqf
+gPB5tHDcUiX+lYvbFeTp6zevr14Hy0cseGIPyarUPGIUCtVE1mTEyiXqIFqzwV2CtRrU0vVETxWZEkzp
+jqalBot0MpVAdi55CfjxGsEMet0CQ9plor1PwLrrZNYDIRMAhR2RiwbyeQ0d614qA+o2w2K4USuwu91n
+3/XY8wMWlGIQaDFGGS+BknPyQYwTQVELOTeARY7rks1TOWYdANEJ2pbgH67mahpg/+Q0khFGLmA4ftRF
+pTamMYymEPgk3eDCZ1rQrwu997l+c6WNNLg8ZqF9jcsMvP3PynJjtGfXFjgIRcddVLJ51eGXX43CQo/w
+TLxNIezNwbuZPuF0JsbdQNfjYgDPE/TcUa8/xXyKs/jKwL75Zm17eFHMJBfsn/8ELHqhKCa8W2JUWoaX
+MpIzQA/ijgC9VZbGMug1kDrRTYiYVLDZSE9eEvCAomjN52r4b0WadyGw7Ri/gjqV28j8LE+lx94VAYTL
+YLv12aLVQlsDs8BUgeisVsZdCuvhgMRtgoS8AASEs1RptHLzFpgpWnBubQhq/DqKx11tTG2Uv6XKoZ6g
+6xa1AG7TDKxOV6kKDHuuNOKU30azTBobY4eBwMBide92+uzuZTWP0oqdkKwraMDzu5f029hHNtjZq/q9
+dPrt+P3cbhrcPnsIMOjVDkqXoBXwB1NF4NTPI4DEdnXHZa9OquEqcAQJVYwRsKkDVjoFxUiXUb7Gu5yv
+t2wcq/ktgd+yzm+50+bbkKvyZUtLK/taeyoYO+tguCBa0dhnn4sEQHgICrrU6EuibkBul7pdgvoH0O8q
+QsbzEbhdsEvbZLV7jQWopKTG7PT1YEdeKjagVg/k8wPT163WvseQ1tID9s4t4BRFz+oj7ToygKjosmGp
+DCZFOHWDqJFpsZPGXilDmUJ1dczRJQKUBVVDwWzLKM15qc4OPPZNoqlmD3kCxXoStuqs2LGluu8qi6gt
+lKrT5xHgQeKP4InBB8hA1yEYFeVAg62cTc7L9A504nJ2k3Mpdtl3TtPsBhxMo8WMSP8B3v7lD6ZWdfYr
+rQE95Yk6EtpVbkW3n0T5udlr7jI8mQxTcZxMwEFpkreOk8SyTFhcXCDQ48e0BK3OMr/h7Pzu+106M1IV
+OrpRPhG9GUkHT2Svqf3FfUMaridRQJsLQtUfsQrQ1tZ+kt6xOIuEOOgITk5yMIZNOi87hy/uHcku2b4K
+bEzvRAziga5yfg8GaQ5xKsR3XqWYANUdJuQi4wedSVRCgDzI+K0Ejd6efuowmUpswbM1jF1wA43naxjK
+4JahhosqdNh/xSDLjwedF/ddIl0pcAFA9Plcc3Ped/W1X4uae8vOIc6uR+8PFf6H+0Pg0+HWluEanaJY
+vskoT6ISz5+h1hJ5U5TAxl0GgNKE7Uw/sf/Y2Xn5+k87e0zTf1MA8IniwB5sFRI5xsL2f3YO9UQwE0rD
+FqFcHkLdIe7SkYD9IRRMxdmpLeJhNRVe3PvayrT8sdMxyVtQv2uwLfpqYIltJjxVEIcwrUVp6OO0LzE2
+t0UrKdI80k0Tq7iKp0ixBSglMP+8jKaGrS/uK6dtzOiRiYCpUsdC6cHh8xTqfkw/oQB6Vedglt+qygDI
+C8zvnkNrx8HBzHg8A7GgEVAhjxtyV53NQtqP2BgUC/Ft6NrSHsEOdWt1akqnQb5aDxUCFOwscf1hiVr2
+h9HhtTP3LrNNDguHMmnl6NdOoiq106ja1hEJQPTtTAjbqktcfElXD8Xa3vLFvd11rdjlqK0MKeqyNsOq
+BbSB0VvGXNZNFO4o1DnaGd0akff1TJIlG6zRRms7LkAUftW0TMHALFoMMGuKHyJaVWU5g+vGG2p2soG3
+bBT21kI+mHJ1Vwb0rSC6T3HKvwHp7A0fRfHi8RyAYAeApmJs513Nja+aD68NIcyOfDw3IMIBktfpA/6k
+SLFvrd6/gi1HhlJ0HSu4AUy4IPwfT3fCMy430v0VEQu0nhLOj6d1lq9Y9b7DaKXeVipb/rWw4oMm6QnM
+mCYwzwpH1FCGL0exu9ofQrFGlGlMn7LSJ4DoaqP3AGX4erhxQcSsNXy1QMKNsOsBTONUuC0U8aLw697S
+CcjdCHx/SPsQXdRxip26t7zu2zOAv8Eswhy+fuZGs34Q9nvsOdW266vZdHkbLmezZS5EmLoy87rV2vSo
+J+3KnrIJq0uFRChH8037Ma2WrddxdbVc0ZVC+vaO3g3SWpC1u6Zav42x+b6/WXjgCcajLIh3CMHdEL92
+leobO7o+5aEEpeQSjx0uuWS+srSZlC9BAWtl9cPs4Crim5f/G8j/gAM2MsAXf6tF/TwrqVbhSgM1gGKB
+dof4OBtom7IYDHY6h+8KVvORghXzHEKKmwWdTDnJPazN0F37xln6a3eNnd6v5T+yEH8Ku2Gnqy1g8n49
+UVKR702KXaGoZlOXYJQSeUL5KOyA3bPONBrxzi7raHrwjs767c5uw5GzjntiYDp4F9rLFtL2TSomC3EA
+UuNgAroX2ktjbKvdI4c3aZ5QYmCPKDfADl1qqbIit8pxeZ8BKsS2V+o6N0qS13c8l2+AazwHNANS/KDP
+tN7TmTcPpyXHbvo4Wd0It8Lt6rNKqBCyyp9xz/aJTWfibVFSlqXQR/trsCSlRRRDFWx1VyxcFy0XA+8u
+lJyFpGPVEJADkMks5t3uHYRqCdH8NpLjEC2vqurRnTwwlD8CUWDsWiyXzr053lm417mxOXBXnEmKeDYB
+5od0mMy+PWABG5jUV9bMkaiuecEW0D37fQc3c6jaeHqJep0mWMI4CUsR+hnMSuzoE3yTWclYNbRuBCow
+tRX9YIhValYFS93S1EHg2QlrRQpTut4UIxcblIe9s3gwMm9ozVRg1BraAIfuwNttFxrbNIHQDXb4oxIk
+k9gYf5wmCc87h04caLtPMJl3Y8/KUWYDqy3Kdk9B88BzDAbbJvKzsG1PGxNSk3d7scu+h+gSg0gncHyg
+e35gNOFcaYCXdHKQ2241nEi97VbjJ/CuZiU4kOouthFxk/nF5UEmFv6SMVUNFd2GcbRODPnYCTkJU3UO
+36gM0TAM9RSNsfXF8UQwel20jTYC1EH/n7fx5skJLtbsS/B6BU9qPf40Zq0WwZr9yPb2DzuvTlpI2fJE
+0OSPv1xbCGSk5mUxX0GUXgXZYPCDe+3hz+CtoTYQftRjCMVM4EGUpSNYGGU6GstO28EAzuL7g87TFovx
+ERA3AzhG8BgBbN2I+0J7ELvrZq1VHTew+c+rLE3LTJ2nIey2ucZPBZRkvisvWeXxVsYW3J71m3hBknH8
++WpxBm656hU4Y8nyrhtGHdwRaBbWDcB2t79sJOSsHFrr6kHR2V2rx2IHd4SSxLohqkdQy4iuhzfrIPj9
+XUg1G7IOSK1r4IavtXhOPUWpSKT4FUyZeh1DMW23TUNsx0ud8k1dhdf36wqZ1+RkzmEvUMzx6cwZJu3e
+RVnX9a6NMX323fb2tp/Pbg/sV+dEgx7jfientKcqJ5pS+csJJeGYbOjjkrNFMWNipn/MIzDgsmBqGkrD
+x4g11/nPR+yqMIBg+7yYctZx2zsMFBNIrJI+q1mfHxyoft98w/zawIcRVI9GlHqAhW1mwP6+LyVsInOH
+ros7bZnMHarW3H141r1O11ZpwMRXPM3Y9Czi0Q8d/Ltcd3/i6YVCqhpCr9GUerYFxq5HIYN6UgtKGx2u
+cNfT6pAx0cZeW9KJNx2ltYbKBOpVkSxsVIxJ1+So7CUORKnqdYhz7nPPWlay1oD67Y9dKSoLqjoI2mPL
+KiNjP82nM0m6DiH5mMcfb4pPHQcTzJvqkPZCzRR/w8KeQWHHjyricZHGOiFJ/TbRtp2K7Dqml3uwD4+T
+hEU5wwI7OTu9YDdZEX9ksLwwKQnXaFJwkQeSjaM7jk/V9EHDesDnRZH5KFK3zuGr/3t/ds6m0FyH4/IC
+RVvngwJZ8aI5xW3KM9haTbMo5mO0oYDS8ST6B4RT9I4qAZsw/NP3G3iCXGhFndiT5k9CXgGtkG9O0op8
+vlizPxvIYqqTyw49BJTmWl1Rq+LBYagfHu8PcXG4Idcz75d71kQLy7ccdl1sdCmpUNkGfXoC4TqXaVRG
+E6Ffbny4eHPJozIen1Nt9z6jUbt2PN7s71Ay1La2m+gqaIWDc8C/IQogpOVmk0nVHPTyNMBmcOEARRnP
+ehsqXtCvQGE5JF6HEljY7bUPQ5G7w7DcHLZ0EO5p4xJnheCOMf1D3RO9rFFiPUIfo8hsvGd5mMtScL6I
+z2rNQdmghQrFP569nFAOHs7UlkdBln7NXSCcccOK35fVm7LOWh8AqbUtTIc1D3TsUzfV2abb64uR6oxT
+vbUBYKqj97Bnz4lV8YQIX0Bg86WGiVne1N3kXDafALn9nfx2BTWSMorHuH+p5vfJOLY9zk6f0ZXx9Yv7
+jT2XzHaqqhEJvqQgapddvyvM9ODfIL5eARVgXe9Vj5D8MEw7lluIwAaCMsf1YZ3OWj5R1hJgoxE+BYhd
+PYluOZa9UBZvihjW96V6YIhpibrTabm4mFHSFesmsJzKWd4jGx2YYOjFfcXBZR/mITF1Awi4BFRB3P4t
+01X0BBgPzzHXUgtNiWOpZagh6v4TlfUZ2MtTB7xtWzL9s3HlZvtWL8AqQPtiCtGTZpLFZqUKOTDUEwPs
+AWpQKl29whMfkNMuXp6Fp7CE0zzCBbFkg0Oqu6IocnndU2/Hgr/mQY+yTBs4LllcPUvbHyKeh3Xa9BmK
+/6h5XfLOWvv5JaxnMJGjSKiPFTzhWwP113iSTyDAAhGtNYBqRdPM6qWUYwBrr9SwE8oQ/+qzaWUraq90
+FRneYxoP7asxh10y5k/kxWqsW94At6Iez8pSWaINZlV3V7sHu3W//nmcAotXocHANcyyhDnZ22wm+BFT
+759TKdjZ6e5f8xf3impUdI9PysspEVAFJT65Gg0ry9BwZH6Fb6M8GvFkta0E7aYZf9n+NdQHJ3RMoMk7
+UEdFDzoBqFQA2KLIuMXbVkOHNnGanB4BV/O4wZwVfZvIP+Qf82Ker2YzskfBXC1poZ7hOMcuSnamXovq
+hlsPccQuOcZsKEfqBMIS6hV3XEwmEYRysIAyjrs9wEQu6Ok6bgYNTBmNQAyoWnhMfAvbowVsVjQZ6R0P
+A1d6XSM+9WIIJ9NLSMm7z+i1QlAd61iaHi+wRPmZA/ZcnwJ1r5WCeMpKPtIq3hL2v7AHpn3wwngB7UiO
+9JQ4pMBPtYBtSm8XbD6OJOw4TO/wuudEIfzvmEOAKG7U2F2zIGgXZTm0a3gdimmWglD72l+kCSpfmhgl
+sw9EdL16qqW87a7mBlYt/8VxrH7OWsWyDevz4K+EADt7j/rqBPovlKCdWH3ww19lv3sArGz/5Ri2M/SY
+sXFG5kW92M14C0VJMBMDHgk52EHDiL/Uy0Csn0MgMHiJ9XPu1o+KO2eMKfntNNa22/HLXxRWv+JiVD+d
+2NDBz6PTZrJv3M0Llc1ePydWp/pTpVOVzdKfm4hoh09ntbpLd55mmTp50vqHa5WAM/WxBtjBV5bDBw/m
+o9OpjMexEDOAHBsbgh+Ko8lWGRMFLVfS9ECDpg9Q01dJ34rcdCSM9552XK6Y3jguxyYqV1g2DtGBKjxG
+R+NtOdtpDtp4sl71XnW+7sP7NzhlV98VMfq82hq10/ew0w0C/0UON5znBquXYuQlmptrGyrQS4MvEa57
+Xx2LvE+10Leh/O+6BK40iNqj5Aazap4S6yMvNnxTTNM74UKAXwbKgsCmopkXdqkwT5jfpqOSdl9Y3zVc
+04gYGN+CkbnCzEeaXpjXvJM0STLOilswarcaHlRqgGTFJhDrwWYM+DHQX1eMC4iUYpktQtZR9D/zTcbI
+/TIZKYfKFKwsy0RFHCbLtPq0iv6a4Ee+EObDW/ZzElDpfRdAtf8C1b+q9Q9BmF35qt68QD1k28AQZj+c
+QKDQLLytEMlAQy5mGb+k2MbYBmBhDVf9NRUzhOEYfX1ss+68OS45xJapXIBSzKYPhG7GMBoE/VdCP+cc
+YxDgcK5SZTYD10OYN0ZNYD6EUB1JN2B4LK3p2DVsECEQz8BMu1/6ESbQhtBUw8NUXNRF1Ay9oXR0DA8Y
+8HShMXkVjC9Ddm2Xy0p/db3SXxnbRFi8oO8xLo9wSkXNsnnLqzsZN3T95S94//WuxnDl8w51DZQv4lSa
+r5ce61s2pARcr4lx6K2RozF4XB3lfhAPwQ1aWooJ1QbV3FHOckf7cRsn8U6GfQ2q9sWjnOiLax1J58tf
+I4wj80BfqV7rBsr7joz5ANSE+59EMl8Pq0GvqfbT4VtANMPy2f8D17JDrIBaAAA=
 `,
 	},
 
@@ -11950,6 +11972,34 @@ JFT8NL5L1NBk64ea6vy28mkLvOztKCg5dYm3M5p/b83Efkcin965HIuzSGC2QkE611OrM4S/7u/v558y
 `,
 	},
 
+	"/static/view/cmsnet.js": {
+		name:    "cmsnet.js",
+		local:   "esc/static/view/cmsnet.js",
+		size:    3675,
+		modtime: 1792366052,
+		compressed: `
+H4sIAAAAAAAC/51W227jNhB991cQQgDJqC3ttm+OrSKXbdeLJBvYbVGgKBqapC1uZNJLUnECQ//eoSjL
+8k32hi8SyTkzh3Mj+XwhlUGrxMzTDhLSJFzMOkgxQZnK0VTJOfLDMEq56VqZ6if8pv3LFi/h14phSlQ2
+n1SYiEjYFEwYHekEK0ajSSVl0RX4dyWXaRNuZgW2IJ+xvpcUA+V7/Mz0VWYSAHCCDaNXX67+HrHvGdNG
+b7TO+SsX2rFusddCzTQTxHAp0M39+IGZzwynJnnEMxZwMZVttGohGOASHf6XyhkXf47u0ADZzXDM1AtT
+j4pN+Sv6CfkSA4eI4DSdYPIMRiz06+QbIybEWvOZCKyiDjqXeht4Vua54AYsY/0mSEU7IFIYzAVTa6p2
+bEIRamaCf1YCz1kPee6MyB3Sy/9tX1YQF+2gmtthY/y0tWJHn/IXxOnAs4eEsGSCeoikcMCBl3BKmfDi
+fgRC8XHo3J7+h1ClKNXdtFudGcE06y4wpZCx3e4Hbx98UAHk0hHRI/bSbvfjzw2QXZhmRXS6CcRhj+ZH
+L94KxJFjH3Rc4kIXn8A0bB9z8v7yUwdVnq421im5qYoilpCYVJJsDmkczpj5lDL7e/02pIFfCPi1XHO4
+Tfo0gTdS+xqcM5rQTsLfJ51KTJ33g5raJRdULm3NDIWB4sZpELTRID4A6qBfPsAowXmrdUh3Va5Oy6ZA
+wa/aoEylx1oJmWvBTOT4Q8eSwt/QTCF1FNPQITWr+US91Sy4mnYylsYSQ/dw/KbMkOTL+OtDAARqp88R
+NCCSoICprW5iR9GfQ9iQKvA/2Q8q1EBCl50TOa495AN/q+Fyh4vJlKgZ2/GFNthkGpiuSReHvtyRguoy
+o0yAmJMP79zCu/vYpsy3C/Rg6VysSvtH6+7XI7a27GqjpJjFljoiCSPPvX5UroEJwZboFi6CoLQVjg1W
+9l4w7dDIOwnXCxsbBUSDdt5oqKIbjqAkKAelQNAPlhwyU7EF5qrto976xm9W1q2p++vx5gYq0uQI/vSZ
+HK5tbv2B9fPwdu0mLlAfowSSfuBdrPbqIJ9YiBcXH2QAelhd3o9w/FQ7yHH/95zhxhAIeHggzQVh6GVB
+7GUztdlmY/CUt85spxerMkGth8KUiZlJWu/JmL7Bk5SduB2MvWeaZUq5GPj0I/ieJXxFiI3z+YARm8G1
+d778UGt46JyWtxInj9g3E0nfThvejs0cLwKIs+3Pp2vXmVGnbThBGjfmN3bu1dHFChiEpbchoSOYlovO
+o3k5A8aw7cVu9gCvuhwF9a22rQXwFj2f4q7x96BLlj8GPUvQBczacLlSxIvb3ypitpEDj2IxvGWaKL6w
+r698vTbUv/FXW0a2/aFg6iZF7/P93FXw04lmWsvEcyotOidJTtkELc0ZDQJFdzictL2Ne47ecw+y6OEo
+wS9sfZFz5+jSL/nOm3BrWn+H1Z+I7obPW/8DX+2CEVsOAAA=
+`,
+	},
+
 	"/static/view/components/batch-task-row.js": {
 		name:    "batch-task-row.js",
 		local:   "esc/static/view/components/batch-task-row.js",
@@ -12463,181 +12513,189 @@ AAD//4vIZSKhJwAA
 	"/static/view/mixins.js": {
 		name:    "mixins.js",
 		local:   "esc/static/view/mixins.js",
-		size:    26533,
-		modtime: 1686846316,
+		size:    27919,
+		modtime: 1792369683,
 		compressed: `
-H4sIAAAAAAAC/+w97XIbOXL/+RTtKZdJnvlhaX2XCyXS0crai269a5Wp3UvO5bKhGZDEagjMAaAoWp5X
-yO88X54kBWA+MJ8cSpSdbMIf1hCDbjS6G43uRhMmy4BxCXcLufR7wDH1MA9hxtkS2oPB0Ceyr14lD4Pf
-RPuoFUP9hbO1n3QfumwZMIqpFEOxQBx7w7nqkIX5RVgjDFcC88z7LBqJxHXfZ/OaLj4RUnXpu4xKRGgt
-Pm9D0ZK4fYOXCGn6toZ/aAEAvMP/WBGOxUh/A5ALIgYfl8xD/ggQeOSmB4QSSZDvb8D1kRDjBfE8TCOA
-GOQKuddzzlbU2wb3h2EL32pKbxCHf0XiJzUajOFOo/woFmz9kyFgtqKuJIx27jSGn9ESwxja7R64iL4m
-YkmEgDFIvsKhQhB2IyTqMxzC+QzkAnMMayQA+RwjbwMI9PSABZiq1xTWGBDHgGcz7EpygzXFTBA6ByJt
-fFcbDaVeIKB4DYziQdKBzKBj8W+6YGsKz55FDGL01GcC65nZZKYstHt0ukdbesAY6Mr3025hTiIWEYZF
-R2UdBjZnk+ejSukOspI4ysw+lUo3AlQah+kPjJ/6xL2OZxX2WkbWbjIdS9g2d6oobRtlajcltNDdLPyO
-0iVrjO5RiTSzkquXVQM5lUhnhnyBc6zJcq6CPS6jQoJrD9bpwnhSql/uFu2yeMfxkt3gsxtM5RtNh2KV
-qwhRay/lhqV9NZJAntcUVdhrhVuMk4uoi/1LJK7FL+/ejOCXd2/AJ9cYnGHA2Q0RhFFtRoemp9NKKPsJ
-XWNxslKLXhIXSeyd/PXk39QwWEgBS3JL8vbpVOMQerjURlk0jACJDXVT+aihz1+LjBbzTU4iaI2IjCY0
-w9Jd/HX69udO2QR7cLfEcsG8EbQv3k4v2z24Yt5mBApiICQndE5mm87dpRl3BBEBYTe05QMuku4COpjz
-vPnRm9oAc854p32m/oChwVeWTppZtuE5KNis5nAsV5zmjVCJEmt8WgssVWZXv2FX2uSYljKN0Qj6seLg
-opKbtRBNHsaABx6WiPiDqKlM5S1WJ3KzJpNRynqtvEICKzxbVPLg8LuXf/zT0NLJZAM02hc3Z/Qu++oe
-WvyaiMBHm7waJ+JRhki900wX95OQ2reNm1EjJMMsEQ3XSWTks/n563LWp16BArFoMzCFzSLxH2wrF5n7
-DC3Ku/uUaVGf49gDg8Ir9bHkPHae3hWEHzqlYJpWBaAfKjoNEkuQok6aBleEetpCdCvAJ8W5JO5k9t2n
-HpRIJdoBAYqcf+t7mEfmLuH/FZ4xjhuLIF2fSmdgXML/44K32toD/2M6Vf/4uaTbPbmf5exx0eGeZHn9
-qXUfpSz6/KUqYBxth5P5Ql4gip196vAglp0Gip73r8plalycfrHXfTS8xqzHMD6bE5pYdTYD3QALRD0f
-81iYOSuetb5bDHZqjhXmd1jZV2uZ5TZsQ5VyHwid/8D4GwU0mDF+htxFhymTywZcI9GgBec0BwpjeP8h
-73saQgTzb3CtY76VDI2ic18izukuo6swp7T9y5cEe+qz6PjtgrMlEbjTiSjtgeFcpQ+dn3CwEovOHY9Z
-lUMziv6GOa8pjS3y+HxM53IBEzjIO2lRCGpmZCLYOKKVnMznmGNvUIDI+2fGR6vYko3JTmPtEbS1JmTi
-7ZGJVtSUcp5g0ZjVGDSP3MTWSk/mNF7YzqTUoNgAnuiv+jPi+/1+wMkS8Q3oJpf5jPf76wWR2LSwG8xn
-Plv3+yYANK0B8jxC5/3+gTOZYqG8Mzi7DQjH3vHQIzfbKdAkf8+8TQW1SX/ijR1hhohGqIHIj+JiKisZ
-kgG6WknJKMhNgMeO+eLosbX8vo8aUva5/QjCes4xc4n4nNC+ZIHi01YS4s+/aOdPGf1Odzy5awynPllG
-mQBe7THK2ezEIXxuKTXE+Q5LvrEwmgD3vkjXhHpsPWABpp1P8fYWbxThpx60P175iF63u43RhhA6kzds
-Duf0eGgEskVRqjW1yeuccmr+JBpiuNJAUydvqYsBpVubWkoLJMBly8DHEnsQcCwEyAVWtohvIFZVphxC
-SegKD7bQ+gjLQpMSL4ucyhpp6h7xFt3pHoXhg9bPRDMYIoSPLeGKV6b5U6/wwvKOMu/yNt648NllCmPw
-mLtaYioHcyzPfKwev9+ce512tmd+lWWwGf5sx6X7tUvzVxmRVWTCmmTDcr7fu9iJye+ilbFq4uha3suK
-+z0IEEfLTGbItMA4fvjyBe6sVJppHbgce2qBIV91bQu0xH3Gidqaj7RXINgSA1NBGlxxthaYC1iiDXh4
-hla+VKutzZZEtnPRmMcifil2bfeBlDPLsVAYx/DWJAJcjpHEHbry/RyHNBsyE29lw4kFptrzChgVuEJY
-ylV6EvcZsOsytyjul3QTEsmVgPEYXr44qILIiPmcdqptdUSppjDhWIFdNfA69dbp6AybxhL551GyzWfz
-uT5soEmajfEafGVOXVEtsxARZyS+lRUzjWapemRonBpmKsLyDH4ObUOxBqpAG02+Ic4KLFVTDlu7TjU/
-zbsqhq18qXGosALfynJ2FzO7JVh+E0wFJzr0DRAX2Ix+VAenTY6Br+hYl9HNIktiwaYqE3YtlbUFlwso
-d+kb1sVfyarKZFyzx4Q/4/WJ5xFlTZE/XV1RLMUPjC+T+NkY31XgIYnjbaD23ERoJJebACehIzdwpQMN
-0v6DG+TbB2oGHxG/UM5WEl35CqON3hwCwSsTPMEo99JJAR1rz1VGzUZafm7YgOYp+Yyb+dOrZLBpGWS1
-3xwCVjPbD4H17vkWGitmlz2aIJRIo1EFkiydSdJNysrPCaOil5vhO92cdalOXJetqDx/nW3+9eI03zRN
-tKCsXc2rlz+pWQUqNO/liNAMtrGHVequkCov4v3hix4cHvTg8LAHh9/14PBlDw7/2IPDP/Xg8J96cPjn
-D0eVC0ZjaF+srnzitnvQvuDkBkmsHlN1bX/I6bJhFozHY1hRD88IxV7dYZQTASxXQsIVBhFgl8wI9mDG
-OKBEnyLChGpeOo2OqGKSpvY6bEqWAdJxxWPSlkh7B9JSmK/DtCdZ4wXPntl6NiDUw7dvZ51UobvK3vUP
-aidxTm+QT7wIE+jVtB/qm6XcjxVSMMeuYydgQsZhY7khK4lFj80uEIWMQiLqIe71DZcqoja5wMirCfgk
-3xIwysX2oDjSXSW8LdHnNmy7DKck/zWG0+oPSv+/xmgnepd4wEjHwyqZKrhKbTiWV8zbPEhTvAbpE4F9
-7EqTJkpWvAMULXG2xU6MzAj2PSA0WMn+EntktWyQqVGfp3fW9jJYoqCj7et4UpFCLiWZBToFpd0zffK0
-CXDowCszFeyNTZM2qKkVC8EjQq1MbxKBHA8Npmakf+qG27k5NDRsy/lsk8w9RacWYCy6z4xib2o171V+
-xvAr+Qny+aHyUygy8ovaIJHfVPeYDKO+0Hl69xOSi0HA1p3DHnx3CH1Qb7ohnF8ICDCPto7ut5KxEVCA
-qBZPmTNbSMQOn95Zsz0eKuivo0haESw9SvyLrB2wmhPZJW3hAzVsv3Mx2WCxuloSmVB74nkQb+ZN87wP
-orrW8pdb9+OhVpN8wYHyVfLlHfk8qwli8unc7dFZXUK2Cq48OZsNyzsVCdyaOLGk4EiLsN2Djk4eFjMg
-eBBwrGBemzRoWaI3Hxr9qvQBxtCpDtC78AqeN6C4NEzVGaVRI3grMtZaWkG7y+iMzJMsSMFH+dvURFGj
-0li1ELOOqsLXTBg7Kotmi1Ht6D5plTqkihujvLjKARLzswMRCUwdJUm0PUofiym0qjOFWio4FlhWn0ck
-xYQdI/SSjiaOEivXxUJ0tF0rCZokEtdg1o/EnlNI6UVZuNIk3K8Xp5m028c5lr9enBpO12TbeBT8x+cv
-+twgQjYwL8vTamLBuIxTBzktb69EHyMh+wftEbTVU7tX6LDGQvYPVQf1VNJhzm4sLPG3io4aW9IxhzF8
-b2byAb58iWZcKDwpmz9NdA6eg9N34Hlm2s+h3dd59hJQIZF7bWAHkr1ha8xPkcCFWntjgSNJXXB8Q/C6
-tryGpv0GhFLML6NsuH6bSj1KsucyaxF5jZNpeeM0VdNKv0ZEz8it1bZaRnmnaA1lX+nkVOFNDJHJrUV9
-M23nIto2XmPPVG317Orci3gtpq0nnpeUsYjCwCee9wPheI183zSWpejUpE1uTSDqXbHbdg/aHr5Rf6RW
-NWiTZeCrv8v5Un+njAaceerxH0j9q79VJO92S/+1ssdrWmhJTsdIbJd8TmQCZozr7P+vF6f3SD4ZHqWZ
-JfV1p6SSAnggDak27pCa01m5wECVJ+fuT9CTTmExwARewLNn8Dy/FtSL7v3ybw+QWklS0FqKuwgwMGAx
-dTocfATiEpOwE20aao+kFa3QDhqXwnQUEUx296J4e8qmJtuYHXH5aYmvA0JufDx2olKaKyYlW47g4EVw
-e1SWcs2WCPp9NW6fs3WzkkI1st/v/6mupM9HV9jPxIe6ZUskqBe+MRetBgGqiaupDqYLcbOjlWrsHL5w
-YIluTb3o2Dl48cIBtJIsLrwaO2w2c4CbqmYPXsUZLhWZP7H3r9AK2VO7FtaxYahnXVt7tAPHv9s/x83W
-1GqSIjPcNjtTKb/rOLc96fD0Lt42lyjoRHtot3lWrJARMxjyObHYuR5HUw8bJ+0ifA1yW02zZdsyZduz
-ZE0U7Jvql3Y6dlAv7XM8inZF3pBOt2rH5v6apeELyVaDdGymHDqTuN//Cn2ofrXL7tEAulr7/nwv7Yu3
-DBV81c4+m0y2QjbH2kfipkld5ri2aLT1SLvt4X24E/sFa+LJxQj++UVwu2WtGGcOTv4udli0xpc7+bso
-XbiNdtZ6qp7evT/oHfa+6738YHYH9LmmJK3ok5mFXljH6HN+EaPPagXn4wC9mNHndCV/atUOHP4Ps+sv
-92/XLwru+z30peJMbQ+2/uuer6UR0GOfr32bHeL3YdmiYHhH02agHtG2Pcy+PcjGZbIguxq5/yuGrphD
-uY/6/G5MXZqJ+n9b9yi2bsb42PHifJRTprCXmCLqbra7u/Y5vrvA7vUVuzUpJWsAo6kVI7oLRlwM1nO/
-L5bI95NcyIEDrzRuoyjFNFx4XyUvZ8gkQfzVg19NSZJ1E6WySc414iqJh0jJHsuIqWr0neVUdgSzF0lZ
-FE5OPA86cSLVYxTDEtEV8v1Nd9+GX/MLpSdHDamdxd1LY0ss14xfQ4zzIaJMBzKCLB/4PmKMqduL9BKy
-lOweYD8bm0/zG1DkkzntE4mXot/H1NvJqB4cJu6gxLfSIBuBvtvDaSisuMqpaUVTLI5TnZoHnZvfnfvN
-eRhVL9UULBWLIJKyhej4WUSXR5Rd72QflNfVMiUdy4uXrBxKHRarWzkeayHX4bG65X5Bvm2Opo6gWCql
-daLdi34Sa1dkZesBOrmf31XXG5Tc0LVAdI6bDXK0dZSoIGTrMFU/7y0f+AhKC10agbYaCWCPZWqlv4Wt
-WAPJsWWxOKMLT8qOL6uO94rHkFuWn7kDpWTco9aDK9a2lQpVlIopDR1tK5aprG6za8bsCZUDFCLOpLgv
-O3IactcNn8/RVSGLU5N1uKygZhtNaZFhBaokFbSFoG2Iin70CJ48KcOYuMaDyC+oqFsscfiqUKY+3Fac
-sf2tQhU7FNWIwopFXK7SDyn7C6t+NPs2wFScMiqY/j1qVK7HAkyjRqs4S2B+g3lUYRUV6vQAxaWgxVKl
-FVd7mA0Gz6EdAYhhG56n0OrN0DVjvjK4x+a339kCOftelRX3K8oQ/4KlOM3ciJAWIlrthYs5v8IUOfZE
-3QR9LEF3Oqq5G1R3gHHFHaEpX2D3az3nWEpC5+DabNrlZk/YxzWLO13+VOh8SaSPS11jH8+kM7FVQ/9m
-0IgInt4lwgobuPPbrngyh26pY7146UzO6A3hjCpPTmkqUW6ziM7c4BglF9iwYGNRee7mb6FRjg1FN2SO
-JOMD1yfBFUPcG6w5kfgS38pOte+4Fn2tQH1Mb9rdtHCzGzqT//qP/zweoknrOOA4W1wTVdVAcmHW7QjM
-j0+OohjUxutMouV48rfpx5PT07Pp9OOPZ//+8fz1+Omd7qXvPBic6CrkH/Hm/HXYskCmZ6fvzi4tyCzY
-FLscywQ4Bzqdnr/9+ePl2x/Pfs6D6RtiLtk1pmHreBhw3Fx0FqnfSmBIk9C/xps+8apEVxn2PUSkmZGd
-SaUMd+RpTo7fiq9Ck2FN8qvytjB6jr95Zd+Zx6nSfzsGaxr6UhHxlZlrjVxgrGUOKrhampIoNDW/TNTy
-UqYLthbGa1Cx4BmVnGCR/W8OLt5EzVt8FRXV94B4sdey10uP97EbTyL3yCdCAo6mqjbfp3eKdn2SQryw
-u499N765zmfII3TuTN6Yh8FgsEviaUcpt5r4hp+Scrph4A/1lLUufso4gPENRXU+YNzncd3AwJKauYj7
-Hu6g4QdO9Du5gEnN/Kj1O9fGVXL2GvhqnddkZp/eRbToE031vNl+Gp45BffJxCDZDE6Jx0MYQfz9NRYu
-J+bI8xUU20bgvKcMvLTpgxMeD30yqT5OrToHPx6u/EdZZUm4998BAAD//1aH5dOlZwAA
+H4sIAAAAAAAC/+097XLbOJL/9RSIKrWSNiIVO5ncnmwp5zjePe9kYlfk2bu9qVRCkZDFMUVqCUqyotUr
+3O97vnuS6wb4AZLgh2TZ2Zs7VzmmQHSj0d1odDdaiD2be35ANtNg5nSJT12L+lsy8b0Zael6z7EDDV/F
+D/qvrHXSsEOoP/neyom790wP2l3qBqzHpoZPrd4tdkjD/MykEXoL+JR6n0YTGOxOc7zbki6OzQLsopme
+Gxi2W4rPWrvGzDY1gRcgRd9G7/cNAj+f6N8Wtk9Zn38iJJjaTP8y8yzD6RODWPayS2zXDmzDcdbEdAzG
+BlPbsqgbAkQgY8O8g7kvXKsK7ve9Br3nlC4Nn/yrwX7C0ciAbDjKL2zqrX4SBEwWrhnYntvecAwfjRmF
+fq1Wl5iG+95mM5sxaAj8Bd0igm0nRII/vR65nAB11KdkZTBiOD41rDVQx6dHvDl18bVLVpSA7AidTCgM
+t6ScYo/Z7i2xAxnfeM2h8IVBXLoiwGQ97mBPSFvi3wjm4ZLf/S5kkOeeA07KZyaTmbBQ7tHunFT0gPm6
+C8dJum0zEpGIECw6UXXQZc7GzyeF0tXTkjhJzT6RSicERI2j7h89/9yxzbtoVttuQ8jajKcjCVvmThGl
+LaFMrbqE5rqLhd9GXZLG6JwopJmWXLmsashJIZ2J4TCaYU2acwXsAQvAAmLKg8H7wVCpX2aFdkm88+nM
+W9KLJZiQD5wOZJWJhODaS7ghaV+JJAzLqosKpr+tME6gZCZ1bsCesZ8/fegT+Ic49h0lzd7c95Y2AyZx
+M9oTPZuNmLKfjDvKzha46APbNAJqnf357N9xGMoCRmb2vZ21T+ccB+PDJTZKogHMHVu7ZiIfHPryPUtp
+sb/OSMRYGXYQTmhCA3P659HVx7Zqgl2ymdFg6oFdbV1fjW6Aa2PPWvcJQugs8MEa2ZN1e3Mjxu2TkIBt
+ZyvLB0wmDEPa1Pez5odvajq88EA0F/iHCBoctHSBmGWLvCAIm9YcnwYL380aIYUSc3xcCyRV9sa/gsWV
+yREtKo3hCLRIcWheycVaCCcPoqK6RWF7dPSwSaXyEqtjuUmTSSlluVaODUYRT4VKHh2/ev3Dm56kk/EG
+KLQvak7pXfrVHloMVnnuGOusGsfiQUOE7zjT2X4Swn1buBklQhLMYuFw7VhG4M5cvlezPvEKEESiTcDk
+NovYf5CtXGjuU7Sgd/c11YI/p5EHRnKv8EeS86D5fJMT/rapBOO0IgB/KOikx5YgQR036WPbtbiF6BSA
+D/Nzid3J9LuvXaKQSrgDEpLn/JUD3AvNXcz/MZ14Pq0tgmR9os6AEub5f5rzVhsH4H9EJ/aPnhXd9uR+
+mrOneYd7mOb118Y+Spn3+ZUqIBztpm/fToNrw6XNQ+qwHsmOA4XPh1dllRrnp5/vtY+Gl5j1CAZGtd3Y
+qnsTwhvI1HAtB6TQUFrxtPWtMNiJOUbMnyjaV2mZZTZsQRW6D7A5g+n+gEA6aPWFYU7bHppcD/w3RMJB
+c85pBhSG/+Vz1vcUhDDPWdJSx7ySDI6ivS8Rl+4uo2OYo2z/+99j7InPwuO3awjKbUbb7ZBSTAiIDa9g
+58pOeL5g0/bGj1iVQdMP/24zXlMSW2TxOdS9DaZkSI6yTloYgooZiQg2imjBB7y9hTDX0nMQWf9M+GgF
+W7Iw2UmsDU4f14RUvN0X0QpOKeMJ5o1ZiUGz7GVkrfhkzqOF3RwqDYoMYDFtoU1sx9G0uW/PDPCueZPp
+OZ6vaaupHVDRAkGMP3HAL9FEACha5+C8ANM17ag5HFGG3hm5uJ/D6rfAftvLago4ye/ADS+gNu5vW4Mm
+E0OEI5RAZEcxwV4UMiQFNF4EAUwiWM/poCk+NPnYXH7vwoaEfaYWQkjPGWbCE4BqgTdHPlWSEP38C3f+
+0Oi3O4PhpjYc/qQZJQJ43GPQ2WxHIXxmKdXE+YlCDCZhFAHuvkhXsH95Kx1zQe2v0fYWbRRb2HtaX8aO
+4d61OrXRbsm2OYTFTS7d054QSIWiFGtqndcZ5eT8iTVEcKWGpg6vIEohRrK14VKaGoxgBtKhsNOROdhE
+hnk2tEWgXJGqeugQgu1bUL2C1kdYFpyUaFlkVFZIk/eItmhwY7fbB62fIWcwCRE+toQLXonmr93cC8k7
+Sr3L2njhwqeXKey6lmcuZiAU/ZYGFw7Fx3frS1iy6Z7ZVZbCJvhTjYv3aynzVymRFWTC6mTDMr7fp8iJ
+ye6ihbFq7OhK3svCd7pkbvjGLJUZEi1AbPgArspGSqWJVt0E7uECg40Xk5kM9mbNg00ftuYT7hUwb0aJ
+h0EaGfveilGfkZkBmkgnxsIJcLW1wNMJWplozPJCfiG7qn0gdGahF2IckCuRCADawKNtY44zwyHOhtTE
+G+lwAowG97zmQAstEBa6Ss+iPrp3p3KLon5xNxYYwQJYNSCvXx4VQaTEfAn+ZWGvkFJOYcyxHLtK4Hnq
+rd3mGTaOJfTPw2QbjH/LDxvcOM0G/YrxqZy6vFqmIULOBPQ+KJhpOEvskaJxJJiJhGUZ/IK0BMUcqABt
+OPmaOAuwFE1529h1qtlpbooYBlrOcWBYAX/U7M5ndhVYfmUeBic89IWVAPEGH/2kDI6bHAFf0LEso5tG
+FseCdVVm25FUVhZcJqDcpe+2LP6KV1Uq45o+JvxIV2fgt6M1NZzRYuzSgEHcNIvjZ2F8F3ML7FG0DZSe
+mzCO5Aa8gzh09AWcciA96a8vDUc+UBP4bPaz63uLwBg7iFFGLw6ByFsRPJF+5mUzAWxKey4aNRmp+tyw
+Bs0j+xut508v4sFGKshiv3lLKM7sMASWu+cVNBbMLn00gYfTQqNyJEk6E6eb0MrfQgvrZmb4iTenXaoz
+0/QWbnD5Pt38l+vzbNMo1gJVO86rmz2pWcwxNO9miOAMlrFvi9QdkaIX8cvxyy45PoLfY/h9Bb+v4fcH
++H0Dv/8Ev3/4fFK4YDiG1vViDK5zC+Kda99ewrLDx0RdW58zuiyYBQo/ABmCbwKctcoOo5ohwGwBg48p
+YXNq2hMbnM4JbJtGrE8hYQybZ81aR1QRSSN5HdYlSwDxuOIxaYulvQNpCczTMO1Z2nhhvYOkZzoEyvT+
+atJOFLqD9k47Kp3EpQv21bZCTISvpsNQXy/lfopIiTh2HTTnHguisFFtyBSx6KnYBcKQEXwb1zJ8SxNc
+KojawDMxrJKAL/ArAsZgWh0Uh7qLwquIPquw7TIcSv4phuPqT1D/n2K0M75LPGAkeOsXhfAl2nAaYAnA
+gzTFqpE+YdQBR06kieIV3yQucDfdIidGwNI4Fmyv80WgzahlL2Y1MjX483wjbS/6zJi3uX0Fh1KdQlaS
+7M15Coq7Z/zkCVBsm+StmAq1BqKJG9TEim2JZTNcmdYwBDntCUz1SP/a2VZzsydoqMr5VElmT9HhAoxE
+982DXWQkNR9UfsLwo/wYPD1QfogiJb+wjcTyG/Eew17Yl7Sfb34ygqk+91Zt8GxeHRON4JvOllxeMzKn
+frh1dL6XjIWA5obLxaNyZnOJWJidNFsYBqCfRpG4Ikh6FPsXaTsgNceyi9u2D9Sww85FZIOB7JkdxNTC
+lk6izbxunvdBVJdafrV1x2oOUJNswQH6KtnyjmyeVQQx2XRudXRWlpAtglMnZ9NhebsggVsSJyoKjrgI
+IeRo8+RhPgNC9blPEea9SIOqEr3Z0OgvqA+YDS0O0DsQxr+oQbEyTOUZpX4teCky5lpaQDv8O7Fv4yxI
+zkf5t5GIovrKWDUXs/aLwtdUGNtXRbP5qLa/T1qlDClyo58VlxogNj87EBHDlFESR9v95DGfQis6Uyil
+wqeMBsXnEXExYVsIXdFRxFFsYZqUsTa3a4qgCat3iFg/sKk2cym9MAunTMKB4FNpty9gGKBNcLok2+aH
+wX90/sLPDUJkunipTquxKRAQpQ4yWt5aMI0aLNCOMBONT61ursMK+K0dYwd8UnS49ZYSluhTQUeOLe6Y
+wbj9RczkM57kiMdc4Ylq/m6sc+QFaWpN+Fee9gvS0nieXQEKsaV5J2D1wPvgrah/bjCaq7UXFjiU1DUY
+RpuuSstr3KQfxPFgcm/CbDh/m0g9TLJnMmshebWTaVnjNMJpJR9Doif2vdS2mIV5p3ANpV/x5FTuTQSR
+yq2FfVNtlyzcNt6Dj8Krtrpyde51tBaTVlhscRkLyw0Mb/9o+3RlOI40yPXyjfikStghC0SmjRmuNfbu
+Mb9m0SX+CbjikZY9mzv4d3Y7459dzwUzYeHj3wz8l38qSOXtlgxspA/buAjjDI+Q3y7ZndAgYF4HzwJA
+YfZIRQkeJXkm/LhTigkBHkhDops7JOp4jm4uoNSpuv0JetbOLQ0yJC8xOfciuzLwRWe/bNwDpKZIEUoL
+cxcBzgVYRB0PDh+BuNhA7EQbhzogaXmbtIPGJTBtJMILOgdRvAPlVuNNTY6/nKTgtwlLde1AqBYW1ow9
+CMZgezl6Ob8/USVg0wWDjobjasCPegWGOLKjaW/KCvwcY0ydVLTIWyriQr7whblo1AhXRZTt8tA6F0U3
+uVINmscvm2Rm3Ivq0UHz6CV8NhaBF5VhDZreZNIkvqhxtsjbKN+FcfozeTfbSgF8Yte2ZWzo8VmXViLt
+wPFXh+e42JoadRJmgttiZ1Lyu4xz1SmI55to28T8WLiHdurnyHL5MYEhmyGLXO1BOPVt7RReiK9Gpqtu
+7qwqb1adM6ujYN9Vv7jTsYN6cZ/jUbQr9IZ48pU7NvtrFofPpV4F0oGYMtAU9ftfoQ/Fr3bZPWpAF2vf
+H/bSvmjLwFCsdPbp1LIUwDWlfSRqGpblkUtLSBuPtNse78OdyC9Y2VYw7ZN/Bn+gYq0IZ46c/QfbYdEK
+Xw5glAu31s5aTtXzzS9H3ePuq+7rz2J3ML6VFKjlfTKx0HPr2PiWXcTGN1zB2TiAL2boHK/kr43Sgbf/
+YHb99eHt+nXOfd9DXwpO2A5g65/2tC2JgB77tO377BC/DcsWBsM7mjYB9Yi27WH27UE2LpUF2dXI/V8x
+dPkcyj7q85sxdUkm6v9t3aPYOsALEFE+qqlS2BvqGq65rnZ35VN9c0rNu7F3L1JK0gBCUwtGNKeebVIi
+PWsamxmOE+dCjkBTOG6hKPk03HZfJVczZBgjfvLgl1MSZ92YUjbxKUdUM/EQKcljCTEVjb6znFQHMgeR
+lEThEI9Y21Ei1fJcSmaGu8B7zzqHNvycX0ZyjlST2knUXRlb0mDl+XckwvkQUSYDCUGqB95HjBF1B5Fe
+TBbK7h/PftZdpPZ8+UYpUjxUfIgYBWIhwvwgO4vv2TMk6CCS49SIFed6AVnZwZRkFbjz5O5WTNq156kX
+2bu/Xl1ekzm83k0uWC2UyESgT+SSHy70t+aOYdIp/z7ooHk2M755rsZrR2CHIb0fQKB67AihbMhbfhCt
+I0LSJ63WXsJ6VNaeX77/pN6L4AWx3QczVwyQMDc/oJK57rqImYjgneOZdwfnaFFzkfURX0g3HPvW1eyA
+zpimUdfaySYdHcfRKLJNIOsTftFQsya/o5LLuuWVkTk55yeDhB8NPiYPw1LKkurJfEVWXEMV1sKw8CYb
+1V1zctVOWWFl3FFdSSmlcMuwSN3UeCQ/ogyP1C1znUXVHEVRU75uk+sElm3y8F8uD00XJ7Uz3wUuLn5S
+XBc4NdxbWm+Qk8pRwuq0ymGK7hpQD3xClFV3tUAbtQRwwJpZ5RfzC9ZAXDWRrxTr4BfGtMLv5Nf/mnfR
+0PxCJsW4J40Hl89W1S0W1K2ihvarKvcKS23lAlZ5QmqAXMIrrjROj5xk/MqGzx4RFCGLTkbKcEk5lSqa
+kornAlRxJrqCoCpE+TC+T549U2GMI3M99GsLiqgV8WYRyiSErMQZ2d8iVFE8U44InRK1HqK7E8EWbuVv
+yQbdw2IM+DYqQ4UdHFSUxC5QMRR2SUEV58P6/Dv03aJS66J7XjLr9SEF1tui6wmu5tRlIHrm8W/+h4XR
+eD9U2CiVwTLqL6kf1rKGRZBdYkRF9/ky0IWPG7QMhvXAIQDrYV1wDI1veqYY863APRC3bKRLkeUbrAB/
+QcH3n0B/z1N3zyQl31J77grkJ5gijM7KJuhgpSJ2Oim5hZl3gIHVtzEnfOG82e0CZeAQXuxHTJlNu9yh
+LG1y+19ou9M1e7nON3aAX+lW+P0OnYDbL6sG/3a2EBF5vomFta0Rq1RdpicKGpKoYQpx44W7tH3PRTcV
+NdXGmICF9Qzk1IivCvPma4nKSzN73xd6ba6xtG+NwPN1eDEfe4Zv6SsfAiWsd28XO8YrCFpQgSCYWrY6
+SYl8B0KR//7P/zrtGcPGKXhT6cLFsGKRxFcT3veJ+JrfSZjfk/E2h+FyBD/ky9n5+cVo9OXHi79+uXw/
+eL7hvfjtMvoZ/77Hj3QNLG9IIKOL808XNxJkGmxE4UMQA2dAR6PLq49fbq5+vPiYBeN3cd14d9TdNk57
+MMn6opNI/V4CMzgJ2h1da7ZVJLrCmPYhIk2NjIeRBTLckacZOX4vvjJOhjTJJ+VtbvQMf7PKvjOPE6X/
+fgzmNGgBEvHEzJVGzjFWMgcFXFXmW3JN9a9tlrwUvLyeCa8BA90LFxxJytL/ocz1h7C5wlfBlEUXph55
+LQe9Xv4Qu/EwdI/wRmxCw6ni5vt8g7TzU2rb2nYOse9Gd4Q6noH35eL9pPxB1/Vdsmo7SrlRxzf8Gpcq
+9+ZOj0+Z6+LXlAMY3QVX5gNGfR7XDZxLUhP/5cEe7qDgB431O77qDmd+0viNa+MirmuZO7jOS9LOzzch
+LbxaBJ/X1ZVGqQojxx4KJGv93Lb8LQSf0ef3lJm+LcpJ3pJ8W580f3E9kHLc9Lm5xSvsh8WlKkU1Rqe9
+hfMoqywO9/4HPC3/UA9tAAA=
 `,
 	},
 
 	"/static/view/mtgas.js": {
 		name:    "mtgas.js",
 		local:   "esc/static/view/mtgas.js",
-		size:    23644,
-		modtime: 1677521893,
+		size:    25512,
+		modtime: 1792365051,
 		compressed: `
-H4sIAAAAAAAC/+R8bXPbOJLwd/+KNp9USNWIVJx6aj/YlrNe25PVljNx2c7MXflcZ4iEJExIgguAclQe
-/fcrvPCdtElHk9u784eEIoBGo9HvDZBECWUCnlYiCscQU7Ei8XIMDMcBZltYMBqB7XmTkAhX9skfvN+5
-fbSXDf874p9ogMIxfEJfMT9NxQrHgvhI4OD0H6f/do3/mWIu+BhuVvSRXzG8IN8uCRcXsWAE83ymSUS+
-kZhXof+NYRT4LI3mRT+fRgmNcSz4hK8Qw8FknveSo/PBHxl9DJ8bt5QdqhPeqJZikO5ZAfuFl+gzSTlm
-5ebWiZDv0zQWLsch9oWecQ9/UwMWaewLQmP4hGK0xMEtQzEn4iMS+BFtToVA/iqSwK7QEjskXtARPO0B
-AIgV4d5/hnRJ4i/XlzAF2ejdYLbGTNMZfgKbolSsJj4Kwznyv9pHaujn+e8SEcQ5WcaOBDSG793JkQat
-/vFpzAUwvCQ05mc0ijDzCQphCndWyl2MuHAPrDHIH4+YC/e9dd8c+JGuz0KaBtmwJV3r3gfW/V6JBDgg
-AqY5JZ1ILFFGpKJXEnIJ6P6o9l725t6Csgvkr9RYmJ6A/N+TgEm8lLBRyPGoGEoW0JhG/tWGCZbio2aH
-OV5Qhi8CIiRK/7j5/IuXIMaxox65YCReksVGzzA6AphMIEJfMSDwabLJAW4b+MD+FOI0DOt4Ze3eLKdq
-vUuZpVBwdcmd2jbcvbsfVUZsAYcc9wVTsEETUMuKNAytjhxD+W151xlNBZ7FSVrZez9lDMeivDiGRcpi
-aEIgJSaenZehKNgtMNR7jwvEBP+NiJVjJ6Frt2GHguBadn6OL9WGqF7cS1K+cmx7dNSfAAEOsZoA8U3s
-V6cZw6I8k9z+fZ/GC8Iixz5lGDY0BZ6ah0cUCxAUDETLhp80cr+gCEslYn2wRyNDhAJDLawpC7t0jxKt
-SQ5tdl6MDbEUc57QmJckRLBNjZ2yPnKZj4gIs/YFFv5KSouTsnAMTxEWKxocgn1+cXlxe2FvS4Tcgo+E
-vwIHM1ZnemUjPMwYZY59If/TRCDx8hAk4nLMUQ2jKhW2rfqEJyHxsVN+ReIAf/u80GwwhoMhe83Rut9O
-6z1Jcr6WCuaUMbTxpMVyFt4/U8w2N8oQUXYaho51F6MIT4sh99Zo5C1IKDBzfKkLfc9fYf8rDkZehJLs
-3RqFaVkl6pnPZufXN33nVOKkppNwiYRrS6ITAzzDgsmW/X3mCUYiZ9SYNAln53wWZ3Or/z1OI+z4JFCD
-2wReNY5qSr0Mqs4sKMRMOJbh75BwAbNzDj6KYypgjgHHAjMcQITiFIXhBlAcQJRy1ajEb5nK9vkGtCsg
-rYTyJFC8AfyNcPWGJnJ3uWe1cl4L42kqxPjx0+3HU5jW0JZCfAgLT5JcE3Zcaa96HLNz2VfU3rWNu1Z6
-XfbWGr6tT2FuZD+S/8o4qgZR6cJDuPM8L8aPcIOFI59L7DwGz/PU7tyP7qujb9J5jMXtJpEgevEfz0cM
-4vn6Es/xAqWh0Cs0P5oLLLbrBdzmqRA0VggZp2QuZ597AeFoHuLAOBYl7ngBIpFWchDAAard+BXn8Mcf
-YNujP1vDO/bs3AYSg7amH8C+Or09+7sNh2Bffb65tccwp8HmEGq+lJGO0XdYBo7Wz9iFHexq3cvczda2
-Qe02YspVqBK5TprJBH4mYSjbZ+daf+WbyClk7m+IBGbwKHs+UvbVa/rByunKhnq/c9qK0Z9A2N0TtS1e
-KFqr8ZY22xlDDvACpE4s+ZPNGKfie7wbw7txqy2wrJcsQL1Hpusb7zONbds1bWxocag0yzOK+s6+YmSN
-BLbH9mmS2GP7HAlkj+3f8Nwe2wo1GSjbY/sG+ykjYmOPbR0sy7hYvldRdv7gfj7LBhJB1riM2bZB7n5R
-ShFoll062aVlm3wU+zh8zvPvJ2TXOKJrLBkFlGH3GZbBONAYa6mTLkhVrF7vgrYGc218W49gTXzK8Boz
-FUUkDK8JTTmsMeOExp2esiKnilbbiEiTTZmEArElFmPgNGU+np03nV7dIm1ZOa7XNj1SMb1SONMCxN27
-cjZATWDiMch6md+NbnWRKQbUW5ozKE4rzaB+D1ADaRIggXMwtXBAz9KIM2fldMr+vsHFeCl7A/IE/XME
-rSw1ROoGZAMkwF+vzngzSDLJt9l5LVIygZXS+zg4zXpJANnzUSn+VzNXVmLbVbW28HQO8GdGo1+vzopl
-1BrqbtdRa8euPKCXYTyTsgxTeFceH9CZgtCzVycmL/p/hkbaBcwJJluUHbf/nEj/9b6bAiNts2SS4aG9
-lIsuhtkvcUyeJFE68RHJ6DCUBEZLRGJ4XJEQg+RVEi/36itXlDPqoMhIMiPnJ/BkEqOeYfTyrz/+gLv7
-FvO2Tnxed7LyKWRkU4AvgVMt60RFPVUbsE78MTxl7oAZIr2K7Uj6SyESTisOHqdMOM76YAzr9yO1mkaC
-cn2gE05v38L++r16zugJ7sFRs//7Uv+Dav+27gb8MQwBfgwvgDZv1weScrNzDV4/fgD3AA7LA7ajZ1XK
-SkThQyOdeqwTAieu60r2Bdd1jyfmXaPzm6cS0YttbPTrnq40JaiAd2q9eVonvl7U1jrRv9Smwx9QbutG
-S/41pxptH75fh5Z87YZJuP142mIS1omv9GJr7kzRq8S5d1nv+6NmT2Uv8vUfdRgXzQ1TPeD1RqXLJIyG
-GZAXlH6mENSy6r7JS3bBDP4J7Mk68ZVx6FKbso9s11SUv5TD9q9tPQxRwVAVSvW5XRgVzSj7hlNeZUw0
-QEnK7ti6mFj1e/u2NEo6YNWfhcPKatzwOhXWTcN+mk2xidRsulT3vZpNert1n10puawAopVca7ed6rvd
-i3eXZjS16pboSiyR0nVNtaiIXWaMrOszIVRR3+oTP7VRuDt4UrBbI6cstih6dAUMV5ctxkEzeVf9WIar
-3aFaoy7BuyvzD2+e9FTbSRIqAX3YvdbTGOxA8UX6dIKpLKnMA39thUxTsqab+od6KxQHIb66PAuJ/7XM
-xHgMMYrwGEgwhuY2Yi9heI1jYVL0TUeVr+jj1aU5z+A0Nq0FfFtdWmHelafTjIHCsJQEgyncVZ2APCVW
-e53OQ+LX36qsWfWVTqFV36l8WvVVKblWbSgybdX35bRbbYTJwbW9VQm5lpl1dq5ouX+NZcmsgsl50Qi3
-nuEYyQhp/wvHzCP8NIhI7IxalfaH7CxUa+uhxuJYJ53hr77kQWlFnFFRZozxozPaWuCHiPOpFXDXd82A
-0rPrJoxEiG2qL3mEwtA6+QU/NgzlLY6SEAl8PNGdTx62L5BDWsmMGk7rgso06qBHty1V9nRBWQQkmFoS
-1M+URfnC1cwGtgU05uk8ImJqmYhJWScL/lo9HYUDRU5comeW23GwF2CBSOiZId7sfAzYM2c+bnXia2ud
-dCKrEA7IOsOQYyWh7gojKbMBd1M3QYF0qFz34AVAdWABd0OX0UcNBoVkGbtE4Ii7Lo6DHsBaAfo0dN33
-PUcrCCGa47DCfPqNQitCbEli1303AKIqERjeUxWEQSOPVTVFcYhUn5ZSotlz7oXlx01qcrMgOAxAgXAj
-HJA0GkKKiVp5T8pPArL+nk36yxDMNFHEJsFTSyVh5/SbpYhUFMkzUpXflGnjryjxMZSeM/UBHzIvUAmT
-zujGXzhWuaK3b6H6xgtxvBQrqbNKGq0sguWUs8kzYy/LIYeUYy4cW2oCe1Sq8kuAH0yKeWr2uEgsbwfz
-9IKybmqoLtbJR7oGneY+JtESOPOn1oQLJIg/IRFaYj5Z0rWvDiEkUi9xsQnx1FpjJoiPQi24hzCnQtDo
-CFaYLFfiEN6/S74dGekJ8UIcwl+Sb0fWySAee3HrjWtS7Hz+ou/GN8htILya2q0YGGIf8wTFWYOgNBQk
-sSBAArnm19Q6DQIQVLo8qpKlOJDExoGyTgz444kEdfJjJfb/D5HY8lKVKl2EFAnXZZI/BmrTzH/QTGAq
-2F2yx9EavyBzfbwNEq8x49g6uUHrwoXYLdolrHURVFcah2B3psYNx88w0C4Zp0e3Pl06/Q2XY5/GAWIb
-6+RM1XZBuX25mzcIdtX9+D4vprfTUVYV9TNjVg8fpO7izs6HaYDCt2jOrlXo81i1uBc1n6QlNbS3K/76
-Ll+vTHqjTHsQXJvwYUTWjrmicjaRpm3btJqiekhO0u9wR1YoXqrdwNWA4OpSxgMdLkj5QOJoO0A5N5wU
-GKQgTbCUZXaK447VSpuOIeuJSJMNsuBDHgkZdLKkV5aB3Z7kvfMM5MNo+zAI18MXArtuEjWK9j9meYMw
-fdj2tAQamf8OY7BzhY1SQfsqj7I3k/AUB9QtO3ZnNI7VHpnDWnyIeX3zVM1vKfbgoitXPyx/P9ClfvPE
-xTbTV8V535KWV+2525yp/TL62akpLkawPwX3YNvXHX+dz21wavG3VcvgiKMfIXsIWH8Z+F8hBCZVr86d
-D+P/Up57qmsG/e3IB7AudU3P8zxrb6A6L82txC4Jm2ct+vyZHN3rTMQLMlkc53/zlISe9KmMgBYtJQHN
-ujRk1FydysRT9fvzJLRLWttWs+PkW2VqlHNseEnirxasGF5MrXIcVnKVKsUSB48hCVWaTT3o9GlPHwrU
-8nRB1MmWOjqeoFeScKgWe+5vMJBhPL0d9e7e0wn7v6dry9GKlNtewYoS8OMBjFLVDMot1bcwTwaqskyP
-1q6MmYuheZEoLwjp7FURjpo1mjhJ/yhcYfl7a0ESIh+vaBhgNrVk6N9CmbYEuDFGD9u+rP+wG+O+G04w
-6Z+e1bE8QWv4QtDkEN6r3Gum8aqJp+zmrUk9nZwGgb6mkOeUjufsh/n7+64LZ5+v/h1+vv78CW4vPl1d
-nt5egOvuJGlEk82/TLrooHfiwuQTtGz4NNn8zGjUxvUKtYDwJEQb1yVxSGLszkPqfx0U0rfcB6BihdXF
-UPXg6UNX5k6SvvGX9xjuAjVC32wSdaxI/zA3qx3TVOQbPoANTl5AUdfa7NELB41eK/KVCPj57G4tJy13
-7aU6ULazmQvRIfF1eVdc3T//u0M5nX26+nx9qyX19LcbuL64+fzl+mxX0qrP3IMkiQaP9QWQ/zmCWxpe
-HEQ7/e1mSJwrYUgDaUr4+t7dGY0FIjFmQ+so1bMDkB0M4OpgnzYH5s1Wms1q9yHFhd6VpqaGqxxctiA/
-n1fKbRrZeqoeXC7ym9XbHC4cPFcKaqugDyqaD6ZOx3K7jig+S4NOddJ2aaWDQNMpvPvxVDAqTVMhw7a0
-1j56VRPvxQr70FtCLhx06t+mx2U01bAa3A+rqR1PJBX6nrjtk+muqcU8BNm47kFNy777kYeLyqdi9oZX
-LkwxYtgxiMpxB0goJ3INhwyHSJA1PgLtfyffrJMHOMzCoAHomXp/jl2trO67cxQsMah/pc+3oLo2LKQE
-7eCEwUCsex4ubASEez/0TMHz5xHVPduBhfi2Q4nVMl7tMOXWOrkIiHhF4f5Z3PUniXaPfe8iZMu50a3k
-KYnWUA3ZL4X8QulqZ0cOugrc4EKlMLcdOKMc6s5psBnokerqjCvNJO/J+40y1SG01G9+pyR27DHYu82C
-7CYjVaYAkrFpjKRKkR6+3CKdKtJYPey0RNN3WxDn1CdI4MB9JGLVY2dO8xEgR8CbfuWH3iK5N6AUv9cz
-p66T6P+v5RDWU8u9AQionyo/a4nFRahOxP9tMwvyb+H8BJbE2Bp5ioqXhAtP0OUyxI69IkGAY7vhqIck
-/ppLZX3R0qc9gA9gH0j7ZsNhO23gJ7CVAbS3vTPzeUiWTX2+VbhnyGl8X3GIIsdNcr0JvX69OhueVHlW
-RgYGjGan3zy13cnR9xVyzG/pl+vLEuajjh0rengMq6SuM/mPyWQ5BhsmoNI36E8LNvsnenqD7bczh2C/
-M7z2ff58Z3PLBbraYmvX6UreWp5SaLmjQ2L1wdDaxS8/H1GqlxZfoPU4Fs7dU6y/oFM7uMat7f3oVRcj
-M+GbI//rktE0DhqC10GhfGhEAxQOGlXNAZllQz326ZCtfF6/R9amQFJuivUqNuhaiTaKVQ4oNnHvOaaA
-aacK1582a36JU1H52XGyQ3NcsbHPDS562Y3PsGUprGe+8pt/jiN7qN+j1pDU0p4BE710BZv3uY3I8+uI
-V4xGhGMPhaFzV7+bWFrWaNy4uZihOrrf4e1FcxgUlua4Kfqe69vV/GLpRqO6NC2JqL/K0frB4Ur3g/va
-Be1q1MmE40QHY4i6PpwRmS9bTKcQ1T5tIdvURymi917pixSjtk9YRPkHMgyU4gMWUP6CxQtXNbd7/xUA
-AP//QMjLD1xcAAA=
+H4sIAAAAAAAC/+U8a3PbOJLf8ytgXmpJ1ohUnNraD35lvbZn1lvO2GV7bu7K6zpTJCRxwoeOIO2oPPrv
+1w2AJPiSSFnJ7N2lUolIAA2g0e9u0A8XcZKS13kaBiMSxencj2YjktDIo8mKTJM4JLptjwM/tbBP8cP+
+jemH73w5/O8O+xx7DoD47Hyh7DRL5zRKfddJqXf6j9P/uKX/nVGWshG5m8cv7CahU//rlc/SiyhNfMqK
+mcah/9WPWBX63xLqeG6ShZOynxtDYwSTsDGbOwn1xpOiF44uBv+UxC/BunEz7FCd8I63lINEzwrYX5iC
+n3EGT2pz60SO68ZZlFqMBtRNxYzv6Fc+YJpFburHEeAvcmbUu0+ciPnpT4DAF2d5mqaOOw8R2A20Gn40
+jU3y+o7AHzgxZv9XEM/86JfbK3JMsNG+o8kzTQSeyQ9Ejx04krHrBMHEcb/AxDj0evIbLsRhzJ9FBgIa
+kbeepClA83/cOGIp0NIMNsbO4jCkies7AazxQcuYRR2WWvvaiODDCwC1PmqPzYE/xc9nQZx5+bBZ/Cx6
+70NvBQXU81Pok2PSCNOZkyOp7LUIGAJ6PKy9x97MnsbJBWCajyXHJwT/txEwcAXCdgJGzXKoPyWNafBP
+bViaZPSw2WFCYTp6Ad1wSf+4u/7ZXjgJowb/yQCd0cyfLsUM5iEh4zEJ4VCIAwhaLAuAq8Z6yN4xibIg
+qK8rb7cvC6zWu6gk5Xg3V8yoHcPDh0ezMmJFKGClL5iSDJqAWnYkYAhxZEjMr9RTT+IspZfRIqucvZsl
+MCZVN5fQNEsi0oTgK0R8ea5C4bBbYPD3cEBOkrJf/XRu6IvA0ttW53jeLXZeR5f8QHgvZi8yBtB087A/
+AjyQJnwChy0jtzrNiEzVmfD494C3pn4SGvppQskyzgjL5I8XJ0pJGhMJUdNBcPDF/eyEFIWI9kk3TYmE
+coWCWbMk6JI9nLXGBbTL83IsTATwGAhKpnBImixr5JT3wW2+OMDnYu9Tmrpz5BYDph+R15Cm89g7IPr5
+xdXF/YW+UhC5IiDD3DkxaJLUiZ7rCBsa4sTQL/A/gQRgQAAGC8cxh7UVVbGwapUnbBH4LjXUVz6c5Nfr
+qSCDEdkfctbMee530uJMFgVdo4A5TRJnaaPGMqY2CPFkeccVUZycBoGhPURwysflkEfNNO2pH6SwFhdl
+oWu7c+p+oZ5ph84if/fsBJkqEsXMZ5fnt3d95+TsxKdDuD7C1RHpvgSeryLBlr29xAbJGBpmY9JFcHnO
+LqN8bv6/zeKQGq7v8cFtDM8bzZpQV0HVicUJaJIamqTvAIAQ6AzUFYH5RCaUgOChoO9BVkcZKNwlcSJ4
+yBhv5Ow3y7B9siTCFEAtwS0JJ1oS+hUg4pt4gafLbK2V8loIT2Ahoi+f7386BQRUl41MfECmNqJcIHZU
+aa9aHJfn2DetvWsbd8vlOvYWEr6tT6lusJ9fPOUUVYPIZeEBebBtG3ZD7mhq4G+FnEcEnvnpPJqP1dF3
+2SSi6f1ygSB60R8rRgyi+foWz+nUyYJU7FA+tG/wlLEYNGBK+U7vnUlABb6dtgYxnST6KqCbJF44s1p/
+hpAWrS0SFEql1Bg//JONHn8YFzv2PcFgvqdMU5LXBlxOsjSNI45AaURNENzE9nyG03vSEFKoeQNEH7X6
+IIADVJG0g87J77+DtDG/tUYy9MtzHdZEhPb/RPSb0/uzv+sE9MvN9d29PiKT2FsekJrtJ7nZfIMmA6Wx
+Ro/t4FTrVvFujrYNarfS5aZNFcl11ID1/KMfBNgOx87lbXGILCa5uR4A0yTkBXu+xMkXu2m3cyMxHwpu
+XNy6om+A2N0jtc2/KVur/qEwM3KCHGC1oAxX7N+mT1axlT6MCPxt012atklj1XvkuqnxPtcwuv7YSzTX
+AXQJ3ocaOInaAy6o1uipB/0m8Z8Bnj7STxcL+PfcSR3471c6gX/5TjFOAL/vKDg3frqEnyJWgGEBfM+D
+DMUP6/osHwhLeKbqRleN0+vnpJV+tmrRYpeWUwd7yKXBOsenH8/e0jAGqxeJiNs1bkIxFkHiiAomRgus
+yqXbW+CtvmwbG9QdeOmeJxTUDXeiwFh59uOMEXjBYPedjgJHJ3fW25AIfr6KQnA5ZzQdgbzKEhdIrmnz
+ixZUjWpYQyj4kIc0uPw6LkHA+SnkwCeQ7ijJe8nnRrc6B5YD6i3NGTilKTPw5wFSJVt4yH85mJo3JGZp
+uNmXajRpb0+uRRpp7waESfqHSFpJagjXDQiGIMB/vzljTR9Rxh4vz2uOovQruRqh3mneCwHkvw+V8Aef
+ubITXa+KNTB+eQj0R2BNWEq5jVpD3Yo7bO3YFQa18xVfIi8DiA/qeC++5BB69upcyUZzUuJIWJQFwrCF
+mwX6twl0bG8KcjCo6pFIhkc2kC+6CGZPoZgiRsRl4ouDznGACAaVCZL+Ze4HlCCtwlLe1XfOMSfFQRmQ
+TSSfn5BXGRe2JaGrT2DLPzy2qLfnhcvqNlsxBTp2JXgFHG+Bofi6qgPgJZgouXUhh6CRsjLR/AIT0mhd
+g82A3gzjeX9Enj+afDeN+Ozzvoi3/elPZO/5I/+d45NY+4fN/h+V/vvV/m3dJfgjMgQ49F4PWr6FXoA5
+IAcOXvz8BJDBzVEGrMy1IgXTS0+NaPKRiIecWJaF5Evg/6OxfNfo/P5VQXp5jI1+3dMpUxLuNR9r718B
+itjUSjsRT/zQye9EbeteFv5pTmWunt4uQxXTvaESwGxqUQmwYC4XW0OHHF8K5T7kvR8Pmz25vij2f9ih
+XAQ1HIsB2yuVLpVgDlMgG4R+LhD4tuq2ySa9IAeDGhjDcK4cusQm9sF2gUV84gbbv7b2kEglEqtESU/u
+QqkIQtmTlLKVMhEAEZXdrno5Me8H8rN8QgOs+lgarEmNGrYTYd047CfZOJmgZBOZyrdKNrR26zY7F3J5
+/kcIudZuO5V3u2fvLskoU/Ut3hXsksu6pljkyFYJI++6xoUq03t9/Kc2DHc7Txx2q+eU+xZljy6HAXo1
+lYMg8q70Obqr3a5aIy3DugsTnt6/iqlW40XAGfRp91JPrGAHgi8UxRkyscYjD2zbBKHAZE029Xf15k7k
+BfTm6izw3S8qEdMRwazGiPjeiDSPkWIa5RlYRGYomoYqm8cvN1eynMNoHFoL+La0PF95V9hPEAaIcSUI
+hlUZVSOgCInVXmcT2HP9LY+aVV+JEFr1HY+nVV8pwbVqQxlpq75Xw261ETIG1/aWB+RaZhbRubLlcRvN
+kmsFGfPCnGdbCYuJHtIeli7ZPjv1Qh+OpVVof8pLwVpbD8QqjkQMm/zVRRpELWKYZZY1oi+GudKIG4DT
+dKx5zHItOUD5bVmLxA8d4OnKSxYCcWgnP9OXhqK8p+ECY/RHY9H55Gm1AR2oJXNsGK0bUnHUgY9uXcr1
+KfipIbDEsYagfoSHYuN8ZglbI0D42ST002NNekxcO2nkr9XiMOpxdFIFn3lsx6C2R1PHD2w5xMagDrVl
+ycu9CHyB7u5cLF+w5z/nK2SUc6g1pw7yLBxEZi0cDw0qy9rfAKgODEYHFohQAcYJwF22/JSGzLKAmnsA
+awXoxoFlfew5mkMInAkNKsQn3vBlAcHNfCCzDwMg8oyDpD2ekBg08ognZziFoPjUuBDNfxdWWFFtU+Ob
+qU8Dj3AQVgi0lIVDUDHmO++J+TGg/i2H9JchKxNISUH8H2s8CDuJv2ocSWWNQI4q9Y2KG3ce+y4lyu9c
+fJBPuRXImUlEdCOQfjxWBPZ+9Y0d0GiWzlFmKRJNZUE15CzjzNTOY8hBzChLDR0lgW4qRQ4I8JMMMR/L
+My4Dy6vBNA0TdGODd9FOoJGIMPeRH84IS9xjbcxSJ/XdMYjbGWXjWfzs8hqMBcolli4DQDJmL3zwrwTj
+HpBJDCI2PCRz6s/m6QH5+GHx9VByT0Cn8OYv8EI7GURjG49emiblyRcv+h58A90SwtbYbl2BRPYRWzhR
+3pDGcZD6C40AqTiWfDrWTj0Ps0KwOp7J4hQI3qswoLQTCf5ojKBOvi/H/nkIx6pb5aJ0CnoptawE6WOg
+NM3tB0EEMiHexXtY+raB5/pYG36E+TiqndwBvMKE2O2ylVWLJKjINA5Z3RkfN3x9koB2STg9uvXp0mlv
+gMUD7oAHJiBsm+d2CTf7CjNvEOyq+fE2K6a30aGKinrJnNbDBqmbuJfnwyRAaVs0ZxcidP2qWsyLmk3S
+Ehp6tyv6epOtp6JeCtMeCBcqfBiShWHOsZxPJHDbNq3AqBhSoPQN5gh4+zN+GrTqEGCEp9MEUesxzdUA
+4dwwUsggASmdpTyyU1Z7VjNtwoesByJlNAiwVXhCcjl50CuPwK5Oit5FBPLJXD0NWuvBBseuG0WNpP33
+2d6glT6temoCsZg/Qhn8IQK7Ljla6277CJI+pl8u3WdSuvNyd5LiJNwkzOcmTplGIS9+Oj8kAcWC/0ng
+RF+wq3hM5zQEQzKOwE4oytVESRvhS9/Kiix1SAcyhLDrganN2qS1yE4U4gJngNp36TwOwDYAnM5erCSd
+WN9a3/x5C6ppr7H+DmTDRIGZnF2lG110ww4jUD8LJ+G1cpMlcUFOOUw7KSoXyX2sEg17I9V0IUOQTR9U
+cbpRSEVoyPZKS1HpYdq/xX5k6COim9+WcP5ooeZkadyXQlXaWrCMerGleqtncRRxxSMrUNkQn+H9azVo
+z3UeS7sSkMOSkgPjBO9fWbrKCay8w6FQEG8vYgG59FGXn5eCstTEhLO1v+obY9gukCDX1BJE4C2Dwyj9
+ENnDaujPA/8nmEDmH/ldomH0ryTvjkUitL9x/IloV6JQwbZtrfewg9yeLubmbLcImgVkff7IxMN2du8G
+niyvaL1/XQQ2OoqFBshbFAbNuzR4VF6HzdmT9/t2HNqt4Ju72XFGoTK1U1BscOVHXzQyhwUca2pwSfH/
+KhlgTPvCEn/mqVm+1jWx6bpjSPj2RJWHkW/VPBo7W6JwqBRb92cwkGE0vTJ7d+/pWf7/k7VqCAb5tlcE
+hjP40QBCqUoG7muLm/UnA0VZLkdr14DlZf8i811kuYVZXFq6co8y+CMeSv8en+u2KMYzWzDTltWTyuhp
+1Zf0n3aj3HdDCTKm3TPlX2SdJF2k8eKAfOQJpVziVaPp+dcUZDz9BJMq/EURKD+aJN/N3t+zLHJ2ffOf
+5Mfb68/k/uLzzdXp/QWxrJ1EwvHiz79KDHy/d0xFBkkFb+DlJSzSa6N6vjTPZ7C/JeY9Aj+i1iSI3S+D
+4pQtl5yAeym/7M9/2KKSVN7bFLe4ix7DTaBGPC+fhNdKigf5tQxDNpVB1E9EJ0aRFeZXf8FvXV89uS3L
+V8J661NWtUQbntqm5HZ+srkJ0cHxdX7nVN0/qbVDPr38fHN9ey849fTXO3J7cXf9y+3ZrrhVXCQiiBIB
+nopbbf97GFcZXlbXwlaG+LkIgwcQRV2SuJt8FkepA8ydDE0OVwuiSF7txHi1slAH8s0K1Wa1+5CMae/0
+eVPCVW5jaKQoOlYSNpK3Xqu3McqkTfWKmkX21+W328qCBlUCDcZOx3a76q7X4qBTnLTdxOtAEPjcH74/
+FqRIE1jIV6vstY9cFcjbWDY09OojUEyn/G1aXFJSDSss+G6FAkdjxELfawR90nc1sVi4IGBz7Nek7Ifv
+WTGplvr17VyxJIRrMqi2q1LDRRYxVh7H0UFCwcT0n+khEfb34qt28gSIlW7QgOXJIqZidbVaIaBGx5tR
+wv9Fm28ai4IXjEXvomxq4Kp7Vkw3HMLhAci3FEqtL7LmHw8YWF3UVmldrU2oVYiDfsVvHGxRjbR27eIz
+c7tffe/KipZi+BXSFC5rqITsF0LekI/fWR1VV9UOaItKtcFq4Iw41MLvFA20SEV2xkI1yXrSfiNNdUBa
+8jdK/m9XUZC1OepctHXurRjmqcnVg7VQ5SkMFF9r06M5zbc3FwywcTvFeEbu42IXHVCVw9hiR0e97fBN
+kUB1Ow7GBCIHRTl6VsgaIkQnV7jT1FhfdiiKKDwL6zx6cIRCVjgCUNALU71FYS9on4boP0cmL/6tpaL3
+teUSGvFiN+P2LZjFFwG/XvW35aVXfKftB6LhijXT5ljEqK2dxrNZQA197nsejfSGgxRgFiWXhvVNoy+x
+j0GZfbQrMBzT3u0HonPDQ1/1zogUrnA+9fmKrz1fnFjvFhV5xdqQ6qXLix9DGBzMWssjAx11edLvX9su
+eIrLb8XK7+Nfbq+UlZsdJ1b2sBPKg+nG+J/j8QykCxkLCeN8Mye/f4CtN9h+J3NA9A+S1t7mR3U2t9zG
+rm22djdbsZKLUE7LhU8/4h/frt0idosRSp66/Jo7uLKp8fAaia+71aqgmbZ6NLe6ZZ8zH37pfAbSPvIa
+jNeBoWJoiJ9AHzSqGnuT2yZ1n7ODt4p53R7RsnKReCjaVmTQtROhFKsUUB7iu3VEAaffJcLFZzebX7Xm
+WF47Djs0x5UHu25w2UtvfCI0Dx2u+WJ+8W2n/Ef9oxwCEt/aGjDhpu95sD5X21lxtx1sr9Bn1Aa/x3io
+X3RXtmWOGtfg86Wajzu8Cp/WyhSdt3wLpBrXVa7H8y9wIBJF4V/rx/sr3fcfa1/7qHr7+BWmcH9Ewq6v
+MIXyM0lgI4S17yRhG//CETQonzcy276HFBZfW5JQyq8hEfVzSBvu/a/e/Q+inAP/qGMAAA==
 `,
 	},
 
@@ -12874,291 +12932,329 @@ Swha4rvMEofyXMiJ0J6a7q9sFhsraMkYVkIPi6nNcmDTwe7Sypqo2f037jHpPwjLV64QHW8vIp/ujVeo
 	"/static/view/vpc.js": {
 		name:    "vpc.js",
 		local:   "esc/static/view/vpc.js",
-		size:    59668,
-		modtime: 1677521893,
+		size:    72190,
+		modtime: 1792369556,
 		compressed: `
-H4sIAAAAAAAC/+R973fbOJLgZ/mvqHDzWtRGoiIn3TsnW8q47Z6s9tKJz3H33nTa14FISGKHIjkEJdvj
-6Nu9d9/uy33Zv2//knv4SYAEacmOk/ROz3sTiygUgEKhAFQVqsJlmmQ53CzyZdSFOMkXYTzvQobjAGcb
-mGXJEtqe14/CvEdh1B/e76R9sCern4QkjdA1OUfkA+nCMYp9HMlf/4rIj0mAoi78iD5gcrTKFzjOQx/l
-ODj6t6P/eYb/tsIkJ114iXNynOGAFqOIqPb7y/AqjInZ5vcZRoGfrZbTAs5PlmkS4zgnfbJAGQ76UwVF
-a6vKL7PkMmqqN6cARpWfiEaR/orgzCj++fT4/DrFBcQ69fPrFOtA1paQ7yerOO8RHGE/N8ZowkdoiqPe
-KuQge/iKwcxWsZ+HSQw/nx6fojl2w3iWdOBmr5UvQuL9FiXzMP7p7BWMgJZ4b3G2xtlphmfhFTyBdoJW
-+aLvoyiaIv9D+0DW89kcsilsqJ0j8qHPQYuqU0QwrXhbvTY84aVHnASTE1pUfP759Fh+2mu9mf5OyYMI
-CeexSxvqwsOwXedgr7UnxuKj+CgI/hJm+BJFEYxAkHc0phRuZThfZTFQ1vBCchQsw9jtwDffgOCGUn1X
-DouWMTj24XhF8mR5uppGoX+WrHJMYDQageMc7LU2Wk/O8DJZ43t1xkRxn/78FoQETSM8mf0YzjOUh/Gc
-9mid+hyZ6JSfxCQHHOEl5WEYQZD4K/q397cVzq7fMqZPsqMoch2vitKhk9EKZ+DKQYRE9l4AJTH9rhpm
-rN9qyRa9WZL9gPyFiyPaJRzJRgIYQZ6tMGtgAzgieIeaMxQRUVWnCcGxmmzBXzACRK5jH9yCKNhLM7zG
-cX6CZ2gV5W7nYE/RCvHlPALs5Sib49xbo2iFaVsRziHNkmVKsb4/yjBcJysgK/HHJYpzyBN4fMNxbOA1
-zi+T7APIPr2AoyiCJF/gTLRD4DKMIphiUKNbxXkYQb7AtC0fEwIhASqHIpzjwHuveuon8SzMlowg4m+X
-d0/N2iMF0wHOn9pIWRnlGkb3oyAQ3ZW9HSpaUO5DQeB0KcEZBkqKDJM0iQkjTZ5dczTyI6X7JQpz4DMz
-w7m/+Le3b167NnnEvp3hOW2Mypt16u8gnWKz2+0u3CxxvkiCIbRP37w9b3dhmgTXQ6DteyTPwngezq5d
-Pv7ORrCgj3J/AS7OMsHDKMJZ7rZ/yLIkAxQEYTzvZ3QB07UmGoWZFAdDoF2jtQ84HTi1GYdK9lwkl1Q6
-upJI3u8kiT36aXLSYau7VbByXuywRCxtxcFFkUdw7r67idESD8ERtCJOF6Iw/jAEp59myTokYRLLnY44
-GzqP9D9RbZ363mmWUAH/Gi0xPAEHeuDAE1YicNaXTE6aWutXoG3NC+xt6DEy0k9sgjcXii6U5dapP4ln
-CYwgXkXRAf8YJYjODYXnZUw6HJQl5U8TWiS2a5fPsdww/Q/zLFnFgedHiJBXIck9NtPYbS/CIMBxu2MI
-XxzfASMKghp0dAQ/nx4TJawUUiSJ1hF7DD0YupR+7TYjI0ewSgOUY7F+w3juxfjyOIljzLC8oQKHkbOz
-t2uNsrw+qMeAguAUY7q6CkT11VOMs2Jpj0CN9EAJqFUW1R1gFHdRbkG6hGBrqn1wLxm1yqI6ocBOrh6m
-MkGKBlaPygQ6hY1igIpk2+gfacNXghqg34dLRIAeDnEAaI7CGC4XYYQlyytKrVOfyQhTsHCJSuDjR3h3
-0fGWKHUzLmRHYxB/eozvNIh16tNi87y3Tv0u3HB8QxA16ZrddDodbxah3O14szDKceauafW1N6FHvmRJ
-j3v0ZOOupYB/NDLk/cePsBbCXJZwVmUEpAPzSJLlrrsedGG9r0QgI+Z6wCXHN9/Ao/U++1vSD3qDAwW2
-r4ENTDANSiA7hC1QHYIdkfixHogxMWT8zxfQG8CQw2065mqmd7r3TCweJildOONer0cZCnq93mFffGMA
-j28YUYq54tK0wCBRADu4jBxWQZB785H/4sLVGfNfbCbhI+hl4Br1OmYnWrypzub955FC/HaGg0kc4CsY
-wdODe0kysUOURLCC5dtISRRzrj+KAymM7ykGKTdpON89vYDRCNq9diEB+n3Q2ECt9ne8WpdthycXMAIN
-j0fSKMzd9ke2x6hDbSFoLQLIEMpnBS4Tc4EuEYR6u5rGmF0ramhhXDLc9j+VKupdvJOwVyfFrDg8ivOD
-thu8CKZv4uj6H2dTMFHqE/rImNE77TWEzd3LLFmllj3nrV4qthUuv+eDLsxL8nsuRO5oBPOSzKVlHBm9
-V8IhBdB+S2Ha0YTuXAlwgawkcg/qZO7jG31QHkmW2KXHt5R2lv2hN01vQ6dZuEY5djrAxeEL0ORv6zCM
-01UO+XWKR46/wP6HaXLlQBiMnNS/kmJGiAeJCuhpuBGAHSVHTkB6fs9fJKGPQfu71yNLFEXOWHaCKaxg
-lmRbI2U1nLEoO+QqL0Pgt4ZSS8l+bgT5OLOtURRK2cdJ6ep05ecLTtguhMEV4wV937KTrY4uOqvVEVeD
-6T2+CYOrjVNsi3xm2ea3I3GbaWtr1EJmowMGsd93GGH53lqSmYyLjTW+QOTnWyjfgRu443bFtkrYlG6n
-i+SSKQwuK3WrVyKlcprj/Aeu3fn+ehK47dhS/VUYf2h3PH5N0nbq3bC0O/zexYULtNsHX/zI8YkuT0dZ
-hq69WZYs3W323KMoctvv1AqyrZyLNj3CC2WbP6Vr0p96bPmVdG01DRZ4heAoVy7q+hFGcovjXXRLeo9F
-GODPw1mSovdiLHGd32KEpbu+/YzJh0WXtqnUMDdqepiBJMaAckCQh0sMaYQRwfR8U1aGyGGqi2KDWcA4
-aFmUb4Ze7sw8cplKueIWzttd5nPU0DAtJuU6ZN5k+iDzSoVlljXVoMXlKnLE2xBF/mHXMBDraZKo4+Rp
-lixDgj0URe678tmST0unWzl0CrpZSrSeW0o57WwIOY06F3c5y4o2GZ0az7SK2c6KU7UiybunFyZjECvU
-4MJgBSvMswtz9q1Azy+K5SmpZgDsX7Ap5cdVeTXTOu/l+Cqnxz6hfhSU6vfhdQL+AsVzDHkCOZXVHi2p
-00hW6FToM8vNHew1oeGD4WdZUWx0mA6na9JWfNMoKaE0uolP2vruVhdFtyQaurrWRsg6wQA4C2fXx+Ik
-R2UmJfy7vVarzYuKXYRpM8XXV8l8Xvp0hkkSrXF2toow0QveYn+Vhfk138z0kuPJyZn54ce3r3FOv1wU
-yuEMpyjM6iRx+bbIhzVNgmsYSRWZn2GUYzdeRfx+aBm02l5jtm2ICxBFw7bmd08vvDz5KU1xdowIdjvw
-hB16PRKFPnYHHXrFf/RIHLIZ+r8k2ZLVvZCbbXHF+ZS2GIfJfMe2HTjF58IWw8m5tQmGftzSAMMxixv1
-pzG2SB7gJP0vxANfmAl49x6CCTjmB2ECOvXS2r4dI3xJEqvevs1Rjquk3o6cFAslZkYxAaGo6J32gVZY
-6DN3gTdMjUyO2b4Z6Id6XBx/lfE9FuohIcOPoqgtRrL1Gms1Lxq2rlR72kpqbSrOCWVMnuqVhs3WMbzG
-2bXq1m3rWHo2tN55nldps3rBm67ynN5NLhQBpnlcyJjcuEk+KnmofPy4TY+36XBHn3IUBEdrFEZoGkZh
-fv0Lva9UFhZuErGoVF0OWambywCkom+uQ6ErnpslOS31jn4R9706fNxHRMqEFlPQfAmxYCH6H0wMc7P7
-H4NzdAcc7njjtmu9ggIc4RzD0S+MXM2MROfyBZwnsoUu04mCs11NB6Y4Si69tnIDKjr6aHQbE8M334AJ
-39623XZxMeK+gBEO6u6G/5VWnZ1n/2ALLwiJz9VctPNBYdorLTyms6YT0wU/DEQv6x3SGhaEalCYlLil
-hXO5aoUzNHPq5fc3oPc6NnLaPFsp7bv4u72ULQyLxpi+/QSTPIzZUYU2NWTtNHi+mT5GbudLu8MVdJUX
-3ofwhytaodyoT2CJLU2HKdfGqwLxScEQmXDeJKvpMsxzHDyCtxjLZpiihR1U6ZF1RSDAOQojIiSepUWN
-zZkY5pR5fXRe5e8Y5V06wXRKij1GuX3W8zOXAsxx8/XR+a8xMDrEKPcmMQkDqTFnXm55ogrfrHKttHAM
-0DrQ+lyts9JfYxQHkgKQMjdkmJw2VaFTgWC2ymmvXh+dv2gbsv7ujqprlEGKMrQkxV4hXJIsO0eX9o+h
-5HW8/45xOjk940PhB30xrttX7mdbriZDbr1a+RC3Xa20EbpQXx+d774+6QJYEio52yf8FHPX9Wlnb4r7
-yQja8FfK2YtkFQWAggAQxPiSdhkuw3zBeDuTczk5BUSAJElM/00TQkLaKJrlOGOQ4rxFVr6PcUC8tiFs
-lmR+u6yo2nIaTFDmAdIxn5U4HU/6cnBNrtPrCcS9nunXbzME1mkkhDuQtBBLjW2iDJAXhr/P2S1eQqkv
-N0e6cIbMdMS2xMKiOdSQG0WKz4c2ByMDVLoRlrpNQSZEuO3jTGvetC4O1fV5e0uk0X4ZXT0yew16Qt61
-jtERixV2CA9l2OVOmcyn01fXdeaJIb6xQ3THRqFP2cEm342dOyrdph6lvledG+2r3owX4XieL0zxSCXO
-ckUlGV/fJFlKMUboPikPJOwAipG/oAfvdp0bF225nmcspZ+xf8WqNEQL8dIVWbip3ynblSpSiLgaTIOd
-viRFZ2EUTeJak3elR7po29YpsILEq8glY2uuhy9ZtesBvyL/wtvtsfXDEFds835IP5a0MnaOqNuY1KvI
-OdZ5/C07vfArJOlK5hVLRIxVGHzpnicAlRshs4R0lCt2uTqJvVVMFuEsd9vio7ESKMDvSRi77S60O4wy
-e/Kcp1vna1cKlUXcO97C2XQJdbjtxeZjeMgORWPDX87SBJcBhk/hYb7AKJBefa3DfDE+7OeLe34Akl9H
-eOTk+CrvoSicx0PwcZzjzBmrPfjFLrXomcGoQP+mPVeeg+029xc8zOmJVoA1EKOgt+FcmWdal4Lx4xsr
-k9k3gS5UtozO5rCfBwbK//w//7vyrb6ZBpnOmrPvCJZmDxEsMjwbOY9vthB5/m0yzq8Var4mxTYOcNPH
-yPltGqH4gzN+fKNBCO9ENLZQJPU97cQGL6D9V0zaMIT266RdGZ/ygaw+M23iqxqv0BfiiDBi3aDMt4E/
-c2cMSkDx3FMUWW08GwdeSLsIrVIyjGwcY8iHfcV3wj2TflKMfNgvFri+1gtuZRRIIpKieOR8W7jpcuMN
-81+tc1tz4M9+FPoftIHdWB6xwq3OmW7nYFNyRhXNa3/3emkWLlF2DbaZuoVkR0EAr/HlYZ/jUqMMwnXt
-EFWHuDedUzCNrIX0S9hxEucojHGm2GaJsnkY9/IkHULveXp1AOLLNMnzZDmEb9OrAmvr0LycgbybETok
-009o48CfTWg+cDEL5vM9F3v8pisRepMTzkYmimJ8/SBcF79Ed/iZucal1LEw+Y39CYtrvmDu0JlXdkCd
-BWYhjgKdPrc9QWJdL49FY3rB+Fojq96aLesenSStKSZIil9qN7ivP37L4pW/jW9+rU/+/XzxrR75d/XE
-b+n++C2LVz7/b2Mhq8VDv0rvW/30dyCkzRW+8dL6Kbzzm6h9b9/8lrEFyK0hMFnayvnLMAgi7FROF5VF
-oMReyfWfShJdYJRb1rYoc0OqTBcXzorY3Fpn7DKaeGu86j3sblLqPOccm4bMsQ/sKAjqRmXD8pmGo89i
-MYNq9vSZ059XVk/Iaifca7XMS3eezOcR/olgqfyenOq6y5VeUDLhcYx6qJCyY7xUwvIQJZPTCZ2cwghn
-Qd5SeG2vMA0XmwKSG5bl6w07CundboQB4YopzYypbH1V/S0fNjOvjwrO0E2g3t+LHywECuuXlyevkkvp
-rKc5GNdXVr1QI3v/+Ia2vCl2vdksvPpLkmkVXArR2bw3ndUsgPrYlEWzsGAt0dXr1XLKZvap1FTMklVs
-zAT9yJEDjzhTmJL1HVm6GalNuXjRp8SmR3KU5eTfw3yh9UfwQ0u2LCdQzkSG5+y1TYwv4QzPf7hK3fdC
-GDNZ3Hvh/vpr8KTzvqPXWjKNyQi01tknl6EToLR7HPDRiAeQkJ0pit4NLljpKg7wLIxxoEDkAlllGY5z
-RconstqBAGOaCQNoXNC+wNbS58OAl4jEBrMpHtptuJOVWGaMggKhmrL3vcc3BeYnMGCM09pAEaCIwwp2
-6vdBaiilVhFlGNg5gVlwhNCB6SpnJQsUB3TpEZyiDOU4ulY+fpWzRYUjSUePlDQXvMQVv/Xnu0fsgMdk
-jQPffFMHII9RtRA/xVmyYqK1AUhGkXFMeWp9Y/dZx7dj74UmCcZssWuemHQU3NooNpJPP5bzDMUkzMN1
-U1e/8IQyOvBwXG/NB82KDCqSm1j6SgiRSXzKDwHMF0UqqPn62Wu1JJmEOXQ0FmvLM+p1KCQ9aVfA1EYh
-XiyzGdDaeGmfjEc1HfTC2I9WASaacO7UEKyOXmEcGsZPtRmXrKB5mEeYG3P53dG0eGsP0UJ8KXZcZenP
-EYt09O4GHHrSc4bgFNYlpwtOGNBvsfrWy9GUfkf0hPUmjq6dId/KZIghDRPblUGepBUytj/3hOy7HZ9C
-J9+dgLi3KIREFPT4kqlFCVWc8pELsFcuBcpMfO9l9PsOnSzHH6vQsCfDV+2A9ByRD/AqmWsdzBH50IuS
-+S59e0WvVBoOdsVqQHCxVxsxRV6XiqhLFY2SduRW4MskQNGtkMV1IOoVp2N2sUt5ULBe76l6Fy5xK0jH
-0IDp10Khorru9QbaRQdtf//QP/L7b1mTW69O3ihdsigsonP16QJNIvyCB4oYCQARB+abderLTzKIzKtk
-DmEMeQLHvOphH32qEfER/I7WiPhZmObDdRIG7tNO9VpHJYr32xznWixJt+7xmvG4TY2d3tFe4hz8AoMx
-FDm7Itih0j/OogTlQ8jC+SIvXdLNHzma9lYheFTSMWUjmpINRRP6H67H7OLXW4XjvarW1BB5kqIUnHIa
-jnOL0jQttgCnhvOeUpovnpcVCwoFwX4SBwIJ2RUL9wNieITYlQhIjijaoMd3b4vSotKVkJAVJrWqYmN9
-EX4/7i0wCnDWU6NwxhOGpaRGKbXxKiR5ZSz8195OjekewcDczC1kLmGZJsF1b5pkAc6woZOdJdmS68LL
-vusO/Jn7YQmV8GhctQ1sDJ2rUDTbsEn3DammK5c7Vc0xMOVMb4mDcLXcWqWjq5C1b0I+2Be35XnDLiqb
-rYwXR7+UbRe0p5T62zBDaWXs78ygPzMP7/4Ze+1o49Mt+UVjGO40vh2XlKwLozEnfP37LRebk9ocxEU9
-l3K0nrGfQmE32FLVex9lYkk3rHXCogg+iiKl/t1tlNrRVRus/vVLj1nvi2XoRfEdKSDecevDV5++9NhV
-RywDF2V3HLXxVF0fe6ngS1Og1B0LHcz7yB3JYT7Q1+lRLvnSBCn3x0KR0q3vjiQRh6mCEuLDlyZA5ZCn
-xs1K7jpapmwyhiu+fPHxin7YBsyKrCNuPKFwxPe2I8lBieMAf5pdPZfc1hseM+HT9ebtdezzvnRFPAbI
-0Zx0AaVpdC0eWFm6WTo/tbY+W9/7OMVf9N/rHNVI4SJqwKej8hnuTXgCCtn76nn0bvQbVC4U/KnJyEkT
-IpZo2au74cZlPRXb+qQuWFVtW/NVtqaPJSNbQxf5FfSWW2er5GJp+hgKB0rdtYA9ST6/TrEO0zeBynV4
-X+Ft+PddanHL3mu03KXSEWPp+gqGn4By09SooblnWqkRmI4W2nWyZCuVO0Hlc/Ml8p6SvhIB+ShNnfFR
-mla8mGzAJyhHzpj+/1bg/46nzvjf8XQrYGYZoUvbGas/t6r4I4rRnJngnXHx91ZV5ZmlOL1sV42lx3HG
-/N8dqvTeHMtavTfHW1WUpp7C32mbStyANOb/blVFMxiNi7+tVSv+bVU/n20XAl3yloXAP3/ehbD/1Bn3
-95+C+7z79L99B5NTAinO4OiXzlb02x/Q6gNw97tPn/9p9+r7tPo+uIPu0/3nu1d/Rqs/A/fbwf7ulZ/T
-ys/B3f/2DuP+llb+FtzB/h1G/R2t/B24391hyP9C6/4LuM/uMOI/OSBdVsf9/T+BO7h95Da2bx2SFHEH
-5ZVaNBYeLiwoFH6nhcNvEaV1o8yglsWjlT3oCtqy5/z+w/VrpvPZZ3COs3ggljd4Y0OveAaKc1xF5e4v
-SYzzo9PJSXIZNxyyFs/GwpcA2BSZ8RzyBI5/fHvYXzzTqqQSG2+jx942yWuXfBgUrulVW9qcEVOjhwRy
-TE/HKAuj68KfeYp9tCIYkhlQXiVkhYtXwwLt0elkCAUz86Z/4C0Lpj3sp43n6qLeTzFZpXT/xsFdjBLO
-NiQzbi0l2iQxd9WJkxxUT5gZbhESmaKjaTg1Z2tLEJDtxscspFlyCfql40pYh+puITYba9Tr/YtpYL3S
-TKw7XP52pfCON8Om4XOX3zDHS9Lr4Tgob8vWUT8vQRkqC1/z1qkKPg6oE5pSjE6izXfcPKpUMXNxO39I
-GWvdaFqVCakj1XeNpAqDbBvy6AFlXPbKdorpCiJoiVWCL8o8nRoi6sI/x1e5pBzvAKMt+yuNkI8XSRTg
-bOQMnnpX9H/9q4c//W1Lzn2naWhyXxOD4+xSOI/L3/fZ6syHPNqznYP7jri6xis+9KZyqKRbKe2NNVKT
-h1/5skLz2ScRmiLCR1VmfmXC8k9NEoBPxwPIygrir19UPt9qbUvlB5v9f+y1jIJAheP5ox59joJAnnpf
-H53/kc45KVcBTU63WbTSd31y2rhDixdnBeYM/20VZjiw78xfz778rHHtmo/uMvXmxvZGlJsrLM+C3PKz
-6M7m89jjKiK76L7FIPcT0YI/qaBk1lm/B3Ut/TEeOW3Dkyrc2GljL3WlR01bgm3Fx9Ix8qqWVzVT0t3o
-MtjhNIiC4NMpPL72ncNStlf1mKy4f9/JxsXtcDU+CZWH8tt30OJMfo/+2Z1ItupejY+r6ZJ+u6dr9WBY
-cc7W0StX+J3slqaDu6VTYD0C6Fv3tp21uKXS1quPfxvtvLaAGsI9uWraZny7SC7fUMnC0vc7d/PZlkvU
-Gb9dJJfA8AFDuKUNu3EStBcCn4QtLJTmTTi791J/NaD+5I93i6cyxWNK9vSg6YktA9DDmRZvG5qqFVB6
-XaJi+dZWJHpCTvGEmM5bUx0GoNfg1GuqwiH0OtzluqkOh6jWeRWy7P631KNQBi0MPv+ec3YTYQx4HVNq
-PAGrRaCBGf0wPOsbe2BA6jgqnuBNaCrA7Yacbk2IyrBVPOamdTuuUg6fCj5jk7kdnZkrqP5tdiO1TNC2
-ER2vrOppfitfhi71SA9F3NSdIkKsVt8esbsWjQXcwKbbexrx6IBVDJpp5HYsGnAVE491dysOBmbwjcVO
-2cg4FngdX5H3ogmLyHuj1+MPhRorcRC9Vumo0lS7BNruaC/2o5DkOP5LkvHwHj+scZxrDzcPqoBvRbh4
-BkoMWE1waUHJLLEZNVBbkkuG+hVrEGds31mGebsrwgjyyyp70m6Jb7VXxAdMbaEIawPJiZeyPJacCAnG
-gwLMkowFEiT0InY1gDC2oJav9mWU3AGMLFDvKAIRBoChjTBDus8CLvC/Dlkr/MeTJyoagES8X4t4X8YX
-CGfgpv6gHNptNKLVy1+/+QZ02MlJGU6mMm+ZEUcXaI0hv0zU63/d6Ciih+uIZaB+cMslPCZdR7iGrjIc
-eHDKclUWnwABCeN5hLVmeEjxKGLmW3l+UfHOBRwOAMUBrGKmuWDgLGJOubv5AuXw8+mxiA/fMsJjlkMs
-FDxm5gtQUYjZdbw2arCvQ7Gor4+2gTTiA7e2jFDsV0PMPtoK1GyN+5MF4tX+S5TjS3R9lOfIX1BBMzm5
-W/Bfhwf/XeZzdOEUAX7DmD1WD+PUDPIrvj+hBVpI4lY16OjQskYYLE/QUAnBWsRg/RLpGISEvlsWBlsw
-WDOyO5sHukSLJ6KlSLA6r2+KaEK3pgZRmeRqT3afUJ6X1prRzltc4UF71HUt5DSZEyOqdCmtsYgrzb4+
-8ac6w32VTETm5MEZSGquRKyPB+Gi20IrVRmKK7LbXeBe91oWtvooT27nQCi+xM5OCmfPuihPIEICwQsR
-nmG4SzQoKIUjUTGK9aY7Rjq6Brz09MnTTFM6UKK4MtG0mAwepkk72Q6+K2IciUMkCwH0Znb0C4ExPFf7
-vFbtyQie8a2Px9+qq7xfU3l/m8qDmsoDselqJLGdwr0wjnF2zhLwwvv+45sCywbcxzc/onzhpcmlu9+F
-Z/vQ08jS2WjujtCD8yRnShcZbUvv5z+D24Sps+lQXJ33B7d1t5g2fvMrzZwe6WxnRrBi3K4zVR7a3LY2
-H064q8Wz0xrrapXp6IbwZDsCarW1pE+7hmuTm8NXtzeUVRUPtE9wvSkgwzPkQTaJqspkW16EWmY09oO5
-Fg6wtsm5Of0HJXbeMe2f4XxYSXBWl+uvlAVNT+tnyetXQNek8DPRyWx9lXR95izWLuPaBGqWBa5Cp9OP
-WjTAr/fEZdHbPdDCYi3RpVV1uXqQ5WVRAn4SWV+PXXyrxu3chrfq0c4tArqava+hW2GQmbLdzoa2NGFf
-ljk/QX6/2/lyu/R+u027ijNak3eswuGiR2f3yQd41y7WV8swwXJbsY2jfK5Suvv7b2R7rTq8OyyvU+kJ
-pR2DClzKT0pfV9I1xV5DFP4xV5NhXPkaFlPTzH7JFbRdvyyw1fVic0RjKLZcVBZL38NdWISCVG/NopOq
-71itajTL7qEZ/UqPTbrt9aFVVrI1YL5MD3JaSqIAk5yXAlcRNTsT2HRYUeh/aHe3uaTY8LrVnlT7KXKQ
-sLwf/PtlGAfJpUdwPolznK1R5LrVpCW8QheePX361IyQa5zZVTYuHjxWjKIwc42eHkB4aIkUzsMQH0Co
-zF2FokgHfBdeePxKpl1kZC5YsMMX3auEgJ9Kvww9Ym084DnH9ouo6O/CdHDB09LGA49FyxWZMfssRZiA
-2Zcw+3UwaNCFKcU/6ELAUIbpQMJ5bb6Sr9g6vioq7Xdhut8Ff78LwT6vtN9QSVADDeCfwR3A4SHsP+/A
-E5gWHwbf0Q9+8eFP9HcwgB64aL9cb79cb79Ub9/kiem1mU14ZORNHrAEvBbq0jIzD3E9lSmGW2H/Mai9
-zOeIfH99/pKJHkVp9pmHLjVidLPvdIXJDYV+oMNx9fjUKptessRa6Gnxp8yNhoNbrIIKTxgH+OrNjDXm
-TU468GgEvUGnwzpCKSp7wQU5yIj9+RypoUkTNAt0QobwjiGjPy66MqEP0P32nWjloiu/mv2bnAwZZq/8
-WaKBjbD5ajTj3eDfN7zfGQ5WPnZdKqO7LDx/IbnLOQcojDcL48AVA7W1T8UaRVMp6ByA5qar8hAUZnn2
-k9GCxcb2PE/7wvvG/1YZCHj55MSEn5yQLniexypMThS4oZFutehoRL1i8BeGoVzSjpZzonUpJ9RMeEvN
-JYxA/U14Jkan73SqEyKnQiwGlqgizEh+xqI/F0kj9NyURX5RI357F8RqIXNCurDMMtIVj6O7QIy4wEgl
-K4Z16kvPCD7XKIp+PH95RMQQSHmFsXEVrhi8AS2zMq1/xtz8aA9U7SxjlemEkJfJ+jhKVgGtz9Zr8alj
-qVCcxB6N4KnZ1NuXb2lTdMgqFP2csADzc1LtJl/JBQZT7FREkSF/tHZrAskWBreyA2IpZXb7n+pQiNQy
-lCjnwilV+6F6b0g5Myi/FUi5J7rVj0pIZrnrzgddmO93zDwng+K4su8ZhxVapp2cDimA9vsF9AYwhEFH
-y0wqsR1KZApKHvRsaVjMQfJvZUKIIZBBF0hpCGTgTYgQ85SLyH7xu6OuEDxniA6qd42LBIFN5hAg++zP
-Kg5BD1Fej4hRgCMqktjoiASxiIVYHFEByukwOeHg6leVvpa8BCLRSiF7OroBmuD8+wyjwM9WyykPNa4f
-4hvt08z3zJBp5Qu01cON87AeBl+Lg98SYdkn5BWeI/96TybBM3PDDc1UblvGzaZXCHpU0Ppzj2DW/D2R
-SuEpI9Qfs3ugZ3habZya6JKG/5QRu0J++pzxJS2hGmQ3bHn9uHuYWKPVMJNbEogr6m6lkEykopNIfvvS
-NLo9+WGFSpbHGO83ezswsziDgTiEGYy8NRs/vpGHAuPQY2Qqrn+sScEf34jTrMqJSH9rGRCL4jpGuN11
-r+Z8/hneeOqzXR6uNeOiOhyWEi6qXIvyxc0ucy3cB+vk1vZSq0irUBHM5tMs/V1Q01P7n9j+8PARovgr
-pBqXbFrUkTvvI2P7KHbdYrMxd5s309+xn3s4zrMQi1wb5fj8Mpso03h2IQwuKtlES3HLHt/ELOfn+PFN
-GDAfH/a7HK9McYUYX6vu9MhKlUqwbizi2MwTMvHD8pZLmczJ4xt6sNYWMpnrmUxVob6MXX0dW/wdy/dr
-juTLLF9ziNbFS0sb1y5dPpZ0zYOn6VUpx/fnXyd8yUpGqnd41VhpU38O4zvD2ZnYGPidTWMm/eF39viG
-Xf9OajfxZZbpW4IErmWlWmNFRWHDUH02hjLe1uvDtu8FQqthspNKvP6HYaZao9Ce4qRbk7s2vi6sVOOX
-ZUongy2qbvSSH2QuT/465kbalFBE7zbXR0FQsu8xpf8aZey9SjKrfXUj9w/+YqXxEQp/qLLDmxZvQoRB
-k5KHw2mflPasNIgi2WhrmmH04cDMrck3Qb2O6bJreVmUrgilnNhexNMivaUiZ6c1d5n1ymakLS+9ZvZ7
-zBQGxZ+93iIMcC/0k7gu2ZiA/O03erTRD+TpuJwiDkJSCilI55v3Dn4+PSZaSMHqqUeoMUXXhw3jiHqz
-JFv2suSyrtPi3TZa5UnpbXftyt8rv2y3JoVGpaTQeip/guNAkkKwE8twc8cUQ8Df4/goPgoUXpFYUuQb
-Kk9A5aV8Qzqxz0Qw7gv3+WnG03NWyca/34Ny+t/yQrUiebLk93Iedp+nD3VAqVFq1SY1y/ISZbGzDe23
-W6ytw8W+DYzeeei+NH6d5PBDFM5DFtN6sa9XTW01WcjC8fkiJCxVp1j5WKDgT/CY129f+SiWae7B+YJC
-RlFyySzynHRJLGP0sA/cv6SIV7oUmsQpEyn0bjZjL/kuk1UUwBRDgEmeJdcyoCmGNEt8TAhrjmDZjIzZ
-uETxCkXRNfelZNFgZ0mGK72nmGR4EdZghnuI7Q1oRncSrSVKDT9ZphHOsRFGtZGYcrYvF2GOeyRFPh5C
-muEDqM5/LeNtjOYaIlC8Vxo9Q8O30YXwppxFvvRieI/e//aYEzCL2SHiumjPyRxGKSo1DQ9V4lAGWXr1
-r30ZkYThh+dwb3hKVgdcdtBvRmr63ZRhhT7Xdstt6Jn+AMXeJx2iTVehuIqKNaRtnm1+NhMWaf4ARai5
-OAcU5ounvL+6Kl+e1AqrrUj+zZMLy0gaYnz0l3It4mZg72g2Y8ZdqQav3jI5LlkurbkHWjv/ishfwit2
-KpYtGj0w2gy9iYQupaTW8f0Uz7bG+KgJJbMf8Krca0XPNP3xI5hlRY7pTsNcPHlyoM5w1YdDpp1J9qdv
-SdcsPGFsR0GRrBm4x7IQDFoG5w0UWVT9JL0+wwGi08gigkzowa+SnkY6r5aAz5PjKEynCcoC9125mQt6
-ffnP//sfh3001kWGFsJkT9xaas6zKtOsHrJEjHxP20UVABtyUy0YwwBegEMcGILjbBR5StDMnNzuQrvz
-MMSytKlTa68ihyuC14zjwglZkPHn02P21GmO8+9RMMeudKxjlrQuGPEkOgf1yZD1ZC6HeSZ3pCIyED0l
-JtkQ/mkw2P/h28GBHiIqwrMikNJhvhhzDtbzx9CvPxGsf7qL3YmiUb4mkCcgLUwmYs3uXFWzH+YLYAnH
-R05JpV9WJ+eLsdJEqRMfc099K+855jGPda8ScJ0h0opeH53zb5WdWLYlDRNsvDL5rBpgEaffSMKjJ+CR
-3C7kC9OlcilW1qNqWXoO8wDi5DJD6qQi303apO0LaK/kpzYMlQStCPoX0NbA2m3duKMa4BSfZv1qkdxV
-aoqZpNOL9KwGCkrZoWX90sGcpWhQ0HS1bMzsCHZupXjabfWjZCQtYZ0QxbrniTKNvgDnr5jLqdeJU2q1
-hpdH41I7lQwTymxCuMtWGHwSd60w6NAdsDfo0H6/Tli3afc35bQSxcK5be1YJsM2Hg0D8+fVS+Xy065E
-ZoYIVkNtAhqWjdC76dgqs9hqHa4iE7FhxzGiSD6+UVRmzVh0Z8y5Qvc4FdYOKjMqdg5lBQ1L4TVbj29o
-BbUAysWH07GAeMu88zeH/WkNileI5D9iQtAclwAsU6Mld5Hx9JIkysPUgQDlqCd+UelRRk63vv/3H9VM
-L8a9RJuHklgs9ZoPi/JjWzF0e9v+T7OxJUefcFK2uFmLULhBSCxPe+SMK18N7jHMpqaz2Tj3yGV/F10y
-13TYEj/uRujDfpnpjFVNAVZmfNr3mx2y4Ny2ptvth1uVdA+2LkfDh1isyxjlOy3LGOWmL/KGHlX49zer
-XHeF/vgRnHfn359cOLVLmNZqWsG0/KEWcAn3J1u/xZjuvnyr3+6wnNk7a8USbKa/uvX6iQbWZeaFLzC8
-H78aYaQ1ZmlGgzdFlSZneHhQQ7ho5zumdmAig8HZhUZFZDy+YdDeCSZ+FjJvhdLAzBrGuM1Rm6McyltJ
-AH4S0UU7cr5zxgbQpnynUNZSkf/LuIzy7Y5fJLnnI7OzHquXb+CkaI6dITjr1He64HD7mzNU7tMOs905
-Q+4u3QVHf0nmDA33athYLqzcJNxbheDR/YPynNaJjQOeeqpGy0qv17wp87lfhIRbXSUyY5w84Ky6bNfe
-mmfhFQ56PMxzSHLwWHBb5W7FPI3lbeewX4Ieg+ZoowXP7ZRVjj8mGWaoTD9S2yuyatQXa6Rl431m6a1a
-0XPGyUxhmHuTk4563rDuArsHjIEFv6HLnH/q7JkPA7bqKIsx09DLTYkabI1xLY+gB2dEccds0GTqKkAt
-MIe6xTZUfVRTNyRsUJP4NEvmGSamk7mi2DUmXaCTy6h2jdnRg/7WNkHnf6zwCgeOrWgSQyoacLqgvXi1
-u5LUyqvHN2KoTULqMAplbQGt39drRZUmpZSAenyjiLtliwr+rm0W8lC/DVIc5Zl6Ac4lymK2gw1BRp/f
-OOPzBc4wszr5qyzDcR5dMyaJQ7LAAfAI15eL0F/AEl0DYtpxyJmJKRTKGmVvMTw6iuDSnb3SR4uJorqU
-bKAiPJNaKts6/O81eO8Jp3YUhcGxluatiJbKi/T7LQeovono1Dzu1sdQbUiqceklAIbaIEu9OysSK5U6
-x0v0nnBLsIYhiQTUzyzA104xfep8uCq9YnzPA/iUFG9l/0UGxBVhztj4afowSk43OKup28pbsxa0MhnG
-COrmQlhOdDKani+30xJGxixowYMK3ixpjcwmKtGgLevIGqi6dhE1Bv64pU4thxvAWui78gXYNjhWYFgL
-y7W2HOS9h3cLse9LkJu7z9UOHVNrXwgcffFvES+II2l0C5ZiwJRoVTlwFwlQ8V/eouPc/3SHud9BHrcK
-IVDQUznSbU1VLgcKDCUXu82eHgBggcjPtIfCObF25+nADeiPPavOjG6Hv+w1wstoV1vTnGa8sm4y+8EI
-XD8MMqJeznGmy/AcX8EI+v/r1+Bm0H22+dUTf/TnBxoUxwojYEiEbjgMxGti+WCQBaLKMEvv5DLUXWhf
-eVftzgGwB8kiPni0yhiLc2SFffQASjDh33lwOl7hBbSZlw0d2xDYrMvfQ2AzH6N1OEd5knm+HLl3mYU5
-pmLK1XA+UWMS73e74LDLwsssuYzYLc59L4kpbLuqF8qGC36Shtzqp9p79J7Pymbv/wcAAP//lGgqOBTp
-AAA=
+H4sIAAAAAAAC/+V923YbR5LgM/kVKbS2AawAUKRldy9valqyPZy1ZK4ke3Za5lhFoAiWDaKwVQVemo23
+PWff9mVf5vvmSzYuea+sQoEXSZ4+PhZReYnMjIyMzIiMjEjOZ2lWiJuz4nzSE9O0OEum457I4ukozhbi
+NEvPRXsw2JgkRR/L6B+DX/P2znoiq79M8tkkus7fRflveU+8iKbDeKK+/inKX6WjCOC/in6L84N5cRZP
+i2QYFfHo4J8P/ueb+H/N47yAkt/FRf4ii0eYHU1y3f7GeXKVTHO3za+zOBoNs/n5iSk3TCFzCtXzjfws
+AkgbJ7oU1taVv8vSy0ldvTEWcKr8mFsY2ZjDl5P909GLd9ez2JS4mA0LSLALBVuKhsN0Pi36eTyJh4Uz
+Rrf8JDqJJ/15wkXW4ysqczqfDosknQrowFE0jjvJ9DTtipv1NZjLfPDLJB0n0x/ffC/2BOYM3sbZRZwd
+ZfFpciWeiHYawXxsDKPJ5CQa/gaAZb0hzSFNYU3tAvI3uKipehLlMVZcVq8Nvyj3gFFw+BKzTDKMSCWt
+r/1w8iuiJ8rzZDztYEM98TBk14WBrMuxwNAORqNvkyy+BAzBaCR69/YRw2tZXMyzqUDSGCT5weg8mXa6
+4o9/FJIavPodNSzMo3KU8GKeF+n50fxkkgzfpPMizsXe3p5otaAjC6snb+Lz9CK+U2dcEHfpzy+jJI9O
+JvHh6atknEUF8A3sEVA9A5OdGqbTvBBA2udIw1BilA7n+HsA2M+u3xLRp9kBdKY1KINs4WSsJaeiowaR
+5Kr3slA6xXTdMJH+2ppqcXCaZt9Ew7NOPMEuxRPVyAj6UmTzmBpYQEYer1DzFOhEVrVxkgPfVN2T9AWF
+o/x6OhQdg5R4MMviC2jkZXwazSdFByBpXEW8nPdEPCiibBwXg4toMo+xrUlciBmwlxlC/XCQxeI6nYt8
+Ln9cRtNCFKl4fMMwFuJ1XFym2W9C9em5AEQL4PJxJtvJxWUCSSex0KODlZhMBJTBtoZxnoskF8iHoPl4
+NPigewr/nibZOSFE/u5w9/SsPdJluoLp0xop5SHVEN5hncjuqt5ua1wg9UWjUauHCCcIiIoszoE35oSa
+IrtmMCoR8X4ZJYAQmpnTuBie/fPbH153QvyI0t7EY2wM+Q0Q0wrcaep2u90TN+dxcZaOtkX76Ie37yDh
+JB1dbwtsf5AXGRB2cnrd4fF3F5IEgTMNz4BMskzScDSJs6LT/ibLUpiu0QiqbWS4gHGtyUbFqWIH0Bj0
+BmvvMB4Y20ShijzP0kvkjh2FJNhK0ukAkw5fdp3VTfOdIEN9E9M+syem88lkxxQ4i4e/vdClDJlrKtdT
+wrP9CSbGDIIG2mbMVA7QwUqTOSEc4GT8tCkMvAYTweernzY1C+tw+rn6VjzZmRPOjd+l0FwZ3dVrsl3J
+KSREmgrcJjDtp83n4sdpEY3HACSfnwChGSYhk6PpiBgEVlFZfGbBTAC1OWgv4wGf0wq2MFtevc3W57ne
+BTUeGxBC5TK7xZItEVVoPw7TGZSUBXfWzXqVHQr3dIdOHNhiBweHMgHuDGtrj28eWQeOVwazui9UbO25
+MHXWdkfJhRhO4HS31xrl/WGfcCvMz37/LBnF/QT61trnOhWVfvkFma0utLY72/9hOrkW38fjaHiNswO7
+WTRFkpXTPuLp2t2YacgbAFp+2L9lb7edrs/2XyAfyAVMFRwMMrOa4A9JA7RUNgVw/vmwwDWI6+fyLCos
+qrlM55MRcJRoOo4H4jVLYrTzUpLalnH1ym4PTIc9TEz6cHY574MIU4WrSX+YTvp9OPunFqZO5kUBZINi
+y16LP1riL0M4Bv6213p8w7wmyP473UXLmQpZm1EjTMHdDc4JYfreO2kt64oOCut3vz/LkvMou26J5+ow
+hBAfeSfqv/9dPJKLw/wcvNC0vmjtv1J8lemqesj278c3DEoWc9aHg5o8pmNR/wxE2zgDwREWxQj7vf/C
+2oQUuMHbYZrFi//iYFpnmm4rBD9XagCV4FB7o6V6GWUwJ3lxPYEpAoyCBNov0tm22Hw6uzJT2XAB4xIL
+lSriq6K1/47PqTBZ57mAtn6DpdJ6FU3n0aQlzqILmgRY67BNQM5JDCvD2u88RmAtKZ823a8PCzUvBdKJ
+npkiwpkY9SnVItoC58qCXGQKPSh1jzPYnkZI7Wm2Lf6wubn1zZebOzg+GCuIu9tiEp8WDkaKM15auxvw
+y01/E+cgUwQyjiSa3Cz4yqxBuj3dLXAu9KchG+J4g/No1iFOgGvOoRIcotP8aP/xDRUdvI7O4wU0NKrI
+PwJUwlQ9Fy3+1YKjbevbKIHl2ArVM19ru/OJ/QncQIJUBAIL9v1xl/o9K/cZIUwS6Mhs8DLOh1kyIzFq
+IDAFROd4lEQsWHUw5QAY0zkkDLGv+oO6Kwlw0d3dAIBWAx+6C7u/G06H3cE5E2PVg3R7UuATiU1T5rpa
+tc4yXtgbGH186JnD8IU5NEBiF08Va0amLYyqLZdnCn2eMFkDKNh5fzOF2QUEyCNX3uqJSTL9DVI2YJVe
+JDk0oVReeWvRk72V1QAyzhVqepBK4PTUEn34/wnlSJjVOYcv61rbKJUONS+htwF6W5anc+LimE9bgBc8
+uUL6IZwhzXkNEydphEIaluc8UhPs+CqTHw8xS+rtOl3rXGaYwYA4yvdJXgxI5Is7bTgAjWIWYsyhL57e
+AiLIkhXgcAR0QlLyhQYaKaR1pbJJH/3abYuS5rMRMFIpyAMuBtP48kU6nfKW9QNqHgid3fVVa/iKm51q
+CDC+ozhGMdsAqq4+g6JGQoCBq9/mLDzPJlWaTE1dSC2RLWiwzLlzJ1EHGq6SPkiFPYhR+FAyCNWTIkhe
+K32gXBYa/SNr+FpaE2JjA0RGPDZPEIHROEqmcH4FjqxIXmMK1gXxCFdcYcHMYb8Zy2rARuTPAdGdVQJA
+Ybar+IVEENAY3raQNWlH6Xa7g9NJVHTgTzIpgDgvsPrF4DCXvBm6/sc/AvtScuKjPUdshKYvpEyocphU
+CYE4sEEOe1+nc7HZExdbmgUSMi82mXNAA48utui3wp/ob+7oYltWsU23mFVKAtsVDUBBoSAg+QGZPCYC
+xj+fAxzYpajcolslyO2mtP3t9/t9OivB390NmSYlPUKKmSvmprZsxMUFaTDxLI3slNG9+Dt/MXNt7fMX
+zaT4u7DzcLO16nXdTsgtrbv48HG4EF/TxKNDwNgVUPrTnTtxMrlDeCxYl+VtxGPFTPUH05Fixndkg0hN
+Fsz3T4/F3p5o99uGAwADsMhAr/b3XK1H2+HLY9KhaTiDHGSRotP+O6uClHbbMNoAA3KY8hsDy4VswKUS
+UW+lnkoBLeHCuW3otP/gVbS7eCtmrxVOmdFByfODtRs8H52gLuIfZ1NwQdoT+siZ0VvtNaychCHPZ4E9
+562dK7cV5t9j4N9jj3+PJcsFwh97PBfzGBipynaxgPWtmGnXYrpjzcAlMI/l1ijP7EFBf8/jDh7fSFih
+H3bTeC1ylCUXQOqtrlgPqQ2S6WxeSGUJyUIn6VVLJKO91mx4pdiMZA8KlMDTcG0BWxofnqXJMBbW734/
+P4fZM7onurkWIHk3Bko1Wvsyb5fvvn0lnC3gLCT6pDwDYrPifYzKjo1XPl8wYnuAjKuuJwtWoK0KLzap
+VSHXKtN/fANtLlpmW+SZpc1vReTW4zbUaADNTgccZEuxk/dWj2cSFTtr/CzKf1qCeeBT4pbbFW2VYrHj
+Sqdn6SXdHF6W6pZFIn33PI6Lb/ia9+vrQ5CFpoHq34Mg2e4OWEyydurVoAAEQjgzF9Fu73zyI8c9CU8H
+WRZdD1C/3Wmy5+INf/u9XkGhlXPcxiO8vHUfnuCaHJ4MaPl5l+4VDRq4knH4lU3d4SSO1BbHXex0XcrC
++4aPQ1kKo3ciLCnONxihJ+uHz5g8LFzarlLD3ajpYiWdxiKCk50oEujKDJrNUZlcUoaoYWpBscY+yDlo
+Be7wnOu9N+6Ry73bM1I4t3tejKOahjE79+vk4zobKMgtNZJldTUw26+iRtwEKeqHD+NiNq2rj9lhpUQe
+PIDm+gR6BKs8gYMV2gy994+jPJPdXumcKlEdyLEGG8hldIcAMloDOXLk3ePbHIxlbwhptQdkTblvzBFd
+IwvEJpfK8mCpzWOHroJlvjh2SSlY6NmxO/HBQl8eG4agkO4U2DomiuADshIGrREO8BYCD5pS4SnRCWv/
+dSqvJvGChVTQA8yp0oGWkGk0qH5zO+t1YJzLbs52OozD6bkTINMsdKtSFnJlko1KCzU9m9X0yuur53Gp
+nq1AkmxXzhUw8tPrF/JQiewbZ+Q9jKzNWWZDI8WqTP0+HY+9JOhmOoGfb+aTOLcz3sbDeZYU17yv2jnQ
+mzduwqu30B6mHO9YlgSzKMmqNgVfcOVh4ZUE1JDaumEWw/bbQd04a8/Kg9Y7/ZR2MCmLIRg6JcByGhTp
+j7NZnL2ADQXafkLn70E+gVNwZ7OL2oZHj+R5n8B/m2bnVPdY7ftG2rpP65IWbT+t0M7UMsnGuoTR2dgs
+DBMbGoUxZCnc368BGKP0PxENfGIi4O49BBEw5AchApx6ZQHcjBA+JYp1b98WQHi3NeRCKIjMDCGJHEGh
+eP1AKwzt0VFSI412/kLa+ljyRWxO4togeCo1VZKHg2jVliNpvMbW6hcNrSvdnrWS1hYlg2kf0kD3yoIW
+6lgMide6W8vWsbK2Xns/GAxKbZZlTTa6aXePNQJOiqnhMYUj1AZsfBr0uEmHu/aUg2x9cBElk+gkmcD+
+/FcUnUoLK65jsZFXXQ1Za779AnlJ9V0FwtaB13NyzB0c/FWKnlXw2G5d8YQ10hV9CrYQQPrvjA2zBcDv
+g3JsA2R+DFBjfzyK8U2BOPgroauekHAun4t3qWqhR+pZ0WpWsyVO4kl6aZklm44+2ltGxHhf65ZvN223
+bSQmfp8EDKdKsvzPtOrCNPs7W3iwQwxZ44adH5lbRm/hkfocJ6YnhslI9vI2BvmmQXm7xZc+TOW6FSZo
+Mi1m+U2gXEcjx+ZppbRv8wbnO9XCtmmMVP8v47xIpnRUwaa2qZ2a1ziuuVOn+6kN/A1elcD7EG90TCtI
+jfYEemTp2m51QrQqAb80BJHJB2UA9zwpinj0SLyNY9UM26HiQRWPrPMceGsBay+XHC/QokXmxIYZM68P
+3pXpG2YeHwXnOCVmj9FP0arpmbkAGcAD4J+ngvAA4AaH0zwZKeU9GdxBcZX5w7ywco2NgtWBtY/VOuX+
+PEWTfdkBMaOnkeLwqK4KTkUEKCSDf2j+edvh9bd/PHcRZWIWZdF5bvYKaR0V2Dl62D8CyXUG/z2OZ4dH
+b3gofNCX41q+cj/acnUJsvFq5SE2Xa3YCC5UaGH19YkL4DxHztl+yaeY267PMHkj7CcAXPwrUvYZvQ6B
+kyyQ1DS+xC6Ly6Q4I9rO1FwCQUa5yFPAOfydpUAU2Gh0WsQZlZTnrXw+HMbxCNp2mA00uZxXlK+Vam7D
+3ANky33q3uoOlFkJq3hb/b4E3O+7b41Dd5JVGglpmaQuq5XGNtV3oceO6dGbJQZLaNjGM4ILZ5tusWhL
+NJer2xZwJ0vT+XbI1skpqiwavW5jkcNcPiWOM6t596JzW4vPzS9FnfZ9cNXAwjXwhLxqHacjgQvhbfFQ
+d8xsH0rmpUMtrvNrBk6jQ3Q3hKH77GCdGcnKHVUWXI9mw0F5bqxUu5nBJJ6OizOXPSLHOZ8jJ+P1jVZI
++iVoQQIYHUjoABpHwGJhDO0qizJsuZpmArkfsX9mVTqsJR/M5vlZZzbs+hdOJS6Ud6wyNSYDHheFiZ0c
+Titv30s9sllbU/vEEpBBiS85W3N1ee+CvbrgZ2TquPw2t3oYUsR25UNMDLy3LVNE1cakPbWMY5vG39Lp
+hUXIvKeIVy6Rrv2CPqcXvWPXopFuQrraKtyvnk8H82l+lpzCupGJzkrAAr+mybQDR6p2lzCzrs559q1/
+5Uqhp1RD8+bSoWxcQl2+ewmZO+5aL5eU6V6gCeYBjnnjrv9M7WzfedV2uwT1Js9+eTeEc0yctfb1Hvx8
+lVp4ZnAqqAd22oix3WbTReelXQ0yDL4dO0/7DR+9pQsSWXgT6InSltF1H9ohyP/4P/+7lFbdTA1Pp+bC
+O0Kg2d1InAF/QyvJBixvuIzHDSuZ2tDiYouW4KuPvdYvJ5No+hvaSFolpKFktB/ACBSzTmziuWj/a5y3
+0T3H67RdGp82xyy7vqmjqwoD1efyiLBH3UDiW4i/sJUGvY1mPwAyK3jHs6h//LxoOUO2niiqB4r260T7
+aaK91g21EgbSST6LpnutL43FsHydjaa0VRZ0zqNv6eAg4FhHLLUThUKL5o/DRWimlqAMGhbQcukhOD5/
+rhqi7hAb9llviVWtyBbCoCKIk1OgjdC76/6z2dWOkCknKXTifFt86b7FdoUzoWSzHIfkGhABsv7iluaB
+y1lwXxJ24gFLugrg4PAlk5ELIvzOem1XdofPzBXWra0Akd+EX9N0XK9KXZx5fQ9ok8BpEk9GNn6WvYai
+rvtjcZ7m8rtv08i8f0HLuo+T1Kp+1azwf9enASG/Ak2eCVQ+D7jbs4Dg44DbPgpwHz+HXkDbz6DXljwW
+KON76ZOBFRAZssqvFVrv46FAHbbv/Ewg8La9/FA/SPnnwN3QU4J/uig/7Vdsz3uFgJzEZhi1r+i9t/Lu
+dClfIhLZfFtX5VqkVtR72N3E6zxTTkhD1goPDLpdNaoQlI80HNcrippBPXv2zNkvPcsnZL0ToicDX4v4
+zRUcn6bRpIFJP8tcp5VPCo2KMK7UEfJV2ukASpAFCW86Azgln3e6JS0il7MebVSWthSLbiWdUV1XaRrd
+ipxaXYsMR/1KlCjrsO504/3Pee/4yYZRXeF9JFn30MVoheqyw3DL6qFQutaDNdIbnlZr4OL70hFqVVfs
+SBHkHigOyiR+jnmC7iQzgmt1YCQQkHKf3TpCrZ/buUALZHnA6qk3qXiFxQbAlbq6OKw8jO9fe9iuf4Ea
+Vy1UefLhwcI80A8b6XDy8aYBRuCWktj2SnJq1x3NgdJ2qX5jRXlX6DWDl35JLqIJukS5ljfr8ywe1age
+a4ZJKsjY0UEaIaaWj4Us5Ssr5J2QF7nq4mVG+XvRa6nmYGSw90STt3QPx9b3bb4ZG2FpdS+Iv7P4VxJv
+8Hd8NUsy/nlK3oja9lMOtBP9lqypYlbPLCNkMlLV4z1NpqMOG5uyHwX6vZSu3VJ1dF0ubfk8cSvojMp3
+yiHFXQ0pN1PgyQNdSUuHG2cpEbpZSpP9LqUTzyul8vR/RGVeDYJwV5FkcyNhW2QFNKXISzMEUyTJX0If
+VBm6h/MIfJDgw9EfTpm+BpzcxRf40p2IWTW30CnGVUrFuIlW8fFNrNx+VORZWjn0BFKmbPQIUlvT+Hta
+UpC3W8M2yuXXjfDI6JbkLD8HpbmFRtEVn415XPJyKg5z6Fs8K8jdWRu9HV/EpCqcwaIDOP2IslESgd58
+YCWitgSCWtdx0V5YnWJiWPeF/d2TbL9GNIUlHl/2S+h29IkxeWCBciGNos6s0ik2ElXvIgx5sm3liAJi
+rXLUTa4mlFhrSfVaj2B7YPs4Sty4RosbP4wad7FEj6uVUcvPIuvL3Lze1k+pNdFKsgrMrCBFg1R69p+2
+5Ebiqi3kHOAqMKCMeFAGTXpBQbX66BBwft4Ss0k0jM/SCeyTe60LWLppBhJxOmrd3supN0Rb17l8oKif
+PHy5ZKg2SDPgqoaWD3s27N//eDXrbjJsWbj50C3oZQxUNB1AxL2Pmve1JkPmkg2Hq8CWxxpqMDTjWg9p
+mfct7n/8tA83Gf4uXhypckWaTopk1hKjqIj68muv9TZGq0DcLE9QKDs/j/IdftwFshr6Yh6NIC9FH/Yo
+RVtiHgpzcntuqUMkNrjfEN9yGGV0B8bXkKyc34jhZU5273x3sF5xbVBznIgr7wzie7g08Inm9jcGH9Yr
+bgoW6w9zQ9AMZ9XXA/GD3g9UIPYeLgf0xYBDvrfQvd+srgcRTbUgi4+h5q4bdL1evrLrH0U/zyS8raQJ
+buF29+91U0YuYj6XGfNv7T+U3CZXS/TVlxDKccVrVxM0dxRrOoaX8e/hquCkvfxrO1W78AhpbOSWDVXI
+VM4+mjdw6E5WdjUe3esduXttL5gL8L+KsGwRC919Tiv0R/Y2JH2vL9kCXU1TSKHE+qTXwjTp55Kuzk98
+N4fiE1uD5HpPd32nlwbFblOnyy25oFClggTzHDLguwXSEvArEKkjqKhLA6Ma/XattgMLywFTzwv6HbgD
+R1IA4Jyv3sUcHsFiyuI8J2WIzGOyX3jfr6BUNEbFSlt0UMsezH0i2l1SlLQX3vWv6yg9JOLa25E1RUrI
+DVGk4yvdnkbuVfVqL9LxeBIDa1HvbQ6P7HU+tzO8V4MM0Y6Y5rsFU+8+OFLb4dEh7i3m3V8A+JqGG/JB
+67zqNyX5LavyXRcGoXx7OdHQ+MbUejmpnxdW3bYWHItGb3r2q8vB38wHRZeRd5Xp9+ml8g9iOTuqrqx7
+oUf2AWgQYzeaw/LpaXL1bZpZFTpYorv44PrHCBS0x6YfUZpHc+fR1ev5+QnN7FNlHH2KDtrtmcBEBi44
+8J65A7IP8sqzgT7LG3+m+jA2yIsoK/J/SYozqz+SHtZUy2oCTfSfMfkaRJ0eyHnfXM06H+QRj3hR/3nn
+559HT7ofunatczLS3hNW65TUIXCyKHaPC+JDanqkprTeOuv95jHlQufiUyC2UddTjA/nGWxzhUblE1VN
+KbTp0sgptG9wb6Ct2fPhlN9xhYGFcTO6YL8OcpkRBiVAPWUf4KhsID8Rm0Q4sMBMnEYuK8lpY0Oo+1Z1
+VYqyKQke9GhM3ULCWYRyzqLpCJdeLqXbybV2K1ISVkoUmXftgJFjSUt84V0tFj4iuZB4TQuvGSoKKDms
+ssSPU5S+KUBKdSEVTK/l8tOgh9GPOr4Vey/PLkB8T13nLzgKfuAod5P7H8u7LJrmCd4p1M3Xp51QwgNH
+JX3runOuPAxrJpQfTo/4eE/P39WbGF4/UFyhSb7ABDzxr4FTDzUcdKQpFdMbhbwHpRmw2vguPBmPKjo4
+SKbDyXwU5xZz7lYgrApfyTRx3lvqzdh7eFkkBZyM6f0om6u6j2wtz4EJXdogcP24GKaSLsVvRAv1Dq1t
+0TJmT62eaCUjTJvqNAx3hOkRyk746geyaStTAVYsSLQrC2W8p4HR/tyXvG85PA1OuboTUhWiAeYyo89L
+phKkKMNUfvUEOdYzIDOZ3s8wfYVO+mFYSzjsqyieKwD9aVPooD0G4MVmXwf3WwEYOr8Q36dja7QYork/
+gaQVwHyPWh8LBmmBagAcr1cGn1DmniaATcki3jr56+LnGPJ5aUlXL62O2qRdnnGgVVIvr7uWp7pk1W2V
+1k1f9/ublmItaq6msBNZP+e/RKl+DrPQb2Fkprn43sDVnk7i52wKtudq8P94MRvuaSmd43EAMQDDQFOn
+F1wVn7vc04h4BL9GFxEH2dq+SJNR52m3rP5C9jT4BYZvxefuVDnfdJxzGiMWGMx3MYnDCoIzFDW7Mjql
+1nKcTtKo2BZZMj4rPCNj9wOouz9PxADZJj2WgL8LBAMjud4nsRLy99fLrz4c/qmvMaA4Uhr0NfDoY2b2
+k1YF5T1FnJ898w2jNQgdL7B0DdEECutcCI7k4UuD3+nb41JXkjyfx3nlU5eGUQ8PCYqnB/DawLhXpbHw
+1/pKjdkejQS5yQqg2YOCSgb4JwNQsfOmBK+/+S2P73sLlgL7kZDq1b39sm7VMX9QD2VC0JTxq7pH8POX
+X/w1tsIwT2CstLK+2FrcAfds9xesU6pxD/7qv73CniL2mxCDtzK2VibQn8hD1cYb8tYaotOG9GIRDDu9
+akYlnvHI3j4jvtr/JBTyTGvq4mFod48tq2f0KS82Nj++/Y/VicBdFaTrG6rVRmmdg63B2qmfesx2XwJD
+N9m3xID0Q20PXyd96rHrjgQGLvNuOWrH1bY9di/jU2PA607Q2s0Wbm6JDtfBuI0PP+dTI8TvTwAjngh5
+S5TIw5TBhEz41AiosqXRFi23Gy1prpzhypRPPl7Zj9CAKSs44toTCgO+8z27GpQ8DrC9dvlcsqw37PP9
+/nrzFl2VUF960p88SJzjvCei2WyinrEEuumdn9Yan63vfJxij+R3OkfVYth4Pb8/LL+J+4fnFDNe9b58
+Hr0d/jZLAgW7ygNZMc3lEvXfLtZIXMFTcahPWsAqq+7qRdmKPno3djVdbBRyvRRz3YtITlf49tNocqmM
+GgAnPrdbyK/DfRVvk7+tUouvCVH9u0KlA2NSHazgvHMuxXH3A7kHsDFyH4pb4qR38ap2glJyvRB5R05f
+CiZ7MJvBSX42K3lhCBV+GRVRax//bVT8X+KT1j7806gwXbPg0m7t65+NKr6KptGY7vNb++Z3o6rqzGJO
+L82qnUXECfnvClX6P7xQteBno4rq3shYXzapxLdR+/y3URXr9mnf/A5WLfnnKPspaLoQcMkHFgInf9yF
+sPW0tb+x9VR0nvWe/revxOFRLmZwvj/4a7cR/rY2sfqm6Gz1nj778+rVt7D6luhs9p5uPVu9+hdY/QvR
++XJza/XKz7DyM+j6l7cY95dY+Uvo+NYtRv0VVv5KdL66xZD/hHX/JDpf3GLEf24J5XIHgPwZer985CGy
+X2PTeToc6EUToGFzg0KW76ssHGMUHzK+CSweK+9BV1DDnrP8w/o110j3Izj3CHhQ8Td4Z0MveTaR57iS
+yn14ngOqD44OX6aX05pD1tkX+9IwQdAUuf7o8Vro1dvdDShlqswUNG5DWo1KsUs/rLhAUVtdYEekRk9y
+UcR4Oo6yZHJt/DGdxMNonsciPRVIq6jIN16PJVgYybYwxMxNf8Mt6+cas9pztan34zSfz3D/jke3uZRo
+NUGZI7V4uEEzXrTumaZo/iV7QtdwZ4Ajvrsb1A2n4mwdCGLQbHx0Q5qll87Lmyt5O1QlhVS8/fmTe8F6
+ZV2xriD8rYrhFSXDuuGzy6IESDXv9+PpyN+Wg6N+5pVyVBZDy/Sn6StGnMSQ7yv3qFKGzOx2/JA8NrjR
+rJUmpApVX9WiKhllTdBjB8TokB+SkxhXUI5mp/LKlYinW4FEm/njq12FOe4A4ZZ+Oa8fN58OrvC/jauH
+P/01RedWq25oal+Tg2NyMQ9w1PddtjrXMN9yO7hz1xGX13jJB5irHPJ0K97eWME1OXzEp2WaX9wL05QR
+Cso88zNjln+u4wA8HQ/AK0uAP39W+azR2lbKD5r9f+y1DOtHhxP5vR590JJBbm8wit/TOWfGKqDDoyaL
+VhnCHx7V7tDSY6aBjF5R0EFTeGf+fPblL2rXru+ZRT3gCfm45euKwBujju8PpPuRfLCUWLbpfuBCDiCZ
+4DU6qFJw1u+A3UB/nBdTzfwsyF4e1fbSVnpUtCXJViZ6x8irSlq1rpJuh5fNFU6DESo57kvh8bnvHIG8
+9bLFZMmW/FZ3XHwPV2GTUHL03byDAcv0O/QvbETSqHsVNq6ufftyS9fywbBknG2D13b1K91blkzl769f
+F5vGNH+lPrlG94EOieCxxD5ONO1owFQWWy87VK69ew75t5Im0+XrdlpLZ+nlD8jt3lFjt7MjV2wD76QA
+FQRPEMCG9+q1k2C9WrgXkghgmptord5L+yWD/snPk81bIPNalJ5D1L0hpgJ2iEjz3qKumill1811fNTK
+irKIXYuorq4OFbBrMPbqqnAJuw6bgdfV4RLlOmg6vrwelmp7vloNnX/NlF2HGKe8DWnmvHGrBGAVc/rh
+WPvX9sApacMoWafXgSkVtiH5hih1gPyyZTjuRrocllu+DM/Z+JaDc4q3qx+f12LLLdp2Io756qd6ZwB+
+aa9HdnjXuu6YqJtW/XAU5EowgeIONPsOqhaOXbAMwbquWQ7FKlyGxPHDlsKgYg7dBO5OawknUN6Gx6aG
+3+LprAYKl3Lq8eOl2kpcxK7lHZ/qantFnbbNYae2fVOs3bUcGkyAccZTGDI7ffoGHy9Y71p3ygXfygDe
+VDR3ylpszwoTFYiWZxUthWxEb0sI+ntqMM5o1wLxqN2Tgd1Y/KYX/wGPR+vGs/Us5D67MrSXfEjMfoCl
+d0/2mYAeADrS3e/oahPvM8r1lVMDFbd0E9oql3qPAKSXBAKLriYgbYv8UfCvXWqFP5480c4SFOCtSsBb
+yv0COkaAHgQ8U0P1gGdquyw7pLbLHb40DhssL/NnEQZ3vkwDLtpz5aPdBqxCp7NfGa9J9Chj+WwfiKNJ
+HOWxSRKRyKGRieMikII8w8rBC2l1+tERqGU5YE7oeX8+JV0MFWfv9V53i7OowLfaMmL3muMy3vdAYWgs
+rnPVvtTztKQ56Xw6dqguFCFeB28gBUZlnNihXYpCCDxqUtKJCLvWMCbtsByv4FGjom5rbIE3kk4TvoP8
+y+j6oCii4RkyscOXtwv32uJgE+fFODpumbgSyZR8BcAfN7aETH+CGVYQ2jXPydOd+nIxm96lK2Uq2g6w
+Ay5b7b5tu4ZwuS45lL4dgABZE7Su7Wnd9Q3Ng1sw9/YDlpqIpctDlt5/NHS59zaOgs4rVkZBD4VOdeOg
+E90g+zQPkr24qTYfWhhPWLnchztuoFRMkrEDFt3SPuuewe9xr/X4lNPO27i0fsMxyq3wMPk4dwLBnFDU
+lxMvFAylPoFka4V8lkQEw3lwAlJ6Uumm5kGoaJlXsDJB8bUJjFX6EDVNVzsoA9KSalYVZMGYFlc5KBPS
+m5V4Lj2LbK/iyEx4nnR05BO7aYX+pXBRrhiQagrxgEjptFmBqw4X7GHMklk2vzLuuaR4QN6rfjg9+Gsu
+9sUzfQazqj3ZE1/wsYRdx1VV3qqovNWk8mZF5U15ILJQEpKvBgnsC9k72C3QMdfG4xsDhSJKvIqKs8Es
+vexs9cQXW6JvoaW7sIxrIeddWpA6TTmKs/v5X0WnDhKsJoTV/bCzrLtm2lim92bOdtK3MiEEITbrTJmG
+FsvW5sMxd714VlpjPasyjm5bPGmGQKu2ZhENWvY8DarN4bPbG3wl1APtE6wRBznKtkN6kE2irAxrSoui
+khid/WBsebKsbHLsTr8vU2XnpLaawWlkhpHGQMRE0TGfyx8kQ4JcyKGpXFNXxJnpA8zhc+BNCmyP/Wi2
+SoVa4iSepJdKwJRxtWRP0EubKQ1SlJvVDoBrq2ne2BCsvZnEo/IsVi5jay1pwL2KBa5jD2Gi5cjy8z1x
+BTSyD7SwqCVcWmUDvwdZXgH17r3w+mroMq3scrYJbVWDHQcY9NrLOIfTLCkq8Sakrj5aAru8PUyGBED2
+/cdDyU0+KXHK0bCy/6HoUim4UDSoocmVpl27yNVO2KcOVksULnukojwxVRbx6JF4G2uuygd71Obl7Iyd
+Y5bnilXesovV1dA6SG0roXH45yp9K3P3jWx9rQruCsvrSNndWccgA0tb5dnrShlChWvIzN/nanKuzT6H
+xVQ3s59yBTXrV6Bseb2EzB4JRMNFFbjDfTiBRSqX7dYCOqnqjlWqlbPsDqrcz/TYZN+qP7TKSrUmyHLu
+QU5LaNuZF5wrWEVUbyYS0mGhnxJUYS0XUkJwO+WelPs5SaMRTgNMh6Tly2Q6AnEBVt4hRiwEyunYUWmc
+Cj3xxdOnT13nzs6ZXcf4Zb/HchTmCnIPbx93A07u2YM2ZOqrSKMosgu+T44HLJJZgkxXSL/N4fKme6Xo
+BSfK4sZ2tjzd5EjGW8ah//tktnmMzuMxd0COnjkWOlB315TZUmW2qspEAPsE4cP/IwIJkFU5YKO0kq9o
+HV+ZSltQCf4fwv+jLa60VVNJYiPaRH3VptjdFVvPukDzJyZh8ytMGJqEP+P3aFP0RSfa8utt+fW2vHpb
+Lk2cXB9OMSSI8ols4RfO3DB0+DeAXcwbODWrsYwQlpb9x8A2XjvmX1+/+45Yj8Y0JbOjXMe9PKXjClMb
+CiZQMGvbtbqO0Y1h5IzXdPlTxfyNR0tuVDUcFZ4YGxvgXT9FJu5S6DnCqOoFM3Khgk1Aqh6aMg8gtzrA
+x98TMPw47gmVh/vte9nKcU+luv07fLlNkAd+sgIjFvI+3sIZd4PTF9zvLB7Nh3Gngzy6R5ElulZ4Zzdc
+BpbhCOByoKH2ka0hmFJGd0dYRuE6hIYxmaBPwgW5dR8MBlYK941/6+AZnA/ocsrDd0/AF1WAD1Xc0Uiv
+reFoZD0z+GPHiEHhDvMZaT2khIoJX9NzCWD1bxmoubXR6pYnRE2FXAwUYyXJ8uIN+Ro38U7cmFy0lfmh
+B3pCrpZ8nMO/cOKCfy9m07wnH+RDjuOLOlInHCw2VLYrPOPRZPLq3XcHuRxI7q8zGp0xluEGujt2/Z+O
+XmN17IGqLcNIYYwmvy4q8jD9MP8uvXgxSecjzKPVbJLcBl69ITtRHKjuXpZR73DeawEFKpgDHyzrp25T
+b797i00hZnWwhnFOIRjGeRkPJsSZ4QCGu5U4nsPmrHYrvCObez3fgtU9fnfaf6gCIYMvIVLeSatm60P3
+3mGmbtiKYCFt39opJ2peDIfbzhg2s/FW140EtGlORVsD50yEedYBbRcLWN/PgQuLbbFJ61IFIJHQdhUw
+XUqdJ0OBitxBcpqPCDmEHIaQe0PIYevP5W6CVJRvme+ullQ4qo5d1O4acx4JTUXZAED4swxD4kPmVwMi
+DDAgE+bJBiSRlQeQxYBMUcYDkDIV119l/AYid8hQRIbFde17bjjAf53F0WiYzc9P2H++LSvUXoOT+aHD
+On05PWjkyDS8NE6ipukXJL8Nqs19FKFbBjw/nPyKmrcoz5PxtHODhmvbxNhhN4EiXe68FV7Cii+hAiIe
+5t/H42h4va6i3LqRYLe9AK7N/NGjsISHIqv7d3ASz+/0dFR4FflBYsyxx1u0Kry2OlZ2jk8YlfQx/bYG
+XKCoboSi97IRoWQTZfetDRHEKsmlGFLRjmwUqbRPjaPlIY5LWAo8KPqwWF+BmOVpU8jjZl6KQN2EjB/f
+qIOPc7xz4l5WP4LG4o9v5LldB0PGbyvyscmuIoTlBp4VkshHeDttz7Y/3GCwZX0M9mIt+6GW11eZazcI
+6h2mGs+olQFOqycaSnOkUWua4dOaZZ1pT7Kzg/iGsr6QyRA+zcy64wvOqxXsNTitdryaUhxS9+mm+26w
+EQHIbffORGDFqylt5HfupDojiPvvbfVBpbrXdZ5XfqST1UcIz92z7bg8O3DM6qoz6yPn1GPOq+aM5B6S
+5BkL+GOWxDL0kh+uJedTWYeuJEDcGh13S4vedWP5+GZKIeoxNPSIjPDo23dfqalejm+tSu6iXK2zrxqL
+FDg52B+LmQ0ZE5R+fIMiqcWY4NNiTDqzkjEFDJJ93sRAPg1vcocY5E2YW7vl4DKqjhH+adeJDDcuCana
+It0ipUW1+MAHmjdv5HmGtR0WMdl+QDLYrUlhVnn2hGz7JKMKV5JS5W1iSaNKoD4aQTmuVuxhh48wUu3o
+kpOipt8PMVXe2q5rSqoSlF80CfNdruZcqVfvWY2gV1d3WqmLel7/6rRUj+HiLDtEXS3yqyjXLPXfqCvr
+aII6jeuD0cgzH6A7RQxkik8V09PKB5dq9+PHirXvD/mN4grPGWGLlfYSiB8uZyVp5bw3CBOGe+0EMn7b
+caNO8xZu13FfBAQelc7mOWJObo7yfZ/dkolmHQzEGdSTPHd4nesGY9inm3Zhfvb7Z8ko7idDyxtKuNIv
+v+ABzZaCZ/t+8FT0Fuz6x8X55t7h88nc8o9bPrPJWxLZ9e2acUz66N8GXa1UdVo6/IjmReo5BankW+u+
+SxTmYPyhORj5X7Jik8TWpXsOk6RQIcmJwrXdMl6e4KeSw2gK5KTgypDLMniePwElFys1sTE/EsLY1Pbj
+44wDV5fRxul3wJz9W2kx5nmRnrMyjGPIcGDtltC6y0pdZcWyvIyyaasJ7pst1rXds61QMRThcFfdfw3L
+9ptJMk4oQMPZll11FqpJ/nf336HzawxiLVd+LEHw62t6VLChTaB9nA/EuzMsOZmkl2Tww6iDmZYO5yiB
+zdeM8+1zeYNwQiwFJcxTesR9mc4n6JdcoCFNll4r79wxmvEP4zyn5tCtHTejHBADvDl05ppNtcm1OXQ+
+LvUeISlfWdRgFvcj2huiU9xJrJYQG8P0fIaPAxyf4LXIVLN9eZYUcT+fRcN4G69hd0R5/isJb+E0V+O6
+6INWoztq9YXNhBfGg1HQK8U6S6/OTa1xJdFR1mN0j0NWSxsbgrxCSW9m1rPWFqEU2atjKQ9yHLL6QbVH
+CMKmvIBut+vd0FQW9h8K1QN17f/8svLCJyTM1/TMfggX7pNdoo3LVUrccrFZu2ybD3HSMoYfwkklNJOK
+ud98yv217/rUkc5Yj1DfpQXJofLVJMeHX9rEkc1RBgenp2Rkou7JysI0w1L5yqpkx2rnn6L82+SKDv+q
+RacHTpsJJMrSXWmTJvZxbA68H+Es2xTiozqQdMHIVdl6Dnm9uiWBobp5Qofb6dbMxZMnO/qwV37A6F5E
+q/5slG9ulUVe6Mx4JIUqfjkhOciRcU21ECZ2+DCdXb+JRxFOI/mcOsQTYikomzKi9wq/S19MktlJGmWj
+znu/mWOU0v7j//47Rvq2eYvlJGtdCmcVB18dX912iiVHvm5tt7oADbmuFr5kFc9FC9jNNmzbC40erzSZ
+tbR7ot19GGQF2rSxtV5i2CUO7XoKY0QaNEpmjKLg19FoHLssuiccj0XdnUrJwwngtltkausyvufwOJlm
+2+IPm5tb33y5uWM7IZzEp8ZVHwZTYwq2o6ZhKoj2dtJtboURjLZ5wwd76v7XBWwZppQvwXYxqElSTFQ4
+MXPh5l/2AEhzGaB2aDKTf6sEIvc8SN0rhRkhQFbW64N3nFbaslVbHxbWeFXIdT1AE53GCT1nh51T1C75
+C6mMmYv56mIrNt1uMYKOXGaRPtKo99shbvtctOcqqQ2rrFPF6KGgVazdtq9edQOM8ZNso5yldpWKbOJ0
+dpYdy0eX0oYqqr53gqfARLo0rpaFGxMoTK0Ip93WH54Jgwf1MNek+y7VhgvApf41Zj71Om15rVbQ8t6+
+104prpK+1MzZdDQZ3YvZaDLq4g4Iezv2G/qL3cbuL/xgSmbhLFs7gckIjceCQO8K7Fy1/CzZyY2LRDX0
+JmBBWUj1og2tNIvQnfnEBexcVzm+k2F3UFimZgJKNrK+si3f5aUO8ozSdY62UUg8p9LQElbQC8DP3j3Z
+lyVYFwhDPakA8X2UF69AzgFa8AoEpsYKaaY8tqbppEhmLQHn4Kgvv5B7+MBx6/t//16Ob+YIMNY8eGzR
+6zUPC+mxrQm63bT/gK9AZFr5WCLw3EM6gB8leeCJoZpxbczFLxdoarqLxS097976hoBVIqFwx6shenfD
+JzpnVWOBueuV/cNihdhvy9Z0u/1wqxL34OBydN4yyHU5jYqVliWUd99ELPCowuk/zAv7SQZ0ofX+3dcv
+j1uVSxhr1a1gzH+oBezBvrf1a8Z0++VbTrvFciZ/D5okaKY/u/V6TwPr0T3EJxjeq8+GGVmNBZqxyrus
+yuIz7IDaYS7W+Y7UDsQyqFyYaZRYBvBBLD14GefDLCGjDG9gbg1n3O6o3VFuK6lkBNvRBBftXusrvFG1
+Ci18mUJfCsuol44wytsdC5JsGk3XyS/0C1zRmgGfaMGpEITOVk+0+KIOEtQDjhZd8kECPdiAb/tFKyQ7
+3mQXAYGVb77780QMcP9AmrM6AdQ90E9mMc97RTs4obc/kMiXywqYM052aa6F7UqpGboYYzR0DCSAnssH
+5D5dG0PSUwQl7exueKX3hWVPZLln7/oqx1dpFhMo19A89Jq17H0q6MvfuW723syanhMlk8KwQMsG/czq
+Au2OiLbJCRcuc05C3bH9QKlRR8nXVU0vFx42aI2xlkfigwlRypg1mkxbBWg5CNJSbE3VRxV1k5wGdTg9
+ytJxhjcFDgIVxq7x7RdOLmENvnDfx29rE2z9j3k8x1iogaxDfL7FDcCasl7ehy1mKvnV4xs51DomBfxJ
+1ZalbXm9klVZXEozqMc3GrkNW9Tlb9um4Ye2NIgw/JkCARYv5WgHA34lY64s8A4sxusi+B9f08HuOrkm
+Ipkm+RlqneiB0eVZMjwT59G1iEg7jtdF6FdPKmv0xYxjuGLCF3TXvcTAFUV5KYWKSjdxeqk0fRG0XmOk
+KF+9RJNk9MIKbmq8HHOWLd9ygfKjqW6Fkwl7DOWGlBoXhQBUGulBer17Y8IJep3jHLsnfGVsQQDGx6V+
+IkeDK/kWqzJVK/WK6J4diXmKN99MkwqxIgwVkdana6qpKN2hrLpua6PUyqKlyXBGUDUX8ubERqNrIrMc
+l9CeXd1yYmZo09MauU2U4g0E1lEwFELlIqp1QLSkTiWFO4UtF5y+ABwaHGU4t4V+rYaDvPPwliD7rgi5
+uf1crdAxvfYlw7EXfwO/ZQyk1vpZsQGXo5X5wG04QMlMu0HH2cx2hblfgR+vGSZg8Kkt7hpjlfmAgeDZ
+4i3WbUckZ1H+E/ZQWjFW7jzQDWGbMpStHjtd9jCwxLoTT2Lmjrz63eSOZztRXbLT9cs6b2n46Gcejzpu
+uCzR27fIsLxR1F1L4ktQdKeX66e/vChARIsxgsfGv/08utnsfbH4eSB/bIx3rFIMFX2sIBCpu4afDE69
+eCaHfVlMQRc7BLon2leDKzjWC3LcIGNcTOYZLUEGZu5vd4RXJvkbO/HkCkCKZC6EY0NfQtvmG35id6fR
+RTKOCmCeQzXywWWWFDGy0Y4F84kek/RzAFIpCTNASJcTkjI7HxQy5d2z7oW+YwYZe5bwraRu79EHnpXF
++v8HX9nmqf4ZAQA=
 `,
 	},
 
 	"/static/view/vpcreqs.js": {
 		name:    "vpcreqs.js",
 		local:   "esc/static/view/vpcreqs.js",
-		size:    12758,
-		modtime: 1686846316,
+		size:    12810,
+		modtime: 1792369683,
 		compressed: `
-H4sIAAAAAAAC/9Q673PbNrLf/Vfg8XkqciyRSftNlpznWmmrTJvoxUnuZjK5BCJXFGISYABQss7W/34D
-8DcJyrKb3tz5i21gd7G/d7EgiRPGJbpbyzgaIg40AD5ElMk1oeEerTiL0cB1vYjIkYIp/3C/isH5SYH+
-GxZ/sABHQ/QHvgFxmco1UEl8LCG4fHX597fwLQUhxRD9hsVr2H5YXP3CeFz8exkERBJGcXSdLilIkW3O
-iEgivBPvsLgRJTNeTG4JFU0GfuaAA5+n8bKC81mcMApUCk+sMYfAW5ZQCrtE/pWzbXQIL1QAzQPRewEc
-VUipAJ5BnMCtBlml1FdCoQ+Lq1z+BQ7BJnTFHHR3ghBCck2E+zliIaHv3/6OpkhtutfAN8AXHFbkFp2h
-AcOpXHs+jqIl9m8G5zXUJRag1HMAW2Jx4w00zpvlV/Cli4UgIbUVBW2Bv9p0Tp3jBIdwgNtN4nP4pvWo
-cCKQiOccoCmiaRSdlxsRwwGh4dtqf4UjATlqoVsc1ACw2FG/NI1d2EH9kBWyWxQdxEGmnJ4j5HnoDY12
-iFFAWCKMJIkBJRFgASWJLkOSp3Be7vuMColSHj0gvftVMDqo8DItiIRRUaMm+a7GvvopYJSgW0xkroMV
-SH/96vrNazvlkVMR2CMfS3+NbODcaZHSMeEC54zbg5fqFxIgJaGhOoWl3Aeko2OMBugMKQrnLV605qrD
-GoouOHUl3Eo0nZZGbvPheeg1Q/4a0xCQZEjiZQRuA6TXDY5jp+ZeDa7OT44+IFNzlj0LqEpEZUwnd8pC
-fm39HHQ+awu9YhzZmbNw+IZYXWGKmluycn+PPn5y2viVlr+58xmaTqfo7PCJTUnEmm1zUEVjqN24Zd/i
-R+XUm+7W/sT8X80NGgw1gntfj+AAhM/JEnLQa4llqmxgi/yvi5osmdIylESFuIL82ODFuk6XMZESAmvY
-3LjC1Ico6m68BZU3u+uXScLZprs+p2jBWchBiPbWjFGorX06b7hFLtKkIYEbAQ3l2ukEu/LoBuTHDP+T
-2c01uPWe3lC2pVauacekap21lYqV9/XqdwmlLSpT2A0eP3b8wvr/FNKOxgqtJWat5WbzfRBilUam3V8w
-6RruU6EQFSel5CVIPSibgikFKPltVTyHiDpoetGgrFqgLxORYIr8CAsxtSRjkSSJEmFDBGGU0HCk0Lsr
-o9O7pgb1MW72t+NySCLsg+0hLxyiwWjg7C0UYIlH+RlT6/ROY8wq2+/H6CDVvYX+z4+If6OQbSVPLdaV
-uBn0fObsrYvTO7qfeEq6iy+VttrKWhEuZK6ppmvW+BqjwRUHLEG1QYOmeeazscpvrqIxnzX3Mrar/ez/
-CmZ/3vZsbZIGjUohWsCS3yF67uxbkDpXziBROZzKLACy5OrGOLFtOUQkuNV60we1aGfbZz86e/SlRvuL
-IciaZUIVk6IbUTauR3mtMqmtVtbvlp8wz3c5sP633XxsCGxVX7Imotf7XxGO50KkYEwB9eLyGxav5m8v
-dX8gTDWlbpsJLlzw9A6aDqhO1ER0K6pIO3u05rCaWv9rFREWiJE/igi9sS7eguQ7QkPXdScevmiafY8g
-ElDyqBjU0hzBn8Q8BDm1Pi8jTG+snIW1lIkYe95XwjFQ6fqxcEO28ZacbQV4p3eNY/YmhtswBq5NyT2L
-nUzQQeX7Tp/lcg95t0u07aT+3TbeETVSp9TXsFVBa8q2VdeP8ra/nXnPO/6imTmqsvVVN0WhRdiotVaN
-M6uM19uJopCjKfqpHTGyzD5ZjclgakGqwtnuVofGykT3rYVjCIlpgHkw0qsWEnIXwdRaMh4AH0mWjNEz
-66Kjk4lcAw5M6/zC2JtN5PpihiVMPBn0Q6jgQ9otJ55c98Nd+j5LqTwM9GFxhV7j+AFSWXJ6CEYbCPhR
-YEj5/GHIzIiHYcy7E8+kYQXbY48lC3bddZ0EdAbXNSXPrF1necCmwcXpnU1hi5RpdYq7DAIILqXjuJKp
-xWvJCQ1tZ3/I8IpMJ+Nnqfcw2gTnefH0rnOJ3ePMSUSeFXOfmc/2RQ6sVlQO7D8pg14UvRMEHxZXRkD1
-8yLP4E/mztskvmc6Mq+je/NmTa7CXYMrRlckdD8srlQcVFJ+6eV+nBUqA4X5rBfpe4vdPv3yb9dN0Y3s
-fQ/xMxWUkjxET9FqtW9Npz4szUMhUcfm+khkt1bfz2d759jQqpXkupXV/w/HZ6/OqrZZ3+7V1flAcBy4
-SGue8jvCAQc13A91qjCiPJQ/lqmUjDa6pHyp9vdolHASY75rLooYR5GFVCswtbLF7s1GNbmdKYa62Fyv
-2RblaxMvQ+/JQeacb/K8iWfI9xNP1/aLL82+qGjVszJwxajEhAKvX0k7A5CaHPXbgh7MEPGGLnAIvzMc
-1NuoDBP7kmygwuXwrX1rUMSzpvtO20N5/BgNyjvrKMEUosHeaQ4q/qfvYN3+ECEZ37lJKtbKtcAecPg2
-GqAzlE2khihgfhqrXloSGcGwNRs+QwOvgnZM44wMgdGriAnQAqiG1+l2u2ZmxOABHhzzDOWYXk+bPyCb
-stsDbbCRahaAW7pFKmwyA4lJJCZeQDamRkJ3jSSYWpvEz3FyFKunmeyLO3NL8mC7YWq15rP+JqqbTHWl
-78sH/ZH2FM4O9YpPy/KotfgyxiT66+VZcPYVfPkYaXKUqkj+lfw9eBXo8lc1Haix8O9h+Jg7TqvH/T6z
-AOPNH43Q6d2RRfnJujGWpXppekTSGgnwmcoyu6IqZ2lsgTmOQQLvzWCKpMpfeckrK551YcAwFstYJff2
-+0mrk0LTKXreLkManVAis2fKziul3eG2rAghyJcRqD9/3s0DXTEa3A+c7lCkMYXrbpt7uawpzcacvT3r
-0IhaBlQPdrFtxtbduxlTb5mxMu0pjZtRq/1D+Nfknwfx1b4Z/1fO0iRrUUzo5fawR9n5Y9O46EO6z1bN
-JeekNVQ0TdI2ia/OzPqrvotLNdX/h+2evXDskR2yzch5YQMW8n4LQjrOi5EtMA2W7PY+gM29BCHvSZxE
-9wlnwT1lVP/+hu/jMJbOqTdEg9Png9bDXN3t88f6/3JPv5bYv+lxGLVlxlJaz+6/48JEPYBpvOBkgyXk
-ycF8VAesn1q6jIj/PYgVML0BUwPooZDx0k+g3Dfjz8UMVjiN5AyC7KMQM50unJneFablDGV87MVUpffq
-sRX98IP++sYl4jKICbWdngQZBKUbi4PWMEH20vyFcNjiKOolVQA8Jrt0bn0pVSFcv+/5BYf1OrclNGBb
-148A8zmVwDc4srtfvhRb5WWqftRRB1UfWLkCpP3xjuosbNUuM8Laf3LOn3pZUk3CEvs3IWcpDYztQQNW
-twWHwaoBQzQqhUKBGKWjBAcBoeFoNxo9a67clisx5iGhGqTvVtXqbkQ/P3q51d9Uim7fyitFoGl/rq6g
-Bh0KcX4l7kXWAHU8z0Mvb5OI+ERGO7QmASC5BgGIUORjAWgLCHNAHEaZaQkN0RJ8nApAbIUo3pAQKw9y
-e6VxyxkDmqLBmgQB1L9zqrF+FGRnjnJI4AK4q6ty2LHAFA4qrT0Wqbel3aizOyeZYxJNizgWIMsorj/S
-N6kO0U/Pnj0zjYpK/rqfuvk6P9VD+rt9VXbgUzY9xjEMo9z5TE95SoYHQ3QXg1yzYIwGizfX7wZDpK4v
-Y6SOcIV+0SCrXSHH/k98x5Z9/6PcV2Jxc/wHbC1j+uXoye4b2zXftbtGqaY6Tucjm/7PBK38M0HPQmeo
-IpHp1NJX0+xF3voP+4JQk1F619dxvSWerP5qcum0a072llArATql1O6e/dfpNtI7ImsvtBJu5QhHJKRj
-FMFKWuV75xid3tVM0f98YSwPvcf/zIKd9dC44sDT8mHUQ4/KTxq19Dw+rx+H9RYkJyAej/gHCIHDR5x4
-eLL0WBU9NGRtP2/Wv+iMcWKXgdv/LPwdrBLojiXAEqzGQ3J5uouDAILPOHtP/p35OCpelAdAR++vVa5G
-a5by5z+Os09h0f6hOZV5MFidyUHy3WcsJcSJFH+SWJz5wSOoHOcIfS9AR4/ejhrDNXvGk/6tL8PWiCzL
-k/uTfwUAAP//gzYSi9YxAAA=
+H4sIAAAAAAAC/9Uaa3PbuPG7fwXKek7kRKSSu5l+8Cv1RcmdMneJGydtZzLpGSIhiTEFKgQoW7X137sL
+8E2Qkn2+TqsvkoDdxWJ3sS8gXK7iRJK7hVxGQ5IwHrBkSHgsFyGfb8ksiZdk4HmjKJQuwhQ/vK9icHwQ
+Zug/U/FrHFAg8Su9ZuI8lQvGZehTyYLzt+f//MC+pUxIMSQA+Y7d/P3i1Zs4WeZ/z4MglGHMaXSZTjmT
+Qk+OQ7GK6EZ8pOJaFMyMluFtyEWdgR8TRgM/SZfTEs6PYZIDH2IkFjRhwWhaQCF2gfxTEt9EfXhzBKgv
+SD4JlpASKYW/GuKA3SqQWcp93BSBzWb7v6BzZod8Fjvk7oDAB8QsvN+ieB7yTx9+IacEJ71LlqxZcpGw
+WXhLnpFBTEGeI59G0ZT617BEiTqlgqF4erAlTI8GCuf99CvzpUeFCOfcRgpKA3+06pwqxyuQQQ+365Wf
+sG9KjogTMQlWqTkAFJ5G0XExEcU0ADP9UM7PaCRYhprLlgYVACo23C9UY+d6wE84I3aDogNryzThx4SM
+RuQ9jzYEDINQSSiR4ZKRVcRAAQWJNkMySdlxMe/HXEiSJtGO3YMhxXxQ4mkpCLBKUaEmk02FffzkMLjR
+GxrKTAYzJv3F28v372xY2ikJbAlo2V8QmyWJ0yClzoQHE3FiD17jFxFMStgdrhKnic+IOh1HZACcI4Xj
+Bi9KcuViNUHnnHqS3UpyeloouckHyP1dTPwF5XNGZEwknUbMq4F0msF+7FTMq8bV8cHeC2gxa++ZQ5Vb
+RGU6mVHm+1faz0An4+amZyBtWxsLcEfiqsCQmlewcn9PPn9xmvillL95kzGI95Q861+xvhOxiG8yUKQx
+VGbc0G/+QZ963Z7aHpj/VcygxlDtcG+rJzhgwk/CKctALyWVKerAFtmvs8petNA0ygqPOEJ+rvFigaNa
+hhLcmzWsT7yi3GdR1J74wNBvtsfPV6skXrfHJ5xcJPEcdCaaU2NwIJWxL8c1s8i2dFLbgRcxPpcLp3XY
+0aJrkJ81/hezmStw6xO/5vENtzJJOyZRK6+NIkbr65TvlBW6KFVh13j83LIL628pS1sSy6W2MkstU5vv
+w9QsjUyzb2jYVtyXXCB4ToqdFyDVQ1nfGAoA929j8IR8yIGt1ShjCnR1IlaUEz+CeHpqyTiOZLjCLaxD
+AdoAj+EienvEPbyrS1At4+nfjpcwCJ0+s0dkNB+SgTtwthYJqKRutsapdXinMMal7rdHpJcqkPirH4X+
+NSLbuJ/KWcftamjwDFvr7PCOb09GuLuzq1JaTWHNwkTITFJ106zwBeHhFXgIyTANGtTVMxkfoX/zkMZk
+XJ/TbJfz+n8Jsz1uWrZSSY1GKRC1wYLfIXnhbBuQyleO2Qp9OJf6AGjn6i3pCgxhSMLgVslNLdSgraef
+fe9syVWF9pXhkNXDBAaTPBtBHVdPeSUy4VTD67fDzzzzdxmw+ttMPtYhu8G8BFA6rf9tmNCJECkzuoBq
+cIEc8O3kw7nKD4QpplR1c0JzEzy8Y3UDxBUVEZWKImkQ5ALSolPrz1Z+wgLh+m4U8mvr7AOD5AeOk+d5
+JyN6Vlf7ljCIzgWPyKDazR78SZrMmTy1fptGFNbJWFhIuRJHo9FX4BKMw/OXwpvHaygn4hvBRod3tWW2
+JoabMAauTc5dnx290UFp+06X5jIL+bhZKd1J9d1U3h4xUrlUyO7x0Jq8bZn1kyztb3re45a9KGb2imxd
+0Q0pNAgbpdaIcWaRJdV0Ig/kIIgfmidGFt5HxxgNUzmkeJztdnSojZyovDU3DIhKPKBJ4KpRC8LnJmKn
+1jROgBR4+dUReW6dtWRyAqUZDUzjyZkxNwOEszH43pORDLoh8PARZZYAt+iGO/f9OOWyHwgMhryjyx2k
+tHPaBaMUxJK9wAjafD+kVmI/jHkWRhPjaJc+pnGwaY8rJ6A8uIopmWdtG8sOnQbgTWwOhxNVq1wcHEco
+1qXjeDLGwUuZgM+wnW2f4pFMy+Nr19uPBn5S+8XDu1YRu6XaSETmFTObmYy3uQ8sR9AHdq+koS/y3IkF
+YFlGQPy8zDz4o7kbQeU9Mi2ZxdGtebKyr9xcg1cxn4VzD6bxHJS7vOrk/kgHKgOFybgT6am33Vz9/B+X
+9a0b2XuK7WsRFDvZRQ9pNdK3ulH372bXkahiJ2pJYjdGP8G+nX2PViUkV7WM/3efz06ZlWmzqu6xdO45
+HD2FtOIpqxF6DNRQHypXYUTZ5T+mqZQxr2VJ2VDlt+uuknBJk019UCxpFFkEUwEImGqwXdlgktvqYmBh
+cwlDJBs7GWn0Dh9k9vkmywPItr+HQYztZ1f1vChP1XUYAOOUNOQsqZakrQZIZR/VakE1ZkLxnmNX+ZeY
+BtU0SmNSgFyzEhdQmlUDEtdJ953SB1o81GxFzepCEciiwdapNyr+1LWwSn9CIeNk461SsUDTYvYAVnax
+T6g7UkMSxH66xFxahjJiw0Zv+BkZjEpox9TO0AgxfxXFgqkNYMLrtLNdMzNisIMHx9xD2SfXU+oPwnWR
+7TGlMBeTBZZYKkXKdTJmYAARJCUAb0okVNYYBqcWOOoMJ0OxOpLJrnNnTkl2phumVGsy7k6i2s5URfou
+f9B90h7DWV+u+DgvTxqDr5cg+z9+P5BmYMfxIbvJUMog+Ufyt7MUaPNXJh2kNvDfYXifGqeR4z5NL8BY
++RMXZLBnUH60bIxhqRqaHuC0XPgbo5fZ5FFZu7ELmoD64Fx0ejAkif4rC3lFxLPODBjGYLlE5968P2lk
+UniD9KIZhhR6yEOprylbt5R2i9siIoDiX0cMf/64mQQqYtS4HzjtpkitC9eeNudyOinVbc7OnHVoRC0O
+VAd2Pm3GVtm7GVNNmbG09FDiZtRyvg//Mvx3Lz7Om/F/SuJ0pVMUE3oxPewQdnbZdJTnIe1rq/qQc9Bo
+Kpo6aRCacU2dX3UVLmVX/1+29+ylY7s2uA3XeWkzKuT9DaA4zkvXFhDMp/HtfcDW9xIG78PlKrqHdCy4
+5zFX39/o/XK+lM7haEgGhy8GjYu5qtlnl/X/55YOntC/7jAYnDJjodR1/XuUq6gDMF1eJOEa+ybaOZiX
+aoF1U0unUI88BbEcpvPAVAA6KGheugkU82b8iRizGU0jSDsD/SjETKcNZ6b3ivKih3K0b2GK7r28bCXf
+fade33ihOA+WIbedDgcZBIUZi15tmCA7ab4JE3YDRWgnqRygQ6AX6790iBBmHuKRWpViyvHYV2tEP99V
+NTbehDyIbzw/YjSZcAjea6j+2q9l8qmiAKsutddC5aMsTzBpf77jynNblQJIWNsvzvFjCyxMLPBJ1Bz8
+Pg+MKUUNVqUS/WBlUyJyi01hCyKFSjjAZyDuxnWf10duixFIjeYhVyBdlVgjIxLd/KjhRk5UCrpZyZeC
+wCvALv9eQg1aFJZZGd2JrACqeKMReX27AgcCFfQGSu2AASmGl2+c+BS+bxihCQO9ulq1+H5oynyawlw8
+I5yuwzlFC/I6d+MVfQlgbQBrBKz6NqrC+l6Qrd5L34Zz4LasigbJBfZH+mg0WynVVLZ96uzWSuYzCStm
+5xiOVnGKqxf7dapD8sPz589N7aWCv/bzOF85puqRfrKXaD3P31Trx9DAwqdM2BkqGB4MyR1UH4sYYtLg
+4v3lRxjAkueI4BKeULcg4WyT72P7O96+6TdDaL54H7j/o7eGMv2iXWV3tfrqd+FtpZSdIKf1MKf7aaGV
+PS0cWfCnJKFlaqlyVt/iW/9jrw4VGZS7KuHVlHi0+Mtup9OMOfr+oRIClEup1KvdJXgT6SP2E4tbXXxL
+6NIonEO+E7GZtIo7UnyyU1FF95WHMTx0Lv8jHAFrV4uj5zq6H7XvIvpR7ZmOC+vFw7DwRUjIxMMRf2VC
+0PkDVuzvRj1URLsas80r0eorULxELg5u91XyE2glUBlLAJqxapfPxeoexSvo36i+g/4l9mmU30IPGHc/
+XaKvJos4TV58f6Sfz5Ltrt6WuZlYrpngKyBYU7LlSorfSWyp7eABVPYzhK5bo73bdXu17uo540H31NWw
+0VbTfnJ78B+VJ7m1CjIAAA==
 `,
 	},
 
 	"/static/view/vpctype.js": {
 		name:    "vpctype.js",
 		local:   "esc/static/view/vpctype.js",
-		size:    3273,
-		modtime: 1677521893,
+		size:    3764,
+		modtime: 1792369551,
 		compressed: `
-H4sIAAAAAAAC/8SWUW/TMBDH3/MpTkFiQyItHfAAXSLBtPEyJDRG4XFeck3NHDuyL2urdRIfgk/IJ0FO
-GqfNWrau28hTFJ/vfn+f79/yLFea4ApGlAm4hqFWGex0Ol3BKbDf3Evnp9npe163W8XQCGHw5eB0miOg
-LDLUjDCBWElDTJIBLiFTCQrTSZVXfobLPLbxgx6E8Kq//PEYUxZPIYRea+FwEmNOXEkIYa/fznTENY6Z
-EBDC69biZ55qRlymg96pWop8sz6yjrI7IIS3fc/DSXlE1Y5acghXHtgnZvJDktTb3sOwkLGl3Z3nflEH
-AoBGKrSsq0IYhgtHMpsBjbjpcFMnm2MpaSNcvn6Z7vqlK3+CmbrE7QncAd2ZBACWYNZsuC/SugbOZrdE
-LzZx6bi6XTipyjGIlVAaEyCcELDzc42XvESGMacRMCClBPEcSMEFYg6C2xvDcWygkLEoiFBjUulIkb7S
-VGDyb60CCQizXDCyV8iO1dm+yZmEWDBjQr+umZigCErCIEg1m/qQMGLBfDn0v8mMSZZi4kd/fv3e79oc
-0Vml1VXjQ9hd2elFJPtsjGSKOEZj2lQD1MYeYM+PBr1lqLIJgMLgOqzKALZGyzXPmJ4GCdMXqNuEVRE/
-Oj78tCmf86GtEVFrpUvANp6r4UeHPw42JWwu/qM3GOpKttNH3zclXTPZ98IeMy3bnC5/9VvVYJOC1Rqe
-P5vs9d7tbSlm0XgeX4wzxEVVVUOcnBVi3FvbgmvCWy3znCUpQsYoHlks+1egXDKgho0VwlDp0l0LJirf
-dF750SbY0ioTE8RBSeJHzg5vyn1gN2yqVvMy5EI04xK5NjyB+61GaflfVCV+GrNbJpq/zv3Oj1zOJ/K1
-u7bKzdH/cLE1R1b6QNRMfgM7n2x4UPx7+9bG+M6zbujYwqm86773NwAA///Y3ixZyQwAAA==
+H4sIAAAAAAAC/8WW207bQBCG7/MUI1cqVKqThh6kNthSi6A3IFWUpr1ksSfOlvVBu2uSCJD6EH3CPkl3
+vfY6NoTmAKlvYnknM98/u/PbNM5SLuEaxjJmcAsjnsaw0+32GJWufmZvuj/FzqDT6fVMjBwjDL8cnM0y
+BEzyGDmRGEKQJkKSRAqgCcRpiEx0o7RTPIarLNDxwz548GrQfHiMEQlmaqHfWjicBphJmiZqbW/QznRE
+OU4IY2rxdWvxhEaKiSbRsH+WNiLfLI6sovQ/VOTbRZEGt4x6p/qC06KRJrpqjAfXHdBXQJKPYVgl/wCj
+PAm0pt0y74sqUF0cZc6TqiJ4njfXuJsb1XkqulRUyUqkNNERNt+gSHf70pY/xTi9ws0JbBuXJlEJ2zAm
+EnX71iUxG9CUuQBl3RKLDpBS/nD0/CFqEKrhOTXliDopLOVqYiROJZCLC45XtECGCZVjFSDTlEmaqV+4
+RMyAUX0OKU4EKDkslxLV/42OCOVXOWMYPqyVoVT14oyp5qvDqcf6fF9kJIGAESE8p6oZCjd3C0LXVbJm
+DoREErdc9pxvSUwSEmHo+H9+/d7v6Rz+udFqq9ER7N57huaR9LUyksiDAIVoUw2RC93AvuMP+02oYhNA
+uREuwjIHamO0jNOY8JkbEn6JvE1oijj+8eHnVfmsD26MiJyrHw3YxrM1HP/wx8GqhPXBf/INhqqS3umj
+76uSLpjstbAnhCdtTpvfvCtrbDXL92t4/my613+/t6GYeeN5ejHWEOdVmQ2xctYWU79gn0pI+cXRhFeD
++RC7vWu/Piqof9r9BQkjhJjIYKxJ9GdUsSQgHdU2DqOUF2+GnDDj+dbnP+kEG9q8GrXALUgc31r5XbmP
+7OR1VTPrI8pYPeq+3YUtOPf9KC3v9k3i7Rh1k6i8Lb1auXGVc0uevOxWWQ/4Hw68oGXF6Pv1sNew5WTD
+o+Kv7bkr41u/vaNj2y67JHrpsMvgLmusndtB5y8AtCF5tA4AAA==
 `,
 	},
 
@@ -13421,6 +13517,7 @@ var _escDirs = map[string][]os.FileInfo{
 		_escData["/static/view/account.js"],
 		_escData["/static/view/accounts.js"],
 		_escData["/static/view/batch.js"],
+		_escData["/static/view/cmsnet.js"],
 		_escData["/static/view/components"],
 		_escData["/static/view/dashboard.js"],
 		_escData["/static/view/mixins.js"],
@@ -28,8 +28,10 @@ import (
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/jira"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/orchestration"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/servicenow"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/session"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/static"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
 )
 
 func main() {
@@ -186,6 +188,39 @@ func main() {
 		os.Exit(2)
 	}
 
+	ticketingProviders := []ticketing.Provider{jiraClient}
+	if os.Getenv("SERVICENOW_CONFIG") != "" {
+		serviceNowConfig := servicenow.Config{}
+		err = json.Unmarshal([]byte(os.Getenv("SERVICENOW_CONFIG")), &serviceNowConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing ServiceNow config: %s\n", err)
+			os.Exit(1)
+		}
+		if os.Getenv("SERVICENOW_USERNAME") == "" || os.Getenv("SERVICENOW_PASSWORD") == "" {
+			fmt.Fprintf(os.Stderr, "%s\n", "SERVICENOW_USERNAME and SERVICENOW_PASSWORD env variables are required with SERVICENOW_CONFIG")
+			os.Exit(2)
+		}
+		ticketingProviders = append(ticketingProviders, &servicenow.Client{
+			Config:   serviceNowConfig,
+			Username: os.Getenv("SERVICENOW_USERNAME"),
+			Password: os.Getenv("SERVICENOW_PASSWORD"),
+		})
+	}
+	// Everything goes to JIRA unless TICKETING_ROUTES sends an account or project elsewhere
+	ticketingRoutes := ticketing.Routes{Default: jiraClient.Name()}
+	if os.Getenv("TICKETING_ROUTES") != "" {
+		err = json.Unmarshal([]byte(os.Getenv("TICKETING_ROUTES")), &ticketingRoutes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing ticketing routes: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	ticketingRouter, err := ticketing.NewRouter(ticketingRoutes, ticketingProviders...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring ticketing: %s\n", err)
+		os.Exit(2)
+	}
+
 	azureADConfig, err := azure.GetConfigFromENV()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading Azure AD configuration: %s", err)
@@ -220,10 +255,11 @@ func main() {
 		ModelsManager: &database.SQLModelsManager{
 			DB: db,
 		},
-		JIRAClient:       jiraClient,
-		JIRAIssueLabels:  jiraIssueLabels,
-		ReparseTemplates: devMode,
-		TaskParallelism:  taskParallelism,
+		Ticketing:         ticketingRouter,
+		AutoApproveAsUser: os.Getenv("AUTO_APPROVE_AS_USER"),
+		JIRAIssueLabels:   jiraIssueLabels,
+		ReparseTemplates:  devMode,
+		TaskParallelism:   taskParallelism,
 	}
 
 	onlyAccountIDs := strings.TrimSpace(os.Getenv("ONLY_AWS_ACCOUNT_IDS"))
@@ -244,11 +280,27 @@ func main() {
 		}
 	}
 
+	egressIPWebhookURL := os.Getenv("EGRESS_IP_SLACK_WEBHOOK_URL")
+	if egressIPWebhookURL != "" {
+		server.EgressIPNotifier = &SlackEgressIPNotifier{WebhookURL: egressIPWebhookURL}
+	}
+
 	cmsnetClient := cmsnet.NewClient(server.CMSNetConfig, nil, server.CachedCredentials.CredentialsProvider)
 
 	server.listenForNewTasks(postgresConnectionString)
+	server.EvaluateSubmittedVPCRequests(postgresConnectionString)
 	server.SyncVPCRequestStatuses()
 
+	// CMSNET_HEALTH_INTERVAL is a duration such as "6h"; the check is off if unset
+	if cmsnetHealthInterval := os.Getenv("CMSNET_HEALTH_INTERVAL"); cmsnetHealthInterval != "" {
+		interval, err := time.ParseDuration(cmsnetHealthInterval)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", "Invalid CMSNET_HEALTH_INTERVAL")
+			os.Exit(2)
+		}
+		server.MonitorCMSNetHealth(interval, os.Getenv("CMSNET_AUTO_REMEDIATE") == "1")
+	}
+
 	tasksDone := server.DoTasks()
 
 	go func() {
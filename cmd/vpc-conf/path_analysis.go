@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/connection"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// analyzeVPCPath explains whether traffic from a subnet or network interface
+// in vpc can reach ip on port. If ip belongs to a network interface in the
+// same VPC, that interface's network ACL and security groups are checked
+// too. Nothing is created.
+func analyzeVPCPath(ec2svc ec2iface.EC2API, vpc *database.VPC, sourceID string, securityGroupIDs []string, ip net.IP, port int64) (*connection.PathAnalysis, error) {
+	source := &connection.NetworkInterfaceSpec{
+		AccountID: vpc.AccountID,
+		Region:    string(vpc.Region),
+	}
+	if strings.HasPrefix(sourceID, "eni-") {
+		if len(securityGroupIDs) > 0 {
+			return nil, fmt.Errorf("Security groups can't be given for a network interface")
+		}
+		out, err := ec2svc.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
+			NetworkInterfaceIds: aws.StringSlice([]string{sourceID}),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Error describing %s: %s", sourceID, err)
+		}
+		if len(out.NetworkInterfaces) != 1 || aws.StringValue(out.NetworkInterfaces[0].VpcId) != vpc.ID {
+			return nil, fmt.Errorf("Network interface %s is not in VPC %s", sourceID, vpc.ID)
+		}
+		source.NetworkInterfaceID = aws.String(sourceID)
+	} else {
+		if _, ok := selectBySubnetID(vpc.State, sourceID); !ok {
+			return nil, fmt.Errorf("Subnet %s is not in VPC %s", sourceID, vpc.ID)
+		}
+		source.SubnetID = sourceID
+		if len(securityGroupIDs) > 0 {
+			source.SecurityGroupIDs = securityGroupIDs
+		} else {
+			source.CreateEgressSecurityGroup = true
+		}
+	}
+
+	destination := &connection.Endpoint{}
+	out, err := ec2svc.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{vpc.ID})},
+			{Name: aws.String("addresses.private-ip-address"), Values: aws.StringSlice([]string{ip.String()})},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error looking for a network interface with IP %s: %s", ip, err)
+	}
+	if len(out.NetworkInterfaces) == 1 {
+		destination.NetworkInterfaceSpec = &connection.NetworkInterfaceSpec{
+			AccountID:          vpc.AccountID,
+			Region:             string(vpc.Region),
+			NetworkInterfaceID: out.NetworkInterfaces[0].NetworkInterfaceId,
+		}
+	} else {
+		destination.IPAddress = ipAddressForConnectivityTest(ip)
+	}
+
+	analysis, err := connection.AnalyzePath(ec2svc, ec2svc, &connection.ConnectionSpec{
+		Source:      &connection.Endpoint{NetworkInterfaceSpec: source},
+		Destination: destination,
+		Port:        port,
+	}, &connection.DefaultLogger{})
+	if err != nil {
+		return nil, err
+	}
+	annotatePathAnalysis(vpc, analysis)
+	return analysis, nil
+}
+
+// annotatePathAnalysis notes which hops are resources vpc-conf manages.
+func annotatePathAnalysis(vpc *database.VPC, analysis *connection.PathAnalysis) {
+	for _, hop := range analysis.Hops {
+		switch hop.Type {
+		case connection.HopTypeRouteTable:
+			rt, ok := vpc.State.RouteTables[hop.ResourceID]
+			if !ok {
+				hop.Detail += " (route table not managed by vpc-conf)"
+			} else if rt.SubnetType != "" {
+				hop.Detail += fmt.Sprintf(" (%s route table managed by vpc-conf)", rt.SubnetType)
+			} else {
+				hop.Detail += fmt.Sprintf(" (%s edge route table managed by vpc-conf)", rt.EdgeAssociationType)
+			}
+		case connection.HopTypeTransitGateway:
+			for _, tga := range vpc.State.TransitGatewayAttachments {
+				if tga.TransitGatewayID == hop.ResourceID {
+					hop.Detail += fmt.Sprintf(" (managed attachment %s)", tga.TransitGatewayAttachmentID)
+				}
+			}
+		}
+	}
+}
+
+var handlePathAnalysis = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handlePathAnalysis but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	query := r.URL.Query()
+	sourceID := query.Get("source")
+	if sourceID == "" {
+		http.Error(w, "source must be a subnet or network interface ID", http.StatusBadRequest)
+		return
+	}
+	ip := net.ParseIP(query.Get("ip"))
+	if ip == nil || ip.To4() == nil {
+		http.Error(w, "ip must be an IPv4 address", http.StatusBadRequest)
+		return
+	}
+	port, err := strconv.ParseInt(query.Get("port"), 10, 64)
+	if err != nil || port < 1 || port > 65535 {
+		http.Error(w, "port must be between 1 and 65535", http.StatusBadRequest)
+		return
+	}
+	var securityGroupIDs []string
+	if groups := query.Get("securityGroups"); groups != "" {
+		securityGroupIDs = strings.Split(groups, ",")
+	}
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil {
+		http.Error(w, fmt.Sprintf("VPC %s is not automated", vpcID), http.StatusBadRequest)
+		return
+	}
+	sess, err := s.CachedCredentials.GetAWSSession(accountID, region, s.getSession(r).Username)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	analysis, err := analyzeVPCPath(ec2.New(sess), vpc, sourceID, securityGroupIDs, ip, port)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(analysis)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
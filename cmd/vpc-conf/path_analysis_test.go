@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/connection"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/google/go-cmp/cmp"
+)
+
+type pathAnalysisEC2 struct {
+	ec2iface.EC2API
+
+	subnets     []*ec2.Subnet
+	routeTables []*ec2.RouteTable
+	acls        []*ec2.NetworkAcl
+	groups      []*ec2.SecurityGroup
+	nats        []*ec2.NatGateway
+	endpoints   []*ec2.VpcEndpoint
+	interfaces  []*ec2.NetworkInterface
+}
+
+func firstFilterValue(filters []*ec2.Filter, name string) string {
+	for _, f := range filters {
+		if aws.StringValue(f.Name) == name {
+			return aws.StringValue(f.Values[0])
+		}
+	}
+	return ""
+}
+
+func (m *pathAnalysisEC2) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	for _, subnet := range m.subnets {
+		if aws.StringValue(subnet.SubnetId) == aws.StringValue(input.SubnetIds[0]) {
+			return &ec2.DescribeSubnetsOutput{Subnets: []*ec2.Subnet{subnet}}, nil
+		}
+	}
+	return &ec2.DescribeSubnetsOutput{}, nil
+}
+
+func (m *pathAnalysisEC2) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	subnetID := firstFilterValue(input.Filters, "association.subnet-id")
+	for _, rt := range m.routeTables {
+		for _, assoc := range rt.Associations {
+			if aws.StringValue(assoc.SubnetId) == subnetID {
+				return &ec2.DescribeRouteTablesOutput{RouteTables: []*ec2.RouteTable{rt}}, nil
+			}
+		}
+	}
+	return &ec2.DescribeRouteTablesOutput{}, nil
+}
+
+func (m *pathAnalysisEC2) DescribeNetworkAcls(input *ec2.DescribeNetworkAclsInput) (*ec2.DescribeNetworkAclsOutput, error) {
+	subnetID := firstFilterValue(input.Filters, "association.subnet-id")
+	for _, acl := range m.acls {
+		for _, assoc := range acl.Associations {
+			if aws.StringValue(assoc.SubnetId) == subnetID {
+				return &ec2.DescribeNetworkAclsOutput{NetworkAcls: []*ec2.NetworkAcl{acl}}, nil
+			}
+		}
+	}
+	return &ec2.DescribeNetworkAclsOutput{}, nil
+}
+
+func (m *pathAnalysisEC2) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	out := &ec2.DescribeSecurityGroupsOutput{}
+	for _, id := range input.GroupIds {
+		for _, group := range m.groups {
+			if aws.StringValue(group.GroupId) == aws.StringValue(id) {
+				out.SecurityGroups = append(out.SecurityGroups, group)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *pathAnalysisEC2) DescribeNatGateways(input *ec2.DescribeNatGatewaysInput) (*ec2.DescribeNatGatewaysOutput, error) {
+	for _, nat := range m.nats {
+		if aws.StringValue(nat.NatGatewayId) == aws.StringValue(input.NatGatewayIds[0]) {
+			return &ec2.DescribeNatGatewaysOutput{NatGateways: []*ec2.NatGateway{nat}}, nil
+		}
+	}
+	return nil, fmt.Errorf("No NAT gateway %s", aws.StringValue(input.NatGatewayIds[0]))
+}
+
+func (m *pathAnalysisEC2) DescribeVpcEndpoints(input *ec2.DescribeVpcEndpointsInput) (*ec2.DescribeVpcEndpointsOutput, error) {
+	for _, endpoint := range m.endpoints {
+		if aws.StringValue(endpoint.VpcEndpointId) == aws.StringValue(input.VpcEndpointIds[0]) {
+			return &ec2.DescribeVpcEndpointsOutput{VpcEndpoints: []*ec2.VpcEndpoint{endpoint}}, nil
+		}
+	}
+	return &ec2.DescribeVpcEndpointsOutput{}, nil
+}
+
+func (m *pathAnalysisEC2) DescribeNetworkInterfaces(input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	out := &ec2.DescribeNetworkInterfacesOutput{}
+	for _, iface := range m.interfaces {
+		if len(input.NetworkInterfaceIds) > 0 {
+			if aws.StringValue(iface.NetworkInterfaceId) == aws.StringValue(input.NetworkInterfaceIds[0]) {
+				out.NetworkInterfaces = append(out.NetworkInterfaces, iface)
+			}
+		} else if aws.StringValue(iface.VpcId) == firstFilterValue(input.Filters, "vpc-id") && aws.StringValue(iface.PrivateIpAddress) == firstFilterValue(input.Filters, "addresses.private-ip-address") {
+			out.NetworkInterfaces = append(out.NetworkInterfaces, iface)
+		}
+	}
+	return out, nil
+}
+
+func TestPathAnalysis(t *testing.T) {
+	routeTable := func(id, subnetID string, routes ...*ec2.Route) *ec2.RouteTable {
+		return &ec2.RouteTable{
+			RouteTableId: aws.String(id),
+			Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String(subnetID)}},
+			Routes:       routes,
+		}
+	}
+	aclEntry := func(number int64, egress bool, port int64, action string) *ec2.NetworkAclEntry {
+		entry := &ec2.NetworkAclEntry{
+			RuleNumber: aws.Int64(number),
+			Egress:     aws.Bool(egress),
+			Protocol:   aws.String("-1"),
+			CidrBlock:  aws.String("0.0.0.0/0"),
+			RuleAction: aws.String(action),
+		}
+		if port != 0 {
+			entry.Protocol = aws.String("6")
+			entry.PortRange = &ec2.PortRange{From: aws.Int64(port), To: aws.Int64(port)}
+		}
+		return entry
+	}
+	openACL := func(id, subnetID string) *ec2.NetworkAcl {
+		return &ec2.NetworkAcl{
+			NetworkAclId: aws.String(id),
+			Associations: []*ec2.NetworkAclAssociation{{SubnetId: aws.String(subnetID)}},
+			Entries:      []*ec2.NetworkAclEntry{aclEntry(100, true, 0, "allow"), aclEntry(100, false, 0, "allow")},
+		}
+	}
+	privateACL := openACL("acl-private", "subnet-private")
+	privateACL.Entries = append(privateACL.Entries, aclEntry(90, false, 22, "deny"))
+	// Replies from 1.1.1.0/24 can't get back to the NAT gateway
+	publicACL := openACL("acl-public", "subnet-public")
+	publicACL.Entries = append(publicACL.Entries, &ec2.NetworkAclEntry{
+		RuleNumber: aws.Int64(50),
+		Egress:     aws.Bool(false),
+		Protocol:   aws.String("6"),
+		PortRange:  &ec2.PortRange{From: aws.Int64(1024), To: aws.Int64(65535)},
+		CidrBlock:  aws.String("1.1.1.0/24"),
+		RuleAction: aws.String("deny"),
+	})
+
+	ec2svc := &pathAnalysisEC2{
+		subnets: []*ec2.Subnet{
+			{SubnetId: aws.String("subnet-private"), VpcId: aws.String("vpc-abc"), CidrBlock: aws.String("10.0.1.0/24")},
+		},
+		routeTables: []*ec2.RouteTable{
+			routeTable("rtb-private", "subnet-private",
+				&ec2.Route{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local")},
+				&ec2.Route{DestinationCidrBlock: aws.String("0.0.0.0/0"), NatGatewayId: aws.String("nat-1")},
+				&ec2.Route{DestinationCidrBlock: aws.String("10.0.0.0/8"), TransitGatewayId: aws.String("tgw-1")}),
+			routeTable("rtb-public", "subnet-public",
+				&ec2.Route{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("vpce-fw")}),
+			routeTable("rtb-firewall", "subnet-firewall",
+				&ec2.Route{DestinationCidrBlock: aws.String("0.0.0.0/0"), GatewayId: aws.String("igw-1")}),
+		},
+		acls: []*ec2.NetworkAcl{privateACL, publicACL, openACL("acl-firewall", "subnet-firewall")},
+		groups: []*ec2.SecurityGroup{
+			{
+				GroupId:   aws.String("sg-web"),
+				GroupName: aws.String("web"),
+				IpPermissionsEgress: []*ec2.IpPermission{
+					{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(443), ToPort: aws.Int64(443), IpRanges: []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}}},
+					{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(5432), ToPort: aws.Int64(5432), UserIdGroupPairs: []*ec2.UserIdGroupPair{{GroupId: aws.String("sg-db")}}},
+				},
+			},
+			{
+				GroupId:   aws.String("sg-db"),
+				GroupName: aws.String("db"),
+				IpPermissions: []*ec2.IpPermission{
+					{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(5432), ToPort: aws.Int64(5432), IpRanges: []*ec2.IpRange{{CidrIp: aws.String("10.0.0.0/16")}}},
+				},
+			},
+		},
+		nats: []*ec2.NatGateway{
+			{NatGatewayId: aws.String("nat-1"), SubnetId: aws.String("subnet-public"), State: aws.String(ec2.NatGatewayStateAvailable)},
+		},
+		endpoints: []*ec2.VpcEndpoint{
+			{VpcEndpointId: aws.String("vpce-fw"), VpcEndpointType: aws.String(ec2.VpcEndpointTypeGatewayLoadBalancer), SubnetIds: aws.StringSlice([]string{"subnet-firewall"})},
+		},
+		interfaces: []*ec2.NetworkInterface{
+			{
+				NetworkInterfaceId: aws.String("eni-db"),
+				VpcId:              aws.String("vpc-abc"),
+				SubnetId:           aws.String("subnet-private"),
+				PrivateIpAddress:   aws.String("10.0.1.50"),
+				Groups:             []*ec2.GroupIdentifier{{GroupId: aws.String("sg-db")}},
+			},
+		},
+	}
+	vpc := &database.VPC{
+		ID:        "vpc-abc",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		State: &database.VPCState{
+			VPCType: database.VPCTypeV1Firewall,
+			AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private"}},
+					},
+				},
+			},
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-private": {SubnetType: database.SubnetTypePrivate},
+			},
+			TransitGatewayAttachments: []*database.TransitGatewayAttachment{
+				{TransitGatewayID: "tgw-1", TransitGatewayAttachmentID: "tgw-attach-1"},
+			},
+		},
+	}
+
+	type testCase struct {
+		name           string
+		securityGroups []string
+		ip             string
+		port           int64
+		expectedHops   []connection.HopType
+		expectedReason string
+	}
+	testCases := []testCase{
+		{
+			name:           "internet through NAT and firewall",
+			securityGroups: []string{"sg-web"},
+			ip:             "8.8.8.8",
+			port:           443,
+			expectedHops: []connection.HopType{
+				connection.HopTypeSecurityGroups, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeNATGateway,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeFirewallEndpoint,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeInternetGateway,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeNetworkACL,
+			},
+		},
+		{
+			name:           "blocked by source security group",
+			securityGroups: []string{"sg-web"},
+			ip:             "8.8.8.8",
+			port:           80,
+			expectedHops: []connection.HopType{
+				connection.HopTypeSecurityGroups, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeNATGateway,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeFirewallEndpoint,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeInternetGateway,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeNetworkACL,
+			},
+			expectedReason: "No group allows egress to 8.8.8.8 on port 80",
+		},
+		{
+			name: "shared service over the transit gateway",
+			ip:   "10.244.112.49",
+			port: 636,
+			expectedHops: []connection.HopType{
+				connection.HopTypeSecurityGroups, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeTransitGateway,
+				connection.HopTypeNetworkACL,
+			},
+		},
+		{
+			name:           "network interface in the same VPC",
+			securityGroups: []string{"sg-web"},
+			ip:             "10.0.1.50",
+			port:           5432,
+			expectedHops: []connection.HopType{
+				connection.HopTypeSecurityGroups, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeLocal,
+				connection.HopTypeNetworkACL, connection.HopTypeSecurityGroups,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+			},
+		},
+		{
+			name: "blocked by destination network ACL",
+			ip:   "10.0.1.50",
+			port: 22,
+			expectedHops: []connection.HopType{
+				connection.HopTypeSecurityGroups, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeLocal,
+				connection.HopTypeNetworkACL, connection.HopTypeSecurityGroups,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+			},
+			expectedReason: "Rule 90 denies ingress from 10.0.1.0 on port 22",
+		},
+		{
+			name:           "reply blocked by NAT subnet network ACL",
+			securityGroups: []string{"sg-web"},
+			ip:             "1.1.1.1",
+			port:           443,
+			expectedHops: []connection.HopType{
+				connection.HopTypeSecurityGroups, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeNATGateway,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeFirewallEndpoint,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeRouteTable, connection.HopTypeInternetGateway,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeNetworkACL, connection.HopTypeNetworkACL,
+				connection.HopTypeNetworkACL,
+			},
+			expectedReason: "Rule 50 denies ingress from 1.1.1.1 on ports 1024-65535",
+		},
+	}
+
+	for _, tc := range testCases {
+		analysis, err := analyzeVPCPath(ec2svc, vpc, "subnet-private", tc.securityGroups, net.ParseIP(tc.ip), tc.port)
+		if err != nil {
+			t.Errorf("Test case %s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		hops := []connection.HopType{}
+		for _, hop := range analysis.Hops {
+			hops = append(hops, hop.Type)
+		}
+		if diff := cmp.Diff(tc.expectedHops, hops); diff != "" {
+			t.Errorf("Test case %s: expected hops did not match actual: \n%s", tc.name, diff)
+		}
+		if analysis.Allowed != (tc.expectedReason == "") || analysis.Reason != tc.expectedReason {
+			t.Errorf("Test case %s: expected reason %q but got allowed=%v reason %q", tc.name, tc.expectedReason, analysis.Allowed, analysis.Reason)
+		}
+	}
+
+	analysis, err := analyzeVPCPath(ec2svc, vpc, "subnet-private", []string{"sg-web"}, net.ParseIP("8.8.8.8"), 443)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expectedRules := []*connection.AuthorizingRule{{SecurityGroupID: "sg-web", SecurityGroupName: "web", Rule: "tcp port 443 0.0.0.0/0"}}
+	if diff := cmp.Diff(expectedRules, analysis.Hops[0].AuthorizingRules); diff != "" {
+		t.Errorf("Expected authorizing rules did not match actual: \n%s", diff)
+	}
+	if detail := analysis.Hops[2].Detail; detail != "8.8.8.8 is routed to nat-1 by the route for 0.0.0.0/0 (Private route table managed by vpc-conf)" {
+		t.Errorf("Unexpected route table detail %q", detail)
+	}
+
+	_, err = analyzeVPCPath(ec2svc, vpc, "subnet-other", nil, net.ParseIP("8.8.8.8"), 443)
+	if err == nil {
+		t.Errorf("Expected an error for a subnet outside the VPC")
+	}
+}
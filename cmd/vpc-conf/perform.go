@@ -17,10 +17,12 @@ import (
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/credentialservice"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/flowlogs"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ipcontrol"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/jira"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/orchestration"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/swagger/models"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -106,10 +108,20 @@ type TaskContext struct {
 	IPAM                     client.Client
 	CMSNet                   cmsnet.ClientInterface
 	Orchestration            *orchestration.Client
+	Ticketing                *ticketing.Router // optional
+	EgressIPNotifier         EgressIPNotifier  // optional
 	TaskDatabase             *database.TaskDatabase
 	AsUser                   string
 }
 
+// issueURL links to an issue in whichever ticketing system it was filed in.
+func (taskContext *TaskContext) issueURL(issue string) string {
+	if taskContext.Ticketing == nil {
+		return jira.JiraURL(issue)
+	}
+	return taskContext.Ticketing.IssueURL(issue)
+}
+
 func (s *Server) performTask(t *database.Task, lockSet database.LockSet) {
 	dependsOn, err := t.DependsOn()
 	if err != nil {
@@ -165,11 +177,22 @@ func (s *Server) performTask(t *database.Task, lockSet database.LockSet) {
 		AWSAccountAccessProvider: &CredentialsServiceBackedAWSAccountAccessProvider{
 			CredentialService: s.CredentialService,
 		},
-		ModelsManager: s.ModelsManager,
-		IPAM:          s.IPAM,
-		CMSNet:        cmsnet.NewClient(s.CMSNetConfig, s.LimitToAWSAccountIDs, s.CredentialService),
-		Orchestration: s.Orchestration,
-		AsUser:        taskData.AsUser,
+		ModelsManager:    s.ModelsManager,
+		IPAM:             s.IPAM,
+		CMSNet:           cmsnet.NewClient(s.CMSNetConfig, s.LimitToAWSAccountIDs, s.CredentialService),
+		Orchestration:    s.Orchestration,
+		Ticketing:        s.Ticketing,
+		EgressIPNotifier: s.EgressIPNotifier,
+		AsUser:           taskData.AsUser,
+	}
+
+	if region, vpcID := routeChangingTaskVPC(taskData); vpcID != "" {
+		err := ctx.snapshotRouteTables(region, vpcID, "Before "+t.Description)
+		if err != nil {
+			t.Log("Error taking route table snapshot: %s", err)
+			t.SetStatus(database.TaskStatusFailed)
+			return
+		}
 	}
 
 	if taskData.CreateVPCTaskData != nil {
@@ -182,10 +205,26 @@ func (s *Server) performTask(t *database.Task, lockSet database.LockSet) {
 		ctx.performUpdateLoggingTask(taskData.UpdateLoggingTaskData)
 	} else if taskData.UpdateSecurityGroupsTaskData != nil {
 		ctx.performUpdateSecurityGroupsTask(taskData.UpdateSecurityGroupsTaskData)
+	} else if taskData.UpdateVPCEndpointsTaskData != nil {
+		ctx.performUpdateVPCEndpointsTask(taskData.UpdateVPCEndpointsTaskData)
+	} else if taskData.UpdateVPNConnectionTaskData != nil {
+		ctx.performUpdateVPNConnectionTask(taskData.UpdateVPNConnectionTaskData)
+	} else if taskData.SnapshotRouteTablesTaskData != nil {
+		ctx.performSnapshotRouteTablesTask(taskData.SnapshotRouteTablesTaskData)
+	} else if taskData.RestoreRouteTablesTaskData != nil {
+		ctx.performRestoreRouteTablesTask(taskData.RestoreRouteTablesTaskData)
+	} else if taskData.TestConnectivityTaskData != nil {
+		ctx.performTestConnectivityTask(taskData.TestConnectivityTaskData)
+	} else if taskData.UpdateFirewallPolicyTaskData != nil {
+		ctx.performUpdateFirewallPolicyTask(taskData.UpdateFirewallPolicyTaskData)
+	} else if taskData.AnalyzeSecurityGroupUsageTaskData != nil {
+		ctx.performAnalyzeSecurityGroupUsageTask(taskData.AnalyzeSecurityGroupUsageTaskData)
 	} else if taskData.ImportVPCTaskData != nil {
 		ctx.performImportVPCTask(taskData.ImportVPCTaskData)
 	} else if taskData.EstablishExceptionVPCTaskData != nil {
 		ctx.performEstablishExceptionVPCTask(taskData.EstablishExceptionVPCTaskData)
+	} else if taskData.UpdateExceptionVPCTaskData != nil {
+		ctx.performUpdateExceptionVPCTask(taskData.UpdateExceptionVPCTaskData)
 	} else if taskData.UnimportVPCTaskData != nil {
 		ctx.performUnimportVPCTask(taskData.UnimportVPCTaskData)
 	} else if taskData.VerifyVPCTaskData != nil {
@@ -196,6 +235,8 @@ func (s *Server) performTask(t *database.Task, lockSet database.LockSet) {
 		ctx.performAddZonedSubnetsTask(taskData.AddZonedSubnetsTaskData)
 	} else if taskData.RemoveZonedSubnetsTaskData != nil {
 		ctx.performRemoveZonedSubnetsTask(taskData.RemoveZonedSubnetsTaskData)
+	} else if taskData.ExpandCIDRTaskData != nil {
+		ctx.performExpandCIDRTask(taskData.ExpandCIDRTaskData)
 	} else if taskData.AddAvailabilityZoneTaskData != nil {
 		ctx.performAddAvailabilityZoneTask(taskData.AddAvailabilityZoneTaskData)
 	} else if taskData.RemoveAvailabilityZoneTaskData != nil {
@@ -208,6 +249,8 @@ func (s *Server) performTask(t *database.Task, lockSet database.LockSet) {
 		ctx.performUpdateVPCNameTask(taskData.UpdateVPCNameTaskData)
 	} else if taskData.DeleteUnusedResourcesTaskData != nil {
 		ctx.performDeleteUnusedResourcesTask(taskData.DeleteUnusedResourcesTaskData)
+	} else if taskData.MigrateLegacyToV1TaskData != nil {
+		ctx.performMigrateLegacyToV1Task(taskData.MigrateLegacyToV1TaskData)
 	} else if taskData.SynchronizeRouteTableStateFromAWSTaskData != nil {
 		ctx.performSynchronizeRouteTableStateFromAWSTask(taskData.SynchronizeRouteTableStateFromAWSTaskData)
 	} else {
@@ -251,6 +294,10 @@ func transitGatewayAttachmentName(vpcName, managedName string) string {
 	return fmt.Sprintf("%s:%s", vpcName, managedName)
 }
 
+func egressOnlyInternetGatewayName(vpcName string) string {
+	return fmt.Sprintf("%s-eigw", vpcName)
+}
+
 func internetGatewayName(vpcName string) string {
 	return vpcName
 }
@@ -1350,6 +1397,7 @@ func (taskContext *TaskContext) performAddZonedSubnetsTask(config *database.AddZ
 	}
 
 	ctx.VPCInfo.ResourceID = config.VPCID // to write the VPC ID back to the new container
+	ctx.VPCInfo.IPv6CIDRBlock = vpcIPv6CIDRBlock(vpc.State)
 	err = awsctx.CreateSubnets(&ctx.VPCInfo)
 	if err != nil {
 		awsctx.Fail("Error creating AWS resources: %s", err)
@@ -1375,6 +1423,7 @@ func (taskContext *TaskContext) performAddZonedSubnetsTask(config *database.AddZ
 			&database.SubnetInfo{
 				SubnetID:  subnet.ResourceID,
 				GroupName: config.GroupName,
+				IPv6CIDR:  subnet.IPv6CIDR,
 			},
 		)
 	}
@@ -1405,6 +1454,210 @@ func (taskContext *TaskContext) performAddZonedSubnetsTask(config *database.AddZ
 	setStatus(t, database.TaskStatusSuccessful)
 }
 
+func (taskContext *TaskContext) performExpandCIDRTask(config *database.ExpandCIDRTaskData) {
+	t := taskContext.Task
+	awsAccountAccess := taskContext.BaseAWSAccountAccess
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+
+	awsctx := &awsp.Context{
+		AWSAccountAccess: awsAccountAccess,
+		Logger:           t,
+		VPCID:            config.VPCID,
+	}
+
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.Region, config.VPCID)
+	if err != nil {
+		t.Log("Error getting VPC info: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if !vpc.State.VPCType.CanExpandCIDR() {
+		t.Log("This is not allowed for this type of VPC")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.Name == "" {
+		t.Log("VPC is missing a name")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.Stack == "" {
+		t.Log("VPC is missing a stack")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	switch config.SubnetType {
+	case database.SubnetTypePublic, database.SubnetTypeFirewall, database.SubnetTypeTransitive, database.SubnetTypeUnroutable:
+		t.Log("%s subnets cannot be expanded", config.SubnetType)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	// Every AZ must already have the group, and the new subnets must be
+	// bigger than the ones they are relieving.
+	groupNamesInUse := map[string]bool{}
+	azs := []string{}
+	for _, az := range vpc.State.AvailabilityZones.InOrder() {
+		azs = append(azs, az.Name)
+		for _, subnets := range az.Subnets {
+			for _, subnet := range subnets {
+				groupNamesInUse[subnet.GroupName] = true
+			}
+		}
+		var existing *database.SubnetInfo
+		for _, subnet := range az.Subnets[config.SubnetType] {
+			if subnet.GroupName == config.GroupName {
+				existing = subnet
+				break
+			}
+		}
+		if existing == nil {
+			t.Log("No %s subnet with group name %q in %s", config.SubnetType, config.GroupName, az.Name)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		out, err := awsctx.EC2().DescribeSubnets(&ec2.DescribeSubnetsInput{
+			SubnetIds: aws.StringSlice([]string{existing.SubnetID}),
+		})
+		if err != nil {
+			t.Log("Error describing subnet %s: %s", existing.SubnetID, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		if len(out.Subnets) != 1 {
+			t.Log("Expected 1 subnet with ID %s but found %d", existing.SubnetID, len(out.Subnets))
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		_, ipNet, err := net.ParseCIDR(aws.StringValue(out.Subnets[0].CidrBlock))
+		if err != nil {
+			t.Log("Error parsing CIDR for subnet %s: %s", existing.SubnetID, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		existingSize, _ := ipNet.Mask.Size()
+		if config.SubnetSize >= existingSize {
+			t.Log("New subnets must be larger than the existing /%d subnet %s", existingSize, existing.SubnetID)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
+	newGroupName := config.NewGroupName
+	if newGroupName == "" {
+		for i := 2; ; i++ {
+			newGroupName = fmt.Sprintf("%s-%d", config.GroupName, i)
+			if !groupNamesInUse[newGroupName] {
+				break
+			}
+		}
+	} else if groupNamesInUse[newGroupName] {
+		t.Log("The group name %q is already in use by another subnet group", newGroupName)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if newGroupName == "public" || newGroupName == "private" || newGroupName == "firewall" {
+		t.Log("A group name of %q is not allowed", newGroupName)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	t.Log("Expanding %s group %q with /%d subnets in new group %q", config.SubnetType, config.GroupName, config.SubnetSize, newGroupName)
+
+	sort.Strings(azs)
+	ctx := &ipcontrol.Context{
+		LockSet: lockSet,
+		IPAM:    taskContext.IPAM,
+		AllocateConfig: database.AllocateConfig{
+			VPCName:           vpc.Name,
+			AccountID:         vpc.AccountID,
+			Stack:             vpc.Stack,
+			AWSRegion:         string(vpc.Region),
+			AvailabilityZones: azs,
+		},
+		Logger: t,
+	}
+
+	err = ctx.AddSubnets(config.SubnetType, config.SubnetSize, newGroupName)
+	if err != nil {
+		t.Log("Error getting IPs for subnets: %s", err)
+		ctx.DeleteIncompleteResources()
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	for _, cidr := range ctx.VPCInfo.NewCIDRs {
+		err := awsctx.AddCIDRBlock(cidr)
+		if err != nil {
+			awsctx.Fail("Error associating new CIDR block: %s", err)
+			ctx.DeleteIncompleteResources()
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
+	ctx.VPCInfo.ResourceID = config.VPCID // to write the VPC ID back to the new container
+	ctx.VPCInfo.IPv6CIDRBlock = vpcIPv6CIDRBlock(vpc.State)
+	err = awsctx.CreateSubnets(&ctx.VPCInfo)
+	if err != nil {
+		awsctx.Fail("Error creating AWS resources: %s", err)
+		ctx.DeleteIncompleteResources()
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	// Update containers with pointers back to AWS resource IDs
+	err = ctx.AddReferencesToContainers()
+	if err != nil {
+		awsctx.Fail("Error updating IPControl containers with AWS resource IDs: %s", err)
+		ctx.DeleteIncompleteResources()
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	err = recordNewSubnetsAndCidrs(ctx.VPCInfo, vpc, taskContext.ModelsManager)
+	if err != nil {
+		awsctx.Fail("Error recording new subnets: %s", err)
+		ctx.DeleteIncompleteResources()
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	err = vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		awsctx.Fail("Error updating VPC state: %s", err)
+		ctx.DeleteIncompleteResources()
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	if taskContext.Orchestration != nil {
+		var notification *orchestration.NewVPCNotification
+		if config.JIRAIssueForComment != "" {
+			notification = &orchestration.NewVPCNotification{
+				VPCID:     config.VPCID,
+				Region:    string(config.Region),
+				JIRAIssue: config.JIRAIssueForComment,
+			}
+		}
+		t.Log("Notifying orchestration engine of changed CIDRs")
+		err := taskContext.Orchestration.NotifyCIDRsChanged(vpc.AccountID, notification)
+		if err != nil {
+			t.Log("Error notifying orchestration engine of new CIDRs: %s", err)
+		}
+	}
+
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+// vpcIPv6CIDRBlock returns the VPC's associated IPv6 block, or "" for IPv4-only VPCs.
+func vpcIPv6CIDRBlock(state *database.VPCState) string {
+	if state == nil || state.IPv6 == nil {
+		return ""
+	}
+	return state.IPv6.AssociatedCIDRBlock
+}
+
 func recordNewSubnetsAndCidrs(vpcInfo ipcontrol.VPCInfo, vpc *database.VPC, mm database.ModelsManager) error {
 	var err error
 	for _, subnet := range vpcInfo.NewSubnets {
@@ -1414,6 +1667,7 @@ func recordNewSubnetsAndCidrs(vpcInfo ipcontrol.VPCInfo, vpc *database.VPC, mm d
 			&database.SubnetInfo{
 				SubnetID:  subnet.ResourceID,
 				GroupName: subnet.GroupName,
+				IPv6CIDR:  subnet.IPv6CIDR,
 			},
 		)
 	}
@@ -1598,6 +1852,7 @@ func (taskContext *TaskContext) performAddAvailabilityZoneTask(config *database.
 	}
 
 	ctx.VPCInfo.ResourceID = config.VPCID // to write the VPC ID back to the new container
+	ctx.VPCInfo.IPv6CIDRBlock = vpcIPv6CIDRBlock(vpc.State)
 	err = awsctx.CreateSubnets(&ctx.VPCInfo)
 	if err != nil {
 		awsctx.Fail("Error creating AWS resources: %s", err)
@@ -1670,6 +1925,7 @@ func (taskContext *TaskContext) performAddAvailabilityZoneTask(config *database.
 				return
 			}
 		}
+		ctx.VPCInfo.IPv6CIDRBlock = vpcIPv6CIDRBlock(vpc.State)
 		err = awsctx.CreateSubnets(&ctx.VPCInfo)
 		if err != nil {
 			awsctx.Fail("Error creating AWS resources: %s", err)
@@ -1873,6 +2129,7 @@ func (taskContext *TaskContext) performRemoveAvailabilityZoneTask(config *databa
 		setStatus(t, database.TaskStatusFailed)
 		return
 	}
+	taskContext.recordEgressIPs(awsctx, vpc)
 
 	if vpc.State.VPCType.HasFirewall() {
 		err = deleteFirewallResourcesWithinAZ(awsctx, vpcWriter, vpc, config.AZName, az)
@@ -2138,7 +2395,7 @@ func (taskContext *TaskContext) performRemoveAvailabilityZoneTask(config *databa
 			notification = &orchestration.NewVPCNotification{
 				VPCID:     config.VPCID,
 				Region:    string(config.Region),
-				JIRAIssue: jira.JiraURL(config.JIRAIssueForComment),
+				JIRAIssue: taskContext.issueURL(config.JIRAIssueForComment),
 			}
 		}
 		t.Log("Notifying orchestration engine of changed CIDRs")
@@ -2242,6 +2499,12 @@ func (taskContext *TaskContext) performImportVPCTask(importConfig *database.Impo
 	ctx.VPCName = vpc.Name
 	ctx.VPCID = vpc.ID
 
+	config := &database.VPCConfig{}
+	if vpc.State != nil && vpc.State.VPCType == database.VPCTypeMigratingLegacyToV1 && vpc.Config != nil {
+		// Keep what was configured while the VPC was Legacy
+		*config = *vpc.Config
+	}
+
 	vpc.State = &database.VPCState{
 		VPCType:           importConfig.VPCType,
 		AvailabilityZones: map[string]*database.AvailabilityZoneInfra{},
@@ -2252,8 +2515,6 @@ func (taskContext *TaskContext) performImportVPCTask(importConfig *database.Impo
 		vpc.State.VPCType = database.VPCTypeV1Firewall
 	}
 
-	config := &database.VPCConfig{}
-
 	var subnetsByType map[database.SubnetType][]*ec2.Subnet
 	if vpc.State.VPCType == database.VPCTypeLegacy {
 		subnetsByType, err = ctx.GetLegacySubnets()
@@ -2863,7 +3124,11 @@ func (taskContext *TaskContext) performImportVPCTask(importConfig *database.Impo
 							return
 						}
 
-						config.ManagedTransitGatewayAttachmentIDs = append(config.ManagedTransitGatewayAttachmentIDs, managedIDs...)
+						for _, managedID := range managedIDs {
+							if !uint64InSlice(managedID, config.ManagedTransitGatewayAttachmentIDs) {
+								config.ManagedTransitGatewayAttachmentIDs = append(config.ManagedTransitGatewayAttachmentIDs, managedID)
+							}
+						}
 						err = taskContext.ModelsManager.UpdateVPCConfig(importConfig.Region, importConfig.VPCID, *config)
 						if err != nil {
 							t.Log("Error updating database: %s", err)
@@ -2876,6 +3141,15 @@ func (taskContext *TaskContext) performImportVPCTask(importConfig *database.Impo
 		}
 	}
 
+	if vpc.State.VPCType.IsV1Variant() {
+		err = importIPv6State(ctx, vpc, vpcResp, importConfig.IPv6)
+		if err != nil {
+			t.Log("Error importing IPv6 configuration: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
 	err = vpcWriter.UpdateState(vpc.State)
 	if err != nil {
 		t.Log("Error updating database: %s", err)
@@ -2917,10 +3191,59 @@ func (taskContext *TaskContext) performImportVPCTask(importConfig *database.Impo
 		return
 	}
 
+	taskContext.recordEgressIPs(ctx, vpc)
+
 	t.Log("Successfully imported VPC %s", importConfig.VPCID)
 	setStatus(t, database.TaskStatusSuccessful)
 }
 
+// importIPv6State records an existing IPv6 block, egress-only internet gateway
+// and subnet /64s in the state. If the VPC has no IPv6 block, one is only
+// associated when ipv6Config is given; the networking task assigns the /64s.
+func importIPv6State(ctx *awsp.Context, vpc *database.VPC, vpcResp *ec2.Vpc, ipv6Config *database.IPv6Config) error {
+	cidr, _ := awsp.AssociatedIPv6CIDRBlock(vpcResp)
+	if cidr == "" && ipv6Config == nil {
+		return nil
+	}
+	if vpc.State.VPCType.HasFirewall() {
+		return fmt.Errorf("IPv6 is not supported for VPCs with a network firewall")
+	}
+	vpc.State.IPv6 = &database.IPv6Info{
+		AssociatedCIDRBlock: cidr,
+	}
+	if ipv6Config != nil {
+		vpc.State.IPv6.IPv6Config = *ipv6Config
+	}
+	if cidr == "" {
+		var err error
+		vpc.State.IPv6.AssociatedCIDRBlock, _, err = ctx.AddIPv6CIDRBlock(ipv6Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	eigw, err := ctx.GetAttachedEgressOnlyInternetGateway()
+	if err != nil {
+		return err
+	}
+	if eigw != nil {
+		vpc.State.IPv6.EgressOnlyInternetGatewayID = aws.StringValue(eigw.EgressOnlyInternetGatewayId)
+	}
+
+	cidrsBySubnetID, err := ctx.GetSubnetIPv6CIDRs()
+	if err != nil {
+		return err
+	}
+	for _, az := range vpc.State.AvailabilityZones {
+		for _, subnets := range az.Subnets {
+			for _, subnet := range subnets {
+				subnet.IPv6CIDR = cidrsBySubnetID[subnet.SubnetID]
+			}
+		}
+	}
+	return nil
+}
+
 func (taskContext *TaskContext) performEstablishExceptionVPCTask(importConfig *database.EstablishExceptionVPCTaskData) {
 	t := taskContext.Task
 	awsAccountAccess := taskContext.BaseAWSAccountAccess
@@ -3095,6 +3418,9 @@ func (taskContext *TaskContext) performVerifyVPCTask(verifyConfig *database.Veri
 		setStatus(t, database.TaskStatusFailed)
 		return
 	}
+	if verifyConfig.Spec.VerifyNetworking {
+		taskContext.recordEgressIPs(ctx, vpc)
+	}
 
 	t.Log("Verification successful")
 	setStatus(t, database.TaskStatusSuccessful)
@@ -3180,7 +3506,7 @@ func (taskContext *TaskContext) performCreateVPCTask(taskConfig *database.Create
 			notification = &orchestration.NewVPCNotification{
 				VPCID:     vpcID,
 				Region:    taskConfig.AWSRegion,
-				JIRAIssue: jira.JiraURL(taskConfig.JIRAIssueForComment),
+				JIRAIssue: taskContext.issueURL(taskConfig.JIRAIssueForComment),
 			}
 		}
 		t.Log("Notifying orchestration engine of new CIDRs")
@@ -3222,6 +3548,10 @@ func (taskContext *TaskContext) createVPC(taskConfig *database.CreateVPCTaskData
 		return "", fmt.Errorf("There must be at least as many public subnets as private subnets.\n")
 	}
 
+	if taskConfig.IPv6 != nil && taskConfig.AddFirewall {
+		return "", fmt.Errorf("IPv6 is not supported for VPCs with a network firewall.\n")
+	}
+
 	ctx := &ipcontrol.Context{
 		LockSet:        lockSet,
 		IPAM:           taskContext.IPAM,
@@ -3240,6 +3570,7 @@ func (taskContext *TaskContext) createVPC(taskConfig *database.CreateVPCTaskData
 	} else {
 		ctx.VPCInfo.Tenancy = "default"
 	}
+	ctx.VPCInfo.IPv6 = taskConfig.IPv6
 
 	// Create resources in AWS
 	err = awsctx.CreateVPC(&ctx.VPCInfo)
@@ -3300,6 +3631,12 @@ func (taskContext *TaskContext) createVPC(taskConfig *database.CreateVPCTaskData
 	if taskConfig.AddFirewall {
 		vpc.State.VPCType = database.VPCTypeV1Firewall
 	}
+	if taskConfig.IPv6 != nil {
+		vpc.State.IPv6 = &database.IPv6Info{
+			IPv6Config:          *taskConfig.IPv6,
+			AssociatedCIDRBlock: ctx.VPCInfo.IPv6CIDRBlock,
+		}
+	}
 	vpc.State.AvailabilityZones = map[string]*database.AvailabilityZoneInfra{}
 	vpc.State.RouteTables = map[string]*database.RouteTableInfo{}
 	for _, subnet := range ctx.VPCInfo.NewSubnets {
@@ -3309,6 +3646,7 @@ func (taskContext *TaskContext) createVPC(taskConfig *database.CreateVPCTaskData
 			&database.SubnetInfo{
 				SubnetID:  subnet.ResourceID,
 				GroupName: strings.ToLower(string(subnet.Type)),
+				IPv6CIDR:  subnet.IPv6CIDR,
 			},
 		)
 	}
@@ -3410,8 +3748,52 @@ func flowLogS3Destinations(accountID string, region database.Region) []string {
 	}
 }
 
+// How long firewall alert logs are kept in CloudWatch Logs
+const firewallAlertLogRetentionDays = 365
+
+// desiredFirewallLogDestinations returns where the VPC's firewall should send
+// its logs. Alerts always go to CloudWatch Logs. Flow logs go to the central
+// logging bucket, but only once traffic is fully routed through the firewall.
+func desiredFirewallLogDestinations(vpc *database.VPC) []*database.FirewallLogDestination {
+	dests := []*database.FirewallLogDestination{
+		{
+			LogType:         networkfirewall.LogTypeAlert,
+			DestinationType: networkfirewall.LogDestinationTypeCloudWatchLogs,
+			Destination: map[string]string{
+				logDestinationLogGroup: firewallAlertLogName(vpc.ID),
+			},
+			RetentionInDays: firewallAlertLogRetentionDays,
+		},
+	}
+	if vpc.State.VPCType == database.VPCTypeV1Firewall {
+		dests = append(dests, &database.FirewallLogDestination{
+			LogType:         networkfirewall.LogTypeFlow,
+			DestinationType: networkfirewall.LogDestinationTypeS3,
+			Destination: map[string]string{
+				"bucketName": fmt.Sprintf("cms-cloud-%s-%s", vpc.AccountID, vpc.Region),
+				"prefix":     fmt.Sprintf("firewall/%s", vpc.ID),
+			},
+		})
+	}
+	return dests
+}
+
+// sameFirewallLogging compares destinations and, for CloudWatch Logs,
+// retention.
+func sameFirewallLogging(a, b []*database.FirewallLogDestination) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if !awsp.SameFirewallLogDestination(a[idx], b[idx]) || a[idx].RetentionInDays != b[idx].RetentionInDays {
+			return false
+		}
+	}
+	return true
+}
+
 // The desired flow log format
-const flowLogFormat = "${version} ${account-id} ${action} ${bytes} ${dstaddr} ${dstport} ${end} ${instance-id} ${interface-id} ${log-status} ${packets} ${pkt-dstaddr} ${pkt-srcaddr} ${protocol} ${srcaddr} ${srcport} ${start} ${subnet-id} ${tcp-flags} ${type} ${version} ${vpc-id} ${az-id} ${flow-direction} ${pkt-dst-aws-service} ${pkt-src-aws-service} ${region} ${sublocation-id} ${sublocation-type} ${traffic-path} ${type}"
+const flowLogFormat = flowlogs.Format
 
 // Where to send VPC query logs
 const cloudwatchQueryLogDestination = "cms-cloud-vpc-querylogs"
@@ -3505,6 +3887,14 @@ func (taskContext *TaskContext) performDeleteVPCTask(taskData *database.DeleteVP
 		setStatus(t, database.TaskStatusFailed)
 		return
 	}
+	if vpc.State.IPv6 != nil && vpc.State.IPv6.EgressOnlyInternetGatewayID != "" {
+		err = ctx.DeleteEgressOnlyInternetGateway(vpc.State.IPv6.EgressOnlyInternetGatewayID)
+		if err != nil {
+			t.Log("Error deleting Egress-Only Internet Gateway: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
 	err = ctx.DeleteAllTransitGatewayVPCAttachments()
 	if err != nil {
 		t.Log("Error deleting Transit Gateway Attachments: %s", err)
@@ -3572,6 +3962,11 @@ func (taskContext *TaskContext) performDeleteVPCTask(taskData *database.DeleteVP
 		return
 	}
 
+	err = taskContext.clearEgressIPs(t, taskData.Region, taskData.VPCID)
+	if err != nil {
+		t.Log("Error updating egress IP inventory: %s", err)
+	}
+
 	err = taskContext.ModelsManager.UpdateVPCConfig(taskData.Region, taskData.VPCID, database.VPCConfig{})
 	if err != nil {
 		t.Log("Error clearing VPC %s config: %s", taskData.VPCID, err)
@@ -3601,10 +3996,13 @@ func (taskContext *TaskContext) performDeleteVPCTask(taskData *database.DeleteVP
 // If desired is nil then any existing route for the destination will be deleted.
 func setRoute(ctx *awsp.Context, routeTableID, destination string, current []*database.RouteInfo, desired *database.RouteInfo) ([]*database.RouteInfo, error) {
 	var destinationCIDR *string
+	var destinationIPv6CIDR *string
 	var destinationPLID *string
 
 	if awsp.IsPrefixListID(destination) {
 		destinationPLID = &destination
+	} else if awsp.IsIPv6CIDR(destination) {
+		destinationIPv6CIDR = &destination
 	} else {
 		destinationCIDR = &destination
 	}
@@ -3626,9 +4024,10 @@ func setRoute(ctx *awsp.Context, routeTableID, destination string, current []*da
 				current = append(current[:idx], current[idx+1:]...)
 				ctx.Log("Deleting route for %s on route table %s", destination, routeTableID)
 				_, err := ctx.EC2().DeleteRoute(&ec2.DeleteRouteInput{
-					RouteTableId:            &routeTableID,
-					DestinationCidrBlock:    destinationCIDR,
-					DestinationPrefixListId: destinationPLID,
+					RouteTableId:             &routeTableID,
+					DestinationCidrBlock:     destinationCIDR,
+					DestinationIpv6CidrBlock: destinationIPv6CIDR,
+					DestinationPrefixListId:  destinationPLID,
 				})
 				if err != nil {
 					if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "InvalidRoute.NotFound" { // this is an okay error - route shouldn't exist when we're through
@@ -3637,21 +4036,25 @@ func setRoute(ctx *awsp.Context, routeTableID, destination string, current []*da
 					}
 					return nil, err
 				}
-			} else if info.InternetGatewayID != desired.InternetGatewayID || info.NATGatewayID != desired.NATGatewayID || info.TransitGatewayID != desired.TransitGatewayID || info.PeeringConnectionID != desired.PeeringConnectionID || info.VPCEndpointID != desired.VPCEndpointID {
-				ctx.Log("Updating route %s -> %s%s%s%s%s on route table %s", destination, desired.NATGatewayID, desired.InternetGatewayID, desired.TransitGatewayID, desired.PeeringConnectionID, desired.VPCEndpointID, routeTableID)
+			} else if info.InternetGatewayID != desired.InternetGatewayID || info.EgressOnlyInternetGatewayID != desired.EgressOnlyInternetGatewayID || info.NATGatewayID != desired.NATGatewayID || info.TransitGatewayID != desired.TransitGatewayID || info.PeeringConnectionID != desired.PeeringConnectionID || info.VPCEndpointID != desired.VPCEndpointID {
+				ctx.Log("Updating route %s -> %s%s%s%s%s%s on route table %s", destination, desired.NATGatewayID, desired.InternetGatewayID, desired.EgressOnlyInternetGatewayID, desired.TransitGatewayID, desired.PeeringConnectionID, desired.VPCEndpointID, routeTableID)
 				input := &ec2.ReplaceRouteInput{
-					RouteTableId:            &routeTableID,
-					DestinationCidrBlock:    destinationCIDR,
-					DestinationPrefixListId: destinationPLID,
-					NatGatewayId:            &desired.NATGatewayID,
-					GatewayId:               &desired.InternetGatewayID,
-					TransitGatewayId:        &desired.TransitGatewayID,
-					VpcPeeringConnectionId:  &desired.PeeringConnectionID,
-				}
-				// AWS errors if this field is specified in input but empty
+					RouteTableId:             &routeTableID,
+					DestinationCidrBlock:     destinationCIDR,
+					DestinationIpv6CidrBlock: destinationIPv6CIDR,
+					DestinationPrefixListId:  destinationPLID,
+					NatGatewayId:             &desired.NATGatewayID,
+					GatewayId:                &desired.InternetGatewayID,
+					TransitGatewayId:         &desired.TransitGatewayID,
+					VpcPeeringConnectionId:   &desired.PeeringConnectionID,
+				}
+				// AWS errors if these fields are specified in input but empty
 				if desired.VPCEndpointID != "" {
 					input.VpcEndpointId = &desired.VPCEndpointID
 				}
+				if desired.EgressOnlyInternetGatewayID != "" {
+					input.EgressOnlyInternetGatewayId = &desired.EgressOnlyInternetGatewayID
+				}
 				_, err := ctx.EC2().ReplaceRoute(input)
 				if err != nil {
 					return nil, err
@@ -3665,20 +4068,24 @@ func setRoute(ctx *awsp.Context, routeTableID, destination string, current []*da
 		if foundInAWS {
 			// a route with the desired destination exists already, but it's not in VPC Conf state.
 			// This happens when we are setting routes for firewall endpoints on the IGW route table and the desired destination matches an existing 'local' route that AWS creates by default when creating the route table
-			ctx.Log("Updating route %s -> %s%s%s%s%s on route table %s", destination, desired.NATGatewayID, desired.InternetGatewayID, desired.TransitGatewayID, desired.PeeringConnectionID, desired.VPCEndpointID, routeTableID)
+			ctx.Log("Updating route %s -> %s%s%s%s%s%s on route table %s", destination, desired.NATGatewayID, desired.InternetGatewayID, desired.EgressOnlyInternetGatewayID, desired.TransitGatewayID, desired.PeeringConnectionID, desired.VPCEndpointID, routeTableID)
 			input := &ec2.ReplaceRouteInput{
-				RouteTableId:            &routeTableID,
-				DestinationCidrBlock:    destinationCIDR,
-				DestinationPrefixListId: destinationPLID,
-				NatGatewayId:            &desired.NATGatewayID,
-				GatewayId:               &desired.InternetGatewayID,
-				TransitGatewayId:        &desired.TransitGatewayID,
-				VpcPeeringConnectionId:  &desired.PeeringConnectionID,
-			}
-			// AWS errors if this field is specified in input but empty
+				RouteTableId:             &routeTableID,
+				DestinationCidrBlock:     destinationCIDR,
+				DestinationIpv6CidrBlock: destinationIPv6CIDR,
+				DestinationPrefixListId:  destinationPLID,
+				NatGatewayId:             &desired.NATGatewayID,
+				GatewayId:                &desired.InternetGatewayID,
+				TransitGatewayId:         &desired.TransitGatewayID,
+				VpcPeeringConnectionId:   &desired.PeeringConnectionID,
+			}
+			// AWS errors if these fields are specified in input but empty
 			if desired.VPCEndpointID != "" {
 				input.VpcEndpointId = &desired.VPCEndpointID
 			}
+			if desired.EgressOnlyInternetGatewayID != "" {
+				input.EgressOnlyInternetGatewayId = &desired.EgressOnlyInternetGatewayID
+			}
 			// AWS errors if these fields are empty string
 			if desired.NATGatewayID == "" {
 				input.NatGatewayId = nil
@@ -3698,20 +4105,24 @@ func setRoute(ctx *awsp.Context, routeTableID, destination string, current []*da
 				return nil, err
 			}
 		} else {
-			ctx.Log("Creating route %s -> %s%s%s%s%s on route table %s", destination, desired.NATGatewayID, desired.InternetGatewayID, desired.TransitGatewayID, desired.PeeringConnectionID, desired.VPCEndpointID, routeTableID)
+			ctx.Log("Creating route %s -> %s%s%s%s%s%s on route table %s", destination, desired.NATGatewayID, desired.InternetGatewayID, desired.EgressOnlyInternetGatewayID, desired.TransitGatewayID, desired.PeeringConnectionID, desired.VPCEndpointID, routeTableID)
 			input := &ec2.CreateRouteInput{
-				RouteTableId:            &routeTableID,
-				DestinationCidrBlock:    destinationCIDR,
-				DestinationPrefixListId: destinationPLID,
-				NatGatewayId:            &desired.NATGatewayID,
-				GatewayId:               &desired.InternetGatewayID,
-				TransitGatewayId:        &desired.TransitGatewayID,
-				VpcPeeringConnectionId:  &desired.PeeringConnectionID,
-			}
-			// AWS errors if this field is specified in input but empty
+				RouteTableId:             &routeTableID,
+				DestinationCidrBlock:     destinationCIDR,
+				DestinationIpv6CidrBlock: destinationIPv6CIDR,
+				DestinationPrefixListId:  destinationPLID,
+				NatGatewayId:             &desired.NATGatewayID,
+				GatewayId:                &desired.InternetGatewayID,
+				TransitGatewayId:         &desired.TransitGatewayID,
+				VpcPeeringConnectionId:   &desired.PeeringConnectionID,
+			}
+			// AWS errors if these fields are specified in input but empty
 			if desired.VPCEndpointID != "" {
 				input.VpcEndpointId = &desired.VPCEndpointID
 			}
+			if desired.EgressOnlyInternetGatewayID != "" {
+				input.EgressOnlyInternetGatewayId = &desired.EgressOnlyInternetGatewayID
+			}
 			// AWS errors if these fields are empty string
 			if desired.NATGatewayID == "" {
 				input.NatGatewayId = nil
@@ -3793,8 +4204,54 @@ type peeringConnection struct {
 	OtherVPCCIDRs []string
 }
 
-func handlePeeringConnections(
-	lockSet database.LockSet,
+// deletePeeringConnectionRoutes deletes all routes for the given peering connection
+func deletePeeringConnectionRoutes(ctx *awsp.Context, vpc *database.VPC, vpcWriter database.VPCWriter, pcxID string) error {
+	for _, az := range vpc.State.AvailabilityZones {
+		for subnetType, subnets := range az.Subnets {
+			for _, subnet := range subnets {
+				var rtID string
+
+				if subnet.CustomRouteTableID != "" {
+					rtID = subnet.CustomRouteTableID
+				} else if subnetType == database.SubnetTypePublic {
+					if vpc.State.VPCType.HasFirewall() {
+						rtID = az.PublicRouteTableID
+					} else {
+						rtID = vpc.State.PublicRouteTableID
+					}
+				} else {
+					rtID = az.PrivateRouteTableID
+				}
+				if rtID == "" {
+					return fmt.Errorf("No route table for subnet %s", subnet.SubnetID)
+				}
+
+				rt, ok := vpc.State.RouteTables[rtID]
+				if !ok {
+					return fmt.Errorf("Route table %s missing from state", rtID)
+				}
+				existingRoutes := append([]*database.RouteInfo{}, rt.Routes...) // copy
+				for _, route := range existingRoutes {
+					if route.PeeringConnectionID == pcxID {
+						var err error
+						rt.Routes, err = setRoute(ctx, rtID, route.Destination, rt.Routes, nil)
+						if err != nil {
+							return fmt.Errorf("Error updating route table %s: %s", rtID, err)
+						}
+						err = vpcWriter.UpdateState(vpc.State)
+						if err != nil {
+							return fmt.Errorf("Error updating state: %s", err)
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func handlePeeringConnections(
+	lockSet database.LockSet,
 	ctx *awsp.Context,
 	vpc *database.VPC,
 	vpcWriter database.VPCWriter,
@@ -3832,51 +4289,6 @@ func handlePeeringConnections(
 		}
 	}
 	// Delete peering connections in state that are no longer configured
-	deleteRoutes := func(ctx *awsp.Context, vpc *database.VPC, vpcWriter database.VPCWriter, pcxID string) error {
-		// Delete all routes for the given peering connection
-		for _, az := range vpc.State.AvailabilityZones {
-			for subnetType, subnets := range az.Subnets {
-				for _, subnet := range subnets {
-					var rtID string
-
-					if subnet.CustomRouteTableID != "" {
-						rtID = subnet.CustomRouteTableID
-					} else if subnetType == database.SubnetTypePublic {
-						if vpc.State.VPCType.HasFirewall() {
-							rtID = az.PublicRouteTableID
-						} else {
-							rtID = vpc.State.PublicRouteTableID
-						}
-					} else {
-						rtID = az.PrivateRouteTableID
-					}
-					if rtID == "" {
-						return fmt.Errorf("No route table for subnet %s", subnet.SubnetID)
-					}
-
-					rt, ok := vpc.State.RouteTables[rtID]
-					if !ok {
-						return fmt.Errorf("Route table %s missing from state", rtID)
-					}
-					existingRoutes := append([]*database.RouteInfo{}, rt.Routes...) // copy
-					for _, route := range existingRoutes {
-						if route.PeeringConnectionID == pcxID {
-							var err error
-							rt.Routes, err = setRoute(ctx, rtID, route.Destination, rt.Routes, nil)
-							if err != nil {
-								return fmt.Errorf("Error updating route table %s: %s", rtID, err)
-							}
-							err = vpcWriter.UpdateState(vpc.State)
-							if err != nil {
-								return fmt.Errorf("Error updating state: %s", err)
-							}
-						}
-					}
-				}
-			}
-		}
-		return nil
-	}
 	keepPeeringConnections := []*peeringConnection{}
 	for _, pc := range peeringConnections {
 		var err error
@@ -3901,12 +4313,12 @@ func handlePeeringConnections(
 			keepPeeringConnections = append(keepPeeringConnections, pc)
 		} else if pc.State.PeeringConnectionID != "" {
 			// Delete any routes to the peering connection
-			err := deleteRoutes(ctx, vpc, vpcWriter, pc.State.PeeringConnectionID)
+			err := deletePeeringConnectionRoutes(ctx, vpc, vpcWriter, pc.State.PeeringConnectionID)
 			if err != nil {
 				ctx.Log("Error deleting route to %s: %s", pc.State.PeeringConnectionID, err)
 				continue
 			}
-			err = deleteRoutes(pc.OtherCTX, pc.OtherVPC, pc.OtherVPCWriter, pc.State.PeeringConnectionID)
+			err = deletePeeringConnectionRoutes(pc.OtherCTX, pc.OtherVPC, pc.OtherVPCWriter, pc.State.PeeringConnectionID)
 			if err != nil {
 				ctx.Log("Error deleting route to %s: %s", pc.State.PeeringConnectionID, err)
 				continue
@@ -4082,6 +4494,68 @@ func handlePeeringConnections(
 	return peeringConnections, nil
 }
 
+// shareTransitGateway adds accountID to the resource share of the transit
+// gateway if it is owned by another account. It returns the share, which is
+// nil if none is configured, and EC2 for the account that owns it.
+func shareTransitGateway(
+	ctx *awsp.Context,
+	modelsManager database.ModelsManager,
+	region database.Region,
+	tgID, accountID string,
+	getAccountCredentials func(accountID string) (ec2iface.EC2API, ramiface.RAMAPI, error)) (*database.TransitGatewayResourceShare, ec2iface.EC2API, error) {
+
+	share, err := modelsManager.GetTransitGatewayResourceShare(region, tgID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error checking share info: %s", err)
+	}
+	if share == nil {
+		ctx.Log("WARNING: no Resource Share ID specified for Transit Gateway %s. Will only be able to attach this transit gateway if it is from the same account or already shared", tgID)
+		return nil, nil, nil
+	}
+	shareEC2, sourceRAM, err := getAccountCredentials(share.AccountID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error getting AWS credentials for share account: %s", err)
+	}
+	if share.AccountID != accountID {
+		shareARN := resourceShareARN(string(region), share.AccountID, share.ResourceShareID)
+		ctx.Log("Checking principal list on share %s in account %s", share.ResourceShareID, share.AccountID)
+		err = ctx.EnsurePrincipalOnShare(sourceRAM, accountID, shareARN)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return share, shareEC2, nil
+}
+
+// acceptTransitGatewayAttachment waits for a new attachment to settle and, if
+// the transit gateway doesn't accept attachments automatically, accepts it as
+// the owner of the share.
+func acceptTransitGatewayAttachment(ctx *awsp.Context, tgID, attachmentID string, share *database.TransitGatewayResourceShare, shareEC2 ec2iface.EC2API) error {
+	state, err := ctx.WaitForTransitGatewayVpcAttachmentStatus(attachmentID, []string{"available", "pendingAcceptance"}, []string{"pending"})
+	if err != nil {
+		return fmt.Errorf("Error waiting for transit gateway attachment status: %s", err)
+	}
+	if state == "pendingAcceptance" {
+		if share == nil {
+			return fmt.Errorf("Transit Gateway %s does not automatically accept attachment and no Resource Share is specified. Specify a Resource Share or manually accept the Attachment and retry.", tgID)
+		}
+		// Accept using owner's EC2 credentials
+		ctx.Log("Accepting Transit Gateway VPC Attachment %s", attachmentID)
+		_, err := shareEC2.AcceptTransitGatewayVpcAttachment(&ec2.AcceptTransitGatewayVpcAttachmentInput{
+			TransitGatewayAttachmentId: &attachmentID,
+		})
+		if err != nil {
+			return fmt.Errorf("Error accepting attachment: %s", err)
+		}
+		ctx.Log("Waiting for transit gateway attachment to become available")
+		_, err = ctx.WaitForTransitGatewayVpcAttachmentStatus(attachmentID, []string{"available"}, []string{"pending"})
+		if err != nil {
+			return fmt.Errorf("Error waiting for transit gateway attachment status: %s", err)
+		}
+	}
+	return nil
+}
+
 func handleTransitGatewayAttachments(
 	ctx *awsp.Context,
 	vpc *database.VPC,
@@ -4174,28 +4648,9 @@ func handleTransitGatewayAttachments(
 
 	// Now create or update all the managed transit gateway attachments.
 	for tgID, managedIDs := range managedIDsByTGID {
-		share, err := modelsManager.GetTransitGatewayResourceShare(networkConfig.AWSRegion, tgID)
+		share, shareEC2, err := shareTransitGateway(ctx, modelsManager, networkConfig.AWSRegion, tgID, vpc.AccountID, getAccountCredentials)
 		if err != nil {
-			return fmt.Errorf("Error checking share info: %s", err)
-		}
-		var shareEC2 ec2iface.EC2API
-		var sourceRAM ramiface.RAMAPI
-		if share == nil {
-			ctx.Log("WARNING: no Resource Share ID specified for Transit Gateway %s. Will only be able to attach this transit gateway if it is from the same account or already shared", tgID)
-		} else {
-			shareEC2, sourceRAM, err = getAccountCredentials(share.AccountID)
-			if err != nil {
-				return fmt.Errorf("Error getting AWS credentials for share account: %s", err)
-			}
-		}
-		// First check to see if we need to share it.
-		if share != nil && share.AccountID != vpc.AccountID {
-			shareARN := resourceShareARN(string(networkConfig.AWSRegion), share.AccountID, share.ResourceShareID)
-			ctx.Log("Checking principal list on share %s in account %s", share.ResourceShareID, share.AccountID)
-			err = ctx.EnsurePrincipalOnShare(sourceRAM, vpc.AccountID, shareARN)
-			if err != nil {
-				return err
-			}
+			return err
 		}
 		// Transit gateway should live in one subnet per AZ
 		transitGatewaySubnetIDs := []string{}
@@ -4252,6 +4707,25 @@ func handleTransitGatewayAttachments(
 				ctx.Log("Waiting for transit gateway attachment to become available")
 				ctx.WaitForTransitGatewayVpcAttachmentStatus(attachment.TransitGatewayAttachmentID, []string{"available"}, []string{"modifying"})
 			}
+			if vpc.State.IPv6 != nil && !attachment.IsIPv6Enabled {
+				ctx.Log("Enabling IPv6 support on transit gateway attachment %s", attachment.TransitGatewayAttachmentID)
+				_, err := ctx.EC2().ModifyTransitGatewayVpcAttachment(&ec2.ModifyTransitGatewayVpcAttachmentInput{
+					TransitGatewayAttachmentId: &attachment.TransitGatewayAttachmentID,
+					Options: &ec2.ModifyTransitGatewayVpcAttachmentRequestOptions{
+						Ipv6Support: aws.String(ec2.Ipv6SupportValueEnable),
+					},
+				})
+				if err != nil {
+					return fmt.Errorf("Error enabling IPv6 support on transit gateway attachment: %s", err)
+				}
+				attachment.IsIPv6Enabled = true
+				err = vpcWriter.UpdateState(vpc.State)
+				if err != nil {
+					return fmt.Errorf("Error updating state: %s", err)
+				}
+				ctx.Log("Waiting for transit gateway attachment to become available")
+				ctx.WaitForTransitGatewayVpcAttachmentStatus(attachment.TransitGatewayAttachmentID, []string{"available"}, []string{"modifying"})
+			}
 		} else {
 			// If we just shared it we might need to wait for it to appear
 			ctx.Log("Waiting for transit gateway to be available")
@@ -4263,10 +4737,11 @@ func handleTransitGatewayAttachments(
 				ManagedTransitGatewayAttachmentIDs: managedIDs,
 				TransitGatewayID:                   tgID,
 				SubnetIDs:                          transitGatewaySubnetIDs,
+				IsIPv6Enabled:                      vpc.State.IPv6 != nil,
 			}
 
 			maName := generateMTGAName(managedIDs, managedAttachmentsByID)
-			attachment.TransitGatewayAttachmentID, err = ctx.CreateTransitGatewayVPCAttachment(transitGatewayAttachmentName(vpc.Name, maName), tgID, transitGatewaySubnetIDs)
+			attachment.TransitGatewayAttachmentID, err = ctx.CreateTransitGatewayVPCAttachment(transitGatewayAttachmentName(vpc.Name, maName), tgID, transitGatewaySubnetIDs, attachment.IsIPv6Enabled)
 			if err != nil {
 				return fmt.Errorf("Error creating transit gateway attachment %s: %s", transitGatewayAttachmentName(vpc.Name, maName), err)
 			}
@@ -4281,26 +4756,21 @@ func handleTransitGatewayAttachments(
 				return fmt.Errorf("Error updating tags on transit gateway attachment %s: %s", attachment.TransitGatewayAttachmentID, err)
 			}
 		}
-		state, err := ctx.WaitForTransitGatewayVpcAttachmentStatus(attachment.TransitGatewayAttachmentID, []string{"available", "pendingAcceptance"}, []string{"pending"})
+		err = acceptTransitGatewayAttachment(ctx, tgID, attachment.TransitGatewayAttachmentID, share, shareEC2)
 		if err != nil {
-			return fmt.Errorf("Error waiting for transit gateway attachment status: %s", err)
+			return err
+		}
+		routing, err := desiredTransitGatewayRouting(managedIDs, managedAttachmentsByID)
+		if err != nil {
+			return err
 		}
-		if state == "pendingAcceptance" {
+		if routing.isManaged(attachment) {
 			if share == nil {
-				return fmt.Errorf("Transit Gateway %s does not automatically accept attachment and no Resource Share is specified. Specify a Resource Share or manually accept the Attachment and retry.", tgID)
-			}
-			// Accept using owner's EC2 credentials
-			ctx.Log("Accepting Transit Gateway VPC Attachment %s", attachment.TransitGatewayAttachmentID)
-			_, err := shareEC2.AcceptTransitGatewayVpcAttachment(&ec2.AcceptTransitGatewayVpcAttachmentInput{
-				TransitGatewayAttachmentId: &attachment.TransitGatewayAttachmentID,
-			})
-			if err != nil {
-				return fmt.Errorf("Error accepting attachment: %s", err)
+				return fmt.Errorf("Transit Gateway %s has no Resource Share so its route tables cannot be managed. Specify a Resource Share and retry.", tgID)
 			}
-			ctx.Log("Waiting for transit gateway attachment to become available")
-			_, err = ctx.WaitForTransitGatewayVpcAttachmentStatus(attachment.TransitGatewayAttachmentID, []string{"available"}, []string{"pending"})
+			err = updateTransitGatewayRouting(ctx, shareEC2, attachment, routing, vpcWriter, vpc)
 			if err != nil {
-				return fmt.Errorf("Error waiting for transit gateway attachment status: %s", err)
+				return fmt.Errorf("Error updating transit gateway route tables: %s", err)
 			}
 		}
 	}
@@ -4606,25 +5076,16 @@ func updatePeeringConnectionRoutesForSubnet(
 	return nil
 }
 
-func updateTransitGatewayRoutesForSubnet(
-	ctx *awsp.Context,
-	vpc *database.VPC,
-	vpcWriter database.VPCWriter,
-	networkConfig *database.UpdateNetworkingTaskData,
+// transitGatewayRoutesBySubnetType returns TG ID --> subnet type --> route
+// for the given attachments and VPN connections.
+func transitGatewayRoutesBySubnetType(
+	managedAttachmentIDs []uint64,
 	managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment,
-	routeTable *database.RouteTableInfo,
-	subnetType database.SubnetType,
-	region database.Region,
-	getPrefixListRAM func(region database.Region) (ramiface.RAMAPI, error)) error {
-	deleteRoutes := []string{}
-	subnetTypesAndRoutesByTGID := make(map[string]map[database.SubnetType]map[string]struct{}) // TG ID --> subnet type --> route --> struct{}
-
-	if subnetType == database.SubnetTypeUnroutable || subnetType == database.SubnetTypeFirewall {
-		return nil
-	}
+	vpnConnections []*database.VPNConnection) map[string]map[database.SubnetType]map[string]struct{} {
+	subnetTypesAndRoutesByTGID := make(map[string]map[database.SubnetType]map[string]struct{})
 
 	// Get the union of routes by subnet type for MTGAs that share a TG ID
-	for _, managedID := range networkConfig.ManagedTransitGatewayAttachmentIDs {
+	for _, managedID := range managedAttachmentIDs {
 		ma := managedAttachmentsByID[managedID]
 		for _, t := range ma.SubnetTypes {
 			if subnetTypesAndRoutesByTGID[ma.TransitGatewayID] == nil {
@@ -4638,6 +5099,41 @@ func updateTransitGatewayRoutesForSubnet(
 			}
 		}
 	}
+	// VPN connections route on-prem CIDRs through a TG the VPC is already attached to
+	for _, vpn := range vpnConnections {
+		for _, t := range vpn.SubnetTypes {
+			if subnetTypesAndRoutesByTGID[vpn.TransitGatewayID] == nil {
+				subnetTypesAndRoutesByTGID[vpn.TransitGatewayID] = make(map[database.SubnetType]map[string]struct{})
+			}
+			if subnetTypesAndRoutesByTGID[vpn.TransitGatewayID][t] == nil {
+				subnetTypesAndRoutesByTGID[vpn.TransitGatewayID][t] = make(map[string]struct{})
+			}
+			for _, route := range vpn.Routes {
+				subnetTypesAndRoutesByTGID[vpn.TransitGatewayID][t][route] = struct{}{}
+			}
+		}
+	}
+	return subnetTypesAndRoutesByTGID
+}
+
+func updateTransitGatewayRoutesForSubnet(
+	ctx *awsp.Context,
+	vpc *database.VPC,
+	vpcWriter database.VPCWriter,
+	networkConfig *database.UpdateNetworkingTaskData,
+	managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment,
+	vpnConnections []*database.VPNConnection,
+	routeTable *database.RouteTableInfo,
+	subnetType database.SubnetType,
+	region database.Region,
+	getPrefixListRAM func(region database.Region) (ramiface.RAMAPI, error)) error {
+	deleteRoutes := []string{}
+
+	if subnetType == database.SubnetTypeUnroutable || subnetType == database.SubnetTypeFirewall {
+		return nil
+	}
+
+	subnetTypesAndRoutesByTGID := transitGatewayRoutesBySubnetType(networkConfig.ManagedTransitGatewayAttachmentIDs, managedAttachmentsByID, vpnConnections)
 
 	for _, managedID := range networkConfig.ManagedTransitGatewayAttachmentIDs {
 		ma := managedAttachmentsByID[managedID]
@@ -4648,6 +5144,11 @@ func updateTransitGatewayRoutesForSubnet(
 			for route := range routes {
 				var err error
 
+				if awsp.IsIPv6CIDR(route) && vpc.State.IPv6 == nil {
+					ctx.Log("Skipping IPv6 route %s because VPC %s is not dual-stack", route, vpc.ID)
+					continue
+				}
+
 				if awsp.IsPrefixListID(route) {
 					plRAM, err := getPrefixListRAM(region)
 					if err != nil {
@@ -4919,53 +5420,45 @@ func (taskContext *TaskContext) performUpdateLoggingTask(networkConfig *database
 	var firewallLogFailed bool
 
 	if vpc.State.VPCType.HasFirewall() {
-		t.Log("Updating firewall alert logs")
-
-		firewallLogFailed = false
-
-		logGroupArn, err := ctx.CheckLogGroupExists(firewallAlertLogName(ctx.VPCID))
-		if err != nil {
-			t.Log("Error checking for firewall alert log group: %s", err)
-			return
-		}
+		t.Log("Updating firewall logs")
 
-		if logGroupArn == nil {
-			_, err := ctx.EnsureCloudWatchLogsGroupExists(firewallAlertLogName(ctx.VPCID))
+		desired := desiredFirewallLogDestinations(vpc)
+		for _, dest := range desired {
+			if dest.DestinationType != networkfirewall.LogDestinationTypeCloudWatchLogs {
+				continue
+			}
+			logGroup := dest.Destination[logDestinationLogGroup]
+			_, err := ctx.EnsureCloudWatchLogsGroupExists(logGroup)
+			if err != nil {
+				t.Log("Error creating CloudWatch Logs firewall log group: %s", err)
+				firewallLogFailed = true
+				break
+			}
+			retention, _, err := ctx.LogGroupRetention(logGroup)
+			if err == nil && retention != dest.RetentionInDays {
+				err = ctx.SetLogGroupRetention(logGroup, dest.RetentionInDays)
+			}
 			if err != nil {
-				t.Log("Error creating CloudWatch Logs firewall alert log group: %s", err)
+				t.Log("%s", err)
 				firewallLogFailed = true
+				break
 			}
 		}
 
 		if !firewallLogFailed && vpc.State.Firewall != nil {
-			_, err := ctx.NetworkFirewall().UpdateLoggingConfiguration(&networkfirewall.UpdateLoggingConfigurationInput{
-				FirewallName: aws.String(ctx.FirewallName()),
-				LoggingConfiguration: &networkfirewall.LoggingConfiguration{
-					LogDestinationConfigs: []*networkfirewall.LogDestinationConfig{
-						{
-							LogDestination: map[string]*string{
-								logDestinationLogGroup: aws.String(firewallAlertLogName(ctx.VPCID)),
-							},
-							LogDestinationType: aws.String(networkfirewall.LogDestinationTypeCloudWatchLogs),
-							LogType:            aws.String(networkfirewall.LogTypeAlert),
-						},
-					},
-				},
-			})
+			err := ctx.UpdateFirewallLogDestinations(desired)
 			if err != nil {
-				if aerr, ok := err.(awserr.Error); ok {
-					if aerr.Code() == networkfirewall.ErrCodeInvalidRequestException && aerr.Message() == awsp.NoLoggingConfigChanges {
-						// no-op, no changes to config
-					} else {
-						t.Log("Error updating firewall alert log configuration: %s", err)
-						firewallLogFailed = true
-					}
-				} else {
-					t.Log("Error updating firewall alert log configuration: %s", err)
-					firewallLogFailed = true
-				}
+				t.Log("%s", err)
+				firewallLogFailed = true
 			} else {
-				t.Log("Updated firewall alert log configuration")
+				vpc.State.Firewall.LogDestinations = desired
+				err = vpcWriter.UpdateState(vpc.State)
+				if err != nil {
+					t.Log("Error updating state: %s", err)
+					setStatus(t, database.TaskStatusFailed)
+					return
+				}
+				t.Log("Updated firewall log configuration")
 			}
 		}
 	}
@@ -4990,67 +5483,212 @@ func (taskContext *TaskContext) performUpdateLoggingTask(networkConfig *database
 	}
 }
 
-func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *database.UpdateNetworkingTaskData) {
-	t := taskContext.Task
-	awsAccountAccess := taskContext.BaseAWSAccountAccess
-	lockSet := taskContext.LockSet
-	asUser := taskContext.AsUser
-
-	setStatus(t, database.TaskStatusInProgress)
-	t.Log("Updating VPC networking")
+// ensureIPv6CIDRs associates the VPC's IPv6 block if it is missing and gives
+// every subnet in state that doesn't have one a /64 out of it.
+func ensureIPv6CIDRs(ctx *awsp.Context, vpc *database.VPC, vpcWriter database.VPCWriter) error {
+	if vpc.State.IPv6.AssociatedCIDRBlock == "" {
+		ctx.Log("Associating IPv6 CIDR block")
+		cidr, _, err := ctx.AddIPv6CIDRBlock(&vpc.State.IPv6.IPv6Config)
+		if err != nil {
+			return err
+		}
+		vpc.State.IPv6.AssociatedCIDRBlock = cidr
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			return fmt.Errorf("Error updating state: %s", err)
+		}
+	}
 
-	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, networkConfig.AWSRegion, networkConfig.VPCID)
+	inUseBySubnetID, err := ctx.GetSubnetIPv6CIDRs()
 	if err != nil {
-		t.Log("Error loading state: %s", err)
-		setStatus(t, database.TaskStatusFailed)
-		return
+		return err
 	}
-	if vpc.State == nil {
-		t.Log("VPC %s is not managed", vpc.ID)
-		setStatus(t, database.TaskStatusFailed)
-		return
+	inUse := []string{}
+	for _, cidr := range inUseBySubnetID {
+		inUse = append(inUse, cidr)
 	}
-	if vpc.State.VPCType == database.VPCTypeException {
-		t.Log("This is not allowed for Exception VPCs")
-		setStatus(t, database.TaskStatusFailed)
-		return
+	missing := []*database.SubnetInfo{}
+	for _, az := range vpc.State.AvailabilityZones.InOrder() {
+		for _, subnetType := range database.AllSubnetTypes() {
+			for _, subnet := range az.Subnets[subnetType] {
+				if cidr, ok := inUseBySubnetID[subnet.SubnetID]; ok {
+					subnet.IPv6CIDR = cidr
+				} else {
+					missing = append(missing, subnet)
+				}
+			}
+		}
 	}
-
-	ctx := &awsp.Context{
-		AWSAccountAccess: awsAccountAccess,
-		Logger:           t,
-		VPCID:            networkConfig.VPCID,
-		VPCName:          vpc.Name,
+	if len(missing) == 0 {
+		return nil
 	}
-
-	prefixListAccountID := prefixListAccountIDCommercial
-	if networkConfig.AWSRegion.IsGovCloud() {
-		prefixListAccountID = prefixListAccountIDGovCloud
+	cidrs, err := awsp.NextIPv6SubnetCIDRs(vpc.State.IPv6.AssociatedCIDRBlock, inUse, len(missing))
+	if err != nil {
+		return err
 	}
-
-	getPrefixListRAM := func(region database.Region) (ramiface.RAMAPI, error) {
-		access, err := taskContext.AWSAccountAccessProvider.AccessAccount(prefixListAccountID, string(region), asUser)
+	for idx, subnet := range missing {
+		err := ctx.AssociateSubnetIPv6CIDR(subnet.SubnetID, cidrs[idx])
 		if err != nil {
-			return nil, fmt.Errorf("Error getting credentials for account %s: %s", prefixListAccountID, err)
+			return err
 		}
-		plRAM := access.RAM()
-		return plRAM, nil
-	}
-
-	// Firewall resources
-
-	firewallTag := map[string]string{awsp.FirewallTypeKey: awsp.FirewallTypeValue}
-
-	if vpc.State.VPCType.HasFirewall() {
-		err = ctx.EnsureDefaultFirewallPolicyExists(vpc.Region)
+		subnet.IPv6CIDR = cidrs[idx]
+		err = vpcWriter.UpdateState(vpc.State)
 		if err != nil {
-			t.Log("Error ensuring default firewall policy exists: %s", err)
-			setStatus(t, database.TaskStatusFailed)
-			return
+			return fmt.Errorf("Error updating state: %s", err)
 		}
+	}
+	return nil
+}
 
-		firewallSubnetIDs := []string{}
-		for _, az := range vpc.State.AvailabilityZones.InOrder() {
+// updateIPv6InternetRoutes manages the ::/0 routes of a dual-stack VPC: public
+// route tables use the internet gateway and everything else uses an
+// egress-only internet gateway, which is created or deleted as needed.
+func updateIPv6InternetRoutes(ctx *awsp.Context, vpc *database.VPC, vpcWriter database.VPCWriter, networkConfig *database.UpdateNetworkingTaskData) error {
+	var err error
+	if vpc.State.PublicRouteTableID != "" {
+		publicRT, ok := vpc.State.RouteTables[vpc.State.PublicRouteTableID]
+		if !ok {
+			return fmt.Errorf("No public route table info found for %q", vpc.State.PublicRouteTableID)
+		}
+		var desired *database.RouteInfo
+		if networkConfig.ConnectPublic && vpc.State.InternetGateway.IsInternetGatewayAttached {
+			desired = &database.RouteInfo{
+				InternetGatewayID: vpc.State.InternetGateway.InternetGatewayID,
+			}
+		}
+		publicRT.Routes, err = setRoute(ctx, vpc.State.PublicRouteTableID, ipv6InternetRoute, publicRT.Routes, desired)
+		if err != nil {
+			return fmt.Errorf("Error updating public route table: %s", err)
+		}
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			return fmt.Errorf("Error updating state: %s", err)
+		}
+	}
+
+	if networkConfig.ConnectPrivate && vpc.State.IPv6.EgressOnlyInternetGatewayID == "" {
+		ctx.Log("Creating Egress-Only Internet Gateway")
+		vpc.State.IPv6.EgressOnlyInternetGatewayID, err = ctx.CreateEgressOnlyInternetGateway()
+		if err != nil {
+			return fmt.Errorf("Error creating Egress-Only Internet Gateway: %s", err)
+		}
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			return fmt.Errorf("Error updating state: %s", err)
+		}
+		err = ctx.WaitForExistence(vpc.State.IPv6.EgressOnlyInternetGatewayID, ctx.EgressOnlyInternetGatewayExists)
+		if err != nil {
+			return fmt.Errorf("Error creating Egress-Only Internet Gateway: %s", err)
+		}
+		ctx.Log("Created Egress-Only Internet Gateway %s", vpc.State.IPv6.EgressOnlyInternetGatewayID)
+		err = ctx.SetNameAndAutomated(vpc.State.IPv6.EgressOnlyInternetGatewayID, egressOnlyInternetGatewayName(ctx.VPCName))
+		if err != nil {
+			return fmt.Errorf("Error creating tags for Egress-Only Internet Gateway: %s", err)
+		}
+	}
+
+	var desired *database.RouteInfo
+	if networkConfig.ConnectPrivate {
+		desired = &database.RouteInfo{
+			EgressOnlyInternetGatewayID: vpc.State.IPv6.EgressOnlyInternetGatewayID,
+		}
+	}
+	for _, az := range vpc.State.AvailabilityZones.InOrder() {
+		if az.PrivateRouteTableID == "" {
+			continue
+		}
+		err := setRouteAllNonPublic(ctx, az.AvailabilityZoneInfra, vpc, ipv6InternetRoute, desired)
+		if err != nil {
+			return fmt.Errorf("Error updating private routes for Egress-Only Internet Gateway: %s", err)
+		}
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			return fmt.Errorf("Error updating state: %s", err)
+		}
+	}
+
+	if !networkConfig.ConnectPrivate && vpc.State.IPv6.EgressOnlyInternetGatewayID != "" {
+		err := ctx.DeleteEgressOnlyInternetGateway(vpc.State.IPv6.EgressOnlyInternetGatewayID)
+		if err != nil {
+			return fmt.Errorf("Error deleting Egress-Only Internet Gateway: %s", err)
+		}
+		vpc.State.IPv6.EgressOnlyInternetGatewayID = ""
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			return fmt.Errorf("Error updating state: %s", err)
+		}
+	}
+	return nil
+}
+
+func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *database.UpdateNetworkingTaskData) {
+	t := taskContext.Task
+	awsAccountAccess := taskContext.BaseAWSAccountAccess
+	lockSet := taskContext.LockSet
+	asUser := taskContext.AsUser
+
+	setStatus(t, database.TaskStatusInProgress)
+	t.Log("Updating VPC networking")
+
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, networkConfig.AWSRegion, networkConfig.VPCID)
+	if err != nil {
+		t.Log("Error loading state: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State == nil {
+		t.Log("VPC %s is not managed", vpc.ID)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State.VPCType == database.VPCTypeException {
+		t.Log("This is not allowed for Exception VPCs")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	ctx := &awsp.Context{
+		AWSAccountAccess: awsAccountAccess,
+		Logger:           t,
+		VPCID:            networkConfig.VPCID,
+		VPCName:          vpc.Name,
+	}
+
+	prefixListAccountID := prefixListAccountIDCommercial
+	if networkConfig.AWSRegion.IsGovCloud() {
+		prefixListAccountID = prefixListAccountIDGovCloud
+	}
+
+	getPrefixListRAM := func(region database.Region) (ramiface.RAMAPI, error) {
+		access, err := taskContext.AWSAccountAccessProvider.AccessAccount(prefixListAccountID, string(region), asUser)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting credentials for account %s: %s", prefixListAccountID, err)
+		}
+		plRAM := access.RAM()
+		return plRAM, nil
+	}
+
+	// Firewall resources
+
+	firewallTag := map[string]string{awsp.FirewallTypeKey: awsp.FirewallTypeValue}
+
+	if vpc.State.VPCType.HasFirewall() {
+		// New firewalls get the configured policy; existing ones are switched by the firewall policy task
+		var policyTemplateID uint64
+		if vpc.State.Firewall != nil {
+			policyTemplateID = vpc.State.Firewall.PolicyTemplateID
+		} else if vpc.Config != nil {
+			policyTemplateID = vpc.Config.FirewallPolicyTemplateID
+		}
+		_, err = taskContext.ensureFirewallPolicy(ctx, vpc.Region, policyTemplateID)
+		if err != nil {
+			t.Log("Error ensuring firewall policy exists: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+
+		firewallSubnetIDs := []string{}
+		for _, az := range vpc.State.AvailabilityZones.InOrder() {
 			for subnetType, subnets := range az.Subnets {
 				if subnetType == database.SubnetTypeFirewall {
 					for _, subnet := range subnets {
@@ -5061,7 +5699,7 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 		}
 
 		if vpc.State.Firewall == nil {
-			_, err := ctx.CreateFirewall(firewallSubnetIDs)
+			_, err := ctx.CreateFirewall(firewallSubnetIDs, policyTemplateID)
 			if err != nil {
 				t.Log("Error creating network firewall: %s", err)
 				setStatus(t, database.TaskStatusFailed)
@@ -5069,6 +5707,7 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 			}
 			vpc.State.Firewall = &database.Firewall{
 				AssociatedSubnetIDs: firewallSubnetIDs,
+				PolicyTemplateID:    policyTemplateID,
 			}
 			err = vpcWriter.UpdateState(vpc.State)
 			if err != nil {
@@ -5108,6 +5747,17 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 		}
 	}
 
+	// IPv6 CIDRs, which transit gateway attachment subnets need before IPv6 support can be enabled
+
+	if vpc.State.IPv6 != nil {
+		err = ensureIPv6CIDRs(ctx, vpc, vpcWriter)
+		if err != nil {
+			t.Log("Error assigning IPv6 CIDRs: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
 	// Transit gateway attachments
 	managedAttachmentsByID := make(map[uint64]*database.ManagedTransitGatewayAttachment)
 	if len(networkConfig.ManagedTransitGatewayAttachmentIDs) > 0 {
@@ -5137,6 +5787,22 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 		return
 	}
 
+	// VPN connections
+	vpnConnections, err := getVPNConnectionsForVPC(taskContext.ModelsManager, vpc, networkConfig.VPNConnectionIDs, networkConfig.ManagedTransitGatewayAttachmentIDs, managedAttachmentsByID)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	// Route table capacity, checked before any transit gateway routes are added
+	err = taskContext.checkRouteTableCapacity(ctx, vpc, networkConfig.ManagedTransitGatewayAttachmentIDs, managedAttachmentsByID, vpnConnections)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
 	// Peering Connections
 	peeringConnections, err := handlePeeringConnections(
 		lockSet, ctx, vpc, vpcWriter, networkConfig, taskContext.ModelsManager,
@@ -5155,6 +5821,13 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 		setStatus(t, database.TaskStatusFailed)
 		return
 	}
+	externalPeeringConnections, err := handleExternalPeeringConnections(ctx, vpc, vpcWriter, networkConfig)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	peeringConnections = append(peeringConnections, externalPeeringConnections...)
 
 	if vpc.State.VPCType == database.VPCTypeLegacy {
 		// We only support transit gateways and peering connections for Legacy VPCs so just add their routes and return.
@@ -5173,7 +5846,7 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 						setStatus(t, database.TaskStatusFailed)
 						return
 					}
-					err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, rt, subnetType, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
+					err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, vpnConnections, rt, subnetType, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
 					if err != nil {
 						t.Log("%s", err)
 						setStatus(t, database.TaskStatusFailed)
@@ -5320,7 +5993,7 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 		publicRTs = append(publicRTs, publicRT)
 	}
 	for _, publicRT := range publicRTs {
-		err = updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, publicRT, database.SubnetTypePublic, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
+		err = updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, vpnConnections, publicRT, database.SubnetTypePublic, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
 		if err != nil {
 			t.Log("%s", err)
 			setStatus(t, database.TaskStatusFailed)
@@ -5724,7 +6397,7 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 			setStatus(t, database.TaskStatusFailed)
 			return
 		}
-		err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, privateRT, database.SubnetTypePrivate, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
+		err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, vpnConnections, privateRT, database.SubnetTypePrivate, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
 		if err != nil {
 			t.Log("%s", err)
 			setStatus(t, database.TaskStatusFailed)
@@ -5802,7 +6475,7 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 						setStatus(t, database.TaskStatusFailed)
 						return
 					}
-					err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, customRT, subnetType, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
+					err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, networkConfig, managedAttachmentsByID, vpnConnections, customRT, subnetType, database.Region(networkConfig.AWSRegion), getPrefixListRAM)
 					if err != nil {
 						t.Log("%s", err)
 						setStatus(t, database.TaskStatusFailed)
@@ -5934,6 +6607,17 @@ func (taskContext *TaskContext) performUpdateNetworkingTask(networkConfig *datab
 		}
 	}
 
+	taskContext.recordEgressIPs(ctx, vpc)
+
+	if vpc.State.IPv6 != nil {
+		err = updateIPv6InternetRoutes(ctx, vpc, vpcWriter, networkConfig)
+		if err != nil {
+			t.Log("%s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
 	if !networkConfig.ConnectPublic {
 		// remove internet route from public RTs
 		for _, publicRT := range publicRTs {
@@ -6572,19 +7256,9 @@ func (taskContext *TaskContext) synchronizeRouteTableState(ctx *awsp.Context, vp
 		return fmt.Errorf("Error getting transit gateway configuration info: %s", err)
 	}
 
-	routeDestination := func(r *ec2.Route) string {
-		if r.DestinationCidrBlock != nil {
-			return aws.StringValue(r.DestinationCidrBlock)
-		}
-		if r.DestinationPrefixListId != nil {
-			return aws.StringValue(r.DestinationPrefixListId)
-		}
-		return ""
-	}
-
 	routeInState := func(routeInAWS *ec2.Route, routesInState []*database.RouteInfo) bool {
 		for _, routeInState := range routesInState {
-			if routeInState.Destination == routeDestination(routeInAWS) && routeInState.TransitGatewayID == aws.StringValue(routeInAWS.TransitGatewayId) {
+			if routeInState.Destination == awsp.RouteDestination(routeInAWS) && routeInState.TransitGatewayID == aws.StringValue(routeInAWS.TransitGatewayId) {
 				return true
 			}
 		}
@@ -6623,12 +7297,12 @@ func (taskContext *TaskContext) synchronizeRouteTableState(ctx *awsp.Context, vp
 					// This route points at a TGW we manage
 					if routeInAWS.TransitGatewayId != nil && aws.StringValue(routeInAWS.TransitGatewayId) == attachment.TransitGatewayID {
 						// This route is configured in a template
-						if routeDestination(routeInAWS) != "" && stringInSlice(routeDestination(routeInAWS), attachment.Routes) {
+						if awsp.RouteDestination(routeInAWS) != "" && stringInSlice(awsp.RouteDestination(routeInAWS), attachment.Routes) {
 							// Not currently in state
 							if !routeInState(routeInAWS, rtInState.Routes) {
-								routesToAdd[routeDestination(routeInAWS)] = &database.RouteInfo{
+								routesToAdd[awsp.RouteDestination(routeInAWS)] = &database.RouteInfo{
 									TransitGatewayID: aws.StringValue(routeInAWS.TransitGatewayId),
-									Destination:      routeDestination(routeInAWS),
+									Destination:      awsp.RouteDestination(routeInAWS),
 								}
 								break
 							}
@@ -6887,6 +7561,48 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 					}
 				}
 			}
+
+			if vpc.State.VPCType.HasFirewall() && vpc.State.Firewall != nil {
+				actual, err := ctx.FirewallLogDestinations()
+				if err != nil {
+					return nil, err
+				}
+				for _, dest := range actual {
+					if dest.DestinationType != networkfirewall.LogDestinationTypeCloudWatchLogs {
+						continue
+					}
+					logGroup := dest.Destination[logDestinationLogGroup]
+					retention, exists, err := ctx.LogGroupRetention(logGroup)
+					if err != nil {
+						return nil, err
+					}
+					if !exists && !fix {
+						issues = append(issues, &database.Issue{
+							Description: fmt.Sprintf("Firewall log group %s is missing", logGroup),
+							IsFixable:   true,
+							Type:        database.VerifyLogging,
+						})
+					}
+					dest.RetentionInDays = retention
+				}
+				if !sameFirewallLogging(actual, vpc.State.Firewall.LogDestinations) {
+					if fix {
+						vpc.State.Firewall.LogDestinations = actual
+					} else {
+						issues = append(issues, &database.Issue{
+							Description: "Firewall logging configuration does not match the last configuration applied",
+							IsFixable:   true,
+							Type:        database.VerifyLogging,
+						})
+					}
+				} else if !fix && !sameFirewallLogging(actual, desiredFirewallLogDestinations(vpc)) {
+					issues = append(issues, &database.Issue{
+						Description: "Firewall logging is not configured as expected for this VPC type",
+						IsFixable:   true,
+						Type:        database.VerifyLogging,
+					})
+				}
+			}
 		} else {
 			taskContext.Task.Log("Verification/repair of logging unsupported on this VPC")
 		}
@@ -7015,6 +7731,30 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 						})
 					}
 				}
+
+				// Check route table association and propagation on the transit gateway side
+				routing, err := desiredTransitGatewayRouting(tga.ManagedTransitGatewayAttachmentIDs, managedAttachmentsByID)
+				if err != nil {
+					return nil, err
+				}
+				if routing.isManaged(tga) && share != nil && !fix {
+					access, err := taskContext.AWSAccountAccessProvider.AccessAccount(share.AccountID, string(vpc.Region), asUser)
+					if err != nil {
+						return nil, fmt.Errorf("Error getting credentials for account %s: %s", share.AccountID, err)
+					}
+					problems, err := verifyTransitGatewayRouting(ctx, access.EC2(), tga, routing)
+					if err != nil {
+						return nil, err
+					}
+					for _, problem := range problems {
+						issues = append(issues, &database.Issue{
+							AffectedSubnetIDs: actualSubnetIDs,
+							Description:       problem,
+							IsFixable:         true,
+							Type:              database.VerifyNetworking,
+						})
+					}
+				}
 			}
 		}
 		if fix {
@@ -7353,6 +8093,29 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 						}
 						issues = append(issues, tagIssues...)
 
+						if vpc.State.IPv6 != nil {
+							actualIPv6CIDR := awsp.SubnetIPv6CIDR(sn)
+							if actualIPv6CIDR != subnet.IPv6CIDR {
+								if fix {
+									subnet.IPv6CIDR = actualIPv6CIDR
+								} else if actualIPv6CIDR == "" {
+									issues = append(issues, &database.Issue{
+										AffectedSubnetIDs: []string{subnetID},
+										Description:       fmt.Sprintf("Subnet %s is missing IPv6 CIDR %s", subnetID, subnet.IPv6CIDR),
+										IsFixable:         true,
+										Type:              database.VerifyNetworking,
+									})
+								} else {
+									issues = append(issues, &database.Issue{
+										AffectedSubnetIDs: []string{subnetID},
+										Description:       fmt.Sprintf("Subnet %s has IPv6 CIDR %s but expected %q", subnetID, actualIPv6CIDR, subnet.IPv6CIDR),
+										IsFixable:         true,
+										Type:              database.VerifyNetworking,
+									})
+								}
+							}
+						}
+
 						var routeTable *ec2.RouteTable
 						if subnet.CustomRouteTableID != "" {
 							routeTable, rtIssues, err = verifyRouteTable(
@@ -7499,6 +8262,46 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 			if fix {
 				vpc.State.PeeringConnections = filteredPeeringConnections
 			}
+
+			externalIssues, err := verifyExternalPeeringConnections(ctx, vpc, nonPublicSubnetIDs, fix)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, externalIssues...)
+		}
+
+		// VPN connections
+		if !fix && vpc.Config != nil {
+			for _, id := range vpc.Config.VPNConnectionIDs {
+				vpn, err := modelsManager.GetVPNConnection(id)
+				if err != nil {
+					return nil, fmt.Errorf("Error getting VPN connection %d: %s", id, err)
+				}
+				share, err := modelsManager.GetTransitGatewayResourceShare(vpc.Region, vpn.TransitGatewayID)
+				if err != nil {
+					return nil, err
+				}
+				ownerCtx := ctx
+				if share != nil && share.AccountID != vpc.AccountID {
+					access, err := taskContext.AWSAccountAccessProvider.AccessAccount(share.AccountID, string(vpc.Region), asUser)
+					if err != nil {
+						return nil, fmt.Errorf("Error getting credentials for account %s: %s", share.AccountID, err)
+					}
+					ownerCtx = &awsp.Context{AWSAccountAccess: access, Logger: ctx.Logger}
+				}
+				problems, err := verifyVPNConnection(ownerCtx, vpn)
+				if err != nil {
+					return nil, err
+				}
+				for _, problem := range problems {
+					issues = append(issues, &database.Issue{
+						AffectedSubnetIDs: nonPublicSubnetIDs,
+						Description:       problem,
+						IsFixable:         false,
+						Type:              database.VerifyNetworking,
+					})
+				}
+			}
 		}
 
 		// Firewall
@@ -7549,6 +8352,16 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 			}
 		}
 
+		// IPv6
+
+		if vpc.State.IPv6 != nil {
+			ipv6Issues, err := verifyIPv6(ctx, vpc, nonPublicSubnetIDs, fix)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, ipv6Issues...)
+		}
+
 		// VPC tags
 
 		out, err := ctx.EC2().DescribeVpcs(&ec2.DescribeVpcsInput{
@@ -7580,21 +8393,11 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 	if verifySpec.VerifyCMSNet {
 		if vpc.State.VPCType.CanUpdateCMSNet() {
 			if cmsNet.SupportsRegion(vpc.Region) {
-				broken, err := cmsNet.GetBrokenActivations(vpc.AccountID, vpc.Region, vpc.ID, asUser)
+				cmsnetIssues, err := verifyCMSNet(ctx, cmsNet, vpc, asUser, fix && verifySpec.RepairCMSNetActivations)
 				if err != nil {
-					return nil, fmt.Errorf("Error getting CMSNet issues: %s", err)
-				}
-				for _, activation := range broken {
-					issues = append(issues, &database.Issue{
-						AffectedSubnetIDs: []string{activation.SubnetID},
-						Description: fmt.Sprintf(
-							"Subnet %s's connection to CMSNet CIDR %s is broken",
-							activation.SubnetID,
-							activation.DestinationCIDR),
-						IsFixable: false,
-						Type:      database.VerifyCMSNet,
-					})
+					return nil, err
 				}
+				issues = append(issues, cmsnetIssues...)
 			}
 		} else {
 			taskContext.Task.Log("Verification/repair of cmsnet unsupported on this VPC")
@@ -7614,6 +8417,12 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 					templatesByID[group.ID] = group
 				}
 			}
+			sgResolver := &securityGroupVariableResolver{
+				ctx:           ctx,
+				modelsManager: modelsManager,
+				vpc:           vpc,
+				templates:     templatesByID,
+			}
 			keepSecurityGroups := []*database.SecurityGroup{}
 			for _, sg := range vpc.State.SecurityGroups {
 				if sg.SecurityGroupID != "" {
@@ -7748,6 +8557,26 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 									Type:      database.VerifySecurityGroups,
 								})
 							}
+							if config != nil {
+								// Placeholders are re-evaluated so that CIDR changes are picked up
+								desiredRules, err := sgResolver.expandRules(config.Rules)
+								if err != nil {
+									issues = append(issues, &database.Issue{
+										Description: fmt.Sprintf("Security group %s: %s", sg.SecurityGroupID, err),
+										IsFixable:   false,
+										Type:        database.VerifySecurityGroups,
+									})
+								} else if !sameSecurityGroupRules(desiredRules, sg.Rules) {
+									issues = append(issues, &database.Issue{
+										Description: fmt.Sprintf(
+											"Security group %s rules are out of date with template %q",
+											sg.SecurityGroupID,
+											config.Name),
+										IsFixable: true,
+										Type:      database.VerifySecurityGroups,
+									})
+								}
+							}
 						}
 					}
 				}
@@ -7760,17 +8589,197 @@ func (taskContext *TaskContext) verifyState(ctx *awsp.Context, vpc *database.VPC
 		}
 	}
 
-	if fix {
-		err := vpcWriter.UpdateState(vpc.State)
-		if err != nil {
-			return nil, fmt.Errorf("Error updating state: %s", err)
-		}
-	}
-
-	return mergeIssues(vpc.Issues, issues, verifySpec.VerifyTypes()), nil
-}
-
-func (taskContext *TaskContext) performUpdateSecurityGroupsTask(config *database.UpdateSecurityGroupsTaskData) {
+	// VPC endpoints
+	if verifySpec.VerifyVPCEndpoints {
+		if vpc.State.VPCType.CanUpdateVPCEndpoints() {
+			keepEndpoints := []*database.VPCEndpoint{}
+			for _, ep := range vpc.State.VPCEndpoints {
+				if ep.VPCEndpointID == "" {
+					continue
+				}
+				out, err := ctx.EC2().DescribeVpcEndpoints(&ec2.DescribeVpcEndpointsInput{
+					Filters: []*ec2.Filter{
+						{
+							Name:   aws.String("vpc-endpoint-id"),
+							Values: []*string{&ep.VPCEndpointID},
+						},
+					},
+				})
+				if err != nil {
+					return nil, err
+				}
+				var actual *ec2.VpcEndpoint
+				if len(out.VpcEndpoints) > 0 {
+					actual = out.VpcEndpoints[0]
+				}
+				if actual == nil || stringInSlice(strings.ToLower(aws.StringValue(actual.State)), []string{"deleting", "deleted", "failed", "rejected"}) {
+					// Dropping it from state lets the follow-up task create it again
+					if !fix {
+						issues = append(issues, &database.Issue{
+							Description: fmt.Sprintf("VPC endpoint %s (%s) is missing or failed", ep.VPCEndpointID, ep.ServiceName),
+							IsFixable:   true,
+							Type:        database.VerifyVPCEndpoints,
+						})
+					}
+					continue
+				}
+				keepEndpoints = append(keepEndpoints, ep)
+				actualRTs := aws.StringValueSlice(actual.RouteTableIds)
+				actualSubnets := aws.StringValueSlice(actual.SubnetIds)
+				actualSGs := []string{}
+				for _, group := range actual.Groups {
+					actualSGs = append(actualSGs, aws.StringValue(group.GroupId))
+				}
+				addRTs, removeRTs := diffStringSets(ep.RouteTableIDs, actualRTs)
+				addSubnets, removeSubnets := diffStringSets(ep.SubnetIDs, actualSubnets)
+				drifted := len(addRTs)+len(removeRTs)+len(addSubnets)+len(removeSubnets) > 0
+				if ep.Type == database.VPCEndpointTypeInterface {
+					addSGs, removeSGs := diffStringSets(ep.SecurityGroupIDs, actualSGs)
+					drifted = drifted || len(addSGs)+len(removeSGs) > 0 || aws.BoolValue(actual.PrivateDnsEnabled) != ep.PrivateDNSEnabled
+				}
+				if drifted {
+					if fix {
+						// Record what's really there so the follow-up task corrects it
+						ep.RouteTableIDs = actualRTs
+						ep.SubnetIDs = actualSubnets
+						if ep.Type == database.VPCEndpointTypeInterface {
+							ep.SecurityGroupIDs = actualSGs
+							ep.PrivateDNSEnabled = aws.BoolValue(actual.PrivateDnsEnabled)
+						}
+					} else {
+						issues = append(issues, &database.Issue{
+							Description: fmt.Sprintf("VPC endpoint %s (%s) does not match its configuration", ep.VPCEndpointID, ep.ServiceName),
+							IsFixable:   true,
+							Type:        database.VerifyVPCEndpoints,
+						})
+					}
+				}
+			}
+			if fix {
+				vpc.State.VPCEndpoints = keepEndpoints
+			}
+		} else {
+			taskContext.Task.Log("Verification/repair of VPC endpoints unsupported on this VPC")
+		}
+	}
+
+	// Firewall policy
+	// The follow-up task recreates and reassociates the policy, so there's nothing to fix in state
+	if verifySpec.VerifyFirewallPolicy && !fix && vpc.State.VPCType.HasFirewall() && vpc.State.Firewall != nil {
+		expectedARN, err := ctx.FirewallPolicyARN(vpc.State.Firewall.PolicyTemplateID)
+		if err != nil {
+			return nil, err
+		}
+		actualARN, err := ctx.AssociatedFirewallPolicyARN()
+		if err != nil {
+			return nil, err
+		}
+		if expectedARN == nil {
+			issues = append(issues, &database.Issue{
+				Description: "Firewall policy is missing",
+				IsFixable:   true,
+				Type:        database.VerifyFirewallPolicy,
+			})
+		} else if actualARN != aws.StringValue(expectedARN) {
+			issues = append(issues, &database.Issue{
+				Description: fmt.Sprintf("Firewall is associated with policy %s instead of %s", actualARN, aws.StringValue(expectedARN)),
+				IsFixable:   true,
+				Type:        database.VerifyFirewallPolicy,
+			})
+		}
+		if vpc.Config != nil && vpc.Config.FirewallPolicyTemplateID != vpc.State.Firewall.PolicyTemplateID {
+			issues = append(issues, &database.Issue{
+				Description: "Firewall is not using the configured policy template",
+				IsFixable:   true,
+				Type:        database.VerifyFirewallPolicy,
+			})
+		}
+	}
+
+	if fix {
+		err := vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			return nil, fmt.Errorf("Error updating state: %s", err)
+		}
+	}
+
+	return mergeIssues(vpc.Issues, issues, verifySpec.VerifyTypes()), nil
+}
+
+func verifyIPv6(ctx *awsp.Context, vpc *database.VPC, nonPublicSubnetIDs []string, fix bool) ([]*database.Issue, error) {
+	issues := []*database.Issue{}
+
+	if vpc.State.IPv6.AssociatedCIDRBlock != "" {
+		out, err := ctx.EC2().DescribeVpcs(&ec2.DescribeVpcsInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("vpc-id"),
+					Values: aws.StringSlice([]string{vpc.ID}),
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		actualCIDR := ""
+		if len(out.Vpcs) > 0 {
+			actualCIDR, _ = awsp.AssociatedIPv6CIDRBlock(out.Vpcs[0])
+		}
+		if actualCIDR != vpc.State.IPv6.AssociatedCIDRBlock {
+			if fix {
+				vpc.State.IPv6.AssociatedCIDRBlock = actualCIDR
+			} else {
+				issues = append(issues, &database.Issue{
+					Description: fmt.Sprintf("IPv6 CIDR block %s is not associated with the VPC", vpc.State.IPv6.AssociatedCIDRBlock),
+					IsFixable:   true,
+					Type:        database.VerifyNetworking,
+				})
+			}
+		}
+	}
+
+	if vpc.State.IPv6.EgressOnlyInternetGatewayID != "" {
+		out, err := ctx.EC2().DescribeEgressOnlyInternetGateways(&ec2.DescribeEgressOnlyInternetGatewaysInput{
+			EgressOnlyInternetGatewayIds: aws.StringSlice([]string{vpc.State.IPv6.EgressOnlyInternetGatewayID}),
+		})
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); !ok || !strings.HasSuffix(aerr.Code(), ".NotFound") {
+				return nil, err
+			}
+			out = &ec2.DescribeEgressOnlyInternetGatewaysOutput{}
+		}
+		attached := false
+		for _, eigw := range out.EgressOnlyInternetGateways {
+			for _, attachment := range eigw.Attachments {
+				if aws.StringValue(attachment.VpcId) == vpc.ID && aws.StringValue(attachment.State) == ec2.AttachmentStatusAttached {
+					attached = true
+				}
+			}
+		}
+		if !attached {
+			if fix {
+				vpc.State.IPv6.EgressOnlyInternetGatewayID = ""
+			} else {
+				issues = append(issues, &database.Issue{
+					AffectedSubnetIDs: nonPublicSubnetIDs,
+					Description:       fmt.Sprintf("Missing egress-only internet gateway %s", vpc.State.IPv6.EgressOnlyInternetGatewayID),
+					IsFixable:         true,
+					Type:              database.VerifyNetworking,
+				})
+			}
+		} else {
+			tagIssues, err := verifyTags(ctx, vpc.State.IPv6.EgressOnlyInternetGatewayID, map[string]string{"Name": egressOnlyInternetGatewayName(ctx.VPCName)}, out.EgressOnlyInternetGateways[0].Tags, database.VerifyNetworking, fix)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, tagIssues...)
+		}
+	}
+
+	return issues, nil
+}
+
+func (taskContext *TaskContext) performUpdateSecurityGroupsTask(config *database.UpdateSecurityGroupsTaskData) {
 	t := taskContext.Task
 	awsAccountAccess := taskContext.BaseAWSAccountAccess
 	lockSet := taskContext.LockSet
@@ -7784,8 +8793,12 @@ func (taskContext *TaskContext) performUpdateSecurityGroupsTask(config *database
 		setStatus(t, database.TaskStatusFailed)
 		return
 	}
+	templatesByID := map[uint64]*database.SecurityGroupTemplate{}
 	for _, set := range sets {
 		setsByID[set.ID] = set
+		for _, group := range set.Groups {
+			templatesByID[group.ID] = group
+		}
 	}
 
 	type securityGroup struct {
@@ -7989,9 +9002,28 @@ func (taskContext *TaskContext) performUpdateSecurityGroupsTask(config *database
 				Tags:      tags,
 			})
 		}
+	}
+
+	// Rules are applied once every group exists so that they can refer to each other
+	resolver := &securityGroupVariableResolver{
+		ctx:           ctx,
+		modelsManager: taskContext.ModelsManager,
+		vpc:           vpc,
+		templates:     templatesByID,
+	}
+	for _, sg := range securityGroups {
+		if sg.Config == nil {
+			continue
+		}
+		desiredRules, err := resolver.expandRules(sg.Config.Rules)
+		if err != nil {
+			t.Log("Error in rules for security group %q: %s", sg.Config.Name, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
 		existingRules := append([]*database.SecurityGroupRule{}, sg.State.Rules...)
 
-		for _, desiredRule := range sg.Config.Rules {
+		for _, desiredRule := range desiredRules {
 			found := false
 			for eIdx, existingRule := range existingRules {
 				if desiredRule.IsEgress == existingRule.IsEgress &&
@@ -8020,6 +9052,13 @@ func (taskContext *TaskContext) performUpdateSecurityGroupsTask(config *database
 							Description:  &desiredRule.Description,
 						},
 					}
+				} else if awsp.IsSecurityGroupID(desiredRule.Source) {
+					perm.UserIdGroupPairs = []*ec2.UserIdGroupPair{
+						{
+							GroupId:     &desiredRule.Source,
+							Description: &desiredRule.Description,
+						},
+					}
 				} else {
 					perm.IpRanges = []*ec2.IpRange{
 						{
@@ -8148,6 +9187,653 @@ func (taskContext *TaskContext) performUpdateSecurityGroupsTask(config *database
 	setStatus(t, database.TaskStatusSuccessful)
 }
 
+// How far back security group usage analysis looks if not told otherwise
+const defaultSecurityGroupUsageDays = 7
+
+// flowLogReader returns a reader for the flow logs this VPC sends to source.
+func flowLogReader(ctx *awsp.Context, vpc *database.VPC, source string) (flowlogs.Reader, error) {
+	flowLogID := vpc.State.CloudWatchLogsFlowLogID
+	if source == database.FlowLogSourceS3 {
+		flowLogID = vpc.State.S3FlowLogID
+	} else if source != database.FlowLogSourceCloudWatch {
+		return nil, fmt.Errorf("Unknown flow log source %q", source)
+	}
+	if flowLogID == "" {
+		return nil, fmt.Errorf("VPC has no %s flow logs. Update logging first.", source)
+	}
+	out, err := ctx.EC2().DescribeFlowLogs(&ec2.DescribeFlowLogsInput{
+		FlowLogIds: []*string{aws.String(flowLogID)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing flow log %s: %s", flowLogID, err)
+	}
+	if len(out.FlowLogs) != 1 {
+		return nil, fmt.Errorf("Flow log %s not found", flowLogID)
+	}
+	flowLog := out.FlowLogs[0]
+	if source == database.FlowLogSourceS3 {
+		// arn:aws:s3:::bucket/optional/prefix/
+		destination := aws.StringValue(flowLog.LogDestination)
+		bucket := strings.SplitN(destination[strings.LastIndex(destination, ":")+1:], "/", 2)[0]
+		return &flowlogs.S3Reader{
+			S3:        ctx.S3(),
+			Bucket:    bucket,
+			AccountID: vpc.AccountID,
+			Region:    string(vpc.Region),
+			FlowLogID: flowLogID,
+		}, nil
+	}
+	return &flowlogs.InsightsReader{
+		Logs:         ctx.CloudWatchLogs(),
+		LogGroup:     aws.StringValue(flowLog.LogGroupName),
+		PollInterval: 5 * time.Second,
+		Timeout:      15 * time.Minute,
+	}, nil
+}
+
+func (taskContext *TaskContext) performAnalyzeSecurityGroupUsageTask(config *database.AnalyzeSecurityGroupUsageTaskData) {
+	t := taskContext.Task
+	awsAccountAccess := taskContext.BaseAWSAccountAccess
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+
+	vpc, _, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.AWSRegion, config.VPCID)
+	if err != nil {
+		t.Log("Error getting VPC info: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if !vpc.State.VPCType.CanUpdateSecurityGroups() {
+		t.Log("This is not allowed for this type of VPC")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	days := config.Days
+	if days <= 0 {
+		days = defaultSecurityGroupUsageDays
+	}
+	source := config.Source
+	if source == "" {
+		source = database.FlowLogSourceCloudWatch
+	}
+
+	ctx := &awsp.Context{
+		AWSAccountAccess: awsAccountAccess,
+		Logger:           t,
+		VPCID:            config.VPCID,
+		VPCName:          vpc.Name,
+	}
+
+	sets, err := taskContext.ModelsManager.GetSecurityGroupSets()
+	if err != nil {
+		t.Log("Failed to load security group sets: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	templatesByID := map[uint64]*database.SecurityGroupTemplate{}
+	for _, set := range sets {
+		for _, group := range set.Groups {
+			templatesByID[group.ID] = group
+		}
+	}
+
+	reader, err := flowLogReader(ctx, vpc, source)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	interfaces := []*flowlogs.Interface{}
+	err = ctx.EC2().DescribeNetworkInterfacesPages(&ec2.DescribeNetworkInterfacesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []*string{aws.String(vpc.ID)},
+			},
+		},
+	}, func(out *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		for _, eni := range out.NetworkInterfaces {
+			iface := &flowlogs.Interface{ID: aws.StringValue(eni.NetworkInterfaceId)}
+			for _, addr := range eni.PrivateIpAddresses {
+				iface.PrivateIPs = append(iface.PrivateIPs, aws.StringValue(addr.PrivateIpAddress))
+			}
+			for _, group := range eni.Groups {
+				iface.SecurityGroupIDs = append(iface.SecurityGroupIDs, aws.StringValue(group.GroupId))
+			}
+			interfaces = append(interfaces, iface)
+		}
+		return true
+	})
+	if err != nil {
+		t.Log("Error listing network interfaces: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	// Each template rule expands to any number of concrete rules; remember
+	// which template rule each one came from so hits can be summed back up.
+	type ruleOrigin struct {
+		group     int
+		ruleIndex int
+	}
+	type analyzedGroup struct {
+		template *database.SecurityGroupTemplate
+		usage    []*database.SecurityGroupRuleUsage
+	}
+	groups := []*analyzedGroup{}
+	rules := []*flowlogs.Rule{}
+	origins := []ruleOrigin{}
+	resolver := &securityGroupVariableResolver{
+		ctx:           ctx,
+		modelsManager: taskContext.ModelsManager,
+		vpc:           vpc,
+		templates:     templatesByID,
+	}
+	prefixListCIDRs := map[string][]string{}
+	for _, sg := range vpc.State.SecurityGroups {
+		template := templatesByID[sg.TemplateID]
+		if template == nil || sg.SecurityGroupID == "" {
+			continue
+		}
+		if len(config.TemplateIDs) > 0 && !uint64InSlice(template.ID, config.TemplateIDs) {
+			continue
+		}
+		group := &analyzedGroup{template: template}
+		for ruleIdx, templateRule := range template.Rules {
+			group.usage = append(group.usage, &database.SecurityGroupRuleUsage{Rule: templateRule})
+			concrete, err := resolver.expandRules([]*database.SecurityGroupRule{templateRule})
+			if err != nil {
+				t.Log("Error in rules for security group %q: %s", template.Name, err)
+				setStatus(t, database.TaskStatusFailed)
+				return
+			}
+			for _, rule := range concrete {
+				fr := &flowlogs.Rule{
+					SecurityGroupID: sg.SecurityGroupID,
+					IsEgress:        rule.IsEgress,
+					Protocol:        rule.Protocol,
+					FromPort:        rule.FromPort,
+					ToPort:          rule.ToPort,
+				}
+				if awsp.IsPrefixListID(rule.Source) {
+					if _, ok := prefixListCIDRs[rule.Source]; !ok {
+						cidrs := []string{}
+						err := ctx.EC2().GetManagedPrefixListEntriesPages(&ec2.GetManagedPrefixListEntriesInput{
+							PrefixListId: aws.String(rule.Source),
+						}, func(page *ec2.GetManagedPrefixListEntriesOutput, lastPage bool) bool {
+							for _, entry := range page.Entries {
+								cidrs = append(cidrs, aws.StringValue(entry.Cidr))
+							}
+							return true
+						})
+						if err != nil {
+							t.Log("Error getting entries of prefix list %s: %s", rule.Source, err)
+							setStatus(t, database.TaskStatusFailed)
+							return
+						}
+						prefixListCIDRs[rule.Source] = cidrs
+					}
+					fr.PeerCIDRs = prefixListCIDRs[rule.Source]
+				} else if awsp.IsSecurityGroupID(rule.Source) {
+					fr.PeerGroupID = rule.Source
+				} else if rule.SourceIPV6CIDR != "" {
+					fr.PeerCIDRs = []string{rule.SourceIPV6CIDR}
+				} else {
+					fr.PeerCIDRs = []string{rule.Source}
+				}
+				rules = append(rules, fr)
+				origins = append(origins, ruleOrigin{group: len(groups), ruleIndex: ruleIdx})
+			}
+		}
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 {
+		t.Log("No security groups to analyze")
+		setStatus(t, database.TaskStatusSuccessful)
+		return
+	}
+
+	analyzer, err := flowlogs.NewAnalyzer(interfaces, rules)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	end := time.Now().UTC()
+	start := end.Add(-time.Duration(days) * 24 * time.Hour)
+	t.Log("Reading %s flow logs from %s to %s", source, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	err = reader.Read(vpc.ID, start, end, analyzer.Add)
+	if err != nil {
+		t.Log("Error reading flow logs: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	for idx, hits := range analyzer.Hits() {
+		origin := origins[idx]
+		groups[origin.group].usage[origin.ruleIndex].Hits += hits
+	}
+
+	for _, group := range groups {
+		t.Log("Security group %q:", group.template.Name)
+		unused := []string{}
+		for _, usage := range group.usage {
+			t.Log("  %d: %q %s %d-%d %s", usage.Hits, usage.Rule.Description, usage.Rule.Protocol, usage.Rule.FromPort, usage.Rule.ToPort, usage.Rule.Source)
+			if usage.Hits == 0 {
+				unused = append(unused, fmt.Sprintf("%q", usage.Rule.Description))
+			}
+		}
+		if len(unused) > 0 {
+			t.Log("  Rules with no matching flows: %s", strings.Join(unused, ", "))
+		}
+		err = taskContext.ModelsManager.SaveSecurityGroupUsage(&database.SecurityGroupUsage{
+			VPCID:       vpc.ID,
+			Region:      vpc.Region,
+			TemplateID:  group.template.ID,
+			WindowStart: start,
+			WindowEnd:   end,
+			Rules:       group.usage,
+		})
+		if err != nil {
+			t.Log("Error saving usage for %q: %s", group.template.Name, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+// diffStringSets returns the values of want missing from have, and the values
+// of have missing from want.
+func diffStringSets(want, have []string) (add, remove []string) {
+	for _, s := range want {
+		if !stringInSlice(s, have) {
+			add = append(add, s)
+		}
+	}
+	for _, s := range have {
+		if !stringInSlice(s, want) {
+			remove = append(remove, s)
+		}
+	}
+	return add, remove
+}
+
+// desiredVPCEndpoint computes the endpoint state that vpcState should have for
+// template. Interface endpoints go in the first subnet of the first listed
+// subnet type in each AZ.
+func desiredVPCEndpoint(vpcState *database.VPCState, region database.Region, template *database.VPCEndpointTemplate) (*database.VPCEndpoint, error) {
+	ep := &database.VPCEndpoint{
+		TemplateID:  template.ID,
+		ServiceName: template.FullServiceName(region),
+		Type:        template.Type,
+	}
+	switch template.Type {
+	case database.VPCEndpointTypeGateway:
+		for rtID, rt := range vpcState.RouteTables {
+			for _, subnetType := range template.SubnetTypes {
+				if rt.SubnetType == subnetType {
+					ep.RouteTableIDs = append(ep.RouteTableIDs, rtID)
+				}
+			}
+		}
+		sort.Strings(ep.RouteTableIDs)
+		if len(ep.RouteTableIDs) == 0 {
+			return nil, fmt.Errorf("No route tables for subnet types %v", template.SubnetTypes)
+		}
+	case database.VPCEndpointTypeInterface:
+		for _, az := range vpcState.AvailabilityZones.InOrder() {
+			for _, subnetType := range template.SubnetTypes {
+				if subnets := az.Subnets[subnetType]; len(subnets) > 0 {
+					ep.SubnetIDs = append(ep.SubnetIDs, subnets[0].SubnetID)
+					break
+				}
+			}
+		}
+		if len(ep.SubnetIDs) == 0 {
+			return nil, fmt.Errorf("No subnets of types %v", template.SubnetTypes)
+		}
+		if template.SecurityGroupTemplateID != 0 {
+			found := false
+			for _, sg := range vpcState.SecurityGroups {
+				if sg.TemplateID == template.SecurityGroupTemplateID && sg.SecurityGroupID != "" {
+					ep.SecurityGroupIDs = []string{sg.SecurityGroupID}
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("Security group template %d has not been applied to this VPC", template.SecurityGroupTemplateID)
+			}
+		}
+		ep.PrivateDNSEnabled = template.PrivateDNSEnabled
+	default:
+		return nil, fmt.Errorf("Unknown endpoint type %q", template.Type)
+	}
+	return ep, nil
+}
+
+func (taskContext *TaskContext) performUpdateVPCEndpointsTask(config *database.UpdateVPCEndpointsTaskData) {
+	t := taskContext.Task
+	awsAccountAccess := taskContext.BaseAWSAccountAccess
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+	setsByID := map[uint64]*database.VPCEndpointSet{}
+	sets, err := taskContext.ModelsManager.GetVPCEndpointSets()
+	if err != nil {
+		t.Log("Failed to load VPC endpoint sets: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	for _, set := range sets {
+		setsByID[set.ID] = set
+	}
+
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.AWSRegion, config.VPCID)
+	if err != nil {
+		t.Log("Error getting VPC info: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if !vpc.State.VPCType.CanUpdateVPCEndpoints() {
+		t.Log("This is not allowed for this type of VPC")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	ctx := &awsp.Context{
+		AWSAccountAccess: awsAccountAccess,
+		Logger:           t,
+		VPCID:            config.VPCID,
+		VPCName:          vpc.Name,
+	}
+
+	desired := []*database.VPCEndpoint{}
+	for _, setID := range config.VPCEndpointSetIDs {
+		set, ok := setsByID[setID]
+		if !ok {
+			t.Log("No VPC endpoint set for configured ID %d", setID)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		for _, template := range set.Endpoints {
+			ep, err := desiredVPCEndpoint(vpc.State, config.AWSRegion, template)
+			if err != nil {
+				t.Log("Error configuring endpoint for %s in set %q: %s", template.ServiceName, set.Name, err)
+				setStatus(t, database.TaskStatusFailed)
+				return
+			}
+			desired = append(desired, ep)
+		}
+	}
+
+	// Delete endpoints that are no longer configured or whose service or type changed
+	existingEndpoints := vpc.State.VPCEndpoints
+	var keep []*database.VPCEndpoint
+	for idx, existing := range existingEndpoints {
+		stillWanted := false
+		for _, ep := range desired {
+			if ep.TemplateID == existing.TemplateID && ep.ServiceName == existing.ServiceName && ep.Type == existing.Type {
+				stillWanted = true
+				break
+			}
+		}
+		if stillWanted && existing.VPCEndpointID != "" {
+			keep = append(keep, existing)
+			continue
+		}
+		if existing.VPCEndpointID != "" {
+			out, err := ctx.EC2().DeleteVpcEndpoints(&ec2.DeleteVpcEndpointsInput{
+				VpcEndpointIds: []*string{&existing.VPCEndpointID},
+			})
+			if err == nil && len(out.Unsuccessful) > 0 {
+				err = fmt.Errorf("%s", aws.StringValue(out.Unsuccessful[0].Error.Message))
+			}
+			if err != nil {
+				t.Log("Error deleting VPC endpoint %s: %s", existing.VPCEndpointID, err)
+				setStatus(t, database.TaskStatusFailed)
+				return
+			}
+			t.Log("Deleted VPC endpoint %s (%s)", existing.VPCEndpointID, existing.ServiceName)
+		}
+		vpc.State.VPCEndpoints = append(append([]*database.VPCEndpoint{}, keep...), existingEndpoints[idx+1:]...)
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			t.Log("Error updating state: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+	vpc.State.VPCEndpoints = keep
+
+	for _, ep := range desired {
+		var existing *database.VPCEndpoint
+		for _, e := range vpc.State.VPCEndpoints {
+			if e.TemplateID == ep.TemplateID {
+				existing = e
+				break
+			}
+		}
+		if existing == nil {
+			input := &ec2.CreateVpcEndpointInput{
+				VpcId:           &config.VPCID,
+				ServiceName:     &ep.ServiceName,
+				VpcEndpointType: aws.String(string(ep.Type)),
+				TagSpecifications: []*ec2.TagSpecification{
+					{
+						ResourceType: aws.String("vpc-endpoint"),
+						Tags: []*ec2.Tag{
+							{
+								Key:   aws.String("Name"),
+								Value: aws.String(fmt.Sprintf("%s-%s", vpc.Name, ep.ServiceName)),
+							},
+							{
+								Key:   aws.String("Automated"),
+								Value: aws.String("true"),
+							},
+						},
+					},
+				},
+			}
+			if ep.Type == database.VPCEndpointTypeGateway {
+				input.RouteTableIds = aws.StringSlice(ep.RouteTableIDs)
+			} else {
+				input.SubnetIds = aws.StringSlice(ep.SubnetIDs)
+				if len(ep.SecurityGroupIDs) > 0 {
+					input.SecurityGroupIds = aws.StringSlice(ep.SecurityGroupIDs)
+				}
+				input.PrivateDnsEnabled = aws.Bool(ep.PrivateDNSEnabled)
+			}
+			out, err := ctx.EC2().CreateVpcEndpoint(input)
+			if err != nil {
+				t.Log("Error creating VPC endpoint for %s: %s", ep.ServiceName, err)
+				setStatus(t, database.TaskStatusFailed)
+				return
+			}
+			ep.VPCEndpointID = aws.StringValue(out.VpcEndpoint.VpcEndpointId)
+			vpc.State.VPCEndpoints = append(vpc.State.VPCEndpoints, ep)
+			err = vpcWriter.UpdateState(vpc.State)
+			if err != nil {
+				t.Log("Error updating state: %s", err)
+				setStatus(t, database.TaskStatusFailed)
+				return
+			}
+			t.Log("Created VPC endpoint %s (%s)", ep.VPCEndpointID, ep.ServiceName)
+			continue
+		}
+
+		input := &ec2.ModifyVpcEndpointInput{
+			VpcEndpointId: &existing.VPCEndpointID,
+		}
+		changed := false
+		addRTs, removeRTs := diffStringSets(ep.RouteTableIDs, existing.RouteTableIDs)
+		addSubnets, removeSubnets := diffStringSets(ep.SubnetIDs, existing.SubnetIDs)
+		addSGs, removeSGs := diffStringSets(ep.SecurityGroupIDs, existing.SecurityGroupIDs)
+		if len(addRTs) > 0 || len(removeRTs) > 0 {
+			input.AddRouteTableIds = aws.StringSlice(addRTs)
+			input.RemoveRouteTableIds = aws.StringSlice(removeRTs)
+			changed = true
+		}
+		if len(addSubnets) > 0 || len(removeSubnets) > 0 {
+			input.AddSubnetIds = aws.StringSlice(addSubnets)
+			input.RemoveSubnetIds = aws.StringSlice(removeSubnets)
+			changed = true
+		}
+		if len(addSGs) > 0 || len(removeSGs) > 0 {
+			input.AddSecurityGroupIds = aws.StringSlice(addSGs)
+			input.RemoveSecurityGroupIds = aws.StringSlice(removeSGs)
+			changed = true
+		}
+		if ep.Type == database.VPCEndpointTypeInterface && ep.PrivateDNSEnabled != existing.PrivateDNSEnabled {
+			input.PrivateDnsEnabled = aws.Bool(ep.PrivateDNSEnabled)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		_, err = ctx.EC2().ModifyVpcEndpoint(input)
+		if err != nil {
+			t.Log("Error updating VPC endpoint %s: %s", existing.VPCEndpointID, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		existing.RouteTableIDs = ep.RouteTableIDs
+		existing.SubnetIDs = ep.SubnetIDs
+		existing.SecurityGroupIDs = ep.SecurityGroupIDs
+		existing.PrivateDNSEnabled = ep.PrivateDNSEnabled
+		err = vpcWriter.UpdateState(vpc.State)
+		if err != nil {
+			t.Log("Error updating state: %s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		t.Log("Updated VPC endpoint %s (%s)", existing.VPCEndpointID, existing.ServiceName)
+	}
+
+	issues, err := taskContext.verifyState(ctx, vpc, vpcWriter, database.VerifySpec{VerifyVPCEndpoints: true}, false)
+	if err != nil {
+		t.Log("Error verifying: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	err = vpcWriter.UpdateIssues(issues)
+	if err != nil {
+		t.Log("Error updating issues: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+// ensureFirewallPolicy makes sure the policy for templateID, or the default
+// policy if it's 0, exists in the VPC's account and returns its ARN.
+func (taskContext *TaskContext) ensureFirewallPolicy(ctx *awsp.Context, region database.Region, templateID uint64) (string, error) {
+	if templateID == 0 {
+		err := ctx.EnsureDefaultFirewallPolicyExists(region)
+		if err != nil {
+			return "", err
+		}
+		arn, err := ctx.FirewallPolicyARN(0)
+		return aws.StringValue(arn), err
+	}
+	template, err := taskContext.ModelsManager.GetFirewallPolicyTemplate(templateID)
+	if err != nil {
+		return "", fmt.Errorf("Error loading firewall policy template: %s", err)
+	}
+	if template.Region != region {
+		return "", fmt.Errorf("Firewall policy template %q is for %s, not %s", template.Name, template.Region, region)
+	}
+	return ctx.EnsureTemplateFirewallPolicy(template)
+}
+
+func (taskContext *TaskContext) performUpdateFirewallPolicyTask(config *database.UpdateFirewallPolicyTaskData) {
+	t := taskContext.Task
+	awsAccountAccess := taskContext.BaseAWSAccountAccess
+	lockSet := taskContext.LockSet
+
+	setStatus(t, database.TaskStatusInProgress)
+
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(lockSet, config.AWSRegion, config.VPCID)
+	if err != nil {
+		t.Log("Error getting VPC info: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if !vpc.State.VPCType.HasFirewall() {
+		t.Log("This is not allowed for this type of VPC")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State.Firewall == nil {
+		t.Log("The firewall has not been created yet. Update networking first.")
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	ctx := &awsp.Context{
+		AWSAccountAccess: awsAccountAccess,
+		Logger:           t,
+		VPCID:            config.VPCID,
+		VPCName:          vpc.Name,
+	}
+
+	policyARN, err := taskContext.ensureFirewallPolicy(ctx, config.AWSRegion, config.FirewallPolicyTemplateID)
+	if err != nil {
+		t.Log("Error ensuring firewall policy exists: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	currentARN, err := ctx.AssociatedFirewallPolicyARN()
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if currentARN != policyARN {
+		err = ctx.AssociateFirewallPolicy(policyARN)
+		if err != nil {
+			t.Log("%s", err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+	}
+
+	oldTemplateID := vpc.State.Firewall.PolicyTemplateID
+	vpc.State.Firewall.PolicyTemplateID = config.FirewallPolicyTemplateID
+	err = vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		t.Log("Error updating state: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if oldTemplateID != 0 && oldTemplateID != config.FirewallPolicyTemplateID {
+		err = ctx.DeleteTemplateFirewallPolicy(oldTemplateID)
+		if err != nil {
+			// Harmless to leave behind; it's cleaned up when the firewall is deleted
+			t.Log("WARNING: Unable to delete previous firewall policy: %s", err)
+		}
+	}
+
+	issues, err := taskContext.verifyState(ctx, vpc, vpcWriter, database.VerifySpec{VerifyFirewallPolicy: true}, false)
+	if err != nil {
+		t.Log("Error verifying: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	err = vpcWriter.UpdateIssues(issues)
+	if err != nil {
+		t.Log("Error updating issues: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
 func (taskContext *TaskContext) performSynchronizeRouteTableStateFromAWSTask(synchronizeConfig *database.SynchronizeRouteTableStateFromAWSTaskData) {
 	t := taskContext.Task
 	awsAccountAccess := taskContext.BaseAWSAccountAccess
@@ -8419,23 +10105,17 @@ func createRouteInfos(rt *ec2.RouteTable, rtInfo *database.RouteTableInfo) (rout
 
 	for _, route := range rt.Routes {
 		if aws.StringValue(route.Origin) == ec2.RouteOriginCreateRoute {
-			var dest string
-
-			if aws.StringValue(route.DestinationCidrBlock) != "" {
-				dest = aws.StringValue(route.DestinationCidrBlock)
-			} else if aws.StringValue(route.DestinationPrefixListId) != "" {
-				dest = aws.StringValue(route.DestinationPrefixListId)
-			}
-
+			dest := awsp.RouteDestination(route)
 			if dest == "" {
 				continue
 			}
 
 			routeInfo := &database.RouteInfo{
-				Destination:         dest,
-				NATGatewayID:        aws.StringValue(route.NatGatewayId),
-				TransitGatewayID:    aws.StringValue(route.TransitGatewayId),
-				PeeringConnectionID: aws.StringValue(route.VpcPeeringConnectionId),
+				Destination:                 dest,
+				NATGatewayID:                aws.StringValue(route.NatGatewayId),
+				EgressOnlyInternetGatewayID: aws.StringValue(route.EgressOnlyInternetGatewayId),
+				TransitGatewayID:            aws.StringValue(route.TransitGatewayId),
+				PeeringConnectionID:         aws.StringValue(route.VpcPeeringConnectionId),
 			}
 
 			gatewayID := aws.StringValue(route.GatewayId)
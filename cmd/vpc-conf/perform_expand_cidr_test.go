@@ -0,0 +1,256 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/client"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/google/go-cmp/cmp"
+)
+
+type expandCIDRTestCase struct {
+	Name string
+
+	StartState          database.VPCState
+	ExistingContainers  testmocks.ContainerTree
+	ExistingSubnetCIDRs map[string]string
+
+	TaskConfig database.ExpandCIDRTaskData
+
+	ExpectedBlocksAdded             []testmocks.BlockSpec
+	ExpectedVPCCIDRBlocksAssociated []string
+
+	ExpectedTaskStatus database.TaskStatus
+
+	ExpectedEndState database.VPCState
+}
+
+func TestPerformExpandCIDR(t *testing.T) {
+	appStartState := func() database.VPCState {
+		return database.VPCState{
+			VPCType: database.VPCTypeV1,
+			AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypeApp: {
+							{
+								SubnetID:  "subnet-app-a",
+								GroupName: "app",
+							},
+						},
+					},
+				},
+				"us-east-1b": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypeApp: {
+							{
+								SubnetID:  "subnet-app-b",
+								GroupName: "app",
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	existingContainers := testmocks.ContainerTree{
+		Name: "/Global/AWS/V4/Commercial/East/Lower-App",
+		Children: []testmocks.ContainerTree{
+			{
+				Name: "/Global/AWS/V4/Commercial/East/Lower-App/123456-chris-east-dev",
+			},
+		},
+	}
+	existingSubnetCIDRs := map[string]string{
+		"subnet-app-a": "10.200.0.0/27",
+		"subnet-app-b": "10.200.0.32/27",
+	}
+
+	testCases := []expandCIDRTestCase{
+		{
+			Name: "Expand app group",
+
+			StartState:          appStartState(),
+			ExistingContainers:  existingContainers,
+			ExistingSubnetCIDRs: existingSubnetCIDRs,
+
+			TaskConfig: database.ExpandCIDRTaskData{
+				VPCID:      "vpc-abc",
+				Region:     "us-east-1",
+				SubnetType: database.SubnetTypeApp,
+				GroupName:  "app",
+				SubnetSize: 26,
+			},
+
+			ExpectedTaskStatus: database.TaskStatusSuccessful,
+
+			ExpectedBlocksAdded: []testmocks.BlockSpec{
+				{
+					ParentContainer: "/Global/AWS/V4/Commercial/East/Lower-App",
+					Container:       "/Global/AWS/V4/Commercial/East/Lower-App/123456-chris-east-dev",
+					BlockType:       client.BlockTypeVPC,
+					Size:            25,
+					Status:          "Aggregate",
+				},
+				{
+					ParentContainer: "/Global/AWS/V4/Commercial/East/Lower-App/123456-chris-east-dev",
+					Container:       "/Global/AWS/V4/Commercial/East/Lower-App/123456-chris-east-dev/app-2-a",
+					BlockType:       client.BlockTypeSubnet,
+					Size:            26,
+					Status:          "Deployed",
+				},
+				{
+					ParentContainer: "/Global/AWS/V4/Commercial/East/Lower-App/123456-chris-east-dev",
+					Container:       "/Global/AWS/V4/Commercial/East/Lower-App/123456-chris-east-dev/app-2-b",
+					BlockType:       client.BlockTypeSubnet,
+					Size:            26,
+					Status:          "Deployed",
+				},
+			},
+			ExpectedVPCCIDRBlocksAssociated: []string{"10.0.0.0/25"},
+
+			ExpectedEndState: database.VPCState{
+				VPCType: database.VPCTypeV1,
+				AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+					"us-east-1a": {
+						Subnets: map[database.SubnetType][]*database.SubnetInfo{
+							database.SubnetTypeApp: {
+								{
+									SubnetID:  "subnet-app-a",
+									GroupName: "app",
+								},
+								{
+									SubnetID:  "subnet-us-east-1a-0",
+									GroupName: "app-2",
+								},
+							},
+						},
+					},
+					"us-east-1b": {
+						Subnets: map[database.SubnetType][]*database.SubnetInfo{
+							database.SubnetTypeApp: {
+								{
+									SubnetID:  "subnet-app-b",
+									GroupName: "app",
+								},
+								{
+									SubnetID:  "subnet-us-east-1b-0",
+									GroupName: "app-2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "New subnets must be larger",
+
+			StartState:          appStartState(),
+			ExistingContainers:  existingContainers,
+			ExistingSubnetCIDRs: existingSubnetCIDRs,
+
+			TaskConfig: database.ExpandCIDRTaskData{
+				VPCID:      "vpc-abc",
+				Region:     "us-east-1",
+				SubnetType: database.SubnetTypeApp,
+				GroupName:  "app",
+				SubnetSize: 27,
+			},
+
+			ExpectedTaskStatus: database.TaskStatusFailed,
+			ExpectedEndState:   appStartState(),
+		},
+		{
+			Name: "Group must exist in every AZ",
+
+			StartState:          appStartState(),
+			ExistingContainers:  existingContainers,
+			ExistingSubnetCIDRs: existingSubnetCIDRs,
+
+			TaskConfig: database.ExpandCIDRTaskData{
+				VPCID:      "vpc-abc",
+				Region:     "us-east-1",
+				SubnetType: database.SubnetTypeApp,
+				GroupName:  "web",
+				SubnetSize: 26,
+			},
+
+			ExpectedTaskStatus: database.TaskStatusFailed,
+			ExpectedEndState:   appStartState(),
+		},
+		{
+			Name: "New group name in use",
+
+			StartState:          appStartState(),
+			ExistingContainers:  existingContainers,
+			ExistingSubnetCIDRs: existingSubnetCIDRs,
+
+			TaskConfig: database.ExpandCIDRTaskData{
+				VPCID:        "vpc-abc",
+				Region:       "us-east-1",
+				SubnetType:   database.SubnetTypeApp,
+				GroupName:    "app",
+				NewGroupName: "app",
+				SubnetSize:   26,
+			},
+
+			ExpectedTaskStatus: database.TaskStatusFailed,
+			ExpectedEndState:   appStartState(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rand.Seed(976)
+			task := &testmocks.MockTask{
+				ID: 1235,
+			}
+			vpcKey := string(tc.TaskConfig.Region) + tc.TaskConfig.VPCID
+			mm := &testmocks.MockModelsManager{
+				VPCs: map[string]*database.VPC{
+					vpcKey: {
+						AccountID: "123456",
+						ID:        tc.TaskConfig.VPCID,
+						State:     &tc.StartState,
+						Name:      "chris-east-dev",
+						Stack:     "dev",
+						Region:    tc.TaskConfig.Region,
+					},
+				},
+			}
+			ipcontrol := &testmocks.MockIPControl{
+				ExistingContainers: tc.ExistingContainers,
+			}
+			ec2 := &testmocks.MockEC2{
+				SubnetCIDRs: tc.ExistingSubnetCIDRs,
+			}
+			taskContext := &TaskContext{
+				Task:          task,
+				ModelsManager: mm,
+				LockSet:       database.GetFakeLockSet(database.TargetVPC(tc.TaskConfig.VPCID), database.TargetIPControlWrite),
+				IPAM:          ipcontrol,
+				BaseAWSAccountAccess: &awsp.AWSAccountAccess{
+					EC2svc: ec2,
+				},
+			}
+
+			taskContext.performExpandCIDRTask(&tc.TaskConfig)
+
+			if task.Status != tc.ExpectedTaskStatus {
+				t.Fatalf("Incorrect task status. Expected %s but got %s", tc.ExpectedTaskStatus, task.Status)
+			}
+			if diff := cmp.Diff(tc.ExpectedBlocksAdded, ipcontrol.BlocksAdded); diff != "" {
+				t.Fatalf("Expected added blocks in IPControl did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ExpectedVPCCIDRBlocksAssociated, ec2.VPCCIDRBlocksAssociated[tc.TaskConfig.VPCID]); diff != "" {
+				t.Fatalf("Expected CIDR blocks associated did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(&tc.ExpectedEndState, mm.VPCs[vpcKey].State); diff != "" {
+				t.Fatalf("Expected end state did not match state saved to database: \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/networkfirewall"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type firewallPolicyTestCase struct {
+	Name string
+
+	StartState        database.VPCState
+	ExistingPolicies  []*networkfirewall.FirewallPolicyMetadata
+	AssociatedPolicy  string
+	CreatePolicyError error
+
+	TaskConfig database.UpdateFirewallPolicyTaskData
+
+	ExpectedTaskStatus       database.TaskStatus
+	ExpectedPoliciesCreated  []string
+	ExpectedPoliciesDeleted  []string
+	ExpectedRuleGroups       map[string][]string // rule group name -> domains
+	ExpectedRuleGroupsLeft   []string
+	ExpectedRuleOrders       map[string]string   // created policy or rule group name -> rule order
+	ExpectedDefaultActions   map[string][]string // created policy name -> stateful default actions
+	ExpectedAssociatedPolicy string
+
+	ExpectedEndState database.VPCState
+}
+
+func TestPerformUpdateFirewallPolicy(t *testing.T) {
+	const firewallName = "cms-cloud-vpc-abc-net-fw"
+	defaultPolicy := &networkfirewall.FirewallPolicyMetadata{
+		Name: aws.String("cms-cloud-vpc-abc-default-fp"),
+		Arn:  aws.String(testmocks.TestARN),
+	}
+	templatePolicyARN := testmocks.PolicyARN("cms-cloud-vpc-abc-fp-1")
+	stateWithTemplate := func(templateID uint64) database.VPCState {
+		return database.VPCState{
+			VPCType: database.VPCTypeV1Firewall,
+			Firewall: &database.Firewall{
+				AssociatedSubnetIDs: []string{"subnet-fw-a"},
+				PolicyTemplateID:    templateID,
+			},
+		}
+	}
+	taskConfig := func(templateID uint64, region database.Region) database.UpdateFirewallPolicyTaskData {
+		return database.UpdateFirewallPolicyTaskData{
+			VPCID:                    "vpc-abc",
+			AWSRegion:                region,
+			FirewallPolicyTemplateID: templateID,
+		}
+	}
+	templates := []*database.FirewallPolicyTemplate{
+		{
+			ID:                    1,
+			Name:                  "allow-listed egress",
+			Region:                "us-east-1",
+			StatefulRuleGroupARNs: []string{"arn:stateful-managed"},
+			AllowedDomains:        []string{".amazonaws.com", "github.com"},
+		},
+		{
+			ID:     2,
+			Name:   "west",
+			Region: "us-west-2",
+		},
+		{
+			ID:                     3,
+			Name:                   "strict egress",
+			Region:                 "us-east-1",
+			StatefulRuleOrder:      database.FirewallRuleOrderStrict,
+			StatefulRuleGroupARNs:  []string{"arn:stateful-strict"},
+			StatefulDefaultActions: []string{"aws:drop_established"},
+			AllowedDomains:         []string{"github.com"},
+		},
+	}
+
+	testCases := []firewallPolicyTestCase{
+		{
+			Name:             "Switch from default to template policy",
+			StartState:       stateWithTemplate(0),
+			ExistingPolicies: []*networkfirewall.FirewallPolicyMetadata{defaultPolicy},
+			AssociatedPolicy: testmocks.TestARN,
+			TaskConfig:       taskConfig(1, "us-east-1"),
+
+			ExpectedTaskStatus:      database.TaskStatusSuccessful,
+			ExpectedPoliciesCreated: []string{"cms-cloud-vpc-abc-fp-1"},
+			ExpectedRuleGroups: map[string][]string{
+				"cms-cloud-vpc-abc-domains-1": {".amazonaws.com", "github.com"},
+			},
+			ExpectedRuleGroupsLeft:   []string{"cms-cloud-vpc-abc-domains-1"},
+			ExpectedAssociatedPolicy: templatePolicyARN,
+			ExpectedEndState:         stateWithTemplate(1),
+		},
+		{
+			Name:             "Switch to strict order template policy",
+			StartState:       stateWithTemplate(0),
+			ExistingPolicies: []*networkfirewall.FirewallPolicyMetadata{defaultPolicy},
+			AssociatedPolicy: testmocks.TestARN,
+			TaskConfig:       taskConfig(3, "us-east-1"),
+
+			ExpectedTaskStatus:      database.TaskStatusSuccessful,
+			ExpectedPoliciesCreated: []string{"cms-cloud-vpc-abc-fp-3"},
+			ExpectedRuleGroups: map[string][]string{
+				"cms-cloud-vpc-abc-domains-3-strict": {"github.com"},
+			},
+			ExpectedRuleGroupsLeft: []string{"cms-cloud-vpc-abc-domains-3-strict"},
+			ExpectedRuleOrders: map[string]string{
+				"cms-cloud-vpc-abc-fp-3":             networkfirewall.RuleOrderStrictOrder,
+				"cms-cloud-vpc-abc-domains-3-strict": networkfirewall.RuleOrderStrictOrder,
+			},
+			ExpectedDefaultActions: map[string][]string{
+				"cms-cloud-vpc-abc-fp-3": {"aws:drop_established"},
+			},
+			ExpectedAssociatedPolicy: testmocks.PolicyARN("cms-cloud-vpc-abc-fp-3"),
+			ExpectedEndState:         stateWithTemplate(3),
+		},
+		{
+			Name:              "Domain allow-list cleaned up when the policy can't be created",
+			StartState:        stateWithTemplate(0),
+			ExistingPolicies:  []*networkfirewall.FirewallPolicyMetadata{defaultPolicy},
+			AssociatedPolicy:  testmocks.TestARN,
+			CreatePolicyError: errors.New("LimitExceededException"),
+			TaskConfig:        taskConfig(1, "us-east-1"),
+
+			ExpectedTaskStatus: database.TaskStatusFailed,
+			ExpectedRuleGroups: map[string][]string{
+				"cms-cloud-vpc-abc-domains-1": {".amazonaws.com", "github.com"},
+			},
+			ExpectedAssociatedPolicy: testmocks.TestARN,
+			ExpectedEndState:         stateWithTemplate(0),
+		},
+		{
+			Name:       "Switch from template back to default policy",
+			StartState: stateWithTemplate(1),
+			ExistingPolicies: []*networkfirewall.FirewallPolicyMetadata{
+				defaultPolicy,
+				{Name: aws.String("cms-cloud-vpc-abc-fp-1"), Arn: aws.String(templatePolicyARN)},
+			},
+			AssociatedPolicy: templatePolicyARN,
+			TaskConfig:       taskConfig(0, "us-east-1"),
+
+			ExpectedTaskStatus:       database.TaskStatusSuccessful,
+			ExpectedPoliciesDeleted:  []string{templatePolicyARN},
+			ExpectedAssociatedPolicy: testmocks.TestARN,
+			ExpectedEndState:         stateWithTemplate(0),
+		},
+		{
+			Name:             "Template in another region",
+			StartState:       stateWithTemplate(0),
+			ExistingPolicies: []*networkfirewall.FirewallPolicyMetadata{defaultPolicy},
+			AssociatedPolicy: testmocks.TestARN,
+			TaskConfig:       taskConfig(2, "us-east-1"),
+
+			ExpectedTaskStatus:       database.TaskStatusFailed,
+			ExpectedAssociatedPolicy: testmocks.TestARN,
+			ExpectedEndState:         stateWithTemplate(0),
+		},
+		{
+			Name: "Firewall not created yet",
+			StartState: database.VPCState{
+				VPCType: database.VPCTypeV1Firewall,
+			},
+			TaskConfig: taskConfig(1, "us-east-1"),
+
+			ExpectedTaskStatus: database.TaskStatusFailed,
+			ExpectedEndState: database.VPCState{
+				VPCType: database.VPCTypeV1Firewall,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			task := &testmocks.MockTask{
+				ID: 1236,
+			}
+			vpcKey := string(tc.TaskConfig.AWSRegion) + tc.TaskConfig.VPCID
+			mm := &testmocks.MockModelsManager{
+				VPCs: map[string]*database.VPC{
+					vpcKey: {
+						AccountID: "123456",
+						ID:        tc.TaskConfig.VPCID,
+						State:     &tc.StartState,
+						Name:      "chris-east-dev",
+						Stack:     "dev",
+						Region:    tc.TaskConfig.AWSRegion,
+					},
+				},
+				FirewallPolicyTemplates: templates,
+			}
+			nf := &testmocks.MockNetworkFirewall{
+				FirewallPolicies:           tc.ExistingPolicies,
+				FirewallPolicyARNsByName:   true,
+				Firewalls:                  map[string]string{firewallName: tc.TaskConfig.VPCID},
+				FirewallPolicyAssociations: map[string]string{firewallName: tc.AssociatedPolicy},
+				CreateFirewallPolicyError:  tc.CreatePolicyError,
+			}
+			taskContext := &TaskContext{
+				Task:          task,
+				ModelsManager: mm,
+				LockSet:       database.GetFakeLockSet(database.TargetVPC(tc.TaskConfig.VPCID)),
+				BaseAWSAccountAccess: &awsp.AWSAccountAccess{
+					NFsvc: nf,
+				},
+			}
+
+			taskContext.performUpdateFirewallPolicyTask(&tc.TaskConfig)
+
+			if task.Status != tc.ExpectedTaskStatus {
+				t.Fatalf("Incorrect task status. Expected %s but got %s", tc.ExpectedTaskStatus, task.Status)
+			}
+			if diff := cmp.Diff(tc.ExpectedPoliciesCreated, nf.FirewallPoliciesCreated); diff != "" {
+				t.Fatalf("Expected created policies did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ExpectedPoliciesDeleted, nf.FirewallPoliciesDeleted); diff != "" {
+				t.Fatalf("Expected deleted policies did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ExpectedRuleGroups, nf.RuleGroupsCreated); diff != "" {
+				t.Fatalf("Expected domain allow-lists did not match actual: \n%s", diff)
+			}
+			ruleGroupsLeft := []string{}
+			for _, rg := range nf.RuleGroups {
+				ruleGroupsLeft = append(ruleGroupsLeft, aws.StringValue(rg.Name))
+			}
+			if diff := cmp.Diff(tc.ExpectedRuleGroupsLeft, ruleGroupsLeft, cmpopts.EquateEmpty()); diff != "" {
+				t.Fatalf("Expected remaining domain allow-lists did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ExpectedRuleOrders, nf.StatefulRuleOrders); diff != "" {
+				t.Fatalf("Expected rule orders did not match actual: \n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ExpectedDefaultActions, nf.StatefulDefaultActions); diff != "" {
+				t.Fatalf("Expected stateful default actions did not match actual: \n%s", diff)
+			}
+			if associated := nf.FirewallPolicyAssociations[firewallName]; associated != tc.ExpectedAssociatedPolicy {
+				t.Fatalf("Expected firewall to use policy %q but got %q", tc.ExpectedAssociatedPolicy, associated)
+			}
+			if diff := cmp.Diff(&tc.ExpectedEndState, mm.VPCs[vpcKey].State); diff != "" {
+				t.Fatalf("Expected end state did not match state saved to database: \n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func ipv6TestVPC() (*database.VPC, database.VPCWriter) {
+	vpc := &database.VPC{
+		ID:        "vpc-abc",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		State: &database.VPCState{
+			PublicRouteTableID: "rtb-public",
+			InternetGateway: database.InternetGatewayInfo{
+				InternetGatewayID:         "igw-1",
+				IsInternetGatewayAttached: true,
+			},
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-public":    {SubnetType: database.SubnetTypePublic},
+				"rtb-private-a": {SubnetType: database.SubnetTypePrivate},
+				"rtb-private-b": {SubnetType: database.SubnetTypePrivate},
+			},
+			AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+				"us-east-1a": {
+					PrivateRouteTableID: "rtb-private-a",
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-a"}},
+						database.SubnetTypePublic:  {{SubnetID: "subnet-public-a"}},
+					},
+				},
+				"us-east-1b": {
+					PrivateRouteTableID: "rtb-private-b",
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-b"}},
+					},
+				},
+			},
+			IPv6: &database.IPv6Info{},
+		},
+	}
+	mm := &testmocks.MockModelsManager{
+		VPCs: map[string]*database.VPC{string(vpc.Region) + vpc.ID: vpc},
+	}
+	writer := &testmocks.MockVPCWriter{MM: mm, Region: vpc.Region, VPCID: vpc.ID}
+	return vpc, writer
+}
+
+func TestEnsureIPv6CIDRs(t *testing.T) {
+	vpc, writer := ipv6TestVPC()
+	ec2svc := &testmocks.MockEC2{
+		NextAmazonProvidedIPv6CIDR: "2600:1f14:abc:de00::/56",
+		SubnetCIDRs: map[string]string{
+			"subnet-private-a": "10.0.0.0/24",
+			"subnet-public-a":  "10.0.1.0/24",
+			"subnet-private-b": "10.0.2.0/24",
+		},
+		// Left over from an earlier run that failed part way through
+		SubnetIPv6CIDRsAssociated: map[string]string{
+			"subnet-public-a": "2600:1f14:abc:de00::/64",
+		},
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		VPCID:            vpc.ID,
+	}
+
+	err := ensureIPv6CIDRs(ctx, vpc, writer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if vpc.State.IPv6.AssociatedCIDRBlock != "2600:1f14:abc:de00::/56" {
+		t.Errorf("Expected the Amazon-provided block to be associated but got %q", vpc.State.IPv6.AssociatedCIDRBlock)
+	}
+	expected := map[string]string{
+		"subnet-public-a":  "2600:1f14:abc:de00::/64",
+		"subnet-private-a": "2600:1f14:abc:de01::/64",
+		"subnet-private-b": "2600:1f14:abc:de02::/64",
+	}
+	if diff := cmp.Diff(expected, ec2svc.SubnetIPv6CIDRsAssociated); diff != "" {
+		t.Errorf("Expected subnet associations did not match actual: \n%s", diff)
+	}
+	actual := map[string]string{}
+	for _, az := range vpc.State.AvailabilityZones {
+		for _, subnets := range az.Subnets {
+			for _, subnet := range subnets {
+				actual[subnet.SubnetID] = subnet.IPv6CIDR
+			}
+		}
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("Expected subnet state did not match actual: \n%s", diff)
+	}
+
+	// Nothing left to do
+	associations := len(ec2svc.IPv6CIDRBlockAssociationSet)
+	err = ensureIPv6CIDRs(ctx, vpc, writer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(ec2svc.IPv6CIDRBlockAssociationSet) != associations || len(ec2svc.SubnetIPv6CIDRsAssociated) != len(expected) {
+		t.Errorf("Expected no new associations")
+	}
+}
+
+func TestUpdateIPv6InternetRoutes(t *testing.T) {
+	vpc, writer := ipv6TestVPC()
+	ec2svc := &testmocks.MockEC2{
+		RouteTables: []*ec2.RouteTable{
+			{RouteTableId: aws.String("rtb-public")},
+			{RouteTableId: aws.String("rtb-private-a")},
+			{RouteTableId: aws.String("rtb-private-b")},
+		},
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		VPCID:            vpc.ID,
+		VPCName:          "test-vpc",
+	}
+
+	err := updateIPv6InternetRoutes(ctx, vpc, writer, &database.UpdateNetworkingTaskData{
+		NetworkingConfig: database.NetworkingConfig{ConnectPublic: true, ConnectPrivate: true},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	eigwID := vpc.State.IPv6.EgressOnlyInternetGatewayID
+	if eigwID != "eigw-1" {
+		t.Fatalf("Expected egress-only internet gateway eigw-1 in state but got %q", eigwID)
+	}
+	expectedAdded := map[string][]*database.RouteInfo{
+		"rtb-public":    {{Destination: ipv6InternetRoute, InternetGatewayID: "igw-1"}},
+		"rtb-private-a": {{Destination: ipv6InternetRoute, EgressOnlyInternetGatewayID: eigwID}},
+		"rtb-private-b": {{Destination: ipv6InternetRoute, EgressOnlyInternetGatewayID: eigwID}},
+	}
+	if diff := cmp.Diff(expectedAdded, ec2svc.RoutesAdded); diff != "" {
+		t.Errorf("Expected routes added did not match actual: \n%s", diff)
+	}
+	if diff := cmp.Diff(expectedAdded["rtb-private-a"], vpc.State.RouteTables["rtb-private-a"].Routes); diff != "" {
+		t.Errorf("Expected private route table state did not match actual: \n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Automated=true", "Name=test-vpc-eigw"}, ec2svc.TagsCreated[eigwID], cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("Expected egress-only internet gateway tags did not match actual: \n%s", diff)
+	}
+
+	// Disconnecting private subnets removes the routes and the gateway
+	err = updateIPv6InternetRoutes(ctx, vpc, writer, &database.UpdateNetworkingTaskData{
+		NetworkingConfig: database.NetworkingConfig{ConnectPublic: true},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expectedDeleted := map[string][]string{
+		"rtb-private-a": {ipv6InternetRoute},
+		"rtb-private-b": {ipv6InternetRoute},
+	}
+	if diff := cmp.Diff(expectedDeleted, ec2svc.RoutesDeleted); diff != "" {
+		t.Errorf("Expected routes deleted did not match actual: \n%s", diff)
+	}
+	if diff := cmp.Diff([]string{eigwID}, ec2svc.EgressOnlyInternetGatewaysDeleted); diff != "" {
+		t.Errorf("Expected egress-only internet gateways deleted did not match actual: \n%s", diff)
+	}
+	if vpc.State.IPv6.EgressOnlyInternetGatewayID != "" {
+		t.Errorf("Expected no egress-only internet gateway in state but got %q", vpc.State.IPv6.EgressOnlyInternetGatewayID)
+	}
+}
+
+func TestVerifyIPv6(t *testing.T) {
+	nonPublicSubnetIDs := []string{"subnet-private-a", "subnet-private-b"}
+	testCases := []struct {
+		name                string
+		associatedCIDRBlock string
+		eigws               []*ec2.EgressOnlyInternetGateway
+		expectedIssues      []string
+	}{
+		{
+			name:                "Everything in place",
+			associatedCIDRBlock: "2600:1f14:abc:de00::/56",
+			eigws: []*ec2.EgressOnlyInternetGateway{
+				{
+					EgressOnlyInternetGatewayId: aws.String("eigw-1"),
+					Attachments:                 []*ec2.InternetGatewayAttachment{{VpcId: aws.String("vpc-abc"), State: aws.String(ec2.AttachmentStatusAttached)}},
+					Tags:                        []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("test-vpc-eigw")}, {Key: aws.String("Automated"), Value: aws.String("true")}},
+				},
+			},
+			expectedIssues: []string{},
+		},
+		{
+			name:                "Block and gateway missing",
+			associatedCIDRBlock: "2600:1f14:abc:ff00::/56",
+			expectedIssues: []string{
+				"IPv6 CIDR block 2600:1f14:abc:ff00::/56 is not associated with the VPC",
+				"Missing egress-only internet gateway eigw-1",
+			},
+		},
+		{
+			name:                "Gateway untagged",
+			associatedCIDRBlock: "2600:1f14:abc:de00::/56",
+			eigws: []*ec2.EgressOnlyInternetGateway{
+				{
+					EgressOnlyInternetGatewayId: aws.String("eigw-1"),
+					Attachments:                 []*ec2.InternetGatewayAttachment{{VpcId: aws.String("vpc-abc"), State: aws.String(ec2.AttachmentStatusAttached)}},
+					Tags:                        []*ec2.Tag{{Key: aws.String("Automated"), Value: aws.String("true")}},
+				},
+			},
+			expectedIssues: []string{`eigw-1 should be tagged "Name"="test-vpc-eigw" but tag is missing`},
+		},
+	}
+	for _, tc := range testCases {
+		for _, fix := range []bool{false, true} {
+			vpc, _ := ipv6TestVPC()
+			vpc.State.IPv6.AssociatedCIDRBlock = tc.associatedCIDRBlock
+			vpc.State.IPv6.EgressOnlyInternetGatewayID = "eigw-1"
+			ec2svc := &testmocks.MockEC2{
+				IPv6CIDRBlockAssociationSet: []*ec2.VpcIpv6CidrBlockAssociation{
+					{
+						Ipv6CidrBlock:      aws.String("2600:1f14:abc:de00::/56"),
+						Ipv6CidrBlockState: &ec2.VpcCidrBlockState{State: aws.String(ec2.VpcCidrBlockStateCodeAssociated)},
+					},
+				},
+				EgressOnlyInternetGateways: tc.eigws,
+			}
+			ctx := &awsp.Context{
+				AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+				Logger:           &testLogger{},
+				VPCID:            vpc.ID,
+				VPCName:          "test-vpc",
+			}
+
+			issues, err := verifyIPv6(ctx, vpc, nonPublicSubnetIDs, fix)
+			if err != nil {
+				t.Errorf("%s (fix %v): unexpected error: %s", tc.name, fix, err)
+				continue
+			}
+			descriptions := []string{}
+			for _, issue := range issues {
+				descriptions = append(descriptions, issue.Description)
+				if issue.Description == "Missing egress-only internet gateway eigw-1" {
+					if diff := cmp.Diff(nonPublicSubnetIDs, issue.AffectedSubnetIDs); diff != "" {
+						t.Errorf("%s: expected affected subnets did not match actual: \n%s", tc.name, diff)
+					}
+				}
+			}
+			expected := tc.expectedIssues
+			if fix {
+				expected = []string{}
+			}
+			if diff := cmp.Diff(expected, descriptions); diff != "" {
+				t.Errorf("%s (fix %v): expected issues did not match actual: \n%s", tc.name, fix, diff)
+			}
+			if fix {
+				if vpc.State.IPv6.AssociatedCIDRBlock != "2600:1f14:abc:de00::/56" {
+					t.Errorf("%s: expected the associated block to be fixed but got %q", tc.name, vpc.State.IPv6.AssociatedCIDRBlock)
+				}
+				if tc.eigws == nil && vpc.State.IPv6.EgressOnlyInternetGatewayID != "" {
+					t.Errorf("%s: expected the missing gateway to be removed from state", tc.name)
+				}
+				if len(tc.expectedIssues) == 1 && len(ec2svc.TagsCreated["eigw-1"]) == 0 {
+					t.Errorf("%s: expected the gateway to be tagged", tc.name)
+				}
+			}
+		}
+	}
+}
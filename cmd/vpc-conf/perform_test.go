@@ -266,6 +266,7 @@ func TestCreateRouteInfos(t *testing.T) {
 	igwID := "igw-123"
 	endpointID := "vpce-456"
 	natID := "nat-789"
+	eigwID := "eigw-abc"
 
 	testCases := []testCase{
 		{
@@ -308,6 +309,46 @@ func TestCreateRouteInfos(t *testing.T) {
 			},
 		},
 
+		{
+			Name: "IPv6 route to IGW",
+			RouteTable: &ec2.RouteTable{
+				Routes: []*ec2.Route{
+					{
+						GatewayId:                aws.String(igwID),
+						Origin:                   aws.String(ec2.RouteOriginCreateRoute),
+						DestinationIpv6CidrBlock: aws.String(ipv6InternetRoute),
+					},
+				},
+			},
+			RTInfo: &database.RouteTableInfo{},
+			ExpectedRouteInfos: []*database.RouteInfo{
+				{
+					Destination:       ipv6InternetRoute,
+					InternetGatewayID: igwID,
+				},
+			},
+		},
+
+		{
+			Name: "IPv6 route to egress-only IGW",
+			RouteTable: &ec2.RouteTable{
+				Routes: []*ec2.Route{
+					{
+						EgressOnlyInternetGatewayId: aws.String(eigwID),
+						Origin:                      aws.String(ec2.RouteOriginCreateRoute),
+						DestinationIpv6CidrBlock:    aws.String(ipv6InternetRoute),
+					},
+				},
+			},
+			RTInfo: &database.RouteTableInfo{},
+			ExpectedRouteInfos: []*database.RouteInfo{
+				{
+					Destination:                 ipv6InternetRoute,
+					EgressOnlyInternetGatewayID: eigwID,
+				},
+			},
+		},
+
 		{
 			Name: "Error: unrecognized gateway ID",
 			RouteTable: &ec2.RouteTable{
@@ -5877,7 +5877,7 @@ func TestHandleTransitGatewayGovCloudAttachments(t *testing.T) {
 				}
 			}
 			for _, publicRT := range publicRTs {
-				err = updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, tc.TaskData, managedAttachmentsByID, publicRT, database.SubnetTypePublic, database.Region(region), getPrefixListRAM)
+				err = updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, tc.TaskData, managedAttachmentsByID, nil, publicRT, database.SubnetTypePublic, database.Region(region), getPrefixListRAM)
 				if err != nil {
 					if expectedErrorString != "" && err.Error() == expectedErrorString {
 						log.Printf("Got expected error for updateTransitGatewayRoutesForSubnet public: %s", err)
@@ -5900,7 +5900,7 @@ func TestHandleTransitGatewayGovCloudAttachments(t *testing.T) {
 						if !ok {
 							privateRT = &database.RouteTableInfo{}
 						}
-						err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, tc.TaskData, managedAttachmentsByID, privateRT, database.SubnetTypePrivate, database.Region(region), getPrefixListRAM)
+						err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, tc.TaskData, managedAttachmentsByID, nil, privateRT, database.SubnetTypePrivate, database.Region(region), getPrefixListRAM)
 						if err != nil {
 							if expectedErrorString != "" && err.Error() == expectedErrorString {
 								log.Printf("Got expected error for updateTransitGatewayRoutesForSubnet private: %s", err)
@@ -5918,7 +5918,7 @@ func TestHandleTransitGatewayGovCloudAttachments(t *testing.T) {
 							if !ok {
 								customRT = &database.RouteTableInfo{}
 							}
-							err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, tc.TaskData, managedAttachmentsByID, customRT, subnetType, database.Region(region), getPrefixListRAM)
+							err := updateTransitGatewayRoutesForSubnet(ctx, vpc, vpcWriter, tc.TaskData, managedAttachmentsByID, nil, customRT, subnetType, database.Region(region), getPrefixListRAM)
 							if err != nil {
 								if expectedErrorString != "" && err.Error() == expectedErrorString {
 									log.Printf("Got expected error for updateTransitGatewayRoutesForSubnet other: %s", err)
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/go-cmp/cmp"
+)
+
+type vpcEndpointsTestCase struct {
+	Name string
+
+	StartState        database.VPCState
+	ExistingEndpoints []*ec2.VpcEndpoint
+
+	TaskConfig database.UpdateVPCEndpointsTaskData
+
+	ExpectedTaskStatus          database.TaskStatus
+	ExpectedEndpointsDeleted    []string
+	ExpectedEndpointRouteTables map[string][]string // endpoint id -> route tables in AWS
+
+	ExpectedEndState database.VPCState
+}
+
+func TestPerformUpdateVPCEndpoints(t *testing.T) {
+	startState := func() database.VPCState {
+		return database.VPCState{
+			VPCType: database.VPCTypeV1,
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-private-a": {SubnetType: database.SubnetTypePrivate},
+				"rtb-private-b": {SubnetType: database.SubnetTypePrivate},
+				"rtb-public":    {SubnetType: database.SubnetTypePublic},
+			},
+			AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {
+							{SubnetID: "subnet-private-a", GroupName: "private"},
+						},
+						database.SubnetTypeApp: {
+							{SubnetID: "subnet-app-a", GroupName: "app"},
+						},
+					},
+				},
+				"us-east-1b": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {
+							{SubnetID: "subnet-private-b", GroupName: "private"},
+						},
+					},
+				},
+			},
+			SecurityGroups: []*database.SecurityGroup{
+				{TemplateID: 7, SecurityGroupID: "sg-endpoints"},
+			},
+		}
+	}
+	taskConfig := func(setIDs ...uint64) database.UpdateVPCEndpointsTaskData {
+		return database.UpdateVPCEndpointsTaskData{
+			VPCID:             "vpc-abc",
+			AWSRegion:         "us-east-1",
+			VPCEndpointConfig: database.VPCEndpointConfig{VPCEndpointSetIDs: setIDs},
+		}
+	}
+	sets := []*database.VPCEndpointSet{
+		{
+			ID:   1,
+			Name: "s3",
+			Endpoints: []*database.VPCEndpointTemplate{
+				{
+					ID:          10,
+					ServiceName: "s3",
+					Type:        database.VPCEndpointTypeGateway,
+					SubnetTypes: []database.SubnetType{database.SubnetTypePrivate},
+				},
+			},
+		},
+		{
+			ID:   2,
+			Name: "ssm",
+			Endpoints: []*database.VPCEndpointTemplate{
+				{
+					ID:                      20,
+					ServiceName:             "ssm",
+					Type:                    database.VPCEndpointTypeInterface,
+					SubnetTypes:             []database.SubnetType{database.SubnetTypeApp, database.SubnetTypePrivate},
+					SecurityGroupTemplateID: 7,
+					PrivateDNSEnabled:       true,
+				},
+			},
+		},
+		{
+			ID:   3,
+			Name: "unapplied security group",
+			Endpoints: []*database.VPCEndpointTemplate{
+				{
+					ID:                      30,
+					ServiceName:             "com.amazonaws.vpce.us-east-1.vpce-svc-123",
+					Type:                    database.VPCEndpointTypeInterface,
+					SubnetTypes:             []database.SubnetType{database.SubnetTypePrivate},
+					SecurityGroupTemplateID: 8,
+				},
+			},
+		},
+	}
+
+	withEndpoints := func(state database.VPCState, endpoints ...*database.VPCEndpoint) database.VPCState {
+		state.VPCEndpoints = endpoints
+		return state
+	}
+	s3Endpoint := func(id string, routeTableIDs ...string) *database.VPCEndpoint {
+		return &database.VPCEndpoint{
+			TemplateID:    10,
+			VPCEndpointID: id,
+			ServiceName:   "com.amazonaws.us-east-1.s3",
+			Type:          database.VPCEndpointTypeGateway,
+			RouteTableIDs: routeTableIDs,
+		}
+	}
+
+	testCases := []vpcEndpointsTestCase{
+		{
+			Name:       "Create gateway and interface endpoints",
+			StartState: startState(),
+			TaskConfig: taskConfig(1, 2),
+
+			ExpectedTaskStatus: database.TaskStatusSuccessful,
+			ExpectedEndpointRouteTables: map[string][]string{
+				"vpce-1": {"rtb-private-a", "rtb-private-b"},
+			},
+			ExpectedEndState: withEndpoints(
+				startState(),
+				s3Endpoint("vpce-1", "rtb-private-a", "rtb-private-b"),
+				&database.VPCEndpoint{
+					TemplateID:        20,
+					VPCEndpointID:     "vpce-2",
+					ServiceName:       "com.amazonaws.us-east-1.ssm",
+					Type:              database.VPCEndpointTypeInterface,
+					SubnetIDs:         []string{"subnet-app-a", "subnet-private-b"},
+					SecurityGroupIDs:  []string{"sg-endpoints"},
+					PrivateDNSEnabled: true,
+				},
+			),
+		},
+		{
+			Name:       "Delete unconfigured endpoint",
+			StartState: withEndpoints(startState(), s3Endpoint("vpce-old", "rtb-private-a", "rtb-private-b")),
+			ExistingEndpoints: []*ec2.VpcEndpoint{
+				{
+					VpcEndpointId: aws.String("vpce-old"),
+					RouteTableIds: aws.StringSlice([]string{"rtb-private-a", "rtb-private-b"}),
+					State:         aws.String("available"),
+				},
+			},
+			TaskConfig: taskConfig(),
+
+			ExpectedTaskStatus:       database.TaskStatusSuccessful,
+			ExpectedEndpointsDeleted: []string{"vpce-old"},
+			ExpectedEndState:         withEndpoints(startState()),
+		},
+		{
+			Name:       "Add new route table to gateway endpoint",
+			StartState: withEndpoints(startState(), s3Endpoint("vpce-old", "rtb-private-a")),
+			ExistingEndpoints: []*ec2.VpcEndpoint{
+				{
+					VpcEndpointId: aws.String("vpce-old"),
+					RouteTableIds: aws.StringSlice([]string{"rtb-private-a"}),
+					State:         aws.String("available"),
+				},
+			},
+			TaskConfig: taskConfig(1),
+
+			ExpectedTaskStatus: database.TaskStatusSuccessful,
+			ExpectedEndpointRouteTables: map[string][]string{
+				"vpce-old": {"rtb-private-a", "rtb-private-b"},
+			},
+			ExpectedEndState: withEndpoints(startState(), s3Endpoint("vpce-old", "rtb-private-a", "rtb-private-b")),
+		},
+		{
+			Name:       "Security group template not applied",
+			StartState: startState(),
+			TaskConfig: taskConfig(3),
+
+			ExpectedTaskStatus: database.TaskStatusFailed,
+			ExpectedEndState:   startState(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			task := &testmocks.MockTask{
+				ID: 1235,
+			}
+			vpcKey := string(tc.TaskConfig.AWSRegion) + tc.TaskConfig.VPCID
+			mm := &testmocks.MockModelsManager{
+				VPCs: map[string]*database.VPC{
+					vpcKey: {
+						AccountID: "123456",
+						ID:        tc.TaskConfig.VPCID,
+						State:     &tc.StartState,
+						Name:      "chris-east-dev",
+						Stack:     "dev",
+						Region:    tc.TaskConfig.AWSRegion,
+					},
+				},
+				VPCEndpointSets: sets,
+			}
+			ec2 := &testmocks.MockEC2{
+				VPCEndpoints: tc.ExistingEndpoints,
+			}
+			taskContext := &TaskContext{
+				Task:          task,
+				ModelsManager: mm,
+				LockSet:       database.GetFakeLockSet(database.TargetVPC(tc.TaskConfig.VPCID)),
+				BaseAWSAccountAccess: &awsp.AWSAccountAccess{
+					EC2svc: ec2,
+				},
+			}
+
+			taskContext.performUpdateVPCEndpointsTask(&tc.TaskConfig)
+
+			if task.Status != tc.ExpectedTaskStatus {
+				t.Fatalf("Incorrect task status. Expected %s but got %s", tc.ExpectedTaskStatus, task.Status)
+			}
+			if diff := cmp.Diff(tc.ExpectedEndpointsDeleted, ec2.VPCEndpointsDeleted); diff != "" {
+				t.Fatalf("Expected deleted endpoints did not match actual: \n%s", diff)
+			}
+			for id, expected := range tc.ExpectedEndpointRouteTables {
+				var actual []string
+				for _, ep := range ec2.VPCEndpoints {
+					if aws.StringValue(ep.VpcEndpointId) == id {
+						actual = aws.StringValueSlice(ep.RouteTableIds)
+					}
+				}
+				if diff := cmp.Diff(expected, actual); diff != "" {
+					t.Fatalf("Expected route tables for %s did not match actual: \n%s", id, diff)
+				}
+			}
+			if diff := cmp.Diff(&tc.ExpectedEndState, mm.VPCs[vpcKey].State); diff != "" {
+				t.Fatalf("Expected end state did not match state saved to database: \n%s", diff)
+			}
+		})
+	}
+}
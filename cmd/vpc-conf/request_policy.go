@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// Author of comments vpc-conf adds to requests itself
+const policyCommentAuthor = "vpc-conf"
+
+// EvaluateSubmittedVPCRequests evaluates requests as they're submitted. The
+// database announces each new request; ones submitted while vpc-conf wasn't
+// listening are caught up on at startup and after reconnecting.
+func (s *Server) EvaluateSubmittedVPCRequests(postgresConnectionString string) {
+	listener := newPostgresListener(postgresConnectionString)
+	go func() {
+		channelName := "new_vpc_request"
+		err := listener.Listen(channelName)
+		if err != nil {
+			log.Fatalf("Error listening to %q channel: %s", channelName, err)
+		}
+		s.evaluatePendingVPCRequests()
+		for {
+			select {
+			case n := <-listener.Notify:
+				if n == nil {
+					// This happens after reconnecting
+					s.evaluatePendingVPCRequests()
+					continue
+				}
+				id, err := strconv.ParseUint(n.Extra, 10, 64)
+				if err != nil {
+					log.Printf("Invalid VPC request ID %q from postgres", n.Extra)
+					continue
+				}
+				req, err := s.ModelsManager.GetVPCRequest(id)
+				if err != nil {
+					log.Printf("Error getting VPC request %d: %s", id, err)
+					continue
+				}
+				if needsEvaluation(req) {
+					s.handleSubmittedVPCRequest(req)
+				}
+			case <-time.After(60 * time.Second):
+				go listener.Ping() // make the client library notice if the connection dropped
+			}
+		}
+	}()
+}
+
+func needsEvaluation(req *database.VPCRequest) bool {
+	return req.PolicyViolations == nil && req.Status == database.StatusSubmitted && req.TaskID == nil
+}
+
+func (s *Server) evaluatePendingVPCRequests() {
+	reqs, err := s.ModelsManager.GetAllVPCRequests()
+	if err != nil {
+		log.Printf("Error getting VPC requests: %s", err)
+		return
+	}
+	for _, req := range reqs {
+		if needsEvaluation(req) {
+			s.handleSubmittedVPCRequest(req)
+		}
+	}
+}
+
+// handleSubmittedVPCRequest creates the request's issue, so that an
+// automatic approval can be recorded on it, and then evaluates the request.
+func (s *Server) handleSubmittedVPCRequest(req *database.VPCRequest) {
+	if req.JIRAIssue == nil {
+		err := s.createJIRAIssue(req.ID)
+		if err != nil {
+			// SyncVPCRequestStatuses retries
+			log.Printf("Error creating issue: %s", err)
+		} else if updated, err := s.ModelsManager.GetVPCRequest(req.ID); err != nil {
+			log.Printf("Error getting VPC request %d: %s", req.ID, err)
+		} else {
+			req = updated
+		}
+	}
+	err := s.evaluateVPCRequest(req)
+	if err != nil {
+		log.Printf("Error evaluating request %d: %s", req.ID, err)
+	}
+}
+
+// evaluateVPCRequest checks a submitted request against its template and its
+// project's quota. Requests that pass a template marked AutoApprove are
+// provisioned right away and their issue is moved to approved; the rest wait
+// for an approver, with the rules they break posted to the request.
+func (s *Server) evaluateVPCRequest(req *database.VPCRequest) error {
+	// Checked first because quotas count IPs by the sizes
+	violations := append([]string{}, req.RequestedConfig.SubnetSizeViolations(req.RequestType)...)
+	var template *database.VPCRequestTemplate
+	if req.TemplateID == nil {
+		violations = append(violations, "No request template was chosen")
+	} else {
+		var err error
+		template, err = s.ModelsManager.GetVPCRequestTemplate(*req.TemplateID)
+		if err != nil {
+			return fmt.Errorf("Error getting template %d: %s", *req.TemplateID, err)
+		}
+		violations = append(violations, template.Violations(req.RequestType, &req.RequestedConfig)...)
+	}
+	quotaViolations, err := s.quotaViolations(req)
+	if err != nil {
+		return err
+	}
+	violations = append(violations, quotaViolations...)
+
+	if len(violations) > 0 {
+		err := s.ModelsManager.SetVPCRequestPolicyViolations(req.ID, violations)
+		if err != nil {
+			return fmt.Errorf("Error recording policy violations: %s", err)
+		}
+		lines := []string{"This request needs an approver because it doesn't meet these rules:", ""}
+		for _, violation := range violations {
+			lines = append(lines, "- "+violation)
+		}
+		return s.addPolicyComment(req, strings.Join(lines, "\n"))
+	}
+
+	// The requester can still ask for a human to look it over
+	if !template.AutoApprove || !req.RequestedConfig.AutoProvision || s.AutoApproveAsUser == "" {
+		err := s.ModelsManager.SetVPCRequestPolicyViolations(req.ID, violations)
+		if err != nil {
+			return fmt.Errorf("Error recording policy violations: %s", err)
+		}
+		return s.addPolicyComment(req, fmt.Sprintf("This request matches the %q template and is within its project's quota.", template.Name))
+	}
+
+	config := req.RequestedConfig
+	config.AccountID = req.AccountID
+	taskID, err := s.provisionVPCRequest(req, &config, s.AutoApproveAsUser)
+	if err != nil {
+		return fmt.Errorf("Error auto-approving: %s", err)
+	}
+	// Only mark it evaluated once the task exists so failures are retried
+	err = s.ModelsManager.SetVPCRequestPolicyViolations(req.ID, violations)
+	if err != nil {
+		return fmt.Errorf("Error recording policy violations: %s", err)
+	}
+	err = s.ModelsManager.SetVPCRequestStatus(req.ID, database.StatusApproved)
+	if err != nil {
+		return fmt.Errorf("Error setting status: %s", err)
+	}
+	req.Status = database.StatusApproved
+	if req.JIRAIssue != nil {
+		if provider := s.Ticketing.ForIssue(*req.JIRAIssue); provider != nil {
+			// The approval still stands; the issue can be moved by hand
+			err := provider.SetIssueStatus(*req.JIRAIssue, database.StatusApproved)
+			if err != nil {
+				log.Printf("Error moving %s to approved: %s", *req.JIRAIssue, err)
+			}
+		}
+	}
+	return s.addPolicyComment(req, fmt.Sprintf("This request matches the %q template and was approved automatically. Provisioning task: %d", template.Name, taskID))
+}
+
+func (s *Server) quotaViolations(req *database.VPCRequest) ([]string, error) {
+	quota, err := s.ModelsManager.GetProjectQuota(req.ProjectName)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting quota for %s: %s", req.ProjectName, err)
+	}
+	if quota == nil {
+		return nil, nil
+	}
+	usage, err := s.ModelsManager.GetProjectUsage(req.ProjectName)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting usage for %s: %s", req.ProjectName, err)
+	}
+	config := &req.RequestedConfig
+	if req.RequestType == database.RequestTypeNewVPC {
+		return quota.Violations(usage, 1, config.Stack, config.RequestedIPs()), nil
+	}
+	vpc, err := s.ModelsManager.GetVPC(database.Region(config.AWSRegion), config.VPCID)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting %s: %s", config.VPCID, err)
+	}
+	numAZs := 0
+	if vpc.State != nil {
+		numAZs = len(vpc.State.AvailabilityZones)
+	}
+	return quota.Violations(usage, 0, vpc.Stack, database.SubnetIPs(int64(numAZs), config.SubnetSize)), nil
+}
+
+func (s *Server) addPolicyComment(req *database.VPCRequest, body string) error {
+	// Picked up by the sync and posted to the request's issue
+	_, err := s.ModelsManager.InsertVPCRequestComment(&database.VPCRequestComment{
+		VPCRequestID: req.ID,
+		Author:       policyCommentAuthor,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("Error adding comment: %s", err)
+	}
+	return nil
+}
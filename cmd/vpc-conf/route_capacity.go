@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// Route tables with at least this fraction of the routes per route table
+// quota are near their limit.
+const routeTableCapacityWarningRatio = 0.8
+
+// transitGatewayRouteTables returns the subnet type of each route table
+// that updateTransitGatewayRoutesForSubnet is called on by the networking
+// task.
+func transitGatewayRouteTables(vpc *database.VPC) map[string]database.SubnetType {
+	routeTables := make(map[string]database.SubnetType)
+	isLegacy := vpc.State.VPCType == database.VPCTypeLegacy
+	for _, az := range vpc.State.AvailabilityZones {
+		for subnetType, subnets := range az.Subnets {
+			for _, subnet := range subnets {
+				if subnet.CustomRouteTableID != "" {
+					routeTables[subnet.CustomRouteTableID] = subnetType
+				}
+			}
+		}
+		if isLegacy {
+			continue
+		}
+		if az.PrivateRouteTableID != "" {
+			routeTables[az.PrivateRouteTableID] = database.SubnetTypePrivate
+		}
+		if vpc.State.VPCType.HasFirewall() && az.PublicRouteTableID != "" {
+			routeTables[az.PublicRouteTableID] = database.SubnetTypePublic
+		}
+	}
+	if !isLegacy && !vpc.State.VPCType.HasFirewall() && vpc.State.PublicRouteTableID != "" {
+		routeTables[vpc.State.PublicRouteTableID] = database.SubnetTypePublic
+	}
+	for rtID, subnetType := range routeTables {
+		if subnetType == database.SubnetTypeUnroutable || subnetType == database.SubnetTypeFirewall {
+			delete(routeTables, rtID)
+		}
+	}
+	return routeTables
+}
+
+// projectRouteTableSizes returns how many routes each route table that gets
+// transit gateway routes will have once the given attachments and VPN
+// connections are applied, starting from the routes currently in AWS.
+// Routes to prefix lists count for the list's max entries.
+func projectRouteTableSizes(
+	current map[string]*database.RouteTableSnapshotEntry,
+	vpc *database.VPC,
+	managedAttachmentIDs []uint64,
+	managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment,
+	vpnConnections []*database.VPNConnection,
+	prefixListWeight func(prefixListID string) (int, error)) ([]*database.RouteTableCapacity, error) {
+	subnetTypesAndRoutesByTGID := transitGatewayRoutesBySubnetType(managedAttachmentIDs, managedAttachmentsByID, vpnConnections)
+
+	capacities := []*database.RouteTableCapacity{}
+	for rtID, subnetType := range transitGatewayRouteTables(vpc) {
+		destinations := make(map[string]bool)
+		if entry, ok := current[rtID]; ok {
+			for _, route := range entry.Routes {
+				destinations[route.Destination] = true
+			}
+		}
+		expected := make(map[string]bool)
+		for _, routesBySubnetType := range subnetTypesAndRoutesByTGID {
+			for route := range routesBySubnetType[subnetType] {
+				if awsp.IsIPv6CIDR(route) && vpc.State.IPv6 == nil {
+					continue
+				}
+				expected[route] = true
+				destinations[route] = true
+			}
+		}
+		// Routes through a managed TG that no longer apply get deleted
+		if rt, ok := vpc.State.RouteTables[rtID]; ok {
+			for _, route := range rt.Routes {
+				if _, managed := subnetTypesAndRoutesByTGID[route.TransitGatewayID]; managed && !expected[route.Destination] {
+					delete(destinations, route.Destination)
+				}
+			}
+		}
+		capacity := &database.RouteTableCapacity{
+			RouteTableID: rtID,
+			SubnetType:   subnetType,
+		}
+		for destination := range destinations {
+			if awsp.IsPrefixListID(destination) {
+				weight, err := prefixListWeight(destination)
+				if err != nil {
+					return nil, err
+				}
+				capacity.Routes += weight
+			} else {
+				capacity.Routes++
+			}
+		}
+		capacities = append(capacities, capacity)
+	}
+	sort.Slice(capacities, func(i, j int) bool {
+		return capacities[i].RouteTableID < capacities[j].RouteTableID
+	})
+	return capacities, nil
+}
+
+// projectRouteTableCapacity is projectRouteTableSizes for the VPC using
+// access to its account. getPrefixListEC2 is for the account that owns the
+// managed prefix lists, which may not be shared with the VPC's account yet.
+// The quota is only looked up if a route table could be near it.
+//
+// Failed lookups are passed to warn rather than returned so that a missing
+// permission can't block the task: a prefix list that can't be described
+// counts as a single route, which keeps the projection a lower bound, and
+// quotaConfirmed is false if the applied quota couldn't be looked up.
+func projectRouteTableCapacity(
+	access *awsp.AWSAccountAccess,
+	getPrefixListEC2 func() (ec2iface.EC2API, error),
+	warn func(format string, v ...interface{}),
+	vpc *database.VPC,
+	managedAttachmentIDs []uint64,
+	managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment,
+	vpnConnections []*database.VPNConnection) (capacity *database.VPCRouteTableCapacity, quotaConfirmed bool, err error) {
+	current, err := captureRouteTables(access.EC2(), vpc)
+	if err != nil {
+		return nil, false, err
+	}
+	weights := make(map[string]int)
+	maxEntries := func(prefixListID string) (int, error) {
+		maxEntries, found, err := awsp.PrefixListMaxEntries(access.EC2(), prefixListID)
+		if err != nil {
+			return 0, fmt.Errorf("Error describing prefix list %s: %s", prefixListID, err)
+		}
+		if !found {
+			plEC2, err := getPrefixListEC2()
+			if err != nil {
+				return 0, err
+			}
+			maxEntries, found, err = awsp.PrefixListMaxEntries(plEC2, prefixListID)
+			if err != nil {
+				return 0, fmt.Errorf("Error describing prefix list %s: %s", prefixListID, err)
+			}
+		}
+		if !found || maxEntries < 1 {
+			maxEntries = 1
+		}
+		return maxEntries, nil
+	}
+	prefixListWeight := func(prefixListID string) (int, error) {
+		if weight, ok := weights[prefixListID]; ok {
+			return weight, nil
+		}
+		weight, err := maxEntries(prefixListID)
+		if err != nil {
+			warn("%s; counting %s as 1 route", err, prefixListID)
+			weight = 1
+		}
+		weights[prefixListID] = weight
+		return weight, nil
+	}
+	routeTables, err := projectRouteTableSizes(current, vpc, managedAttachmentIDs, managedAttachmentsByID, vpnConnections, prefixListWeight)
+	if err != nil {
+		return nil, false, err
+	}
+
+	capacity = &database.VPCRouteTableCapacity{
+		VPCID:       vpc.ID,
+		Region:      vpc.Region,
+		AccountID:   vpc.AccountID,
+		VPCName:     vpc.Name,
+		Quota:       awsp.DefaultRoutesPerRouteTable,
+		RouteTables: routeTables,
+	}
+	// Applied quotas are never below the default
+	quotaConfirmed = true
+	for _, rt := range routeTables {
+		if rt.NearQuota(awsp.DefaultRoutesPerRouteTable, routeTableCapacityWarningRatio) {
+			quota, err := awsp.RoutesPerRouteTableQuota(access.ServiceQuotas())
+			if err != nil {
+				warn("Error getting routes per route table quota, assuming the default of %d: %s", awsp.DefaultRoutesPerRouteTable, err)
+				quotaConfirmed = false
+			} else {
+				capacity.Quota = quota
+			}
+			break
+		}
+	}
+	return capacity, quotaConfirmed, nil
+}
+
+func routeTableCapacityOverQuota(capacity *database.VPCRouteTableCapacity) []*database.RouteTableCapacity {
+	over := []*database.RouteTableCapacity{}
+	for _, rt := range capacity.RouteTables {
+		if rt.Routes > capacity.Quota {
+			over = append(over, rt)
+		}
+	}
+	return over
+}
+
+func routeTableCapacityNearQuota(capacity *database.VPCRouteTableCapacity) []*database.RouteTableCapacity {
+	near := []*database.RouteTableCapacity{}
+	for _, rt := range capacity.RouteTables {
+		if rt.Routes <= capacity.Quota && rt.NearQuota(capacity.Quota, routeTableCapacityWarningRatio) {
+			near = append(near, rt)
+		}
+	}
+	return near
+}
+
+func describeRouteTableCapacity(vpc *database.VPC, rt *database.RouteTableCapacity, quota int) string {
+	return fmt.Sprintf("route table %s (%s) in %s would have %d routes and the quota is %d", rt.RouteTableID, rt.SubnetType, vpc.ID, rt.Routes, quota)
+}
+
+func prefixListAccountIDForRegion(region database.Region) string {
+	if region.IsGovCloud() {
+		return prefixListAccountIDGovCloud
+	}
+	return prefixListAccountIDCommercial
+}
+
+// checkRouteTableCapacity fails before any routes are changed if a route
+// table would go over the routes per route table quota, which would
+// otherwise fail the task partway through adding routes. The check is
+// best-effort: it only fails when going over the quota is confirmed, and
+// errors looking anything up are logged as warnings.
+func (taskContext *TaskContext) checkRouteTableCapacity(
+	ctx *awsp.Context,
+	vpc *database.VPC,
+	managedAttachmentIDs []uint64,
+	managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment,
+	vpnConnections []*database.VPNConnection) error {
+	warn := func(format string, v ...interface{}) {
+		ctx.Log("Warning: "+format, v...)
+	}
+	capacity, quotaConfirmed, err := projectRouteTableCapacity(
+		ctx.AWSAccountAccess,
+		func() (ec2iface.EC2API, error) {
+			accountID := prefixListAccountIDForRegion(vpc.Region)
+			access, err := taskContext.AWSAccountAccessProvider.AccessAccount(accountID, string(vpc.Region), taskContext.AsUser)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting credentials for account %s: %s", accountID, err)
+			}
+			return access.EC2(), nil
+		},
+		warn, vpc, managedAttachmentIDs, managedAttachmentsByID, vpnConnections)
+	if err != nil {
+		warn("Not checking route table capacity: %s", err)
+		return nil
+	}
+	err = taskContext.ModelsManager.SaveRouteTableCapacity(capacity)
+	if err != nil {
+		ctx.Log("Error saving route table capacity: %s", err)
+	}
+	for _, rt := range routeTableCapacityNearQuota(capacity) {
+		ctx.Log("Warning: %s", describeRouteTableCapacity(vpc, rt, capacity.Quota))
+	}
+	over := routeTableCapacityOverQuota(capacity)
+	if len(over) > 0 {
+		if !quotaConfirmed {
+			for _, rt := range over {
+				warn("%s unless the quota has been raised", describeRouteTableCapacity(vpc, rt, capacity.Quota))
+			}
+			return nil
+		}
+		return fmt.Errorf("Not updating routes because %s. Remove routes or request a quota increase first.", describeRouteTableCapacity(vpc, over[0], capacity.Quota))
+	}
+	return nil
+}
+
+type RouteTableCapacityReportEntry struct {
+	VPCID        string
+	Region       database.Region
+	AccountID    string
+	VPCName      string
+	RouteTableID string
+	SubnetType   database.SubnetType
+	Routes       int
+	Quota        int
+	UpdatedAt    time.Time
+}
+
+// handleRouteTableCapacityReport lists the route tables across all VPCs
+// that had at least ?threshold (default 0.8) of their quota as of the last
+// networking task, fullest first.
+var handleRouteTableCapacityReport = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleRouteTableCapacityReport but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	threshold := routeTableCapacityWarningRatio
+	if t := r.URL.Query().Get("threshold"); t != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(t, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid threshold: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	capacities, err := s.ModelsManager.GetRouteTableCapacities()
+	if err != nil {
+		log.Printf("Error getting route table capacities: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	report := []*RouteTableCapacityReportEntry{}
+	for _, capacity := range capacities {
+		for _, rt := range capacity.RouteTables {
+			if !rt.NearQuota(capacity.Quota, threshold) {
+				continue
+			}
+			report = append(report, &RouteTableCapacityReportEntry{
+				VPCID:        capacity.VPCID,
+				Region:       capacity.Region,
+				AccountID:    capacity.AccountID,
+				VPCName:      capacity.VPCName,
+				RouteTableID: rt.RouteTableID,
+				SubnetType:   rt.SubnetType,
+				Routes:       rt.Routes,
+				Quota:        capacity.Quota,
+				UpdatedAt:    capacity.UpdatedAt,
+			})
+		}
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		return report[i].Routes*report[j].Quota > report[j].Routes*report[i].Quota
+	})
+
+	buf, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRouteTableCapacity(t *testing.T) {
+	route := func(destination string) *ec2.Route {
+		return &ec2.Route{DestinationCidrBlock: aws.String(destination), Origin: aws.String(ec2.RouteOriginCreateRoute)}
+	}
+	ec2svc := &testmocks.MockEC2{
+		RouteTables: []*ec2.RouteTable{
+			{
+				RouteTableId: aws.String("rtb-private"),
+				VpcId:        aws.String("vpc-abc"),
+				Routes: []*ec2.Route{
+					{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), Origin: aws.String(ec2.RouteOriginCreateRouteTable)},
+					route("0.0.0.0/0"),
+					route("10.1.0.0/16"),
+					route("10.2.0.0/16"),
+					route("172.16.0.0/12"),
+				},
+			},
+			{
+				RouteTableId: aws.String("rtb-public"),
+				VpcId:        aws.String("vpc-abc"),
+				Routes:       []*ec2.Route{route("0.0.0.0/0")},
+			},
+		},
+	}
+	// Only shared with the VPC's account once the networking task runs
+	prefixListEC2 := &testmocks.MockEC2{
+		PrefixLists: []*ec2.ManagedPrefixList{{PrefixListId: aws.String("pl-big"), MaxEntries: aws.Int64(45)}},
+	}
+	vpc := &database.VPC{
+		ID:        "vpc-abc",
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		State: &database.VPCState{
+			VPCType:            database.VPCTypeV1,
+			PublicRouteTableID: "rtb-public",
+			AvailabilityZones: map[string]*database.AvailabilityZoneInfra{
+				"us-east-1a": {PrivateRouteTableID: "rtb-private"},
+			},
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-private": {
+					RouteTableID: "rtb-private",
+					SubnetType:   database.SubnetTypePrivate,
+					Routes: []*database.RouteInfo{
+						{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"},
+						{Destination: "10.1.0.0/16", TransitGatewayID: "tgw-1"},
+						{Destination: "10.2.0.0/16", TransitGatewayID: "tgw-1"},
+					},
+				},
+				"rtb-public": {RouteTableID: "rtb-public", SubnetType: database.SubnetTypePublic},
+			},
+		},
+	}
+	managedAttachmentsByID := map[uint64]*database.ManagedTransitGatewayAttachment{
+		1: {
+			ID:               1,
+			TransitGatewayID: "tgw-1",
+			SubnetTypes:      []database.SubnetType{database.SubnetTypePrivate},
+			Routes:           []string{"10.1.0.0/16", "10.3.0.0/16", "pl-big", "2600:1f00::/40"},
+		},
+		2: {
+			ID:               2,
+			TransitGatewayID: "tgw-2",
+			SubnetTypes:      []database.SubnetType{database.SubnetTypePrivate, database.SubnetTypePublic},
+			Routes:           []string{"10.4.0.0/16", "10.5.0.0/16"},
+		},
+	}
+	mm := &testmocks.MockModelsManager{}
+	taskContext := &TaskContext{
+		AWSAccountAccessProvider: &testmocks.MockAWSAccountAccessProvider{EC2: prefixListEC2},
+		ModelsManager:            mm,
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{
+			EC2svc:           ec2svc,
+			ServiceQuotassvc: &testmocks.MockServiceQuotas{DefaultQuotas: map[string]float64{awsp.RoutesPerRouteTableQuotaCode: 50}},
+		},
+		Logger: &testLogger{},
+		VPCID:  vpc.ID,
+	}
+
+	// 10.2.0.0/16 is removed, IPv6 is skipped and pl-big counts for 45
+	err := taskContext.checkRouteTableCapacity(ctx, vpc, []uint64{1}, managedAttachmentsByID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []*database.VPCRouteTableCapacity{
+		{
+			VPCID:     "vpc-abc",
+			Region:    "us-east-1",
+			AccountID: "123456789012",
+			Quota:     50,
+			RouteTables: []*database.RouteTableCapacity{
+				{RouteTableID: "rtb-private", SubnetType: database.SubnetTypePrivate, Routes: 49},
+				{RouteTableID: "rtb-public", SubnetType: database.SubnetTypePublic, Routes: 1},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, mm.RouteTableCapacities, cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".UpdatedAt"
+	}, cmp.Ignore())); diff != "" {
+		t.Errorf("Expected capacity did not match actual: \n%s", diff)
+	}
+	near := routeTableCapacityNearQuota(mm.RouteTableCapacities[0])
+	if len(near) != 1 || near[0].RouteTableID != "rtb-private" {
+		t.Errorf("Expected only rtb-private to be near its quota but got %+v", near)
+	}
+
+	err = taskContext.checkRouteTableCapacity(ctx, vpc, []uint64{1, 2}, managedAttachmentsByID, nil)
+	if err == nil || !strings.Contains(err.Error(), "rtb-private") {
+		t.Fatalf("Expected rtb-private to go over its quota but got %v", err)
+	}
+	if len(ec2svc.RoutesAdded) > 0 {
+		t.Errorf("Expected no routes to be added but got %+v", ec2svc.RoutesAdded)
+	}
+
+	// A raised quota is used when there is one
+	ctx.ServiceQuotassvc = &testmocks.MockServiceQuotas{AppliedQuotas: map[string]float64{awsp.RoutesPerRouteTableQuotaCode: 100}}
+	err = taskContext.checkRouteTableCapacity(ctx, vpc, []uint64{1, 2}, managedAttachmentsByID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if quota := mm.RouteTableCapacities[0].Quota; quota != 100 {
+		t.Errorf("Expected quota of 100 but got %d", quota)
+	}
+
+	// Without permission to look up the quota, going over the default is only a warning
+	ctx.ServiceQuotassvc = &testmocks.MockServiceQuotas{Err: awserr.New("AccessDeniedException", "Not authorized", nil)}
+	err = taskContext.checkRouteTableCapacity(ctx, vpc, []uint64{1, 2}, managedAttachmentsByID, nil)
+	if err != nil {
+		t.Fatalf("Expected only a warning when the quota can't be looked up but got %s", err)
+	}
+
+	// Without credentials for the prefix list account, pl-big counts as 1 route
+	taskContext.AWSAccountAccessProvider = &testmocks.MockAWSAccountAccessProvider{Err: errors.New("No credentials")}
+	ctx.ServiceQuotassvc = &testmocks.MockServiceQuotas{DefaultQuotas: map[string]float64{awsp.RoutesPerRouteTableQuotaCode: 50}}
+	err = taskContext.checkRouteTableCapacity(ctx, vpc, []uint64{1, 2}, managedAttachmentsByID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if routes := mm.RouteTableCapacities[0].RouteTables[0].Routes; routes != 7 {
+		t.Errorf("Expected rtb-private to have 7 routes but got %d", routes)
+	}
+}
@@ -0,0 +1,591 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// routeChangingTaskVPC returns the VPC whose routes the task may change, if
+// any, so that its route tables can be snapshotted first.
+func routeChangingTaskVPC(taskData *database.TaskData) (database.Region, string) {
+	if taskData.UpdateNetworkingTaskData != nil {
+		return taskData.UpdateNetworkingTaskData.AWSRegion, taskData.UpdateNetworkingTaskData.VPCID
+	} else if taskData.RepairVPCTaskData != nil {
+		// Only networking and gateway endpoint repairs touch routes
+		spec := taskData.RepairVPCTaskData.Spec
+		if spec.VerifyNetworking || spec.VerifyVPCEndpoints {
+			return taskData.RepairVPCTaskData.Region, taskData.RepairVPCTaskData.VPCID
+		}
+	} else if taskData.AddZonedSubnetsTaskData != nil {
+		return taskData.AddZonedSubnetsTaskData.Region, taskData.AddZonedSubnetsTaskData.VPCID
+	} else if taskData.RemoveZonedSubnetsTaskData != nil {
+		return taskData.RemoveZonedSubnetsTaskData.Region, taskData.RemoveZonedSubnetsTaskData.VPCID
+	} else if taskData.AddAvailabilityZoneTaskData != nil {
+		return taskData.AddAvailabilityZoneTaskData.Region, taskData.AddAvailabilityZoneTaskData.VPCID
+	} else if taskData.RemoveAvailabilityZoneTaskData != nil {
+		return taskData.RemoveAvailabilityZoneTaskData.Region, taskData.RemoveAvailabilityZoneTaskData.VPCID
+	} else if taskData.UpdateVPCTypeTaskData != nil {
+		return taskData.UpdateVPCTypeTaskData.AWSRegion, taskData.UpdateVPCTypeTaskData.VPCID
+	} else if taskData.UpdateVPCEndpointsTaskData != nil {
+		return taskData.UpdateVPCEndpointsTaskData.AWSRegion, taskData.UpdateVPCEndpointsTaskData.VPCID
+	} else if taskData.DeleteUnusedResourcesTaskData != nil {
+		return taskData.DeleteUnusedResourcesTaskData.AWSRegion, taskData.DeleteUnusedResourcesTaskData.VPCID
+	} else if taskData.RestoreRouteTablesTaskData != nil {
+		return taskData.RestoreRouteTablesTaskData.Region, taskData.RestoreRouteTablesTaskData.VPCID
+	} else if taskData.UpdateExceptionVPCTaskData != nil {
+		return taskData.UpdateExceptionVPCTaskData.Region, taskData.UpdateExceptionVPCTaskData.VPCID
+	}
+	return "", ""
+}
+
+func snapshotRoute(route *ec2.Route) *database.SnapshotRoute {
+	sr := &database.SnapshotRoute{
+		RouteInfo: database.RouteInfo{
+			Destination:                 awsp.RouteDestination(route),
+			NATGatewayID:                aws.StringValue(route.NatGatewayId),
+			EgressOnlyInternetGatewayID: aws.StringValue(route.EgressOnlyInternetGatewayId),
+			TransitGatewayID:            aws.StringValue(route.TransitGatewayId),
+			PeeringConnectionID:         aws.StringValue(route.VpcPeeringConnectionId),
+		},
+	}
+	gatewayID := aws.StringValue(route.GatewayId)
+	if awsp.IsInternetGatewayID(gatewayID) {
+		sr.InternetGatewayID = gatewayID
+	} else if awsp.IsVPCEndpointID(gatewayID) {
+		sr.VPCEndpointID = gatewayID
+	} else if gatewayID != "" {
+		sr.OtherTargetID = gatewayID
+	}
+	for _, id := range []*string{route.InstanceId, route.LocalGatewayId, route.CarrierGatewayId, route.NetworkInterfaceId} {
+		if aws.StringValue(id) != "" {
+			sr.OtherTargetID = aws.StringValue(id)
+		}
+	}
+	return sr
+}
+
+// captureRouteTables reads every route table in the VPC from AWS. Local and
+// propagated routes are left out since they can't be set.
+func captureRouteTables(ec2svc ec2iface.EC2API, vpc *database.VPC) (map[string]*database.RouteTableSnapshotEntry, error) {
+	out, err := ec2svc.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-id"),
+				Values: []*string{aws.String(vpc.ID)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error describing route tables: %s", err)
+	}
+	routeTables := map[string]*database.RouteTableSnapshotEntry{}
+	for _, rt := range out.RouteTables {
+		var rtInState *database.RouteTableInfo
+		if vpc.State != nil {
+			rtInState = vpc.State.RouteTables[aws.StringValue(rt.RouteTableId)]
+		}
+		entry := &database.RouteTableSnapshotEntry{
+			Managed: rtInState != nil,
+			Routes:  []*database.SnapshotRoute{},
+		}
+		for _, route := range rt.Routes {
+			if aws.StringValue(route.Origin) != ec2.RouteOriginCreateRoute || awsp.RouteDestination(route) == "" {
+				continue
+			}
+			sr := snapshotRoute(route)
+			if rtInState != nil {
+				for _, info := range rtInState.Routes {
+					if info.Destination == sr.Destination {
+						sr.Managed = true
+					}
+				}
+			}
+			entry.Routes = append(entry.Routes, sr)
+		}
+		routeTables[aws.StringValue(rt.RouteTableId)] = entry
+	}
+	return routeTables, nil
+}
+
+// Only the most recent snapshots of each VPC are kept
+const maxRouteTableSnapshotsPerVPC = 50
+
+func takeRouteTableSnapshot(ctx *awsp.Context, mm database.ModelsManager, vpc *database.VPC, reason string, taskID *uint64) (*database.RouteTableSnapshot, error) {
+	routeTables, err := captureRouteTables(ctx.EC2(), vpc)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &database.RouteTableSnapshot{
+		VPCID:       vpc.ID,
+		Region:      vpc.Region,
+		Reason:      reason,
+		TaskID:      taskID,
+		RouteTables: routeTables,
+	}
+	err = mm.CreateRouteTableSnapshot(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Error saving route table snapshot: %s", err)
+	}
+	ctx.Log("Saved snapshot %d of %d route tables", snapshot.ID, len(routeTables))
+	deleted, err := mm.PruneRouteTableSnapshots(vpc.Region, vpc.ID, maxRouteTableSnapshotsPerVPC)
+	if err != nil {
+		ctx.Log("Error deleting old route table snapshots: %s", err)
+	} else if deleted > 0 {
+		ctx.Log("Deleted %d old route table snapshots", deleted)
+	}
+	return snapshot, nil
+}
+
+// snapshotRouteTables is run before any task that can change routes.
+func (taskContext *TaskContext) snapshotRouteTables(region database.Region, vpcID, reason string) error {
+	vpc, err := taskContext.ModelsManager.GetVPC(region, vpcID)
+	if err != nil {
+		return fmt.Errorf("Error loading VPC: %s", err)
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: taskContext.BaseAWSAccountAccess,
+		Logger:           taskContext.Task,
+		VPCID:            vpc.ID,
+		VPCName:          vpc.Name,
+	}
+	taskID := taskContext.Task.GetID()
+	_, err = takeRouteTableSnapshot(ctx, taskContext.ModelsManager, vpc, reason, &taskID)
+	return err
+}
+
+func sortedRouteTableIDs(routeTables map[string]*database.RouteTableSnapshotEntry) []string {
+	ids := []string{}
+	for id := range routeTables {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sameRouteTarget(a, b *database.SnapshotRoute) bool {
+	return a.RouteInfo == b.RouteInfo && a.OtherTargetID == b.OtherTargetID
+}
+
+type routeChange struct {
+	RouteTableID string
+	Destination  string
+	Before       *database.SnapshotRoute // nil if the route was added
+	After        *database.SnapshotRoute // nil if the route was removed
+}
+
+type routeTableDiff struct {
+	AddedRouteTables   []string
+	RemovedRouteTables []string
+	Changes            []*routeChange
+}
+
+func diffRouteTables(before, after map[string]*database.RouteTableSnapshotEntry) *routeTableDiff {
+	diff := &routeTableDiff{
+		AddedRouteTables:   []string{},
+		RemovedRouteTables: []string{},
+		Changes:            []*routeChange{},
+	}
+	for _, rtID := range sortedRouteTableIDs(after) {
+		if _, ok := before[rtID]; !ok {
+			diff.AddedRouteTables = append(diff.AddedRouteTables, rtID)
+		}
+	}
+	for _, rtID := range sortedRouteTableIDs(before) {
+		afterEntry, ok := after[rtID]
+		if !ok {
+			diff.RemovedRouteTables = append(diff.RemovedRouteTables, rtID)
+			continue
+		}
+		afterByDestination := map[string]*database.SnapshotRoute{}
+		for _, route := range afterEntry.Routes {
+			afterByDestination[route.Destination] = route
+		}
+		seen := map[string]bool{}
+		for _, route := range before[rtID].Routes {
+			seen[route.Destination] = true
+			afterRoute := afterByDestination[route.Destination]
+			if afterRoute == nil || !sameRouteTarget(route, afterRoute) {
+				diff.Changes = append(diff.Changes, &routeChange{RouteTableID: rtID, Destination: route.Destination, Before: route, After: afterRoute})
+			}
+		}
+		for _, route := range afterEntry.Routes {
+			if !seen[route.Destination] {
+				diff.Changes = append(diff.Changes, &routeChange{RouteTableID: rtID, Destination: route.Destination, After: route})
+			}
+		}
+	}
+	return diff
+}
+
+// restoreRouteTableSnapshot replays the snapshot through setRoute. Route
+// tables that no longer exist are skipped, and routes to targets setRoute
+// can't create are left as they are.
+func restoreRouteTableSnapshot(ctx *awsp.Context, vpc *database.VPC, vpcWriter database.VPCWriter, snapshot *database.RouteTableSnapshot) error {
+	current, err := captureRouteTables(ctx.EC2(), vpc)
+	if err != nil {
+		return err
+	}
+	for _, rtID := range sortedRouteTableIDs(snapshot.RouteTables) {
+		now, ok := current[rtID]
+		if !ok {
+			ctx.Log("Route table %s no longer exists, skipping", rtID)
+			continue
+		}
+		routesInAWS := []*database.RouteInfo{}
+		nowByDestination := map[string]*database.SnapshotRoute{}
+		for _, route := range now.Routes {
+			info := route.RouteInfo
+			routesInAWS = append(routesInAWS, &info)
+			nowByDestination[route.Destination] = route
+		}
+		inSnapshot := map[string]bool{}
+		managedRoutes := []*database.RouteInfo{}
+		for _, route := range snapshot.RouteTables[rtID].Routes {
+			inSnapshot[route.Destination] = true
+			nowRoute := nowByDestination[route.Destination]
+			if nowRoute != nil && sameRouteTarget(route, nowRoute) {
+				if route.Managed {
+					info := route.RouteInfo
+					managedRoutes = append(managedRoutes, &info)
+				}
+				continue
+			}
+			if route.OtherTargetID != "" {
+				ctx.Log("Cannot restore route %s -> %s on route table %s", route.Destination, route.OtherTargetID, rtID)
+				continue
+			}
+			desired := route.RouteInfo
+			routesInAWS, err = setRoute(ctx, rtID, route.Destination, routesInAWS, &desired)
+			if err != nil {
+				return fmt.Errorf("Error restoring route %s on route table %s: %s", route.Destination, rtID, err)
+			}
+			if route.Managed {
+				info := route.RouteInfo
+				managedRoutes = append(managedRoutes, &info)
+			}
+		}
+		for _, route := range now.Routes {
+			if inSnapshot[route.Destination] {
+				continue
+			}
+			routesInAWS, err = setRoute(ctx, rtID, route.Destination, routesInAWS, nil)
+			if err != nil {
+				return fmt.Errorf("Error deleting route %s on route table %s: %s", route.Destination, rtID, err)
+			}
+		}
+		if rtInState := vpc.State.RouteTables[rtID]; rtInState != nil {
+			rtInState.Routes = managedRoutes
+			err := vpcWriter.UpdateState(vpc.State)
+			if err != nil {
+				return fmt.Errorf("Error updating state: %s", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (taskContext *TaskContext) performSnapshotRouteTablesTask(config *database.SnapshotRouteTablesTaskData) {
+	t := taskContext.Task
+	setStatus(t, database.TaskStatusInProgress)
+
+	err := taskContext.snapshotRouteTables(config.Region, config.VPCID, "Requested by "+taskContext.AsUser)
+	if err != nil {
+		t.Log("Error taking route table snapshot: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+func (taskContext *TaskContext) performRestoreRouteTablesTask(config *database.RestoreRouteTablesTaskData) {
+	t := taskContext.Task
+	setStatus(t, database.TaskStatusInProgress)
+
+	snapshot, err := taskContext.ModelsManager.GetRouteTableSnapshot(config.SnapshotID)
+	if err != nil {
+		t.Log("Error loading snapshot %d: %s", config.SnapshotID, err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if snapshot.VPCID != config.VPCID || snapshot.Region != config.Region {
+		t.Log("Snapshot %d is for %s/%s, not %s/%s", snapshot.ID, snapshot.Region, snapshot.VPCID, config.Region, config.VPCID)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	vpc, vpcWriter, err := taskContext.ModelsManager.GetOperableVPC(taskContext.LockSet, config.Region, config.VPCID)
+	if err != nil {
+		t.Log("Error loading state: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	if vpc.State == nil {
+		t.Log("VPC %s is not managed", vpc.ID)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: taskContext.BaseAWSAccountAccess,
+		Logger:           t,
+		VPCID:            vpc.ID,
+		VPCName:          vpc.Name,
+	}
+
+	t.Log("Restoring routes from snapshot %d taken %s (%s)", snapshot.ID, snapshot.CreatedAt.Format("2006-01-02 15:04:05 MST"), snapshot.Reason)
+	err = restoreRouteTableSnapshot(ctx, vpc, vpcWriter, snapshot)
+	if err != nil {
+		t.Log("%s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	setStatus(t, database.TaskStatusSuccessful)
+}
+
+type routeTableSnapshotSummary struct {
+	ID              uint64
+	CreatedAt       string
+	Reason          string
+	TaskID          *uint64
+	RouteTableCount int
+	RouteCount      int
+}
+
+// getRouteTableSnapshotForVPC checks that the snapshot belongs to the VPC in
+// the URL, since its ID alone is enough to look it up.
+func (s *Server) getRouteTableSnapshotForVPC(region, vpcID, idString string) (*database.RouteTableSnapshot, error) {
+	id, err := strconv.ParseUint(idString, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid snapshot ID %q", idString)
+	}
+	snapshot, err := s.ModelsManager.GetRouteTableSnapshot(id)
+	if err != nil || snapshot.VPCID != vpcID || string(snapshot.Region) != region {
+		return nil, fmt.Errorf("No snapshot %d for %s", id, vpcID)
+	}
+	return snapshot, nil
+}
+
+func (s *Server) queueRouteTablesTask(w http.ResponseWriter, r *http.Request, accountID, vpcID, description string, taskData *database.TaskData) {
+	taskData.AsUser = s.getSession(r).Username
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, description, taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	buf, err := json.Marshal(map[string]uint64{"TaskID": t.ID})
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleRouteTableSnapshotList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleRouteTableSnapshotList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	vpcID := args[2]
+
+	snapshots, err := s.ModelsManager.GetRouteTableSnapshots(database.Region(region), vpcID)
+	if err != nil {
+		log.Printf("Error listing route table snapshots: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	summaries := []*routeTableSnapshotSummary{}
+	for _, snapshot := range snapshots {
+		summary := &routeTableSnapshotSummary{
+			ID:              snapshot.ID,
+			CreatedAt:       snapshot.CreatedAt.Format("2006-01-02 15:04:05 MST"),
+			Reason:          snapshot.Reason,
+			TaskID:          snapshot.TaskID,
+			RouteTableCount: len(snapshot.RouteTables),
+		}
+		for _, entry := range snapshot.RouteTables {
+			summary.RouteCount += len(entry.Routes)
+		}
+		summaries = append(summaries, summary)
+	}
+	buf, err := json.Marshal(summaries)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleRouteTableSnapshotDetails = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 4 {
+		log.Printf("Expected 4 additional args to handleRouteTableSnapshotDetails but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	snapshot, err := s.getRouteTableSnapshotForVPC(args[0], args[2], args[3])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	buf, err := json.Marshal(snapshot)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+// handleRouteTableSnapshotDiff shows what has changed since the snapshot,
+// either in AWS now or as of another snapshot given with ?against=ID.
+var handleRouteTableSnapshotDiff = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 4 {
+		log.Printf("Expected 4 additional args to handleRouteTableSnapshotDiff but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	snapshot, err := s.getRouteTableSnapshotForVPC(region, vpcID, args[3])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var after map[string]*database.RouteTableSnapshotEntry
+	if against := r.URL.Query().Get("against"); against != "" {
+		other, err := s.getRouteTableSnapshotForVPC(region, vpcID, against)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		after = other.RouteTables
+	} else {
+		vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error loading VPC: %s", err), http.StatusInternalServerError)
+			return
+		}
+		sess, err := s.CachedCredentials.GetAWSSession(accountID, region, s.getSession(r).Username)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+			return
+		}
+		after, err = captureRouteTables(ec2.New(sess), vpc)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	buf, err := json.Marshal(diffRouteTables(snapshot.RouteTables, after))
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+// handleVPCRouteTables returns the VPC's route tables as they are in AWS
+// now, in the same form as a snapshot.
+var handleVPCRouteTables = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleVPCRouteTables but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.AccountID != accountID {
+		http.Error(w, fmt.Sprintf("VPC %s is not in account %s", vpcID, accountID), http.StatusBadRequest)
+		return
+	}
+	sess, err := s.CachedCredentials.GetAWSSession(accountID, region, s.getSession(r).Username)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+		return
+	}
+	routeTables, err := captureRouteTables(ec2.New(sess), vpc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(routeTables)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleRouteTableSnapshotCreate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleRouteTableSnapshotCreate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	s.queueRouteTablesTask(w, r, accountID, vpcID, "Snapshot route tables", &database.TaskData{
+		SnapshotRouteTablesTaskData: &database.SnapshotRouteTablesTaskData{
+			VPCID:  vpcID,
+			Region: database.Region(region),
+		},
+	})
+}
+
+var handleRouteTableSnapshotRestore = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 4 {
+		log.Printf("Expected 4 additional args to handleRouteTableSnapshotRestore but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	snapshot, err := s.getRouteTableSnapshotForVPC(region, vpcID, args[3])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.queueRouteTablesTask(w, r, accountID, vpcID, fmt.Sprintf("Restore route tables from snapshot %d", snapshot.ID), &database.TaskData{
+		RestoreRouteTablesTaskData: &database.RestoreRouteTablesTaskData{
+			VPCID:      vpcID,
+			Region:     database.Region(region),
+			SnapshotID: snapshot.ID,
+		},
+	})
+}
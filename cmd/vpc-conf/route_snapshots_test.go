@@ -0,0 +1,188 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRouteTableSnapshotRestore(t *testing.T) {
+	route := func(destination, target string) *ec2.Route {
+		r := &ec2.Route{DestinationCidrBlock: aws.String(destination), Origin: aws.String(ec2.RouteOriginCreateRoute)}
+		switch target[:3] {
+		case "tgw":
+			r.TransitGatewayId = aws.String(target)
+		case "nat":
+			r.NatGatewayId = aws.String(target)
+		case "eni":
+			r.NetworkInterfaceId = aws.String(target)
+		}
+		return r
+	}
+	local := &ec2.Route{DestinationCidrBlock: aws.String("10.0.0.0/16"), GatewayId: aws.String("local"), Origin: aws.String(ec2.RouteOriginCreateRouteTable)}
+	managedRT := &ec2.RouteTable{
+		RouteTableId: aws.String("rtb-managed"),
+		VpcId:        aws.String("vpc-abc"),
+		Routes:       []*ec2.Route{local, route("0.0.0.0/0", "nat-1"), route("10.1.0.0/16", "tgw-1"), route("192.168.0.0/16", "tgw-manual")},
+	}
+	unmanagedRT := &ec2.RouteTable{
+		RouteTableId: aws.String("rtb-unmanaged"),
+		VpcId:        aws.String("vpc-abc"),
+		Routes:       []*ec2.Route{local, route("172.16.0.0/12", "eni-appliance")},
+	}
+	ec2svc := &testmocks.MockEC2{RouteTables: []*ec2.RouteTable{managedRT, unmanagedRT}}
+	vpc := &database.VPC{
+		ID:     "vpc-abc",
+		Region: "us-east-1",
+		State: &database.VPCState{
+			RouteTables: map[string]*database.RouteTableInfo{
+				"rtb-managed": {
+					RouteTableID: "rtb-managed",
+					Routes: []*database.RouteInfo{
+						{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"},
+						{Destination: "10.1.0.0/16", TransitGatewayID: "tgw-1"},
+					},
+				},
+			},
+		},
+	}
+	mm := &testmocks.MockModelsManager{VPCs: map[string]*database.VPC{"us-east-1vpc-abc": vpc}}
+	vpcWriter := &testmocks.MockVPCWriter{MM: mm, Region: vpc.Region, VPCID: vpc.ID}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		VPCID:            vpc.ID,
+	}
+
+	snapshot, err := takeRouteTableSnapshot(ctx, mm, vpc, "test", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expectedSnapshot := map[string]*database.RouteTableSnapshotEntry{
+		"rtb-managed": {
+			Managed: true,
+			Routes: []*database.SnapshotRoute{
+				{RouteInfo: database.RouteInfo{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"}, Managed: true},
+				{RouteInfo: database.RouteInfo{Destination: "10.1.0.0/16", TransitGatewayID: "tgw-1"}, Managed: true},
+				{RouteInfo: database.RouteInfo{Destination: "192.168.0.0/16", TransitGatewayID: "tgw-manual"}},
+			},
+		},
+		"rtb-unmanaged": {
+			Routes: []*database.SnapshotRoute{
+				{RouteInfo: database.RouteInfo{Destination: "172.16.0.0/12"}, OtherTargetID: "eni-appliance"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedSnapshot, snapshot.RouteTables); diff != "" {
+		t.Fatalf("Expected snapshot did not match actual: \n%s", diff)
+	}
+
+	// Someone changes the routes by hand
+	managedRT.Routes = []*ec2.Route{local, route("0.0.0.0/0", "nat-1"), route("10.1.0.0/16", "tgw-2"), route("10.9.0.0/16", "tgw-1")}
+	unmanagedRT.Routes = []*ec2.Route{local}
+	vpc.State.RouteTables["rtb-managed"].Routes = []*database.RouteInfo{
+		{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"},
+	}
+
+	current, err := captureRouteTables(ec2svc, vpc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	diff := diffRouteTables(snapshot.RouteTables, current)
+	changes := []string{}
+	for _, change := range diff.Changes {
+		changes = append(changes, change.RouteTableID+" "+change.Destination)
+	}
+	expectedChanges := []string{
+		"rtb-managed 10.1.0.0/16",
+		"rtb-managed 192.168.0.0/16",
+		"rtb-managed 10.9.0.0/16",
+		"rtb-unmanaged 172.16.0.0/12",
+	}
+	if d := cmp.Diff(expectedChanges, changes); d != "" {
+		t.Errorf("Expected changes did not match actual: \n%s", d)
+	}
+
+	err = restoreRouteTableSnapshot(ctx, vpc, vpcWriter, snapshot)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if d := cmp.Diff(map[string][]*database.RouteInfo{"rtb-managed": {{Destination: "10.1.0.0/16", TransitGatewayID: "tgw-1"}}}, ec2svc.RoutesReplaced); d != "" {
+		t.Errorf("Expected replaced routes did not match actual: \n%s", d)
+	}
+	if d := cmp.Diff(map[string][]*database.RouteInfo{"rtb-managed": {{Destination: "192.168.0.0/16", TransitGatewayID: "tgw-manual"}}}, ec2svc.RoutesAdded); d != "" {
+		t.Errorf("Expected added routes did not match actual: \n%s", d)
+	}
+	if d := cmp.Diff(map[string][]string{"rtb-managed": {"10.9.0.0/16"}}, ec2svc.RoutesDeleted); d != "" {
+		t.Errorf("Expected deleted routes did not match actual: \n%s", d)
+	}
+	expectedState := []*database.RouteInfo{
+		{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"},
+		{Destination: "10.1.0.0/16", TransitGatewayID: "tgw-1"},
+	}
+	if d := cmp.Diff(expectedState, mm.VPCs["us-east-1vpc-abc"].State.RouteTables["rtb-managed"].Routes); d != "" {
+		t.Errorf("Expected state did not match actual: \n%s", d)
+	}
+}
+
+func TestRouteTableSnapshotPrune(t *testing.T) {
+	ec2svc := &testmocks.MockEC2{}
+	vpc := &database.VPC{ID: "vpc-abc", Region: "us-east-1"}
+	other := &database.RouteTableSnapshot{VPCID: "vpc-other", Region: "us-east-1"}
+	mm := &testmocks.MockModelsManager{}
+	mm.CreateRouteTableSnapshot(other)
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		VPCID:            vpc.ID,
+	}
+
+	var first, last *database.RouteTableSnapshot
+	for i := 0; i <= maxRouteTableSnapshotsPerVPC; i++ {
+		snapshot, err := takeRouteTableSnapshot(ctx, mm, vpc, "test", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if first == nil {
+			first = snapshot
+		}
+		last = snapshot
+	}
+	snapshots, _ := mm.GetRouteTableSnapshots(vpc.Region, vpc.ID)
+	if len(snapshots) != maxRouteTableSnapshotsPerVPC {
+		t.Errorf("Expected %d snapshots to be kept but got %d", maxRouteTableSnapshotsPerVPC, len(snapshots))
+	}
+	if snapshots[0] != last || snapshots[len(snapshots)-1] == first {
+		t.Errorf("Expected the oldest snapshot to be deleted")
+	}
+	if others, _ := mm.GetRouteTableSnapshots(other.Region, other.VPCID); len(others) != 1 {
+		t.Errorf("Expected other VPCs' snapshots to be kept")
+	}
+}
+
+func TestRouteChangingTaskVPC(t *testing.T) {
+	repair := func(spec database.VerifySpec) *database.TaskData {
+		return &database.TaskData{RepairVPCTaskData: &database.RepairVPCTaskData{VPCID: "vpc-abc", Region: "us-east-1", Spec: spec}}
+	}
+	for _, tc := range []struct {
+		name     string
+		taskData *database.TaskData
+		expected string
+	}{
+		{"Repair all", repair(database.VerifyAllSpec()), "vpc-abc"},
+		{"Repair networking", repair(database.VerifySpec{VerifyNetworking: true}), "vpc-abc"},
+		{"Repair endpoints", repair(database.VerifySpec{VerifyVPCEndpoints: true}), "vpc-abc"},
+		{"Repair logging", repair(database.VerifySpec{VerifyLogging: true}), ""},
+		{"Repair security groups and CMSNet", repair(database.VerifySpec{VerifySecurityGroups: true, VerifyCMSNet: true}), ""},
+		{"Verify", &database.TaskData{VerifyVPCTaskData: &database.VerifyVPCTaskData{VPCID: "vpc-abc", Region: "us-east-1", Spec: database.VerifyAllSpec()}}, ""},
+	} {
+		if _, vpcID := routeChangingTaskVPC(tc.taskData); vpcID != tc.expected {
+			t.Errorf("%s: expected %q but got %q", tc.name, tc.expected, vpcID)
+		}
+	}
+}
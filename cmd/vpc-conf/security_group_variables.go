@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// Security group template rules can use one of these placeholders as their
+// Source instead of a fixed CIDR, security group or prefix list ID. They are
+// expanded separately for each VPC the template is applied to:
+//
+//	${vpc.cidrs}              the VPC's IPv4 CIDRs
+//	${vpc.subnets.<type>}     the CIDRs of the VPC's subnets of the given type
+//	${peer:<vpc name>.cidrs}  the CIDRs of a VPC this one is peered with
+//	${sg:<template name>}     this VPC's security group for another template
+const (
+	sgVariableVPCCIDRs    = "vpc.cidrs"
+	sgVariableSubnets     = "vpc.subnets."
+	sgVariablePeerPrefix  = "peer:"
+	sgVariablePeerSuffix  = ".cidrs"
+	sgVariableGroupPrefix = "sg:"
+)
+
+type securityGroupVariable struct {
+	Kind string // one of the sgVariable* constants, minus any suffix
+	Arg  string // subnet type, peer VPC name or template name
+}
+
+// parseSecurityGroupVariable returns nil if source is not a placeholder and
+// an error if it looks like one but isn't valid.
+func parseSecurityGroupVariable(source string) (*securityGroupVariable, error) {
+	if !strings.HasPrefix(source, "${") {
+		return nil, nil
+	}
+	if !strings.HasSuffix(source, "}") {
+		return nil, fmt.Errorf("Unterminated placeholder %q", source)
+	}
+	name := source[2 : len(source)-1]
+	switch {
+	case name == sgVariableVPCCIDRs:
+		return &securityGroupVariable{Kind: sgVariableVPCCIDRs}, nil
+	case strings.HasPrefix(name, sgVariableSubnets):
+		subnetType := strings.TrimPrefix(name, sgVariableSubnets)
+		for _, t := range database.AllSubnetTypes() {
+			if strings.EqualFold(string(t), subnetType) {
+				return &securityGroupVariable{Kind: sgVariableSubnets, Arg: string(t)}, nil
+			}
+		}
+		return nil, fmt.Errorf("Unknown subnet type %q in placeholder %q", subnetType, source)
+	case strings.HasPrefix(name, sgVariablePeerPrefix) && strings.HasSuffix(name, sgVariablePeerSuffix):
+		peer := strings.TrimSuffix(strings.TrimPrefix(name, sgVariablePeerPrefix), sgVariablePeerSuffix)
+		if peer == "" {
+			return nil, fmt.Errorf("Missing VPC name in placeholder %q", source)
+		}
+		return &securityGroupVariable{Kind: sgVariablePeerPrefix, Arg: peer}, nil
+	case strings.HasPrefix(name, sgVariableGroupPrefix):
+		group := strings.TrimPrefix(name, sgVariableGroupPrefix)
+		if group == "" {
+			return nil, fmt.Errorf("Missing template name in placeholder %q", source)
+		}
+		return &securityGroupVariable{Kind: sgVariableGroupPrefix, Arg: group}, nil
+	}
+	return nil, fmt.Errorf("Unknown placeholder %q", source)
+}
+
+func validateSecurityGroupSetRules(set *database.SecurityGroupSet) error {
+	for _, group := range set.Groups {
+		for _, rule := range group.Rules {
+			_, err := parseSecurityGroupVariable(rule.Source)
+			if err != nil {
+				return fmt.Errorf("Security group %q rule %q: %s", group.Name, rule.Description, err)
+			}
+		}
+	}
+	return nil
+}
+
+// securityGroupVariableResolver expands placeholders for one VPC, looking
+// each one up at most once.
+type securityGroupVariableResolver struct {
+	ctx           *awsp.Context
+	modelsManager database.ModelsManager
+	vpc           *database.VPC
+	templates     map[uint64]*database.SecurityGroupTemplate // all known templates by ID
+
+	subnetCIDRs map[string]string // subnet ID -> CIDR
+	peerCIDRs   map[string][]string
+}
+
+func (r *securityGroupVariableResolver) resolve(v *securityGroupVariable) ([]string, error) {
+	switch v.Kind {
+	case sgVariableVPCCIDRs:
+		return r.vpcCIDRs(r.vpc.ID, r.vpc.Region)
+	case sgVariableSubnets:
+		if r.subnetCIDRs == nil {
+			cidrs, err := r.ctx.GetSubnetCIDRs()
+			if err != nil {
+				return nil, err
+			}
+			r.subnetCIDRs = cidrs
+		}
+		values := []string{}
+		for _, az := range r.vpc.State.AvailabilityZones.InOrder() {
+			for _, subnet := range az.Subnets[database.SubnetType(v.Arg)] {
+				cidr, ok := r.subnetCIDRs[subnet.SubnetID]
+				if !ok {
+					return nil, fmt.Errorf("No CIDR found for subnet %s", subnet.SubnetID)
+				}
+				values = append(values, cidr)
+			}
+		}
+		return values, nil
+	case sgVariablePeerPrefix:
+		if cidrs, ok := r.peerCIDRs[v.Arg]; ok {
+			return cidrs, nil
+		}
+		for _, pc := range r.vpc.State.PeeringConnections {
+			otherID, otherRegion := pc.AccepterVPCID, pc.AccepterRegion
+			if otherID == r.vpc.ID && otherRegion == r.vpc.Region {
+				otherID, otherRegion = pc.RequesterVPCID, pc.RequesterRegion
+			}
+			other, err := r.modelsManager.GetVPC(otherRegion, otherID)
+			if err != nil {
+				return nil, fmt.Errorf("Error getting peer VPC %s: %s", otherID, err)
+			}
+			if other.Name != v.Arg {
+				continue
+			}
+			cidrs, err := r.vpcCIDRs(otherID, otherRegion)
+			if err != nil {
+				return nil, err
+			}
+			if r.peerCIDRs == nil {
+				r.peerCIDRs = map[string][]string{}
+			}
+			r.peerCIDRs[v.Arg] = cidrs
+			return cidrs, nil
+		}
+		return nil, fmt.Errorf("VPC is not peered with a VPC named %q", v.Arg)
+	case sgVariableGroupPrefix:
+		for _, sg := range r.vpc.State.SecurityGroups {
+			template := r.templates[sg.TemplateID]
+			if template != nil && template.Name == v.Arg && sg.SecurityGroupID != "" {
+				return []string{sg.SecurityGroupID}, nil
+			}
+		}
+		return nil, fmt.Errorf("VPC has no security group for template %q", v.Arg)
+	}
+	return nil, fmt.Errorf("Unknown placeholder kind %q", v.Kind)
+}
+
+func (r *securityGroupVariableResolver) vpcCIDRs(vpcID string, region database.Region) ([]string, error) {
+	primary, secondary, err := r.modelsManager.GetVPCCIDRs(vpcID, region)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting CIDRs for %s: %s", vpcID, err)
+	}
+	cidrs := []string{}
+	if primary != nil && *primary != "" {
+		cidrs = append(cidrs, *primary)
+	}
+	sorted := append([]string{}, secondary...)
+	sort.Strings(sorted)
+	return append(cidrs, sorted...), nil
+}
+
+// expandRules returns rules with every placeholder Source replaced by one
+// rule per value it stands for.
+func (r *securityGroupVariableResolver) expandRules(rules []*database.SecurityGroupRule) ([]*database.SecurityGroupRule, error) {
+	expanded := []*database.SecurityGroupRule{}
+	for _, rule := range rules {
+		v, err := parseSecurityGroupVariable(rule.Source)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			expanded = append(expanded, rule)
+			continue
+		}
+		values, err := r.resolve(v)
+		if err != nil {
+			return nil, fmt.Errorf("Error expanding rule %q: %s", rule.Description, err)
+		}
+		for _, value := range values {
+			concrete := *rule
+			concrete.Source = value
+			expanded = append(expanded, &concrete)
+		}
+	}
+	return expanded, nil
+}
+
+// sameSecurityGroupRules reports whether a and b hold the same rules in any
+// order, ignoring descriptions.
+func sameSecurityGroupRules(a, b []*database.SecurityGroupRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	remaining := append([]*database.SecurityGroupRule{}, b...)
+	for _, rule := range a {
+		found := false
+		for idx, other := range remaining {
+			if rule.IsEgress == other.IsEgress &&
+				rule.Protocol == other.Protocol &&
+				rule.Source == other.Source &&
+				rule.SourceIPV6CIDR == other.SourceIPV6CIDR &&
+				rule.FromPort == other.FromPort &&
+				rule.ToPort == other.ToPort {
+				found = true
+				remaining = append(remaining[:idx], remaining[idx+1:]...)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
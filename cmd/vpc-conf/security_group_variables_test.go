@@ -0,0 +1,126 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandSecurityGroupRules(t *testing.T) {
+	rule := func(source string) *database.SecurityGroupRule {
+		return &database.SecurityGroupRule{
+			Description: "https",
+			Protocol:    "tcp",
+			FromPort:    443,
+			ToPort:      443,
+			Source:      source,
+		}
+	}
+	vpc := &database.VPC{
+		ID:     "vpc-abc",
+		Name:   "chris-east-dev",
+		Region: "us-east-1",
+		State: &database.VPCState{
+			AvailabilityZones: database.AZMap{
+				"us-east-1b": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-b"}},
+					},
+				},
+				"us-east-1a": {
+					Subnets: map[database.SubnetType][]*database.SubnetInfo{
+						database.SubnetTypePrivate: {{SubnetID: "subnet-private-a"}},
+						database.SubnetTypePublic:  {{SubnetID: "subnet-public-a"}},
+					},
+				},
+			},
+			PeeringConnections: []*database.PeeringConnection{
+				{
+					RequesterVPCID:  "vpc-other",
+					RequesterRegion: "us-west-2",
+					AccepterVPCID:   "vpc-abc",
+					AccepterRegion:  "us-east-1",
+				},
+			},
+			SecurityGroups: []*database.SecurityGroup{
+				{TemplateID: 5, SecurityGroupID: "sg-web"},
+			},
+		},
+	}
+	mm := &testmocks.MockModelsManager{
+		VPCs: map[string]*database.VPC{
+			"us-west-2vpc-other": {ID: "vpc-other", Name: "shared-west-prod", Region: "us-west-2"},
+		},
+		VPCsPrimaryCIDR: map[string]*string{
+			"us-east-1vpc-abc":   aws.String("10.0.0.0/24"),
+			"us-west-2vpc-other": aws.String("10.9.0.0/24"),
+		},
+		VPCsSecondaryCIDRs: map[string][]string{
+			"us-east-1vpc-abc": {"10.1.0.0/24", "10.0.5.0/24"},
+		},
+	}
+	ec2 := &testmocks.MockEC2{
+		SubnetCIDRs: map[string]string{
+			"subnet-private-a": "10.0.0.0/26",
+			"subnet-private-b": "10.0.0.64/26",
+			"subnet-public-a":  "10.0.0.128/26",
+		},
+	}
+	resolver := &securityGroupVariableResolver{
+		ctx: &awsp.Context{
+			AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2},
+			VPCID:            "vpc-abc",
+		},
+		modelsManager: mm,
+		vpc:           vpc,
+		templates: map[uint64]*database.SecurityGroupTemplate{
+			5: {ID: 5, Name: "web"},
+		},
+	}
+
+	testCases := []struct {
+		Name        string
+		Source      string
+		Expected    []string
+		ExpectError bool
+	}{
+		{Name: "Fixed CIDR", Source: "10.2.0.0/16", Expected: []string{"10.2.0.0/16"}},
+		{Name: "VPC CIDRs", Source: "${vpc.cidrs}", Expected: []string{"10.0.0.0/24", "10.0.5.0/24", "10.1.0.0/24"}},
+		{Name: "Private subnets", Source: "${vpc.subnets.private}", Expected: []string{"10.0.0.0/26", "10.0.0.64/26"}},
+		{Name: "No subnets of type", Source: "${vpc.subnets.data}", Expected: []string{}},
+		{Name: "Peer CIDRs", Source: "${peer:shared-west-prod.cidrs}", Expected: []string{"10.9.0.0/24"}},
+		{Name: "Security group", Source: "${sg:web}", Expected: []string{"sg-web"}},
+		{Name: "Not peered", Source: "${peer:nobody.cidrs}", ExpectError: true},
+		{Name: "Unknown template", Source: "${sg:db}", ExpectError: true},
+		{Name: "Unknown subnet type", Source: "${vpc.subnets.bogus}", ExpectError: true},
+		{Name: "Unknown placeholder", Source: "${vpc.name}", ExpectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rules, err := resolver.expandRules([]*database.SecurityGroupRule{rule(tc.Source)})
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("Expected an error but got rules %v", rules)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			sources := []string{}
+			for _, r := range rules {
+				if r.Description != "https" || r.FromPort != 443 {
+					t.Errorf("Expanded rule lost template fields: %+v", r)
+				}
+				sources = append(sources, r.Source)
+			}
+			if diff := cmp.Diff(tc.Expected, sources); diff != "" {
+				t.Fatalf("Expected sources did not match actual: \n%s", diff)
+			}
+		})
+	}
+}
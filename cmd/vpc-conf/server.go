@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -26,11 +27,11 @@ import (
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/credentialservice"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ipcontrol"
-	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/jira"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/lib"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/orchestration"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/search"
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/session"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
 	"github.com/lib/pq"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -70,12 +71,14 @@ type Server struct {
 	PathPrefix        string
 	*database.TaskDatabase
 	session.SessionStore
-	JIRAClient           *jira.Client
+	Ticketing            *ticketing.Router
 	JIRAIssueLabels      IssueLabels
 	ModelsManager        database.ModelsManager
 	TaskParallelism      int
 	LimitToAWSAccountIDs []string              // nil means "all accounts allowed"
 	Orchestration        *orchestration.Client // optional
+	EgressIPNotifier     EgressIPNotifier      // optional
+	AutoApproveAsUser    string                // runs auto-approved VPC request tasks; "" disables auto-approval
 
 	ReparseTemplates bool
 
@@ -160,7 +163,8 @@ func (s *Server) createJIRAIssue(requestID uint64) error {
 		return fmt.Errorf("Error preparing JIRA issue description: %s", err)
 	}
 
-	issueID, err := s.JIRAClient.CreateIssue(&jira.IssueDetails{
+	provider := s.Ticketing.For(req.AccountID, req.ProjectName)
+	issueID, err := provider.CreateIssue(&ticketing.IssueDetails{
 		Summary:     summary,
 		Description: buf.String(),
 		Reporter:    req.RequesterUID,
@@ -172,7 +176,7 @@ func (s *Server) createJIRAIssue(requestID uint64) error {
 		if err != nil {
 			log.Printf("Error inserting request log: %s", err)
 		}
-		return fmt.Errorf("Error creating JIRA issue: %s", err)
+		return fmt.Errorf("Error creating %s issue: %s", provider.Name(), err)
 	}
 
 	err = tx.SetVPCRequestJIRAIssue(issueID)
@@ -187,40 +191,256 @@ func (s *Server) createJIRAIssue(requestID uint64) error {
 	return nil
 }
 
+// SyncVPCRequestStatuses keeps VPC requests and their issues in sync:
+// status, assignee, rejection reason and comments come from the ticketing
+// system, while local comments and task results are posted back to it.
 func (s *Server) SyncVPCRequestStatuses() {
-	sync := func() {
-		reqs, err := s.ModelsManager.GetAllVPCRequests()
+	issuesUpdated := map[string]time.Time{}
+	go func() {
+		s.syncVPCRequests(issuesUpdated)
+		for range time.Tick(time.Minute) {
+			s.syncVPCRequests(issuesUpdated)
+		}
+	}()
+}
+
+// vpcRequestSettled reports whether the request's issue has nothing left to
+// tell vpc-conf or be told: the request was cancelled or rejected, or its
+// task finished and the result was posted.
+func vpcRequestSettled(req *database.VPCRequest) bool {
+	if req.Status == database.StatusCancelledByRequester || req.Status == database.StatusRejected {
+		return true
+	}
+	if req.TaskID == nil || req.TaskStatus == nil || req.TaskStatusNotified == nil {
+		return false
+	}
+	status := database.TaskStatus(*req.TaskStatus)
+	return (status == database.TaskStatusSuccessful || status == database.TaskStatusFailed) && *req.TaskStatusNotified == status
+}
+
+// syncVPCRequests does one sync pass. Only unsettled requests' issues are
+// fetched, and their comments are only compared with the stored ones when
+// the issue was updated since the last pass. issuesUpdated holds the last
+// update seen for each issue.
+func (s *Server) syncVPCRequests(issuesUpdated map[string]time.Time) {
+	reqs, err := s.ModelsManager.GetAllVPCRequests()
+	if err != nil {
+		log.Printf("Error getting VPC requests: %s", err)
+		return
+	}
+	reqsByIssue := map[string]*database.VPCRequest{}
+	reqsByID := map[uint64]*database.VPCRequest{}
+	issueKeys := map[ticketing.Provider][]string{}
+	for _, req := range reqs {
+		if req.JIRAIssue == nil {
+			err := s.createJIRAIssue(req.ID)
+			if err != nil {
+				log.Printf("Error creating issue: %s", err)
+			}
+			continue
+		}
+		// skip issues no provider owns, e.g. the old JIRA project
+		issueKey := *req.JIRAIssue
+		provider := s.Ticketing.ForIssue(issueKey)
+		if provider == nil {
+			continue
+		}
+		// local comments can be made on any request
+		reqsByID[req.ID] = req
+		if vpcRequestSettled(req) {
+			delete(issuesUpdated, issueKey)
+			continue
+		}
+		reqsByIssue[issueKey] = req
+		issueKeys[provider] = append(issueKeys[provider], issueKey)
+	}
+
+	for _, provider := range s.Ticketing.Providers() {
+		if len(issueKeys[provider]) == 0 {
+			continue
+		}
+		issues, err := provider.SearchIssues(issueKeys[provider])
 		if err != nil {
-			log.Printf("Error getting VPC requests: %s", err)
-			return
+			log.Printf("Error searching %s issues: %s", provider.Name(), err)
 		}
-		for _, req := range reqs {
-			if req.JIRAIssue == nil {
-				err := s.createJIRAIssue(req.ID)
-				if err != nil {
-					log.Printf("Error creating JIRA issue: %s", err)
-				}
-			} else if req.TaskID == nil {
-				// skip old JIRA project and cancelled/denied tasks
-				jiraIssue := *req.JIRAIssue
-				if strings.HasPrefix(jiraIssue, s.JIRAClient.Config.Project+"-") && req.Status != database.StatusCancelledByRequester && req.Status != database.StatusRejected {
-					// Check for approval
-					status, err := s.JIRAClient.GetIssueStatus(jiraIssue)
-					if err != nil {
-						log.Printf("Error getting status for %q: %s", jiraIssue, err)
-					} else if status != req.Status {
-						s.ModelsManager.SetVPCRequestStatus(req.ID, status)
-					}
-				}
+		for _, issue := range issues {
+			req := reqsByIssue[issue.Key]
+			if req == nil {
+				continue
+			}
+			lastUpdated, seen := issuesUpdated[issue.Key]
+			syncComments := !seen || issue.Updated.IsZero() || !issue.Updated.Equal(lastUpdated)
+			err := s.syncVPCRequestFromIssue(provider, req, issue, syncComments)
+			if err != nil {
+				log.Printf("Error syncing request %d from %s: %s", req.ID, issue.Key, err)
+				continue
 			}
+			issuesUpdated[issue.Key] = issue.Updated
 		}
 	}
-	go func() {
-		sync()
-		for range time.Tick(time.Minute) {
-			sync()
+
+	s.postUnsyncedVPCRequestComments(reqsByID)
+
+	for issueKey, req := range reqsByIssue {
+		err := s.notifyIssueOfTaskResult(issueKey, req)
+		if err != nil {
+			log.Printf("Error posting task result to %s: %s", issueKey, err)
 		}
-	}()
+	}
+}
+
+func (s *Server) syncVPCRequestFromIssue(provider ticketing.Provider, req *database.VPCRequest, issue *ticketing.Issue, syncComments bool) error {
+	// Once a task is created or the request is cancelled/denied the status is owned by vpc-conf
+	if req.TaskID == nil && req.Status != database.StatusCancelledByRequester && req.Status != database.StatusRejected {
+		status := issue.Status
+		if status == database.StatusUnknown {
+			log.Printf("Unknown %s status for %q", provider.Name(), issue.Key)
+		} else if status != req.Status {
+			err := s.ModelsManager.SetVPCRequestStatus(req.ID, status)
+			if err != nil {
+				return fmt.Errorf("Error setting status: %s", err)
+			}
+			req.Status = status
+		}
+	}
+
+	rejectionReason := ""
+	if req.Status == database.StatusRejected {
+		rejectionReason = issue.Resolution
+	}
+	if issue.Assignee != req.Assignee || rejectionReason != req.RejectionReason {
+		err := s.ModelsManager.SetVPCRequestJIRAFields(req.ID, issue.Assignee, rejectionReason)
+		if err != nil {
+			return fmt.Errorf("Error setting assignee and rejection reason: %s", err)
+		}
+	}
+
+	if !syncComments || len(issue.Comments) == 0 {
+		return nil
+	}
+	existing, err := s.ModelsManager.GetVPCRequestComments(req.ID)
+	if err != nil {
+		return fmt.Errorf("Error getting comments: %s", err)
+	}
+	known := map[string]bool{}
+	for _, comment := range existing {
+		if comment.JIRACommentID != nil {
+			known[*comment.JIRACommentID] = true
+		}
+	}
+	for _, comment := range issue.Comments {
+		// Comments posted by vpc-conf itself are already here or are task notifications
+		if known[comment.ID] || comment.Author == provider.ServiceAccount() {
+			continue
+		}
+		jiraCommentID := comment.ID
+		_, err := s.ModelsManager.InsertVPCRequestComment(&database.VPCRequestComment{
+			VPCRequestID:  req.ID,
+			Author:        comment.Author,
+			Body:          comment.Body,
+			FromJIRA:      true,
+			JIRACommentID: &jiraCommentID,
+		})
+		if err != nil {
+			return fmt.Errorf("Error inserting comment %s: %s", comment.ID, err)
+		}
+	}
+	return nil
+}
+
+// postUnsyncedVPCRequestComments posts comments made in vpc-conf to the
+// request's issue. Comments are only ever posted from here so that each is
+// posted once.
+func (s *Server) postUnsyncedVPCRequestComments(reqsByID map[uint64]*database.VPCRequest) {
+	comments, err := s.ModelsManager.GetUnsyncedVPCRequestComments()
+	if err != nil {
+		log.Printf("Error getting unsynced comments: %s", err)
+		return
+	}
+	for _, comment := range comments {
+		req := reqsByID[comment.VPCRequestID]
+		if req == nil {
+			// no issue yet
+			continue
+		}
+		err := s.postVPCRequestComment(*req.JIRAIssue, comment)
+		if err != nil {
+			log.Printf("Error posting comment %d to %s: %s", comment.ID, *req.JIRAIssue, err)
+		}
+	}
+}
+
+func (s *Server) postVPCRequestComment(issueKey string, comment *database.VPCRequestComment) error {
+	provider := s.Ticketing.ForIssue(issueKey)
+	if provider == nil {
+		return fmt.Errorf("No ticketing provider for %s", issueKey)
+	}
+	body := fmt.Sprintf("%s commented in vpc-conf:\n\n%s", comment.Author, comment.Body)
+	commentID, err := provider.AddComment(issueKey, body)
+	if err != nil {
+		return err
+	}
+	// Even an empty ID marks the comment as posted
+	return s.ModelsManager.SetVPCRequestCommentJIRAID(comment.ID, commentID)
+}
+
+// notifyIssueOfTaskResult posts a comment once the request's task finishes, with
+// the provisioned VPC and its CIDRs on success.
+func (s *Server) notifyIssueOfTaskResult(issueKey string, req *database.VPCRequest) error {
+	if req.TaskID == nil || req.TaskStatus == nil {
+		return nil
+	}
+	status := database.TaskStatus(*req.TaskStatus)
+	if status != database.TaskStatusSuccessful && status != database.TaskStatusFailed {
+		return nil
+	}
+	if req.TaskStatusNotified != nil && *req.TaskStatusNotified == status {
+		return nil
+	}
+
+	var comment string
+	if status == database.TaskStatusFailed {
+		comment = fmt.Sprintf("Provisioning task %d failed. A network engineer will look into the failure and follow up on this issue.", *req.TaskID)
+	} else {
+		var vpcID string
+		var region database.Region
+		if req.ProvisionedVPC != nil {
+			vpcID, region = req.ProvisionedVPC.ID, req.ProvisionedVPC.Region
+		} else if req.ApprovedConfig != nil {
+			vpcID, region = req.ApprovedConfig.VPCID, database.Region(req.ApprovedConfig.AWSRegion)
+		}
+		if vpcID == "" {
+			comment = fmt.Sprintf("Provisioning task %d completed successfully.", *req.TaskID)
+		} else {
+			primary, secondaries, err := s.ModelsManager.GetVPCCIDRs(vpcID, region)
+			if err != nil {
+				return fmt.Errorf("Error getting CIDRs for %s: %s", vpcID, err)
+			}
+			lines := []string{
+				fmt.Sprintf("Provisioning task %d completed successfully.", *req.TaskID),
+				"",
+				fmt.Sprintf("VPC ID: %s", vpcID),
+				fmt.Sprintf("Region: %s", region),
+			}
+			if primary != nil && *primary != "" {
+				lines = append(lines, fmt.Sprintf("Primary CIDR: %s", *primary))
+			}
+			if len(secondaries) > 0 {
+				lines = append(lines, fmt.Sprintf("Secondary CIDRs: %s", strings.Join(secondaries, ", ")))
+			}
+			comment = strings.Join(lines, "\n")
+		}
+	}
+
+	provider := s.Ticketing.ForIssue(issueKey)
+	if provider == nil {
+		return fmt.Errorf("No ticketing provider for %s", issueKey)
+	}
+	err := provider.PostComment(issueKey, comment)
+	if err != nil {
+		return err
+	}
+	return s.ModelsManager.SetVPCRequestTaskStatusNotified(req.ID, status)
 }
 
 func (s *Server) suggestCheckForTasks() {
@@ -230,9 +450,8 @@ func (s *Server) suggestCheckForTasks() {
 	}
 }
 
-func (s *Server) listenForNewTasks(postgresConnectionString string) {
-	s.checkForTasks = make(chan struct{}, 1)
-	listener := pq.NewListener(postgresConnectionString, time.Second, 30*time.Second, func(t pq.ListenerEventType, err error) {
+func newPostgresListener(postgresConnectionString string) *pq.Listener {
+	return pq.NewListener(postgresConnectionString, time.Second, 30*time.Second, func(t pq.ListenerEventType, err error) {
 		eventType := map[pq.ListenerEventType]string{
 			pq.ListenerEventConnected:               "connected",
 			pq.ListenerEventDisconnected:            "disconnected",
@@ -244,6 +463,11 @@ func (s *Server) listenForNewTasks(postgresConnectionString string) {
 		}
 		log.Printf("Postgres listener: got event type %q with err %v", eventType, err)
 	})
+}
+
+func (s *Server) listenForNewTasks(postgresConnectionString string) {
+	s.checkForTasks = make(chan struct{}, 1)
+	listener := newPostgresListener(postgresConnectionString)
 	go func() {
 		channelName := "new_task"
 		err := listener.Listen(channelName)
@@ -393,7 +617,11 @@ type VPCConfig struct {
 	ManagedTransitGatewayAttachmentIDs []uint64
 	ManagedResolverRuleSetIDs          []uint64
 	SecurityGroupSetIDs                []uint64
+	VPCEndpointSetIDs                  []uint64
 	PeeringConnections                 []*database.PeeringConnectionConfig
+	ExternalPeeringConnections         []*database.ExternalPeeringConnectionConfig
+	VPNConnectionIDs                   []uint64
+	FirewallPolicyTemplateID           uint64
 }
 type VPCInfo struct {
 	AccountID   string
@@ -421,7 +649,13 @@ type VPCInfo struct {
 	SubnetGroups       []SubnetGroupInfo
 	CMSNetSupported    bool
 	CMSNetError        string
+	VPNConnections     []*vpnConnectionStatus
+	VPNError           string
 	CustomPublicRoutes string
+
+	ExternalPeeringConnections []*database.ExternalPeeringConnection // as of the last networking update
+
+	ExceptionTransitGateway *database.ExceptionTransitGatewayStatus `json:",omitempty"` // Exception VPCs only
 }
 type SubnetGroupInfo struct {
 	Name       string
@@ -494,6 +728,12 @@ var routes = []*route{
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^routeTableCapacity.json$`),
+		handler:      &handleRouteTableCapacityReport,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^ipusage/refresh$`),
 		handler:      &handleRefreshIPUsage,
@@ -594,6 +834,24 @@ var routes = []*route{
 		method:       http.MethodPost,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/securityGroupUsage$`),
+		handler:      &handleVPCSecurityGroupUsage,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/vpcEndpoints$`),
+		handler:      &handleVPCEndpoints,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/firewallPolicy$`),
+		handler:      &handleVPCFirewallPolicy,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/resolverRules$`),
 		handler:      &handleVPCResolverRules,
@@ -606,6 +864,18 @@ var routes = []*route{
 		method:       http.MethodPost,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/compliance.json$`),
+		handler:      &handleVPCCompliance,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/migrateToV1$`),
+		handler:      &handleVPCMigrateToV1,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/import$`),
 		handler:      &handleVPCImport,
@@ -618,6 +888,12 @@ var routes = []*route{
 		method:       http.MethodPost,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/exceptionTransitGateway$`),
+		handler:      &handleVPCUpdateExceptionTransitGateway,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/unimport$`),
 		handler:      &handleVPCUnimport,
@@ -654,6 +930,12 @@ var routes = []*route{
 		method:       http.MethodPost,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/expandCIDR$`),
+		handler:      &handleExpandCIDR,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/connectCMSNet$`),
 		handler:      &handleConnectCMSNet,
@@ -726,6 +1008,60 @@ var routes = []*route{
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/routeSnapshots$`),
+		handler:      &handleRouteTableSnapshotCreate,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/routeSnapshots/([0-9]+)/restore$`),
+		handler:      &handleRouteTableSnapshotRestore,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/routeSnapshots.json$`),
+		handler:      &handleRouteTableSnapshotList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/routeSnapshots/([0-9]+).json$`),
+		handler:      &handleRouteTableSnapshotDetails,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/routeSnapshots/([0-9]+)/diff.json$`),
+		handler:      &handleRouteTableSnapshotDiff,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/routeTables.json$`),
+		handler:      &handleVPCRouteTables,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/pathAnalysis.json$`),
+		handler:      &handlePathAnalysis,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/connectivityTests$`),
+		handler:      &handleTestConnectivity,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/connectivityTests.json$`),
+		handler:      &handleConnectivityTestResults,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^([^/]+)/vpc/([^/]+)/([^/]+)/tgas.json$`),
 		handler:      &handleVPCTransitGatewayAttachments,
@@ -769,6 +1105,11 @@ var routes = []*route{
 		handler: &handleIndex,
 		method:  http.MethodGet,
 	},
+	{
+		regexp:  regexp.MustCompile(`^cmsnet$`),
+		handler: &handleIndex,
+		method:  http.MethodGet,
+	},
 	{
 		regexp:       regexp.MustCompile(`^batch$`),
 		handler:      &handleBatchTask,
@@ -799,6 +1140,30 @@ var routes = []*route{
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
+	{
+		regexp:       regexp.MustCompile(`^batch/selectors.json$`),
+		handler:      &handleVPCSelectorList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^batch/selectors/$`),
+		handler:      &handleSaveVPCSelector,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^batch/selectors/([^/]+)$`),
+		handler:      &handleDeleteVPCSelector,
+		method:       http.MethodDelete,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^batch/select.json$`),
+		handler:      &handleSelectVPCs,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
 	{
 		regexp:       regexp.MustCompile(`^labels.json$`),
 		handler:      &handleGetLabels,
@@ -899,89 +1264,263 @@ var routes = []*route{
 		method:  http.MethodDelete,
 	},
 	{
-		regexp:       regexp.MustCompile(`^sgs.json$`),
-		handler:      &handleSecurityGroupSetList,
-		method:       http.MethodGet,
+		regexp:       regexp.MustCompile(`^sgs/templates/([0-9]+)/usage$`),
+		handler:      &handleAnalyzeSecurityGroupTemplateUsage,
+		method:       http.MethodPost,
 		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^([^/]+)/pl.json$`),
-		handler:      &handleListManagedPrefixLists,
+		regexp:       regexp.MustCompile(`^sgs/templates/([0-9]+)/usage.json$`),
+		handler:      &handleSecurityGroupTemplateUsage,
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^([^/]+)/pl/([^/]+).json$`),
-		handler:      &handleManagedPrefixListDetails,
+		regexp:       regexp.MustCompile(`^sgs.json$`),
+		handler:      &handleSecurityGroupSetList,
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
 	{
-		regexp:  regexp.MustCompile(`^accounts/([^/]+)/vpc/([^/]+)/([^/]+)$`),
-		handler: &handleIndex,
-		method:  http.MethodGet,
+		regexp:       regexp.MustCompile(`^vpces/$`),
+		handler:      &handleCreateVPCEndpointSet,
+		method:       http.MethodPost,
+		requiresAuth: true,
 	},
 	{
-		regexp:  regexp.MustCompile(`^$`),
-		handler: &handleIndex,
-		method:  http.MethodGet,
+		regexp:       regexp.MustCompile(`^vpces/([0-9]+)$`),
+		handler:      &handleUpdateVPCEndpointSet,
+		method:       http.MethodPatch,
+		requiresAuth: true,
 	},
 	{
-		regexp:  regexp.MustCompile(`^vpcreqs/?([0-9]+)?$`),
-		handler: &handleIndex,
-		method:  http.MethodGet,
+		regexp:       regexp.MustCompile(`^vpces/([0-9]+)$`),
+		handler:      &handleDeleteVPCEndpointSet,
+		method:       http.MethodDelete,
+		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^vpcreq/([0-9]+)/provision$`),
-		handler:      &handleProvisionRequest,
+		regexp:       regexp.MustCompile(`^vpces.json$`),
+		handler:      &handleVPCEndpointSetList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^fwpolicies/$`),
+		handler:      &handleCreateFirewallPolicyTemplate,
 		method:       http.MethodPost,
 		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^vpcreqs\.json$`),
-		handler:      &handleVPCRequestList,
-		method:       http.MethodGet,
+		regexp:       regexp.MustCompile(`^fwpolicies/([0-9]+)$`),
+		handler:      &handleUpdateFirewallPolicyTemplate,
+		method:       http.MethodPatch,
 		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^vpcreqs/([0-9]+)\.json$`),
-		handler:      &handleGetVPCRequest,
+		regexp:       regexp.MustCompile(`^fwpolicies/([0-9]+)$`),
+		handler:      &handleDeleteFirewallPolicyTemplate,
+		method:       http.MethodDelete,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^fwpolicies.json$`),
+		handler:      &handleFirewallPolicyTemplateList,
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^vpcreqs/([0-9]+)/jiraErrors`), // [0-9] is the VPCRequest.ID
-		handler:      &handleVPCRequestJIRAIssueErrorList,
+		regexp:       regexp.MustCompile(`^cmsnet/health.json$`),
+		handler:      &handleCMSNetHealth,
 		method:       http.MethodGet,
 		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^allowWorkers$`),
-		handler:      &handleAllowWorkers,
+		regexp:       regexp.MustCompile(`^vpns/$`),
+		handler:      &handleCreateVPNConnection,
 		method:       http.MethodPost,
 		requiresAuth: true,
 	},
 	{
-		regexp:  regexp.MustCompile(`^logout$`),
-		handler: &handleLogout,
-		method:  http.MethodGet,
+		regexp:       regexp.MustCompile(`^vpns/([0-9]+)$`),
+		handler:      &handleUpdateVPNConnection,
+		method:       http.MethodPatch,
+		requiresAuth: true,
 	},
 	{
-		regexp:  regexp.MustCompile(`^search$`),
-		handler: &handleIndex,
-		method:  http.MethodGet,
+		regexp:       regexp.MustCompile(`^vpns/([0-9]+)$`),
+		handler:      &handleDeleteVPNConnection,
+		method:       http.MethodDelete,
+		requiresAuth: true,
 	},
 	{
-		regexp:       regexp.MustCompile(`^search/do$`),
-		handler:      &handleSearch,
-		method:       http.MethodPost,
+		regexp:       regexp.MustCompile(`^vpns.json$`),
+		handler:      &handleVPNConnectionList,
+		method:       http.MethodGet,
 		requiresAuth: true,
 	},
 	{
-		regexp:  regexp.MustCompile(`^oauth/callback$`),
-		handler: &handleOauthCallback,
-		method:  http.MethodGet,
-	},
+		regexp:       regexp.MustCompile(`^egressIPs.(json|csv)$`),
+		handler:      &handleEgressIPList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^egressIPEvents.json$`),
+		handler:      &handleEgressIPEventList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^sharedServiceEndpoints/$`),
+		handler:      &handleCreateSharedServiceEndpoint,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^sharedServiceEndpoints/([0-9]+)$`),
+		handler:      &handleDeleteSharedServiceEndpoint,
+		method:       http.MethodDelete,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^sharedServiceEndpoints.json$`),
+		handler:      &handleSharedServiceEndpointList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqtemplates/$`),
+		handler:      &handleCreateVPCRequestTemplate,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqtemplates/([0-9]+)$`),
+		handler:      &handleUpdateVPCRequestTemplate,
+		method:       http.MethodPatch,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqtemplates/([0-9]+)$`),
+		handler:      &handleDeleteVPCRequestTemplate,
+		method:       http.MethodDelete,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqtemplates\.json$`),
+		handler:      &handleVPCRequestTemplateList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^quotas/([^/]+)$`),
+		handler:      &handleSetProjectQuota,
+		method:       http.MethodPut,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^quotas/([^/]+)$`),
+		handler:      &handleDeleteProjectQuota,
+		method:       http.MethodDelete,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^quotas\.json$`),
+		handler:      &handleProjectQuotaList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/pl.json$`),
+		handler:      &handleListManagedPrefixLists,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^([^/]+)/pl/([^/]+).json$`),
+		handler:      &handleManagedPrefixListDetails,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:  regexp.MustCompile(`^accounts/([^/]+)/vpc/([^/]+)/([^/]+)$`),
+		handler: &handleIndex,
+		method:  http.MethodGet,
+	},
+	{
+		regexp:  regexp.MustCompile(`^$`),
+		handler: &handleIndex,
+		method:  http.MethodGet,
+	},
+	{
+		regexp:  regexp.MustCompile(`^vpcreqs/?([0-9]+)?$`),
+		handler: &handleIndex,
+		method:  http.MethodGet,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreq/([0-9]+)/provision$`),
+		handler:      &handleProvisionRequest,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqs\.json$`),
+		handler:      &handleVPCRequestList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqs/([0-9]+)\.json$`),
+		handler:      &handleGetVPCRequest,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqs/([0-9]+)/comments\.json$`),
+		handler:      &handleVPCRequestCommentList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqs/([0-9]+)/comments$`),
+		handler:      &handleAddVPCRequestComment,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^vpcreqs/([0-9]+)/jiraErrors`), // [0-9] is the VPCRequest.ID
+		handler:      &handleVPCRequestJIRAIssueErrorList,
+		method:       http.MethodGet,
+		requiresAuth: true,
+	},
+	{
+		regexp:       regexp.MustCompile(`^allowWorkers$`),
+		handler:      &handleAllowWorkers,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:  regexp.MustCompile(`^logout$`),
+		handler: &handleLogout,
+		method:  http.MethodGet,
+	},
+	{
+		regexp:  regexp.MustCompile(`^search$`),
+		handler: &handleIndex,
+		method:  http.MethodGet,
+	},
+	{
+		regexp:       regexp.MustCompile(`^search/do$`),
+		handler:      &handleSearch,
+		method:       http.MethodPost,
+		requiresAuth: true,
+	},
+	{
+		regexp:  regexp.MustCompile(`^oauth/callback$`),
+		handler: &handleOauthCallback,
+		method:  http.MethodGet,
+	},
 	{
 		regexp:  regexp.MustCompile(`^oauth/validate$`),
 		handler: &handleOauthValidate,
@@ -1590,6 +2129,7 @@ var handleAccountDetails = func(s *Server, w http.ResponseWriter, r *http.Reques
 						if vpcModel.State.VPCType == database.VPCTypeException {
 							vpcInfo.IsException = true
 							vpcInfo.IsAutomated = false
+							vpcInfo.ExceptionTransitGateway = vpcModel.State.ExceptionTransitGateway
 						} else {
 							vpcInfo.IsAutomated = true
 						}
@@ -1698,6 +2238,11 @@ type BatchTaskRequest struct {
 	VPCs       []struct {
 		Name, ID, AccountID, Region string
 	}
+	// Instead of VPCs, a selector expression or the name of a saved selector
+	// can be given. It is resolved to VPCs when the batch task is submitted.
+	Selector     string
+	SelectorName string
+
 	AddSecurityGroupSets    []uint64
 	RemoveSecurityGroupSets []uint64
 
@@ -1706,6 +2251,9 @@ type BatchTaskRequest struct {
 
 	AddResolverRuleSets    []uint64
 	RemoveResolverRuleSets []uint64
+
+	AddVPCEndpointSets    []uint64
+	RemoveVPCEndpointSets []uint64
 }
 
 var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
@@ -1776,11 +2324,30 @@ var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		batchDescription = strings.ToUpper(batchDescription[:1]) + batchDescription[1:]
 	}
 
-	batchTaskID, err := s.TaskDatabase.AddBatchTask(batchDescription)
-	if err != nil {
-		log.Printf("Error adding batch task to database: %s", err)
-		http.Error(w, "Internal error", http.StatusInternalServerError)
-		return
+	var batchSelector *database.BatchTaskSelector
+	if req.Selector != "" || req.SelectorName != "" {
+		if len(req.VPCs) > 0 {
+			http.Error(w, "VPCs cannot be given along with a selector", http.StatusBadRequest)
+			return
+		}
+		batchSelector, err = resolveVPCSelector(s.ModelsManager, req.SelectorName, req.Selector)
+		if _, ok := err.(*invalidSelectorError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			log.Printf("Error resolving VPC selector: %s", err)
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		if len(batchSelector.VPCs) == 0 {
+			http.Error(w, "Selector matched no VPCs", http.StatusBadRequest)
+			return
+		}
+		for _, vpc := range batchSelector.VPCs {
+			req.VPCs = append(req.VPCs, struct {
+				Name, ID, AccountID, Region string
+			}{vpc.Name, vpc.ID, vpc.AccountID, string(vpc.Region)})
+		}
 	}
 
 	vpcs := []*database.VPC{}
@@ -1838,6 +2405,24 @@ var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		}
 	}
 
+	validVPCESets := []uint64{}
+	vpceSets, err := s.ModelsManager.GetVPCEndpointSets()
+	if err != nil {
+		log.Printf("Error fetching valid VPC endpoint set ids")
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	for _, set := range vpceSets {
+		validVPCESets = append(validVPCESets, set.ID)
+	}
+	for _, id := range append(req.AddVPCEndpointSets, req.RemoveVPCEndpointSets...) {
+		if !uint64InSlice(id, validVPCESets) {
+			log.Printf("Invalid VPC endpoint set ID supplied: %d", id)
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+	}
+
 	validMRRs := []uint64{}
 	mrrRegions := map[uint64]database.Region{}
 	mrrs, err := s.ModelsManager.GetManagedResolverRuleSets()
@@ -1860,26 +2445,33 @@ var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		}
 	}
 
+	sess := s.getSession(r)
+
+	batchMTGAs := func(vpc *database.VPC) ([]uint64, bool) {
+		newMTGAs := []uint64{}
+		updated := false
+		for _, mtgaID := range vpc.Config.ManagedTransitGatewayAttachmentIDs {
+			if uint64InSlice(mtgaID, req.RemoveManagedTransitGatewayAttachments) {
+				updated = true
+			} else {
+				newMTGAs = append(newMTGAs, mtgaID)
+			}
+		}
+		for _, mtgaID := range req.AddManagedTransitGatewayAttachments {
+			if !uint64InSlice(mtgaID, newMTGAs) {
+				updated = true
+				newMTGAs = append(newMTGAs, mtgaID)
+			}
+		}
+		return newMTGAs, updated
+	}
+
 	if len(req.AddManagedTransitGatewayAttachments) > 0 || len(req.RemoveManagedTransitGatewayAttachments) > 0 {
 		for _, vpc := range vpcs {
 			if !vpc.State.VPCType.CanUpdateMTGAs() {
 				continue
 			}
-			newMTGAs := []uint64{}
-			updated := false
-			for _, mtgaID := range vpc.Config.ManagedTransitGatewayAttachmentIDs {
-				if uint64InSlice(mtgaID, req.RemoveManagedTransitGatewayAttachments) {
-					updated = true
-				} else {
-					newMTGAs = append(newMTGAs, mtgaID)
-				}
-			}
-			for _, mtgaID := range req.AddManagedTransitGatewayAttachments {
-				if !uint64InSlice(mtgaID, newMTGAs) {
-					updated = true
-					newMTGAs = append(newMTGAs, mtgaID)
-				}
-			}
+			newMTGAs, updated := batchMTGAs(vpc)
 			if updated {
 				vpc.Config.ManagedTransitGatewayAttachmentIDs = newMTGAs
 				err := s.ModelsManager.UpdateVPCConfig(vpc.Region, vpc.ID, *vpc.Config)
@@ -1924,6 +2516,38 @@ var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		}
 	}
 
+	if len(req.AddVPCEndpointSets) > 0 || len(req.RemoveVPCEndpointSets) > 0 {
+		for _, vpc := range vpcs {
+			if !vpc.State.VPCType.CanUpdateVPCEndpoints() {
+				continue
+			}
+			newSets := []uint64{}
+			updated := false
+			for _, setID := range vpc.Config.VPCEndpointSetIDs {
+				if uint64InSlice(setID, req.RemoveVPCEndpointSets) {
+					updated = true
+				} else {
+					newSets = append(newSets, setID)
+				}
+			}
+			for _, setID := range req.AddVPCEndpointSets {
+				if !uint64InSlice(setID, newSets) {
+					updated = true
+					newSets = append(newSets, setID)
+				}
+			}
+			if updated {
+				vpc.Config.VPCEndpointSetIDs = newSets
+				err := s.ModelsManager.UpdateVPCConfig(vpc.Region, vpc.ID, *vpc.Config)
+				if err != nil {
+					log.Printf("Error saving config for VPC %q: %s", vpc.ID, err)
+					http.Error(w, "Internal error", http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+	}
+
 	if len(req.AddResolverRuleSets) > 0 || len(req.RemoveResolverRuleSets) > 0 {
 		for _, vpc := range vpcs {
 			if !vpc.State.VPCType.CanUpdateResolverRules() {
@@ -1959,7 +2583,13 @@ var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		}
 	}
 
-	sess := s.getSession(r)
+	batchTaskID, err := s.TaskDatabase.AddBatchTask(batchDescription, batchSelector)
+	if err != nil {
+		log.Printf("Error adding batch task to database: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
 	errors := []string{}
 	for _, vpc := range vpcs {
 		_, err := scheduleVPCTasks(s.ModelsManager, s.TaskDatabase, database.Region(vpc.Region), vpc.AccountID, vpc.ID, sess.Username, req.TaskTypes, req.VerifySpec, nil, &batchTaskID)
@@ -1973,8 +2603,9 @@ var handleBatchTask = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		return
 	}
 
-	result := map[string]uint64{
+	result := map[string]interface{}{
 		"BatchTaskID": batchTaskID,
+		"Description": batchDescription,
 	}
 	buf, err := json.Marshal(result)
 	if err != nil {
@@ -2001,8 +2632,12 @@ var handleBatchTaskByID = func(s *Server, w http.ResponseWriter, r *http.Request
 	}
 
 	bt, err := s.TaskDatabase.GetBatchTaskByID(id)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("%s", err), http.StatusBadRequest)
+	if err == database.ErrRecordNotFound {
+		http.Error(w, fmt.Sprintf("No batch task %d", id), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error getting batch task %d: %s", id, err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
 	}
 
@@ -2296,6 +2931,19 @@ var handleDeleteAccountLabel = func(s *Server, w http.ResponseWriter, r *http.Re
 	fmt.Fprintf(w, "%s", "null")
 }
 
+func validateManagedTransitGatewayAttachment(mtga *database.ManagedTransitGatewayAttachment) error {
+	routeTableIDs := append([]string{}, mtga.PropagateRouteTableIDs...)
+	if mtga.AssociateRouteTableID != "" {
+		routeTableIDs = append(routeTableIDs, mtga.AssociateRouteTableID)
+	}
+	for _, id := range routeTableIDs {
+		if !strings.HasPrefix(id, "tgw-rtb-") {
+			return fmt.Errorf("%q is not a transit gateway route table ID", id)
+		}
+	}
+	return nil
+}
+
 var handleCreateManagedTransitGatewayAttachment = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
 	if len(args) != 0 {
 		log.Printf("Expected 0 additional args to handleCreateManagedTransitGatewayAttachment but got %d", len(args))
@@ -2309,6 +2957,11 @@ var handleCreateManagedTransitGatewayAttachment = func(s *Server, w http.Respons
 		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
 		return
 	}
+	err = validateManagedTransitGatewayAttachment(mtga)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	err = s.ModelsManager.CreateManagedTransitGatewayAttachment(mtga)
 	if err != nil {
@@ -2366,6 +3019,11 @@ var handleUpdateManagedTransitGatewayAttachment = func(s *Server, w http.Respons
 		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
 		return
 	}
+	err = validateManagedTransitGatewayAttachment(mtga)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	err = s.ModelsManager.UpdateManagedTransitGatewayAttachment(uint64(id), mtga)
 	if err != nil {
@@ -2389,6 +3047,11 @@ var handleCreateSecurityGroupSet = func(s *Server, w http.ResponseWriter, r *htt
 		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
 		return
 	}
+	err = validateSecurityGroupSetRules(sgs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	err = s.ModelsManager.CreateSecurityGroupSet(sgs)
 	if err != nil {
@@ -2452,6 +3115,11 @@ var handleUpdateSecurityGroupSet = func(s *Server, w http.ResponseWriter, r *htt
 		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
 		return
 	}
+	err = validateSecurityGroupSetRules(sgs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	err = s.ModelsManager.UpdateSecurityGroupSet(uint64(id), sgs)
 	if err != nil {
@@ -2652,37 +3320,966 @@ var handleSecurityGroupSetList = func(s *Server, w http.ResponseWriter, r *http.
 	fmt.Fprintf(w, "%s", buf)
 }
 
-var handleListManagedPrefixLists = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
-	if len(args) != 1 {
-		log.Printf("Expected 1 additional arg to handleListManagedPrefixLists but got %d", len(args))
+// parseSecurityGroupUsageRequest reads the optional analysis settings from
+// the request body, which may be empty.
+func parseSecurityGroupUsageRequest(r *http.Request) (*database.AnalyzeSecurityGroupUsageTaskData, error) {
+	config := &database.AnalyzeSecurityGroupUsageTaskData{}
+	err := json.NewDecoder(r.Body).Decode(config)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if config.Days < 0 {
+		return nil, fmt.Errorf("Days must not be negative")
+	}
+	if config.Source != "" && config.Source != database.FlowLogSourceCloudWatch && config.Source != database.FlowLogSourceS3 {
+		return nil, fmt.Errorf("Source must be %q or %q", database.FlowLogSourceCloudWatch, database.FlowLogSourceS3)
+	}
+	return config, nil
+}
+
+var handleVPCSecurityGroupUsage = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional args to handleVPCSecurityGroupUsage but got %d", len(args))
 		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
 	}
-
 	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
 
-	asUser := s.getSession(r).Username
-	prefixListAccountID := prefixListAccountIDCommercial
-	if strings.HasPrefix(region, "us-gov-") {
-		prefixListAccountID = prefixListAccountIDGovCloud
-	}
-	sess, err := s.CachedCredentials.GetAWSSession(prefixListAccountID, region, asUser)
+	config, err := parseSecurityGroupUsageRequest(r)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
 		return
 	}
-	ec2svc := ec2.New(sess)
+	config.AWSRegion = database.Region(region)
+	config.VPCID = vpcID
 
-	out, err := ec2svc.DescribeManagedPrefixLists(&ec2.DescribeManagedPrefixListsInput{
-		Filters: []*ec2.Filter{
-			{
-				Name:   aws.String("owner-id"),
-				Values: []*string{aws.String(prefixListAccountID)},
-			},
-		},
-	})
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error listing managed prefix lists: %s", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil {
+		http.Error(w, fmt.Sprintf("VPC %s is not automated", vpcID), http.StatusBadRequest)
+		return
+	}
+	if !vpc.State.VPCType.CanUpdateSecurityGroups() {
+		http.Error(w, "Not available for this type of VPC", http.StatusBadRequest)
+		return
+	}
+
+	taskData := &database.TaskData{
+		AnalyzeSecurityGroupUsageTaskData: config,
+		AsUser:                            s.getSession(r).Username,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		log.Printf("Error marshaling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, "Analyze VPC "+vpcID+" security group usage", taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+// handleAnalyzeSecurityGroupTemplateUsage queues an analysis of one template
+// in every VPC that has a security group for it.
+var handleAnalyzeSecurityGroupTemplateUsage = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleAnalyzeSecurityGroupTemplateUsage but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	templateID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	config, err := parseSecurityGroupUsageRequest(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	vpcs, err := s.ModelsManager.ListAutomatedVPCs()
+	if err != nil {
+		log.Printf("Error listing VPCs: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	taskIDs := []uint64{}
+	for _, listed := range vpcs {
+		vpc, err := s.ModelsManager.GetVPC(listed.Region, listed.ID)
+		if err != nil {
+			log.Printf("Error loading VPC %s: %s", listed.ID, err)
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		if vpc.State == nil || !vpc.State.VPCType.CanUpdateSecurityGroups() {
+			continue
+		}
+		hasTemplate := false
+		for _, sg := range vpc.State.SecurityGroups {
+			if sg.TemplateID == templateID && sg.SecurityGroupID != "" {
+				hasTemplate = true
+				break
+			}
+		}
+		if !hasTemplate {
+			continue
+		}
+		taskData := &database.TaskData{
+			AnalyzeSecurityGroupUsageTaskData: &database.AnalyzeSecurityGroupUsageTaskData{
+				VPCID:       vpc.ID,
+				AWSRegion:   vpc.Region,
+				TemplateIDs: []uint64{templateID},
+				Days:        config.Days,
+				Source:      config.Source,
+			},
+			AsUser: s.getSession(r).Username,
+		}
+		taskBytes, err := json.Marshal(taskData)
+		if err != nil {
+			log.Printf("Error marshaling: %s", err)
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		t, err := s.TaskDatabase.AddVPCTask(vpc.AccountID, vpc.ID, "Analyze VPC "+vpc.ID+" security group usage", taskBytes, database.TaskStatusQueued, nil)
+		if err != nil {
+			log.Printf("Error adding task: %s", err)
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		taskIDs = append(taskIDs, t.ID)
+	}
+
+	response := map[string][]uint64{
+		"TaskIDs": taskIDs,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+// templateRuleUsage is the total use of one template rule across all VPCs
+// that have been analyzed.
+type templateRuleUsage struct {
+	Rule *database.SecurityGroupRule
+	Hits int64
+	VPCs map[string]int64 // VPC ID -> hits
+}
+
+type templateUsageResponse struct {
+	TemplateID  uint64
+	Rules       []*templateRuleUsage
+	UnusedRules []*database.SecurityGroupRule
+	Analyses    []*database.SecurityGroupUsage
+}
+
+var handleSecurityGroupTemplateUsage = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleSecurityGroupTemplateUsage but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	templateID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	usages, err := s.ModelsManager.GetSecurityGroupUsage(templateID)
+	if err != nil {
+		log.Printf("Error getting security group usage: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	response := summarizeTemplateUsage(templateID, usages)
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+// summarizeTemplateUsage adds up the hits of each rule across VPCs. Rules are
+// matched by everything but their description; a rule is only reported as
+// unused if no analyzed VPC saw any traffic for it.
+func summarizeTemplateUsage(templateID uint64, usages []*database.SecurityGroupUsage) *templateUsageResponse {
+	response := &templateUsageResponse{
+		TemplateID:  templateID,
+		Rules:       []*templateRuleUsage{},
+		UnusedRules: []*database.SecurityGroupRule{},
+		Analyses:    usages,
+	}
+	for _, usage := range usages {
+		for _, ruleUsage := range usage.Rules {
+			var total *templateRuleUsage
+			for _, existing := range response.Rules {
+				if sameSecurityGroupRules([]*database.SecurityGroupRule{existing.Rule}, []*database.SecurityGroupRule{ruleUsage.Rule}) {
+					total = existing
+					break
+				}
+			}
+			if total == nil {
+				total = &templateRuleUsage{Rule: ruleUsage.Rule, VPCs: map[string]int64{}}
+				response.Rules = append(response.Rules, total)
+			}
+			total.Hits += ruleUsage.Hits
+			total.VPCs[usage.VPCID] += ruleUsage.Hits
+		}
+	}
+	for _, total := range response.Rules {
+		if total.Hits == 0 {
+			response.UnusedRules = append(response.UnusedRules, total.Rule)
+		}
+	}
+	return response
+}
+
+func validateVPCEndpointSet(set *database.VPCEndpointSet) error {
+	for _, ep := range set.Endpoints {
+		if ep.ServiceName == "" {
+			return fmt.Errorf("Every endpoint needs a service name")
+		}
+		if ep.Type != database.VPCEndpointTypeGateway && ep.Type != database.VPCEndpointTypeInterface {
+			return fmt.Errorf("Invalid type %q for endpoint %s", ep.Type, ep.ServiceName)
+		}
+		if len(ep.SubnetTypes) == 0 {
+			return fmt.Errorf("Endpoint %s needs at least one subnet type", ep.ServiceName)
+		}
+		if ep.Type == database.VPCEndpointTypeGateway && (ep.SecurityGroupTemplateID != 0 || ep.PrivateDNSEnabled) {
+			return fmt.Errorf("Gateway endpoint %s cannot have a security group or private DNS", ep.ServiceName)
+		}
+	}
+	return nil
+}
+
+var handleCreateVPCEndpointSet = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleCreateVPCEndpointSet but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	set := new(database.VPCEndpointSet)
+	err := json.NewDecoder(r.Body).Decode(set)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	err = validateVPCEndpointSet(set)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.CreateVPCEndpointSet(set)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating VPC endpoint set: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(set)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleUpdateVPCEndpointSet = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleUpdateVPCEndpointSet but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	set := new(database.VPCEndpointSet)
+	err = json.NewDecoder(r.Body).Decode(set)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	err = validateVPCEndpointSet(set)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.UpdateVPCEndpointSet(id, set)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating VPC endpoint set: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(set)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteVPCEndpointSet = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteVPCEndpointSet but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.DeleteVPCEndpointSet(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting VPC endpoint set: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprintf(w, "%s", "null")
+}
+
+var handleVPCEndpointSetList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleVPCEndpointSetList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	sets, err := s.ModelsManager.GetVPCEndpointSets()
+	if err != nil {
+		log.Printf("Error getting VPC endpoint sets: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(sets)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleVPCRequestTemplateList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleVPCRequestTemplateList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	templates, err := s.ModelsManager.GetVPCRequestTemplates()
+	if err != nil {
+		log.Printf("Error getting VPC request templates: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(templates)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleCreateVPCRequestTemplate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleCreateVPCRequestTemplate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	template := new(database.VPCRequestTemplate)
+	err := json.NewDecoder(r.Body).Decode(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.CreateVPCRequestTemplate(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating VPC request template: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(template)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleUpdateVPCRequestTemplate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleUpdateVPCRequestTemplate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	template := new(database.VPCRequestTemplate)
+	err = json.NewDecoder(r.Body).Decode(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.UpdateVPCRequestTemplate(id, template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating VPC request template: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(template)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteVPCRequestTemplate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteVPCRequestTemplate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.DeleteVPCRequestTemplate(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting VPC request template: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprintf(w, "%s", "null")
+}
+
+var handleFirewallPolicyTemplateList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleFirewallPolicyTemplateList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	templates, err := s.ModelsManager.GetFirewallPolicyTemplates()
+	if err != nil {
+		log.Printf("Error getting firewall policy templates: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(templates)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleCreateFirewallPolicyTemplate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleCreateFirewallPolicyTemplate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	template := new(database.FirewallPolicyTemplate)
+	err := json.NewDecoder(r.Body).Decode(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.CreateFirewallPolicyTemplate(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating firewall policy template: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(template)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleUpdateFirewallPolicyTemplate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleUpdateFirewallPolicyTemplate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	template := new(database.FirewallPolicyTemplate)
+	err = json.NewDecoder(r.Body).Decode(template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.UpdateFirewallPolicyTemplate(id, template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating firewall policy template: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(template)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteFirewallPolicyTemplate = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteFirewallPolicyTemplate but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.DeleteFirewallPolicyTemplate(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting firewall policy template: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprintf(w, "%s", "null")
+}
+
+func validateVPNConnection(vpn *database.VPNConnection) error {
+	if vpn.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if vpn.Region == "" {
+		return fmt.Errorf("Region is required")
+	}
+	if !strings.HasPrefix(vpn.TransitGatewayID, "tgw-") {
+		return fmt.Errorf("%q is not a transit gateway ID", vpn.TransitGatewayID)
+	}
+	if vpn.TransitGatewayRouteTableID != "" && !strings.HasPrefix(vpn.TransitGatewayRouteTableID, "tgw-rtb-") {
+		return fmt.Errorf("%q is not a transit gateway route table ID", vpn.TransitGatewayRouteTableID)
+	}
+	if vpn.StaticRoutesOnly && vpn.TransitGatewayRouteTableID == "" {
+		return fmt.Errorf("A transit gateway route table is required for static routing")
+	}
+	ip := net.ParseIP(vpn.CustomerGateway.IPAddress)
+	if ip == nil || ip.To4() == nil {
+		return fmt.Errorf("%q is not an IPv4 address", vpn.CustomerGateway.IPAddress)
+	}
+	if vpn.CustomerGateway.BGPASN < 1 || vpn.CustomerGateway.BGPASN > 4294967294 {
+		return fmt.Errorf("Invalid BGP ASN %d", vpn.CustomerGateway.BGPASN)
+	}
+	if len(vpn.TunnelOptions) > 2 {
+		return fmt.Errorf("A VPN connection has only 2 tunnels")
+	}
+	for _, opts := range vpn.TunnelOptions {
+		if opts.InsideCIDR != "" {
+			_, _, err := net.ParseCIDR(opts.InsideCIDR)
+			if err != nil {
+				return fmt.Errorf("Invalid tunnel inside CIDR %q", opts.InsideCIDR)
+			}
+		}
+	}
+	for _, cidr := range vpn.Routes {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("Invalid CIDR %q", cidr)
+		}
+	}
+	return nil
+}
+
+// vpnConnectionOwnerAccountID returns the account that owns vpn's transit
+// gateway, which is where the VPN connection is created.
+func (s *Server) vpnConnectionOwnerAccountID(vpn *database.VPNConnection) (string, error) {
+	share, err := s.ModelsManager.GetTransitGatewayResourceShare(vpn.Region, vpn.TransitGatewayID)
+	if err != nil {
+		return "", fmt.Errorf("Error getting resource share for transit gateway %s: %s", vpn.TransitGatewayID, err)
+	}
+	if share == nil {
+		return "", fmt.Errorf("No resource share is configured for transit gateway %s", vpn.TransitGatewayID)
+	}
+	return share.AccountID, nil
+}
+
+func (s *Server) getVPNConnectionStatus(id uint64, asUser string) (*vpnConnectionStatus, error) {
+	vpn, err := s.ModelsManager.GetVPNConnection(id)
+	if err != nil {
+		return nil, err
+	}
+	accountID, err := s.vpnConnectionOwnerAccountID(vpn)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := s.CachedCredentials.GetAWSSession(accountID, string(vpn.Region), asUser)
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to AWS: %s", err)
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{
+			Session: sess,
+		},
+	}
+	return getVPNConnectionStatus(ctx, vpn)
+}
+
+func (s *Server) queueVPNConnectionTask(r *http.Request, vpn *database.VPNConnection, delete bool) (*database.Task, error) {
+	accountID, err := s.vpnConnectionOwnerAccountID(vpn)
+	if err != nil {
+		return nil, err
+	}
+	taskData := &database.TaskData{
+		UpdateVPNConnectionTaskData: &database.UpdateVPNConnectionTaskData{
+			VPNConnectionID: vpn.ID,
+			Region:          vpn.Region,
+			Delete:          delete,
+		},
+		AsUser: s.getSession(r).Username,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling: %s", err)
+	}
+	description := "Update VPN connection " + vpn.Name
+	if delete {
+		description = "Delete VPN connection " + vpn.Name
+	}
+	return s.TaskDatabase.AddAccountTask(accountID, description, taskBytes, database.TaskStatusQueued)
+}
+
+var handleVPNConnectionList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleVPNConnectionList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpns, err := s.ModelsManager.GetVPNConnections()
+	if err != nil {
+		log.Printf("Error getting VPN connections: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(vpns)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleCreateVPNConnection = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleCreateVPNConnection but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpn := new(database.VPNConnection)
+	err := json.NewDecoder(r.Body).Decode(vpn)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	err = validateVPNConnection(vpn)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, err = s.vpnConnectionOwnerAccountID(vpn)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.CreateVPNConnection(vpn)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating VPN connection: %s", err), http.StatusBadRequest)
+		return
+	}
+	t, err := s.queueVPNConnectionTask(r, vpn, false)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"ID":     vpn.ID,
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleUpdateVPNConnection = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleUpdateVPNConnection but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	vpn := new(database.VPNConnection)
+	err = json.NewDecoder(r.Body).Decode(vpn)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	err = validateVPNConnection(vpn)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	existing, err := s.ModelsManager.GetVPNConnection(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting VPN connection: %s", err), http.StatusNotFound)
+		return
+	}
+	if vpn.Region != existing.Region || vpn.TransitGatewayID != existing.TransitGatewayID {
+		http.Error(w, "The region and transit gateway of a VPN connection cannot be changed", http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.UpdateVPNConnection(id, vpn)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating VPN connection: %s", err), http.StatusBadRequest)
+		return
+	}
+	vpn.ID = id
+	t, err := s.queueVPNConnectionTask(r, vpn, false)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteVPNConnection = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteVPNConnection but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	vpn, err := s.ModelsManager.GetVPNConnection(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error getting VPN connection: %s", err), http.StatusNotFound)
+		return
+	}
+	if len(vpn.InUseVPCs) > 0 {
+		http.Error(w, fmt.Sprintf("VPN connection is in use by %s", strings.Join(vpn.InUseVPCs, ", ")), http.StatusBadRequest)
+		return
+	}
+	t, err := s.queueVPNConnectionTask(r, vpn, true)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error adding task: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleProjectQuotaList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleProjectQuotaList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	quotas, err := s.ModelsManager.GetProjectQuotas()
+	if err != nil {
+		log.Printf("Error getting project quotas: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(quotas)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleSetProjectQuota = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleSetProjectQuota but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	quota := new(database.ProjectQuota)
+	err := json.NewDecoder(r.Body).Decode(quota)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	quota.ProjectName = args[0]
+
+	err = s.ModelsManager.SetProjectQuota(quota)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error setting project quota: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	buf, err := json.Marshal(quota)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteProjectQuota = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteProjectQuota but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	err := s.ModelsManager.DeleteProjectQuota(args[0])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting project quota: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprintf(w, "%s", "null")
+}
+
+var handleListManagedPrefixLists = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleListManagedPrefixLists but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	region := args[0]
+
+	asUser := s.getSession(r).Username
+	prefixListAccountID := prefixListAccountIDCommercial
+	if strings.HasPrefix(region, "us-gov-") {
+		prefixListAccountID = prefixListAccountIDGovCloud
+	}
+	sess, err := s.CachedCredentials.GetAWSSession(prefixListAccountID, region, asUser)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error connecting to AWS: %s", err), http.StatusInternalServerError)
+		return
+	}
+	ec2svc := ec2.New(sess)
+
+	out, err := ec2svc.DescribeManagedPrefixLists(&ec2.DescribeManagedPrefixListsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("owner-id"),
+				Values: []*string{aws.String(prefixListAccountID)},
+			},
+		},
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error listing managed prefix lists: %s", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -2744,13 +4341,9 @@ var handleManagedPrefixListDetails = func(s *Server, w http.ResponseWriter, r *h
 
 // Takes the first URL that looks like https://jiraent.cms.gov/browse/IA-1801
 // and stores the issue ID (IA-1801) in the provided location.
-func extractJIRAIssue(relatedIssueURLs []string, issueID *string) {
-	for _, url := range relatedIssueURLs {
-		if strings.HasPrefix(url, jira.BaseURL) {
-			pieces := strings.Split(url, "/")
-			*issueID = pieces[len(pieces)-1]
-			break
-		}
+func (s *Server) extractRelatedIssue(relatedIssueURLs []string, issueID *string) {
+	if key := s.Ticketing.IssueKeyFromURLs(relatedIssueURLs); key != "" {
+		*issueID = key
 	}
 }
 
@@ -2783,15 +4376,37 @@ var handleProvisionRequest = func(s *Server, w http.ResponseWriter, r *http.Requ
 	}
 	allocateConfig.AccountID = req.AccountID
 
-	err = s.ModelsManager.SetVPCRequestApprovedConfig(requestID, allocateConfig)
+	if req.RequestType != database.RequestTypeNewSubnet && req.RequestType != database.RequestTypeNewVPC {
+		log.Printf("Unknown request type: %d", req.RequestType)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	taskID, err := s.provisionVPCRequest(req, allocateConfig, s.getSession(r).Username)
 	if err != nil {
-		log.Printf("Error updating request %d status: %s", requestID, err)
-		http.Error(w, "Error updating request status", http.StatusInternalServerError)
+		log.Printf("Error provisioning request %d: %s", requestID, err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
 	}
 
-	var response map[string]uint64
-	asUser := s.getSession(r).Username
+	buf, err := json.Marshal(map[string]uint64{
+		"TaskID": taskID,
+	})
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+// provisionVPCRequest records the approved config and queues the tasks that
+// carry out the request, returning the ID of the task to watch.
+func (s *Server) provisionVPCRequest(req *database.VPCRequest, allocateConfig *database.AllocateConfig, asUser string) (uint64, error) {
+	err := s.ModelsManager.SetVPCRequestApprovedConfig(req.ID, allocateConfig)
+	if err != nil {
+		return 0, fmt.Errorf("Error updating request status: %s", err)
+	}
 
 	if req.RequestType == database.RequestTypeNewSubnet {
 		taskData := &database.AddZonedSubnetsTaskData{
@@ -2810,37 +4425,29 @@ var handleProvisionRequest = func(s *Server, w http.ResponseWriter, r *http.Requ
 			AddZonedSubnetsTaskData: taskData,
 			AsUser:                  asUser,
 		}
-		extractJIRAIssue(req.RelatedIssues, &prereq.AddZonedSubnetsTaskData.JIRAIssueForComment)
+		s.extractRelatedIssue(req.RelatedIssues, &prereq.AddZonedSubnetsTaskData.JIRAIssueForComment)
 		taskBytes, err := json.Marshal(prereq)
 		if err != nil {
-			log.Printf("Error marshalling: %s", err)
-			http.Error(w, "Internal error", http.StatusInternalServerError)
-			return
+			return 0, fmt.Errorf("Error marshalling: %s", err)
 		}
 
 		taskName := fmt.Sprintf("Adding %s subnets to %s", taskData.SubnetType, allocateConfig.VPCID)
 		t, err := s.TaskDatabase.AddVPCTask(allocateConfig.AccountID, allocateConfig.VPCID, taskName, taskBytes, database.TaskStatusQueued, nil)
 		if err != nil {
-			log.Printf("Error adding task: %s", err)
-			http.Error(w, "Internal error", http.StatusInternalServerError)
-			return
+			return 0, fmt.Errorf("Error adding task: %s", err)
 		}
 		id, err := scheduleVPCTasks(s.ModelsManager, s.TaskDatabase, database.Region(allocateConfig.AWSRegion), allocateConfig.AccountID, allocateConfig.VPCID, asUser, database.TaskTypeNetworking, database.VerifySpec{}, t, nil)
 		if err != nil {
-			log.Printf("Error scheduling add-zoned-subnets task: %s", err)
-			http.Error(w, "Internal error", http.StatusInternalServerError)
-			return
+			return 0, fmt.Errorf("Error scheduling add-zoned-subnets task: %s", err)
 		}
 
-		err = s.ModelsManager.SetVPCRequestTaskID(uint64(requestID), t.ID)
+		err = s.ModelsManager.SetVPCRequestTaskID(req.ID, t.ID)
 		if err != nil {
-			log.Printf("Error updating request %d task ID: %s", requestID, err)
-		}
-
-		response = map[string]uint64{
-			"TaskID": id,
+			log.Printf("Error updating request %d task ID: %s", req.ID, err)
 		}
+		return id, nil
 	} else if req.RequestType == database.RequestTypeNewVPC {
+		requestID := req.ID
 		taskData := &database.TaskData{
 			CreateVPCTaskData: &database.CreateVPCTaskData{
 				AllocateConfig: *allocateConfig,
@@ -2848,41 +4455,24 @@ var handleProvisionRequest = func(s *Server, w http.ResponseWriter, r *http.Requ
 			},
 			AsUser: asUser,
 		}
-		extractJIRAIssue(req.RelatedIssues, &taskData.CreateVPCTaskData.JIRAIssueForComment)
+		s.extractRelatedIssue(req.RelatedIssues, &taskData.CreateVPCTaskData.JIRAIssueForComment)
 		taskBytes, err := json.Marshal(taskData)
 		if err != nil {
-			log.Printf("Error marshaling: %s", err)
-			http.Error(w, "Internal error", http.StatusInternalServerError)
-			return
+			return 0, fmt.Errorf("Error marshaling: %s", err)
 		}
 
 		t, err := s.TaskDatabase.AddAccountTask(allocateConfig.AccountID, "Create new VPC "+allocateConfig.VPCName, taskBytes, database.TaskStatusQueued)
 		if err != nil {
-			log.Printf("Error adding task: %s", err)
-			http.Error(w, "Internal error", http.StatusInternalServerError)
-			return
+			return 0, fmt.Errorf("Error adding task: %s", err)
 		}
 
-		err = s.ModelsManager.SetVPCRequestTaskID(uint64(requestID), t.ID)
+		err = s.ModelsManager.SetVPCRequestTaskID(req.ID, t.ID)
 		if err != nil {
-			log.Printf("Error updating request %d task ID: %s", requestID, err)
-		}
-		response = map[string]uint64{
-			"TaskID": t.ID,
+			log.Printf("Error updating request %d task ID: %s", req.ID, err)
 		}
-	} else {
-		log.Printf("Unknown request type: %d", req.RequestType)
-		http.Error(w, "Bad Request", http.StatusBadRequest)
-		return
-	}
-
-	buf, err := json.Marshal(response)
-	if err != nil {
-		log.Printf("Error marshalling: %s", err)
-		http.Error(w, "Internal error", http.StatusInternalServerError)
-		return
+		return t.ID, nil
 	}
-	fmt.Fprintf(w, "%s", buf)
+	return 0, fmt.Errorf("Unknown request type: %d", req.RequestType)
 }
 
 var handleVPCRequestList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
@@ -2935,6 +4525,101 @@ var handleGetVPCRequest = func(s *Server, w http.ResponseWriter, r *http.Request
 	fmt.Fprintf(w, "%s", buf)
 }
 
+// canAccessVPCRequest is true for admins and for users with access to the request's account.
+func (s *Server) canAccessVPCRequest(r *http.Request, req *database.VPCRequest) bool {
+	sess := s.getSession(r)
+	if sess.IsAdmin {
+		return true
+	}
+	for _, account := range sess.AuthorizedAccounts {
+		if account.ID == req.AccountID {
+			return true
+		}
+	}
+	return false
+}
+
+var handleVPCRequestCommentList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 argument to handleVPCRequestCommentList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpcRequestID, _ := strconv.ParseUint(args[0], 10, 64)
+	req, err := s.ModelsManager.GetVPCRequest(vpcRequestID)
+	if err != nil {
+		log.Printf("Error getting VPC Request: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if !s.canAccessVPCRequest(r, req) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	comments, err := s.ModelsManager.GetVPCRequestComments(vpcRequestID)
+	if err != nil {
+		log.Printf("Error getting comments for VPC Request %d: %s", vpcRequestID, err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(comments)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleAddVPCRequestComment = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 argument to handleAddVPCRequestComment but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpcRequestID, _ := strconv.ParseUint(args[0], 10, 64)
+	req, err := s.ModelsManager.GetVPCRequest(vpcRequestID)
+	if err != nil {
+		log.Printf("Error getting VPC Request: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if !s.canAccessVPCRequest(r, req) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var body struct {
+		Body string
+	}
+	err = json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Body) == "" {
+		http.Error(w, "Comment is empty", http.StatusBadRequest)
+		return
+	}
+
+	comment := &database.VPCRequestComment{
+		VPCRequestID: vpcRequestID,
+		Author:       s.getSession(r).Username,
+		Body:         body.Body,
+	}
+	comment.ID, err = s.ModelsManager.InsertVPCRequestComment(comment)
+	if err != nil {
+		log.Printf("Error inserting comment for VPC Request %d: %s", vpcRequestID, err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	// SyncVPCRequestStatuses posts it to the request's issue
+}
+
 var handleVPCRequestJIRAIssueErrorList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
 	if len(args) != 1 {
 		log.Printf("Expected 1 argument to handleVPCRequestJiraIssueList but got %d", len(args))
@@ -2985,6 +4670,7 @@ var handleIndex = func(s *Server, w http.ResponseWriter, r *http.Request, args .
 }
 
 const internetRoute = "0.0.0.0/0"
+const ipv6InternetRoute = "::/0"
 
 var handleVPCImport = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
 	if len(args) != 3 {
@@ -3010,6 +4696,16 @@ var handleVPCImport = func(s *Server, w http.ResponseWriter, r *http.Request, ar
 		Region:    database.Region(region),
 		AccountID: accountID,
 	}
+	if r.FormValue("ipv6") == "1" {
+		if isLegacy {
+			http.Error(w, "IPv6 is not supported for legacy VPCs", http.StatusBadRequest)
+			return
+		}
+		importData.IPv6 = &database.IPv6Config{
+			Pool:      r.FormValue("ipv6Pool"),
+			CIDRBlock: r.FormValue("ipv6CIDR"),
+		}
+	}
 
 	taskData := &database.TaskData{
 		ImportVPCTaskData: importData,
@@ -3202,6 +4898,8 @@ func scheduleVPCTasks(mm database.ModelsManager, taskDB *database.TaskDatabase,
 			ConnectPrivate:                     vpc.Config.ConnectPrivate,
 			ManagedTransitGatewayAttachmentIDs: vpc.Config.ManagedTransitGatewayAttachmentIDs,
 			PeeringConnections:                 vpc.Config.PeeringConnections,
+			ExternalPeeringConnections:         vpc.Config.ExternalPeeringConnections,
+			VPNConnectionIDs:                   vpc.Config.VPNConnectionIDs,
 		}}
 		networkConfig.AWSRegion = region
 		networkConfig.VPCID = vpcID
@@ -3236,19 +4934,57 @@ func scheduleVPCTasks(mm database.ModelsManager, taskDB *database.TaskDatabase,
 		}
 	}
 
-	if taskTypes&database.TaskTypeSecurityGroups != 0 && vpc.State.VPCType.CanUpdateSecurityGroups() {
+	if taskTypes&database.TaskTypeSecurityGroups != 0 && vpc.State.VPCType.CanUpdateSecurityGroups() {
+		taskData := &database.TaskData{
+			UpdateSecurityGroupsTaskData: &database.UpdateSecurityGroupsTaskData{
+				VPCID:     vpc.ID,
+				AWSRegion: vpc.Region,
+				SecurityGroupConfig: database.SecurityGroupConfig{
+					SecurityGroupSetIDs: vpc.Config.SecurityGroupSetIDs,
+				},
+			},
+			AsUser: asUser,
+		}
+		_, err := addTask(&taskInfo{
+			name: "Update VPC " + vpcID + " security groups",
+			data: taskData,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("Error adding task: %s", err)
+		}
+	}
+
+	if taskTypes&database.TaskTypeVPCEndpoints != 0 && vpc.State.VPCType.CanUpdateVPCEndpoints() {
 		taskData := &database.TaskData{
-			UpdateSecurityGroupsTaskData: &database.UpdateSecurityGroupsTaskData{
+			UpdateVPCEndpointsTaskData: &database.UpdateVPCEndpointsTaskData{
 				VPCID:     vpc.ID,
 				AWSRegion: vpc.Region,
-				SecurityGroupConfig: database.SecurityGroupConfig{
-					SecurityGroupSetIDs: vpc.Config.SecurityGroupSetIDs,
+				VPCEndpointConfig: database.VPCEndpointConfig{
+					VPCEndpointSetIDs: vpc.Config.VPCEndpointSetIDs,
 				},
 			},
 			AsUser: asUser,
 		}
 		_, err := addTask(&taskInfo{
-			name: "Update VPC " + vpcID + " security groups",
+			name: "Update VPC " + vpcID + " endpoints",
+			data: taskData,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("Error adding task: %s", err)
+		}
+	}
+
+	if taskTypes&database.TaskTypeFirewallPolicy != 0 && vpc.State.VPCType.HasFirewall() {
+		taskData := &database.TaskData{
+			UpdateFirewallPolicyTaskData: &database.UpdateFirewallPolicyTaskData{
+				VPCID:                    vpc.ID,
+				AWSRegion:                vpc.Region,
+				FirewallPolicyTemplateID: vpc.Config.FirewallPolicyTemplateID,
+			},
+			AsUser: asUser,
+		}
+		_, err := addTask(&taskInfo{
+			name: "Update VPC " + vpcID + " firewall policy",
 			data: taskData,
 		})
 		if err != nil {
@@ -3531,6 +5267,66 @@ var handleAddZonedSubnets = func(s *Server, w http.ResponseWriter, r *http.Reque
 	fmt.Fprintf(w, "%s", buf)
 }
 
+var handleExpandCIDR = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional arg to handleExpandCIDR but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	expandData := &database.ExpandCIDRTaskData{}
+	err := json.NewDecoder(r.Body).Decode(expandData)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if expandData.GroupName == "" {
+		http.Error(w, "No group name specified", http.StatusBadRequest)
+		return
+	}
+	expandData.VPCID = vpcID
+	expandData.Region = database.Region(region)
+
+	prereq := &database.TaskData{
+		ExpandCIDRTaskData: expandData,
+	}
+	prereq.AsUser = s.getSession(r).Username
+	taskBytes, err := json.Marshal(prereq)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	taskName := fmt.Sprintf("Expanding %s subnet group %q in %s", expandData.SubnetType, expandData.GroupName, vpcID)
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, taskName, taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	id, err := scheduleVPCTasks(s.ModelsManager, s.TaskDatabase, database.Region(region), accountID, vpcID, s.getSession(r).Username, database.TaskTypeNetworking, database.VerifySpec{}, t, nil)
+	if err != nil {
+		log.Printf("Error scheduling expand-cidr task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": id,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
 var handleConnectCMSNet = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
 	if len(args) != 3 {
 		log.Printf("Expected 3 additional arg to handleConnectCMSNet but got %d", len(args))
@@ -4171,6 +5967,87 @@ var handleVPCFlowLogs = func(s *Server, w http.ResponseWriter, r *http.Request,
 	fmt.Fprintf(w, "%s", buf)
 }
 
+var awsAccountIDRegexp = regexp.MustCompile(`^[0-9]{12}$`)
+
+// AWS VPC CIDR blocks are at most this large, so a shorter prefix can't be
+// the other VPC's CIDR or one of its subnets
+const (
+	minExternalPeeringIPv4PrefixLength = 16
+	minExternalPeeringIPv6PrefixLength = 44
+)
+
+// validateExternalPeeringConnections checks the external peering
+// connections configured for vpc and rewrites their CIDRs in canonical
+// form, so that routes to them match what AWS reports.
+func (s *Server) validateExternalPeeringConnections(vpc *database.VPC, configs []*database.ExternalPeeringConnectionConfig) error {
+	ownCIDRs := []*net.IPNet{}
+	primary, secondaries, err := s.ModelsManager.GetVPCCIDRs(vpc.ID, vpc.Region)
+	if err != nil {
+		return fmt.Errorf("Error getting CIDRs of %s: %s", vpc.ID, err)
+	}
+	cidrs := secondaries
+	if primary != nil && *primary != "" {
+		cidrs = append([]string{*primary}, secondaries...)
+	}
+	if vpc.State.IPv6 != nil && vpc.State.IPv6.AssociatedCIDRBlock != "" {
+		cidrs = append(cidrs, vpc.State.IPv6.AssociatedCIDRBlock)
+	}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("Invalid CIDR %q on %s: %s", cidr, vpc.ID, err)
+		}
+		ownCIDRs = append(ownCIDRs, ipNet)
+	}
+
+	for _, config := range configs {
+		if !strings.HasPrefix(config.OtherVPCID, "vpc-") {
+			return fmt.Errorf("%q is not a VPC ID", config.OtherVPCID)
+		}
+		if !awsAccountIDRegexp.MatchString(config.OtherVPCAccountID) {
+			return fmt.Errorf("%q is not an AWS account ID", config.OtherVPCAccountID)
+		}
+		if config.OtherVPCRegion == "" {
+			return fmt.Errorf("No region given for external VPC %s", config.OtherVPCID)
+		}
+		if len(config.OtherVPCCIDRs) == 0 {
+			return fmt.Errorf("No CIDRs given for external VPC %s", config.OtherVPCID)
+		}
+		otherCIDRs := []*net.IPNet{}
+		for idx, cidr := range config.OtherVPCCIDRs {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+			if err != nil {
+				return fmt.Errorf("Invalid CIDR %q for external VPC %s", cidr, config.OtherVPCID)
+			}
+			ones, bits := ipNet.Mask.Size()
+			minLength := minExternalPeeringIPv4PrefixLength
+			if bits == 128 {
+				minLength = minExternalPeeringIPv6PrefixLength
+			}
+			if ones < minLength {
+				return fmt.Errorf("CIDR %s for external VPC %s is larger than a VPC can be; the prefix must be at least /%d", ipNet, config.OtherVPCID, minLength)
+			}
+			for _, own := range ownCIDRs {
+				if own.Contains(ipNet.IP) || ipNet.Contains(own.IP) {
+					return fmt.Errorf("CIDR %s for external VPC %s overlaps %s's CIDR %s", ipNet, config.OtherVPCID, vpc.ID, own)
+				}
+			}
+			for _, other := range otherCIDRs {
+				if other.Contains(ipNet.IP) || ipNet.Contains(other.IP) {
+					return fmt.Errorf("CIDRs %s and %s for external VPC %s overlap", other, ipNet, config.OtherVPCID)
+				}
+			}
+			otherCIDRs = append(otherCIDRs, ipNet)
+			config.OtherVPCCIDRs[idx] = ipNet.String()
+		}
+		other, err := s.ModelsManager.GetVPC(config.OtherVPCRegion, config.OtherVPCID)
+		if err == nil && other.State != nil {
+			return fmt.Errorf("VPC %s is managed by vpc-conf; peer with it directly instead", config.OtherVPCID)
+		}
+	}
+	return nil
+}
+
 var handleVPCNetwork = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
 	if len(args) != 3 {
 		log.Printf("Expected 3 additional arg to handleVPCNetwork but got %d", len(args))
@@ -4203,11 +6080,40 @@ var handleVPCNetwork = func(s *Server, w http.ResponseWriter, r *http.Request, a
 		http.Error(w, "Not available for Exception VPCs", http.StatusBadRequest)
 		return
 	}
+	// Leaving a field out keeps what is configured
+	if networkConfig.ExternalPeeringConnections == nil {
+		networkConfig.ExternalPeeringConnections = vpc.Config.ExternalPeeringConnections
+	}
+	if networkConfig.VPNConnectionIDs == nil {
+		networkConfig.VPNConnectionIDs = vpc.Config.VPNConnectionIDs
+	}
 
 	if networkConfig.ConnectPrivate && !networkConfig.ConnectPublic {
 		http.Error(w, "You cannot connect private subnets to the internet without connecting public subnets.", http.StatusBadRequest)
 		return
 	}
+	err = s.validateExternalPeeringConnections(vpc, networkConfig.ExternalPeeringConnections)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(networkConfig.VPNConnectionIDs) > 0 {
+		managedAttachments, err := s.ModelsManager.GetManagedTransitGatewayAttachments()
+		if err != nil {
+			log.Printf("Error getting transit gateway configuration info: %s", err)
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		managedAttachmentsByID := make(map[uint64]*database.ManagedTransitGatewayAttachment)
+		for _, ma := range managedAttachments {
+			managedAttachmentsByID[ma.ID] = ma
+		}
+		_, err = getVPNConnectionsForVPC(s.ModelsManager, vpc, networkConfig.VPNConnectionIDs, networkConfig.ManagedTransitGatewayAttachmentIDs, managedAttachmentsByID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	taskData := &database.TaskData{
 		UpdateNetworkingTaskData: networkConfig,
@@ -4231,6 +6137,8 @@ var handleVPCNetwork = func(s *Server, w http.ResponseWriter, r *http.Request, a
 	vpc.Config.ConnectPrivate = networkConfig.ConnectPrivate
 	vpc.Config.ManagedTransitGatewayAttachmentIDs = networkConfig.ManagedTransitGatewayAttachmentIDs
 	vpc.Config.PeeringConnections = networkConfig.PeeringConnections
+	vpc.Config.ExternalPeeringConnections = networkConfig.ExternalPeeringConnections
+	vpc.Config.VPNConnectionIDs = networkConfig.VPNConnectionIDs
 	err = s.ModelsManager.UpdateVPCConfig(database.Region(region), vpcID, *vpc.Config)
 	if err != nil {
 		log.Printf("Error updating VPC config: %s", err)
@@ -4472,6 +6380,159 @@ var handleVPCSecurityGroups = func(s *Server, w http.ResponseWriter, r *http.Req
 	fmt.Fprintf(w, "%s", buf)
 }
 
+var handleVPCEndpoints = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional arg to handleVPCEndpoints but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	config := &database.UpdateVPCEndpointsTaskData{}
+	err := json.NewDecoder(r.Body).Decode(&config)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	config.AWSRegion = database.Region(region)
+	config.VPCID = vpcID
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil {
+		http.Error(w, fmt.Sprintf("VPC %s is not automated", vpcID), http.StatusBadRequest)
+		return
+	}
+	if !vpc.State.VPCType.CanUpdateVPCEndpoints() {
+		http.Error(w, "Not available for this type of VPC", http.StatusBadRequest)
+		return
+	}
+
+	taskData := &database.TaskData{
+		UpdateVPCEndpointsTaskData: config,
+		AsUser:                     s.getSession(r).Username,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		log.Printf("Error marshaling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, "Update VPC "+vpcID+" endpoints", taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpc.Config.VPCEndpointSetIDs = config.VPCEndpointSetIDs
+	err = s.ModelsManager.UpdateVPCConfig(database.Region(region), vpcID, *vpc.Config)
+	if err != nil {
+		log.Printf("Error updating VPC config: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleVPCFirewallPolicy = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 3 {
+		log.Printf("Expected 3 additional arg to handleVPCFirewallPolicy but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	region := args[0]
+	accountID := args[1]
+	vpcID := args[2]
+
+	config := &database.UpdateFirewallPolicyTaskData{}
+	err := json.NewDecoder(r.Body).Decode(&config)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	config.AWSRegion = database.Region(region)
+	config.VPCID = vpcID
+
+	vpc, err := s.ModelsManager.GetVPC(database.Region(region), vpcID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error loading VPC from database: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if vpc.State == nil {
+		http.Error(w, fmt.Sprintf("VPC %s is not automated", vpcID), http.StatusBadRequest)
+		return
+	}
+	if !vpc.State.VPCType.HasFirewall() {
+		http.Error(w, "Not available for this type of VPC", http.StatusBadRequest)
+		return
+	}
+	if config.FirewallPolicyTemplateID != 0 {
+		template, err := s.ModelsManager.GetFirewallPolicyTemplate(config.FirewallPolicyTemplateID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if template.Region != vpc.Region {
+			http.Error(w, fmt.Sprintf("Firewall policy template %q is for %s", template.Name, template.Region), http.StatusBadRequest)
+			return
+		}
+	}
+
+	taskData := &database.TaskData{
+		UpdateFirewallPolicyTaskData: config,
+		AsUser:                       s.getSession(r).Username,
+	}
+	taskBytes, err := json.Marshal(taskData)
+	if err != nil {
+		log.Printf("Error marshaling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	t, err := s.TaskDatabase.AddVPCTask(accountID, vpcID, "Update VPC "+vpcID+" firewall policy", taskBytes, database.TaskStatusQueued, nil)
+	if err != nil {
+		log.Printf("Error adding task: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	vpc.Config.FirewallPolicyTemplateID = config.FirewallPolicyTemplateID
+	err = s.ModelsManager.UpdateVPCConfig(database.Region(region), vpcID, *vpc.Config)
+	if err != nil {
+		log.Printf("Error updating VPC config: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"TaskID": t.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
 var handleGetVPCState = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
 	if len(args) != 3 {
 		log.Printf("Expected 3 additional arg to handleGetVPCState but got %d", len(args))
@@ -4587,14 +6648,7 @@ var handleVPCTransitGatewayAttachments = func(s *Server, w http.ResponseWriter,
 			}
 			for _, rt := range out.RouteTables {
 				for _, route := range rt.Routes {
-					var dest string
-
-					if aws.StringValue(route.DestinationCidrBlock) != "" {
-						dest = aws.StringValue(route.DestinationCidrBlock)
-					} else if aws.StringValue(route.DestinationPrefixListId) != "" {
-						dest = aws.StringValue(route.DestinationPrefixListId)
-					}
-
+					dest := awsp.RouteDestination(route)
 					if dest == "" {
 						continue
 					}
@@ -4811,8 +6865,13 @@ var handleVPCDetails = func(s *Server, w http.ResponseWriter, r *http.Request, a
 			ManagedTransitGatewayAttachmentIDs: vpc.Config.ManagedTransitGatewayAttachmentIDs,
 			ManagedResolverRuleSetIDs:          vpc.Config.ManagedResolverRuleSetIDs,
 			PeeringConnections:                 vpc.Config.PeeringConnections,
+			ExternalPeeringConnections:         vpc.Config.ExternalPeeringConnections,
+			VPNConnectionIDs:                   vpc.Config.VPNConnectionIDs,
 			SecurityGroupSetIDs:                vpc.Config.SecurityGroupSetIDs,
+			VPCEndpointSetIDs:                  vpc.Config.VPCEndpointSetIDs,
+			FirewallPolicyTemplateID:           vpc.Config.FirewallPolicyTemplateID,
 		},
+		ExternalPeeringConnections: vpc.State.ExternalPeeringConnections,
 	}
 	subnetIDToName := make(map[string]string)
 	var primaryIPNet *net.IPNet
@@ -4893,6 +6952,15 @@ var handleVPCDetails = func(s *Server, w http.ResponseWriter, r *http.Request, a
 				}
 			}
 		}
+		vpcInfo.VPNConnections = []*vpnConnectionStatus{}
+		for _, id := range vpc.Config.VPNConnectionIDs {
+			status, err := s.getVPNConnectionStatus(id, asUser)
+			if err != nil {
+				vpcInfo.VPNError = fmt.Sprintf("Error getting VPN connection status: %s", err)
+				break
+			}
+			vpcInfo.VPNConnections = append(vpcInfo.VPNConnections, status)
+		}
 		for _, subnet := range out.Subnets {
 			subnetIDToSubnet[*subnet.SubnetId] = subnet
 			for _, tag := range subnet.Tags {
@@ -5343,18 +7411,34 @@ var readOnlyHandlers = []*func(*Server, http.ResponseWriter, *http.Request, ...s
 	&handleGetLabels,
 	&handleGetAccountLabels,
 	&handleListBatchVPCLabels,
+	&handleVPCSelectorList,
+	&handleSelectVPCs,
 	&handleGetVPCLabels,
 	&handleManagedTransitGatewayAttachmentList,
 	&handleManagedResolverRuleSetList,
 	&handleSearch,
 	&handleSecurityGroupSetList,
+	&handleSecurityGroupTemplateUsage,
+	&handleVPCEndpointSetList,
+	&handleFirewallPolicyTemplateList,
+	&handleVPNConnectionList,
+	&handleRouteTableSnapshotList, &handleRouteTableSnapshotDetails, &handleRouteTableSnapshotDiff, &handleVPCRouteTables,
+	&handleSharedServiceEndpointList, &handleConnectivityTestResults, &handlePathAnalysis,
+	&handleVPCCompliance,
+	&handleEgressIPList, &handleEgressIPEventList,
+	&handleCMSNetHealth,
+	// Requesters pick a template when filling out a request
+	&handleVPCRequestTemplateList,
 	&handleTasks,
 	&handleVPCLastSubTasks,
 	&handleVPCDetails,
 	&handleVPCRequestList,
 	&handleGetVPCRequest,
+	// Requesters can read the discussion on their own requests; access is checked per request
+	&handleVPCRequestCommentList,
 	&handleVPCTask,
 	&handleIPUsageList,
+	&handleRouteTableCapacityReport,
 	&handleGetTask,
 }
 
@@ -5694,6 +7778,8 @@ func (s *Server) scheduleV1ToV1FirewallTasks(vpcID, accountID, asUser string, re
 			ConnectPrivate:                     vpc.Config.ConnectPrivate,
 			ManagedTransitGatewayAttachmentIDs: vpc.Config.ManagedTransitGatewayAttachmentIDs,
 			PeeringConnections:                 vpc.Config.PeeringConnections,
+			ExternalPeeringConnections:         vpc.Config.ExternalPeeringConnections,
+			VPNConnectionIDs:                   vpc.Config.VPNConnectionIDs,
 		},
 	}
 	taskData = &database.TaskData{
@@ -5783,6 +7869,8 @@ func (s *Server) scheduleV1FirewallToV1Tasks(vpcID, accountID, asUser string, re
 			ConnectPrivate:                     vpc.Config.ConnectPrivate,
 			ManagedTransitGatewayAttachmentIDs: vpc.Config.ManagedTransitGatewayAttachmentIDs,
 			PeeringConnections:                 vpc.Config.PeeringConnections,
+			ExternalPeeringConnections:         vpc.Config.ExternalPeeringConnections,
+			VPNConnectionIDs:                   vpc.Config.VPNConnectionIDs,
 		},
 	}
 	taskData = &database.TaskData{
@@ -5889,17 +7977,10 @@ func (s *Server) findCustomRoutesOnPublicRouteTables(ec2svc *ec2.EC2, vpc *datab
 }
 
 func routeInfoIsEqualToRoute(info *database.RouteInfo, route *ec2.Route) bool {
-	var routeDestination string
-
-	if aws.StringValue(route.DestinationCidrBlock) != "" {
-		routeDestination = aws.StringValue(route.DestinationCidrBlock)
-	} else if aws.StringValue(route.DestinationPrefixListId) != "" {
-		routeDestination = aws.StringValue(route.DestinationPrefixListId)
-	}
-
-	destIsEqual := info.Destination == routeDestination
+	destIsEqual := info.Destination == awsp.RouteDestination(route)
 
 	natIsEqual := info.NATGatewayID == aws.StringValue(route.NatGatewayId)
+	eigwIsEqual := info.EgressOnlyInternetGatewayID == aws.StringValue(route.EgressOnlyInternetGatewayId)
 	tgwIsEqual := info.TransitGatewayID == aws.StringValue(route.TransitGatewayId)
 	pcxIsEqual := info.PeeringConnectionID == aws.StringValue(route.VpcPeeringConnectionId)
 
@@ -5913,7 +7994,7 @@ func routeInfoIsEqualToRoute(info *database.RouteInfo, route *ec2.Route) bool {
 		gatewayIsEqual = true
 	}
 
-	return destIsEqual && gatewayIsEqual && natIsEqual && tgwIsEqual && pcxIsEqual
+	return destIsEqual && gatewayIsEqual && natIsEqual && eigwIsEqual && tgwIsEqual && pcxIsEqual
 }
 
 func (s *Server) getSessionKeyForPrincipal(principal string) string {
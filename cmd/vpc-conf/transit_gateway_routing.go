@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// transitGatewayRouting is the route table setup wanted on the transit
+// gateway side of one attachment.
+type transitGatewayRouting struct {
+	AssociateRouteTableID  string
+	PropagateRouteTableIDs []string
+}
+
+// desiredTransitGatewayRouting combines the route table settings of all the
+// managed attachments that share one transit gateway attachment.
+func desiredTransitGatewayRouting(managedIDs []uint64, managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment) (*transitGatewayRouting, error) {
+	routing := &transitGatewayRouting{PropagateRouteTableIDs: []string{}}
+	associatedBy := ""
+	for _, id := range managedIDs {
+		ma := managedAttachmentsByID[id]
+		if ma == nil {
+			continue
+		}
+		if ma.AssociateRouteTableID != "" {
+			if routing.AssociateRouteTableID != "" && routing.AssociateRouteTableID != ma.AssociateRouteTableID {
+				return nil, fmt.Errorf("Managed attachments %q and %q associate with different route tables on transit gateway %s", associatedBy, ma.Name, ma.TransitGatewayID)
+			}
+			routing.AssociateRouteTableID = ma.AssociateRouteTableID
+			associatedBy = ma.Name
+		}
+		for _, rtID := range ma.PropagateRouteTableIDs {
+			if !stringInSlice(rtID, routing.PropagateRouteTableIDs) {
+				routing.PropagateRouteTableIDs = append(routing.PropagateRouteTableIDs, rtID)
+			}
+		}
+	}
+	sort.Strings(routing.PropagateRouteTableIDs)
+	return routing, nil
+}
+
+// isManaged is true if vpc-conf has anything to do on the transit gateway
+// side of the attachment, either to set it up or to undo what it did before.
+func (routing *transitGatewayRouting) isManaged(tga *database.TransitGatewayAttachment) bool {
+	return routing.AssociateRouteTableID != "" || len(routing.PropagateRouteTableIDs) > 0 ||
+		tga.AssociatedRouteTableID != "" || len(tga.PropagatedRouteTableIDs) > 0
+}
+
+// updateTransitGatewayRouting associates and propagates the attachment as
+// wanted using credentials for the transit gateway owner account. It only
+// removes associations and propagations that vpc-conf made itself, so
+// anything set up by hand in the network account is left alone.
+func updateTransitGatewayRouting(ctx *awsp.Context, ownerEC2 ec2iface.EC2API, tga *database.TransitGatewayAttachment, want *transitGatewayRouting, vpcWriter database.VPCWriter, vpc *database.VPC) error {
+	associated, propagated, err := ctx.GetTransitGatewayAttachmentRouting(ownerEC2, tga.TransitGatewayAttachmentID)
+	if err != nil {
+		return err
+	}
+
+	if want.AssociateRouteTableID != "" {
+		if associated != want.AssociateRouteTableID {
+			err := ctx.AssociateTransitGatewayRouteTable(ownerEC2, tga.TransitGatewayAttachmentID, associated, want.AssociateRouteTableID)
+			if err != nil {
+				return err
+			}
+		}
+		tga.AssociatedRouteTableID = want.AssociateRouteTableID
+	} else if tga.AssociatedRouteTableID != "" {
+		if associated == tga.AssociatedRouteTableID {
+			err := ctx.DisassociateTransitGatewayRouteTable(ownerEC2, tga.TransitGatewayAttachmentID, associated)
+			if err != nil {
+				return err
+			}
+		}
+		tga.AssociatedRouteTableID = ""
+	}
+	err = vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		return fmt.Errorf("Error updating state: %s", err)
+	}
+
+	for _, rtID := range tga.PropagatedRouteTableIDs {
+		if !stringInSlice(rtID, want.PropagateRouteTableIDs) && stringInSlice(rtID, propagated) {
+			err := ctx.DisableTransitGatewayRouteTablePropagation(ownerEC2, tga.TransitGatewayAttachmentID, rtID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, rtID := range want.PropagateRouteTableIDs {
+		if !stringInSlice(rtID, propagated) {
+			err := ctx.EnableTransitGatewayRouteTablePropagation(ownerEC2, tga.TransitGatewayAttachmentID, rtID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	tga.PropagatedRouteTableIDs = want.PropagateRouteTableIDs
+	if len(tga.PropagatedRouteTableIDs) == 0 {
+		tga.PropagatedRouteTableIDs = nil
+	}
+	err = vpcWriter.UpdateState(vpc.State)
+	if err != nil {
+		return fmt.Errorf("Error updating state: %s", err)
+	}
+	return nil
+}
+
+// verifyTransitGatewayRouting returns a description of each way the
+// attachment's transit gateway route table setup differs from want.
+func verifyTransitGatewayRouting(ctx *awsp.Context, ownerEC2 ec2iface.EC2API, tga *database.TransitGatewayAttachment, want *transitGatewayRouting) ([]string, error) {
+	associated, propagated, err := ctx.GetTransitGatewayAttachmentRouting(ownerEC2, tga.TransitGatewayAttachmentID)
+	if err != nil {
+		return nil, err
+	}
+	problems := []string{}
+	if want.AssociateRouteTableID != "" && associated != want.AssociateRouteTableID {
+		if associated == "" {
+			problems = append(problems, fmt.Sprintf("Transit gateway attachment %s is not associated with route table %s", tga.TransitGatewayAttachmentID, want.AssociateRouteTableID))
+		} else {
+			problems = append(problems, fmt.Sprintf("Transit gateway attachment %s is associated with route table %s instead of %s", tga.TransitGatewayAttachmentID, associated, want.AssociateRouteTableID))
+		}
+	} else if want.AssociateRouteTableID == "" && tga.AssociatedRouteTableID != "" && associated == tga.AssociatedRouteTableID {
+		problems = append(problems, fmt.Sprintf("Transit gateway attachment %s is still associated with route table %s", tga.TransitGatewayAttachmentID, associated))
+	}
+	for _, rtID := range want.PropagateRouteTableIDs {
+		if !stringInSlice(rtID, propagated) {
+			problems = append(problems, fmt.Sprintf("Transit gateway attachment %s does not propagate to route table %s", tga.TransitGatewayAttachmentID, rtID))
+		}
+	}
+	for _, rtID := range tga.PropagatedRouteTableIDs {
+		if !stringInSlice(rtID, want.PropagateRouteTableIDs) && stringInSlice(rtID, propagated) {
+			problems = append(problems, fmt.Sprintf("Transit gateway attachment %s still propagates to route table %s", tga.TransitGatewayAttachmentID, rtID))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUpdateTransitGatewayRouting(t *testing.T) {
+	managed := map[uint64]*database.ManagedTransitGatewayAttachment{
+		1: {ID: 1, Name: "shared-services", TransitGatewayID: "tgw-1", AssociateRouteTableID: "tgw-rtb-dev", PropagateRouteTableIDs: []string{"tgw-rtb-shared"}},
+		2: {ID: 2, Name: "inspection", TransitGatewayID: "tgw-1", PropagateRouteTableIDs: []string{"tgw-rtb-inspection", "tgw-rtb-shared"}},
+		3: {ID: 3, Name: "plain", TransitGatewayID: "tgw-1"},
+		4: {ID: 4, Name: "prod", TransitGatewayID: "tgw-1", AssociateRouteTableID: "tgw-rtb-prod"},
+	}
+
+	testCases := []struct {
+		Name                 string
+		ManagedIDs           []uint64
+		StartAssociation     string
+		StartPropagations    []string
+		StartState           database.TransitGatewayAttachment
+		ExpectedAssociation  string
+		ExpectedPropagations []string
+		ExpectedState        database.TransitGatewayAttachment
+		ExpectedProblems     int
+		ExpectError          bool
+	}{
+		{
+			Name:                 "Associate and propagate new attachment",
+			ManagedIDs:           []uint64{1, 2},
+			ExpectedAssociation:  "tgw-rtb-dev",
+			ExpectedPropagations: []string{"tgw-rtb-inspection", "tgw-rtb-shared"},
+			ExpectedState: database.TransitGatewayAttachment{
+				AssociatedRouteTableID:  "tgw-rtb-dev",
+				PropagatedRouteTableIDs: []string{"tgw-rtb-inspection", "tgw-rtb-shared"},
+			},
+			ExpectedProblems: 3,
+		},
+		{
+			Name:                 "Replace default association",
+			ManagedIDs:           []uint64{4},
+			StartAssociation:     "tgw-rtb-default",
+			StartPropagations:    []string{"tgw-rtb-default"},
+			ExpectedAssociation:  "tgw-rtb-prod",
+			ExpectedPropagations: []string{"tgw-rtb-default"},
+			ExpectedState: database.TransitGatewayAttachment{
+				AssociatedRouteTableID: "tgw-rtb-prod",
+			},
+			ExpectedProblems: 1,
+		},
+		{
+			Name:                 "Undo only what vpc-conf did",
+			ManagedIDs:           []uint64{3},
+			StartAssociation:     "tgw-rtb-dev",
+			StartPropagations:    []string{"tgw-rtb-manual", "tgw-rtb-shared"},
+			StartState:           database.TransitGatewayAttachment{AssociatedRouteTableID: "tgw-rtb-dev", PropagatedRouteTableIDs: []string{"tgw-rtb-shared"}},
+			ExpectedPropagations: []string{"tgw-rtb-manual"},
+			ExpectedProblems:     2,
+		},
+		{
+			Name:        "Conflicting associations",
+			ManagedIDs:  []uint64{1, 4},
+			ExpectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			attachmentID := "tgw-attach-1"
+			ec2 := &testmocks.MockEC2{
+				TransitGatewayRouteTableAssociations: map[string]string{},
+				TransitGatewayRouteTablePropagations: map[string][]string{attachmentID: tc.StartPropagations},
+			}
+			if tc.StartAssociation != "" {
+				ec2.TransitGatewayRouteTableAssociations[attachmentID] = tc.StartAssociation
+			}
+			tga := tc.StartState
+			tga.TransitGatewayID = "tgw-1"
+			tga.TransitGatewayAttachmentID = attachmentID
+			vpc := &database.VPC{
+				ID:     "vpc-1",
+				Region: "us-east-1",
+				State:  &database.VPCState{TransitGatewayAttachments: []*database.TransitGatewayAttachment{&tga}},
+			}
+			mm := &testmocks.MockModelsManager{VPCs: map[string]*database.VPC{}}
+			vpcWriter := &testmocks.MockVPCWriter{MM: mm, Region: vpc.Region, VPCID: vpc.ID}
+			ctx := &awsp.Context{Logger: &testLogger{}}
+
+			routing, err := desiredTransitGatewayRouting(tc.ManagedIDs, managed)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatalf("Expected an error but got %+v", routing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			problems, err := verifyTransitGatewayRouting(ctx, ec2, &tga, routing)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if len(problems) != tc.ExpectedProblems {
+				t.Errorf("Expected %d problems before update but got %v", tc.ExpectedProblems, problems)
+			}
+
+			err = updateTransitGatewayRouting(ctx, ec2, &tga, routing, vpcWriter, vpc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if got := ec2.TransitGatewayRouteTableAssociations[attachmentID]; got != tc.ExpectedAssociation {
+				t.Errorf("Expected association %q but got %q", tc.ExpectedAssociation, got)
+			}
+			_, propagated, err := ctx.GetTransitGatewayAttachmentRouting(ec2, attachmentID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(tc.ExpectedPropagations, propagated, cmp.Transformer("nilToEmpty", func(s []string) []string {
+				if s == nil {
+					return []string{}
+				}
+				return s
+			})); diff != "" {
+				t.Errorf("Expected propagations did not match actual: \n%s", diff)
+			}
+			if tga.AssociatedRouteTableID != tc.ExpectedState.AssociatedRouteTableID {
+				t.Errorf("Expected associated route table %q in state but got %q", tc.ExpectedState.AssociatedRouteTableID, tga.AssociatedRouteTableID)
+			}
+			if diff := cmp.Diff(tc.ExpectedState.PropagatedRouteTableIDs, tga.PropagatedRouteTableIDs); diff != "" {
+				t.Errorf("Expected propagations in state did not match actual: \n%s", diff)
+			}
+
+			problems, err = verifyTransitGatewayRouting(ctx, ec2, &tga, routing)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if len(problems) != 0 {
+				t.Errorf("Expected no problems after update but got %v", problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeTicketing struct {
+	posted   map[string][]string // issue key -> comment bodies
+	issues   map[string]*ticketing.Issue
+	searched [][]string
+	statuses map[string]database.VPCRequestStatus // issue key -> status set by vpc-conf
+}
+
+func (p *fakeTicketing) Name() string                      { return "jira" }
+func (p *fakeTicketing) Owns(issueKey string) bool         { return strings.HasPrefix(issueKey, "VPCAPP-") }
+func (p *fakeTicketing) IssueKeyFromURL(url string) string { return "" }
+func (p *fakeTicketing) IssueURL(issueKey string) string   { return issueKey }
+func (p *fakeTicketing) ServiceAccount() string            { return "vpc-conf-bot" }
+func (p *fakeTicketing) CreateIssue(details *ticketing.IssueDetails) (string, error) {
+	return "", fmt.Errorf("Not implemented")
+}
+func (p *fakeTicketing) SearchIssues(keys []string) ([]*ticketing.Issue, error) {
+	p.searched = append(p.searched, keys)
+	issues := []*ticketing.Issue{}
+	for _, key := range keys {
+		if issue := p.issues[key]; issue != nil {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+func (p *fakeTicketing) AddComment(issueKey, comment string) (string, error) {
+	if p.posted == nil {
+		p.posted = map[string][]string{}
+	}
+	p.posted[issueKey] = append(p.posted[issueKey], comment)
+	return fmt.Sprintf("c%d", len(p.posted[issueKey])), nil
+}
+func (p *fakeTicketing) PostComment(issueKey, comment string) error {
+	_, err := p.AddComment(issueKey, comment)
+	return err
+}
+func (p *fakeTicketing) SetIssueStatus(issueKey string, status database.VPCRequestStatus) error {
+	if p.statuses == nil {
+		p.statuses = map[string]database.VPCRequestStatus{}
+	}
+	p.statuses[issueKey] = status
+	return nil
+}
+
+func TestVPCRequestCommentSync(t *testing.T) {
+	provider := &fakeTicketing{}
+	router, err := ticketing.NewRouter(ticketing.Routes{Default: "jira"}, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	synced := "c1"
+	mm := &testmocks.MockModelsManager{
+		VPCRequestComments: []*database.VPCRequestComment{
+			{ID: 1, VPCRequestID: 1, Author: "alice", Body: "Already posted", JIRACommentID: &synced},
+			{ID: 2, VPCRequestID: 1, Author: "alice", Body: "Please hurry"},
+			{ID: 3, VPCRequestID: 2, Author: "bob", Body: "No issue yet"},
+		},
+	}
+	s := &Server{ModelsManager: mm, Ticketing: router}
+	issueKey := "VPCAPP-1"
+	req := &database.VPCRequest{ID: 1, JIRAIssue: &issueKey, Status: database.StatusSubmitted}
+	reqsByID := map[uint64]*database.VPCRequest{1: req}
+
+	// Each local comment is posted once, on the first pass
+	s.postUnsyncedVPCRequestComments(reqsByID)
+	s.postUnsyncedVPCRequestComments(reqsByID)
+	expectedPosted := map[string][]string{
+		issueKey: {"alice commented in vpc-conf:\n\nPlease hurry"},
+	}
+	if diff := cmp.Diff(expectedPosted, provider.posted); diff != "" {
+		t.Errorf("Expected posted comments did not match actual: \n%s", diff)
+	}
+	if id := mm.VPCRequestComments[1].JIRACommentID; id == nil || *id != "c1" {
+		t.Errorf("Expected the comment's JIRA ID to be recorded but got %v", id)
+	}
+
+	// Comments made in JIRA are copied, skipping ones already known and ones vpc-conf posted
+	err = s.syncVPCRequestFromIssue(provider, req, &ticketing.Issue{
+		Key:    issueKey,
+		Status: database.StatusSubmitted,
+		Comments: []*ticketing.Comment{
+			{ID: "c1", Author: "vpc-conf-bot", Body: "alice commented in vpc-conf:\n\nPlease hurry"},
+			{ID: "c2", Author: "vpc-conf-bot", Body: "Task 5 succeeded"},
+			{ID: "c3", Author: "carol", Body: "Approved"},
+		},
+	}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	comments, _ := mm.GetVPCRequestComments(1)
+	bodies := []string{}
+	for _, comment := range comments {
+		bodies = append(bodies, comment.Author+": "+comment.Body)
+	}
+	expectedBodies := []string{"alice: Already posted", "alice: Please hurry", "carol: Approved"}
+	if diff := cmp.Diff(expectedBodies, bodies); diff != "" {
+		t.Errorf("Expected comments did not match actual: \n%s", diff)
+	}
+	if !comments[2].FromJIRA {
+		t.Errorf("Expected the copied comment to be marked as from JIRA")
+	}
+}
+
+func TestVPCRequestSyncSkipsSettledRequests(t *testing.T) {
+	openIssue := &ticketing.Issue{
+		Key:      "VPCAPP-1",
+		Status:   database.StatusApproved,
+		Updated:  time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		Comments: []*ticketing.Comment{{ID: "c1", Author: "carol", Body: "Looking"}},
+	}
+	provider := &fakeTicketing{
+		issues: map[string]*ticketing.Issue{
+			"VPCAPP-1": openIssue,
+			"VPCAPP-2": {Key: "VPCAPP-2", Status: database.StatusRejected},
+			"VPCAPP-3": {Key: "VPCAPP-3", Status: database.StatusApproved},
+		},
+	}
+	router, err := ticketing.NewRouter(ticketing.Routes{Default: "jira"}, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issueKeys := []string{"VPCAPP-1", "VPCAPP-2", "VPCAPP-3"}
+	taskID := uint64(5)
+	taskStatus := int(database.TaskStatusSuccessful)
+	notified := database.TaskStatusSuccessful
+	mm := &testmocks.MockModelsManager{
+		VPCRequests: []*database.VPCRequest{
+			{ID: 1, JIRAIssue: &issueKeys[0], Status: database.StatusApproved},
+			{ID: 2, JIRAIssue: &issueKeys[1], Status: database.StatusRejected},
+			{ID: 3, JIRAIssue: &issueKeys[2], Status: database.StatusApproved, TaskID: &taskID, TaskStatus: &taskStatus, TaskStatusNotified: &notified},
+		},
+	}
+	s := &Server{ModelsManager: mm, Ticketing: router}
+	issuesUpdated := map[string]time.Time{}
+	commentBodies := func() []string {
+		comments, _ := mm.GetVPCRequestComments(1)
+		bodies := []string{}
+		for _, comment := range comments {
+			bodies = append(bodies, comment.Body)
+		}
+		return bodies
+	}
+
+	s.syncVPCRequests(issuesUpdated)
+	if diff := cmp.Diff([][]string{{"VPCAPP-1"}}, provider.searched); diff != "" {
+		t.Errorf("Expected only the unsettled request's issue to be searched: \n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Looking"}, commentBodies()); diff != "" {
+		t.Errorf("Expected comments did not match actual: \n%s", diff)
+	}
+
+	// Comments aren't compared again until the issue's update time changes
+	openIssue.Comments = append(openIssue.Comments, &ticketing.Comment{ID: "c2", Author: "carol", Body: "Approved"})
+	s.syncVPCRequests(issuesUpdated)
+	if diff := cmp.Diff([]string{"Looking"}, commentBodies()); diff != "" {
+		t.Errorf("Expected comments did not match actual: \n%s", diff)
+	}
+	openIssue.Updated = openIssue.Updated.Add(time.Minute)
+	s.syncVPCRequests(issuesUpdated)
+	if diff := cmp.Diff([]string{"Looking", "Approved"}, commentBodies()); diff != "" {
+		t.Errorf("Expected comments did not match actual: \n%s", diff)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/selector"
+)
+
+// invalidSelectorError is a selector problem the requester can fix
+type invalidSelectorError struct {
+	error
+}
+
+// selectorTargets returns what selectors are evaluated against for every
+// automated VPC
+func selectorTargets(modelsManager database.ModelsManager) ([]*selector.Target, []*database.VPCSelectorInfo, error) {
+	infos, err := modelsManager.ListVPCSelectorInfo()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error listing VPCs: %s", err)
+	}
+	mtgas, err := modelsManager.GetManagedTransitGatewayAttachments()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error getting managed transit gateway attachments: %s", err)
+	}
+	mtgaNames := make(map[uint64]string)
+	for _, mtga := range mtgas {
+		mtgaNames[mtga.ID] = mtga.Name
+	}
+
+	targets := []*selector.Target{}
+	for _, info := range infos {
+		target := &selector.Target{
+			ID:          info.ID,
+			Name:        info.Name,
+			Stack:       info.Stack,
+			Region:      string(info.Region),
+			AccountID:   info.AccountID,
+			AccountName: info.AccountName,
+			ProjectName: info.ProjectName,
+			Type:        selector.TypeName(info.VPCType),
+			Labels:      info.Labels,
+		}
+		for _, id := range info.ManagedTransitGatewayAttachmentIDs {
+			if name, ok := mtgaNames[id]; ok {
+				target.MTGAs = append(target.MTGAs, name)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets, infos, nil
+}
+
+// resolveVPCSelector evaluates either the saved selector with the given name
+// or the given expression against the automated VPCs as they are now
+func resolveVPCSelector(modelsManager database.ModelsManager, name, expression string) (*database.BatchTaskSelector, error) {
+	if (name == "") == (expression == "") {
+		return nil, &invalidSelectorError{fmt.Errorf("Exactly one of a selector name or expression must be given")}
+	}
+	if name != "" {
+		saved, err := modelsManager.GetVPCSelector(name)
+		if err == sql.ErrNoRows {
+			return nil, &invalidSelectorError{fmt.Errorf("No selector named %q", name)}
+		} else if err != nil {
+			return nil, fmt.Errorf("Error getting selector %q: %s", name, err)
+		}
+		expression = saved.Expression
+	}
+	expr, err := selector.Parse(expression)
+	if err != nil {
+		return nil, &invalidSelectorError{fmt.Errorf("Invalid selector: %s", err)}
+	}
+
+	targets, infos, err := selectorTargets(modelsManager)
+	if err != nil {
+		return nil, err
+	}
+	resolved := &database.BatchTaskSelector{
+		Name:       name,
+		Expression: expression,
+		VPCs:       []*database.BatchTaskSelectedVPC{},
+	}
+	for idx, target := range targets {
+		if expr.Matches(target) {
+			info := infos[idx]
+			resolved.VPCs = append(resolved.VPCs, &database.BatchTaskSelectedVPC{
+				ID:        info.ID,
+				Name:      info.Name,
+				AccountID: info.AccountID,
+				Region:    info.Region,
+			})
+		}
+	}
+	return resolved, nil
+}
+
+var handleVPCSelectorList = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleVPCSelectorList but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	selectors, err := s.ModelsManager.GetVPCSelectors()
+	if err != nil {
+		log.Printf("Error getting VPC selectors: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(selectors)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleSaveVPCSelector = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleSaveVPCSelector but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	saved := new(database.VPCSelector)
+	err := json.NewDecoder(r.Body).Decode(saved)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error parsing request: %s", err), http.StatusBadRequest)
+		return
+	}
+	saved.Name = strings.TrimSpace(saved.Name)
+	if saved.Name == "" || strings.Contains(saved.Name, "/") {
+		http.Error(w, "Name is required and cannot contain /", http.StatusBadRequest)
+		return
+	}
+	_, err = selector.Parse(saved.Expression)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid selector: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	err = s.ModelsManager.CreateOrUpdateVPCSelector(saved)
+	if err != nil {
+		log.Printf("Error saving VPC selector %q: %s", saved.Name, err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]uint64{
+		"ID": saved.ID,
+	}
+	buf, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+var handleDeleteVPCSelector = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 1 {
+		log.Printf("Expected 1 additional arg to handleDeleteVPCSelector but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	err := s.ModelsManager.DeleteVPCSelector(args[0])
+	if err != nil {
+		log.Printf("Error deleting VPC selector %q: %s", args[0], err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleSelectVPCs previews the VPCs a selector matches, given as either
+// ?name= for a saved selector or ?selector= for an expression
+var handleSelectVPCs = func(s *Server, w http.ResponseWriter, r *http.Request, args ...string) {
+	if len(args) != 0 {
+		log.Printf("Expected 0 additional args to handleSelectVPCs but got %d", len(args))
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	resolved, err := resolveVPCSelector(s.ModelsManager, r.URL.Query().Get("name"), r.URL.Query().Get("selector"))
+	if _, ok := err.(*invalidSelectorError); ok {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Printf("Error resolving VPC selector: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	buf, err := json.Marshal(resolved)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, "%s", buf)
+}
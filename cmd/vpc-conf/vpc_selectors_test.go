@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveVPCSelector(t *testing.T) {
+	mm := &testmocks.MockModelsManager{
+		ManagedTransitGatewayAttachments: []*database.ManagedTransitGatewayAttachment{
+			{ID: 1, Name: "Shared Services"},
+			{ID: 2, Name: "Other"},
+		},
+		VPCSelectorInfos: []*database.VPCSelectorInfo{
+			{ID: "vpc-1", Name: "app-prod", Stack: "prod", Region: "us-east-1", VPCType: database.VPCTypeV1, AccountID: "111", Labels: []string{"pci"}, ManagedTransitGatewayAttachmentIDs: []uint64{1}},
+			{ID: "vpc-2", Name: "fw-prod", Stack: "prod", Region: "us-east-1", VPCType: database.VPCTypeV1Firewall, AccountID: "222", Labels: []string{"pci"}, ManagedTransitGatewayAttachmentIDs: []uint64{1, 2}},
+			{ID: "vpc-3", Name: "app-dev", Stack: "dev", Region: "us-west-2", VPCType: database.VPCTypeLegacy, AccountID: "111", ManagedTransitGatewayAttachmentIDs: []uint64{2}},
+		},
+		VPCSelectors: []*database.VPCSelector{
+			{ID: 1, Name: "pci-v1", Expression: `label:pci AND NOT type=V1Firewall`},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		selectorName  string
+		expression    string
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:       "Expression",
+			expression: `stack=prod AND mtga:"Shared Services" AND mtga:Other`,
+			expected:   []string{"vpc-2"},
+		},
+		{
+			name:         "Saved selector",
+			selectorName: "pci-v1",
+			expected:     []string{"vpc-1"},
+		},
+		{
+			name:       "No matches",
+			expression: `region=us-gov-west-1`,
+			expected:   []string{},
+		},
+		{
+			name:          "Unknown saved selector",
+			selectorName:  "missing",
+			expectedError: `No selector named "missing"`,
+		},
+		{
+			name:          "Invalid expression",
+			expression:    `stack=`,
+			expectedError: `Invalid selector: Expected a value for stack but got end of selector`,
+		},
+		{
+			name:          "Both",
+			selectorName:  "pci-v1",
+			expression:    `stack=prod`,
+			expectedError: `Exactly one of a selector name or expression must be given`,
+		},
+	}
+	for _, tc := range testCases {
+		resolved, err := resolveVPCSelector(mm, tc.selectorName, tc.expression)
+		if tc.expectedError != "" {
+			if _, ok := err.(*invalidSelectorError); !ok || err.Error() != tc.expectedError {
+				t.Errorf("%s: expected invalid selector error %q but got %v", tc.name, tc.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		ids := []string{}
+		for _, vpc := range resolved.VPCs {
+			ids = append(ids, vpc.ID)
+		}
+		if diff := cmp.Diff(tc.expected, ids); diff != "" {
+			t.Errorf("%s: expected VPCs did not match actual: \n%s", tc.name, diff)
+		}
+		if resolved.Name != tc.selectorName {
+			t.Errorf("%s: expected selector name %q but got %q", tc.name, tc.selectorName, resolved.Name)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+type vpnTunnelStatus struct {
+	OutsideIPAddress   string
+	Status             string
+	StatusMessage      string
+	LastStatusChange   *time.Time
+	AcceptedRouteCount int64
+}
+
+// vpnConnectionStatus is what AWS currently reports for a VPN connection.
+type vpnConnectionStatus struct {
+	ID              uint64
+	Name            string
+	VPNConnectionID string
+	State           string
+	Tunnels         []*vpnTunnelStatus
+}
+
+// getVPNConnectionStatus needs a context for the transit gateway owner
+// account. State is "deleted" if the connection no longer exists and ""
+// if it was never created.
+func getVPNConnectionStatus(ctx *awsp.Context, vpn *database.VPNConnection) (*vpnConnectionStatus, error) {
+	status := &vpnConnectionStatus{
+		ID:      vpn.ID,
+		Name:    vpn.Name,
+		Tunnels: []*vpnTunnelStatus{},
+	}
+	if vpn.State == nil || vpn.State.VPNConnectionID == "" {
+		return status, nil
+	}
+	status.VPNConnectionID = vpn.State.VPNConnectionID
+	current, err := ctx.GetVPNConnection(vpn.State.VPNConnectionID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		status.State = ec2.VpnStateDeleted
+		return status, nil
+	}
+	status.State = aws.StringValue(current.State)
+	for _, telemetry := range current.VgwTelemetry {
+		status.Tunnels = append(status.Tunnels, &vpnTunnelStatus{
+			OutsideIPAddress:   aws.StringValue(telemetry.OutsideIpAddress),
+			Status:             aws.StringValue(telemetry.Status),
+			StatusMessage:      aws.StringValue(telemetry.StatusMessage),
+			LastStatusChange:   telemetry.LastStatusChange,
+			AcceptedRouteCount: aws.Int64Value(telemetry.AcceptedRouteCount),
+		})
+	}
+	return status, nil
+}
+
+// verifyVPNConnection returns a description of each problem with the VPN
+// connection. None of them can be fixed from the VPCs that use it.
+func verifyVPNConnection(ctx *awsp.Context, vpn *database.VPNConnection) ([]string, error) {
+	status, err := getVPNConnectionStatus(ctx, vpn)
+	if err != nil {
+		return nil, err
+	}
+	if status.State == "" {
+		return []string{fmt.Sprintf("VPN connection %q has not been created yet", vpn.Name)}, nil
+	}
+	if status.State == ec2.VpnStateDeleted || status.State == ec2.VpnStateDeleting {
+		return []string{fmt.Sprintf("VPN connection %q (%s) no longer exists", vpn.Name, status.VPNConnectionID)}, nil
+	}
+	problems := []string{}
+	if status.State != ec2.VpnStateAvailable {
+		problems = append(problems, fmt.Sprintf("VPN connection %q (%s) is %s", vpn.Name, status.VPNConnectionID, status.State))
+	}
+	if vpn.State.CustomerGateway != vpn.CustomerGateway {
+		problems = append(problems, fmt.Sprintf("VPN connection %q has customer gateway changes that have not been applied", vpn.Name))
+	}
+	for _, tunnel := range status.Tunnels {
+		if tunnel.Status != ec2.TelemetryStatusUp {
+			problems = append(problems, fmt.Sprintf("Tunnel %s of VPN connection %q is down: %s", tunnel.OutsideIPAddress, vpn.Name, tunnel.StatusMessage))
+		}
+	}
+	return problems, nil
+}
+
+// getVPNConnectionsForVPC loads the VPN connections a VPC routes to. Each
+// one's transit gateway must be one the VPC is attached to, since that is
+// where the routes point.
+func getVPNConnectionsForVPC(mm database.ModelsManager, vpc *database.VPC, vpnConnectionIDs []uint64, managedAttachmentIDs []uint64, managedAttachmentsByID map[uint64]*database.ManagedTransitGatewayAttachment) ([]*database.VPNConnection, error) {
+	vpns := []*database.VPNConnection{}
+	for _, id := range vpnConnectionIDs {
+		vpn, err := mm.GetVPNConnection(id)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting VPN connection %d: %s", id, err)
+		}
+		if vpn.Region != vpc.Region {
+			return nil, fmt.Errorf("VPN connection %q is in %s, not %s", vpn.Name, vpn.Region, vpc.Region)
+		}
+		attached := false
+		for _, managedID := range managedAttachmentIDs {
+			if ma := managedAttachmentsByID[managedID]; ma != nil && ma.TransitGatewayID == vpn.TransitGatewayID {
+				attached = true
+				break
+			}
+		}
+		if !attached {
+			return nil, fmt.Errorf("VPN connection %q is on transit gateway %s, which VPC %s is not attached to", vpn.Name, vpn.TransitGatewayID, vpc.ID)
+		}
+		vpns = append(vpns, vpn)
+	}
+	return vpns, nil
+}
+
+// updateVPNConnection creates or updates the customer gateway, VPN
+// connection and transit gateway routing for vpn. ctx must be for the
+// account that owns the transit gateway.
+func updateVPNConnection(ctx *awsp.Context, mm database.ModelsManager, vpn *database.VPNConnection) error {
+	state := vpn.State
+	if state == nil {
+		state = &database.VPNConnectionState{}
+	}
+	saveState := func() error {
+		vpn.State = state
+		err := mm.UpdateVPNConnectionState(vpn.ID, state)
+		if err != nil {
+			return fmt.Errorf("Error updating state: %s", err)
+		}
+		return nil
+	}
+
+	var current *ec2.VpnConnection
+	if state.VPNConnectionID != "" {
+		var err error
+		current, err = ctx.GetVPNConnection(state.VPNConnectionID)
+		if err != nil {
+			return err
+		}
+		if current == nil || aws.StringValue(current.State) == ec2.VpnStateDeleted || aws.StringValue(current.State) == ec2.VpnStateDeleting {
+			ctx.Log("VPN connection %s no longer exists; creating a new one", state.VPNConnectionID)
+			state.VPNConnectionID = ""
+			state.TransitGatewayAttachmentID = ""
+			state.AssociatedRouteTableID = ""
+			state.PropagatedRouteTableID = ""
+			state.StaticRoutes = nil
+			err := saveState()
+			if err != nil {
+				return err
+			}
+			current = nil
+		} else if current.Options != nil && aws.BoolValue(current.Options.StaticRoutesOnly) != vpn.StaticRoutesOnly {
+			return fmt.Errorf("VPN connection %s can't be switched between static and dynamic routing; delete it and create a new one", state.VPNConnectionID)
+		}
+	}
+
+	// Customer gateway. Changing its IP address or ASN needs a new one.
+	if state.CustomerGatewayID == "" || state.CustomerGateway != vpn.CustomerGateway {
+		newID, err := ctx.CreateCustomerGateway(&vpn.CustomerGateway, vpn.Name)
+		if err != nil {
+			return err
+		}
+		if current != nil {
+			err := ctx.ReplaceVPNConnectionCustomerGateway(state.VPNConnectionID, newID)
+			if err != nil {
+				return err
+			}
+		}
+		oldID := state.CustomerGatewayID
+		state.CustomerGatewayID = newID
+		state.CustomerGateway = vpn.CustomerGateway
+		err = saveState()
+		if err != nil {
+			return err
+		}
+		if oldID != "" {
+			err := ctx.DeleteCustomerGateway(oldID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// VPN connection
+	if current == nil {
+		created, err := ctx.CreateVPNConnection(state.CustomerGatewayID, vpn.TransitGatewayID, vpn.StaticRoutesOnly, vpn.TunnelOptions, vpn.Name)
+		if created != nil {
+			state.VPNConnectionID = aws.StringValue(created.VpnConnectionId)
+			saveErr := saveState()
+			if saveErr != nil {
+				return saveErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+	} else if current.Options != nil {
+		for idx, opts := range vpn.TunnelOptions {
+			if idx >= len(current.Options.TunnelOptions) {
+				break
+			}
+			tunnel := current.Options.TunnelOptions[idx]
+			if !awsp.VPNTunnelOptionsMatch(opts, tunnel) {
+				err := ctx.ModifyVPNTunnelOptions(state.VPNConnectionID, aws.StringValue(tunnel.OutsideIpAddress), opts)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if state.TransitGatewayAttachmentID == "" {
+		attachmentID, err := ctx.GetVPNTransitGatewayAttachmentID(state.VPNConnectionID)
+		if err != nil {
+			return err
+		}
+		state.TransitGatewayAttachmentID = attachmentID
+		err = saveState()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Transit gateway routing. Only undo what was done here before so that
+	// routing set up by hand in the network account is left alone.
+	attachmentID := state.TransitGatewayAttachmentID
+	associated, propagated, err := ctx.GetTransitGatewayAttachmentRouting(ctx.EC2(), attachmentID)
+	if err != nil {
+		return err
+	}
+	wantRouteTableID := vpn.TransitGatewayRouteTableID
+	wantStaticRoutes := []string{}
+	if wantRouteTableID != "" && vpn.StaticRoutesOnly {
+		wantStaticRoutes = vpn.Routes
+	}
+
+	keptRoutes := []string{}
+	for _, cidr := range state.StaticRoutes {
+		if state.AssociatedRouteTableID == wantRouteTableID && stringInSlice(cidr, wantStaticRoutes) {
+			keptRoutes = append(keptRoutes, cidr)
+			continue
+		}
+		err := ctx.DeleteTransitGatewayRoute(state.AssociatedRouteTableID, cidr)
+		if err != nil {
+			return err
+		}
+	}
+	state.StaticRoutes = keptRoutes
+	if state.PropagatedRouteTableID != "" && (state.PropagatedRouteTableID != wantRouteTableID || vpn.StaticRoutesOnly) {
+		if stringInSlice(state.PropagatedRouteTableID, propagated) {
+			err := ctx.DisableTransitGatewayRouteTablePropagation(ctx.EC2(), attachmentID, state.PropagatedRouteTableID)
+			if err != nil {
+				return err
+			}
+		}
+		state.PropagatedRouteTableID = ""
+	}
+	err = saveState()
+	if err != nil {
+		return err
+	}
+
+	if wantRouteTableID == "" {
+		if state.AssociatedRouteTableID != "" && associated == state.AssociatedRouteTableID {
+			err := ctx.DisassociateTransitGatewayRouteTable(ctx.EC2(), attachmentID, associated)
+			if err != nil {
+				return err
+			}
+		}
+		state.AssociatedRouteTableID = ""
+		return saveState()
+	}
+	if associated != wantRouteTableID {
+		err := ctx.AssociateTransitGatewayRouteTable(ctx.EC2(), attachmentID, associated, wantRouteTableID)
+		if err != nil {
+			return err
+		}
+	}
+	state.AssociatedRouteTableID = wantRouteTableID
+	err = saveState()
+	if err != nil {
+		return err
+	}
+	if vpn.StaticRoutesOnly {
+		for _, cidr := range wantStaticRoutes {
+			if stringInSlice(cidr, state.StaticRoutes) {
+				continue
+			}
+			err := ctx.CreateTransitGatewayRoute(wantRouteTableID, attachmentID, cidr)
+			if err != nil {
+				return err
+			}
+			state.StaticRoutes = append(state.StaticRoutes, cidr)
+			err = saveState()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !stringInSlice(wantRouteTableID, propagated) {
+		err := ctx.EnableTransitGatewayRouteTablePropagation(ctx.EC2(), attachmentID, wantRouteTableID)
+		if err != nil {
+			return err
+		}
+	}
+	state.PropagatedRouteTableID = wantRouteTableID
+	return saveState()
+}
+
+// deleteVPNConnection deletes vpn's AWS resources and then vpn itself.
+func deleteVPNConnection(ctx *awsp.Context, mm database.ModelsManager, vpn *database.VPNConnection) error {
+	if len(vpn.InUseVPCs) > 0 {
+		return fmt.Errorf("VPN connection %q is still in use by %v", vpn.Name, vpn.InUseVPCs)
+	}
+	state := vpn.State
+	if state != nil {
+		for _, cidr := range state.StaticRoutes {
+			err := ctx.DeleteTransitGatewayRoute(state.AssociatedRouteTableID, cidr)
+			if err != nil {
+				return err
+			}
+		}
+		state.StaticRoutes = nil
+		if state.VPNConnectionID != "" {
+			// The transit gateway attachment and its routing go with it
+			err := ctx.DeleteVPNConnection(state.VPNConnectionID)
+			if err != nil {
+				return err
+			}
+			state.VPNConnectionID = ""
+			state.TransitGatewayAttachmentID = ""
+			state.AssociatedRouteTableID = ""
+			state.PropagatedRouteTableID = ""
+			err = mm.UpdateVPNConnectionState(vpn.ID, state)
+			if err != nil {
+				return fmt.Errorf("Error updating state: %s", err)
+			}
+		}
+		if state.CustomerGatewayID != "" {
+			err := ctx.DeleteCustomerGateway(state.CustomerGatewayID)
+			if err != nil {
+				return err
+			}
+			state.CustomerGatewayID = ""
+			err = mm.UpdateVPNConnectionState(vpn.ID, state)
+			if err != nil {
+				return fmt.Errorf("Error updating state: %s", err)
+			}
+		}
+	}
+	return mm.DeleteVPNConnection(vpn.ID)
+}
+
+func (taskContext *TaskContext) performUpdateVPNConnectionTask(config *database.UpdateVPNConnectionTaskData) {
+	t := taskContext.Task
+	setStatus(t, database.TaskStatusInProgress)
+
+	vpn, err := taskContext.ModelsManager.GetVPNConnection(config.VPNConnectionID)
+	if err != nil {
+		t.Log("Error getting VPN connection: %s", err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	ctx := &awsp.Context{
+		AWSAccountAccess: taskContext.BaseAWSAccountAccess,
+		Logger:           t,
+	}
+
+	if config.Delete {
+		err = deleteVPNConnection(ctx, taskContext.ModelsManager, vpn)
+		if err != nil {
+			t.Log("Error deleting VPN connection %q: %s", vpn.Name, err)
+			setStatus(t, database.TaskStatusFailed)
+			return
+		}
+		t.Log("Deleted VPN connection %q", vpn.Name)
+		setStatus(t, database.TaskStatusSuccessful)
+		return
+	}
+
+	err = updateVPNConnection(ctx, taskContext.ModelsManager, vpn)
+	if err != nil {
+		t.Log("Error updating VPN connection %q: %s", vpn.Name, err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	problems, err := verifyVPNConnection(ctx, vpn)
+	if err != nil {
+		t.Log("Error verifying VPN connection %q: %s", vpn.Name, err)
+		setStatus(t, database.TaskStatusFailed)
+		return
+	}
+	for _, problem := range problems {
+		t.Log("%s", problem)
+	}
+	setStatus(t, database.TaskStatusSuccessful)
+}
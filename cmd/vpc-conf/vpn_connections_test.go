@@ -0,0 +1,153 @@
+package main
+
+import (
+	"testing"
+
+	awsp "github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/aws"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/testmocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUpdateVPNConnection(t *testing.T) {
+	speedUpTime()
+	vpn := &database.VPNConnection{
+		ID:               1,
+		Name:             "datacenter-east",
+		Region:           "us-east-1",
+		TransitGatewayID: "tgw-1",
+		CustomerGateway: database.CustomerGatewayConfig{
+			IPAddress: "198.51.100.10",
+			BGPASN:    65000,
+		},
+		StaticRoutesOnly: true,
+		TunnelOptions: []*database.VPNTunnelOptions{
+			{InsideCIDR: "169.254.10.0/30", IKEVersions: []string{"ikev2"}},
+		},
+		TransitGatewayRouteTableID: "tgw-rtb-onprem",
+		Routes:                     []string{"10.200.0.0/16", "10.201.0.0/16"},
+		SubnetTypes:                []database.SubnetType{database.SubnetTypePrivate},
+	}
+	mm := &testmocks.MockModelsManager{VPCs: map[string]*database.VPC{}, VPNConnections: []*database.VPNConnection{vpn}}
+	ec2svc := &testmocks.MockEC2{}
+	ctx := &awsp.Context{
+		AWSAccountAccess: &awsp.AWSAccountAccess{EC2svc: ec2svc},
+		Logger:           &testLogger{},
+		Clock:            testClock,
+	}
+	verify := func() []string {
+		problems, err := verifyVPNConnection(ctx, vpn)
+		if err != nil {
+			t.Fatalf("Unexpected error verifying: %s", err)
+		}
+		return problems
+	}
+
+	if problems := verify(); len(problems) != 1 {
+		t.Errorf("Expected a problem for the VPN connection not being created but got %v", problems)
+	}
+
+	// Create
+	err := updateVPNConnection(ctx, mm, vpn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	attachmentID := testmocks.TestVPNAttachmentID("vpn-1")
+	expectedState := &database.VPNConnectionState{
+		CustomerGatewayID:          "cgw-1",
+		CustomerGateway:            vpn.CustomerGateway,
+		VPNConnectionID:            "vpn-1",
+		TransitGatewayAttachmentID: attachmentID,
+		AssociatedRouteTableID:     "tgw-rtb-onprem",
+		StaticRoutes:               []string{"10.200.0.0/16", "10.201.0.0/16"},
+	}
+	if diff := cmp.Diff(expectedState, vpn.State); diff != "" {
+		t.Fatalf("Expected state did not match actual: \n%s", diff)
+	}
+	if got := ec2svc.TransitGatewayRouteTableAssociations[attachmentID]; got != "tgw-rtb-onprem" {
+		t.Errorf("Expected attachment to be associated with tgw-rtb-onprem but got %q", got)
+	}
+	if diff := cmp.Diff(vpn.Routes, ec2svc.TransitGatewayRoutes["tgw-rtb-onprem"]); diff != "" {
+		t.Errorf("Expected transit gateway routes did not match actual: \n%s", diff)
+	}
+	created := ec2svc.VPNConnections[0]
+	if !aws.BoolValue(created.Options.StaticRoutesOnly) || aws.StringValue(created.Options.TunnelOptions[0].TunnelInsideCidr) != "169.254.10.0/30" {
+		t.Errorf("Wrong options for created VPN connection: %+v", created.Options)
+	}
+	if tags := ec2svc.TagsCreated["vpn-1"]; !stringInSlice(testmocks.FormatTag("Name", "datacenter-east"), tags) {
+		t.Errorf("Expected Name tag but got %v", tags)
+	}
+	if problems := verify(); len(problems) != 0 {
+		t.Errorf("Expected no problems after creating but got %v", problems)
+	}
+
+	// Update is idempotent
+	err = updateVPNConnection(ctx, mm, vpn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(ec2svc.VPNConnections) != 1 || len(ec2svc.CustomerGateways) != 1 || len(ec2svc.VPNTunnelsModified) != 0 {
+		t.Fatalf("Expected nothing to change but got VPN connections %v, customer gateways %v, tunnels modified %v", ec2svc.VPNConnections, ec2svc.CustomerGateways, ec2svc.VPNTunnelsModified)
+	}
+
+	// Change the customer gateway, a tunnel option and the routes
+	vpn.CustomerGateway.IPAddress = "198.51.100.20"
+	vpn.TunnelOptions[0].DPDTimeoutAction = "restart"
+	vpn.Routes = []string{"10.200.0.0/16", "10.202.0.0/16"}
+	if problems := verify(); len(problems) != 1 {
+		t.Errorf("Expected a problem for unapplied customer gateway changes but got %v", problems)
+	}
+	err = updateVPNConnection(ctx, mm, vpn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if aws.StringValue(created.CustomerGatewayId) != "cgw-2" || vpn.State.CustomerGatewayID != "cgw-2" {
+		t.Errorf("Expected VPN connection to use cgw-2 but AWS has %s and state has %s", aws.StringValue(created.CustomerGatewayId), vpn.State.CustomerGatewayID)
+	}
+	if !cmp.Equal(ec2svc.CustomerGatewaysDeleted, []string{"cgw-1"}) {
+		t.Errorf("Expected cgw-1 to be deleted but got %v", ec2svc.CustomerGatewaysDeleted)
+	}
+	if len(ec2svc.VPNTunnelsModified) != 1 {
+		t.Errorf("Expected 1 tunnel to be modified but got %v", ec2svc.VPNTunnelsModified)
+	}
+	if diff := cmp.Diff(vpn.Routes, ec2svc.TransitGatewayRoutes["tgw-rtb-onprem"]); diff != "" {
+		t.Errorf("Expected transit gateway routes did not match actual: \n%s", diff)
+	}
+	if problems := verify(); len(problems) != 0 {
+		t.Errorf("Expected no problems after updating but got %v", problems)
+	}
+
+	// A tunnel goes down
+	created.VgwTelemetry[1].Status = aws.String(ec2.TelemetryStatusDown)
+	if problems := verify(); len(problems) != 1 {
+		t.Errorf("Expected a problem for the tunnel being down but got %v", problems)
+	}
+
+	// Can't delete while in use
+	vpn.InUseVPCs = []string{"vpc-abc"}
+	err = deleteVPNConnection(ctx, mm, vpn)
+	if err == nil {
+		t.Fatalf("Expected an error deleting a VPN connection that is in use")
+	}
+	vpn.InUseVPCs = nil
+
+	// Delete
+	err = deleteVPNConnection(ctx, mm, vpn)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if aws.StringValue(created.State) != ec2.VpnStateDeleted {
+		t.Errorf("Expected VPN connection to be deleted but it is %s", aws.StringValue(created.State))
+	}
+	if !cmp.Equal(ec2svc.CustomerGatewaysDeleted, []string{"cgw-1", "cgw-2"}) {
+		t.Errorf("Expected cgw-2 to be deleted but got %v", ec2svc.CustomerGatewaysDeleted)
+	}
+	if len(ec2svc.TransitGatewayRoutes["tgw-rtb-onprem"]) != 0 {
+		t.Errorf("Expected transit gateway routes to be deleted but got %v", ec2svc.TransitGatewayRoutes["tgw-rtb-onprem"])
+	}
+	if len(mm.VPNConnections) != 0 {
+		t.Errorf("Expected VPN connection to be removed from the database but got %v", mm.VPNConnections)
+	}
+}
@@ -0,0 +1,709 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/vpcconfapi"
+)
+
+const (
+	exitCodeSuccess = iota
+	exitCodeUsage
+	exitCodeInvalidEnvironment
+	exitCodeFatal
+)
+
+var baseURLs = map[string]string{
+	"dev":  "https://dev.vpc-conf.actually-east.west.cms.gov/provision",
+	"prod": "https://vpc-conf.actually-east.west.cms.gov/provision",
+}
+
+type VPCConfAPIKeys struct {
+	Dev  string `json:"dev"`
+	Prod string `json:"prod"`
+}
+
+type options struct {
+	api       *vpcconfapi.VPCConfAPI
+	ctx       context.Context
+	out       io.Writer
+	output    string
+	dryRun    bool
+	wait      bool
+	threads   int
+	threshold float64
+	cidrs     []string
+	addMTGAs  []uint64
+	rmMTGAs   []uint64
+	// verifySpec is what verify and repair batches check
+	verifySpec database.VerifySpec
+	// selector or selectorName picked the VPCs on the server
+	selector     string
+	selectorName string
+}
+
+type command struct {
+	usage     string
+	needsVPCs bool
+	run       func(opts *options, vpcs []vpcconfapi.VPC, args []string) error
+}
+
+// List of available commands
+var commands = map[string]*command{
+	"vpcs": {
+		usage:     "list the selected VPCs",
+		needsVPCs: true,
+		run:       listVPCs,
+	},
+	"routes": {
+		usage:     "show the route tables of the selected VPCs as they are in AWS",
+		needsVPCs: true,
+		run:       showRoutes,
+	},
+	"audit": {
+		usage:     "audit supernets|capacity - check the selected VPCs' route tables",
+		needsVPCs: true,
+		run:       audit,
+	},
+	"snapshot": {
+		usage:     "back up the route tables of the selected VPCs on the server",
+		needsVPCs: true,
+		run:       snapshotRoutes,
+	},
+	"batch": {
+		usage:     "batch <task type>[,<task type>...] - queue a batch task on the selected VPCs",
+		needsVPCs: true,
+		run:       submitBatch,
+	},
+	"tail": {
+		usage: "tail <task ID> - follow a task's log until it finishes",
+		run:   tailTask,
+	},
+	"selectors": {
+		usage: "selectors [save <name> <selector> [description]|delete <name>] - list or change saved selectors",
+		run:   selectors,
+	},
+}
+
+type vpcSelector struct {
+	vpc, account, region, stack string
+}
+
+// selectVPCs applies every selector that was given. -vpc matches either the
+// VPC ID or its name.
+func selectVPCs(vpcs []vpcconfapi.VPC, sel *vpcSelector) []vpcconfapi.VPC {
+	selected := []vpcconfapi.VPC{}
+	for _, vpc := range vpcs {
+		if sel.vpc != "" && sel.vpc != vpc.ID && sel.vpc != vpc.Name {
+			continue
+		}
+		if sel.account != "" && sel.account != vpc.AccountID {
+			continue
+		}
+		if sel.region != "" && sel.region != string(vpc.Region) {
+			continue
+		}
+		if sel.stack != "" && !strings.EqualFold(sel.stack, vpc.Stack) {
+			continue
+		}
+		selected = append(selected, vpc)
+	}
+	return selected
+}
+
+// resolvedVPCs returns the VPCs a selector matched on the server, in the
+// order they are listed in
+func resolvedVPCs(vpcs []vpcconfapi.VPC, resolved *database.BatchTaskSelector) []vpcconfapi.VPC {
+	matched := make(map[string]bool)
+	for _, vpc := range resolved.VPCs {
+		matched[string(vpc.Region)+"/"+vpc.ID] = true
+	}
+	selected := []vpcconfapi.VPC{}
+	for _, vpc := range vpcs {
+		if matched[string(vpc.Region)+"/"+vpc.ID] {
+			selected = append(selected, vpc)
+		}
+	}
+	return selected
+}
+
+func parseIDs(s string) ([]uint64, error) {
+	ids := []uint64{}
+	if s == "" {
+		return ids, nil
+	}
+	for _, idString := range strings.Split(s, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(idString), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ID %q", idString)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// table is the -o table form of a command's output
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// write prints v as JSON, or t as aligned columns
+func (opts *options) write(v interface{}, t *table) error {
+	if opts.output == "json" {
+		buf, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(opts.out, "%s\n", buf)
+		return err
+	}
+	w := tabwriter.NewWriter(opts.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// forEachVPC runs fn on up to opts.threads VPCs at a time. Failures are
+// reported and counted rather than stopping the other VPCs.
+func (opts *options) forEachVPC(vpcs []vpcconfapi.VPC, fn func(i int, vpc vpcconfapi.VPC) error) error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	failed := 0
+	sem := make(chan struct{}, opts.threads)
+	for i, vpc := range vpcs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, vpc vpcconfapi.VPC) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := fn(i, vpc)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", vpc, err)
+				lock.Lock()
+				failed++
+				lock.Unlock()
+			}
+		}(i, vpc)
+	}
+	wg.Wait()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d VPCs failed", failed, len(vpcs))
+	}
+	return nil
+}
+
+func listVPCs(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	t := &table{headers: []string{"VPC ID", "NAME", "ACCOUNT", "REGION", "STACK"}}
+	for _, vpc := range vpcs {
+		t.add(vpc.ID, vpc.Name, vpc.AccountID, string(vpc.Region), vpc.Stack)
+	}
+	return opts.write(vpcs, t)
+}
+
+type routeRow struct {
+	VPCID        string
+	AccountID    string
+	Region       database.Region
+	RouteTableID string
+	Destination  string
+	Target       string
+	Managed      bool
+}
+
+func routeRows(vpc vpcconfapi.VPC, routeTableID string, routes []*database.SnapshotRoute) []*routeRow {
+	rows := []*routeRow{}
+	for _, r := range routes {
+		rows = append(rows, &routeRow{
+			VPCID:        vpc.ID,
+			AccountID:    vpc.AccountID,
+			Region:       vpc.Region,
+			RouteTableID: routeTableID,
+			Destination:  r.Destination,
+			Target:       vpcconfapi.RouteTarget(r),
+			Managed:      r.Managed,
+		})
+	}
+	return rows
+}
+
+func sortedRouteTableIDs(routeTables map[string]*database.RouteTableSnapshotEntry) []string {
+	ids := []string{}
+	for id := range routeTables {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// collectRoutes fetches each VPC's route tables and turns them into rows
+// with routesFor, keeping the rows in VPC order.
+func (opts *options) collectRoutes(vpcs []vpcconfapi.VPC, routesFor func(*database.RouteTableSnapshotEntry) ([]*database.SnapshotRoute, error)) ([]*routeRow, error) {
+	rowsByVPC := make([][]*routeRow, len(vpcs))
+	err := opts.forEachVPC(vpcs, func(i int, vpc vpcconfapi.VPC) error {
+		routeTables, err := opts.api.GetRouteTables(vpc)
+		if err != nil {
+			return err
+		}
+		for _, id := range sortedRouteTableIDs(routeTables) {
+			routes, err := routesFor(routeTables[id])
+			if err != nil {
+				return fmt.Errorf("%s: %s", id, err)
+			}
+			rowsByVPC[i] = append(rowsByVPC[i], routeRows(vpc, id, routes)...)
+		}
+		return nil
+	})
+	rows := []*routeRow{}
+	for _, vpcRows := range rowsByVPC {
+		rows = append(rows, vpcRows...)
+	}
+	return rows, err
+}
+
+func writeRouteRows(opts *options, rows []*routeRow) error {
+	t := &table{headers: []string{"VPC ID", "REGION", "ROUTE TABLE", "DESTINATION", "TARGET", "MANAGED"}}
+	for _, r := range rows {
+		t.add(r.VPCID, string(r.Region), r.RouteTableID, r.Destination, r.Target, strconv.FormatBool(r.Managed))
+	}
+	return opts.write(rows, t)
+}
+
+func showRoutes(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	rows, err := opts.collectRoutes(vpcs, func(rt *database.RouteTableSnapshotEntry) ([]*database.SnapshotRoute, error) {
+		return rt.Routes, nil
+	})
+	if writeErr := writeRouteRows(opts, rows); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func audit(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Please provide an audit sub-command: supernets or capacity")
+	}
+	switch args[0] {
+	case "supernets":
+		return auditSupernets(opts, vpcs)
+	case "capacity":
+		return auditCapacity(opts, vpcs)
+	}
+	return fmt.Errorf("Invalid audit sub-command: %s", args[0])
+}
+
+// auditSupernets finds routes that are less specific than the routes
+// vpc-conf manages in the same route table, or than any -cidrs.
+func auditSupernets(opts *options, vpcs []vpcconfapi.VPC) error {
+	rows, err := opts.collectRoutes(vpcs, func(rt *database.RouteTableSnapshotEntry) ([]*database.SnapshotRoute, error) {
+		cidrs := append([]string{}, opts.cidrs...)
+		for _, r := range rt.Routes {
+			if r.Managed && !strings.HasPrefix(r.Destination, "pl-") {
+				cidrs = append(cidrs, r.Destination)
+			}
+		}
+		return vpcconfapi.FindSupernets(rt.Routes, cidrs)
+	})
+	if writeErr := writeRouteRows(opts, rows); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func auditCapacity(opts *options, vpcs []vpcconfapi.VPC) error {
+	report, err := opts.api.GetRouteTableCapacityReport(opts.threshold)
+	if err != nil {
+		return err
+	}
+	selected := map[string]bool{}
+	for _, vpc := range vpcs {
+		selected[string(vpc.Region)+"/"+vpc.ID] = true
+	}
+	entries := []*vpcconfapi.RouteTableCapacityReportEntry{}
+	t := &table{headers: []string{"VPC ID", "NAME", "REGION", "ROUTE TABLE", "SUBNET TYPE", "ROUTES", "QUOTA", "AS OF"}}
+	for _, e := range report {
+		if !selected[string(e.Region)+"/"+e.VPCID] {
+			continue
+		}
+		entries = append(entries, e)
+		t.add(e.VPCID, e.VPCName, string(e.Region), e.RouteTableID, string(e.SubnetType), strconv.Itoa(e.Routes), strconv.Itoa(e.Quota), e.UpdatedAt.Format(time.RFC3339))
+	}
+	return opts.write(entries, t)
+}
+
+func snapshotRoutes(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	type snapshotTask struct {
+		VPCID  string
+		Region database.Region
+		TaskID uint64
+	}
+	tasks := make([]*snapshotTask, len(vpcs))
+	err := opts.forEachVPC(vpcs, func(i int, vpc vpcconfapi.VPC) error {
+		tasks[i] = &snapshotTask{VPCID: vpc.ID, Region: vpc.Region}
+		if opts.dryRun {
+			fmt.Fprintf(os.Stderr, "DRY-RUN: snapshot route tables of %s\n", vpc)
+			return nil
+		}
+		taskID, err := opts.api.SnapshotRouteTables(vpc)
+		tasks[i].TaskID = taskID
+		return err
+	})
+	t := &table{headers: []string{"VPC ID", "REGION", "TASK ID"}}
+	for _, task := range tasks {
+		t.add(task.VPCID, string(task.Region), strconv.FormatUint(task.TaskID, 10))
+	}
+	if writeErr := opts.write(tasks, t); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+func submitBatch(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Please provide the task types to run")
+	}
+	taskTypes, err := vpcconfapi.ParseTaskTypes(args[0])
+	if err != nil {
+		return err
+	}
+	if len(vpcs) == 0 {
+		return fmt.Errorf("No VPCs selected")
+	}
+	request := &vpcconfapi.BatchTaskRequest{
+		TaskTypes:                              taskTypes,
+		VPCs:                                   vpcs,
+		AddManagedTransitGatewayAttachments:    opts.addMTGAs,
+		RemoveManagedTransitGatewayAttachments: opts.rmMTGAs,
+	}
+	if opts.selector != "" || opts.selectorName != "" {
+		// Let the server resolve the selector again so the batch task
+		// records what it matched
+		request.VPCs = nil
+		request.Selector = opts.selector
+		request.SelectorName = opts.selectorName
+	}
+	if taskTypes.Includes(database.TaskTypeVerifyState) || taskTypes.Includes(database.TaskTypeRepair) {
+		request.VerifySpec = opts.verifySpec
+	}
+	if opts.dryRun {
+		buf, err := json.MarshalIndent(request, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "DRY-RUN: batch task payload:\n")
+		_, err = fmt.Fprintf(opts.out, "%s\n", buf)
+		return err
+	}
+
+	result, err := opts.api.SubmitBatchTask(request)
+	if err != nil {
+		return err
+	}
+	if !opts.wait {
+		return opts.write(result, &table{
+			headers: []string{"BATCH TASK ID"},
+			rows:    [][]string{{strconv.Itoa(result.BatchTaskID)}},
+		})
+	}
+
+	for {
+		info, err := opts.api.GetBatchTaskByID(result.BatchTaskID)
+		if err != nil {
+			return err
+		}
+		progress := info.GetBatchTaskProgress()
+		fmt.Fprintf(os.Stderr, "Batch task %d: %s\n", result.BatchTaskID, progress)
+		if progress.Remaining() == 0 {
+			t := &table{headers: []string{"TASK ID", "VPC ID", "REGION", "STATUS"}}
+			for _, task := range info.Tasks {
+				t.add(strconv.FormatUint(task.ID, 10), task.VPCID, task.VPCRegion, task.Status)
+			}
+			err = opts.write(info, t)
+			if err == nil && progress.Failed > 0 {
+				err = fmt.Errorf("%d task(s) failed", progress.Failed)
+			}
+			return err
+		}
+		select {
+		case <-opts.ctx.Done():
+			return opts.ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func tailTask(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Please provide a task ID")
+	}
+	taskID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid task ID %q", args[0])
+	}
+	task, err := opts.api.TailTask(opts.ctx, taskID, 5*time.Second, func(entry *database.LogEntry) {
+		if opts.output != "json" {
+			fmt.Fprintf(opts.out, "%s  %s\n", entry.Time.Local().Format("15:04:05"), entry.Message)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if opts.output == "json" {
+		if err := opts.write(task, nil); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(opts.out, "Task %d: %s\n", task.ID, task.Status)
+	}
+	if task.Status != database.TaskStatusSuccessful {
+		return fmt.Errorf("Task %d %s", task.ID, strings.ToLower(task.Status.String()))
+	}
+	return nil
+}
+
+func selectors(opts *options, vpcs []vpcconfapi.VPC, args []string) error {
+	if len(args) == 0 {
+		saved, err := opts.api.GetVPCSelectors()
+		if err != nil {
+			return err
+		}
+		t := &table{headers: []string{"NAME", "SELECTOR", "DESCRIPTION"}}
+		for _, sel := range saved {
+			t.add(sel.Name, sel.Expression, sel.Description)
+		}
+		return opts.write(saved, t)
+	}
+	switch args[0] {
+	case "save":
+		if len(args) != 3 && len(args) != 4 {
+			return fmt.Errorf("Usage: selectors save <name> <selector> [description]")
+		}
+		sel := &database.VPCSelector{Name: args[1], Expression: args[2]}
+		if len(args) == 4 {
+			sel.Description = args[3]
+		}
+		if opts.dryRun {
+			fmt.Fprintf(os.Stderr, "DRY-RUN: would save selector %q\n", sel.Name)
+			return nil
+		}
+		return opts.api.SaveVPCSelector(sel)
+	case "delete":
+		if len(args) != 2 {
+			return fmt.Errorf("Usage: selectors delete <name>")
+		}
+		if opts.dryRun {
+			fmt.Fprintf(os.Stderr, "DRY-RUN: would delete selector %q\n", args[1])
+			return nil
+		}
+		return opts.api.DeleteVPCSelector(args[1])
+	}
+	return fmt.Errorf("Unknown selectors command %q; use save or delete", args[0])
+}
+
+func listCommands() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <command> [args]\n\nCommands:\n", os.Args[0])
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	env := flag.String("env", "", "environment to use: dev|prod")
+	output := flag.String("o", "table", "output format: table|json")
+	limitVPC := flag.String("vpc", "", "limit to a specific VPC ID or name")
+	limitAccount := flag.String("account", "", "limit to a specific account ID")
+	limitRegion := flag.String("region", "", "limit to a specific region")
+	limitStack := flag.String("stack", "", "limit to a specific stack")
+	selectorExpr := flag.String("selector", "", "select VPCs with a selector expression evaluated by vpc-conf, e.g. 'stack=prod AND label:pci'")
+	selectorName := flag.String("selector-name", "", "select VPCs with a selector saved in vpc-conf")
+	dryRun := flag.Bool("n", false, "show what would be submitted without submitting it")
+	wait := flag.Bool("wait", false, "wait for a batch task to finish")
+	threads := flag.Int("threads", 4, "number of VPCs to work on at once")
+	threshold := flag.Float64("threshold", 0.8, "fraction of the route quota to report for audit capacity")
+	cidrs := flag.String("cidrs", "", "comma-separated CIDRs to also check for supernets in audit supernets")
+	addMTGAs := flag.String("add-mtgas", "", "comma-separated managed transit gateway attachment IDs to add in a batch")
+	removeMTGAs := flag.String("remove-mtgas", "", "comma-separated managed transit gateway attachment IDs to remove in a batch")
+	verify := flag.String("verify", "all", "what a verify or repair batch checks - comma separated")
+	defaultCacheDir := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		defaultCacheDir = filepath.Join(dir, "vpcctl")
+	}
+	cacheDir := flag.String("cache-dir", defaultCacheDir, "directory to cache VPC lists and templates in; empty to disable")
+	cacheTTL := flag.Duration("cache-ttl", 10*time.Minute, "how long cached lists are used for")
+	refresh := flag.Bool("refresh", false, "clear the cache first")
+	verbose := flag.Bool("v", false, "log requests to vpc-conf")
+
+	flag.Usage = listCommands
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(exitCodeUsage)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Invalid command: %s\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(exitCodeUsage)
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "Invalid output format %q\n", *output)
+		os.Exit(exitCodeUsage)
+	}
+	if *threads < 1 {
+		*threads = 1
+	}
+	if *selectorExpr != "" && *selectorName != "" {
+		fmt.Fprintf(os.Stderr, "-selector and -selector-name can't be used together\n")
+		os.Exit(exitCodeUsage)
+	}
+	if (*selectorExpr != "" || *selectorName != "") && (*limitVPC != "" || *limitAccount != "" || *limitRegion != "" || *limitStack != "") {
+		fmt.Fprintf(os.Stderr, "-selector and -selector-name can't be combined with -vpc, -account, -region or -stack\n")
+		os.Exit(exitCodeUsage)
+	}
+	var err error
+	if *env == "" {
+		*env = os.Getenv("VPCCTL_ENV")
+	}
+	baseURL := baseURLs[*env]
+	if baseURL == "" {
+		fmt.Fprintf(os.Stderr, "Invalid environment %q\n", *env)
+		os.Exit(exitCodeUsage)
+	}
+	if !*verbose {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	opts := &options{
+		out:          os.Stdout,
+		output:       *output,
+		dryRun:       *dryRun,
+		wait:         *wait,
+		threads:      *threads,
+		threshold:    *threshold,
+		selector:     *selectorExpr,
+		selectorName: *selectorName,
+	}
+	if *cidrs != "" {
+		opts.cidrs = strings.Split(*cidrs, ",")
+	}
+	opts.addMTGAs, err = parseIDs(*addMTGAs)
+	if err == nil {
+		opts.rmMTGAs, err = parseIDs(*removeMTGAs)
+	}
+	if err == nil {
+		opts.verifySpec, err = vpcconfapi.ParseVerifySpec(*verify)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(exitCodeUsage)
+	}
+
+	keysString := os.Getenv("VPC_CONF_API_KEYS")
+	if len(keysString) == 0 {
+		fmt.Fprintf(os.Stderr, "VPC_CONF_API_KEYS is not defined\n")
+		os.Exit(exitCodeInvalidEnvironment)
+	}
+	keys := &VPCConfAPIKeys{}
+	err = json.Unmarshal([]byte(keysString), keys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unmarshal VPC_CONF_API_KEYS: %s\n", err)
+		os.Exit(exitCodeInvalidEnvironment)
+	}
+	key := keys.Prod
+	if *env == "dev" {
+		key = keys.Dev
+	}
+	if key == "" {
+		fmt.Fprintf(os.Stderr, "VPC_CONF_API_KEYS entry for %s is empty\n", *env)
+		os.Exit(exitCodeInvalidEnvironment)
+	}
+
+	opts.api = &vpcconfapi.VPCConfAPI{
+		APIKey:   key,
+		BaseURL:  baseURL,
+		CacheTTL: *cacheTTL,
+	}
+	if *cacheDir != "" {
+		// Keep dev and prod lists apart
+		opts.api.Cache = &vpcconfapi.FileCache{Dir: filepath.Join(*cacheDir, *env)}
+		if *refresh {
+			err = opts.api.Cache.Clear()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error clearing cache: %s\n", err)
+				os.Exit(exitCodeFatal)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+	opts.ctx = ctx
+
+	var vpcs []vpcconfapi.VPC
+	if cmd.needsVPCs {
+		allVPCs, _, err := opts.api.GetAutomatedVPCsAndRegions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching VPCs: %s\n", err)
+			os.Exit(exitCodeFatal)
+		}
+		if opts.selector != "" || opts.selectorName != "" {
+			resolved, err := opts.api.SelectVPCs(opts.selectorName, opts.selector)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error resolving selector: %s\n", err)
+				os.Exit(exitCodeFatal)
+			}
+			vpcs = resolvedVPCs(allVPCs, resolved)
+		} else {
+			vpcs = selectVPCs(allVPCs, &vpcSelector{
+				vpc:     *limitVPC,
+				account: *limitAccount,
+				region:  *limitRegion,
+				stack:   *limitStack,
+			})
+		}
+	}
+
+	err = cmd.run(opts, vpcs, flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(exitCodeFatal)
+	}
+	os.Exit(exitCodeSuccess)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/vpcconfapi"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var testVPCs = []vpcconfapi.VPC{
+	{ID: "vpc-1", AccountID: "111111111111", Name: "app-dev", Stack: "dev", Region: "us-east-1"},
+	{ID: "vpc-2", AccountID: "111111111111", Name: "app-prod", Stack: "prod", Region: "us-east-1"},
+	{ID: "vpc-3", AccountID: "222222222222", Name: "other-dev", Stack: "dev", Region: "us-west-2"},
+}
+
+func TestSelectVPCs(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *vpcSelector
+		expected []string
+	}{
+		{"everything", &vpcSelector{}, []string{"vpc-1", "vpc-2", "vpc-3"}},
+		{"by ID", &vpcSelector{vpc: "vpc-2"}, []string{"vpc-2"}},
+		{"by name", &vpcSelector{vpc: "other-dev"}, []string{"vpc-3"}},
+		{"by stack", &vpcSelector{stack: "DEV"}, []string{"vpc-1", "vpc-3"}},
+		{"by account and region", &vpcSelector{account: "111111111111", region: "us-west-2"}, []string{}},
+	}
+	for _, tc := range tests {
+		ids := []string{}
+		for _, vpc := range selectVPCs(testVPCs, tc.selector) {
+			ids = append(ids, vpc.ID)
+		}
+		if diff := cmp.Diff(tc.expected, ids); diff != "" {
+			t.Errorf("%s: expected VPCs did not match actual: \n%s", tc.name, diff)
+		}
+	}
+}
+
+func TestResolvedVPCs(t *testing.T) {
+	resolved := &database.BatchTaskSelector{
+		Expression: "stack=dev",
+		VPCs: []*database.BatchTaskSelectedVPC{
+			{ID: "vpc-3", Region: "us-west-2"},
+			{ID: "vpc-1", Region: "us-east-1"},
+			{ID: "vpc-2", Region: "us-west-2"},
+		},
+	}
+	ids := []string{}
+	for _, vpc := range resolvedVPCs(testVPCs, resolved) {
+		ids = append(ids, vpc.ID)
+	}
+	if diff := cmp.Diff([]string{"vpc-1", "vpc-3"}, ids); diff != "" {
+		t.Errorf("Expected VPCs did not match actual: \n%s", diff)
+	}
+}
+
+func TestSubmitBatchSelector(t *testing.T) {
+	var request *vpcconfapi.BatchTaskRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/batch" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		request = &vpcconfapi.BatchTaskRequest{}
+		json.NewDecoder(r.Body).Decode(request)
+		json.NewEncoder(w).Encode(&vpcconfapi.BatchTaskResult{BatchTaskID: 7})
+	}))
+	defer server.Close()
+
+	opts := &options{
+		api:          &vpcconfapi.VPCConfAPI{APIKey: "key", BaseURL: server.URL},
+		ctx:          context.Background(),
+		out:          new(bytes.Buffer),
+		output:       "json",
+		selectorName: "pci",
+	}
+	err := submitBatch(opts, testVPCs, []string{"networking"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := &vpcconfapi.BatchTaskRequest{
+		TaskTypes:    database.TaskTypeNetworking,
+		SelectorName: "pci",
+	}
+	if diff := cmp.Diff(expected, request, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Expected request did not match actual: \n%s", diff)
+	}
+}
+
+func TestSubmitBatchVerifySpec(t *testing.T) {
+	var request *vpcconfapi.BatchTaskRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = &vpcconfapi.BatchTaskRequest{}
+		json.NewDecoder(r.Body).Decode(request)
+		json.NewEncoder(w).Encode(&vpcconfapi.BatchTaskResult{BatchTaskID: 7})
+	}))
+	defer server.Close()
+
+	opts := &options{
+		api:        &vpcconfapi.VPCConfAPI{APIKey: "key", BaseURL: server.URL},
+		ctx:        context.Background(),
+		out:        new(bytes.Buffer),
+		output:     "json",
+		verifySpec: database.VerifySpec{VerifyNetworking: true},
+	}
+	err := submitBatch(opts, testVPCs, []string{"repair"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(database.VerifySpec{VerifyNetworking: true}, request.VerifySpec); diff != "" {
+		t.Errorf("Expected verify spec did not match actual: \n%s", diff)
+	}
+}
+
+func TestAuditSupernets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/us-east-1/vpc/111111111111/vpc-1/routeTables.json" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]*database.RouteTableSnapshotEntry{
+			"rtb-1": {
+				Managed: true,
+				Routes: []*database.SnapshotRoute{
+					{RouteInfo: database.RouteInfo{Destination: "10.128.0.0/16", TransitGatewayID: "tgw-1"}, Managed: true},
+					{RouteInfo: database.RouteInfo{Destination: "10.0.0.0/8", PeeringConnectionID: "pcx-1"}},
+					{RouteInfo: database.RouteInfo{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"}},
+				},
+			},
+			"rtb-2": {
+				Routes: []*database.SnapshotRoute{
+					{RouteInfo: database.RouteInfo{Destination: "192.168.0.0/16"}, OtherTargetID: "eni-1"},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		api:     &vpcconfapi.VPCConfAPI{APIKey: "key", BaseURL: server.URL},
+		ctx:     context.Background(),
+		out:     out,
+		output:  "json",
+		threads: 2,
+		cidrs:   []string{"192.168.1.0/24"},
+	}
+	err := audit(opts, testVPCs[:1], []string{"supernets"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	rows := []*routeRow{}
+	err = json.Unmarshal(out.Bytes(), &rows)
+	if err != nil {
+		t.Fatalf("Error parsing output %q: %s", out, err)
+	}
+	expected := []*routeRow{
+		{VPCID: "vpc-1", AccountID: "111111111111", Region: "us-east-1", RouteTableID: "rtb-1", Destination: "10.0.0.0/8", Target: "pcx-1"},
+		{VPCID: "vpc-1", AccountID: "111111111111", Region: "us-east-1", RouteTableID: "rtb-2", Destination: "192.168.0.0/16", Target: "eni-1"},
+	}
+	if diff := cmp.Diff(expected, rows); diff != "" {
+		t.Errorf("Expected supernets did not match actual: \n%s", diff)
+	}
+
+	// A VPC the server doesn't know about fails on its own
+	out.Reset()
+	err = audit(opts, testVPCs[:2], []string{"supernets"})
+	if err == nil || err.Error() != "1 of 2 VPCs failed" {
+		t.Errorf("Expected one VPC to fail but got %v", err)
+	}
+}
@@ -0,0 +1,475 @@
+// Package fake is an in-memory stand-in for babygroot, the CMSNet API. It
+// serves the endpoints that cmsnet.ClientInterface calls so that vpc-conf
+// can run connect, disconnect and NAT flows without the real service.
+//
+// Requests don't complete on a timer. Every read of a VPC's connections or
+// NATs moves each pending request forward one step, so a client that polls
+// sees REQUESTED, then IN_PROGRESS, then SUCCESSFUL (or a failure if one was
+// injected).
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet"
+)
+
+const (
+	statusFailed cmsnet.ConnectionStatus = "FAILED"
+
+	deletionStatusInProgress cmsnet.DeletionStatus = "IN_PROGRESS"
+	deletionStatusFailed     cmsnet.DeletionStatus = "FAILED"
+
+	defaultSteps = 3
+)
+
+// Faults are consumed as they are used.
+type Faults struct {
+	// The next N connection or NAT requests fail to deploy
+	FailRequests int
+	// The next N deletions fail
+	FailDeletions int
+	// The next N API calls return this HTTP status instead of being handled
+	ErrorStatus int
+	ErrorCount  int
+	// Activations with these IDs show up as missing in AWS once they are active
+	BreakActivations []string
+}
+
+var resources = map[string]bool{
+	"wan_activations":  true,
+	"synchronizations": true,
+	"nats":             true,
+	"routes":           true,
+}
+
+type vpcKey struct {
+	Region, AccountID, VPCID string
+}
+
+type connection struct {
+	request *cmsnet.ConnectionRequest
+	steps   int // steps left for whatever is in progress
+	fail    bool
+	broken  bool
+	active  bool
+}
+
+type nat struct {
+	request *cmsnet.NATRequest
+	steps   int
+	fail    bool
+	active  bool
+}
+
+type vpcData struct {
+	connections []*connection
+	nats        []*nat
+}
+
+type Server struct {
+	// Required in the x-apigw-api-id header if set
+	APIID string
+	// How many reads it takes for a request or deletion to finish
+	Steps int
+
+	mu      sync.Mutex
+	vpcs    map[vpcKey]*vpcData
+	faults  Faults
+	natIPID int
+}
+
+func NewServer(apiID string) *Server {
+	return &Server{
+		APIID: apiID,
+		Steps: defaultSteps,
+		vpcs:  map[vpcKey]*vpcData{},
+	}
+}
+
+// InjectFaults replaces any faults that haven't been used yet.
+func (s *Server) InjectFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+}
+
+func requestID(parts ...string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(parts, "/"))))
+}
+
+func (s *Server) vpc(key vpcKey) *vpcData {
+	data := s.vpcs[key]
+	if data == nil {
+		data = &vpcData{}
+		s.vpcs[key] = data
+	}
+	return data
+}
+
+func (s *Server) advance(data *vpcData) {
+	for _, conn := range data.connections {
+		req := conn.request
+		if req.ConnectionStatus.BlocksDeletion() {
+			conn.steps--
+			req.ConnectionState++
+			req.ConnectionStatus = cmsnet.ConnectionStatusInProgress
+			req.ConnectionMessages = append(req.ConnectionMessages, fmt.Sprintf("Step %d", req.ConnectionState))
+			if conn.steps <= 0 {
+				if conn.fail {
+					req.ConnectionStatus = statusFailed
+					req.ConnectionFailureMessage = "Injected deployment failure"
+				} else {
+					req.ConnectionStatus = cmsnet.ConnectionStatusSuccessful
+					conn.active = true
+				}
+			}
+		} else if req.DeletionStatus == deletionStatusInProgress {
+			conn.steps--
+			req.DeletionState++
+			req.DeletionMessages = append(req.DeletionMessages, fmt.Sprintf("Step %d", req.DeletionState))
+			if conn.steps <= 0 {
+				if conn.fail {
+					req.DeletionStatus = deletionStatusFailed
+					req.DeletionFailureMessage = "Injected deletion failure"
+				} else {
+					req.DeletionStatus = cmsnet.DeletionStatusSuccessful
+					conn.active = false
+				}
+			}
+		}
+	}
+	for _, n := range data.nats {
+		req := n.request
+		if req.ConnectionStatus.BlocksDeletion() {
+			n.steps--
+			req.ConnectionState++
+			req.ConnectionStatus = cmsnet.ConnectionStatusInProgress
+			if n.steps <= 0 {
+				if n.fail {
+					req.ConnectionStatus = statusFailed
+					req.ConnectionFailureMessage = "Injected deployment failure"
+				} else {
+					req.ConnectionStatus = cmsnet.ConnectionStatusSuccessful
+					n.active = true
+				}
+			}
+		} else if req.DeletionStatus == deletionStatusInProgress {
+			n.steps--
+			req.DeletionState++
+			if n.steps <= 0 {
+				if n.fail {
+					req.DeletionStatus = deletionStatusFailed
+					req.DeletionFailureMessage = "Injected deletion failure"
+				} else {
+					req.DeletionStatus = cmsnet.DeletionStatusSuccessful
+					n.active = false
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) breakActivations() {
+	remaining := []string{}
+	for _, id := range s.faults.BreakActivations {
+		found := false
+		for _, data := range s.vpcs {
+			for _, conn := range data.connections {
+				if conn.request.ID == id && conn.active {
+					conn.broken = true
+					found = true
+				}
+			}
+		}
+		if !found {
+			remaining = append(remaining, id)
+		}
+	}
+	s.faults.BreakActivations = remaining
+}
+
+func (s *Server) connectionData(data *vpcData, broken bool) cmsnet.ConnectionData {
+	result := cmsnet.ConnectionData{
+		Requests:    []cmsnet.ConnectionRequest{},
+		Activations: []cmsnet.Activation{},
+	}
+	for _, conn := range data.connections {
+		if !broken {
+			result.Requests = append(result.Requests, *conn.request)
+		}
+		if conn.active && conn.broken == broken {
+			result.Activations = append(result.Activations, cmsnet.Activation{
+				RequestID:       conn.request.ID,
+				SubnetID:        conn.request.Params.SubnetID,
+				DestinationCIDR: conn.request.Params.DestinationCIDR,
+				VRF:             conn.request.Params.VRF,
+			})
+		}
+	}
+	return result
+}
+
+func (s *Server) natData(data *vpcData) cmsnet.NATData {
+	result := cmsnet.NATData{
+		Requests: []cmsnet.NATRequest{},
+		NATs:     []cmsnet.NAT{},
+	}
+	for _, n := range data.nats {
+		result.Requests = append(result.Requests, *n.request)
+		if n.active {
+			result.NATs = append(result.NATs, cmsnet.NAT{
+				RequestID:      n.request.ID,
+				InsideNetwork:  n.request.Params.InsideNetwork,
+				OutsideNetwork: n.request.Params.OutsideNetwork,
+				VRF:            n.request.Params.VRF,
+			})
+		}
+	}
+	return result
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.APIID != "" && r.Header.Get("x-apigw-api-id") != s.APIID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS ") {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.HasSuffix(r.URL.Path, "/_faults") && r.Method == http.MethodPost {
+		faults := Faults{}
+		err := json.NewDecoder(r.Body).Decode(&faults)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error parsing faults: %s", err), http.StatusBadRequest)
+			return
+		}
+		s.faults = faults
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if s.faults.ErrorCount > 0 {
+		s.faults.ErrorCount--
+		http.Error(w, "Injected error", s.faults.ErrorStatus)
+		return
+	}
+
+	// [prefix/]{resource}/{region}/{account}/{vpc}[/{id}]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	start := -1
+	for idx, part := range parts {
+		if resources[part] && (len(parts)-idx == 4 || len(parts)-idx == 5) {
+			start = idx
+			break
+		}
+	}
+	if start == -1 {
+		http.NotFound(w, r)
+		return
+	}
+	parts = parts[start:]
+	resource, key, id := parts[0], vpcKey{parts[1], parts[2], parts[3]}, ""
+	if len(parts) == 5 {
+		id = parts[4]
+	}
+	s.breakActivations()
+
+	var result interface{}
+	var err error
+	switch {
+	case resource == "wan_activations" && r.Method == http.MethodGet && id == "":
+		data := s.vpc(key)
+		s.advance(data)
+		result = &cmsnet.WANActivationResponse{DatabaseResults: s.connectionData(data, false)}
+	case resource == "wan_activations" && r.Method == http.MethodPut && id == "":
+		result, err = s.requestConnection(key, r)
+	case resource == "wan_activations" && r.Method == http.MethodDelete && id != "":
+		result, err = s.deleteConnection(key, id)
+	case resource == "synchronizations" && r.Method == http.MethodGet && id == "":
+		data := s.vpc(key)
+		result = &cmsnet.WANActivationResponse{
+			DatabaseResults: s.connectionData(data, false),
+			MissingInAWS:    s.connectionData(data, true),
+		}
+	case resource == "nats" && r.Method == http.MethodGet && id == "":
+		data := s.vpc(key)
+		s.advance(data)
+		result = &cmsnet.NATResponse{DatabaseResults: s.natData(data)}
+	case resource == "nats" && r.Method == http.MethodPut && id == "":
+		result, err = s.requestNAT(key, r)
+	case resource == "nats" && r.Method == http.MethodDelete && id != "":
+		result, err = s.deleteNAT(key, id)
+	case resource == "routes" && r.Method == http.MethodGet && id == "":
+		result = s.routes(key, r.URL.Query().Get("ip"))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	buf, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Error marshalling: %s", err)
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "%s", buf)
+}
+
+func (s *Server) requestConnection(key vpcKey, r *http.Request) (*cmsnet.WANActivationResponse, error) {
+	params := cmsnet.ConnectionRequestParams{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing request: %s", err)
+	}
+	if params.SubnetID == "" || params.VRF == "" || len(params.AttachmentSubnetIDs) == 0 {
+		return nil, fmt.Errorf("subnet_id, vrf and attachment_subnet_ids are required")
+	}
+	_, _, err = net.ParseCIDR(params.DestinationCIDR)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid destination_cidr %q", params.DestinationCIDR)
+	}
+	data := s.vpc(key)
+	for _, conn := range data.connections {
+		p := conn.request.Params
+		if p.SubnetID == params.SubnetID && p.DestinationCIDR == params.DestinationCIDR && (conn.active || conn.request.ConnectionStatus.BlocksDeletion()) {
+			return nil, fmt.Errorf("Subnet %s is already connected to %s", params.SubnetID, params.DestinationCIDR)
+		}
+	}
+	conn := &connection{
+		request: &cmsnet.ConnectionRequest{
+			ID:                 requestID(key.Region, key.AccountID, key.VPCID, params.SubnetID, params.DestinationCIDR, fmt.Sprintf("%d", len(data.connections))),
+			ConnectionStatus:   cmsnet.ConnectionStatusRequested,
+			ConnectionMessages: []string{},
+			Params:             params,
+		},
+		steps: s.Steps,
+	}
+	if s.faults.FailRequests > 0 {
+		s.faults.FailRequests--
+		conn.fail = true
+	}
+	data.connections = append(data.connections, conn)
+	return &cmsnet.WANActivationResponse{DatabaseResults: s.connectionData(data, false)}, nil
+}
+
+func (s *Server) deleteConnection(key vpcKey, id string) (*cmsnet.WANActivationResponse, error) {
+	data := s.vpc(key)
+	for _, conn := range data.connections {
+		if conn.request.ID != id || !conn.active {
+			continue
+		}
+		if conn.request.DeletionStatus == deletionStatusInProgress {
+			return nil, fmt.Errorf("Deletion of %s is already in progress", id)
+		}
+		conn.request.DeletionStatus = deletionStatusInProgress
+		conn.request.DeletionMessages = []string{}
+		conn.steps = s.Steps
+		conn.fail = false
+		conn.broken = false
+		if s.faults.FailDeletions > 0 {
+			s.faults.FailDeletions--
+			conn.fail = true
+		}
+		return &cmsnet.WANActivationResponse{DatabaseResults: s.connectionData(data, false)}, nil
+	}
+	return nil, fmt.Errorf("No activation %s", id)
+}
+
+func (s *Server) requestNAT(key vpcKey, r *http.Request) (*cmsnet.NATResponse, error) {
+	params := cmsnet.NATRequestParams{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing request: %s", err)
+	}
+	_, _, err = net.ParseCIDR(params.InsideNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid inside_network %q", params.InsideNetwork)
+	}
+	if params.OutsideNetwork == "" {
+		// Addresses come out of the benchmarking range so they can't be mistaken for real ones
+		s.natIPID++
+		params.OutsideNetwork = fmt.Sprintf("198.18.%d.%d/32", s.natIPID/256, s.natIPID%256)
+	}
+	data := s.vpc(key)
+	for _, n := range data.nats {
+		if n.request.Params.InsideNetwork == params.InsideNetwork && (n.active || n.request.ConnectionStatus.BlocksDeletion()) {
+			return nil, fmt.Errorf("%s already has a NAT", params.InsideNetwork)
+		}
+	}
+	n := &nat{
+		request: &cmsnet.NATRequest{
+			ID:                 requestID(key.Region, key.AccountID, key.VPCID, params.InsideNetwork, fmt.Sprintf("%d", len(data.nats))),
+			ConnectionStatus:   cmsnet.ConnectionStatusRequested,
+			ConnectionMessages: []string{},
+			Params:             params,
+		},
+		steps: s.Steps,
+	}
+	if s.faults.FailRequests > 0 {
+		s.faults.FailRequests--
+		n.fail = true
+	}
+	data.nats = append(data.nats, n)
+	return &cmsnet.NATResponse{DatabaseResults: s.natData(data)}, nil
+}
+
+func (s *Server) deleteNAT(key vpcKey, id string) (*cmsnet.NATResponse, error) {
+	data := s.vpc(key)
+	for _, n := range data.nats {
+		if n.request.ID != id || !n.active {
+			continue
+		}
+		if n.request.DeletionStatus == deletionStatusInProgress {
+			return nil, fmt.Errorf("Deletion of %s is already in progress", id)
+		}
+		n.request.DeletionStatus = deletionStatusInProgress
+		n.steps = s.Steps
+		n.fail = false
+		if s.faults.FailDeletions > 0 {
+			s.faults.FailDeletions--
+			n.fail = true
+		}
+		return &cmsnet.NATResponse{DatabaseResults: s.natData(data)}, nil
+	}
+	return nil, fmt.Errorf("No NAT %s", id)
+}
+
+// routes returns the CMSNet CIDRs the VPC is connected to, limited to the
+// ones containing ip if it is given.
+func (s *Server) routes(key vpcKey, ip string) *cmsnet.RouteResponse {
+	result := &cmsnet.RouteResponse{CMSNetRoutes: []*cmsnet.RouteData{}}
+	seen := map[string]bool{}
+	for _, conn := range s.vpc(key).connections {
+		p := conn.request.Params
+		if !conn.active || seen[p.DestinationCIDR+p.VRF] {
+			continue
+		}
+		route := &cmsnet.RouteData{CMSNetCIDR: p.DestinationCIDR, VRF: p.VRF}
+		if ip != "" {
+			_, ipNet, err := net.ParseCIDR(p.DestinationCIDR)
+			if err != nil || !ipNet.Contains(net.ParseIP(ip)) {
+				continue
+			}
+			route.MatchedIPAddress = ip
+		}
+		seen[p.DestinationCIDR+p.VRF] = true
+		result.CMSNetRoutes = append(result.CMSNetRoutes, route)
+	}
+	return result
+}
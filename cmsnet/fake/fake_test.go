@@ -0,0 +1,163 @@
+package fake
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/cmsnet"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/credentialservice"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	awssession "github.com/aws/aws-sdk-go/aws/session"
+)
+
+type testCredentials struct{}
+
+func (testCredentials) GetAWSCredentials(accountID, region, asUser string) (*credentialservice.Credentials, error) {
+	return &credentialservice.Credentials{AccessKeyID: "AKIA" + accountID}, nil
+}
+
+func (testCredentials) GetAWSSession(accountID, region, asUser string) (*awssession.Session, error) {
+	return nil, nil
+}
+
+const (
+	accountID = "123456789012"
+	region    = database.Region("us-east-1")
+	vpcID     = "vpc-abc"
+)
+
+func newTestClient(t *testing.T) (*Server, cmsnet.ClientInterface) {
+	server := NewServer("test-api")
+	server.Steps = 2
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	config := cmsnet.Config{region: {BaseURL: ts.URL + "/", APIID: "test-api", TGWAccountID: "999999999999"}}
+	return server, cmsnet.NewClient(config, nil, testCredentials{})
+}
+
+func TestConnectionFlow(t *testing.T) {
+	server, client := newTestClient(t)
+	params := &cmsnet.ConnectionRequestParams{
+		SubnetID:            "subnet-app",
+		DestinationCIDR:     "10.0.0.0/8",
+		VRF:                 "vpn_app_unix_imp",
+		AttachmentSubnetIDs: []string{"subnet-private"},
+	}
+	data, err := client.MakeConnectionRequest(accountID, region, vpcID, params, "tester")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(data.Requests) != 1 || data.Requests[0].ConnectionStatus != cmsnet.ConnectionStatusRequested {
+		t.Fatalf("Expected 1 REQUESTED request but got %+v", data.Requests)
+	}
+	_, err = client.MakeConnectionRequest(accountID, region, vpcID, params, "tester")
+	if err == nil {
+		t.Errorf("Expected an error requesting the same connection twice")
+	}
+
+	get := func() *cmsnet.ConnectionData {
+		data, err := client.GetAllConnectionRequests(accountID, region, vpcID, "tester")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return data
+	}
+	if data := get(); data.Requests[0].ConnectionStatus != cmsnet.ConnectionStatusInProgress || len(data.Activations) != 0 {
+		t.Errorf("Expected request to be in progress with no activations but got %+v", data)
+	}
+	data = get()
+	if data.Requests[0].ConnectionStatus != cmsnet.ConnectionStatusSuccessful || len(data.Activations) != 1 {
+		t.Fatalf("Expected request to succeed with an activation but got %+v", data)
+	}
+	activation := data.Activations[0]
+	if activation.SubnetID != "subnet-app" || activation.VRF != "vpn_app_unix_imp" || activation.RequestID != data.Requests[0].ID {
+		t.Errorf("Wrong activation %+v", activation)
+	}
+
+	routes := server.routes(vpcKey{string(region), accountID, vpcID}, "10.1.2.3")
+	if len(routes.CMSNetRoutes) != 1 || routes.CMSNetRoutes[0].MatchedIPAddress != "10.1.2.3" {
+		t.Errorf("Expected a matching route but got %+v", routes.CMSNetRoutes)
+	}
+
+	// Broken activations show up in synchronizations
+	server.InjectFaults(Faults{BreakActivations: []string{activation.RequestID}})
+	broken, err := client.GetBrokenActivations(accountID, region, vpcID, "tester")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(broken) != 1 || broken[0].RequestID != activation.RequestID {
+		t.Errorf("Expected the activation to be broken but got %+v", broken)
+	}
+	if data := get(); len(data.Activations) != 0 {
+		t.Errorf("Expected broken activation not to be listed as active but got %+v", data.Activations)
+	}
+
+	// Delete
+	_, err = client.DeleteActivation(activation.RequestID, accountID, region, vpcID, "tester")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	get()
+	data = get()
+	if data.Requests[0].DeletionStatus != cmsnet.DeletionStatusSuccessful || len(data.Activations) != 0 {
+		t.Errorf("Expected deletion to succeed but got %+v", data)
+	}
+	broken, err = client.GetBrokenActivations(accountID, region, vpcID, "tester")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(broken) != 0 {
+		t.Errorf("Expected no broken activations after deleting but got %+v", broken)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	server, client := newTestClient(t)
+	server.InjectFaults(Faults{FailRequests: 1, ErrorStatus: 503, ErrorCount: 1})
+
+	_, err := client.GetAllNATRequests(accountID, region, vpcID, "tester")
+	if err == nil {
+		t.Errorf("Expected an injected error")
+	}
+
+	params := &cmsnet.NATRequestParams{InsideNetwork: "10.10.0.5/32", VRF: "vpn_app_unix_imp"}
+	for _, expected := range []cmsnet.ConnectionStatus{statusFailed, cmsnet.ConnectionStatusSuccessful} {
+		_, err = client.MakeNATRequest(accountID, region, vpcID, params, "tester")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		var data *cmsnet.NATData
+		for i := 0; i < server.Steps; i++ {
+			data, err = client.GetAllNATRequests(accountID, region, vpcID, "tester")
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		}
+		if status := data.Requests[len(data.Requests)-1].ConnectionStatus; status != expected {
+			t.Fatalf("Expected NAT request to be %s but got %s", expected, status)
+		}
+	}
+
+	data, err := client.GetAllNATRequests(accountID, region, vpcID, "tester")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(data.NATs) != 1 || data.NATs[0].OutsideNetwork == "" {
+		t.Fatalf("Expected 1 NAT with an outside network but got %+v", data.NATs)
+	}
+
+	server.InjectFaults(Faults{FailDeletions: 1})
+	_, err = client.DeleteNAT(data.NATs[0].RequestID, accountID, region, vpcID, false, "tester")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for i := 0; i < server.Steps; i++ {
+		data, err = client.GetAllNATRequests(accountID, region, vpcID, "tester")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if len(data.NATs) != 1 || data.Requests[1].DeletionStatus != deletionStatusFailed {
+		t.Errorf("Expected NAT deletion to fail but got %+v", data)
+	}
+}
@@ -0,0 +1,495 @@
+package connection
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+type HopType string
+
+const (
+	HopTypeSecurityGroups    HopType = "SecurityGroups"
+	HopTypeNetworkACL        HopType = "NetworkACL"
+	HopTypeRouteTable        HopType = "RouteTable"
+	HopTypeLocal             HopType = "Local"
+	HopTypeTransitGateway    HopType = "TransitGateway"
+	HopTypePeeringConnection HopType = "PeeringConnection"
+	HopTypeNATGateway        HopType = "NATGateway"
+	HopTypeInternetGateway   HopType = "InternetGateway"
+	HopTypeFirewallEndpoint  HopType = "FirewallEndpoint"
+	HopTypeVPCEndpoint       HopType = "VPCEndpoint"
+	HopTypeOther             HopType = "Other"
+)
+
+// Paths longer than this are assumed to be routing loops
+const maxRoutedHops = 8
+
+// Return traffic goes to whatever ephemeral port the client picked, so
+// network ACLs have to allow the whole range.
+const (
+	ephemeralPortFrom = 1024
+	ephemeralPortTo   = 65535
+)
+
+// A Hop is one thing traffic passes through or is checked against on its
+// way from the source to the destination.
+type Hop struct {
+	Type       HopType
+	ResourceID string
+	Detail     string
+	// Rules that allow the traffic, for SecurityGroups hops
+	AuthorizingRules []*AuthorizingRule `json:",omitempty"`
+	// Blocking is set on whatever stops the traffic
+	Blocking bool
+}
+
+type AuthorizingRule struct {
+	SecurityGroupID   string
+	SecurityGroupName string
+	Rule              string
+}
+
+// A PathAnalysis explains whether a connection would be allowed.
+type PathAnalysis struct {
+	Allowed bool
+	// Reason is the Detail of the first blocking hop
+	Reason string `json:",omitempty"`
+	Hops   []*Hop
+}
+
+func (a *PathAnalysis) add(hop *Hop) *Hop {
+	a.Hops = append(a.Hops, hop)
+	if hop.Blocking && a.Allowed {
+		a.Allowed = false
+		a.Reason = hop.Detail
+	}
+	return hop
+}
+
+func (a *PathAnalysis) block(hop *Hop, detail string, args ...interface{}) {
+	hop.Blocking = true
+	hop.Detail = fmt.Sprintf(detail, args...)
+	if a.Allowed {
+		a.Allowed = false
+		a.Reason = hop.Detail
+	}
+}
+
+// AnalyzePath works out the route traffic from spec.Source to
+// spec.Destination on spec.Port would take and whether network ACLs and
+// security groups allow it. Unlike Verify, it only makes describe calls and
+// never creates anything, so it can only see what sourceEC2 and
+// destinationEC2 can: routing inside a transit gateway or on the other side
+// of a peering connection isn't followed. Network ACLs are checked in both
+// directions on every subnet the traffic enters or leaves, including NAT
+// gateway and firewall subnets, with return traffic on ephemeral ports.
+//
+// spec.Source must have a NetworkInterfaceSpec. destinationEC2 is only used
+// if spec.Destination has one as well.
+func AnalyzePath(sourceEC2, destinationEC2 ec2iface.EC2API, spec *ConnectionSpec, logger Logger) (*PathAnalysis, error) {
+	if logger == nil {
+		logger = &DefaultLogger{}
+	}
+	if spec.Source.NetworkInterfaceSpec == nil {
+		return nil, fmt.Errorf("Source must be in a VPC to analyze a path")
+	}
+	if (spec.Destination.NetworkInterfaceSpec == nil) == (spec.Destination.IPAddress == nil) {
+		return nil, fmt.Errorf("You must specify exactly one of NetworkInterfaceSpec or IPAddress")
+	}
+	// Filling in details changes the endpoints, so work on copies
+	source := &Endpoint{NetworkInterfaceSpec: copyNetworkInterfaceSpec(spec.Source.NetworkInterfaceSpec)}
+	err := fillInNetworkInterfaceDetails(sourceEC2, source, logger)
+	if err != nil {
+		return nil, err
+	}
+	destination := &Endpoint{IPAddress: spec.Destination.IPAddress}
+	if spec.Destination.NetworkInterfaceSpec != nil {
+		destination = &Endpoint{NetworkInterfaceSpec: copyNetworkInterfaceSpec(spec.Destination.NetworkInterfaceSpec)}
+		err := fillInNetworkInterfaceDetails(destinationEC2, destination, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	analysis := &PathAnalysis{Allowed: true}
+
+	sourceGroups := analysis.add(&Hop{Type: HopTypeSecurityGroups, ResourceID: strings.Join(source.NetworkInterfaceSpec.SecurityGroupIDs, ",")})
+	if source.NetworkInterfaceSpec.CreateEgressSecurityGroup {
+		sourceGroups.Detail = "No security groups given so egress is assumed to be allowed"
+	} else {
+		err := checkSecurityGroups(sourceEC2, analysis, sourceGroups, source, destination, spec.Port, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = checkNetworkACL(sourceEC2, analysis, source.NetworkInterfaceSpec.SubnetID, destination.IPAddress.IP, spec.Port, spec.Port, true)
+	if err != nil {
+		return nil, err
+	}
+	transitSubnetIDs, err := followRoutes(sourceEC2, analysis, source, destination, spec.Port, logger)
+	if err != nil {
+		return nil, err
+	}
+	if destination.NetworkInterfaceSpec != nil {
+		err = checkNetworkACL(destinationEC2, analysis, destination.NetworkInterfaceSpec.SubnetID, source.IPAddress.IP, spec.Port, spec.Port, false)
+		if err != nil {
+			return nil, err
+		}
+		destinationGroups := analysis.add(&Hop{Type: HopTypeSecurityGroups, ResourceID: strings.Join(destination.NetworkInterfaceSpec.SecurityGroupIDs, ",")})
+		err = checkSecurityGroups(destinationEC2, analysis, destinationGroups, destination, source, spec.Port, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Security groups are stateful but network ACLs aren't, so the reply has
+	// to be allowed back through every subnet in reverse.
+	if destination.NetworkInterfaceSpec != nil {
+		err = checkNetworkACL(destinationEC2, analysis, destination.NetworkInterfaceSpec.SubnetID, source.IPAddress.IP, ephemeralPortFrom, ephemeralPortTo, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for idx := len(transitSubnetIDs) - 1; idx >= 0; idx-- {
+		err = checkNetworkACL(sourceEC2, analysis, transitSubnetIDs[idx], destination.IPAddress.IP, ephemeralPortFrom, ephemeralPortTo, false)
+		if err != nil {
+			return nil, err
+		}
+		err = checkNetworkACL(sourceEC2, analysis, transitSubnetIDs[idx], source.IPAddress.IP, ephemeralPortFrom, ephemeralPortTo, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = checkNetworkACL(sourceEC2, analysis, source.NetworkInterfaceSpec.SubnetID, destination.IPAddress.IP, ephemeralPortFrom, ephemeralPortTo, false)
+	if err != nil {
+		return nil, err
+	}
+	return analysis, nil
+}
+
+func copyNetworkInterfaceSpec(spec *NetworkInterfaceSpec) *NetworkInterfaceSpec {
+	c := *spec
+	c.SecurityGroupIDs = append([]string(nil), spec.SecurityGroupIDs...)
+	return &c
+}
+
+func checkSecurityGroups(ec2svc ec2iface.EC2API, analysis *PathAnalysis, hop *Hop, local, remote *Endpoint, port int64, isEgress bool) error {
+	ingressGroups, egressGroups, err := authorizingGroups(ec2svc, local, remote, port)
+	if err != nil {
+		return fmt.Errorf("Failed to determine security group that applies to %s: %w", remote.IPAddress.IP, err)
+	}
+	groups := ingressGroups
+	if isEgress {
+		groups = egressGroups
+	}
+	for _, group := range groups {
+		perms := group.IpPermissions
+		if isEgress {
+			perms = group.IpPermissionsEgress
+		}
+		perm, err := matchingPermission(local, remote, port, perms)
+		if err != nil {
+			return err
+		}
+		hop.AuthorizingRules = append(hop.AuthorizingRules, &AuthorizingRule{
+			SecurityGroupID:   aws.StringValue(group.GroupId),
+			SecurityGroupName: aws.StringValue(group.GroupName),
+			Rule:              describePermission(perm),
+		})
+	}
+	if len(groups) == 0 {
+		if isEgress {
+			analysis.block(hop, "No group allows egress to %s on port %d", remote.IPAddress.IP, port)
+		} else {
+			analysis.block(hop, "No group allows ingress from %s on port %d", remote.IPAddress.IP, port)
+		}
+	} else if isEgress {
+		hop.Detail = fmt.Sprintf("Egress to %s on port %d is allowed", remote.IPAddress.IP, port)
+	} else {
+		hop.Detail = fmt.Sprintf("Ingress from %s on port %d is allowed", remote.IPAddress.IP, port)
+	}
+	return nil
+}
+
+func describePermission(perm *ec2.IpPermission) string {
+	protocol := aws.StringValue(perm.IpProtocol)
+	ports := ""
+	if protocol == "-1" {
+		protocol = "all traffic"
+	} else if aws.Int64Value(perm.FromPort) == aws.Int64Value(perm.ToPort) {
+		ports = fmt.Sprintf(" port %d", aws.Int64Value(perm.FromPort))
+	} else {
+		ports = fmt.Sprintf(" ports %d-%d", aws.Int64Value(perm.FromPort), aws.Int64Value(perm.ToPort))
+	}
+	peers := []string{}
+	for _, ipRange := range perm.IpRanges {
+		peers = append(peers, aws.StringValue(ipRange.CidrIp))
+	}
+	for _, pair := range perm.UserIdGroupPairs {
+		peers = append(peers, aws.StringValue(pair.GroupId))
+	}
+	return fmt.Sprintf("%s%s %s", protocol, ports, strings.Join(peers, ", "))
+}
+
+func describePorts(fromPort, toPort int64) string {
+	if fromPort == toPort {
+		return fmt.Sprintf("port %d", fromPort)
+	}
+	return fmt.Sprintf("ports %d-%d", fromPort, toPort)
+}
+
+type portRange struct {
+	From, To int64
+}
+
+func (r portRange) overlaps(other portRange) bool {
+	return other.From <= r.To && other.To >= r.From
+}
+
+// subtract returns what is left of r once other is taken out
+func (r portRange) subtract(other portRange) []portRange {
+	if !r.overlaps(other) {
+		return []portRange{r}
+	}
+	left := []portRange{}
+	if other.From > r.From {
+		left = append(left, portRange{r.From, other.From - 1})
+	}
+	if other.To < r.To {
+		left = append(left, portRange{other.To + 1, r.To})
+	}
+	return left
+}
+
+// checkNetworkACL evaluates the rules of the network ACL on subnetID in
+// order, the same way AWS does: the lowest numbered matching rule wins.
+// Every port from fromPort to toPort has to be allowed, so a deny rule
+// covering any of them blocks the traffic.
+func checkNetworkACL(ec2svc ec2iface.EC2API, analysis *PathAnalysis, subnetID string, ip net.IP, fromPort, toPort int64, isEgress bool) error {
+	out, err := ec2svc.DescribeNetworkAcls(&ec2.DescribeNetworkAclsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("association.subnet-id"),
+				Values: []*string{&subnetID},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Error describing network ACL for %q: %w", subnetID, err)
+	}
+	if len(out.NetworkAcls) != 1 {
+		return fmt.Errorf("Found %d network ACLs associated with %q", len(out.NetworkAcls), subnetID)
+	}
+	acl := out.NetworkAcls[0]
+	hop := analysis.add(&Hop{Type: HopTypeNetworkACL, ResourceID: aws.StringValue(acl.NetworkAclId)})
+	direction := "ingress from"
+	if isEgress {
+		direction = "egress to"
+	}
+
+	entries := []*ec2.NetworkAclEntry{}
+	for _, entry := range acl.Entries {
+		if aws.BoolValue(entry.Egress) == isEgress && entry.CidrBlock != nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return aws.Int64Value(entries[i].RuleNumber) < aws.Int64Value(entries[j].RuleNumber)
+	})
+	ports := describePorts(fromPort, toPort)
+	remaining := []portRange{{fromPort, toPort}}
+	allowedBy := []string{}
+	for _, entry := range entries {
+		protocol := aws.StringValue(entry.Protocol)
+		if protocol != "-1" && protocol != "6" {
+			continue
+		}
+		entryPorts := portRange{0, 65535}
+		if protocol == "6" && entry.PortRange != nil {
+			entryPorts = portRange{aws.Int64Value(entry.PortRange.From), aws.Int64Value(entry.PortRange.To)}
+		}
+		_, ipNet, err := net.ParseCIDR(aws.StringValue(entry.CidrBlock))
+		if err != nil {
+			return fmt.Errorf("Unable to parse CIDR %q: %w", aws.StringValue(entry.CidrBlock), err)
+		}
+		if !ipNet.Contains(ip) {
+			continue
+		}
+		matches := false
+		left := []portRange{}
+		for _, r := range remaining {
+			matches = matches || r.overlaps(entryPorts)
+			left = append(left, r.subtract(entryPorts)...)
+		}
+		if !matches {
+			continue
+		}
+		if aws.StringValue(entry.RuleAction) != ec2.RuleActionAllow {
+			analysis.block(hop, "Rule %d denies %s %s on %s", aws.Int64Value(entry.RuleNumber), direction, ip, ports)
+			return nil
+		}
+		allowedBy = append(allowedBy, fmt.Sprintf("%d", aws.Int64Value(entry.RuleNumber)))
+		remaining = left
+		if len(remaining) == 0 {
+			rules := "Rule " + allowedBy[0] + " allows"
+			if len(allowedBy) > 1 {
+				rules = "Rules " + strings.Join(allowedBy, ", ") + " allow"
+			}
+			hop.Detail = fmt.Sprintf("%s %s %s on %s", rules, direction, ip, ports)
+			return nil
+		}
+	}
+	analysis.block(hop, "No rule allows %s %s on %s", direction, ip, describePorts(remaining[0].From, remaining[0].To))
+	return nil
+}
+
+// followRoutes follows routes from the source subnet through NAT gateways
+// and firewall endpoints until traffic leaves the VPC or reaches its
+// destination inside it, then checks it went the way traffic to that kind
+// of destination has to. The network ACLs of the NAT gateway and firewall
+// subnets passed through are checked for port on the way, and those subnets
+// are returned in order so the reply can be checked too.
+func followRoutes(ec2svc ec2iface.EC2API, analysis *PathAnalysis, source, destination *Endpoint, port int64, logger Logger) ([]string, error) {
+	ip := destination.IPAddress.IP
+	subnetID := source.NetworkInterfaceSpec.SubnetID
+	transitSubnetIDs := []string{}
+	enterSubnet := func(nextSubnetID string) error {
+		if nextSubnetID == subnetID {
+			// Network ACLs only apply between subnets
+			return nil
+		}
+		transitSubnetIDs = append(transitSubnetIDs, nextSubnetID)
+		subnetID = nextSubnetID
+		err := checkNetworkACL(ec2svc, analysis, subnetID, source.IPAddress.IP, port, port, false)
+		if err != nil {
+			return err
+		}
+		return checkNetworkACL(ec2svc, analysis, subnetID, ip, port, port, true)
+	}
+	viaNAT := false
+	var last *Hop
+	target := ""
+	for i := 0; ; i++ {
+		routeTable, route, err := findRoute(ec2svc, subnetID, ip, logger)
+		if err != nil {
+			return nil, err
+		}
+		hop := analysis.add(&Hop{Type: HopTypeRouteTable, ResourceID: aws.StringValue(routeTable.RouteTableId)})
+		if route == nil {
+			analysis.block(hop, "No route in %s matches %s", subnetID, ip)
+			return transitSubnetIDs, nil
+		}
+		target = getRouteDestination(route)
+		routeDestination := aws.StringValue(route.DestinationCidrBlock)
+		if route.DestinationPrefixListId != nil {
+			routeDestination = aws.StringValue(route.DestinationPrefixListId)
+		}
+		hop.Detail = fmt.Sprintf("%s is routed to %s by the route for %s", ip, target, routeDestination)
+		if i == maxRoutedHops {
+			analysis.block(hop, "Gave up following routes to %s after %d hops", ip, maxRoutedHops)
+			return transitSubnetIDs, nil
+		}
+
+		switch {
+		case target == "local":
+			last = analysis.add(&Hop{Type: HopTypeLocal, ResourceID: target, Detail: "Delivered within the VPC"})
+		case strings.HasPrefix(target, "tgw-"):
+			last = analysis.add(&Hop{Type: HopTypeTransitGateway, ResourceID: target, Detail: "Routing within the transit gateway is not followed"})
+		case strings.HasPrefix(target, "igw-"):
+			last = analysis.add(&Hop{Type: HopTypeInternetGateway, ResourceID: target})
+		case strings.HasPrefix(target, "pcx-"):
+			last = analysis.add(&Hop{Type: HopTypePeeringConnection, ResourceID: target})
+			out, err := ec2svc.DescribeVpcPeeringConnections(&ec2.DescribeVpcPeeringConnectionsInput{
+				VpcPeeringConnectionIds: []*string{&target},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Error checking peering connection: %w", err)
+			}
+			if len(out.VpcPeeringConnections) != 1 {
+				return nil, fmt.Errorf("Got %d peering connections for id %q", len(out.VpcPeeringConnections), target)
+			}
+			pcx := out.VpcPeeringConnections[0]
+			if pcx.Status == nil || aws.StringValue(pcx.Status.Code) != ec2.VpcPeeringConnectionStateReasonCodeActive {
+				analysis.block(last, "%s is not active", target)
+				return transitSubnetIDs, nil
+			}
+		case strings.HasPrefix(target, "nat-"):
+			out, err := ec2svc.DescribeNatGateways(&ec2.DescribeNatGatewaysInput{
+				NatGatewayIds: []*string{&target},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Error checking NAT gateway: %w", err)
+			}
+			if len(out.NatGateways) != 1 {
+				return nil, fmt.Errorf("Got %d NAT Gateways for id %q", len(out.NatGateways), target)
+			}
+			nat := out.NatGateways[0]
+			natHop := analysis.add(&Hop{Type: HopTypeNATGateway, ResourceID: target, Detail: fmt.Sprintf("Translated and sent on from %s", aws.StringValue(nat.SubnetId))})
+			if aws.StringValue(nat.State) != ec2.NatGatewayStateAvailable {
+				analysis.block(natHop, "%s is %s", target, aws.StringValue(nat.State))
+				return transitSubnetIDs, nil
+			}
+			viaNAT = true
+			err = enterSubnet(aws.StringValue(nat.SubnetId))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		case strings.HasPrefix(target, "vpce-"):
+			out, err := ec2svc.DescribeVpcEndpoints(&ec2.DescribeVpcEndpointsInput{
+				VpcEndpointIds: []*string{&target},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("Error describing VPC endpoint: %w", err)
+			}
+			if len(out.VpcEndpoints) != 1 || len(out.VpcEndpoints[0].SubnetIds) != 1 {
+				last = analysis.add(&Hop{Type: HopTypeVPCEndpoint, ResourceID: target, Detail: "Traffic past this endpoint is not followed"})
+				break
+			}
+			endpoint := out.VpcEndpoints[0]
+			hopType := HopTypeVPCEndpoint
+			if aws.StringValue(endpoint.VpcEndpointType) == ec2.VpcEndpointTypeGatewayLoadBalancer {
+				hopType = HopTypeFirewallEndpoint
+			}
+			endpointSubnetID := aws.StringValue(endpoint.SubnetIds[0])
+			analysis.add(&Hop{Type: hopType, ResourceID: target, Detail: fmt.Sprintf("Inspected and sent on from %s", endpointSubnetID)})
+			err = enterSubnet(endpointSubnetID)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			last = analysis.add(&Hop{Type: HopTypeOther, ResourceID: target, Detail: "Traffic past this target is not followed"})
+		}
+		break
+	}
+
+	switch destination.IPAddress.IPType {
+	case IPTypeInternet:
+		if last.Type != HopTypeInternetGateway {
+			analysis.block(last, "Internet IP %s is not routed to an internet gateway (routed to %s instead)", ip, target)
+		} else if !viaNAT && !source.NetworkInterfaceSpec.UsePublicIP {
+			analysis.block(last, "%s is a private IP but internet IP %s is not routed through a NAT gateway", source.IPAddress.IP, ip)
+		} else {
+			last.Detail = "Sent to the internet"
+		}
+	case IPTypeSharedService:
+		if last.Type != HopTypeTransitGateway {
+			analysis.block(last, "%s is not routed to a transit gateway (routed to %s instead)", ip, target)
+		}
+	case IPTypeVPC:
+		sameVPC := destination.NetworkInterfaceSpec != nil && destination.NetworkInterfaceSpec.VPCID == source.NetworkInterfaceSpec.VPCID
+		if sameVPC && last.Type != HopTypeLocal {
+			analysis.block(last, "%s is not routed to \"local\" (routed to %s instead)", ip, target)
+		} else if !sameVPC && last.Type != HopTypePeeringConnection && last.Type != HopTypeTransitGateway {
+			analysis.block(last, "%s is not routed to a peering connection or transit gateway (routed to %s instead)", ip, target)
+		}
+	}
+	return transitSubnetIDs, nil
+}
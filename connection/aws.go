@@ -17,6 +17,16 @@ import (
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 )
 
+// describeEC2 is what's needed to look at an endpoint's network configuration
+// without changing anything. Both cancellableEC2 and ec2iface.EC2API have it.
+type describeEC2 interface {
+	DescribeNetworkInterfaces(*ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error)
+	DescribeSubnets(*ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
+	DescribeRouteTables(*ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error)
+	GetManagedPrefixListEntries(*ec2.GetManagedPrefixListEntriesInput) (*ec2.GetManagedPrefixListEntriesOutput, error)
+	DescribeSecurityGroups(*ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
+}
+
 type cancellableEC2 struct {
 	t   *NetworkConnectionTest
 	EC2 ec2iface.EC2API
@@ -147,70 +147,72 @@ func (t *NetworkConnectionTest) fillInDetails(endpoint *Endpoint) error {
 		return fmt.Errorf("You must specify exactly one of NetworkInterfaceSpec or IPAddress")
 	}
 	if endpoint.NetworkInterfaceSpec != nil {
-		ec2svc := newCancellableEC2(t, endpoint.NetworkInterfaceSpec.session)
-		endpoint.IPAddress = &IPAddress{}
-		if endpoint.NetworkInterfaceSpec.NetworkInterfaceID != nil {
-			if endpoint.NetworkInterfaceSpec.VPCID != "" || endpoint.NetworkInterfaceSpec.SubnetID != "" || endpoint.NetworkInterfaceSpec.SecurityGroupIDs != nil {
-				return fmt.Errorf("Must not specify VPC ID, Subnet, or Security Groups if Network Interface is specified")
-			}
-			out, err := ec2svc.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
-				NetworkInterfaceIds: []*string{endpoint.NetworkInterfaceSpec.NetworkInterfaceID},
-			})
-			if err != nil {
-				return fmt.Errorf("Error describing network interfaces: %w", err)
-			}
-			if len(out.NetworkInterfaces) != 1 {
-				return fmt.Errorf("Got %d network interfaces with id %q", len(out.NetworkInterfaces), *endpoint.NetworkInterfaceSpec.NetworkInterfaceID)
-			}
-			iface := out.NetworkInterfaces[0]
-			for _, group := range iface.Groups {
-				endpoint.NetworkInterfaceSpec.SecurityGroupIDs = append(endpoint.NetworkInterfaceSpec.SecurityGroupIDs, aws.StringValue(group.GroupId))
-			}
-			if endpoint.NetworkInterfaceSpec.UsePublicIP {
-				if iface.Association == nil || iface.Association.PublicIp == nil {
-					return fmt.Errorf("%s has no public IP", *endpoint.NetworkInterfaceSpec.NetworkInterfaceID)
-				}
-				ip := aws.StringValue(iface.Association.PublicIp)
-				endpoint.IPAddress.IP = net.ParseIP(ip)
-				endpoint.IPAddress.IPType = IPTypeInternet
-			} else {
-				ip := aws.StringValue(iface.PrivateIpAddress)
-				endpoint.IPAddress.IP = net.ParseIP(ip)
-				endpoint.IPAddress.IPType = IPTypeVPC
-			}
-			if endpoint.IPAddress.IP == nil {
-				return fmt.Errorf("Failed to locate IP of network interface %q", *endpoint.NetworkInterfaceSpec.NetworkInterfaceID)
+		return fillInNetworkInterfaceDetails(newCancellableEC2(t, endpoint.NetworkInterfaceSpec.session), endpoint, t)
+	}
+	return nil
+}
+
+func fillInNetworkInterfaceDetails(ec2svc describeEC2, endpoint *Endpoint, logger Logger) error {
+	endpoint.IPAddress = &IPAddress{}
+	if endpoint.NetworkInterfaceSpec.NetworkInterfaceID != nil {
+		if endpoint.NetworkInterfaceSpec.VPCID != "" || endpoint.NetworkInterfaceSpec.SubnetID != "" || endpoint.NetworkInterfaceSpec.SecurityGroupIDs != nil {
+			return fmt.Errorf("Must not specify VPC ID, Subnet, or Security Groups if Network Interface is specified")
+		}
+		out, err := ec2svc.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
+			NetworkInterfaceIds: []*string{endpoint.NetworkInterfaceSpec.NetworkInterfaceID},
+		})
+		if err != nil {
+			return fmt.Errorf("Error describing network interfaces: %w", err)
+		}
+		if len(out.NetworkInterfaces) != 1 {
+			return fmt.Errorf("Got %d network interfaces with id %q", len(out.NetworkInterfaces), *endpoint.NetworkInterfaceSpec.NetworkInterfaceID)
+		}
+		iface := out.NetworkInterfaces[0]
+		for _, group := range iface.Groups {
+			endpoint.NetworkInterfaceSpec.SecurityGroupIDs = append(endpoint.NetworkInterfaceSpec.SecurityGroupIDs, aws.StringValue(group.GroupId))
+		}
+		if endpoint.NetworkInterfaceSpec.UsePublicIP {
+			if iface.Association == nil || iface.Association.PublicIp == nil {
+				return fmt.Errorf("%s has no public IP", *endpoint.NetworkInterfaceSpec.NetworkInterfaceID)
 			}
-			endpoint.NetworkInterfaceSpec.VPCID = aws.StringValue(iface.VpcId)
-			endpoint.NetworkInterfaceSpec.SubnetID = aws.StringValue(iface.SubnetId)
-			t.Log("Network Interface %q is in VPC %s / Subnet %s and has IP %s", *endpoint.NetworkInterfaceSpec.NetworkInterfaceID, endpoint.NetworkInterfaceSpec.VPCID, endpoint.NetworkInterfaceSpec.SubnetID, endpoint.IPAddress.IP)
+			ip := aws.StringValue(iface.Association.PublicIp)
+			endpoint.IPAddress.IP = net.ParseIP(ip)
+			endpoint.IPAddress.IPType = IPTypeInternet
 		} else {
-			if endpoint.NetworkInterfaceSpec.SubnetID == "" {
-				return fmt.Errorf("Must specify Subnet ID if Network Interface is not specified")
-			}
-			if endpoint.NetworkInterfaceSpec.SecurityGroupIDs == nil != endpoint.NetworkInterfaceSpec.CreateEgressSecurityGroup {
-				return fmt.Errorf("Must either specify security group IDs or ask for an egress security group to be created, not both or neither")
-			}
-			out, err := ec2svc.DescribeSubnets(&ec2.DescribeSubnetsInput{
-				SubnetIds: []*string{&endpoint.NetworkInterfaceSpec.SubnetID},
-			})
-			if err != nil {
-				return fmt.Errorf("Error loading subnet info: %w", err)
-			}
-			if len(out.Subnets) != 1 {
-				return fmt.Errorf("Got %d subnets for ID %q", len(out.Subnets), endpoint.NetworkInterfaceSpec.SubnetID)
-			}
-			endpoint.NetworkInterfaceSpec.VPCID = aws.StringValue(out.Subnets[0].VpcId)
+			ip := aws.StringValue(iface.PrivateIpAddress)
+			endpoint.IPAddress.IP = net.ParseIP(ip)
 			endpoint.IPAddress.IPType = IPTypeVPC
-			endpoint.IPAddress.IP, _, err = net.ParseCIDR(aws.StringValue(out.Subnets[0].CidrBlock))
-			if err != nil {
-				return fmt.Errorf("Error parsing subnet CIDR: %w", err)
-			}
 		}
-		return nil
+		if endpoint.IPAddress.IP == nil {
+			return fmt.Errorf("Failed to locate IP of network interface %q", *endpoint.NetworkInterfaceSpec.NetworkInterfaceID)
+		}
+		endpoint.NetworkInterfaceSpec.VPCID = aws.StringValue(iface.VpcId)
+		endpoint.NetworkInterfaceSpec.SubnetID = aws.StringValue(iface.SubnetId)
+		logger.Log("Network Interface %q is in VPC %s / Subnet %s and has IP %s", *endpoint.NetworkInterfaceSpec.NetworkInterfaceID, endpoint.NetworkInterfaceSpec.VPCID, endpoint.NetworkInterfaceSpec.SubnetID, endpoint.IPAddress.IP)
 	} else {
-		return nil
+		if endpoint.NetworkInterfaceSpec.SubnetID == "" {
+			return fmt.Errorf("Must specify Subnet ID if Network Interface is not specified")
+		}
+		if endpoint.NetworkInterfaceSpec.SecurityGroupIDs == nil != endpoint.NetworkInterfaceSpec.CreateEgressSecurityGroup {
+			return fmt.Errorf("Must either specify security group IDs or ask for an egress security group to be created, not both or neither")
+		}
+		out, err := ec2svc.DescribeSubnets(&ec2.DescribeSubnetsInput{
+			SubnetIds: []*string{&endpoint.NetworkInterfaceSpec.SubnetID},
+		})
+		if err != nil {
+			return fmt.Errorf("Error loading subnet info: %w", err)
+		}
+		if len(out.Subnets) != 1 {
+			return fmt.Errorf("Got %d subnets for ID %q", len(out.Subnets), endpoint.NetworkInterfaceSpec.SubnetID)
+		}
+		endpoint.NetworkInterfaceSpec.VPCID = aws.StringValue(out.Subnets[0].VpcId)
+		endpoint.IPAddress.IPType = IPTypeVPC
+		endpoint.IPAddress.IP, _, err = net.ParseCIDR(aws.StringValue(out.Subnets[0].CidrBlock))
+		if err != nil {
+			return fmt.Errorf("Error parsing subnet CIDR: %w", err)
+		}
 	}
+	return nil
 }
 
 type RollbackError struct {
@@ -1208,7 +1210,19 @@ func getRouteDestination(route *ec2.Route) string {
 }
 
 func (t *NetworkConnectionTest) determineDestination(sess *session.Session, subnetID string, ip net.IP) (string, error) {
-	ec2svc := newCancellableEC2(t, sess)
+	_, route, err := findRoute(newCancellableEC2(t, sess), subnetID, ip, t)
+	if err != nil {
+		return "", err
+	}
+	if route == nil {
+		return "", fmt.Errorf("No route matched %s", ip)
+	}
+	return getRouteDestination(route), nil
+}
+
+// findRoute returns the route table associated with subnetID and the most
+// specific route in it for ip, or a nil route if nothing matches.
+func findRoute(ec2svc describeEC2, subnetID string, ip net.IP, logger Logger) (*ec2.RouteTable, *ec2.Route, error) {
 	out, err := ec2svc.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
 		Filters: []*ec2.Filter{
 			{
@@ -1218,14 +1232,14 @@ func (t *NetworkConnectionTest) determineDestination(sess *session.Session, subn
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("Error determining route table for %q: %w", subnetID, err)
+		return nil, nil, fmt.Errorf("Error determining route table for %q: %w", subnetID, err)
 	}
 	if len(out.RouteTables) != 1 {
-		return "", fmt.Errorf("Found %d route tables associated with %q", len(out.RouteTables), subnetID)
+		return nil, nil, fmt.Errorf("Found %d route tables associated with %q", len(out.RouteTables), subnetID)
 	}
 	routeTable := out.RouteTables[0]
 	matchingSize := -1
-	matchingDestination := ""
+	var matchingRoute *ec2.Route
 	destinationWithinRoute := func(cidr *string) (bool, error) {
 		_, ipNet, err := net.ParseCIDR(*cidr)
 		if err != nil {
@@ -1242,16 +1256,16 @@ func (t *NetworkConnectionTest) determineDestination(sess *session.Session, subn
 	}
 	for _, route := range routeTable.Routes {
 		if aws.StringValue(route.State) == ec2.RouteStateBlackhole {
-			t.Log("Skipping blackhole route %s", route.String())
+			logger.Log("Skipping blackhole route %s", route.String())
 			continue
 		}
 		if route.DestinationCidrBlock != nil {
 			within, err := destinationWithinRoute(route.DestinationCidrBlock)
 			if err != nil {
-				return "", err
+				return nil, nil, err
 			}
 			if within {
-				matchingDestination = getRouteDestination(route)
+				matchingRoute = route
 			}
 		} else if route.DestinationPrefixListId != nil {
 			plout, err := ec2svc.GetManagedPrefixListEntries(
@@ -1260,26 +1274,23 @@ func (t *NetworkConnectionTest) determineDestination(sess *session.Session, subn
 				},
 			)
 			if err != nil {
-				return "", fmt.Errorf("Error determining entries for prefix list %q: %w", *route.DestinationPrefixListId, err)
+				return nil, nil, fmt.Errorf("Error determining entries for prefix list %q: %w", *route.DestinationPrefixListId, err)
 			}
 			for _, entry := range plout.Entries {
 				within, err := destinationWithinRoute(entry.Cidr)
 				if err != nil {
-					return "", err
+					return nil, nil, err
 				}
 				if within {
-					matchingDestination = getRouteDestination(route)
+					matchingRoute = route
 				}
 			}
 		} else {
-			t.Log("Skipping route %s with no ipv4 cidr", route.String())
+			logger.Log("Skipping route %s with no ipv4 cidr", route.String())
 			continue
 		}
 	}
-	if matchingSize == -1 {
-		return "", fmt.Errorf("No route matched %s", ip)
-	}
-	return matchingDestination, nil
+	return routeTable, matchingRoute, nil
 }
 
 func stringInSlice(s string, sl []string) bool {
@@ -1292,6 +1303,13 @@ func stringInSlice(s string, sl []string) bool {
 }
 
 func destinationIsAllowed(source *Endpoint, destination *Endpoint, port int64, perms []*ec2.IpPermission) (bool, error) {
+	perm, err := matchingPermission(source, destination, port, perms)
+	return perm != nil, err
+}
+
+// matchingPermission returns the first of perms that allows traffic between
+// source and destination on port, or nil if none do.
+func matchingPermission(source *Endpoint, destination *Endpoint, port int64, perms []*ec2.IpPermission) (*ec2.IpPermission, error) {
 	for _, perm := range perms {
 		protocol := aws.StringValue(perm.IpProtocol)
 		if protocol != "-1" && protocol != "tcp" {
@@ -1308,7 +1326,7 @@ func destinationIsAllowed(source *Endpoint, destination *Endpoint, port int64, p
 			if destination.NetworkInterfaceSpec != nil && destination.NetworkInterfaceSpec.Region == source.NetworkInterfaceSpec.Region {
 				for _, ug := range perm.UserIdGroupPairs {
 					if stringInSlice(aws.StringValue(ug.GroupId), destination.NetworkInterfaceSpec.SecurityGroupIDs) {
-						return true, nil
+						return perm, nil
 					}
 				}
 			}
@@ -1318,26 +1336,31 @@ func destinationIsAllowed(source *Endpoint, destination *Endpoint, port int64, p
 			if strings.HasPrefix(cidr, "sg-") {
 				if destination.NetworkInterfaceSpec != nil && destination.NetworkInterfaceSpec.Region == source.NetworkInterfaceSpec.Region {
 					if stringInSlice(cidr, destination.NetworkInterfaceSpec.SecurityGroupIDs) {
-						return true, nil
+						return perm, nil
 					}
 				}
 			} else {
 				_, ipNet, err := net.ParseCIDR(cidr)
 				if err != nil {
-					return false, fmt.Errorf("Unable to parse CIDR %q: %w", cidr, err)
+					return nil, fmt.Errorf("Unable to parse CIDR %q: %w", cidr, err)
 				}
 				if ipNet.Contains(destination.IPAddress.IP) {
-					return true, nil
+					return perm, nil
 				}
 			}
 		}
 	}
-	return false, nil
+	return nil, nil
 }
 
 func (t *NetworkConnectionTest) determineAuthorizingGroups(source, destination *Endpoint, port int64) ([]*ec2.SecurityGroup, []*ec2.SecurityGroup, error) {
+	return authorizingGroups(newCancellableEC2(t, source.NetworkInterfaceSpec.session), source, destination, port)
+}
+
+// authorizingGroups returns the groups of source that allow ingress from and
+// egress to destination on port.
+func authorizingGroups(ec2svc describeEC2, source, destination *Endpoint, port int64) ([]*ec2.SecurityGroup, []*ec2.SecurityGroup, error) {
 	// TODO: make sure cross-account sg security group references work
-	ec2svc := newCancellableEC2(t, source.NetworkInterfaceSpec.session)
 	var matchingIngressGroups []*ec2.SecurityGroup
 	var matchingEgressGroups []*ec2.SecurityGroup
 	groups := source.NetworkInterfaceSpec.SecurityGroupIDs
@@ -29,6 +29,11 @@ func TargetVPC(vpcID string) Target {
 	return Target(fmt.Sprintf("vpc_%s", vpcID))
 }
 
+// You need one of these if you are going to change a VPN connection or its state.
+func TargetVPNConnection(id uint64) Target {
+	return Target(fmt.Sprintf("vpn_%d", id))
+}
+
 // a LockSet represents a set of acquired locks.
 // Acquire a LockSet before starting operations involving any of the
 // targets controlled by this package.
@@ -2284,5 +2284,176 @@ func allMigrations() []migration {
 		&staticMigration{
 			`CREATE TABLE micro_service_heartbeats (service_name TEXT PRIMARY KEY, last_success timestamp with time zone DEFAULT current_timestamp)`,
 		},
+		&staticMigration{
+			`ALTER TABLE vpc_request ADD COLUMN assignee TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE vpc_request ADD COLUMN rejection_reason TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE vpc_request ADD COLUMN task_status_notified integer NULL`,
+			// Don't post results for tasks that finished before syncing existed
+			`UPDATE vpc_request SET task_status_notified=task.status FROM task WHERE task.id=vpc_request.task_id`,
+			`CREATE TABLE vpc_request_comment (
+				id serial PRIMARY KEY,
+				added_at timestamp with time zone DEFAULT current_timestamp,
+				vpc_request_id integer REFERENCES vpc_request(id) NOT NULL,
+				author TEXT NOT NULL,
+				body TEXT NOT NULL,
+				from_jira boolean NOT NULL,
+				jira_comment_id TEXT NULL UNIQUE
+			)`,
+		},
+		&staticMigration{
+			`CREATE TABLE vpc_request_template (
+				id serial PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				config jsonb NOT NULL
+			)`,
+			`CREATE TABLE project_quota (
+				project_name TEXT PRIMARY KEY,
+				max_vpcs integer NOT NULL DEFAULT 0,
+				max_ips_per_stack bigint NOT NULL DEFAULT 0
+			)`,
+			`ALTER TABLE vpc_request ADD COLUMN template_id integer REFERENCES vpc_request_template(id) NULL`,
+			// NULL until evaluated; existing requests were approved by hand
+			`ALTER TABLE vpc_request ADD COLUMN policy_violations TEXT[] NULL`,
+			`UPDATE vpc_request SET policy_violations='{}'`,
+		},
+		&staticMigration{
+			`CREATE TABLE vpc_endpoint_set (
+				id serial PRIMARY KEY,
+				name TEXT NOT NULL,
+				region TEXT NOT NULL,
+				is_gov_cloud boolean NOT NULL DEFAULT false,
+				is_default boolean NOT NULL DEFAULT false,
+				UNIQUE (name, region)
+			)`,
+			`CREATE TABLE vpc_endpoint_template (
+				id serial PRIMARY KEY,
+				vpc_endpoint_set_id integer REFERENCES vpc_endpoint_set(id) NOT NULL,
+				service_name TEXT NOT NULL,
+				endpoint_type TEXT NOT NULL,
+				subnet_types TEXT[] NOT NULL,
+				security_group_template_id integer REFERENCES security_group(id) NULL,
+				private_dns_enabled boolean NOT NULL DEFAULT false
+			)`,
+			`CREATE TABLE configured_vpc_endpoint_set (
+				vpc_id integer REFERENCES vpc(id) NOT NULL,
+				vpc_endpoint_set_id integer REFERENCES vpc_endpoint_set(id) NOT NULL,
+				PRIMARY KEY(vpc_id, vpc_endpoint_set_id)
+			)`,
+		},
+		&staticMigration{
+			`CREATE TABLE firewall_policy_template (
+				id serial PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				config jsonb NOT NULL
+			)`,
+		},
+		&staticMigration{
+			`CREATE TABLE security_group_rule_usage (
+				vpc_id integer REFERENCES vpc(id) ON DELETE CASCADE NOT NULL,
+				security_group_id integer REFERENCES security_group(id) ON DELETE CASCADE NOT NULL,
+				window_start timestamp with time zone NOT NULL,
+				window_end timestamp with time zone NOT NULL,
+				rules jsonb NOT NULL,
+				PRIMARY KEY(vpc_id, security_group_id)
+			)`,
+		},
+		&staticMigration{
+			`ALTER TABLE managed_transit_gateway_attachment ADD COLUMN associate_route_table_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE managed_transit_gateway_attachment ADD COLUMN propagate_route_table_ids TEXT[] NOT NULL DEFAULT '{}'`,
+		},
+		&staticMigration{
+			`CREATE TABLE vpn_connection (
+				id serial PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				config jsonb NOT NULL,
+				state jsonb NULL
+			)`,
+		},
+		&staticMigration{
+			`CREATE TABLE route_table_snapshot (
+				id serial PRIMARY KEY,
+				vpc_id integer REFERENCES vpc(id) ON DELETE CASCADE NOT NULL,
+				created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+				reason TEXT NOT NULL,
+				task_id integer NULL,
+				route_tables jsonb NOT NULL
+			)`,
+			`CREATE INDEX route_table_snapshot_vpc_id ON route_table_snapshot(vpc_id, created_at)`,
+		},
+		&staticMigration{
+			`CREATE TABLE route_table_capacity (
+				vpc_id integer REFERENCES vpc(id) ON DELETE CASCADE PRIMARY KEY,
+				updated_at timestamp with time zone NOT NULL DEFAULT NOW(),
+				quota integer NOT NULL,
+				route_tables jsonb NOT NULL
+			)`,
+		},
+		&staticMigration{
+			`CREATE TABLE shared_service_endpoint (
+				id serial PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				hostname TEXT NOT NULL DEFAULT '',
+				ip TEXT NOT NULL DEFAULT '',
+				port integer NOT NULL,
+				description TEXT NOT NULL DEFAULT ''
+			)`,
+			`INSERT INTO shared_service_endpoint (name, ip, port, description) VALUES ('AD-1', '10.244.112.49', 636, 'Active Directory LDAPS')`,
+			`CREATE TABLE connectivity_test_result (
+				id serial PRIMARY KEY,
+				vpc_id integer REFERENCES vpc(id) ON DELETE CASCADE NOT NULL,
+				created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+				task_id integer NULL,
+				spec jsonb NOT NULL,
+				succeeded boolean NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				resources_not_rolled_back TEXT[] NOT NULL DEFAULT '{}'
+			)`,
+			`CREATE INDEX connectivity_test_result_vpc_id ON connectivity_test_result(vpc_id, created_at)`,
+		},
+		&staticMigration{
+			`CREATE TABLE egress_ip (
+				vpc_id integer REFERENCES vpc(id) ON DELETE CASCADE NOT NULL,
+				allocation_id TEXT NOT NULL,
+				public_ip TEXT NOT NULL,
+				az_name TEXT NOT NULL,
+				nat_gateway_id TEXT NOT NULL DEFAULT '',
+				first_seen_at timestamp with time zone NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (vpc_id, allocation_id)
+			)`,
+			// Events outlive the VPC so the history of an IP can still be looked up
+			`CREATE TABLE egress_ip_event (
+				id serial PRIMARY KEY,
+				created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+				event_type TEXT NOT NULL,
+				aws_region TEXT NOT NULL,
+				vpc_aws_id TEXT NOT NULL,
+				account_aws_id TEXT NOT NULL,
+				az_name TEXT NOT NULL,
+				allocation_id TEXT NOT NULL,
+				public_ip TEXT NOT NULL,
+				task_id integer NULL
+			)`,
+			`CREATE INDEX egress_ip_event_created_at ON egress_ip_event(created_at)`,
+		},
+		&staticMigration{
+			`CREATE TABLE vpc_selector (
+				id serial PRIMARY KEY,
+				name TEXT NOT NULL UNIQUE,
+				expression TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				updated_at timestamp with time zone NOT NULL DEFAULT NOW()
+			)`,
+			// The selector a batch task targeted and the VPCs it resolved to
+			`ALTER TABLE batch_task ADD COLUMN selector jsonb NULL`,
+		},
+		&staticMigration{
+			`CREATE OR REPLACE FUNCTION notify_new_vpc_request() RETURNS trigger as $$
+			BEGIN
+			  PERFORM pg_notify('new_vpc_request', NEW.id::text);
+			  RETURN NULL;
+			END;
+			$$ LANGUAGE plpgsql;`,
+			`CREATE TRIGGER notify_new_vpc_request AFTER INSERT ON vpc_request FOR EACH ROW EXECUTE PROCEDURE notify_new_vpc_request();`,
+		},
 	}
 }
@@ -33,12 +33,13 @@ type AWSAccount struct {
 }
 
 type RouteInfo struct {
-	Destination         string
-	NATGatewayID        string
-	InternetGatewayID   string
-	TransitGatewayID    string
-	PeeringConnectionID string
-	VPCEndpointID       string
+	Destination                 string
+	NATGatewayID                string
+	InternetGatewayID           string
+	EgressOnlyInternetGatewayID string
+	TransitGatewayID            string
+	PeeringConnectionID         string
+	VPCEndpointID               string
 }
 
 type TransitGatewayAttachment struct {
@@ -46,6 +47,10 @@ type TransitGatewayAttachment struct {
 	TransitGatewayID                   string
 	TransitGatewayAttachmentID         string
 	SubnetIDs                          []string
+	IsIPv6Enabled                      bool
+	// Transit gateway route table setup that vpc-conf made in the owner account
+	AssociatedRouteTableID  string   `json:",omitempty"`
+	PropagatedRouteTableIDs []string `json:",omitempty"`
 }
 
 type ResolverRuleAssociation struct {
@@ -59,6 +64,19 @@ type SecurityGroup struct {
 	Rules           []*SecurityGroupRule
 }
 
+// VPCEndpoint records what was created for a VPCEndpointTemplate so that
+// changes to the template can be applied in place.
+type VPCEndpoint struct {
+	TemplateID        uint64
+	VPCEndpointID     string
+	ServiceName       string
+	Type              VPCEndpointType
+	RouteTableIDs     []string // gateway endpoints
+	SubnetIDs         []string // interface endpoints
+	SecurityGroupIDs  []string // interface endpoints
+	PrivateDNSEnabled bool
+}
+
 type RouteTableInfo struct {
 	RouteTableID        string `json:"-"` // filled in from keys of RouteTables map on VPCState
 	Routes              []*RouteInfo
@@ -100,6 +118,7 @@ type SubnetInfo struct {
 	GroupName               string
 	RouteTableAssociationID string
 	CustomRouteTableID      string
+	IPv6CIDR                string // "" for IPv4-only subnets
 }
 
 type RequestType int
@@ -273,6 +292,80 @@ type PeeringConnection struct {
 
 type Firewall struct {
 	AssociatedSubnetIDs []string
+	PolicyTemplateID    uint64 // 0 for the default policy
+	LogDestinations     []*FirewallLogDestination
+}
+
+// A FirewallLogDestination is where the firewall sends one type of log.
+// Network Firewall allows at most one destination per log type.
+type FirewallLogDestination struct {
+	LogType         string            // ALERT or FLOW
+	DestinationType string            // CloudWatchLogs or S3
+	Destination     map[string]string // logGroup, or bucketName and prefix
+	RetentionInDays int64             `json:",omitempty"` // CloudWatchLogs only
+}
+
+// A FirewallPolicyTemplate is an admin-defined Network Firewall policy that
+// V1Firewall VPCs can use instead of the default one. Stateless rule groups
+// get priorities in the order listed, and so do stateful rule groups if
+// StatefulRuleOrder is STRICT_ORDER. AllowedDomains, if set, become a
+// domain allow-list rule group created in each VPC's account.
+type FirewallPolicyTemplate struct {
+	ID                              uint64
+	Name                            string
+	Description                     string
+	Region                          Region
+	StatefulRuleOrder               string   // DEFAULT_ACTION_ORDER if empty, or STRICT_ORDER
+	StatefulRuleGroupARNs           []string // must use the same rule order
+	StatefulDefaultActions          []string // STRICT_ORDER only
+	StatelessRuleGroupARNs          []string
+	StatelessDefaultActions         []string
+	StatelessFragmentDefaultActions []string
+	AllowedDomains                  []string
+	InUseVPCs                       []string `json:",omitempty"` // not stored
+}
+
+const (
+	FirewallRuleOrderDefault = "DEFAULT_ACTION_ORDER"
+	FirewallRuleOrderStrict  = "STRICT_ORDER"
+)
+
+var validStatefulDefaultActions = []string{"aws:drop_strict", "aws:drop_established", "aws:alert_strict", "aws:alert_established"}
+
+// Validate checks the fields that Network Firewall would otherwise reject only
+// once a VPC uses the template.
+func (t *FirewallPolicyTemplate) Validate() error {
+	if t.Name == "" {
+		return errors.New("Name is required")
+	}
+	switch t.StatefulRuleOrder {
+	case "", FirewallRuleOrderDefault, FirewallRuleOrderStrict:
+	default:
+		return fmt.Errorf("Invalid stateful rule order %q", t.StatefulRuleOrder)
+	}
+	if len(t.StatefulDefaultActions) > 0 && t.StatefulRuleOrder != FirewallRuleOrderStrict {
+		return fmt.Errorf("Stateful default actions require %s", FirewallRuleOrderStrict)
+	}
+	for _, action := range t.StatefulDefaultActions {
+		if violations := checkAllowed("Stateful default action", validStatefulDefaultActions, action); len(violations) > 0 {
+			return errors.New(violations[0])
+		}
+	}
+	return nil
+}
+
+// IPv6Config describes where a VPC's IPv6 CIDR block comes from. An empty
+// Pool means an Amazon-provided /56; otherwise the block is allocated from
+// the given BYOIP pool, optionally at a specific CIDRBlock.
+type IPv6Config struct {
+	Pool      string
+	CIDRBlock string
+}
+
+type IPv6Info struct {
+	IPv6Config
+	AssociatedCIDRBlock         string // "" until the block has been associated
+	EgressOnlyInternetGatewayID string
 }
 
 type VPCType int
@@ -293,8 +386,16 @@ func (t VPCType) CanUpdateZonedSubnets() bool {
 	return t.IsV1Variant() || t.IsMigrating()
 }
 
+func (t VPCType) CanExpandCIDR() bool {
+	return t.IsV1Variant()
+}
+
 func (t VPCType) CanImportVPC() bool {
-	return t.IsV1Variant() || t == VPCTypeLegacy
+	return t.IsV1Variant() || t == VPCTypeLegacy || t == VPCTypeMigratingLegacyToV1
+}
+
+func (t VPCType) CanMigrateToV1() bool {
+	return t == VPCTypeLegacy
 }
 
 func (t VPCType) CanDeleteVPC() bool {
@@ -305,6 +406,10 @@ func (t VPCType) CanUpdateSecurityGroups() bool {
 	return t.IsV1Variant()
 }
 
+func (t VPCType) CanUpdateVPCEndpoints() bool {
+	return t.IsV1Variant()
+}
+
 func (t VPCType) CanUpdateCMSNet() bool {
 	return t.IsV1Variant()
 }
@@ -372,6 +477,8 @@ func (t VPCType) String() string {
 		return "VPCTypeMigratingV1ToV1Firewall"
 	case VPCTypeMigratingV1FirewallToV1:
 		return "VPCTypeMigratingV1FirewallToV1"
+	case VPCTypeMigratingLegacyToV1:
+		return "VPCTypeMigratingLegacyToV1"
 	default:
 		return "Unknown"
 	}
@@ -385,6 +492,7 @@ func GetVPCTypes() []VPCType {
 		VPCTypeV1Firewall,
 		VPCTypeMigratingV1ToV1Firewall,
 		VPCTypeMigratingV1FirewallToV1,
+		VPCTypeMigratingLegacyToV1,
 	}
 }
 
@@ -395,9 +503,10 @@ func GetVPCTypes() []VPCType {
 // - Manage peering connections
 // - Manage resolver rule sharing
 // Exception VPCs are VPCs which don't meet current spec https://docs.google.com/document/d/1XPsZPiUMtvnq9GTJ9eX9i54vGvahp4Bs27-usdf2Y6s/edit#
-// and are managed by the EVM.
+// and for which we only attach a transit gateway and add its routes, never removing anything.
 // V1Firewall VPCs have feature parity with V1 VPCs, but follow the reference architecture: https://confluenceent.cms.gov/display/ITOPS/Network+Firewall+VPC+Design+Doc#NetworkFirewallVPCDesignDoc-Architecture
 // Migrating{start_type}To{end_type} VPCs are in the middle of a migration.  The only operations supported for these types are those that implement/revert the migration.
+// MigratingLegacyToV1 VPCs have had their subnets tagged for V1 and are waiting to be re-imported as V1. Importing them as Legacy again abandons the migration.
 
 const (
 	VPCTypeV1 VPCType = iota
@@ -406,6 +515,7 @@ const (
 	VPCTypeV1Firewall
 	VPCTypeMigratingV1ToV1Firewall
 	VPCTypeMigratingV1FirewallToV1
+	VPCTypeMigratingLegacyToV1
 )
 
 type AZMap map[string]*AvailabilityZoneInfra
@@ -436,13 +546,60 @@ type VPCState struct {
 	TransitGatewayAttachments       []*TransitGatewayAttachment
 	ResolverRuleAssociations        []*ResolverRuleAssociation
 	PeeringConnections              []*PeeringConnection `json:"-"` // stored in created_peering_connection table
+	ExternalPeeringConnections      []*ExternalPeeringConnection
 	SecurityGroups                  []*SecurityGroup
+	VPCEndpoints                    []*VPCEndpoint
 	S3FlowLogID                     string
 	CloudWatchLogsFlowLogID         string
 	ResolverQueryLogConfigurationID string
 	ResolverQueryLogAssociationID   string
 	Firewall                        *Firewall // nil for V1
 	FirewallRouteTableID            string    // "" for V1
+	IPv6                            *IPv6Info // nil for IPv4-only VPCs
+
+	ExceptionTransitGateway *ExceptionTransitGatewayStatus `json:",omitempty"` // Exception VPCs only
+}
+
+// Statuses of an Exception VPC route to its transit gateway
+const (
+	ExceptionRoutePresent  = "present"
+	ExceptionRouteCreated  = "created"
+	ExceptionRouteMissing  = "missing"  // left missing by a dry run
+	ExceptionRouteConflict = "conflict" // the destination already routes elsewhere
+)
+
+// ExceptionRouteStatus is one route from the managed transit gateway
+// attachment on one route table of an Exception VPC.
+type ExceptionRouteStatus struct {
+	RouteTableID string
+	Destination  string
+	Status       string
+	Target       string `json:",omitempty"` // where a conflicting route goes instead
+}
+
+// ExceptionTransitGatewayStatus is what the last exception VPC transit
+// gateway task found or did. Exception VPCs are otherwise unmanaged, so
+// only routes are ever added: conflicts are reported, not fixed.
+type ExceptionTransitGatewayStatus struct {
+	ManagedTransitGatewayAttachmentID uint64
+	TransitGatewayID                  string
+	TransitGatewayAttachmentID        string // "" if not attached yet
+	AttachmentState                   string
+	SubnetIDs                         []string
+	Routes                            []*ExceptionRouteStatus
+	DryRun                            bool
+	CheckedAt                         time.Time
+}
+
+// Conflicts returns the routes that need someone to look at them.
+func (s *ExceptionTransitGatewayStatus) Conflicts() []*ExceptionRouteStatus {
+	conflicts := []*ExceptionRouteStatus{}
+	for _, route := range s.Routes {
+		if route.Status == ExceptionRouteConflict {
+			conflicts = append(conflicts, route)
+		}
+	}
+	return conflicts
 }
 
 func (infra *VPCState) GetAvailabilityZoneInfo(azName string) *AvailabilityZoneInfra {
@@ -456,6 +613,31 @@ func (infra *VPCState) GetAvailabilityZoneInfo(azName string) *AvailabilityZoneI
 	return az
 }
 
+// ExternalPeeringConnection is a peering connection this VPC requested to a
+// VPC that vpc-conf doesn't manage.
+type ExternalPeeringConnection struct {
+	OtherVPCID          string
+	OtherVPCRegion      Region
+	OtherVPCAccountID   string
+	PeeringConnectionID string
+	IsAccepted          bool
+	Status              string // as of the last networking update
+}
+
+// ExternalPeeringConnectionConfig peers a VPC with one in an account vpc-conf
+// has no access to, such as a vendor's. This VPC is always the requester and
+// the owner of the other VPC has to accept the connection. Routes to
+// OtherVPCCIDRs are only added once it is accepted.
+type ExternalPeeringConnectionConfig struct {
+	Name                string // for the Name tag; the other VPC has no name in vpc-conf
+	OtherVPCID          string
+	OtherVPCRegion      Region
+	OtherVPCAccountID   string
+	OtherVPCCIDRs       []string
+	ConnectPrivate      bool
+	ConnectSubnetGroups []string
+}
+
 type PeeringConnectionConfig struct {
 	IsRequester                 bool
 	OtherVPCID                  string
@@ -472,8 +654,12 @@ type VPCConfig struct {
 	ConnectPrivate                     bool
 	ManagedTransitGatewayAttachmentIDs []uint64                   `json:"-"` // stored in configured_managed_transit_gateway_attachment table
 	SecurityGroupSetIDs                []uint64                   `json:"-"` // stored in configured_security_group_set table
+	VPCEndpointSetIDs                  []uint64                   `json:"-"` // stored in configured_vpc_endpoint_set table
 	ManagedResolverRuleSetIDs          []uint64                   `json:"-"` // stored in configured_managed_resolver_rule_set table
 	PeeringConnections                 []*PeeringConnectionConfig `json:"-"` // stored in configured_peering_connection table
+	ExternalPeeringConnections         []*ExternalPeeringConnectionConfig
+	FirewallPolicyTemplateID           uint64 // 0 uses the default policy
+	VPNConnectionIDs                   []uint64
 }
 
 type VerifyTypes uint64
@@ -485,6 +671,8 @@ const (
 	VerifySecurityGroups VerifyTypes = 1 << iota
 	VerifyCIDRs          VerifyTypes = 1 << iota
 	VerifyCMSNet         VerifyTypes = 1 << iota
+	VerifyVPCEndpoints   VerifyTypes = 1 << iota
+	VerifyFirewallPolicy VerifyTypes = 1 << iota
 )
 
 func bitmapIncludes(superset, subset uint64) bool {
@@ -516,6 +704,26 @@ type VPCLabel struct {
 	Label  string
 }
 
+// VPCSelector is a named selector expression batch tasks can target
+type VPCSelector struct {
+	ID          uint64
+	Name        string
+	Expression  string
+	Description string
+	UpdatedAt   time.Time
+}
+
+// VPCSelectorInfo is what selectors are evaluated against for an automated VPC
+type VPCSelectorInfo struct {
+	ID, Name, Stack                    string
+	Region                             Region
+	VPCType                            VPCType
+	AccountID, AccountName             string
+	ProjectName                        string
+	Labels                             []string // of the VPC and its account
+	ManagedTransitGatewayAttachmentIDs []uint64
+}
+
 type Session struct {
 	Key                string
 	UserID             int
@@ -570,6 +778,61 @@ type ManagedTransitGatewayAttachment struct {
 	SubnetTypes      []SubnetType
 	InUseVPCs        []string
 	IsDefault        bool
+	// Transit gateway route tables in the owner account to associate the
+	// attachment with and propagate its routes to. Left alone if empty.
+	AssociateRouteTableID  string
+	PropagateRouteTableIDs []string
+}
+
+// A VPNConnection is a Site-to-Site VPN from a transit gateway to an
+// on-premises network. The customer gateway, VPN connection and transit
+// gateway routing are created in the account that owns the transit gateway.
+// VPCs that select the connection get routes to Routes through the transit
+// gateway in the route tables of SubnetTypes, so they must also have a
+// managed transit gateway attachment to that transit gateway.
+type VPNConnection struct {
+	ID               uint64
+	Name             string
+	Region           Region
+	IsGovCloud       bool
+	TransitGatewayID string
+	CustomerGateway  CustomerGatewayConfig
+	StaticRoutesOnly bool
+	// At most two, one per tunnel. AWS picks defaults for missing tunnels
+	// and empty fields.
+	TunnelOptions []*VPNTunnelOptions
+	// Transit gateway route table to associate the VPN attachment with. With
+	// static routing, Routes are also added to it pointing at the VPN;
+	// otherwise the attachment propagates its BGP routes to it.
+	TransitGatewayRouteTableID string
+	Routes                     []string // on-premises CIDRs
+	SubnetTypes                []SubnetType
+	InUseVPCs                  []string            `json:",omitempty"` // not stored
+	State                      *VPNConnectionState `json:",omitempty"` // stored separately from the config
+}
+
+type CustomerGatewayConfig struct {
+	IPAddress  string
+	BGPASN     int64
+	DeviceName string
+}
+
+type VPNTunnelOptions struct {
+	InsideCIDR       string
+	IKEVersions      []string
+	DPDTimeoutAction string
+	StartupAction    string
+}
+
+// VPNConnectionState records what was created for a VPNConnection.
+type VPNConnectionState struct {
+	CustomerGatewayID          string
+	CustomerGateway            CustomerGatewayConfig // what CustomerGatewayID was created with
+	VPNConnectionID            string
+	TransitGatewayAttachmentID string
+	AssociatedRouteTableID     string
+	PropagatedRouteTableID     string
+	StaticRoutes               []string
 }
 
 type SecurityGroupRule struct {
@@ -589,6 +852,191 @@ type SecurityGroupTemplate struct {
 	Rules       []*SecurityGroupRule
 }
 
+// SecurityGroupRuleUsage is how many flows a template rule allowed, summed
+// over the concrete rules it expanded to.
+type SecurityGroupRuleUsage struct {
+	Rule *SecurityGroupRule
+	Hits int64
+}
+
+// SecurityGroupUsage is the result of the latest flow log analysis of one
+// security group template in one VPC.
+type SecurityGroupUsage struct {
+	VPCID       string
+	Region      Region
+	TemplateID  uint64
+	WindowStart time.Time
+	WindowEnd   time.Time
+	Rules       []*SecurityGroupRuleUsage
+}
+
+// SnapshotRoute is a route as it was in AWS. Routes to targets that
+// RouteInfo can't express, like network interfaces, are recorded with
+// OtherTargetID so that they show up in diffs, but they can't be restored.
+type SnapshotRoute struct {
+	RouteInfo
+	OtherTargetID string `json:",omitempty"`
+	Managed       bool   // the route was in the VPC's state
+}
+
+type RouteTableSnapshotEntry struct {
+	Managed bool // the route table was in the VPC's state
+	Routes  []*SnapshotRoute
+}
+
+// RouteTableSnapshot is every non-local route on every route table in a VPC
+// at one point in time, whether or not vpc-conf manages it.
+type RouteTableSnapshot struct {
+	ID          uint64
+	VPCID       string
+	Region      Region
+	CreatedAt   time.Time
+	Reason      string
+	TaskID      *uint64
+	RouteTables map[string]*RouteTableSnapshotEntry // by route table ID
+}
+
+type RouteTableCapacity struct {
+	RouteTableID string
+	SubnetType   SubnetType
+	Routes       int // routes to prefix lists count for the list's max entries
+}
+
+func (c *RouteTableCapacity) NearQuota(quota int, ratio float64) bool {
+	return float64(c.Routes) >= float64(quota)*ratio
+}
+
+// VPCRouteTableCapacity is how full each route table in a VPC will be once
+// the last networking task checked against it finishes, along with the
+// account's routes per route table quota at the time.
+type VPCRouteTableCapacity struct {
+	VPCID       string
+	Region      Region
+	AccountID   string
+	VPCName     string
+	UpdatedAt   time.Time
+	Quota       int
+	RouteTables []*RouteTableCapacity
+}
+
+// EgressIP is a public IP that traffic from a VPC to the internet leaves
+// through, which partners put on their allow lists. For now these are the
+// EIPs of the VPC's NAT gateways; firewalled VPCs send traffic through the
+// same NAT gateways.
+type EgressIP struct {
+	VPCID        string
+	Region       Region
+	AccountID    string
+	VPCName      string // filled in by GetEgressIPs
+	ProjectName  string // filled in by GetEgressIPs
+	AZName       string
+	NATGatewayID string
+	AllocationID string
+	PublicIP     string
+	FirstSeenAt  time.Time
+}
+
+type EgressIPEventType string
+
+const (
+	EgressIPEventTypeAdded   EgressIPEventType = "Added"
+	EgressIPEventTypeRemoved EgressIPEventType = "Removed"
+)
+
+// EgressIPEvent records an egress IP appearing or going away, for example
+// when a NAT gateway is replaced or its availability zone is removed.
+type EgressIPEvent struct {
+	ID           uint64
+	CreatedAt    time.Time
+	Type         EgressIPEventType
+	VPCID        string
+	Region       Region
+	AccountID    string
+	ProjectName  string // filled in by GetEgressIPEvents
+	AZName       string
+	AllocationID string
+	PublicIP     string
+	TaskID       *uint64
+}
+
+// EgressIPFilter limits egress IPs and events to a VPC, account or project.
+// Empty fields match everything.
+type EgressIPFilter struct {
+	Region      Region
+	VPCID       string
+	AccountID   string
+	ProjectName string
+}
+
+// DiffEgressIPs returns the IPs in current that aren't in previous and the
+// ones in previous that aren't in current. An EIP that keeps its allocation
+// ID but moves to a new NAT gateway hasn't changed as far as partners are
+// concerned.
+func DiffEgressIPs(previous, current []*EgressIP) (added, removed []*EgressIP) {
+	key := func(ip *EgressIP) string {
+		return ip.AllocationID + " " + ip.PublicIP
+	}
+	previousKeys := map[string]bool{}
+	for _, ip := range previous {
+		previousKeys[key(ip)] = true
+	}
+	currentKeys := map[string]bool{}
+	for _, ip := range current {
+		currentKeys[key(ip)] = true
+		if !previousKeys[key(ip)] {
+			added = append(added, ip)
+		}
+	}
+	for _, ip := range previous {
+		if !currentKeys[key(ip)] {
+			removed = append(removed, ip)
+		}
+	}
+	return added, removed
+}
+
+// SharedServiceEndpoint is a service connectivity tests can be run against
+// by name.
+type SharedServiceEndpoint struct {
+	ID          uint64
+	Name        string
+	Hostname    string // resolved when a test runs; IP is used if empty
+	IP          string
+	Port        int64
+	Description string
+}
+
+// ConnectivityTestSpec is a connection to test from a subnet in a VPC.
+// Exactly one of DestinationIP, DestinationSubnetID or
+// SharedServiceEndpointName should be set.
+type ConnectivityTestSpec struct {
+	SourceSubnetID         string
+	SourceSecurityGroupIDs []string // a group allowing all egress is created for the test if empty
+
+	DestinationIP string `json:",omitempty"`
+
+	// A subnet in another automated VPC in the same region. A web server is
+	// started there for the test.
+	DestinationVPCID            string   `json:",omitempty"`
+	DestinationSubnetID         string   `json:",omitempty"`
+	DestinationSecurityGroupIDs []string `json:",omitempty"`
+	SharedServiceEndpointName   string   `json:",omitempty"`
+	Port                        int64    // the endpoint's port is used for shared service endpoints
+}
+
+// ConnectivityTestResult is one run of a connectivity test.
+type ConnectivityTestResult struct {
+	ID                     uint64
+	VPCID                  string
+	Region                 Region
+	TaskID                 *uint64
+	CreatedAt              time.Time
+	Spec                   ConnectivityTestSpec
+	Succeeded              bool
+	Error                  string
+	ResourcesNotRolledBack []string
+}
+
 type SecurityGroupSet struct {
 	ID         uint64
 	Name       string
@@ -599,6 +1047,43 @@ type SecurityGroupSet struct {
 	IsGovCloud bool
 }
 
+type VPCEndpointType string
+
+const (
+	VPCEndpointTypeGateway   VPCEndpointType = "Gateway"
+	VPCEndpointTypeInterface VPCEndpointType = "Interface"
+)
+
+// A VPCEndpointTemplate is one endpoint in a VPCEndpointSet. Gateway endpoints
+// are added to the route tables of SubnetTypes; interface endpoints get one
+// network interface per AZ, in the first subnet of the first listed type.
+type VPCEndpointTemplate struct {
+	ID                      uint64
+	ServiceName             string // e.g. "s3"; names with a "." are used as-is
+	Type                    VPCEndpointType
+	SubnetTypes             []SubnetType
+	SecurityGroupTemplateID uint64 // interface endpoints only; 0 means the VPC's default security group
+	PrivateDNSEnabled       bool   // interface endpoints only
+}
+
+// FullServiceName expands short service names to the region's AWS service.
+func (t *VPCEndpointTemplate) FullServiceName(region Region) string {
+	if strings.Contains(t.ServiceName, ".") {
+		return t.ServiceName
+	}
+	return fmt.Sprintf("com.amazonaws.%s.%s", region, t.ServiceName)
+}
+
+type VPCEndpointSet struct {
+	ID         uint64
+	Name       string
+	Endpoints  []*VPCEndpointTemplate
+	InUseVPCs  []string
+	IsDefault  bool
+	Region     Region
+	IsGovCloud bool
+}
+
 type TransitGatewayResourceShare struct {
 	AccountID        string
 	Region           Region
@@ -668,6 +1153,11 @@ type VPCRequest struct {
 	Status                                      VPCRequestStatus
 	HasJIRAErrors                               bool
 	JIRAIssue                                   *string
+	Assignee                                    string      // synced from JIRA
+	RejectionReason                             string      // synced from JIRA
+	TaskStatusNotified                          *TaskStatus // last task status posted to the JIRA issue
+	TemplateID                                  *uint64     // nil if the requester didn't pick a template
+	PolicyViolations                            []string    // nil until the request has been evaluated
 	RequestedConfig                             AllocateConfig
 	ApprovedConfig                              *AllocateConfig
 	TaskID                                      *uint64
@@ -691,6 +1181,18 @@ type VPCRequestLog struct {
 	Message       string    `json:"message"`
 }
 
+// A VPCRequestComment is mirrored between vpc-conf and the request's JIRA
+// issue. JIRACommentID is nil until a local comment has been posted to JIRA.
+type VPCRequestComment struct {
+	ID            uint64
+	VPCRequestID  uint64
+	AddedAt       time.Time
+	Author        string
+	Body          string
+	FromJIRA      bool
+	JIRACommentID *string
+}
+
 type AllocateConfig struct {
 	ParentContainer string
 	Stack           string
@@ -713,6 +1215,161 @@ type AllocateConfig struct {
 
 	AWSRegion     string
 	AutoProvision bool
+
+	IPv6 *IPv6Config // nil for IPv4-only VPCs
+}
+
+// IntRange bounds a requested parameter; Min == Max fixes it and zero means unbounded.
+type IntRange struct {
+	Min, Max int
+}
+
+func (r IntRange) Contains(n int) bool {
+	return (r.Min == 0 || n >= r.Min) && (r.Max == 0 || n <= r.Max)
+}
+
+// A VPCRequestTemplate is an admin-defined shape for VPC requests. Requests
+// within its bounds can be auto-approved if AutoApprove is set and the
+// project's quota allows.
+type VPCRequestTemplate struct {
+	ID          uint64
+	Name        string
+	Description string
+	RequestType RequestType
+
+	// Empty means any
+	Stacks  []string
+	Regions []string
+
+	// New VPC requests; sizes are prefix lengths
+	NumAZs                 IntRange
+	PrivateSize            IntRange
+	PublicSize             IntRange
+	NumPrivateSubnets      IntRange
+	NumPublicSubnets       IntRange
+	AllowFirewall          bool
+	AllowContainersSubnets bool
+
+	// New subnet requests
+	SubnetTypes []string // empty means any
+	SubnetSize  IntRange
+
+	AutoApprove bool
+}
+
+func checkRange(name string, r IntRange, n int) []string {
+	if r.Contains(n) {
+		return nil
+	}
+	if r.Min == r.Max {
+		return []string{fmt.Sprintf("%s must be %d but %d was requested", name, r.Min, n)}
+	}
+	if r.Max == 0 {
+		return []string{fmt.Sprintf("%s must be at least %d but %d was requested", name, r.Min, n)}
+	}
+	if r.Min == 0 {
+		return []string{fmt.Sprintf("%s must be at most %d but %d was requested", name, r.Max, n)}
+	}
+	return []string{fmt.Sprintf("%s must be between %d and %d but %d was requested", name, r.Min, r.Max, n)}
+}
+
+func checkAllowed(name string, allowed []string, value string) []string {
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, a := range allowed {
+		if a == value {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("%s %q is not one of %s", name, value, strings.Join(allowed, ", "))}
+}
+
+// Violations lists the ways the requested config falls outside the template.
+func (t *VPCRequestTemplate) Violations(requestType RequestType, config *AllocateConfig) []string {
+	if requestType != t.RequestType {
+		return []string{fmt.Sprintf("Template %q is not for this type of request", t.Name)}
+	}
+	violations := checkAllowed("Region", t.Regions, config.AWSRegion)
+	if requestType == RequestTypeNewSubnet {
+		violations = append(violations, checkAllowed("Subnet type", t.SubnetTypes, config.SubnetType)...)
+		violations = append(violations, checkRange("Subnet size", t.SubnetSize, config.SubnetSize)...)
+		return violations
+	}
+	violations = append(violations, checkAllowed("Stack", t.Stacks, config.Stack)...)
+	violations = append(violations, checkRange("Number of AZs", t.NumAZs, len(config.AvailabilityZones))...)
+	violations = append(violations, checkRange("Number of private subnets", t.NumPrivateSubnets, config.NumPrivateSubnets)...)
+	violations = append(violations, checkRange("Private subnet size", t.PrivateSize, config.PrivateSize)...)
+	violations = append(violations, checkRange("Number of public subnets", t.NumPublicSubnets, config.NumPublicSubnets)...)
+	if config.NumPublicSubnets > 0 {
+		violations = append(violations, checkRange("Public subnet size", t.PublicSize, config.PublicSize)...)
+	}
+	if config.AddFirewall && !t.AllowFirewall {
+		violations = append(violations, "A firewall is not allowed by this template")
+	}
+	if config.AddContainersSubnets && !t.AllowContainersSubnets {
+		violations = append(violations, "Container subnets are not allowed by this template")
+	}
+	return violations
+}
+
+// AWS allows subnet prefix lengths from /16 to /28
+var validSubnetSizes = IntRange{Min: 16, Max: 28}
+
+// SubnetSizeViolations lists the requested subnet sizes AWS doesn't allow.
+func (c *AllocateConfig) SubnetSizeViolations(requestType RequestType) []string {
+	if requestType == RequestTypeNewSubnet {
+		return checkRange("Subnet size", validSubnetSizes, c.SubnetSize)
+	}
+	violations := checkRange("Private subnet size", validSubnetSizes, c.PrivateSize)
+	if c.NumPublicSubnets > 0 {
+		violations = append(violations, checkRange("Public subnet size", validSubnetSizes, c.PublicSize)...)
+	}
+	return violations
+}
+
+// SubnetIPs is the IPv4 space of n subnets of the given prefix length, or 0
+// if AWS doesn't allow that size.
+func SubnetIPs(n int64, size int) int64 {
+	if !validSubnetSizes.Contains(size) {
+		return 0
+	}
+	return n << uint(32-size)
+}
+
+// RequestedIPs is the IPv4 space a new VPC request needs.
+func (c *AllocateConfig) RequestedIPs() int64 {
+	ips := SubnetIPs(int64(c.NumPrivateSubnets), c.PrivateSize)
+	if c.NumPublicSubnets > 0 {
+		ips += SubnetIPs(int64(c.NumPublicSubnets), c.PublicSize)
+	}
+	return ips
+}
+
+type ProjectQuota struct {
+	ProjectName    string
+	MaxVPCs        int   // 0 means unlimited
+	MaxIPsPerStack int64 // 0 means unlimited
+}
+
+// ProjectUsage counts the non-deleted VPCs in a project's accounts, plus the
+// VPCs and subnets of approved requests that haven't been provisioned yet.
+type ProjectUsage struct {
+	NumVPCs    int
+	IPsByStack map[string]int64
+}
+
+// Violations lists the ways granting a request would exceed the quota.
+// newVPCs is 0 for requests that only add IP space to an existing VPC.
+func (q *ProjectQuota) Violations(usage *ProjectUsage, newVPCs int, stack string, requestedIPs int64) []string {
+	violations := []string{}
+	if q.MaxVPCs > 0 && newVPCs > 0 && usage.NumVPCs+newVPCs > q.MaxVPCs {
+		violations = append(violations, fmt.Sprintf("Project %s already has %d of its %d allowed VPCs", q.ProjectName, usage.NumVPCs, q.MaxVPCs))
+	}
+	if q.MaxIPsPerStack > 0 && usage.IPsByStack[stack]+requestedIPs > q.MaxIPsPerStack {
+		violations = append(violations, fmt.Sprintf("Project %s would use %d IPs in %s but is limited to %d", q.ProjectName, usage.IPsByStack[stack]+requestedIPs, stack, q.MaxIPsPerStack))
+	}
+	return violations
 }
 
 type JIRAHealth struct {
@@ -940,6 +1597,29 @@ type ModelsManager interface {
 	SetVPCRequestTaskID(id uint64, taskID uint64) error
 	SetVPCRequestStatus(id uint64, status VPCRequestStatus) error
 	SetVPCRequestProvisionedVPC(requestID uint64, region Region, vpcID string) error
+	SetVPCRequestJIRAFields(id uint64, assignee, rejectionReason string) error
+	SetVPCRequestTaskStatusNotified(id uint64, status TaskStatus) error
+	// An empty slice records that the request was evaluated and passed
+	SetVPCRequestPolicyViolations(id uint64, violations []string) error
+
+	GetVPCRequestTemplates() ([]*VPCRequestTemplate, error)
+	GetVPCRequestTemplate(id uint64) (*VPCRequestTemplate, error)
+	CreateVPCRequestTemplate(template *VPCRequestTemplate) error
+	UpdateVPCRequestTemplate(id uint64, template *VPCRequestTemplate) error
+	DeleteVPCRequestTemplate(id uint64) error
+
+	GetProjectQuotas() ([]*ProjectQuota, error)
+	// Returns nil if the project has no quota
+	GetProjectQuota(projectName string) (*ProjectQuota, error)
+	SetProjectQuota(quota *ProjectQuota) error
+	DeleteProjectQuota(projectName string) error
+	GetProjectUsage(projectName string) (*ProjectUsage, error)
+
+	GetVPCRequestComments(vpcRequestID uint64) ([]*VPCRequestComment, error)
+	// Local comments that haven't been posted to JIRA yet
+	GetUnsyncedVPCRequestComments() ([]*VPCRequestComment, error)
+	InsertVPCRequestComment(comment *VPCRequestComment) (uint64, error)
+	SetVPCRequestCommentJIRAID(id uint64, jiraCommentID string) error
 
 	GetVPCRequestLogs(vpcRequestID uint64) ([]*VPCRequestLog, error)
 	// VPCRequestLogInsert inserts an entry for the given vpc_request_id or
@@ -961,6 +1641,8 @@ type ModelsManager interface {
 	ListAutomatedVPCs() ([]*VPC, error)
 	// Region/state/config/issues are not filled out
 	ListExceptionVPCs() ([]*VPC, error)
+	// Non-deleted VPCs with at least one issue of the given type; state/config are not filled out
+	ListVPCsWithIssueType(issueType VerifyTypes) ([]*VPC, error)
 
 	UpdateVPCConfig(region Region, vpcID string, config VPCConfig) error
 
@@ -974,6 +1656,13 @@ type ModelsManager interface {
 	DeleteAccountLabel(accountID string, label string) error
 
 	ListBatchVPCLabels() ([]*VPCLabel, error)
+	// Non-deleted and non-exception only
+	ListVPCSelectorInfo() ([]*VPCSelectorInfo, error)
+	GetVPCSelectors() ([]*VPCSelector, error)
+	GetVPCSelector(name string) (*VPCSelector, error)
+	// Will be identified by name; ID and UpdatedAt fields will be set
+	CreateOrUpdateVPCSelector(selector *VPCSelector) error
+	DeleteVPCSelector(name string) error
 
 	GetManagedTransitGatewayAttachments() ([]*ManagedTransitGatewayAttachment, error)
 	// ID field will be set
@@ -993,6 +1682,48 @@ type ModelsManager interface {
 	DeleteManagedResolverRuleSet(id uint64) error
 
 	GetSecurityGroupSets() ([]*SecurityGroupSet, error)
+	GetVPCEndpointSets() ([]*VPCEndpointSet, error)
+	SaveSecurityGroupUsage(usage *SecurityGroupUsage) error
+	GetSecurityGroupUsage(templateID uint64) ([]*SecurityGroupUsage, error)
+	// ID and CreatedAt fields will be set
+	CreateRouteTableSnapshot(snapshot *RouteTableSnapshot) error
+	// Most recent first
+	GetRouteTableSnapshots(region Region, vpcID string) ([]*RouteTableSnapshot, error)
+	GetRouteTableSnapshot(id uint64) (*RouteTableSnapshot, error)
+	// Deletes all but the keep most recent snapshots of the VPC
+	PruneRouteTableSnapshots(region Region, vpcID string, keep int) (int64, error)
+	SaveRouteTableCapacity(capacity *VPCRouteTableCapacity) error
+	GetRouteTableCapacities() ([]*VPCRouteTableCapacity, error)
+	GetSharedServiceEndpoints() ([]*SharedServiceEndpoint, error)
+	GetSharedServiceEndpointByName(name string) (*SharedServiceEndpoint, error)
+	// ID field will be set
+	CreateSharedServiceEndpoint(endpoint *SharedServiceEndpoint) error
+	DeleteSharedServiceEndpoint(id uint64) error
+	// ID and CreatedAt fields will be set
+	CreateConnectivityTestResult(result *ConnectivityTestResult) error
+	// Most recent first
+	GetConnectivityTestResults(region Region, vpcID string) ([]*ConnectivityTestResult, error)
+	// Replaces the egress IPs of a VPC and records an event for each one
+	// added or removed. The events are returned.
+	UpdateEgressIPs(region Region, vpcID string, ips []*EgressIP, taskID *uint64) ([]*EgressIPEvent, error)
+	GetEgressIPs(filter *EgressIPFilter) ([]*EgressIP, error)
+	// Most recent first
+	GetEgressIPEvents(filter *EgressIPFilter) ([]*EgressIPEvent, error)
+	GetFirewallPolicyTemplates() ([]*FirewallPolicyTemplate, error)
+	GetFirewallPolicyTemplate(id uint64) (*FirewallPolicyTemplate, error)
+	CreateFirewallPolicyTemplate(template *FirewallPolicyTemplate) error
+	UpdateFirewallPolicyTemplate(id uint64, template *FirewallPolicyTemplate) error
+	DeleteFirewallPolicyTemplate(id uint64) error
+	GetVPNConnections() ([]*VPNConnection, error)
+	GetVPNConnection(id uint64) (*VPNConnection, error)
+	// ID field will be set
+	CreateVPNConnection(vpn *VPNConnection) error
+	UpdateVPNConnection(id uint64, vpn *VPNConnection) error
+	UpdateVPNConnectionState(id uint64, state *VPNConnectionState) error
+	DeleteVPNConnection(id uint64) error
+	CreateVPCEndpointSet(*VPCEndpointSet) error
+	UpdateVPCEndpointSet(id uint64, set *VPCEndpointSet) error
+	DeleteVPCEndpointSet(id uint64) error
 	// ID fields will be set
 	CreateSecurityGroupSet(*SecurityGroupSet) error
 	UpdateSecurityGroupSet(id uint64, set *SecurityGroupSet) error
@@ -1265,16 +1996,119 @@ func (m *SQLModelsManager) ListBatchVPCLabels() ([]*VPCLabel, error) {
 	return vpcLabels, nil
 }
 
+func (m *SQLModelsManager) ListVPCSelectorInfo() ([]*VPCSelectorInfo, error) {
+	q := `
+		SELECT
+			vpc.aws_id,
+			vpc.name,
+			vpc.stack,
+			vpc.aws_region,
+			(vpc.state->>'VPCType')::integer,
+			aws_account.aws_id,
+			aws_account.name,
+			aws_account.project_name,
+			ARRAY(
+				SELECT label.name FROM vpc_label INNER JOIN label ON label.id=vpc_label.label_id WHERE vpc_label.vpc_id=vpc.id
+				UNION
+				SELECT label.name FROM account_label INNER JOIN label ON label.id=account_label.label_id WHERE account_label.account_id=aws_account.id
+			),
+			COALESCE(vpc.config->'ManagedTransitGatewayAttachmentIDs', '[]')
+		FROM vpc
+		INNER JOIN aws_account ON vpc.aws_account_id=aws_account.id
+		WHERE (vpc.state->>'VPCType')::integer != $1 AND NOT vpc.is_deleted
+		ORDER BY vpc.name ASC`
+	rows, err := m.DB.Query(q, VPCTypeException)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	infos := []*VPCSelectorInfo{}
+	for rows.Next() {
+		info := &VPCSelectorInfo{}
+		var mtgaIDs []byte
+		err := rows.Scan(&info.ID, &info.Name, &info.Stack, &info.Region, &info.VPCType, &info.AccountID, &info.AccountName, &info.ProjectName, pq.Array(&info.Labels), &mtgaIDs)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(mtgaIDs, &info.ManagedTransitGatewayAttachmentIDs)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling MTGA IDs of %s: %s", info.ID, err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+const vpcSelectorSelect = `SELECT id, name, expression, description, updated_at FROM vpc_selector`
+
+func scanVPCSelector(row interface{ Scan(...interface{}) error }) (*VPCSelector, error) {
+	selector := &VPCSelector{}
+	err := row.Scan(&selector.ID, &selector.Name, &selector.Expression, &selector.Description, &selector.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return selector, nil
+}
+
+func (m *SQLModelsManager) GetVPCSelectors() ([]*VPCSelector, error) {
+	rows, err := m.DB.Query(vpcSelectorSelect + " ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	selectors := []*VPCSelector{}
+	for rows.Next() {
+		selector, err := scanVPCSelector(rows)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, rows.Err()
+}
+
+func (m *SQLModelsManager) GetVPCSelector(name string) (*VPCSelector, error) {
+	return scanVPCSelector(m.DB.QueryRow(vpcSelectorSelect+" WHERE name=$1", name))
+}
+
+func (m *SQLModelsManager) CreateOrUpdateVPCSelector(selector *VPCSelector) error {
+	q := `
+		INSERT INTO vpc_selector
+			(name, expression, description)
+		VALUES ($1, $2, $3)
+		ON CONFLICT(name) DO UPDATE SET
+			expression=EXCLUDED.expression,
+			description=EXCLUDED.description,
+			updated_at=NOW()
+		RETURNING id, updated_at`
+	return m.DB.QueryRow(q, selector.Name, selector.Expression, selector.Description).Scan(&selector.ID, &selector.UpdatedAt)
+}
+
+func (m *SQLModelsManager) DeleteVPCSelector(name string) error {
+	_, err := m.DB.Exec("DELETE FROM vpc_selector WHERE name=$1", name)
+	return err
+}
+
+// propagateRouteTableIDs is never NULL in the database
+func (mtga *ManagedTransitGatewayAttachment) propagateRouteTableIDs() []string {
+	if mtga.PropagateRouteTableIDs == nil {
+		return []string{}
+	}
+	return mtga.PropagateRouteTableIDs
+}
+
 func (m *SQLModelsManager) CreateManagedTransitGatewayAttachment(mtga *ManagedTransitGatewayAttachment) error {
-	q := "INSERT INTO managed_transit_gateway_attachment (transit_gateway_id, region, is_gov_cloud, name, routes, subnet_types, is_default) VALUES (:transitGatewayID, :region, :isGovCloud, :name, :routes, :subnetTypes, :isDefault) RETURNING id"
+	q := "INSERT INTO managed_transit_gateway_attachment (transit_gateway_id, region, is_gov_cloud, name, routes, subnet_types, is_default, associate_route_table_id, propagate_route_table_ids) VALUES (:transitGatewayID, :region, :isGovCloud, :name, :routes, :subnetTypes, :isDefault, :associateRouteTableID, :propagateRouteTableIDs) RETURNING id"
 	rewritten, args, err := m.DB.BindNamed(q, map[string]interface{}{
-		"transitGatewayID": mtga.TransitGatewayID,
-		"region":           mtga.Region,
-		"isGovCloud":       mtga.IsGovCloud,
-		"name":             mtga.Name,
-		"routes":           pq.Array(mtga.Routes),
-		"subnetTypes":      pq.Array(mtga.SubnetTypes),
-		"isDefault":        mtga.IsDefault,
+		"transitGatewayID":       mtga.TransitGatewayID,
+		"region":                 mtga.Region,
+		"isGovCloud":             mtga.IsGovCloud,
+		"name":                   mtga.Name,
+		"routes":                 pq.Array(mtga.Routes),
+		"subnetTypes":            pq.Array(mtga.SubnetTypes),
+		"isDefault":              mtga.IsDefault,
+		"associateRouteTableID":  mtga.AssociateRouteTableID,
+		"propagateRouteTableIDs": pq.Array(mtga.propagateRouteTableIDs()),
 	})
 	if err != nil {
 		return err
@@ -1290,16 +2124,18 @@ func (m *SQLModelsManager) UpdateManagedTransitGatewayAttachment(id uint64, mtga
 	if mtga.ID != 0 && mtga.ID != id {
 		return errors.New("Updating ID is not supported")
 	}
-	q := "UPDATE managed_transit_gateway_attachment SET transit_gateway_id=:transitGatewayID, region=:region, is_gov_cloud=:isGovCloud, name=:name, routes=:routes, subnet_types=:subnetTypes, is_default=:isDefault WHERE id=:id"
+	q := "UPDATE managed_transit_gateway_attachment SET transit_gateway_id=:transitGatewayID, region=:region, is_gov_cloud=:isGovCloud, name=:name, routes=:routes, subnet_types=:subnetTypes, is_default=:isDefault, associate_route_table_id=:associateRouteTableID, propagate_route_table_ids=:propagateRouteTableIDs WHERE id=:id"
 	_, err := m.DB.NamedExec(q, map[string]interface{}{
-		"transitGatewayID": mtga.TransitGatewayID,
-		"region":           mtga.Region,
-		"isGovCloud":       mtga.IsGovCloud,
-		"name":             mtga.Name,
-		"routes":           pq.Array(mtga.Routes),
-		"subnetTypes":      pq.Array(mtga.SubnetTypes),
-		"isDefault":        mtga.IsDefault,
-		"id":               id,
+		"transitGatewayID":       mtga.TransitGatewayID,
+		"region":                 mtga.Region,
+		"isGovCloud":             mtga.IsGovCloud,
+		"name":                   mtga.Name,
+		"routes":                 pq.Array(mtga.Routes),
+		"subnetTypes":            pq.Array(mtga.SubnetTypes),
+		"isDefault":              mtga.IsDefault,
+		"associateRouteTableID":  mtga.AssociateRouteTableID,
+		"propagateRouteTableIDs": pq.Array(mtga.propagateRouteTableIDs()),
+		"id":                     id,
 	})
 	return err
 }
@@ -1720,45 +2556,314 @@ func (m *SQLModelsManager) DeleteSecurityGroupSet(id uint64) error {
 	return err
 }
 
-func (m *SQLModelsManager) GetManagedResolverRuleSets() ([]*ManagedResolverRuleSet, error) {
+func (m *SQLModelsManager) GetVPCEndpointSets() ([]*VPCEndpointSet, error) {
 	q := `
-		SELECT
-			set.id, set.aws_region, set.name, set.aws_share_id, set.is_default, rule.id, COALESCE(rule.description, ''), COALESCE(rule.aws_id, ''), account.aws_id, account.is_gov_cloud,
-			(SELECT
-				array_agg(CONCAT(vpc.aws_region, '/', aws_account.aws_id, '/', vpc.aws_id, ' - ', vpc.name))
-			 FROM vpc
-			 INNER JOIN configured_managed_resolver_rule_set
-				ON vpc_id=vpc.id
-			 INNER JOIN aws_account
-			 	ON aws_account.id=vpc.aws_account_id
-			 WHERE managed_resolver_rule_set_id=set.id
-			)
-		FROM managed_resolver_rule_set set
-		INNER JOIN 
-			aws_account account
-			ON account.id=set.aws_account_id
-		LEFT JOIN
-			resolver_rule rule
-			ON rule.ruleset_id = set.id
-		ORDER BY set.id`
-	var currSet *ManagedResolverRuleSet
-	sets := []*ManagedResolverRuleSet{}
+	SELECT
+		set.id,
+		set.name,
+		set.is_default,
+		set.region,
+		set.is_gov_cloud,
+		ep.id,
+		COALESCE(ep.service_name, ''),
+		COALESCE(ep.endpoint_type, ''),
+		ep.subnet_types,
+		COALESCE(ep.security_group_template_id, 0),
+		COALESCE(ep.private_dns_enabled, false),
+		(SELECT
+			array_agg(CONCAT(vpc.aws_region, '/', aws_account.aws_id, '/', vpc.aws_id, ' - ', vpc.name))
+		 FROM vpc
+		 INNER JOIN configured_vpc_endpoint_set
+			ON vpc_id=vpc.id
+		 INNER JOIN aws_account
+			 ON aws_account.id=vpc.aws_account_id
+		 WHERE vpc_endpoint_set_id=set.id
+		)
+	FROM
+		vpc_endpoint_set set
+	LEFT JOIN
+		vpc_endpoint_template ep
+		ON ep.vpc_endpoint_set_id=set.id
+	ORDER BY set.id, ep.id
+	`
+	var currSet *VPCEndpointSet
+	sets := []*VPCEndpointSet{}
 	rows, err := m.DB.Queryx(q)
 	if err != nil {
 		return nil, err
 	}
 	for rows.Next() {
-		set := &ManagedResolverRuleSet{}
-		rule := &ResolverRule{}
-		var ruleID *uint64
+		set := &VPCEndpointSet{}
+		ep := &VPCEndpointTemplate{}
+		var epID *uint64
+		var subnetTypes []string
 		err := rows.Scan(
 			&set.ID,
-			&set.Region,
 			&set.Name,
-			&set.ResourceShareID,
 			&set.IsDefault,
-			&ruleID,
-			&rule.Description,
+			&set.Region,
+			&set.IsGovCloud,
+			&epID,
+			&ep.ServiceName,
+			&ep.Type,
+			pq.Array(&subnetTypes),
+			&ep.SecurityGroupTemplateID,
+			&ep.PrivateDNSEnabled,
+			pq.Array(&set.InUseVPCs),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if currSet == nil || currSet.ID != set.ID {
+			sets = append(sets, set)
+			currSet = set
+		}
+		if epID != nil {
+			ep.ID = *epID
+			for _, subnetType := range subnetTypes {
+				ep.SubnetTypes = append(ep.SubnetTypes, SubnetType(subnetType))
+			}
+			currSet.Endpoints = append(currSet.Endpoints, ep)
+		}
+	}
+	return sets, nil
+}
+
+func insertOrUpdateEndpointTemplates(tx *sqlx.Tx, set *VPCEndpointSet, allowUpdate bool) error {
+	for _, ep := range set.Endpoints {
+		subnetTypes := []string{}
+		for _, subnetType := range ep.SubnetTypes {
+			subnetTypes = append(subnetTypes, string(subnetType))
+		}
+		var sgTemplateID *uint64
+		if ep.SecurityGroupTemplateID != 0 {
+			sgTemplateID = &ep.SecurityGroupTemplateID
+		}
+		args := map[string]interface{}{
+			"id":                ep.ID,
+			"setID":             set.ID,
+			"serviceName":       ep.ServiceName,
+			"endpointType":      ep.Type,
+			"subnetTypes":       pq.Array(subnetTypes),
+			"sgTemplateID":      sgTemplateID,
+			"privateDNSEnabled": ep.PrivateDNSEnabled,
+		}
+		if allowUpdate && ep.ID > 0 {
+			q := `
+			UPDATE vpc_endpoint_template SET
+				service_name=:serviceName,
+				endpoint_type=:endpointType,
+				subnet_types=:subnetTypes,
+				security_group_template_id=:sgTemplateID,
+				private_dns_enabled=:privateDNSEnabled
+			WHERE id=:id AND vpc_endpoint_set_id=:setID`
+			_, err := tx.NamedExec(q, args)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		q := `
+		INSERT INTO vpc_endpoint_template
+			(
+				vpc_endpoint_set_id,
+				service_name,
+				endpoint_type,
+				subnet_types,
+				security_group_template_id,
+				private_dns_enabled
+			)
+			VALUES (
+				:setID,
+				:serviceName,
+				:endpointType,
+				:subnetTypes,
+				:sgTemplateID,
+				:privateDNSEnabled
+			)
+			RETURNING id`
+		rewritten, bound, err := tx.BindNamed(q, args)
+		if err != nil {
+			return err
+		}
+		err = tx.Get(&ep.ID, rewritten, bound...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *SQLModelsManager) CreateVPCEndpointSet(set *VPCEndpointSet) error {
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+	q := `
+		INSERT INTO vpc_endpoint_set (name, is_default, region, is_gov_cloud) VALUES (:name, :isDefault, :region, :isGovCloud) RETURNING id;
+	`
+	rewritten, args, err := tx.BindNamed(q, map[string]interface{}{
+		"name":       set.Name,
+		"isDefault":  set.IsDefault,
+		"region":     set.Region,
+		"isGovCloud": set.IsGovCloud,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Get(&set.ID, rewritten, args...)
+	if err != nil {
+		return err
+	}
+	err = insertOrUpdateEndpointTemplates(tx, set, false)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (m *SQLModelsManager) UpdateVPCEndpointSet(id uint64, set *VPCEndpointSet) error {
+	if set.ID != 0 && set.ID != id {
+		return fmt.Errorf("id arg does not match set ID")
+	}
+	set.ID = id
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec("SELECT id FROM vpc_endpoint_set WHERE id=$1 FOR UPDATE", id)
+	if err != nil {
+		return err
+	}
+	q := "UPDATE vpc_endpoint_set SET name=:name, is_default=:isDefault, region=:region, is_gov_cloud=:isGovCloud WHERE id=:id"
+	_, err = tx.NamedExec(q, map[string]interface{}{
+		"id":         id,
+		"name":       set.Name,
+		"isDefault":  set.IsDefault,
+		"region":     set.Region,
+		"isGovCloud": set.IsGovCloud,
+	})
+	if err != nil {
+		return err
+	}
+	keepIDs := []uint64{}
+	for _, ep := range set.Endpoints {
+		if ep.ID > 0 {
+			keepIDs = append(keepIDs, ep.ID)
+		}
+	}
+	if len(keepIDs) == 0 {
+		_, err = tx.Exec("DELETE FROM vpc_endpoint_template WHERE vpc_endpoint_set_id=$1", id)
+	} else {
+		q, args, inErr := m.getInQuery(
+			"DELETE FROM vpc_endpoint_template WHERE vpc_endpoint_set_id=:setID AND id NOT IN (:keepIDs)",
+			map[string]interface{}{
+				"setID":   id,
+				"keepIDs": keepIDs,
+			})
+		if inErr != nil {
+			return inErr
+		}
+		_, err = tx.Exec(q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	err = insertOrUpdateEndpointTemplates(tx, set, true)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (m *SQLModelsManager) DeleteVPCEndpointSet(id uint64) error {
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec("DELETE FROM vpc_endpoint_template WHERE vpc_endpoint_set_id=$1", id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM vpc_endpoint_set WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (m *SQLModelsManager) GetManagedResolverRuleSets() ([]*ManagedResolverRuleSet, error) {
+	q := `
+		SELECT
+			set.id, set.aws_region, set.name, set.aws_share_id, set.is_default, rule.id, COALESCE(rule.description, ''), COALESCE(rule.aws_id, ''), account.aws_id, account.is_gov_cloud,
+			(SELECT
+				array_agg(CONCAT(vpc.aws_region, '/', aws_account.aws_id, '/', vpc.aws_id, ' - ', vpc.name))
+			 FROM vpc
+			 INNER JOIN configured_managed_resolver_rule_set
+				ON vpc_id=vpc.id
+			 INNER JOIN aws_account
+			 	ON aws_account.id=vpc.aws_account_id
+			 WHERE managed_resolver_rule_set_id=set.id
+			)
+		FROM managed_resolver_rule_set set
+		INNER JOIN 
+			aws_account account
+			ON account.id=set.aws_account_id
+		LEFT JOIN
+			resolver_rule rule
+			ON rule.ruleset_id = set.id
+		ORDER BY set.id`
+	var currSet *ManagedResolverRuleSet
+	sets := []*ManagedResolverRuleSet{}
+	rows, err := m.DB.Queryx(q)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		set := &ManagedResolverRuleSet{}
+		rule := &ResolverRule{}
+		var ruleID *uint64
+		err := rows.Scan(
+			&set.ID,
+			&set.Region,
+			&set.Name,
+			&set.ResourceShareID,
+			&set.IsDefault,
+			&ruleID,
+			&rule.Description,
 			&rule.AWSID,
 			&set.AccountID,
 			&set.IsGovCloud,
@@ -1892,6 +2997,7 @@ func (m *SQLModelsManager) GetManagedTransitGatewayAttachments() ([]*ManagedTran
 	q := `
 		SELECT
 			id, transit_gateway_id, region, is_gov_cloud, name, routes, subnet_types, is_default,
+			associate_route_table_id, propagate_route_table_ids,
 			(SELECT
 				array_agg(CONCAT(vpc.aws_region, '/', aws_account.aws_id, '/', vpc.aws_id, ' - ', vpc.name))
 			 FROM vpc
@@ -1918,6 +3024,8 @@ func (m *SQLModelsManager) GetManagedTransitGatewayAttachments() ([]*ManagedTran
 			pq.Array(&ma.Routes),
 			pq.Array(&ma.SubnetTypes),
 			&ma.IsDefault,
+			&ma.AssociateRouteTableID,
+			pq.Array(&ma.PropagateRouteTableIDs),
 			pq.Array(&ma.InUseVPCs))
 		if err != nil {
 			return nil, err
@@ -2503,6 +3611,35 @@ func (m *SQLModelsManager) UpdateVPCConfig(region Region, vpcID string, config V
 		return err
 	}
 
+	for _, vpceSetID := range config.VPCEndpointSetIDs {
+		q := "INSERT INTO configured_vpc_endpoint_set (vpc_id, vpc_endpoint_set_id) VALUES (:dbID, :vpceSetID) ON CONFLICT(vpc_id, vpc_endpoint_set_id) DO NOTHING"
+		_, err = tx.NamedExec(q, map[string]interface{}{
+			"dbID":      dbID,
+			"vpceSetID": vpceSetID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if len(config.VPCEndpointSetIDs) == 0 {
+		_, err = tx.Exec("DELETE FROM configured_vpc_endpoint_set WHERE vpc_id = $1", dbID)
+	} else {
+		var args []interface{}
+		q, args, err = m.getInQuery(
+			"DELETE FROM configured_vpc_endpoint_set WHERE vpc_id = :dbID AND vpc_endpoint_set_id NOT IN (:vpceSetIDs)",
+			map[string]interface{}{
+				"dbID":       dbID,
+				"vpceSetIDs": config.VPCEndpointSetIDs,
+			})
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(q, args...)
+	}
+	if err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -2666,6 +3803,44 @@ func (m *SQLModelsManager) ListExceptionVPCs() ([]*VPC, error) {
 	return vpcs, nil
 }
 
+func (m *SQLModelsManager) ListVPCsWithIssueType(issueType VerifyTypes) ([]*VPC, error) {
+	q := `
+	SELECT
+		vpc.aws_id,
+		aws_account.aws_id,
+		vpc.name,
+		vpc.stack,
+		vpc.aws_region,
+		vpc.issues
+	FROM vpc
+	INNER JOIN aws_account ON vpc.aws_account_id=aws_account.id
+	WHERE NOT vpc.is_deleted
+	AND EXISTS (SELECT 1 FROM jsonb_array_elements(vpc.issues) issue WHERE (issue->>'Type')::integer = $1)
+	ORDER BY vpc.name ASC`
+
+	rows, err := m.DB.Queryx(q, issueType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	vpcs := []*VPC{}
+	for rows.Next() {
+		vpc := &VPC{}
+		var issues []byte
+		err := rows.Scan(&vpc.ID, &vpc.AccountID, &vpc.Name, &vpc.Stack, &vpc.Region, &issues)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(issues, &vpc.Issues)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling issues for %s: %s", vpc.ID, err)
+		}
+		vpcs = append(vpcs, vpc)
+	}
+	return vpcs, rows.Err()
+}
+
 func (m *SQLModelsManager) GetAutomatedVPCsForAccount(region Region, accountID string) ([]*VPC, error) {
 	q := `
 	SELECT
@@ -2947,6 +4122,19 @@ func (m *SQLModelsManager) GetVPC(region Region, vpcID string) (*VPC, error) {
 		}
 		vpc.Config.SecurityGroupSetIDs = append(vpc.Config.SecurityGroupSetIDs, sgsID)
 	}
+	q = `SELECT vpc_endpoint_set_id FROM configured_vpc_endpoint_set WHERE vpc_id = $1`
+	rows, err = m.DB.Queryx(q, vpcDBID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		vpceSetID := uint64(0)
+		err := rows.Scan(&vpceSetID)
+		if err != nil {
+			return nil, err
+		}
+		vpc.Config.VPCEndpointSetIDs = append(vpc.Config.VPCEndpointSetIDs, vpceSetID)
+	}
 	q = `SELECT security_group_id, aws_id FROM created_security_group WHERE vpc_id = $1`
 	rows, err = m.DB.Queryx(q, vpcDBID)
 	if err != nil {
@@ -3053,14 +4241,989 @@ func (m *SQLModelsManager) SetVPCRequestProvisionedVPC(requestID uint64, region
 
 }
 
-func (m *SQLModelsManager) CreateVPCRequest(req *VPCRequest) error {
-	if req.AccountID != req.RequestedConfig.AccountID {
-		return fmt.Errorf("Inconsistent account IDs %q vs %q", req.AccountID, req.RequestedConfig.AccountID)
-	}
-	if req.ApprovedConfig != nil {
-		return errors.New("ApprovedConfig must be null")
-	}
-	requestedJSON, err := json.Marshal(req.RequestedConfig)
+func (m *SQLModelsManager) SetVPCRequestJIRAFields(id uint64, assignee, rejectionReason string) error {
+	q := "UPDATE vpc_request SET assignee=:assignee, rejection_reason=:rejectionReason WHERE id=:id"
+	_, err := m.DB.NamedExec(q, map[string]interface{}{
+		"id":              id,
+		"assignee":        assignee,
+		"rejectionReason": rejectionReason,
+	})
+	return err
+}
+
+func (m *SQLModelsManager) SetVPCRequestTaskStatusNotified(id uint64, status TaskStatus) error {
+	q := "UPDATE vpc_request SET task_status_notified=:status WHERE id=:id"
+	_, err := m.DB.NamedExec(q, map[string]interface{}{
+		"id":     id,
+		"status": status,
+	})
+	return err
+}
+
+func (m *SQLModelsManager) SetVPCRequestPolicyViolations(id uint64, violations []string) error {
+	if violations == nil {
+		violations = []string{}
+	}
+	q := "UPDATE vpc_request SET policy_violations=:violations WHERE id=:id"
+	_, err := m.DB.NamedExec(q, map[string]interface{}{
+		"id":         id,
+		"violations": pq.Array(violations),
+	})
+	return err
+}
+
+func (m *SQLModelsManager) GetVPCRequestTemplates() ([]*VPCRequestTemplate, error) {
+	q := "SELECT id, name, config FROM vpc_request_template ORDER BY name"
+	rows, err := m.DB.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	templates := []*VPCRequestTemplate{}
+	for rows.Next() {
+		template, err := scanVPCRequestTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+func (m *SQLModelsManager) GetVPCRequestTemplate(id uint64) (*VPCRequestTemplate, error) {
+	q := "SELECT id, name, config FROM vpc_request_template WHERE id=$1"
+	template, err := scanVPCRequestTemplate(m.DB.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("No VPC request template with id %d", id)
+	}
+	return template, err
+}
+
+func scanVPCRequestTemplate(row interface{ Scan(...interface{}) error }) (*VPCRequestTemplate, error) {
+	template := &VPCRequestTemplate{}
+	var id uint64
+	var name string
+	var config []byte
+	err := row.Scan(&id, &name, &config)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(config, template)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling template %d: %s", id, err)
+	}
+	template.ID = id
+	template.Name = name
+	return template, nil
+}
+
+func (m *SQLModelsManager) CreateVPCRequestTemplate(template *VPCRequestTemplate) error {
+	if template.Name == "" {
+		return errors.New("Name is required")
+	}
+	config, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	q := "INSERT INTO vpc_request_template (name, config) VALUES (:name, :config) RETURNING id"
+	rewritten, args, err := m.DB.BindNamed(q, map[string]interface{}{
+		"name":   template.Name,
+		"config": config,
+	})
+	if err != nil {
+		return err
+	}
+	return m.DB.Get(&template.ID, rewritten, args...)
+}
+
+func (m *SQLModelsManager) UpdateVPCRequestTemplate(id uint64, template *VPCRequestTemplate) error {
+	if template.ID != 0 && template.ID != id {
+		return errors.New("Updating ID is not supported")
+	}
+	if template.Name == "" {
+		return errors.New("Name is required")
+	}
+	template.ID = id
+	config, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	q := "UPDATE vpc_request_template SET name=:name, config=:config WHERE id=:id"
+	_, err = m.DB.NamedExec(q, map[string]interface{}{
+		"id":     id,
+		"name":   template.Name,
+		"config": config,
+	})
+	return err
+}
+
+func (m *SQLModelsManager) DeleteVPCRequestTemplate(id uint64) error {
+	// Requests keep their place in history but lose the reference
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+	_, err = tx.Exec("UPDATE vpc_request SET template_id=NULL WHERE template_id=$1", id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM vpc_request_template WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+const firewallPolicyTemplateSelect = `
+	SELECT
+		t.id,
+		t.name,
+		t.config,
+		(SELECT
+			array_agg(CONCAT(vpc.aws_region, '/', aws_account.aws_id, '/', vpc.aws_id, ' - ', vpc.name))
+		 FROM vpc
+		 INNER JOIN aws_account
+			 ON aws_account.id=vpc.aws_account_id
+		 WHERE NOT vpc.is_deleted AND (vpc.config->>'FirewallPolicyTemplateID')::bigint=t.id
+		)
+	FROM firewall_policy_template t`
+
+// SaveSecurityGroupUsage replaces any earlier analysis of the same template
+// in the same VPC.
+func (m *SQLModelsManager) SaveSecurityGroupUsage(usage *SecurityGroupUsage) error {
+	dbID, err := m.GetVPCDBID(usage.VPCID, usage.Region)
+	if err != nil {
+		return fmt.Errorf("Error getting VPC %s: %s", usage.VPCID, err)
+	}
+	rules, err := json.Marshal(usage.Rules)
+	if err != nil {
+		return err
+	}
+	q := `
+		INSERT INTO security_group_rule_usage
+			(vpc_id, security_group_id, window_start, window_end, rules)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vpc_id, security_group_id) DO UPDATE SET
+			window_start=EXCLUDED.window_start,
+			window_end=EXCLUDED.window_end,
+			rules=EXCLUDED.rules`
+	_, err = m.DB.Exec(q, *dbID, usage.TemplateID, usage.WindowStart, usage.WindowEnd, rules)
+	return err
+}
+
+func (m *SQLModelsManager) GetSecurityGroupUsage(templateID uint64) ([]*SecurityGroupUsage, error) {
+	q := `
+		SELECT vpc.aws_id, vpc.aws_region, u.window_start, u.window_end, u.rules
+		FROM security_group_rule_usage u
+		INNER JOIN vpc ON vpc.id=u.vpc_id
+		WHERE u.security_group_id=$1
+		ORDER BY vpc.aws_region, vpc.aws_id`
+	rows, err := m.DB.Query(q, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	usages := []*SecurityGroupUsage{}
+	for rows.Next() {
+		usage := &SecurityGroupUsage{TemplateID: templateID}
+		var rules []byte
+		err := rows.Scan(&usage.VPCID, &usage.Region, &usage.WindowStart, &usage.WindowEnd, &rules)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(rules, &usage.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling rule usage for %s: %s", usage.VPCID, err)
+		}
+		usages = append(usages, usage)
+	}
+	return usages, rows.Err()
+}
+
+func (m *SQLModelsManager) CreateRouteTableSnapshot(snapshot *RouteTableSnapshot) error {
+	dbID, err := m.GetVPCDBID(snapshot.VPCID, snapshot.Region)
+	if err != nil {
+		return fmt.Errorf("Error getting VPC %s: %s", snapshot.VPCID, err)
+	}
+	routeTables, err := json.Marshal(snapshot.RouteTables)
+	if err != nil {
+		return err
+	}
+	q := `
+		INSERT INTO route_table_snapshot
+			(vpc_id, reason, task_id, route_tables)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	return m.DB.QueryRow(q, *dbID, snapshot.Reason, snapshot.TaskID, routeTables).Scan(&snapshot.ID, &snapshot.CreatedAt)
+}
+
+const routeTableSnapshotSelect = `
+	SELECT s.id, vpc.aws_id, vpc.aws_region, s.created_at, s.reason, s.task_id, s.route_tables
+	FROM route_table_snapshot s
+	INNER JOIN vpc ON vpc.id=s.vpc_id`
+
+func scanRouteTableSnapshot(row interface{ Scan(...interface{}) error }) (*RouteTableSnapshot, error) {
+	snapshot := &RouteTableSnapshot{}
+	var routeTables []byte
+	err := row.Scan(&snapshot.ID, &snapshot.VPCID, &snapshot.Region, &snapshot.CreatedAt, &snapshot.Reason, &snapshot.TaskID, &routeTables)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(routeTables, &snapshot.RouteTables)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling route table snapshot %d: %s", snapshot.ID, err)
+	}
+	return snapshot, nil
+}
+
+func (m *SQLModelsManager) GetRouteTableSnapshots(region Region, vpcID string) ([]*RouteTableSnapshot, error) {
+	rows, err := m.DB.Query(routeTableSnapshotSelect+" WHERE vpc.aws_region=$1 AND vpc.aws_id=$2 ORDER BY s.created_at DESC, s.id DESC", region, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	snapshots := []*RouteTableSnapshot{}
+	for rows.Next() {
+		snapshot, err := scanRouteTableSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, rows.Err()
+}
+
+func (m *SQLModelsManager) GetRouteTableSnapshot(id uint64) (*RouteTableSnapshot, error) {
+	return scanRouteTableSnapshot(m.DB.QueryRow(routeTableSnapshotSelect+" WHERE s.id=$1", id))
+}
+
+func (m *SQLModelsManager) PruneRouteTableSnapshots(region Region, vpcID string, keep int) (int64, error) {
+	dbID, err := m.GetVPCDBID(vpcID, region)
+	if err != nil {
+		return 0, fmt.Errorf("Error getting VPC %s: %s", vpcID, err)
+	}
+	q := `
+		DELETE FROM route_table_snapshot
+		WHERE vpc_id=$1 AND id NOT IN (
+			SELECT id FROM route_table_snapshot
+			WHERE vpc_id=$1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		)`
+	result, err := m.DB.Exec(q, *dbID, keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// SaveRouteTableCapacity replaces any earlier capacity check of the same VPC.
+func (m *SQLModelsManager) SaveRouteTableCapacity(capacity *VPCRouteTableCapacity) error {
+	dbID, err := m.GetVPCDBID(capacity.VPCID, capacity.Region)
+	if err != nil {
+		return fmt.Errorf("Error getting VPC %s: %s", capacity.VPCID, err)
+	}
+	routeTables, err := json.Marshal(capacity.RouteTables)
+	if err != nil {
+		return err
+	}
+	q := `
+		INSERT INTO route_table_capacity
+			(vpc_id, updated_at, quota, route_tables)
+		VALUES ($1, NOW(), $2, $3)
+		ON CONFLICT (vpc_id) DO UPDATE SET
+			updated_at=EXCLUDED.updated_at,
+			quota=EXCLUDED.quota,
+			route_tables=EXCLUDED.route_tables`
+	_, err = m.DB.Exec(q, *dbID, capacity.Quota, routeTables)
+	return err
+}
+
+func (m *SQLModelsManager) GetRouteTableCapacities() ([]*VPCRouteTableCapacity, error) {
+	q := `
+		SELECT vpc.aws_id, vpc.aws_region, aws_account.aws_id, vpc.name, c.updated_at, c.quota, c.route_tables
+		FROM route_table_capacity c
+		INNER JOIN vpc ON vpc.id=c.vpc_id
+		INNER JOIN aws_account ON aws_account.id=vpc.aws_account_id
+		ORDER BY vpc.aws_region, vpc.aws_id`
+	rows, err := m.DB.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	capacities := []*VPCRouteTableCapacity{}
+	for rows.Next() {
+		capacity := &VPCRouteTableCapacity{}
+		var routeTables []byte
+		err := rows.Scan(&capacity.VPCID, &capacity.Region, &capacity.AccountID, &capacity.VPCName, &capacity.UpdatedAt, &capacity.Quota, &routeTables)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(routeTables, &capacity.RouteTables)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling route table capacity for %s: %s", capacity.VPCID, err)
+		}
+		capacities = append(capacities, capacity)
+	}
+	return capacities, rows.Err()
+}
+
+const sharedServiceEndpointSelect = `SELECT id, name, hostname, ip, port, description FROM shared_service_endpoint`
+
+func scanSharedServiceEndpoint(row interface{ Scan(...interface{}) error }) (*SharedServiceEndpoint, error) {
+	endpoint := &SharedServiceEndpoint{}
+	err := row.Scan(&endpoint.ID, &endpoint.Name, &endpoint.Hostname, &endpoint.IP, &endpoint.Port, &endpoint.Description)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (m *SQLModelsManager) GetSharedServiceEndpoints() ([]*SharedServiceEndpoint, error) {
+	rows, err := m.DB.Query(sharedServiceEndpointSelect + " ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	endpoints := []*SharedServiceEndpoint{}
+	for rows.Next() {
+		endpoint, err := scanSharedServiceEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+func (m *SQLModelsManager) GetSharedServiceEndpointByName(name string) (*SharedServiceEndpoint, error) {
+	return scanSharedServiceEndpoint(m.DB.QueryRow(sharedServiceEndpointSelect+" WHERE name=$1", name))
+}
+
+func (m *SQLModelsManager) CreateSharedServiceEndpoint(endpoint *SharedServiceEndpoint) error {
+	q := `
+		INSERT INTO shared_service_endpoint
+			(name, hostname, ip, port, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+	return m.DB.QueryRow(q, endpoint.Name, endpoint.Hostname, endpoint.IP, endpoint.Port, endpoint.Description).Scan(&endpoint.ID)
+}
+
+func (m *SQLModelsManager) DeleteSharedServiceEndpoint(id uint64) error {
+	_, err := m.DB.Exec("DELETE FROM shared_service_endpoint WHERE id=$1", id)
+	return err
+}
+
+func (m *SQLModelsManager) CreateConnectivityTestResult(result *ConnectivityTestResult) error {
+	dbID, err := m.GetVPCDBID(result.VPCID, result.Region)
+	if err != nil {
+		return fmt.Errorf("Error getting VPC %s: %s", result.VPCID, err)
+	}
+	spec, err := json.Marshal(result.Spec)
+	if err != nil {
+		return err
+	}
+	q := `
+		INSERT INTO connectivity_test_result
+			(vpc_id, task_id, spec, succeeded, error, resources_not_rolled_back)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	return m.DB.QueryRow(q, *dbID, result.TaskID, spec, result.Succeeded, result.Error, pq.Array(result.ResourcesNotRolledBack)).Scan(&result.ID, &result.CreatedAt)
+}
+
+func (m *SQLModelsManager) GetConnectivityTestResults(region Region, vpcID string) ([]*ConnectivityTestResult, error) {
+	q := `
+		SELECT r.id, vpc.aws_id, vpc.aws_region, r.task_id, r.created_at, r.spec, r.succeeded, r.error, r.resources_not_rolled_back
+		FROM connectivity_test_result r
+		INNER JOIN vpc ON vpc.id=r.vpc_id
+		WHERE vpc.aws_region=$1 AND vpc.aws_id=$2
+		ORDER BY r.created_at DESC, r.id DESC`
+	rows, err := m.DB.Query(q, region, vpcID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []*ConnectivityTestResult{}
+	for rows.Next() {
+		result := &ConnectivityTestResult{}
+		var spec []byte
+		err := rows.Scan(&result.ID, &result.VPCID, &result.Region, &result.TaskID, &result.CreatedAt, &spec, &result.Succeeded, &result.Error, pq.Array(&result.ResourcesNotRolledBack))
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(spec, &result.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling connectivity test %d: %s", result.ID, err)
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (m *SQLModelsManager) UpdateEgressIPs(region Region, vpcID string, ips []*EgressIP, taskID *uint64) ([]*EgressIPEvent, error) {
+	dbID, err := m.GetVPCDBID(vpcID, region)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting VPC %s: %s", vpcID, err)
+	}
+	tx, err := m.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	var accountID string
+	err = tx.Get(&accountID, `SELECT aws_account.aws_id FROM vpc INNER JOIN aws_account ON aws_account.id=vpc.aws_account_id WHERE vpc.id=$1`, *dbID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`SELECT allocation_id, public_ip, az_name, nat_gateway_id FROM egress_ip WHERE vpc_id=$1 FOR UPDATE`, *dbID)
+	if err != nil {
+		return nil, err
+	}
+	previous := []*EgressIP{}
+	for rows.Next() {
+		ip := &EgressIP{}
+		err := rows.Scan(&ip.AllocationID, &ip.PublicIP, &ip.AZName, &ip.NATGatewayID)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		previous = append(previous, ip)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	added, removed := DiffEgressIPs(previous, ips)
+	events := []*EgressIPEvent{}
+	record := func(ip *EgressIP, eventType EgressIPEventType) error {
+		event := &EgressIPEvent{
+			Type:         eventType,
+			VPCID:        vpcID,
+			Region:       region,
+			AccountID:    accountID,
+			AZName:       ip.AZName,
+			AllocationID: ip.AllocationID,
+			PublicIP:     ip.PublicIP,
+			TaskID:       taskID,
+		}
+		q := `
+			INSERT INTO egress_ip_event
+				(event_type, aws_region, vpc_aws_id, account_aws_id, az_name, allocation_id, public_ip, task_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at`
+		err := tx.QueryRow(q, event.Type, event.Region, event.VPCID, event.AccountID, event.AZName, event.AllocationID, event.PublicIP, event.TaskID).Scan(&event.ID, &event.CreatedAt)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	}
+	for _, ip := range removed {
+		_, err := tx.Exec(`DELETE FROM egress_ip WHERE vpc_id=$1 AND allocation_id=$2`, *dbID, ip.AllocationID)
+		if err != nil {
+			return nil, err
+		}
+		err = record(ip, EgressIPEventTypeRemoved)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, ip := range added {
+		err := record(ip, EgressIPEventTypeAdded)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, ip := range ips {
+		q := `
+			INSERT INTO egress_ip
+				(vpc_id, allocation_id, public_ip, az_name, nat_gateway_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (vpc_id, allocation_id) DO UPDATE SET
+				public_ip=EXCLUDED.public_ip,
+				az_name=EXCLUDED.az_name,
+				nat_gateway_id=EXCLUDED.nat_gateway_id`
+		_, err := tx.Exec(q, *dbID, ip.AllocationID, ip.PublicIP, ip.AZName, ip.NATGatewayID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	committed = true
+	return events, nil
+}
+
+func (m *SQLModelsManager) GetEgressIPs(filter *EgressIPFilter) ([]*EgressIP, error) {
+	q := `
+		SELECT vpc.aws_id, vpc.aws_region, aws_account.aws_id, vpc.name, aws_account.project_name, e.az_name, e.nat_gateway_id, e.allocation_id, e.public_ip, e.first_seen_at
+		FROM egress_ip e
+		INNER JOIN vpc ON vpc.id=e.vpc_id
+		INNER JOIN aws_account ON aws_account.id=vpc.aws_account_id
+		WHERE NOT vpc.is_deleted
+			AND ($1='' OR vpc.aws_region=$1)
+			AND ($2='' OR vpc.aws_id=$2)
+			AND ($3='' OR aws_account.aws_id=$3)
+			AND ($4='' OR aws_account.project_name=$4)
+		ORDER BY aws_account.project_name, aws_account.aws_id, vpc.aws_region, vpc.aws_id, e.az_name`
+	rows, err := m.DB.Query(q, filter.Region, filter.VPCID, filter.AccountID, filter.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ips := []*EgressIP{}
+	for rows.Next() {
+		ip := &EgressIP{}
+		err := rows.Scan(&ip.VPCID, &ip.Region, &ip.AccountID, &ip.VPCName, &ip.ProjectName, &ip.AZName, &ip.NATGatewayID, &ip.AllocationID, &ip.PublicIP, &ip.FirstSeenAt)
+		if err != nil {
+			return nil, err
+		}
+		ips = append(ips, ip)
+	}
+	return ips, rows.Err()
+}
+
+func (m *SQLModelsManager) GetEgressIPEvents(filter *EgressIPFilter) ([]*EgressIPEvent, error) {
+	q := `
+		SELECT e.id, e.created_at, e.event_type, e.vpc_aws_id, e.aws_region, e.account_aws_id, COALESCE(aws_account.project_name, ''), e.az_name, e.allocation_id, e.public_ip, e.task_id
+		FROM egress_ip_event e
+		LEFT JOIN aws_account ON aws_account.aws_id=e.account_aws_id
+		WHERE ($1='' OR e.aws_region=$1)
+			AND ($2='' OR e.vpc_aws_id=$2)
+			AND ($3='' OR e.account_aws_id=$3)
+			AND ($4='' OR aws_account.project_name=$4)
+		ORDER BY e.created_at DESC, e.id DESC`
+	rows, err := m.DB.Query(q, filter.Region, filter.VPCID, filter.AccountID, filter.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []*EgressIPEvent{}
+	for rows.Next() {
+		event := &EgressIPEvent{}
+		err := rows.Scan(&event.ID, &event.CreatedAt, &event.Type, &event.VPCID, &event.Region, &event.AccountID, &event.ProjectName, &event.AZName, &event.AllocationID, &event.PublicIP, &event.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (m *SQLModelsManager) GetFirewallPolicyTemplates() ([]*FirewallPolicyTemplate, error) {
+	rows, err := m.DB.Query(firewallPolicyTemplateSelect + " ORDER BY t.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	templates := []*FirewallPolicyTemplate{}
+	for rows.Next() {
+		template, err := scanFirewallPolicyTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+func (m *SQLModelsManager) GetFirewallPolicyTemplate(id uint64) (*FirewallPolicyTemplate, error) {
+	template, err := scanFirewallPolicyTemplate(m.DB.QueryRow(firewallPolicyTemplateSelect+" WHERE t.id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("No firewall policy template with id %d", id)
+	}
+	return template, err
+}
+
+func scanFirewallPolicyTemplate(row interface{ Scan(...interface{}) error }) (*FirewallPolicyTemplate, error) {
+	template := &FirewallPolicyTemplate{}
+	var id uint64
+	var name string
+	var config []byte
+	var inUseVPCs []string
+	err := row.Scan(&id, &name, &config, pq.Array(&inUseVPCs))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(config, template)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling firewall policy template %d: %s", id, err)
+	}
+	template.ID = id
+	template.Name = name
+	template.InUseVPCs = inUseVPCs
+	return template, nil
+}
+
+func (m *SQLModelsManager) CreateFirewallPolicyTemplate(template *FirewallPolicyTemplate) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	template.InUseVPCs = nil
+	config, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	q := "INSERT INTO firewall_policy_template (name, config) VALUES (:name, :config) RETURNING id"
+	rewritten, args, err := m.DB.BindNamed(q, map[string]interface{}{
+		"name":   template.Name,
+		"config": config,
+	})
+	if err != nil {
+		return err
+	}
+	return m.DB.Get(&template.ID, rewritten, args...)
+}
+
+func (m *SQLModelsManager) UpdateFirewallPolicyTemplate(id uint64, template *FirewallPolicyTemplate) error {
+	if template.ID != 0 && template.ID != id {
+		return errors.New("Updating ID is not supported")
+	}
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	template.ID = id
+	template.InUseVPCs = nil
+	config, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	q := "UPDATE firewall_policy_template SET name=:name, config=:config WHERE id=:id"
+	_, err = m.DB.NamedExec(q, map[string]interface{}{
+		"id":     id,
+		"name":   template.Name,
+		"config": config,
+	})
+	return err
+}
+
+func (m *SQLModelsManager) DeleteFirewallPolicyTemplate(id uint64) error {
+	template, err := m.GetFirewallPolicyTemplate(id)
+	if err != nil {
+		return err
+	}
+	if len(template.InUseVPCs) > 0 {
+		return fmt.Errorf("Firewall policy template is in use by %s", strings.Join(template.InUseVPCs, ", "))
+	}
+	_, err = m.DB.Exec("DELETE FROM firewall_policy_template WHERE id=$1", id)
+	return err
+}
+
+const vpnConnectionSelect = `
+	SELECT
+		t.id,
+		t.name,
+		t.config,
+		t.state,
+		(SELECT
+			array_agg(CONCAT(vpc.aws_region, '/', aws_account.aws_id, '/', vpc.aws_id, ' - ', vpc.name))
+		 FROM vpc
+		 INNER JOIN aws_account
+			 ON aws_account.id=vpc.aws_account_id
+		 WHERE NOT vpc.is_deleted AND vpc.config->'VPNConnectionIDs' @> to_jsonb(t.id)
+		)
+	FROM vpn_connection t`
+
+func (m *SQLModelsManager) GetVPNConnections() ([]*VPNConnection, error) {
+	rows, err := m.DB.Query(vpnConnectionSelect + " ORDER BY t.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	vpns := []*VPNConnection{}
+	for rows.Next() {
+		vpn, err := scanVPNConnection(rows)
+		if err != nil {
+			return nil, err
+		}
+		vpns = append(vpns, vpn)
+	}
+	return vpns, rows.Err()
+}
+
+func (m *SQLModelsManager) GetVPNConnection(id uint64) (*VPNConnection, error) {
+	vpn, err := scanVPNConnection(m.DB.QueryRow(vpnConnectionSelect+" WHERE t.id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("No VPN connection with id %d", id)
+	}
+	return vpn, err
+}
+
+func scanVPNConnection(row interface{ Scan(...interface{}) error }) (*VPNConnection, error) {
+	vpn := &VPNConnection{}
+	var id uint64
+	var name string
+	var config, state []byte
+	var inUseVPCs []string
+	err := row.Scan(&id, &name, &config, &state, pq.Array(&inUseVPCs))
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(config, vpn)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshaling VPN connection %d: %s", id, err)
+	}
+	vpn.State = nil
+	if state != nil {
+		vpn.State = &VPNConnectionState{}
+		err = json.Unmarshal(state, vpn.State)
+		if err != nil {
+			return nil, fmt.Errorf("Error unmarshaling state of VPN connection %d: %s", id, err)
+		}
+	}
+	vpn.ID = id
+	vpn.Name = name
+	vpn.InUseVPCs = inUseVPCs
+	return vpn, nil
+}
+
+func vpnConnectionConfigJSON(vpn *VPNConnection) ([]byte, error) {
+	config := *vpn
+	config.InUseVPCs = nil
+	config.State = nil
+	return json.Marshal(config)
+}
+
+func (m *SQLModelsManager) CreateVPNConnection(vpn *VPNConnection) error {
+	if vpn.Name == "" {
+		return errors.New("Name is required")
+	}
+	config, err := vpnConnectionConfigJSON(vpn)
+	if err != nil {
+		return err
+	}
+	q := "INSERT INTO vpn_connection (name, config) VALUES (:name, :config) RETURNING id"
+	rewritten, args, err := m.DB.BindNamed(q, map[string]interface{}{
+		"name":   vpn.Name,
+		"config": config,
+	})
+	if err != nil {
+		return err
+	}
+	return m.DB.Get(&vpn.ID, rewritten, args...)
+}
+
+// UpdateVPNConnection only changes the configuration; the state is left as
+// it is for the next task to reconcile.
+func (m *SQLModelsManager) UpdateVPNConnection(id uint64, vpn *VPNConnection) error {
+	if vpn.ID != 0 && vpn.ID != id {
+		return errors.New("Updating ID is not supported")
+	}
+	if vpn.Name == "" {
+		return errors.New("Name is required")
+	}
+	vpn.ID = id
+	config, err := vpnConnectionConfigJSON(vpn)
+	if err != nil {
+		return err
+	}
+	q := "UPDATE vpn_connection SET name=:name, config=:config WHERE id=:id"
+	_, err = m.DB.NamedExec(q, map[string]interface{}{
+		"id":     id,
+		"name":   vpn.Name,
+		"config": config,
+	})
+	return err
+}
+
+func (m *SQLModelsManager) UpdateVPNConnectionState(id uint64, state *VPNConnectionState) error {
+	var buf []byte
+	if state != nil {
+		var err error
+		buf, err = json.Marshal(state)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := m.DB.Exec("UPDATE vpn_connection SET state=$1 WHERE id=$2", buf, id)
+	return err
+}
+
+// DeleteVPNConnection refuses to delete a connection that VPCs still route
+// to or whose AWS resources still exist.
+func (m *SQLModelsManager) DeleteVPNConnection(id uint64) error {
+	vpn, err := m.GetVPNConnection(id)
+	if err != nil {
+		return err
+	}
+	if len(vpn.InUseVPCs) > 0 {
+		return fmt.Errorf("VPN connection is in use by %s", strings.Join(vpn.InUseVPCs, ", "))
+	}
+	if vpn.State != nil && (vpn.State.VPNConnectionID != "" || vpn.State.CustomerGatewayID != "") {
+		return fmt.Errorf("VPN connection %s still has AWS resources", vpn.Name)
+	}
+	_, err = m.DB.Exec("DELETE FROM vpn_connection WHERE id=$1", id)
+	return err
+}
+
+func (m *SQLModelsManager) GetProjectQuotas() ([]*ProjectQuota, error) {
+	quotas := []*ProjectQuota{}
+	q := "SELECT project_name, max_vpcs, max_ips_per_stack FROM project_quota ORDER BY project_name"
+	rows, err := m.DB.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		quota := &ProjectQuota{}
+		err := rows.Scan(&quota.ProjectName, &quota.MaxVPCs, &quota.MaxIPsPerStack)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, quota)
+	}
+	return quotas, rows.Err()
+}
+
+func (m *SQLModelsManager) GetProjectQuota(projectName string) (*ProjectQuota, error) {
+	quota := &ProjectQuota{}
+	q := "SELECT project_name, max_vpcs, max_ips_per_stack FROM project_quota WHERE project_name=$1"
+	err := m.DB.QueryRow(q, projectName).Scan(&quota.ProjectName, &quota.MaxVPCs, &quota.MaxIPsPerStack)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+func (m *SQLModelsManager) SetProjectQuota(quota *ProjectQuota) error {
+	q := `
+		INSERT INTO project_quota
+			(project_name, max_vpcs, max_ips_per_stack)
+		VALUES
+			(:projectName, :maxVPCs, :maxIPsPerStack)
+		ON CONFLICT (project_name) DO UPDATE SET
+			max_vpcs=:maxVPCs,
+			max_ips_per_stack=:maxIPsPerStack`
+	_, err := m.DB.NamedExec(q, map[string]interface{}{
+		"projectName":    quota.ProjectName,
+		"maxVPCs":        quota.MaxVPCs,
+		"maxIPsPerStack": quota.MaxIPsPerStack,
+	})
+	return err
+}
+
+func (m *SQLModelsManager) DeleteProjectQuota(projectName string) error {
+	_, err := m.DB.Exec("DELETE FROM project_quota WHERE project_name=$1", projectName)
+	return err
+}
+
+func (m *SQLModelsManager) GetProjectUsage(projectName string) (*ProjectUsage, error) {
+	q := `
+		SELECT
+			vpc.stack,
+			count(DISTINCT vpc.id),
+			coalesce(sum(2 ^ (32 - masklen(vpc_cidr.cidr))), 0)::bigint
+		FROM vpc
+		INNER JOIN aws_account ON aws_account.id=vpc.aws_account_id
+		LEFT JOIN vpc_cidr ON vpc_cidr.vpc_id=vpc.id AND family(vpc_cidr.cidr)=4
+		WHERE aws_account.project_name=$1 AND NOT vpc.is_deleted
+		GROUP BY vpc.stack`
+	rows, err := m.DB.Query(q, projectName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	usage := &ProjectUsage{
+		IPsByStack: map[string]int64{},
+	}
+	for rows.Next() {
+		var stack string
+		var numVPCs int
+		var ips int64
+		err := rows.Scan(&stack, &numVPCs, &ips)
+		if err != nil {
+			return nil, err
+		}
+		usage.NumVPCs += numVPCs
+		usage.IPsByStack[stack] = ips
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Approved requests whose VPCs or subnets don't exist yet
+	q = `
+		SELECT
+			vpc_request.request_type,
+			coalesce(vpc_request.approved_config, vpc_request.requested_config),
+			coalesce(vpc.stack, ''),
+			CASE
+				WHEN jsonb_typeof(vpc.state->'AvailabilityZones')='object'
+					THEN (SELECT count(*) FROM jsonb_object_keys(vpc.state->'AvailabilityZones'))
+				ELSE 0
+			END
+		FROM vpc_request
+		INNER JOIN aws_account ON aws_account.id=vpc_request.aws_account_id
+		LEFT JOIN task ON task.id=vpc_request.task_id
+		LEFT JOIN vpc ON
+			vpc.aws_id=coalesce(vpc_request.approved_config, vpc_request.requested_config)->>'VPCID'
+			AND vpc.aws_region=coalesce(vpc_request.approved_config, vpc_request.requested_config)->>'AWSRegion'
+			AND NOT vpc.is_deleted
+		WHERE aws_account.project_name=$1
+			AND vpc_request.status IN ($2, $3)
+			AND vpc_request.provisioned_vpc_id IS NULL
+			AND (task.id IS NULL OR task.status IN ($4, $5))`
+	pendingRows, err := m.DB.Query(q, projectName, StatusApproved, StatusInProgress, TaskStatusQueued, TaskStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer pendingRows.Close()
+	for pendingRows.Next() {
+		var requestType RequestType
+		var configBytes []byte
+		var vpcStack string
+		var numAZs int64
+		err := pendingRows.Scan(&requestType, &configBytes, &vpcStack, &numAZs)
+		if err != nil {
+			return nil, err
+		}
+		config := &AllocateConfig{}
+		err = json.Unmarshal(configBytes, config)
+		if err != nil {
+			return nil, err
+		}
+		if requestType == RequestTypeNewVPC {
+			usage.NumVPCs++
+			usage.IPsByStack[config.Stack] += config.RequestedIPs()
+		} else if vpcStack != "" {
+			usage.IPsByStack[vpcStack] += SubnetIPs(numAZs, config.SubnetSize)
+		}
+	}
+	return usage, pendingRows.Err()
+}
+
+func (m *SQLModelsManager) CreateVPCRequest(req *VPCRequest) error {
+	if req.AccountID != req.RequestedConfig.AccountID {
+		return fmt.Errorf("Inconsistent account IDs %q vs %q", req.AccountID, req.RequestedConfig.AccountID)
+	}
+	if req.ApprovedConfig != nil {
+		return errors.New("ApprovedConfig must be null")
+	}
+	requestedJSON, err := json.Marshal(req.RequestedConfig)
 	if err != nil {
 		return err
 	}
@@ -3077,7 +5240,8 @@ func (m *SQLModelsManager) CreateVPCRequest(req *VPCRequest) error {
 			 status,
 			 requested_config,
 			 task_id,
-			 jira_issue)
+			 jira_issue,
+			 template_id)
 		VALUES
 			((SELECT id FROM aws_account WHERE aws_id=:accountID),
 			 :requesterUID,
@@ -3090,7 +5254,8 @@ func (m *SQLModelsManager) CreateVPCRequest(req *VPCRequest) error {
 			 :status,
 			 :requestedConfig,
 			 :taskID,
-			 :jiraIssue)`
+			 :jiraIssue,
+			 :templateID)`
 	_, err = m.DB.NamedExec(q, map[string]interface{}{
 		"accountID":       req.AccountID,
 		"requesterUID":    req.RequesterUID,
@@ -3104,6 +5269,7 @@ func (m *SQLModelsManager) CreateVPCRequest(req *VPCRequest) error {
 		"requestedConfig": requestedJSON,
 		"taskID":          req.TaskID,
 		"jiraIssue":       req.JIRAIssue,
+		"templateID":      req.TemplateID,
 	})
 	return err
 }
@@ -3134,6 +5300,11 @@ func vpcRequestSelect(where string, forUpdate bool) string {
 		vpc_request.approved_config,
 		vpc_request.task_id,
 		vpc_request.jira_issue,
+		vpc_request.assignee,
+		vpc_request.rejection_reason,
+		vpc_request.task_status_notified,
+		vpc_request.template_id,
+		vpc_request.policy_violations,
 		provisioned_vpc.aws_id,
 		provisioned_vpc.aws_region,
 		task.status AS task_status,
@@ -3186,6 +5357,11 @@ func getVPCRequests(rows *sqlx.Rows) ([]*VPCRequest, error) {
 			&approvedBytes,
 			&req.TaskID,
 			&req.JIRAIssue,
+			&req.Assignee,
+			&req.RejectionReason,
+			&req.TaskStatusNotified,
+			&req.TemplateID,
+			pq.Array(&req.PolicyViolations),
 			&provisionedVPCID,
 			&provisionedVPCRegion,
 			&req.TaskStatus,
@@ -3359,6 +5535,75 @@ func (m *SQLModelsManager) GetVPCRequestLogs(vpcRequestID uint64) ([]*VPCRequest
 	return parseVPCRequestLogRows(rows)
 }
 
+func getVPCRequestComments(rows *sqlx.Rows) ([]*VPCRequestComment, error) {
+	defer rows.Close()
+	comments := []*VPCRequestComment{}
+	for rows.Next() {
+		comment := &VPCRequestComment{}
+		err := rows.Scan(
+			&comment.ID,
+			&comment.VPCRequestID,
+			&comment.AddedAt,
+			&comment.Author,
+			&comment.Body,
+			&comment.FromJIRA,
+			&comment.JIRACommentID)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+const vpcRequestCommentSelect = `SELECT id, vpc_request_id, added_at, author, body, from_jira, jira_comment_id FROM vpc_request_comment`
+
+func (m *SQLModelsManager) GetVPCRequestComments(vpcRequestID uint64) ([]*VPCRequestComment, error) {
+	q := vpcRequestCommentSelect + ` WHERE vpc_request_id = :vpcRequestID ORDER BY added_at ASC`
+	rows, err := m.DB.NamedQuery(q, map[string]interface{}{
+		"vpcRequestID": vpcRequestID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return getVPCRequestComments(rows)
+}
+
+func (m *SQLModelsManager) GetUnsyncedVPCRequestComments() ([]*VPCRequestComment, error) {
+	q := vpcRequestCommentSelect + ` WHERE NOT from_jira AND jira_comment_id IS NULL ORDER BY added_at ASC`
+	rows, err := m.DB.Queryx(q)
+	if err != nil {
+		return nil, err
+	}
+	return getVPCRequestComments(rows)
+}
+
+func (m *SQLModelsManager) InsertVPCRequestComment(comment *VPCRequestComment) (uint64, error) {
+	q := "INSERT INTO vpc_request_comment (vpc_request_id, author, body, from_jira, jira_comment_id) VALUES (:vpcRequestID, :author, :body, :fromJIRA, :jiraCommentID) RETURNING id"
+	rewritten, args, err := m.DB.BindNamed(q, map[string]interface{}{
+		"vpcRequestID":  comment.VPCRequestID,
+		"author":        comment.Author,
+		"body":          comment.Body,
+		"fromJIRA":      comment.FromJIRA,
+		"jiraCommentID": comment.JIRACommentID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var id uint64
+	err = m.DB.Get(&id, rewritten, args...)
+	return id, err
+}
+
+func (m *SQLModelsManager) SetVPCRequestCommentJIRAID(id uint64, jiraCommentID string) error {
+	q := "UPDATE vpc_request_comment SET jira_comment_id=:jiraCommentID WHERE id=:id"
+	_, err := m.DB.NamedExec(q, map[string]interface{}{
+		"id":            id,
+		"jiraCommentID": jiraCommentID,
+	})
+	return err
+}
+
 func (m *SQLModelsManager) GetJIRAHealth() (*JIRAHealth, error) {
 	health := &JIRAHealth{}
 	q := `SELECT COUNT(vrl.id)
@@ -3637,5 +5882,15 @@ func (m *SQLModelsManager) GetDefaultVPCConfig(region Region) (*VPCConfig, error
 		}
 	}
 
+	allVPCESets, err := m.GetVPCEndpointSets()
+	if err != nil {
+		return nil, fmt.Errorf("Error loading VPC endpoint sets: %s", err)
+	}
+	for _, set := range allVPCESets {
+		if set.Region == region && set.IsDefault {
+			config.VPCEndpointSetIDs = append(config.VPCEndpointSetIDs, set.ID)
+		}
+	}
+
 	return config, nil
 }
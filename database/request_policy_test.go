@@ -0,0 +1,107 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVPCRequestTemplateViolations(t *testing.T) {
+	template := &VPCRequestTemplate{
+		Name:              "Standard app VPC",
+		RequestType:       RequestTypeNewVPC,
+		Stacks:            []string{"dev", "impl"},
+		NumAZs:            IntRange{Min: 3, Max: 3},
+		PrivateSize:       IntRange{Min: 22, Max: 24},
+		PublicSize:        IntRange{Min: 26},
+		NumPrivateSubnets: IntRange{Max: 3},
+		NumPublicSubnets:  IntRange{Max: 3},
+	}
+	for _, tc := range []struct {
+		Name               string
+		RequestType        RequestType
+		Config             AllocateConfig
+		ExpectedViolations []string
+	}{
+		{
+			Name:        "Within bounds",
+			RequestType: RequestTypeNewVPC,
+			Config: AllocateConfig{
+				Stack:             "dev",
+				AvailabilityZones: []string{"us-east-1a", "us-east-1b", "us-east-1c"},
+				PrivateSize:       23,
+				NumPrivateSubnets: 3,
+				PublicSize:        28,
+				NumPublicSubnets:  3,
+			},
+		},
+		{
+			Name:        "Out of bounds",
+			RequestType: RequestTypeNewVPC,
+			Config: AllocateConfig{
+				Stack:             "prod",
+				AvailabilityZones: []string{"us-east-1a", "us-east-1b"},
+				PrivateSize:       20,
+				NumPrivateSubnets: 2,
+				PublicSize:        24,
+				NumPublicSubnets:  2,
+				AddFirewall:       true,
+			},
+			ExpectedViolations: []string{
+				`Stack "prod" is not one of dev, impl`,
+				"Number of AZs must be 3 but 2 was requested",
+				"Private subnet size must be between 22 and 24 but 20 was requested",
+				"Public subnet size must be at least 26 but 24 was requested",
+				"A firewall is not allowed by this template",
+			},
+		},
+		{
+			Name:               "Wrong request type",
+			RequestType:        RequestTypeNewSubnet,
+			ExpectedViolations: []string{`Template "Standard app VPC" is not for this type of request`},
+		},
+	} {
+		violations := template.Violations(tc.RequestType, &tc.Config)
+		if diff := cmp.Diff(tc.ExpectedViolations, violations); diff != "" {
+			t.Errorf("%s: unexpected violations (-want +got):\n%s", tc.Name, diff)
+		}
+	}
+}
+
+func TestProjectQuotaViolations(t *testing.T) {
+	quota := &ProjectQuota{ProjectName: "Test", MaxVPCs: 2, MaxIPsPerStack: 4096}
+	usage := &ProjectUsage{NumVPCs: 2, IPsByStack: map[string]int64{"dev": 2048}}
+
+	violations := quota.Violations(usage, 0, "dev", 1024)
+	if len(violations) != 0 {
+		t.Errorf("Expected no violations adding subnets but got %v", violations)
+	}
+	violations = quota.Violations(usage, 1, "dev", 4096)
+	expected := []string{
+		"Project Test already has 2 of its 2 allowed VPCs",
+		"Project Test would use 6144 IPs in dev but is limited to 4096",
+	}
+	if diff := cmp.Diff(expected, violations); diff != "" {
+		t.Errorf("Unexpected violations (-want +got):\n%s", diff)
+	}
+}
+
+func TestSubnetSizeViolations(t *testing.T) {
+	newSubnet := &AllocateConfig{SubnetSize: 40}
+	expected := []string{"Subnet size must be between 16 and 28 but 40 was requested"}
+	if diff := cmp.Diff(expected, newSubnet.SubnetSizeViolations(RequestTypeNewSubnet)); diff != "" {
+		t.Errorf("Unexpected violations (-want +got):\n%s", diff)
+	}
+	if ips := SubnetIPs(3, newSubnet.SubnetSize); ips != 0 {
+		t.Errorf("Expected no IPs counted for an invalid size but got %d", ips)
+	}
+
+	// The public size only matters if there are public subnets
+	newVPC := &AllocateConfig{NumPrivateSubnets: 2, PrivateSize: 24, PublicSize: 0}
+	if violations := newVPC.SubnetSizeViolations(RequestTypeNewVPC); len(violations) != 0 {
+		t.Errorf("Expected no violations but got %v", violations)
+	}
+	if ips := newVPC.RequestedIPs(); ips != 512 {
+		t.Errorf("Expected 512 requested IPs but got %d", ips)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
 )
 
 type TaskStatus int
@@ -44,6 +45,21 @@ type BatchTask struct {
 	Description string
 	Tasks       []*Task
 	AddedAt     time.Time
+	Selector    *BatchTaskSelector `json:",omitempty"`
+}
+
+// BatchTaskSelector records the selector a batch task targeted and the VPCs
+// it matched when the batch task was submitted
+type BatchTaskSelector struct {
+	Name       string `json:",omitempty"` // if a saved selector was used
+	Expression string
+	VPCs       []*BatchTaskSelectedVPC
+}
+
+// BatchTaskSelectedVPC is a VPC a batch task's selector matched
+type BatchTaskSelectedVPC struct {
+	ID, Name, AccountID string
+	Region              Region
 }
 
 func (s TaskStatus) String() string {
@@ -307,10 +323,20 @@ func (d *TaskDatabase) ReserveNextQueuedTask(mm ModelsManager) (*Task, LockSet,
 	return t, lockSet, nil
 }
 
-func (d *TaskDatabase) AddBatchTask(description string) (uint64, error) {
-	q := `INSERT INTO batch_task (description) VALUES ($1) RETURNING id`
+// selector is nil unless the batch task targeted a selector
+func (d *TaskDatabase) AddBatchTask(description string, selector *BatchTaskSelector) (uint64, error) {
+	var selectorJSON *string
+	if selector != nil {
+		buf, err := json.Marshal(selector)
+		if err != nil {
+			return 0, err
+		}
+		s := string(buf)
+		selectorJSON = &s
+	}
+	q := `INSERT INTO batch_task (description, selector) VALUES ($1, $2) RETURNING id`
 	var id uint64
-	err := d.DB.Get(&id, q, description)
+	err := d.DB.Get(&id, q, description, selectorJSON)
 	return id, err
 }
 
@@ -339,6 +365,7 @@ func (d *TaskDatabase) getBatchTasks(beforeID *uint64, batchTaskID *uint64) ([]*
 			batch_task.id,
 			batch_task.description,
 			batch_task.added_at,
+			batch_task.selector,
 			task.id,
 			COALESCE(task.description, ''),
 			task.data,
@@ -367,11 +394,19 @@ func (d *TaskDatabase) getBatchTasks(beforeID *uint64, batchTaskID *uint64) ([]*
 		t := &Task{
 			db: d,
 		}
-		err := rows.Scan(&bt.ID, &bt.Description, &bt.AddedAt, &taskID, &t.Description, &t.Data, &t.Status, &t.AccountID, &t.VPCID, &t.VPCRegion, &t.dependsOnID)
+		var selector []byte
+		err := rows.Scan(&bt.ID, &bt.Description, &bt.AddedAt, &selector, &taskID, &t.Description, &t.Data, &t.Status, &t.AccountID, &t.VPCID, &t.VPCRegion, &t.dependsOnID)
 		if err != nil {
 			return nil, false, err
 		}
 		if curr == nil || curr.ID != bt.ID {
+			if selector != nil {
+				bt.Selector = &BatchTaskSelector{}
+				err = json.Unmarshal(selector, bt.Selector)
+				if err != nil {
+					return nil, false, fmt.Errorf("Error unmarshaling selector of batch task %d: %s", bt.ID, err)
+				}
+			}
 			curr = bt
 			tasks = append(tasks, curr)
 		}
@@ -399,6 +434,9 @@ func (d *TaskDatabase) GetBatchTaskByID(batchTaskID uint64) (*BatchTask, error)
 	if err != nil {
 		return nil, err
 	}
+	if len(batchTasks) == 0 {
+		return nil, ErrRecordNotFound
+	}
 	if len(batchTasks) != 1 {
 		return nil, fmt.Errorf("Expected a single batch task with ID %d to be retrieved but got %d", batchTaskID, len(batchTasks))
 	}
@@ -406,6 +444,21 @@ func (d *TaskDatabase) GetBatchTaskByID(batchTaskID uint64) (*BatchTask, error)
 	return batchTasks[0], nil
 }
 
+// GetLatestBatchTaskID returns the ID of the most recently added batch task
+// with any of the given descriptions, or false if there isn't one
+func (d *TaskDatabase) GetLatestBatchTaskID(descriptions ...string) (uint64, bool, error) {
+	q := `SELECT id FROM batch_task WHERE description = ANY($1) ORDER BY added_at DESC LIMIT 1`
+	var id uint64
+	err := d.DB.Get(&id, q, pq.Array(descriptions))
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
 func (d *TaskDatabase) AddAccountTask(accountID, description string, data []byte, status TaskStatus) (*Task, error) {
 	t := &Task{
 		db:          d,
@@ -783,6 +836,8 @@ func (d *TaskDatabase) GetLastSubtaskStatuses(region Region, vpcID string) (map[
 		"Networking":     "UpdateNetworkingTaskData",
 		"ResolverRules":  "UpdateResolverRulesTaskData",
 		"SecurityGroups": "UpdateSecurityGroupsTaskData",
+		"VPCEndpoints":   "UpdateVPCEndpointsTaskData",
+		"FirewallPolicy": "UpdateFirewallPolicyTaskData",
 	}
 
 	statuses := map[string]string{}
@@ -20,6 +20,18 @@ type RemoveZonedSubnetsTaskData struct {
 	BeIdempotent bool
 }
 
+// ExpandCIDRTaskData grows an existing subnet group by adding a new group of
+// larger subnets of the same type, carved out of a freshly allocated CIDR block.
+type ExpandCIDRTaskData struct {
+	VPCID               string
+	Region              Region
+	SubnetType          SubnetType
+	GroupName           string // the existing group being expanded
+	NewGroupName        string // defaults to GroupName with the first unused "-N" suffix
+	SubnetSize          int
+	JIRAIssueForComment string
+}
+
 type AddAvailabilityZoneTaskData struct {
 	VPCID               string
 	Region              Region
@@ -40,6 +52,7 @@ type ImportVPCTaskData struct {
 	VPCType   VPCType
 	Region    Region
 	AccountID string
+	IPv6      *IPv6Config // associate an IPv6 block if the VPC doesn't have one yet
 }
 
 type EstablishExceptionVPCTaskData struct {
@@ -48,6 +61,18 @@ type EstablishExceptionVPCTaskData struct {
 	AccountID string
 }
 
+// UpdateExceptionVPCTaskData attaches an Exception VPC to the transit gateway
+// of a managed transit gateway attachment and adds the attachment's routes to
+// every route table in the VPC. A dry run changes nothing and only reports
+// what is missing.
+type UpdateExceptionVPCTaskData struct {
+	VPCID                             string
+	Region                            Region
+	ManagedTransitGatewayAttachmentID uint64
+	SubnetIDs                         []string // subnets tagged vpc-conf-layer=transitive if empty
+	DryRun                            bool
+}
+
 type UnimportVPCTaskData struct {
 	VPCID  string
 	Region Region
@@ -94,6 +119,11 @@ type NetworkingConfig struct {
 	ConnectPrivate                     bool
 	ManagedTransitGatewayAttachmentIDs []uint64
 	PeeringConnections                 []*PeeringConnectionConfig
+	ExternalPeeringConnections         []*ExternalPeeringConnectionConfig
+	VPNConnectionIDs                   []uint64
+	// Other VPC IDs of external peering connections to request again if
+	// they were deleted, rejected or expired. Not kept in the VPC's config.
+	RenewExternalPeeringConnections []string `json:",omitempty"`
 }
 
 type UpdateSecurityGroupsTaskData struct {
@@ -106,6 +136,65 @@ type SecurityGroupConfig struct {
 	SecurityGroupSetIDs []uint64
 }
 
+type UpdateVPCEndpointsTaskData struct {
+	VPCID     string
+	AWSRegion Region
+	VPCEndpointConfig
+}
+
+type VPCEndpointConfig struct {
+	VPCEndpointSetIDs []uint64
+}
+
+type UpdateFirewallPolicyTaskData struct {
+	VPCID                    string
+	AWSRegion                Region
+	FirewallPolicyTemplateID uint64 // 0 switches back to the default policy
+}
+
+// UpdateVPNConnectionTaskData runs in the account that owns the VPN
+// connection's transit gateway.
+type UpdateVPNConnectionTaskData struct {
+	VPNConnectionID uint64
+	Region          Region
+	Delete          bool // delete the AWS resources and then the VPN connection itself
+}
+
+type SnapshotRouteTablesTaskData struct {
+	VPCID  string
+	Region Region
+}
+
+// RestoreRouteTablesTaskData puts the routes in a VPC back to how they were
+// in a snapshot. Route tables created since the snapshot are left alone.
+type RestoreRouteTablesTaskData struct {
+	VPCID      string
+	Region     Region
+	SnapshotID uint64
+}
+
+// TestConnectivityTaskData runs a network connection test from a subnet in
+// the VPC using probes that are removed afterward.
+type TestConnectivityTaskData struct {
+	VPCID  string
+	Region Region
+	Spec   ConnectivityTestSpec
+}
+
+// Where AnalyzeSecurityGroupUsageTaskData reads flow logs from
+const (
+	FlowLogSourceCloudWatch = "cloudwatch"
+	FlowLogSourceS3         = "s3"
+)
+
+type AnalyzeSecurityGroupUsageTaskData struct {
+	VPCID       string
+	AWSRegion   Region
+	TemplateIDs []uint64 // empty for every template applied to the VPC
+	Days        int
+	Source      string // FlowLogSource*
+}
+
 type UpdateResolverRulesTaskData struct {
 	VPCID     string
 	AWSRegion Region
@@ -129,6 +218,11 @@ type DeleteUnusedResourcesTaskData struct {
 	VPCType   VPCType
 }
 
+type MigrateLegacyToV1TaskData struct {
+	VPCID     string
+	AWSRegion Region
+}
+
 type ResolverRulesConfig struct {
 	ManagedResolverRuleSetIDs []uint64
 }
@@ -157,6 +251,8 @@ const (
 	TaskTypeVerifyState    TaskTypes = 1 << iota
 	TaskTypeLogging        TaskTypes = 1 << iota
 	TaskTypeSyncRoutes     TaskTypes = 1 << iota
+	TaskTypeVPCEndpoints   TaskTypes = 1 << iota
+	TaskTypeFirewallPolicy TaskTypes = 1 << iota
 )
 
 func (t TaskTypes) Includes(sub TaskTypes) bool {
@@ -170,6 +266,12 @@ type VerifySpec struct {
 	VerifySecurityGroups bool
 	VerifyCIDRs          bool
 	VerifyCMSNet         bool
+	VerifyVPCEndpoints   bool
+	VerifyFirewallPolicy bool
+
+	// Repairs only delete and request broken CMSNet activations again if
+	// this is set, since doing so briefly drops the connection
+	RepairCMSNetActivations bool
 }
 
 func VerifyAllSpec() VerifySpec {
@@ -180,6 +282,8 @@ func VerifyAllSpec() VerifySpec {
 		VerifySecurityGroups: true,
 		VerifyCIDRs:          true,
 		VerifyCMSNet:         true,
+		VerifyVPCEndpoints:   true,
+		VerifyFirewallPolicy: true,
 	}
 }
 
@@ -203,6 +307,12 @@ func (vs VerifySpec) VerifyTypes() VerifyTypes {
 	if vs.VerifyCMSNet {
 		v |= VerifyCMSNet
 	}
+	if vs.VerifyVPCEndpoints {
+		v |= VerifyVPCEndpoints
+	}
+	if vs.VerifyFirewallPolicy {
+		v |= VerifyFirewallPolicy
+	}
 	return v
 }
 
@@ -220,6 +330,12 @@ func (vs VerifySpec) FollowUpTasks() TaskTypes {
 	if vs.VerifySecurityGroups {
 		t |= TaskTypeSecurityGroups
 	}
+	if vs.VerifyVPCEndpoints {
+		t |= TaskTypeVPCEndpoints
+	}
+	if vs.VerifyFirewallPolicy {
+		t |= TaskTypeFirewallPolicy
+	}
 	return t
 }
 
@@ -231,18 +347,28 @@ type TaskData struct {
 	UpdateLoggingTaskData                     *UpdateLoggingTaskData
 	UpdateSecurityGroupsTaskData              *UpdateSecurityGroupsTaskData
 	UpdateResolverRulesTaskData               *UpdateResolverRulesTaskData
+	UpdateVPCEndpointsTaskData                *UpdateVPCEndpointsTaskData
+	UpdateFirewallPolicyTaskData              *UpdateFirewallPolicyTaskData
+	UpdateVPNConnectionTaskData               *UpdateVPNConnectionTaskData
+	SnapshotRouteTablesTaskData               *SnapshotRouteTablesTaskData
+	RestoreRouteTablesTaskData                *RestoreRouteTablesTaskData
+	TestConnectivityTaskData                  *TestConnectivityTaskData
+	AnalyzeSecurityGroupUsageTaskData         *AnalyzeSecurityGroupUsageTaskData
 	ImportVPCTaskData                         *ImportVPCTaskData
 	EstablishExceptionVPCTaskData             *EstablishExceptionVPCTaskData
+	UpdateExceptionVPCTaskData                *UpdateExceptionVPCTaskData
 	UnimportVPCTaskData                       *UnimportVPCTaskData
 	VerifyVPCTaskData                         *VerifyVPCTaskData
 	RepairVPCTaskData                         *RepairVPCTaskData
 	AddZonedSubnetsTaskData                   *AddZonedSubnetsTaskData
 	RemoveZonedSubnetsTaskData                *RemoveZonedSubnetsTaskData
+	ExpandCIDRTaskData                        *ExpandCIDRTaskData
 	ProvisionDNSTLSTaskData                   *ProvisionDNSTLSTaskData
 	DeleteDNSTLSTaskData                      *DeleteDNSTLSTaskData
 	UpdateVPCTypeTaskData                     *UpdateVPCTypeTaskData
 	UpdateVPCNameTaskData                     *UpdateVPCNameTaskData
 	DeleteUnusedResourcesTaskData             *DeleteUnusedResourcesTaskData
+	MigrateLegacyToV1TaskData                 *MigrateLegacyToV1TaskData
 	SynchronizeRouteTableStateFromAWSTaskData *SynchronizeRouteTableStateFromAWSTaskData
 	AddAvailabilityZoneTaskData               *AddAvailabilityZoneTaskData
 	RemoveAvailabilityZoneTaskData            *RemoveAvailabilityZoneTaskData
@@ -275,12 +401,32 @@ func (t *TaskData) LockTargetsNeeded(mm ModelsManager) ([]Target, error) {
 		return []Target{TargetVPC(t.UpdateLoggingTaskData.VPCID)}, nil
 	} else if t.UpdateSecurityGroupsTaskData != nil {
 		return []Target{TargetVPC(t.UpdateSecurityGroupsTaskData.VPCID)}, nil
+	} else if t.UpdateVPCEndpointsTaskData != nil {
+		return []Target{TargetVPC(t.UpdateVPCEndpointsTaskData.VPCID)}, nil
+	} else if t.UpdateFirewallPolicyTaskData != nil {
+		return []Target{TargetVPC(t.UpdateFirewallPolicyTaskData.VPCID)}, nil
+	} else if t.UpdateVPNConnectionTaskData != nil {
+		return []Target{TargetVPNConnection(t.UpdateVPNConnectionTaskData.VPNConnectionID)}, nil
+	} else if t.SnapshotRouteTablesTaskData != nil {
+		return []Target{TargetVPC(t.SnapshotRouteTablesTaskData.VPCID)}, nil
+	} else if t.RestoreRouteTablesTaskData != nil {
+		return []Target{TargetVPC(t.RestoreRouteTablesTaskData.VPCID)}, nil
+	} else if t.TestConnectivityTaskData != nil {
+		targets := []Target{TargetVPC(t.TestConnectivityTaskData.VPCID)}
+		if destination := t.TestConnectivityTaskData.Spec.DestinationVPCID; destination != "" && destination != t.TestConnectivityTaskData.VPCID {
+			targets = append(targets, TargetVPC(destination))
+		}
+		return targets, nil
+	} else if t.AnalyzeSecurityGroupUsageTaskData != nil {
+		return []Target{TargetVPC(t.AnalyzeSecurityGroupUsageTaskData.VPCID)}, nil
 	} else if t.UpdateResolverRulesTaskData != nil {
 		return []Target{TargetVPC(t.UpdateResolverRulesTaskData.VPCID)}, nil
 	} else if t.ImportVPCTaskData != nil {
 		return []Target{TargetVPC(t.ImportVPCTaskData.VPCID)}, nil
 	} else if t.EstablishExceptionVPCTaskData != nil {
 		return []Target{TargetVPC(t.EstablishExceptionVPCTaskData.VPCID)}, nil
+	} else if t.UpdateExceptionVPCTaskData != nil {
+		return []Target{TargetVPC(t.UpdateExceptionVPCTaskData.VPCID)}, nil
 	} else if t.UnimportVPCTaskData != nil {
 		return []Target{TargetVPC(t.UnimportVPCTaskData.VPCID)}, nil
 	} else if t.VerifyVPCTaskData != nil {
@@ -306,6 +452,8 @@ func (t *TaskData) LockTargetsNeeded(mm ModelsManager) ([]Target, error) {
 			return []Target{TargetVPC(t.RemoveZonedSubnetsTaskData.VPCID)}, nil
 		}
 		return []Target{TargetVPC(t.RemoveZonedSubnetsTaskData.VPCID), TargetIPControlWrite}, nil
+	} else if t.ExpandCIDRTaskData != nil {
+		return []Target{TargetVPC(t.ExpandCIDRTaskData.VPCID), TargetIPControlWrite}, nil
 	} else if t.ProvisionDNSTLSTaskData != nil {
 		return []Target{TargetFastDNSAPI}, nil
 	} else if t.DeleteDNSTLSTaskData != nil {
@@ -318,6 +466,8 @@ func (t *TaskData) LockTargetsNeeded(mm ModelsManager) ([]Target, error) {
 		return []Target{TargetVPC(t.UpdateVPCTypeTaskData.VPCID)}, nil
 	} else if t.DeleteUnusedResourcesTaskData != nil {
 		return []Target{TargetVPC(t.DeleteUnusedResourcesTaskData.VPCID)}, nil
+	} else if t.MigrateLegacyToV1TaskData != nil {
+		return []Target{TargetVPC(t.MigrateLegacyToV1TaskData.VPCID)}, nil
 	} else if t.SynchronizeRouteTableStateFromAWSTaskData != nil {
 		return []Target{TargetVPC(t.SynchronizeRouteTableStateFromAWSTaskData.VPCID)}, nil
 	} else if t.UpdateVPCNameTaskData != nil {
@@ -338,12 +488,28 @@ func (t *TaskData) GetRegion() Region {
 		return t.UpdateLoggingTaskData.Region
 	} else if t.UpdateSecurityGroupsTaskData != nil {
 		return t.UpdateSecurityGroupsTaskData.AWSRegion
+	} else if t.UpdateVPCEndpointsTaskData != nil {
+		return t.UpdateVPCEndpointsTaskData.AWSRegion
+	} else if t.UpdateFirewallPolicyTaskData != nil {
+		return t.UpdateFirewallPolicyTaskData.AWSRegion
+	} else if t.UpdateVPNConnectionTaskData != nil {
+		return t.UpdateVPNConnectionTaskData.Region
+	} else if t.SnapshotRouteTablesTaskData != nil {
+		return t.SnapshotRouteTablesTaskData.Region
+	} else if t.RestoreRouteTablesTaskData != nil {
+		return t.RestoreRouteTablesTaskData.Region
+	} else if t.TestConnectivityTaskData != nil {
+		return t.TestConnectivityTaskData.Region
+	} else if t.AnalyzeSecurityGroupUsageTaskData != nil {
+		return t.AnalyzeSecurityGroupUsageTaskData.AWSRegion
 	} else if t.UpdateResolverRulesTaskData != nil {
 		return t.UpdateResolverRulesTaskData.AWSRegion
 	} else if t.ImportVPCTaskData != nil {
 		return t.ImportVPCTaskData.Region
 	} else if t.EstablishExceptionVPCTaskData != nil {
 		return t.EstablishExceptionVPCTaskData.Region
+	} else if t.UpdateExceptionVPCTaskData != nil {
+		return t.UpdateExceptionVPCTaskData.Region
 	} else if t.UnimportVPCTaskData != nil {
 		return t.UnimportVPCTaskData.Region
 	} else if t.VerifyVPCTaskData != nil {
@@ -358,12 +524,16 @@ func (t *TaskData) GetRegion() Region {
 		return t.AddZonedSubnetsTaskData.Region
 	} else if t.RemoveZonedSubnetsTaskData != nil {
 		return t.RemoveZonedSubnetsTaskData.Region
+	} else if t.ExpandCIDRTaskData != nil {
+		return t.ExpandCIDRTaskData.Region
 	} else if t.UpdateVPCTypeTaskData != nil {
 		return t.UpdateVPCTypeTaskData.AWSRegion
 	} else if t.UpdateVPCNameTaskData != nil {
 		return t.UpdateVPCNameTaskData.AWSRegion
 	} else if t.DeleteUnusedResourcesTaskData != nil {
 		return t.DeleteUnusedResourcesTaskData.AWSRegion
+	} else if t.MigrateLegacyToV1TaskData != nil {
+		return t.MigrateLegacyToV1TaskData.AWSRegion
 	} else if t.SynchronizeRouteTableStateFromAWSTaskData != nil {
 		return t.SynchronizeRouteTableStateFromAWSTaskData.Region
 	}
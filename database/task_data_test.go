@@ -95,7 +95,7 @@ func TestFollowUpTasks(t *testing.T) {
 		{
 			name:          "Verify all",
 			spec:          VerifyAllSpec(),
-			followUpTypes: TaskTypeNetworking | TaskTypeLogging | TaskTypeResolverRules | TaskTypeSecurityGroups,
+			followUpTypes: TaskTypeNetworking | TaskTypeLogging | TaskTypeResolverRules | TaskTypeSecurityGroups | TaskTypeVPCEndpoints | TaskTypeFirewallPolicy,
 		},
 		{
 			name:          "Verify some",
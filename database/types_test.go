@@ -65,3 +65,58 @@ func TestAllSubnetTypes(t *testing.T) {
 		t.Fatalf("Not all subnet types returned by AllSubnetTypes(): \n%s", diff)
 	}
 }
+
+func TestFirewallPolicyTemplateValidate(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Template      FirewallPolicyTemplate
+		ExpectedError string
+	}{
+		{
+			Name:     "Default rule order",
+			Template: FirewallPolicyTemplate{Name: "default"},
+		},
+		{
+			Name: "Strict order with default actions",
+			Template: FirewallPolicyTemplate{
+				Name:                   "strict",
+				StatefulRuleOrder:      FirewallRuleOrderStrict,
+				StatefulDefaultActions: []string{"aws:drop_established", "aws:alert_established"},
+			},
+		},
+		{
+			Name: "Default actions need strict order",
+			Template: FirewallPolicyTemplate{
+				Name:                   "default",
+				StatefulDefaultActions: []string{"aws:drop_strict"},
+			},
+			ExpectedError: "Stateful default actions require STRICT_ORDER",
+		},
+		{
+			Name: "Unknown default action",
+			Template: FirewallPolicyTemplate{
+				Name:                   "strict",
+				StatefulRuleOrder:      FirewallRuleOrderStrict,
+				StatefulDefaultActions: []string{"aws:pass"},
+			},
+			ExpectedError: `Stateful default action "aws:pass" is not one of aws:drop_strict, aws:drop_established, aws:alert_strict, aws:alert_established`,
+		},
+		{
+			Name:          "Unknown rule order",
+			Template:      FirewallPolicyTemplate{Name: "x", StatefulRuleOrder: "RANDOM"},
+			ExpectedError: `Invalid stateful rule order "RANDOM"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Template.Validate()
+			errString := ""
+			if err != nil {
+				errString = err.Error()
+			}
+			if errString != tc.ExpectedError {
+				t.Errorf("Expected error %q but got %q", tc.ExpectedError, errString)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package flowlogs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// A Reader calls fn for every accepted flow in the given VPC that started
+// in [start, end).
+type Reader interface {
+	Read(vpcID string, start, end time.Time, fn func(*Record) error) error
+}
+
+func wanted(r *Record, vpcID string, start, end time.Time) bool {
+	return r.Action == ActionAccept && r.VPCID == vpcID && !r.Start.Before(start) && r.Start.Before(end)
+}
+
+// readLines parses every line of in, which is gzipped if gzipped is true.
+func readLines(in io.Reader, gzipped bool, vpcID string, start, end time.Time, fn func(*Record) error) error {
+	if gzipped {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		in = gz
+	}
+	scanner := bufio.NewScanner(in)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		r, err := ParseRecord(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("Line %d: %s", lineNum, err)
+		}
+		if r == nil || !wanted(r, vpcID, start, end) {
+			continue
+		}
+		err = fn(r)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// FileReader reads flow logs from a local file, gzipped if its name ends in
+// .gz, such as one downloaded from the S3 destination.
+type FileReader struct {
+	Path string
+}
+
+func (f *FileReader) Read(vpcID string, start, end time.Time, fn func(*Record) error) error {
+	in, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	err = readLines(in, strings.HasSuffix(f.Path, ".gz"), vpcID, start, end, fn)
+	if err != nil {
+		return fmt.Errorf("Error reading %s: %s", f.Path, err)
+	}
+	return nil
+}
+
+// S3Reader reads the gzipped files that flow logs deliver to S3 under
+// AWSLogs/<account>/vpcflowlogs/<region>/<yyyy>/<mm>/<dd>/.
+type S3Reader struct {
+	S3        s3iface.S3API
+	Bucket    string
+	AccountID string
+	Region    string
+	FlowLogID string // only read files from this flow log if set
+}
+
+func (s *S3Reader) Read(vpcID string, start, end time.Time, fn func(*Record) error) error {
+	for day := start.UTC().Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		prefix := fmt.Sprintf("AWSLogs/%s/vpcflowlogs/%s/%s/", s.AccountID, s.Region, day.Format("2006/01/02"))
+		keys := []string{}
+		err := s.S3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+			Bucket: aws.String(s.Bucket),
+			Prefix: aws.String(prefix),
+		}, func(out *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, obj := range out.Contents {
+				key := aws.StringValue(obj.Key)
+				if s.FlowLogID == "" || strings.Contains(key, "_"+s.FlowLogID+"_") {
+					keys = append(keys, key)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("Error listing s3://%s/%s: %s", s.Bucket, prefix, err)
+		}
+		for _, key := range keys {
+			out, err := s.S3.GetObject(&s3.GetObjectInput{
+				Bucket: aws.String(s.Bucket),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				return fmt.Errorf("Error getting s3://%s/%s: %s", s.Bucket, key, err)
+			}
+			err = readLines(out.Body, strings.HasSuffix(key, ".gz"), vpcID, start, end, fn)
+			out.Body.Close()
+			if err != nil {
+				return fmt.Errorf("Error reading s3://%s/%s: %s", s.Bucket, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Logs Insights returns at most this many rows from a query
+const insightsMaxResults = 10000
+
+// InsightsReader aggregates flows with a CloudWatch Logs Insights query so
+// that only distinct flows are transferred. Records it returns have no
+// Start time and a Count of how many flows they stand for.
+type InsightsReader struct {
+	Logs         cloudwatchlogsiface.CloudWatchLogsAPI
+	LogGroup     string
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// groupFields are what a security group rule can match on, plus the TCP
+// flags that tell whether a flow opened a connection
+var groupFields = []string{fieldInterfaceID, fieldFlowDirection, fieldProtocol, fieldSrcAddr, fieldDstAddr, fieldDstPort, fieldTCPFlags}
+
+func insightsField(name string) string {
+	return fmt.Sprintf("f%d", fieldIndex[name])
+}
+
+func insightsQuery(vpcID string) string {
+	pattern := strings.TrimSpace(strings.Repeat("* ", numFields))
+	names := []string{}
+	for i := 0; i < numFields; i++ {
+		names = append(names, fmt.Sprintf("f%d", i))
+	}
+	by := []string{}
+	for _, name := range groupFields {
+		by = append(by, insightsField(name))
+	}
+	// Source ports aren't grouped by, so Record.Initiated's check for other
+	// protocols than TCP is done by the query
+	return fmt.Sprintf(
+		`parse @message "%s" as %s | filter %s = "%s" and %s = "%s" and (%s = %d or %s <= %s) | stats count(*) as hits by %s | limit %d`,
+		pattern, strings.Join(names, ", "),
+		insightsField(fieldVPCID), vpcID,
+		insightsField(fieldAction), ActionAccept,
+		insightsField(fieldProtocol), protocolTCP, insightsField(fieldDstPort), insightsField(fieldSrcPort),
+		strings.Join(by, ", "),
+		insightsMaxResults)
+}
+
+func (q *InsightsReader) Read(vpcID string, start, end time.Time, fn func(*Record) error) error {
+	started, err := q.Logs.StartQuery(&cloudwatchlogs.StartQueryInput{
+		LogGroupName: aws.String(q.LogGroup),
+		QueryString:  aws.String(insightsQuery(vpcID)),
+		StartTime:    aws.Int64(start.Unix()),
+		EndTime:      aws.Int64(end.Unix()),
+		Limit:        aws.Int64(insightsMaxResults),
+	})
+	if err != nil {
+		return fmt.Errorf("Error starting Logs Insights query: %s", err)
+	}
+	deadline := time.Now().Add(q.Timeout)
+	var out *cloudwatchlogs.GetQueryResultsOutput
+	for {
+		out, err = q.Logs.GetQueryResults(&cloudwatchlogs.GetQueryResultsInput{
+			QueryId: started.QueryId,
+		})
+		if err != nil {
+			return fmt.Errorf("Error getting Logs Insights query results: %s", err)
+		}
+		status := aws.StringValue(out.Status)
+		if status == cloudwatchlogs.QueryStatusComplete {
+			break
+		}
+		if status != cloudwatchlogs.QueryStatusScheduled && status != cloudwatchlogs.QueryStatusRunning {
+			return fmt.Errorf("Logs Insights query ended with status %s", status)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Timed out waiting for Logs Insights query")
+		}
+		time.Sleep(q.PollInterval)
+	}
+	for _, row := range out.Results {
+		values := map[string]string{}
+		for _, field := range row {
+			values[aws.StringValue(field.Field)] = aws.StringValue(field.Value)
+		}
+		r, err := newRecord(func(name string) string {
+			for _, grouped := range groupFields {
+				if grouped == name {
+					return values[insightsField(name)]
+				}
+			}
+			return ""
+		})
+		if err != nil {
+			return fmt.Errorf("Invalid Logs Insights result: %s", err)
+		}
+		r.VPCID = vpcID
+		r.Action = ActionAccept
+		fmt.Sscanf(values["hits"], "%d", &r.Count)
+		err = fn(r)
+		if err != nil {
+			return err
+		}
+	}
+	if len(out.Results) >= insightsMaxResults {
+		return fmt.Errorf("Logs Insights returned the maximum of %d distinct flows; use a shorter window or the S3 source", insightsMaxResults)
+	}
+	return nil
+}
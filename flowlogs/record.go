@@ -0,0 +1,152 @@
+// Package flowlogs reads the VPC flow logs that vpc-conf configures and
+// works out which security group rules the recorded traffic used.
+package flowlogs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is the custom flow log format vpc-conf configures on every VPC.
+const Format = "${version} ${account-id} ${action} ${bytes} ${dstaddr} ${dstport} ${end} ${instance-id} ${interface-id} ${log-status} ${packets} ${pkt-dstaddr} ${pkt-srcaddr} ${protocol} ${srcaddr} ${srcport} ${start} ${subnet-id} ${tcp-flags} ${type} ${version} ${vpc-id} ${az-id} ${flow-direction} ${pkt-dst-aws-service} ${pkt-src-aws-service} ${region} ${sublocation-id} ${sublocation-type} ${traffic-path} ${type}"
+
+// fieldIndex maps each field name to its first position in Format.
+var fieldIndex = func() map[string]int {
+	idx := map[string]int{}
+	for i, f := range strings.Fields(Format) {
+		name := strings.TrimSuffix(strings.TrimPrefix(f, "${"), "}")
+		if _, ok := idx[name]; !ok {
+			idx[name] = i
+		}
+	}
+	return idx
+}()
+
+var numFields = len(strings.Fields(Format))
+
+// The fields a Record is built from
+const (
+	fieldAction        = "action"
+	fieldDstAddr       = "dstaddr"
+	fieldDstPort       = "dstport"
+	fieldInterfaceID   = "interface-id"
+	fieldProtocol      = "protocol"
+	fieldSrcAddr       = "srcaddr"
+	fieldSrcPort       = "srcport"
+	fieldTCPFlags      = "tcp-flags"
+	fieldStart         = "start"
+	fieldVPCID         = "vpc-id"
+	fieldFlowDirection = "flow-direction"
+)
+
+const (
+	ActionAccept = "ACCEPT"
+
+	DirectionIngress = "ingress"
+	DirectionEgress  = "egress"
+
+	protocolTCP = 6
+
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10 // only logged as part of SYN-ACK
+)
+
+// A Record is one flow, or with Count > 1 a number of identical flows that
+// were aggregated before being read.
+type Record struct {
+	InterfaceID   string
+	VPCID         string
+	Action        string
+	FlowDirection string
+	Protocol      int64 // IANA protocol number
+	SrcAddr       net.IP
+	DstAddr       net.IP
+	DstPort       int64
+	SrcPort       int64 // -1 if unknown
+	TCPFlags      int64 // the flags seen during the flow, ORed together
+	Start         time.Time
+	Count         int64
+}
+
+// ParseRecord parses one line in Format. It returns nil for lines that don't
+// describe a flow: the header line and NODATA/SKIPDATA records.
+func ParseRecord(line string) (*Record, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] == "version" {
+		return nil, nil
+	}
+	if len(fields) != numFields {
+		return nil, fmt.Errorf("Expected %d fields but got %d", numFields, len(fields))
+	}
+	get := func(name string) string {
+		return fields[fieldIndex[name]]
+	}
+	if get(fieldInterfaceID) == "-" || get(fieldSrcAddr) == "-" {
+		return nil, nil
+	}
+	return newRecord(get)
+}
+
+// newRecord builds a Record from a field getter so that the same parsing
+// applies to log lines and to pre-aggregated query results.
+func newRecord(get func(string) string) (*Record, error) {
+	r := &Record{
+		InterfaceID:   get(fieldInterfaceID),
+		VPCID:         get(fieldVPCID),
+		Action:        get(fieldAction),
+		FlowDirection: get(fieldFlowDirection),
+		SrcAddr:       net.ParseIP(get(fieldSrcAddr)),
+		DstAddr:       net.ParseIP(get(fieldDstAddr)),
+		SrcPort:       -1,
+		Count:         1,
+	}
+	if r.SrcAddr == nil || r.DstAddr == nil {
+		return nil, fmt.Errorf("Invalid address in %s -> %s", get(fieldSrcAddr), get(fieldDstAddr))
+	}
+	var err error
+	r.Protocol, err = strconv.ParseInt(get(fieldProtocol), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid protocol: %s", err)
+	}
+	if port := get(fieldDstPort); port != "-" {
+		r.DstPort, err = strconv.ParseInt(port, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid destination port: %s", err)
+		}
+	}
+	if port := get(fieldSrcPort); port != "-" && port != "" {
+		r.SrcPort, err = strconv.ParseInt(port, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid source port: %s", err)
+		}
+	}
+	if flags := get(fieldTCPFlags); flags != "-" && flags != "" {
+		r.TCPFlags, err = strconv.ParseInt(flags, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid TCP flags: %s", err)
+		}
+	}
+	if start := get(fieldStart); start != "" {
+		secs, err := strconv.ParseInt(start, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid start time: %s", err)
+		}
+		r.Start = time.Unix(secs, 0).UTC()
+	}
+	return r, nil
+}
+
+// Initiated reports whether the flow opened a connection from its source to
+// its destination. TCP flows must carry a SYN that isn't a SYN-ACK. Other
+// protocols have no handshake, so a flow counts if it went to a port no
+// higher than the one it came from, since services listen on lower ports
+// than the ephemeral ones clients send from.
+func (r *Record) Initiated() bool {
+	if r.Protocol == protocolTCP {
+		return r.TCPFlags&tcpFlagSYN != 0 && r.TCPFlags&tcpFlagACK == 0
+	}
+	return r.SrcPort == -1 || r.DstPort <= r.SrcPort
+}
@@ -0,0 +1,150 @@
+package flowlogs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// An Interface is a network interface whose flows are checked against the
+// rules of the security groups it is in.
+type Interface struct {
+	ID               string
+	PrivateIPs       []string
+	SecurityGroupIDs []string
+}
+
+// A Rule is one concrete security group rule. The peer is the source of an
+// ingress rule or the destination of an egress rule.
+type Rule struct {
+	SecurityGroupID string
+	IsEgress        bool
+	Protocol        string // "tcp", "udp", "icmp", "-1" or a protocol number
+	FromPort        int64
+	ToPort          int64
+	PeerCIDRs       []string // prefix lists must be resolved by the caller
+	PeerGroupID     string   // matches the private IPs of interfaces in the group
+}
+
+type compiledRule struct {
+	*Rule
+	protocol  int64 // -1 for all
+	peerNets  []*net.IPNet
+	peerIPs   map[string]bool
+	checkPort bool
+}
+
+var protocolNumbers = map[string]int64{
+	"-1":     -1,
+	"all":    -1,
+	"icmp":   1,
+	"tcp":    6,
+	"udp":    17,
+	"icmpv6": 58,
+}
+
+// An Analyzer counts how many flows each rule allowed. A flow that more
+// than one rule allows counts towards each of them.
+type Analyzer struct {
+	rules           []*compiledRule
+	hits            []int64
+	interfaceGroups map[string][]string // interface ID -> security group IDs
+}
+
+func NewAnalyzer(interfaces []*Interface, rules []*Rule) (*Analyzer, error) {
+	a := &Analyzer{
+		hits:            make([]int64, len(rules)),
+		interfaceGroups: map[string][]string{},
+	}
+	groupIPs := map[string]map[string]bool{}
+	for _, iface := range interfaces {
+		a.interfaceGroups[iface.ID] = iface.SecurityGroupIDs
+		for _, groupID := range iface.SecurityGroupIDs {
+			if groupIPs[groupID] == nil {
+				groupIPs[groupID] = map[string]bool{}
+			}
+			for _, ip := range iface.PrivateIPs {
+				groupIPs[groupID][net.ParseIP(ip).String()] = true
+			}
+		}
+	}
+	for _, rule := range rules {
+		c := &compiledRule{Rule: rule}
+		protocol, ok := protocolNumbers[rule.Protocol]
+		if !ok {
+			n, err := strconv.ParseInt(rule.Protocol, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Unknown protocol %q", rule.Protocol)
+			}
+			protocol = n
+		}
+		c.protocol = protocol
+		c.checkPort = (protocol == 6 || protocol == 17) && !(rule.FromPort == -1 && rule.ToPort == -1)
+		for _, cidr := range rule.PeerCIDRs {
+			_, ipnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid CIDR %q: %s", cidr, err)
+			}
+			c.peerNets = append(c.peerNets, ipnet)
+		}
+		if rule.PeerGroupID != "" {
+			c.peerIPs = groupIPs[rule.PeerGroupID]
+		}
+		a.rules = append(a.rules, c)
+	}
+	return a, nil
+}
+
+func (c *compiledRule) matches(r *Record, groups []string) bool {
+	if (r.FlowDirection == DirectionEgress) != c.IsEgress {
+		return false
+	}
+	inGroup := false
+	for _, g := range groups {
+		if g == c.SecurityGroupID {
+			inGroup = true
+			break
+		}
+	}
+	if !inGroup {
+		return false
+	}
+	if c.protocol != -1 && c.protocol != r.Protocol {
+		return false
+	}
+	if c.checkPort && (r.DstPort < c.FromPort || r.DstPort > c.ToPort) {
+		return false
+	}
+	peer := r.SrcAddr
+	if c.IsEgress {
+		peer = r.DstAddr
+	}
+	for _, ipnet := range c.peerNets {
+		if ipnet.Contains(peer) {
+			return true
+		}
+	}
+	return c.peerIPs[peer.String()]
+}
+
+// Add counts r towards every rule that allows it. Only flows that open a
+// connection are counted: replies and the rest of a connection are allowed
+// by connection tracking whatever the rules say.
+func (a *Analyzer) Add(r *Record) error {
+	groups := a.interfaceGroups[r.InterfaceID]
+	if len(groups) == 0 || !r.Initiated() {
+		return nil
+	}
+	for idx, rule := range a.rules {
+		if rule.matches(r, groups) {
+			a.hits[idx] += r.Count
+		}
+	}
+	return nil
+}
+
+// Hits returns the number of flows each rule allowed, in the order the
+// rules were given.
+func (a *Analyzer) Hits() []int64 {
+	return append([]int64{}, a.hits...)
+}
@@ -0,0 +1,173 @@
+package flowlogs
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// line builds a log line in Format with the given fields set and all others "-".
+func line(fields map[string]string) string {
+	values := []string{}
+	for _, f := range strings.Fields(Format) {
+		name := strings.TrimSuffix(strings.TrimPrefix(f, "${"), "}")
+		value, ok := fields[name]
+		if !ok {
+			value = "-"
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, " ")
+}
+
+// flow builds a line for a flow that opens a connection
+func flow(eni, direction, protocol, src, dst, dstPort string, start time.Time) string {
+	flags := "0"
+	if protocol == "6" {
+		flags = "2"
+	}
+	return reply(eni, direction, protocol, src, dst, "49152", dstPort, flags, start)
+}
+
+func reply(eni, direction, protocol, src, dst, srcPort, dstPort, tcpFlags string, start time.Time) string {
+	return line(map[string]string{
+		"interface-id":   eni,
+		"vpc-id":         "vpc-1",
+		"action":         ActionAccept,
+		"flow-direction": direction,
+		"protocol":       protocol,
+		"srcaddr":        src,
+		"dstaddr":        dst,
+		"dstport":        dstPort,
+		"srcport":        srcPort,
+		"tcp-flags":      tcpFlags,
+		"start":          strconv.FormatInt(start.Unix(), 10),
+	})
+}
+
+func TestParseRecord(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	r, err := ParseRecord(flow("eni-1", DirectionIngress, "6", "10.0.0.5", "10.1.0.9", "443", start))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if r.InterfaceID != "eni-1" || r.Protocol != 6 || r.DstPort != 443 || r.SrcPort != 49152 || r.TCPFlags != 2 || !r.Start.Equal(start) || r.Count != 1 {
+		t.Fatalf("Wrong record: %+v", r)
+	}
+	if r.SrcAddr.String() != "10.0.0.5" || r.DstAddr.String() != "10.1.0.9" {
+		t.Fatalf("Wrong addresses: %s -> %s", r.SrcAddr, r.DstAddr)
+	}
+
+	header := strings.NewReplacer("${", "", "}", "").Replace(Format)
+	if r, err := ParseRecord(header); r != nil || err != nil {
+		t.Errorf("Expected header line to be skipped but got %+v, %v", r, err)
+	}
+	if r, err := ParseRecord(line(map[string]string{"log-status": "NODATA"})); r != nil || err != nil {
+		t.Errorf("Expected NODATA line to be skipped but got %+v, %v", r, err)
+	}
+	if _, err := ParseRecord("2 123456 ACCEPT"); err == nil {
+		t.Errorf("Expected an error for a short line")
+	}
+}
+
+func TestAnalyzeFileFlows(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	lines := []string{
+		strings.NewReplacer("${", "", "}", "").Replace(Format),
+		// https from the corporate range to the web server
+		flow("eni-web", DirectionIngress, "6", "10.128.0.7", "10.0.0.10", "443", start),
+		flow("eni-web", DirectionIngress, "6", "10.128.0.8", "10.0.0.10", "443", start.Add(time.Hour)),
+		// web server to the database, matched by the group reference
+		flow("eni-web", DirectionEgress, "6", "10.0.0.10", "10.0.0.20", "5432", start),
+		flow("eni-db", DirectionIngress, "6", "10.0.0.10", "10.0.0.20", "5432", start),
+		// outside the window
+		flow("eni-web", DirectionIngress, "6", "10.128.0.7", "10.0.0.10", "443", start.Add(-48*time.Hour)),
+		// interface not in any analyzed group
+		flow("eni-other", DirectionIngress, "6", "10.128.0.7", "10.0.0.30", "443", start),
+		// ping, allowed by the all-traffic rule only
+		flow("eni-db", DirectionIngress, "1", "10.0.0.10", "10.0.0.20", "0", start),
+		// replies and the rest of connections, which the rules don't decide on
+		reply("eni-web", DirectionEgress, "6", "10.0.0.10", "10.128.0.7", "443", "49152", "18", start),
+		reply("eni-web", DirectionIngress, "6", "10.128.0.7", "10.0.0.10", "49152", "443", "0", start),
+		reply("eni-db", DirectionIngress, "17", "10.0.0.10", "10.0.0.20", "53", "50000", "0", start),
+	}
+
+	dir, err := ioutil.TempDir("", "flowlogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "flows.log.gz")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(out)
+	gz.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	gz.Close()
+	out.Close()
+
+	interfaces := []*Interface{
+		{ID: "eni-web", PrivateIPs: []string{"10.0.0.10"}, SecurityGroupIDs: []string{"sg-web"}},
+		{ID: "eni-db", PrivateIPs: []string{"10.0.0.20"}, SecurityGroupIDs: []string{"sg-db"}},
+	}
+	rules := []*Rule{
+		{SecurityGroupID: "sg-web", Protocol: "tcp", FromPort: 443, ToPort: 443, PeerCIDRs: []string{"10.128.0.0/16"}},
+		{SecurityGroupID: "sg-web", Protocol: "tcp", FromPort: 80, ToPort: 80, PeerCIDRs: []string{"10.128.0.0/16"}},
+		{SecurityGroupID: "sg-web", IsEgress: true, Protocol: "-1", PeerCIDRs: []string{"0.0.0.0/0"}},
+		{SecurityGroupID: "sg-db", Protocol: "tcp", FromPort: 5432, ToPort: 5432, PeerGroupID: "sg-web"},
+		{SecurityGroupID: "sg-db", Protocol: "-1", FromPort: -1, ToPort: -1, PeerGroupID: "sg-web"},
+	}
+	analyzer, err := NewAnalyzer(interfaces, rules)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	reader := &FileReader{Path: path}
+	err = reader.Read("vpc-1", start.Add(-time.Hour), start.Add(24*time.Hour), analyzer.Add)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []int64{2, 0, 1, 1, 2}
+	if diff := cmp.Diff(expected, analyzer.Hits()); diff != "" {
+		t.Fatalf("Expected hits did not match actual: \n%s", diff)
+	}
+}
+
+func TestNewAnalyzerErrors(t *testing.T) {
+	if _, err := NewAnalyzer(nil, []*Rule{{Protocol: "bogus"}}); err == nil {
+		t.Errorf("Expected an error for an unknown protocol")
+	}
+	if _, err := NewAnalyzer(nil, []*Rule{{Protocol: "tcp", PeerCIDRs: []string{"10.0.0.0"}}}); err == nil {
+		t.Errorf("Expected an error for an invalid CIDR")
+	}
+}
+
+func TestInitiated(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Record   Record
+		Expected bool
+	}{
+		{"TCP SYN", Record{Protocol: 6, TCPFlags: 2, SrcPort: 49152, DstPort: 443}, true},
+		{"TCP SYN and FIN in one interval", Record{Protocol: 6, TCPFlags: 3, SrcPort: 49152, DstPort: 443}, true},
+		{"TCP SYN-ACK", Record{Protocol: 6, TCPFlags: 18, SrcPort: 443, DstPort: 49152}, false},
+		{"TCP without flags", Record{Protocol: 6, SrcPort: 49152, DstPort: 443}, false},
+		{"UDP request", Record{Protocol: 17, SrcPort: 50000, DstPort: 53}, true},
+		{"UDP reply", Record{Protocol: 17, SrcPort: 53, DstPort: 50000}, false},
+		{"UDP with unknown source port", Record{Protocol: 17, SrcPort: -1, DstPort: 50000}, true},
+		{"ICMP", Record{Protocol: 1}, true},
+	}
+	for _, tc := range testCases {
+		if initiated := tc.Record.Initiated(); initiated != tc.Expected {
+			t.Errorf("%s: expected %v but got %v", tc.Name, tc.Expected, initiated)
+		}
+	}
+}
@@ -5,7 +5,7 @@ go 1.14
 require (
 	cirello.io/dynamolock v1.4.0
 	github.com/akamai/AkamaiOPEN-edgegrid-golang v0.9.15
-	github.com/aws/aws-sdk-go v1.38.40
+	github.com/aws/aws-sdk-go v1.42.23
 	github.com/benbjohnson/clock v1.0.0
 	github.com/coreos/go-oidc/v3 v3.0.0
 	github.com/davecgh/go-spew v1.1.1
@@ -40,6 +40,7 @@ type SubnetInfo struct {
 	AvailabilityZone string
 	ResourceID       string
 	CIDR             string
+	IPv6CIDR         string // assigned by AWS, not IPControl
 	GroupName        string
 }
 
@@ -51,4 +52,10 @@ type VPCInfo struct {
 	NewCIDRs          []string
 	AvailabilityZones []string
 	NewSubnets        []*SubnetInfo
+
+	// IPv6 space comes from AWS rather than IPControl. If IPv6 is set,
+	// CreateVPC associates a block and records it in IPv6CIDRBlock, and
+	// CreateSubnets gives each new subnet a /64 out of IPv6CIDRBlock.
+	IPv6          *database.IPv6Config
+	IPv6CIDRBlock string
 }
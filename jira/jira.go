@@ -17,6 +17,7 @@ import (
 	"github.com/dghubble/oauth1"
 
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
 )
 
 type Config struct {
@@ -36,6 +37,8 @@ type ClientInterface interface {
 	GetDNSTLSIssueStatus(id string) (database.DNSTLSRequestStatus, error)
 	SetDNSTLSIssueStatus(issueID string, status database.DNSTLSRequestStatus) error
 	PostComment(issueID, comment string) error
+	AddComment(issueID, comment string) (string, error)
+	SearchIssues(keys []string) ([]*Issue, error)
 }
 
 type OauthConfig struct {
@@ -49,6 +52,8 @@ type Client struct {
 
 	oauth1Config *oauth1.Config
 	oauthToken   string
+
+	baseURL string // overrides BaseURL for REST requests, for tests
 }
 
 func (c *Client) AddAuthentication(oauthConfig *OauthConfig) error {
@@ -61,15 +66,22 @@ func (c *Client) AddAuthentication(oauthConfig *OauthConfig) error {
 	return nil
 }
 
-type IssueDetails struct {
-	Reporter    string
-	Summary     string
-	Description string
-	Labels      []string
-}
+type IssueDetails = ticketing.IssueDetails
+
+type Issue = ticketing.Issue
+
+type Comment = ticketing.Comment
+
+var _ ticketing.Provider = &Client{}
 
 const BaseURL = "https://jiraent.cms.gov/"
 
+// Number of issues fetched per search request
+const searchPageSize = 50
+
+// JIRA's REST API doesn't use RFC 3339 timestamps
+const timeFormat = "2006-01-02T15:04:05.000-0700"
+
 // JiraURL is a helper method to decide if the issue is an URL (starter box) or
 // only an issue ID (QuickVPC) - fix QuickVPC so that it sends a full URL
 // then this extra function can be removed
@@ -81,6 +93,30 @@ func JiraURL(issueURL string) string {
 	return BaseURL + "browse/" + issueURL
 }
 
+func (c *Client) Name() string {
+	return "jira"
+}
+
+func (c *Client) Owns(issueKey string) bool {
+	return strings.HasPrefix(issueKey, c.Config.Project+"-")
+}
+
+func (c *Client) IssueKeyFromURL(issueURL string) string {
+	if !strings.HasPrefix(issueURL, BaseURL) {
+		return ""
+	}
+	parts := strings.Split(issueURL, "/")
+	return parts[len(parts)-1]
+}
+
+func (c *Client) IssueURL(issueKey string) string {
+	return JiraURL(issueKey)
+}
+
+func (c *Client) ServiceAccount() string {
+	return c.Username
+}
+
 func GenerateOauth1Config(c *OauthConfig) (*oauth1.Config, error) {
 	keyDERBlock, _ := pem.Decode([]byte(c.PrivateKey))
 	if keyDERBlock == nil {
@@ -113,6 +149,13 @@ func GenerateOauth1Config(c *OauthConfig) (*oauth1.Config, error) {
 	}, nil
 }
 
+func (c *Client) restURL() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return BaseURL
+}
+
 func (c *Client) httpClient() *http.Client {
 	token := oauth1.NewToken(c.oauthToken, "") // token secret is ignored for RSA signatures
 	client := c.oauth1Config.Client(context.Background(), token)
@@ -122,7 +165,7 @@ func (c *Client) httpClient() *http.Client {
 
 func (c *Client) setIssueStatus(issueID string, statusID string) error {
 	// Find transition ID for status
-	url := BaseURL + "rest/api/2/issue/" + issueID + "/transitions"
+	url := c.restURL() + "rest/api/2/issue/" + issueID + "/transitions"
 	req, _ := http.NewRequest(http.MethodGet, url, nil)
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
@@ -206,7 +249,7 @@ func (c *Client) CreateIssue(details *IssueDetails) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("Error marshalling: %s", err)
 	}
-	req, _ := http.NewRequest(http.MethodPost, BaseURL+"rest/api/2/issue/", bytes.NewReader(buf))
+	req, _ := http.NewRequest(http.MethodPost, c.restURL()+"rest/api/2/issue/", bytes.NewReader(buf))
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
@@ -235,7 +278,7 @@ func (c *Client) CreateIssue(details *IssueDetails) (string, error) {
 		if err != nil {
 			log.Printf("Error marshalling watcher %q: %s", watcher, err)
 		} else {
-			url := fmt.Sprintf("%srest/api/2/issue/%s/watchers", BaseURL, respData.Key)
+			url := fmt.Sprintf("%srest/api/2/issue/%s/watchers", c.restURL(), respData.Key)
 			req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(buf))
 			req.Header.Set("Content-Type", "application/json")
 			resp, err := c.httpClient().Do(req)
@@ -258,7 +301,7 @@ func (c *Client) CreateIssue(details *IssueDetails) (string, error) {
 }
 
 func (c *Client) VerifyAccess() error {
-	req, _ := http.NewRequest(http.MethodGet, BaseURL+"rest/api/2/user?username="+c.Username, nil)
+	req, _ := http.NewRequest(http.MethodGet, c.restURL()+"rest/api/2/user?username="+c.Username, nil)
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("Error talking to JIRA: %s", err)
@@ -276,7 +319,7 @@ func (c *Client) VerifyAccess() error {
 }
 
 func (c *Client) GetCurrentUsername() (string, error) {
-	req, _ := http.NewRequest(http.MethodGet, BaseURL+"rest/auth/1/session", nil)
+	req, _ := http.NewRequest(http.MethodGet, c.restURL()+"rest/auth/1/session", nil)
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return "", fmt.Errorf("Error talking to JIRA: %s", err)
@@ -301,7 +344,7 @@ func (c *Client) GetCurrentUsername() (string, error) {
 }
 
 func (c *Client) getIssueStatus(id string) (string, error) {
-	req, _ := http.NewRequest(http.MethodGet, BaseURL+"rest/api/2/issue/"+id, nil)
+	req, _ := http.NewRequest(http.MethodGet, c.restURL()+"rest/api/2/issue/"+id, nil)
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return "", fmt.Errorf("Error talking to JIRA: %s", err)
@@ -337,11 +380,7 @@ func (c *Client) GetIssueStatus(id string) (database.VPCRequestStatus, error) {
 	if err != nil {
 		return database.StatusUnknown, fmt.Errorf("Unable to get issue status for %s", id)
 	}
-	status, ok := c.Config.Statuses[statusString]
-	if !ok {
-		return database.StatusUnknown, fmt.Errorf("Unkown status id %s", statusString)
-	}
-	return status, nil
+	return c.VPCRequestStatus(statusString)
 }
 
 func (c *Client) SetIssueStatus(issueID string, status database.VPCRequestStatus) error {
@@ -388,18 +427,24 @@ func (c *Client) SetDNSTLSIssueStatus(issueID string, status database.DNSTLSRequ
 }
 
 func (c *Client) PostComment(issueID, comment string) error {
+	_, err := c.AddComment(issueID, comment)
+	return err
+}
+
+// AddComment posts a comment and returns its JIRA ID.
+func (c *Client) AddComment(issueID, comment string) (string, error) {
 	data := map[string]interface{}{
 		"body": comment,
 	}
 	buf, err := json.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("Error marshalling: %s", err)
+		return "", fmt.Errorf("Error marshalling: %s", err)
 	}
-	req, _ := http.NewRequest(http.MethodPost, BaseURL+"rest/api/2/issue/"+issueID+"/comment", bytes.NewReader(buf))
+	req, _ := http.NewRequest(http.MethodPost, c.restURL()+"rest/api/2/issue/"+issueID+"/comment", bytes.NewReader(buf))
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
-		return fmt.Errorf("Error talking to JIRA: %s", err)
+		return "", fmt.Errorf("Error talking to JIRA: %s", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 201 {
@@ -408,7 +453,138 @@ func (c *Client) PostComment(issueID, comment string) error {
 			log.Printf("Error reading JIRA response: %s", err)
 		}
 		log.Printf("JIRA response: %s", buf)
-		return fmt.Errorf("Unexpected status %s from JIRA", resp.Status)
+		return "", fmt.Errorf("Unexpected status %s from JIRA", resp.Status)
 	}
-	return nil
+	var respData struct {
+		ID string `json:"id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&respData)
+	if err != nil {
+		return "", fmt.Errorf("Error reading response from JIRA: %s", err)
+	}
+	return respData.ID, nil
+}
+
+// VPCRequestStatus maps a JIRA status ID to a VPC request status.
+func (c *Client) VPCRequestStatus(statusID string) (database.VPCRequestStatus, error) {
+	status, ok := c.Config.Statuses[statusID]
+	if !ok {
+		return database.StatusUnknown, fmt.Errorf("Unkown status id %s", statusID)
+	}
+	return status, nil
+}
+
+// SearchIssues fetches the given issues with one JQL query per page of keys
+// instead of one request per issue. Keys that don't exist are skipped.
+func (c *Client) SearchIssues(keys []string) ([]*Issue, error) {
+	issues := []*Issue{}
+	for start := 0; start < len(keys); start += searchPageSize {
+		end := start + searchPageSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		page, err := c.searchIssues(keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, page...)
+	}
+	return issues, nil
+}
+
+func (c *Client) searchIssues(keys []string) ([]*Issue, error) {
+	data := map[string]interface{}{
+		"jql":        fmt.Sprintf("key in (%s)", strings.Join(keys, ",")),
+		"fields":     []string{"status", "assignee", "resolution", "updated", "comment"},
+		"maxResults": len(keys),
+		// Don't fail the whole query if one of the issues was deleted or moved
+		"validateQuery": "warn",
+	}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling: %s", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, c.restURL()+"rest/api/2/search", bytes.NewReader(buf))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error talking to JIRA: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		buf, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			log.Printf("Error reading JIRA response: %s", err)
+		}
+		log.Printf("JIRA response: %s", buf)
+		return nil, fmt.Errorf("Unexpected status %s from JIRA", resp.Status)
+	}
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	var respData struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Status struct {
+					ID string `json:"id"`
+				} `json:"status"`
+				Assignee   *user `json:"assignee"`
+				Resolution *struct {
+					Name string `json:"name"`
+				} `json:"resolution"`
+				Updated string `json:"updated"`
+				Comment struct {
+					Comments []struct {
+						ID      string `json:"id"`
+						Author  user   `json:"author"`
+						Body    string `json:"body"`
+						Created string `json:"created"`
+					} `json:"comments"`
+				} `json:"comment"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&respData)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading response from JIRA: %s", err)
+	}
+
+	issues := []*Issue{}
+	for _, i := range respData.Issues {
+		// Statuses vpc-conf doesn't know about are left for the caller to skip
+		status, _ := c.VPCRequestStatus(i.Fields.Status.ID)
+		issue := &Issue{
+			Key:    i.Key,
+			Status: status,
+		}
+		if i.Fields.Assignee != nil {
+			issue.Assignee = i.Fields.Assignee.Name
+		}
+		if i.Fields.Resolution != nil {
+			issue.Resolution = i.Fields.Resolution.Name
+		}
+		if i.Fields.Updated != "" {
+			updated, err := time.Parse(timeFormat, i.Fields.Updated)
+			if err != nil {
+				log.Printf("Error parsing updated time %q on %s: %s", i.Fields.Updated, i.Key, err)
+			}
+			issue.Updated = updated
+		}
+		for _, comment := range i.Fields.Comment.Comments {
+			created, err := time.Parse(timeFormat, comment.Created)
+			if err != nil {
+				log.Printf("Error parsing time %q on comment %s: %s", comment.Created, comment.ID, err)
+			}
+			issue.Comments = append(issue.Comments, &Comment{
+				ID:      comment.ID,
+				Author:  comment.Author.Name,
+				Body:    comment.Body,
+				Created: created,
+			})
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
 }
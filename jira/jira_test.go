@@ -0,0 +1,153 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/dghubble/oauth1"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testClient(handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	client := &Client{
+		Config: Config{
+			Project: "VPCAPP",
+			Statuses: map[string]database.VPCRequestStatus{
+				"1": database.StatusSubmitted,
+				"2": database.StatusApproved,
+			},
+		},
+		Username:     "vpc-conf-bot",
+		oauth1Config: &oauth1.Config{},
+		baseURL:      server.URL + "/",
+	}
+	return client, server.Close
+}
+
+func TestSearchIssues(t *testing.T) {
+	searches := [][]string{}
+	client, done := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/search" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		var query struct {
+			JQL        string `json:"jql"`
+			MaxResults int    `json:"maxResults"`
+		}
+		json.NewDecoder(r.Body).Decode(&query)
+		keys := strings.Split(strings.TrimSuffix(strings.TrimPrefix(query.JQL, "key in ("), ")"), ",")
+		if query.MaxResults != len(keys) {
+			t.Errorf("Expected maxResults %d but got %d", len(keys), query.MaxResults)
+		}
+		searches = append(searches, keys)
+
+		issues := []map[string]interface{}{}
+		for _, key := range keys {
+			switch key {
+			case "VPCAPP-1":
+				issues = append(issues, map[string]interface{}{
+					"key": key,
+					"fields": map[string]interface{}{
+						"status":   map[string]string{"id": "2"},
+						"assignee": map[string]string{"name": "jdoe"},
+						"updated":  "2021-03-04T05:06:07.000-0500",
+						"comment": map[string]interface{}{
+							"comments": []map[string]interface{}{
+								{"id": "100", "author": map[string]string{"name": "jdoe"}, "body": "Looks good", "created": "2021-03-04T05:06:07.000-0500"},
+							},
+						},
+					},
+				})
+			case "VPCAPP-2":
+				issues = append(issues, map[string]interface{}{
+					"key": key,
+					"fields": map[string]interface{}{
+						"status":     map[string]string{"id": "99"},
+						"resolution": map[string]string{"name": "Won't Do"},
+					},
+				})
+			}
+			// Other keys don't exist
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues})
+	})
+	defer done()
+
+	keys := []string{"VPCAPP-1", "VPCAPP-2"}
+	for i := 3; i <= searchPageSize+1; i++ {
+		keys = append(keys, fmt.Sprintf("VPCAPP-%d", i))
+	}
+	issues, err := client.SearchIssues(keys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(searches) != 2 || len(searches[0]) != searchPageSize || len(searches[1]) != 1 {
+		t.Errorf("Expected a full page and a page of 1 but searched for %d pages", len(searches))
+	}
+	expected := []*Issue{
+		{
+			Key:      "VPCAPP-1",
+			Status:   database.StatusApproved,
+			Assignee: "jdoe",
+			Updated:  time.Date(2021, 3, 4, 10, 6, 7, 0, time.UTC),
+			Comments: []*Comment{
+				{ID: "100", Author: "jdoe", Body: "Looks good", Created: time.Date(2021, 3, 4, 10, 6, 7, 0, time.UTC)},
+			},
+		},
+		{
+			Key:        "VPCAPP-2",
+			Status:     database.StatusUnknown,
+			Resolution: "Won't Do",
+		},
+	}
+	if diff := cmp.Diff(expected, issues, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Errorf("Expected issues did not match actual: \n%s", diff)
+	}
+}
+
+func TestSearchIssuesError(t *testing.T) {
+	client, done := testClient(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Bad JQL", http.StatusBadRequest)
+	})
+	defer done()
+
+	_, err := client.SearchIssues([]string{"VPCAPP-1"})
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected an error with the status but got %v", err)
+	}
+}
+
+func TestAddComment(t *testing.T) {
+	var body string
+	client, done := testClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/issue/VPCAPP-1/comment" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		var data struct {
+			Body string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&data)
+		body = data.Body
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"id": "12345"})
+	})
+	defer done()
+
+	id, err := client.AddComment("VPCAPP-1", "Hello")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if id != "12345" || body != "Hello" {
+		t.Errorf("Expected comment 12345 with body %q but got %q with body %q", "Hello", id, body)
+	}
+}
@@ -170,12 +170,13 @@ func TestObjectToMap(t *testing.T) {
 						"RouteTableID":        "rt-321",
 						"Routes": []interface{}{
 							map[string]interface{}{
-								"Destination":         "9.8.7.6/32",
-								"InternetGatewayID":   "",
-								"NATGatewayID":        "",
-								"PeeringConnectionID": "",
-								"TransitGatewayID":    "tgw-123",
-								"VPCEndpointID":       "",
+								"Destination":                 "9.8.7.6/32",
+								"InternetGatewayID":           "",
+								"EgressOnlyInternetGatewayID": "",
+								"NATGatewayID":                "",
+								"PeeringConnectionID":         "",
+								"TransitGatewayID":            "tgw-123",
+								"VPCEndpointID":               "",
 							},
 						},
 						"SubnetType": string(database.SubnetTypeApp),
@@ -196,6 +197,7 @@ func TestObjectToMap(t *testing.T) {
 									"GroupName":               "private",
 									"RouteTableAssociationID": "rtbassoc-76543210",
 									"CustomRouteTableID":      "",
+									"IPv6CIDR":                "",
 								},
 							},
 						},
@@ -230,11 +232,15 @@ func TestObjectToMap(t *testing.T) {
 				"S3FlowLogID":                     "fl-123",
 
 				// Add all the empty objects
-				"Firewall":                  map[string]interface{}{},
-				"FirewallRouteTableID":      "",
-				"PeeringConnections":        []interface{}{},
-				"ResolverRuleAssociations":  []interface{}{},
-				"TransitGatewayAttachments": []interface{}{},
+				"ExceptionTransitGateway":    map[string]interface{}{},
+				"ExternalPeeringConnections": []interface{}{},
+				"Firewall":                   map[string]interface{}{},
+				"FirewallRouteTableID":       "",
+				"IPv6":                       map[string]interface{}{},
+				"PeeringConnections":         []interface{}{},
+				"ResolverRuleAssociations":   []interface{}{},
+				"TransitGatewayAttachments":  []interface{}{},
+				"VPCEndpoints":               []interface{}{},
 			},
 		},
 	}
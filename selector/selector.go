@@ -0,0 +1,395 @@
+// Package selector parses and evaluates VPC selector expressions like
+//
+//	stack=prod AND region=us-east-1 AND label:pci AND NOT type=V1Firewall AND mtga:"Shared Services"
+//
+// Terms are field=value or field:value, which mean the same thing: the field
+// has that value. Labels and MTGAs can have several values and match if any of
+// them does. field!=value is short for NOT field=value. Terms combine with AND,
+// OR, NOT and parentheses; AND binds tighter than OR. Comparisons and keywords
+// are case insensitive. Values containing spaces or punctuation are quoted.
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// Target is what a selector is evaluated against: a VPC and its account
+type Target struct {
+	ID          string
+	Name        string
+	Stack       string
+	Region      string
+	AccountID   string
+	AccountName string
+	ProjectName string
+	// VPCType without the "VPCType" prefix, e.g. V1Firewall
+	Type string
+	// Labels on both the VPC and its account
+	Labels []string
+	// Names of the VPC's managed transit gateway attachments
+	MTGAs []string
+}
+
+// TypeName is the name VPC types are selected by
+func TypeName(t database.VPCType) string {
+	return strings.TrimPrefix(t.String(), "VPCType")
+}
+
+var fields = map[string]func(t *Target) []string{
+	"id":      func(t *Target) []string { return []string{t.ID} },
+	"name":    func(t *Target) []string { return []string{t.Name} },
+	"stack":   func(t *Target) []string { return []string{t.Stack} },
+	"region":  func(t *Target) []string { return []string{t.Region} },
+	"account": func(t *Target) []string { return []string{t.AccountID, t.AccountName} },
+	"project": func(t *Target) []string { return []string{t.ProjectName} },
+	"type":    func(t *Target) []string { return []string{t.Type} },
+	"label":   func(t *Target) []string { return t.Labels },
+	"mtga":    func(t *Target) []string { return t.MTGAs },
+}
+
+func fieldNames() string {
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Expr is a parsed selector
+type Expr interface {
+	Matches(t *Target) bool
+	String() string
+}
+
+type termExpr struct {
+	field string
+	value string
+}
+
+func (e *termExpr) Matches(t *Target) bool {
+	for _, v := range fields[e.field](t) {
+		if strings.EqualFold(v, e.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !isWordRune(r) }) == -1 && !isKeyword(s) {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (e *termExpr) String() string {
+	return e.field + "=" + quote(e.value)
+}
+
+type notExpr struct {
+	expr Expr
+}
+
+func (e *notExpr) Matches(t *Target) bool {
+	return !e.expr.Matches(t)
+}
+
+func (e *notExpr) String() string {
+	switch e.expr.(type) {
+	case *andExpr, *orExpr:
+		return "NOT (" + e.expr.String() + ")"
+	}
+	return "NOT " + e.expr.String()
+}
+
+type andExpr struct {
+	exprs []Expr
+}
+
+func (e *andExpr) Matches(t *Target) bool {
+	for _, expr := range e.exprs {
+		if !expr.Matches(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *andExpr) String() string {
+	parts := []string{}
+	for _, expr := range e.exprs {
+		if _, ok := expr.(*orExpr); ok {
+			parts = append(parts, "("+expr.String()+")")
+		} else {
+			parts = append(parts, expr.String())
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+type orExpr struct {
+	exprs []Expr
+}
+
+func (e *orExpr) Matches(t *Target) bool {
+	for _, expr := range e.exprs {
+		if expr.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *orExpr) String() string {
+	parts := []string{}
+	for _, expr := range e.exprs {
+		parts = append(parts, expr.String())
+	}
+	return strings.Join(parts, " OR ")
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./*@", r)
+}
+
+func isKeyword(s string) bool {
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT":
+		return true
+	}
+	return false
+}
+
+func tokenize(s string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '=' || r == ':':
+			tokens = append(tokens, token{kind: tokenOperator, value: string(r), pos: i})
+			i++
+		case r == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, token{kind: tokenOperator, value: "!=", pos: i})
+			i += 2
+		case r == '"':
+			start := i
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("Unterminated quote at position %d", start+1)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, value: value.String(), pos: start})
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("Unexpected %q at position %d", r, i+1)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tokenWord && strings.EqualFold(t.value, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func describe(t token) string {
+	if t.kind == tokenEOF {
+		return "end of selector"
+	}
+	return fmt.Sprintf("%q at position %d", t.value, t.pos+1)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	exprs := []Expr{}
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.keyword("OR") {
+			break
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &orExpr{exprs: exprs}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	exprs := []Expr{}
+	for {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.keyword("AND") {
+			break
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &andExpr{exprs: exprs}, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.keyword("NOT") {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: expr}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, fmt.Errorf("Expected ) but got %s", describe(t))
+		}
+		return expr, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	field := p.next()
+	if field.kind != tokenWord || isKeyword(field.value) {
+		return nil, fmt.Errorf("Expected a field name but got %s", describe(field))
+	}
+	name := strings.ToLower(field.value)
+	if _, ok := fields[name]; !ok {
+		return nil, fmt.Errorf("Unknown field %q; valid fields are %s", field.value, fieldNames())
+	}
+	op := p.next()
+	if op.kind != tokenOperator {
+		return nil, fmt.Errorf("Expected =, != or : after %s but got %s", field.value, describe(op))
+	}
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, fmt.Errorf("Expected a value for %s but got %s", field.value, describe(value))
+	}
+	if name == "type" {
+		err := validateType(value.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var expr Expr = &termExpr{field: name, value: value.value}
+	if op.value == "!=" {
+		expr = &notExpr{expr: expr}
+	}
+	return expr, nil
+}
+
+func validateType(value string) error {
+	names := []string{}
+	for _, t := range database.GetVPCTypes() {
+		if strings.EqualFold(TypeName(t), value) {
+			return nil
+		}
+		names = append(names, TypeName(t))
+	}
+	return fmt.Errorf("Unknown VPC type %q; valid types are %s", value, strings.Join(names, ", "))
+}
+
+// Parse parses a selector expression
+func Parse(s string) (Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, fmt.Errorf("Selector is empty")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("Expected AND, OR or end of selector but got %s", describe(t))
+	}
+	return expr, nil
+}
+
+// Select returns the targets that match the expression, in order
+func Select(expr Expr, targets []*Target) []*Target {
+	selected := []*Target{}
+	for _, t := range targets {
+		if expr.Matches(t) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,111 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var targets = []*Target{
+	{ID: "vpc-1", Name: "app-prod-east", Stack: "prod", Region: "us-east-1", AccountID: "111", AccountName: "App", Type: "V1", Labels: []string{"pci"}, MTGAs: []string{"Shared Services"}},
+	{ID: "vpc-2", Name: "app-prod-west", Stack: "prod", Region: "us-west-2", AccountID: "111", AccountName: "App", Type: "V1", Labels: []string{"pci"}},
+	{ID: "vpc-3", Name: "fw-prod-east", Stack: "prod", Region: "us-east-1", AccountID: "222", AccountName: "Firewall", Type: "V1Firewall", Labels: []string{"pci"}, MTGAs: []string{"Shared Services"}},
+	{ID: "vpc-4", Name: "app-dev-east", Stack: "dev", Region: "us-east-1", AccountID: "333", AccountName: "Dev", ProjectName: "Sandbox", Type: "Legacy", MTGAs: []string{"Shared Services", "Other"}},
+}
+
+func TestSelect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		selector string
+		expected []string
+	}{
+		{
+			name:     "Everything from the example",
+			selector: `stack=prod AND region=us-east-1 AND label:pci AND NOT type=V1Firewall AND mtga:"Shared Services"`,
+			expected: []string{"vpc-1"},
+		},
+		{
+			name:     "Case insensitive",
+			selector: `Stack=PROD and TYPE=v1firewall`,
+			expected: []string{"vpc-3"},
+		},
+		{
+			name:     "AND binds tighter than OR",
+			selector: `stack=dev OR region=us-west-2 AND label:pci`,
+			expected: []string{"vpc-2", "vpc-4"},
+		},
+		{
+			name:     "Parentheses",
+			selector: `(stack=dev OR region=us-west-2) AND label:pci`,
+			expected: []string{"vpc-2"},
+		},
+		{
+			name:     "Not equal",
+			selector: `region!=us-east-1`,
+			expected: []string{"vpc-2"},
+		},
+		{
+			name:     "Account by ID or name",
+			selector: `account=222 OR account=Dev`,
+			expected: []string{"vpc-3", "vpc-4"},
+		},
+		{
+			name:     "Any of several values",
+			selector: `mtga:Other`,
+			expected: []string{"vpc-4"},
+		},
+		{
+			name:     "Nothing",
+			selector: `NOT NOT label:missing`,
+			expected: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		expr, err := Parse(tc.selector)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		ids := []string{}
+		for _, target := range Select(expr, targets) {
+			ids = append(ids, target.ID)
+		}
+		if diff := cmp.Diff(tc.expected, ids); diff != "" {
+			t.Errorf("%s: expected VPCs did not match actual: \n%s", tc.name, diff)
+		}
+
+		// The normalized form selects the same VPCs
+		reparsed, err := Parse(expr.String())
+		if err != nil {
+			t.Errorf("%s: unexpected error parsing %q: %s", tc.name, expr.String(), err)
+			continue
+		}
+		if diff := cmp.Diff(len(tc.expected), len(Select(reparsed, targets))); diff != "" {
+			t.Errorf("%s: %q selected different VPCs: \n%s", tc.name, expr.String(), diff)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		selector      string
+		expectedError string
+	}{
+		{``, "Selector is empty"},
+		{`color=red`, `Unknown field "color"; valid fields are account, id, label, mtga, name, project, region, stack, type`},
+		{`type=V2`, `Unknown VPC type "V2"; valid types are V1, Legacy, Exception, V1Firewall, MigratingV1ToV1Firewall, MigratingV1FirewallToV1, MigratingLegacyToV1`},
+		{`stack prod`, `Expected =, != or : after stack but got "prod" at position 7`},
+		{`stack=`, `Expected a value for stack but got end of selector`},
+		{`stack=prod AND`, `Expected a field name but got end of selector`},
+		{`stack=prod region=us-east-1`, `Expected AND, OR or end of selector but got "region" at position 12`},
+		{`(stack=prod`, `Expected ) but got end of selector`},
+		{`mtga:"Shared`, `Unterminated quote at position 6`},
+		{`stack=prod;`, `Unexpected ';' at position 11`},
+	}
+	for _, tc := range testCases {
+		_, err := Parse(tc.selector)
+		if err == nil || err.Error() != tc.expectedError {
+			t.Errorf("%q: expected error %q but got %v", tc.selector, tc.expectedError, err)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package servicenow
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
+)
+
+type Config struct {
+	BaseURL         string // e.g. https://cmsgov.service-now.com/
+	Table           string // e.g. sc_req_item
+	NumberPrefix    string // e.g. RITM, used to tell which issues are ServiceNow's
+	AssignmentGroup string // optional
+	CallerField     string // optional, e.g. caller_id; set to the requester's EUA ID
+	// Statuses maps the table's state values (not labels) to VPC request statuses
+	Statuses map[string]database.VPCRequestStatus
+}
+
+type Client struct {
+	Config
+	Username string
+	Password string
+}
+
+var _ ticketing.Provider = &Client{}
+
+// Number of issues fetched per query
+const searchPageSize = 50
+
+// The Table API returns times in UTC without a zone
+const timeFormat = "2006-01-02 15:04:05"
+
+func (c *Client) Name() string {
+	return "servicenow"
+}
+
+func (c *Client) Owns(issueKey string) bool {
+	return c.NumberPrefix != "" && strings.HasPrefix(issueKey, c.NumberPrefix)
+}
+
+func (c *Client) IssueKeyFromURL(issueURL string) string {
+	if !strings.HasPrefix(issueURL, c.BaseURL) {
+		return ""
+	}
+	idx := strings.LastIndex(issueURL, "number=")
+	if idx == -1 {
+		return ""
+	}
+	key := issueURL[idx+len("number="):]
+	if end := strings.IndexAny(key, "&^"); end != -1 {
+		key = key[:end]
+	}
+	if !c.Owns(key) {
+		return ""
+	}
+	return key
+}
+
+func (c *Client) IssueURL(issueKey string) string {
+	return fmt.Sprintf("%s%s.do?sysparm_query=number=%s", c.BaseURL, c.Table, url.QueryEscape(issueKey))
+}
+
+func (c *Client) ServiceAccount() string {
+	return c.Username
+}
+
+// VPCRequestStatus maps a ServiceNow state value to a VPC request status.
+func (c *Client) VPCRequestStatus(state string) (database.VPCRequestStatus, error) {
+	status, ok := c.Statuses[state]
+	if !ok {
+		return database.StatusUnknown, fmt.Errorf("Unknown state %s", state)
+	}
+	return status, nil
+}
+
+func (c *Client) CreateIssue(details *ticketing.IssueDetails) (string, error) {
+	// ServiceNow records have no labels, so they're dropped
+	data := map[string]string{
+		"short_description": details.Summary,
+		"description":       details.Description,
+	}
+	if c.AssignmentGroup != "" {
+		data["assignment_group"] = c.AssignmentGroup
+	}
+	if c.CallerField != "" && details.Reporter != "" {
+		data[c.CallerField] = details.Reporter
+	}
+	var result struct {
+		Number string `json:"number"`
+	}
+	err := c.doRequest(http.MethodPost, c.tableURL(c.Table, nil), data, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Number, nil
+}
+
+// SetIssueStatus sets the record's state. If several states map to the
+// status, the lowest state value is used.
+func (c *Client) SetIssueStatus(issueKey string, status database.VPCRequestStatus) error {
+	states := []string{}
+	for state, s := range c.Statuses {
+		if s == status {
+			states = append(states, state)
+		}
+	}
+	if len(states) == 0 {
+		return fmt.Errorf("No ServiceNow state for status %q", status)
+	}
+	sort.Strings(states)
+	sysID, err := c.sysID(issueKey)
+	if err != nil {
+		return err
+	}
+	return c.doRequest(http.MethodPatch, c.tableURL(c.Table+"/"+sysID, nil), map[string]string{"state": states[0]}, nil)
+}
+
+func (c *Client) PostComment(issueKey, comment string) error {
+	_, err := c.AddComment(issueKey, comment)
+	return err
+}
+
+// AddComment posts a comment and returns the sys_id of its journal entry.
+// Updating a record doesn't say which journal entry it created, so each
+// comment ends with a random reference that its entry is found by. Once the
+// comment is posted, failing to find its entry only loses the ID: "" is
+// returned without an error so that the comment isn't posted again.
+func (c *Client) AddComment(issueKey, comment string) (string, error) {
+	sysID, err := c.sysID(issueKey)
+	if err != nil {
+		return "", err
+	}
+	refBytes := make([]byte, 8)
+	_, err = rand.Read(refBytes)
+	if err != nil {
+		return "", fmt.Errorf("Error generating comment reference: %s", err)
+	}
+	ref := "vpc-conf-" + hex.EncodeToString(refBytes)
+	comment = fmt.Sprintf("%s\n\n[%s]", comment, ref)
+	err = c.doRequest(http.MethodPatch, c.tableURL(c.Table+"/"+sysID, nil), map[string]string{"comments": comment}, nil)
+	if err != nil {
+		return "", err
+	}
+	var entries []map[string]string
+	err = c.doRequest(http.MethodGet, c.tableURL("sys_journal_field", url.Values{
+		"sysparm_query":  {fmt.Sprintf("element=comments^element_id=%s^valueLIKE%s^ORDERBYDESCsys_created_on", sysID, ref)},
+		"sysparm_fields": {"sys_id"},
+		"sysparm_limit":  {"1"},
+	}), nil, &entries)
+	if err != nil {
+		log.Printf("Error finding comment %s on %s after adding it: %s", ref, issueKey, err)
+		return "", nil
+	}
+	if len(entries) == 0 {
+		log.Printf("Comment %s on %s not found after adding it", ref, issueKey)
+		return "", nil
+	}
+	return entries[0]["sys_id"], nil
+}
+
+func (c *Client) sysID(issueKey string) (string, error) {
+	var records []map[string]string
+	err := c.doRequest(http.MethodGet, c.tableURL(c.Table, url.Values{
+		"sysparm_query":  {"number=" + issueKey},
+		"sysparm_fields": {"sys_id"},
+		"sysparm_limit":  {"1"},
+	}), nil, &records)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("%s not found", issueKey)
+	}
+	return records[0]["sys_id"], nil
+}
+
+// SearchIssues fetches the given issues and their comments with two queries
+// per page of keys. Keys that don't exist are skipped.
+func (c *Client) SearchIssues(keys []string) ([]*ticketing.Issue, error) {
+	issues := []*ticketing.Issue{}
+	for start := 0; start < len(keys); start += searchPageSize {
+		end := start + searchPageSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		page, err := c.searchIssues(keys[start:end])
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, page...)
+	}
+	return issues, nil
+}
+
+func (c *Client) searchIssues(keys []string) ([]*ticketing.Issue, error) {
+	var records []map[string]string
+	err := c.doRequest(http.MethodGet, c.tableURL(c.Table, url.Values{
+		"sysparm_query":  {"numberIN" + strings.Join(keys, ",")},
+		"sysparm_fields": {"sys_id,number,state,assigned_to.user_name,close_notes,sys_updated_on"},
+		"sysparm_limit":  {fmt.Sprintf("%d", len(keys))},
+	}), nil, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []*ticketing.Issue{}
+	bySysID := map[string]*ticketing.Issue{}
+	for _, record := range records {
+		// States vpc-conf doesn't know about are left for the caller to skip
+		status, _ := c.VPCRequestStatus(record["state"])
+		issue := &ticketing.Issue{
+			Key:        record["number"],
+			Status:     status,
+			Assignee:   record["assigned_to.user_name"],
+			Resolution: record["close_notes"],
+		}
+		if record["sys_updated_on"] != "" {
+			updated, err := time.Parse(timeFormat, record["sys_updated_on"])
+			if err != nil {
+				log.Printf("Error parsing updated time %q on %s: %s", record["sys_updated_on"], record["number"], err)
+			}
+			issue.Updated = updated
+		}
+		bySysID[record["sys_id"]] = issue
+		issues = append(issues, issue)
+	}
+	if len(bySysID) == 0 {
+		return issues, nil
+	}
+
+	sysIDs := []string{}
+	for sysID := range bySysID {
+		sysIDs = append(sysIDs, sysID)
+	}
+	var entries []map[string]string
+	err = c.doRequest(http.MethodGet, c.tableURL("sys_journal_field", url.Values{
+		"sysparm_query":  {fmt.Sprintf("element=comments^element_idIN%s^ORDERBYsys_created_on", strings.Join(sysIDs, ","))},
+		"sysparm_fields": {"sys_id,element_id,value,sys_created_by,sys_created_on"},
+	}), nil, &entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		issue := bySysID[entry["element_id"]]
+		if issue == nil {
+			continue
+		}
+		created, err := time.Parse(timeFormat, entry["sys_created_on"])
+		if err != nil {
+			log.Printf("Error parsing time %q on comment %s: %s", entry["sys_created_on"], entry["sys_id"], err)
+		}
+		issue.Comments = append(issue.Comments, &ticketing.Comment{
+			ID:      entry["sys_id"],
+			Author:  entry["sys_created_by"],
+			Body:    entry["value"],
+			Created: created,
+		})
+	}
+	return issues, nil
+}
+
+func (c *Client) tableURL(path string, query url.Values) string {
+	u := fmt.Sprintf("%sapi/now/table/%s", c.BaseURL, path)
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c *Client) doRequest(method, url string, body interface{}, result interface{}) error {
+	buf := new(bytes.Buffer)
+	if body != nil {
+		err := json.NewEncoder(buf).Encode(body)
+		if err != nil {
+			return fmt.Errorf("Error marshalling: %s", err)
+		}
+	}
+	req, err := http.NewRequest(method, url, buf)
+	if err != nil {
+		return fmt.Errorf("Failed to create request for %q - %s", url, err)
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error talking to ServiceNow: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		buf, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			log.Printf("Error reading ServiceNow response: %s", err)
+		}
+		log.Printf("ServiceNow response: %s", buf)
+		return fmt.Errorf("Unexpected status %s from ServiceNow", resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	respData := struct {
+		Result interface{} `json:"result"`
+	}{Result: result}
+	err = json.NewDecoder(resp.Body).Decode(&respData)
+	if err != nil {
+		return fmt.Errorf("Error reading response from ServiceNow: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package servicenow
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/ticketing"
+	"github.com/google/go-cmp/cmp"
+)
+
+type journalEntry map[string]string
+
+// fakeTable is just enough of the Table API for one table and its journal
+type fakeTable struct {
+	t       *testing.T
+	records []map[string]string
+	journal []journalEntry
+
+	journalError bool // fail journal queries
+}
+
+func (f *fakeTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "vpc-conf-bot" || pass != "secret" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query().Get("sysparm_query")
+	var result interface{}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/sc_req_item":
+		numbers := []string{}
+		if strings.HasPrefix(query, "numberIN") {
+			numbers = strings.Split(strings.TrimPrefix(query, "numberIN"), ",")
+		} else {
+			numbers = append(numbers, strings.TrimPrefix(query, "number="))
+		}
+		matching := []map[string]string{}
+		for _, record := range f.records {
+			for _, number := range numbers {
+				if record["number"] == number {
+					matching = append(matching, record)
+				}
+			}
+		}
+		result = matching
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/api/now/table/sc_req_item/"):
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		sysID := strings.TrimPrefix(r.URL.Path, "/api/now/table/sc_req_item/")
+		if state, ok := body["state"]; ok {
+			for _, record := range f.records {
+				if record["sys_id"] == sysID {
+					record["state"] = state
+				}
+			}
+			result = map[string]string{"sys_id": sysID}
+			break
+		}
+		f.journal = append(f.journal, journalEntry{
+			"sys_id":         "journal-" + body["comments"][:5],
+			"element_id":     sysID,
+			"value":          body["comments"],
+			"sys_created_by": "vpc-conf-bot",
+			"sys_created_on": "2021-03-04 05:06:07",
+		})
+		// Someone else comments as the same account right after
+		f.journal = append(f.journal, journalEntry{
+			"sys_id":         "journal-other",
+			"element_id":     sysID,
+			"value":          "Another comment",
+			"sys_created_by": "vpc-conf-bot",
+			"sys_created_on": "2021-03-04 05:06:08",
+		})
+		result = map[string]string{"sys_id": sysID}
+	case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/sys_journal_field":
+		if f.journalError {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		matching := []journalEntry{}
+		for _, entry := range f.journal {
+			if f.journalMatches(entry, query) {
+				matching = append(matching, entry)
+			}
+		}
+		result = matching
+	case r.Method == http.MethodPost && r.URL.Path == "/api/now/table/sc_req_item":
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		body["number"] = "RITM0000003"
+		f.records = append(f.records, body)
+		w.WriteHeader(http.StatusCreated)
+		result = body
+	default:
+		f.t.Errorf("Unexpected %s %s", r.Method, r.URL)
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+}
+
+// journalMatches understands the encoded queries the client sends
+func (f *fakeTable) journalMatches(entry journalEntry, query string) bool {
+	for _, term := range strings.Split(query, "^") {
+		switch {
+		case term == "element=comments" || term == "ORDERBYsys_created_on" || term == "ORDERBYDESCsys_created_on":
+		case strings.HasPrefix(term, "element_id="):
+			if entry["element_id"] != strings.TrimPrefix(term, "element_id=") {
+				return false
+			}
+		case strings.HasPrefix(term, "element_idIN"):
+			found := false
+			for _, id := range strings.Split(strings.TrimPrefix(term, "element_idIN"), ",") {
+				found = found || entry["element_id"] == id
+			}
+			if !found {
+				return false
+			}
+		case strings.HasPrefix(term, "valueLIKE"):
+			if !strings.Contains(entry["value"], strings.TrimPrefix(term, "valueLIKE")) {
+				return false
+			}
+		default:
+			f.t.Errorf("Unexpected journal query term %q", term)
+			return false
+		}
+	}
+	return true
+}
+
+func testClient(table *fakeTable) (*Client, func()) {
+	server := httptest.NewServer(table)
+	client := &Client{
+		Config: Config{
+			BaseURL:      server.URL + "/",
+			Table:        "sc_req_item",
+			NumberPrefix: "RITM",
+			CallerField:  "caller_id",
+			Statuses: map[string]database.VPCRequestStatus{
+				"1": database.StatusSubmitted,
+				"2": database.StatusApproved,
+			},
+		},
+		Username: "vpc-conf-bot",
+		Password: "secret",
+	}
+	return client, server.Close
+}
+
+func TestAddComment(t *testing.T) {
+	table := &fakeTable{
+		t: t,
+		records: []map[string]string{
+			{"sys_id": "abc", "number": "RITM0000001"},
+		},
+	}
+	client, done := testClient(table)
+	defer done()
+
+	id, err := client.AddComment("RITM0000001", "Hello")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(table.journal) == 0 || id != table.journal[0]["sys_id"] {
+		t.Errorf("Expected the ID of the comment that was added but got %q", id)
+	}
+	if !strings.HasPrefix(table.journal[0]["value"], "Hello\n\n[vpc-conf-") {
+		t.Errorf("Expected the comment to end with a reference but got %q", table.journal[0]["value"])
+	}
+
+	_, err = client.AddComment("RITM0000002", "Hello")
+	if err == nil {
+		t.Errorf("Expected an error commenting on a missing issue")
+	}
+}
+
+func TestAddCommentLookupFails(t *testing.T) {
+	table := &fakeTable{
+		t: t,
+		records: []map[string]string{
+			{"sys_id": "abc", "number": "RITM0000001"},
+		},
+		journalError: true,
+	}
+	client, done := testClient(table)
+	defer done()
+
+	// The comment is posted, so it must not be reported as failed and posted again
+	id, err := client.AddComment("RITM0000001", "Hello")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if id != "" {
+		t.Errorf("Expected no ID when the comment can't be found but got %q", id)
+	}
+	if len(table.journal) == 0 || !strings.HasPrefix(table.journal[0]["value"], "Hello") {
+		t.Errorf("Expected the comment to be posted")
+	}
+}
+
+func TestSetIssueStatus(t *testing.T) {
+	table := &fakeTable{
+		t: t,
+		records: []map[string]string{
+			{"sys_id": "abc", "number": "RITM0000001", "state": "1"},
+		},
+	}
+	client, done := testClient(table)
+	defer done()
+	client.Statuses["3"] = database.StatusApproved
+
+	err := client.SetIssueStatus("RITM0000001", database.StatusApproved)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if state := table.records[0]["state"]; state != "2" {
+		t.Errorf("Expected the lowest approved state 2 but got %q", state)
+	}
+	err = client.SetIssueStatus("RITM0000001", database.StatusRejected)
+	if err == nil {
+		t.Errorf("Expected an error for a status with no state")
+	}
+}
+
+func TestSearchIssues(t *testing.T) {
+	table := &fakeTable{
+		t: t,
+		records: []map[string]string{
+			{"sys_id": "abc", "number": "RITM0000001", "state": "2", "assigned_to.user_name": "jdoe", "sys_updated_on": "2021-03-04 05:06:07"},
+			{"sys_id": "def", "number": "RITM0000002", "state": "99", "close_notes": "Duplicate"},
+		},
+		journal: []journalEntry{
+			{"sys_id": "j1", "element_id": "abc", "value": "Looks good", "sys_created_by": "jdoe", "sys_created_on": "2021-03-04 05:06:07"},
+			{"sys_id": "j2", "element_id": "xyz", "value": "Another record", "sys_created_by": "jdoe", "sys_created_on": "2021-03-04 05:06:07"},
+		},
+	}
+	client, done := testClient(table)
+	defer done()
+
+	issues, err := client.SearchIssues([]string{"RITM0000001", "RITM0000002", "RITM0000404"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := []*ticketing.Issue{
+		{
+			Key:      "RITM0000001",
+			Status:   database.StatusApproved,
+			Assignee: "jdoe",
+			Updated:  time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+			Comments: []*ticketing.Comment{
+				{ID: "j1", Author: "jdoe", Body: "Looks good", Created: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+			},
+		},
+		{
+			Key:        "RITM0000002",
+			Status:     database.StatusUnknown,
+			Resolution: "Duplicate",
+		},
+	}
+	if diff := cmp.Diff(expected, issues); diff != "" {
+		t.Errorf("Expected issues did not match actual: \n%s", diff)
+	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	table := &fakeTable{t: t}
+	client, done := testClient(table)
+	defer done()
+
+	key, err := client.CreateIssue(&ticketing.IssueDetails{
+		Reporter:    "ABCD",
+		Summary:     "New VPC",
+		Description: "Please",
+		Labels:      []string{"ignored"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if key != "RITM0000003" {
+		t.Errorf("Expected RITM0000003 but got %q", key)
+	}
+	expected := []map[string]string{
+		{"short_description": "New VPC", "description": "Please", "caller_id": "ABCD", "number": "RITM0000003"},
+	}
+	if diff := cmp.Diff(expected, table.records); diff != "" {
+		t.Errorf("Expected records did not match actual: \n%s", diff)
+	}
+}
+
+func TestIssueKeyFromURL(t *testing.T) {
+	client := &Client{Config: Config{BaseURL: "https://snow.example.com/", Table: "sc_req_item", NumberPrefix: "RITM"}}
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{client.IssueURL("RITM0000001"), "RITM0000001"},
+		{"https://snow.example.com/sc_req_item.do?sysparm_query=number=RITM0000001^active=true", "RITM0000001"},
+		{"https://snow.example.com/sc_req_item.do?sysparm_query=number=INC0000001", ""},
+		{"https://jira.example.com/browse/RITM0000001", ""},
+	}
+	for _, tc := range testCases {
+		if key := client.IssueKeyFromURL(tc.url); key != tc.expected {
+			t.Errorf("%s: expected %q but got %q", tc.url, tc.expected, key)
+		}
+	}
+}
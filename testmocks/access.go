@@ -6,9 +6,13 @@ import (
 
 type MockAWSAccountAccessProvider struct {
 	EC2 *MockEC2
+	Err error // returned instead of access if set
 }
 
 func (m *MockAWSAccountAccessProvider) AccessAccount(accountID, region, asUser string) (*awsp.AWSAccountAccess, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
 	access := awsp.AWSAccountAccess{
 		EC2svc: m.EC2,
 	}
@@ -7,8 +7,37 @@ import (
 
 type MockCMSNet struct {
 	cmsnet.ClientInterface
+
+	Connections       cmsnet.ConnectionData
+	NATs              cmsnet.NATData
+	BrokenActivations []cmsnet.Activation
+
+	ActivationsDeleted []string
+	RequestsMade       []*cmsnet.ConnectionRequestParams
 }
 
 func (m *MockCMSNet) SupportsRegion(region database.Region) bool {
 	return false
 }
+
+func (m *MockCMSNet) GetAllConnectionRequests(accountID string, region database.Region, vpcID string, asUser string) (*cmsnet.ConnectionData, error) {
+	return &m.Connections, nil
+}
+
+func (m *MockCMSNet) GetAllNATRequests(accountID string, region database.Region, vpcID string, asUser string) (*cmsnet.NATData, error) {
+	return &m.NATs, nil
+}
+
+func (m *MockCMSNet) GetBrokenActivations(accountID string, region database.Region, vpcID string, asUser string) ([]cmsnet.Activation, error) {
+	return m.BrokenActivations, nil
+}
+
+func (m *MockCMSNet) DeleteActivation(requestID, accountID string, region database.Region, vpcID string, asUser string) (*cmsnet.ConnectionData, error) {
+	m.ActivationsDeleted = append(m.ActivationsDeleted, requestID)
+	return &m.Connections, nil
+}
+
+func (m *MockCMSNet) MakeConnectionRequest(accountID string, region database.Region, vpcID string, params *cmsnet.ConnectionRequestParams, asUser string) (*cmsnet.ConnectionData, error) {
+	m.RequestsMade = append(m.RequestsMade, params)
+	return &m.Connections, nil
+}
@@ -39,8 +39,12 @@ type MockEC2 struct {
 	AttachmentsModified  map[string][]string // id -> subnets
 	AttachmentIDsDeleted []string
 
-	RoutesAdded   map[string][]*database.RouteInfo
-	RoutesDeleted map[string][]string
+	TransitGatewayRouteTableAssociations map[string]string   // attachment id -> route table id
+	TransitGatewayRouteTablePropagations map[string][]string // attachment id -> route table ids
+
+	RoutesAdded    map[string][]*database.RouteInfo
+	RoutesDeleted  map[string][]string
+	RoutesReplaced map[string][]*database.RouteInfo
 
 	PeeringConnectionsCreated   *[]*ec2.VpcPeeringConnection
 	PeeringConnections          *[]*ec2.VpcPeeringConnection
@@ -49,8 +53,10 @@ type MockEC2 struct {
 
 	SubnetCIDRs map[string]string
 
-	PrimaryCIDR             *string
-	CIDRBlockAssociationSet []*ec2.VpcCidrBlockAssociation
+	PrimaryCIDR                 *string
+	CIDRBlockAssociationSet     []*ec2.VpcCidrBlockAssociation
+	IPv6CIDRBlockAssociationSet []*ec2.VpcIpv6CidrBlockAssociation
+	NextAmazonProvidedIPv6CIDR  string // block handed out for AmazonProvidedIpv6CidrBlock requests
 
 	SubnetsCreated          []*ec2.Subnet
 	VPCCIDRBlocksAssociated map[string][]string // vpc id -> [blocks associated]
@@ -70,7 +76,13 @@ type MockEC2 struct {
 	InternetGatewaysDetached  map[string]string // gateway id -> vpc id
 	InternetGatewaysDeleted   []string
 
+	EgressOnlyInternetGateways        []*ec2.EgressOnlyInternetGateway
+	EgressOnlyInternetGatewaysDeleted []string
+
+	SubnetIPv6CIDRsAssociated map[string]string // subnet id -> cidr
+
 	EIPsAllocated      []string
+	EIPPublicIPs       map[string]string // allocation id -> public IP
 	EIPsReleased       []string
 	NATGatewaysCreated []*ec2.NatGateway
 	NATGatewaysDeleted []string
@@ -84,7 +96,18 @@ type MockEC2 struct {
 	PreDefinedRouteTableAssociationIDQueue []string
 	PreDefinedEIPQueue                     []string
 
-	pcxID, rtID, assocID, allocID, natID int
+	VPCEndpoints        []*ec2.VpcEndpoint // existing and created endpoints
+	VPCEndpointsDeleted []string
+
+	CustomerGateways        []*ec2.CustomerGateway // existing and created gateways
+	CustomerGatewaysDeleted []string
+	VPNConnections          []*ec2.VpnConnection // existing and created connections
+	VPNTunnelsModified      []string             // outside IP addresses
+	TransitGatewayRoutes    map[string][]string  // route table id -> [cidr]
+
+	PrefixLists []*ec2.ManagedPrefixList
+
+	pcxID, rtID, assocID, allocID, natID, eigwID, vpceID, cgwID, vpnID int
 }
 
 func (m *MockEC2) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
@@ -123,10 +146,21 @@ func (m *MockEC2) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.Describ
 	// Return all subnets
 	out := &ec2.DescribeSubnetsOutput{}
 	for id, cidr := range m.SubnetCIDRs {
-		out.Subnets = append(out.Subnets, &ec2.Subnet{
+		subnet := &ec2.Subnet{
 			SubnetId:  aws.String(id),
 			CidrBlock: aws.String(cidr),
-		})
+		}
+		if ipv6CIDR, ok := m.SubnetIPv6CIDRsAssociated[id]; ok {
+			subnet.Ipv6CidrBlockAssociationSet = []*ec2.SubnetIpv6CidrBlockAssociation{
+				{
+					Ipv6CidrBlock: aws.String(ipv6CIDR),
+					Ipv6CidrBlockState: &ec2.SubnetCidrBlockState{
+						State: aws.String(ec2.SubnetCidrBlockStateCodeAssociated),
+					},
+				},
+			}
+		}
+		out.Subnets = append(out.Subnets, subnet)
 	}
 	return out, nil
 }
@@ -171,8 +205,9 @@ func (m *MockEC2) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsO
 	return &ec2.DescribeVpcsOutput{
 		Vpcs: []*ec2.Vpc{
 			{
-				CidrBlock:               m.PrimaryCIDR,
-				CidrBlockAssociationSet: m.CIDRBlockAssociationSet,
+				CidrBlock:                   m.PrimaryCIDR,
+				CidrBlockAssociationSet:     m.CIDRBlockAssociationSet,
+				Ipv6CidrBlockAssociationSet: m.IPv6CIDRBlockAssociationSet,
 			},
 		},
 	}, nil
@@ -396,6 +431,97 @@ func (m *MockEC2) AcceptTransitGatewayVpcAttachment(input *ec2.AcceptTransitGate
 	return nil, nil
 }
 
+// VPN connection attachments are named after the VPN connection
+func TestVPNAttachmentID(vpnConnectionID string) string {
+	return fmt.Sprintf("tgw-attach-%s", vpnConnectionID)
+}
+
+func (m *MockEC2) DescribeTransitGatewayAttachments(input *ec2.DescribeTransitGatewayAttachmentsInput) (*ec2.DescribeTransitGatewayAttachmentsOutput, error) {
+	if len(input.TransitGatewayAttachmentIds) == 0 {
+		out := &ec2.DescribeTransitGatewayAttachmentsOutput{}
+		for _, filter := range input.Filters {
+			if aws.StringValue(filter.Name) != "resource-id" {
+				continue
+			}
+			for _, vpn := range m.VPNConnections {
+				if aws.StringValue(vpn.VpnConnectionId) == aws.StringValue(filter.Values[0]) && aws.StringValue(vpn.State) != ec2.VpnStateDeleted {
+					out.TransitGatewayAttachments = append(out.TransitGatewayAttachments, &ec2.TransitGatewayAttachment{
+						TransitGatewayAttachmentId: aws.String(TestVPNAttachmentID(aws.StringValue(vpn.VpnConnectionId))),
+						ResourceId:                 vpn.VpnConnectionId,
+						ResourceType:               aws.String(ec2.TransitGatewayAttachmentResourceTypeVpn),
+					})
+				}
+			}
+		}
+		return out, nil
+	}
+	id := aws.StringValue(input.TransitGatewayAttachmentIds[0])
+	attachment := &ec2.TransitGatewayAttachment{TransitGatewayAttachmentId: aws.String(id)}
+	if rtID, ok := m.TransitGatewayRouteTableAssociations[id]; ok {
+		attachment.Association = &ec2.TransitGatewayAttachmentAssociation{
+			State:                      aws.String(ec2.TransitGatewayAssociationStateAssociated),
+			TransitGatewayRouteTableId: aws.String(rtID),
+		}
+	}
+	return &ec2.DescribeTransitGatewayAttachmentsOutput{
+		TransitGatewayAttachments: []*ec2.TransitGatewayAttachment{attachment},
+	}, nil
+}
+
+func (m *MockEC2) GetTransitGatewayAttachmentPropagationsPages(input *ec2.GetTransitGatewayAttachmentPropagationsInput, fn func(*ec2.GetTransitGatewayAttachmentPropagationsOutput, bool) bool) error {
+	out := &ec2.GetTransitGatewayAttachmentPropagationsOutput{}
+	for _, rtID := range m.TransitGatewayRouteTablePropagations[aws.StringValue(input.TransitGatewayAttachmentId)] {
+		out.TransitGatewayAttachmentPropagations = append(out.TransitGatewayAttachmentPropagations, &ec2.TransitGatewayAttachmentPropagation{
+			State:                      aws.String(ec2.TransitGatewayPropagationStateEnabled),
+			TransitGatewayRouteTableId: aws.String(rtID),
+		})
+	}
+	fn(out, true)
+	return nil
+}
+
+func (m *MockEC2) AssociateTransitGatewayRouteTable(input *ec2.AssociateTransitGatewayRouteTableInput) (*ec2.AssociateTransitGatewayRouteTableOutput, error) {
+	id := aws.StringValue(input.TransitGatewayAttachmentId)
+	if rtID, ok := m.TransitGatewayRouteTableAssociations[id]; ok {
+		return nil, fmt.Errorf("Transit gateway attachment %s is already associated with %s", id, rtID)
+	}
+	if m.TransitGatewayRouteTableAssociations == nil {
+		m.TransitGatewayRouteTableAssociations = make(map[string]string)
+	}
+	m.TransitGatewayRouteTableAssociations[id] = aws.StringValue(input.TransitGatewayRouteTableId)
+	return &ec2.AssociateTransitGatewayRouteTableOutput{}, nil
+}
+
+func (m *MockEC2) DisassociateTransitGatewayRouteTable(input *ec2.DisassociateTransitGatewayRouteTableInput) (*ec2.DisassociateTransitGatewayRouteTableOutput, error) {
+	id := aws.StringValue(input.TransitGatewayAttachmentId)
+	if m.TransitGatewayRouteTableAssociations[id] != aws.StringValue(input.TransitGatewayRouteTableId) {
+		return nil, fmt.Errorf("Transit gateway attachment %s is not associated with %s", id, aws.StringValue(input.TransitGatewayRouteTableId))
+	}
+	delete(m.TransitGatewayRouteTableAssociations, id)
+	return &ec2.DisassociateTransitGatewayRouteTableOutput{}, nil
+}
+
+func (m *MockEC2) EnableTransitGatewayRouteTablePropagation(input *ec2.EnableTransitGatewayRouteTablePropagationInput) (*ec2.EnableTransitGatewayRouteTablePropagationOutput, error) {
+	id := aws.StringValue(input.TransitGatewayAttachmentId)
+	if m.TransitGatewayRouteTablePropagations == nil {
+		m.TransitGatewayRouteTablePropagations = make(map[string][]string)
+	}
+	m.TransitGatewayRouteTablePropagations[id] = append(m.TransitGatewayRouteTablePropagations[id], aws.StringValue(input.TransitGatewayRouteTableId))
+	return &ec2.EnableTransitGatewayRouteTablePropagationOutput{}, nil
+}
+
+func (m *MockEC2) DisableTransitGatewayRouteTablePropagation(input *ec2.DisableTransitGatewayRouteTablePropagationInput) (*ec2.DisableTransitGatewayRouteTablePropagationOutput, error) {
+	id := aws.StringValue(input.TransitGatewayAttachmentId)
+	remaining := []string{}
+	for _, rtID := range m.TransitGatewayRouteTablePropagations[id] {
+		if rtID != aws.StringValue(input.TransitGatewayRouteTableId) {
+			remaining = append(remaining, rtID)
+		}
+	}
+	m.TransitGatewayRouteTablePropagations[id] = remaining
+	return &ec2.DisableTransitGatewayRouteTablePropagationOutput{}, nil
+}
+
 func (m *MockEC2) ModifyTransitGatewayVpcAttachment(input *ec2.ModifyTransitGatewayVpcAttachmentInput) (*ec2.ModifyTransitGatewayVpcAttachmentOutput, error) {
 	id := *input.TransitGatewayAttachmentId
 	_, ok := m.TransitGatewayAttachmentStatus[id]
@@ -432,17 +558,20 @@ func (m *MockEC2) CreateRoute(input *ec2.CreateRouteInput) (*ec2.CreateRouteOutp
 	var destination *string
 	if aws.StringValue(input.DestinationCidrBlock) != "" {
 		destination = input.DestinationCidrBlock
+	} else if aws.StringValue(input.DestinationIpv6CidrBlock) != "" {
+		destination = input.DestinationIpv6CidrBlock
 	} else {
 		destination = input.DestinationPrefixListId
 	}
 
 	m.RoutesAdded[*input.RouteTableId] = append(m.RoutesAdded[*input.RouteTableId], &database.RouteInfo{
-		Destination:         aws.StringValue(destination),
-		NATGatewayID:        aws.StringValue(input.NatGatewayId),
-		InternetGatewayID:   aws.StringValue(input.GatewayId),
-		VPCEndpointID:       aws.StringValue(input.VpcEndpointId),
-		TransitGatewayID:    aws.StringValue(input.TransitGatewayId),
-		PeeringConnectionID: aws.StringValue(input.VpcPeeringConnectionId),
+		Destination:                 aws.StringValue(destination),
+		NATGatewayID:                aws.StringValue(input.NatGatewayId),
+		InternetGatewayID:           aws.StringValue(input.GatewayId),
+		EgressOnlyInternetGatewayID: aws.StringValue(input.EgressOnlyInternetGatewayId),
+		VPCEndpointID:               aws.StringValue(input.VpcEndpointId),
+		TransitGatewayID:            aws.StringValue(input.TransitGatewayId),
+		PeeringConnectionID:         aws.StringValue(input.VpcPeeringConnectionId),
 	})
 	return nil, nil
 }
@@ -455,6 +584,8 @@ func (m *MockEC2) DeleteRoute(input *ec2.DeleteRouteInput) (*ec2.DeleteRouteOutp
 	var destination *string
 	if aws.StringValue(input.DestinationCidrBlock) != "" {
 		destination = input.DestinationCidrBlock
+	} else if aws.StringValue(input.DestinationIpv6CidrBlock) != "" {
+		destination = input.DestinationIpv6CidrBlock
 	} else {
 		destination = input.DestinationPrefixListId
 	}
@@ -464,6 +595,9 @@ func (m *MockEC2) DeleteRoute(input *ec2.DeleteRouteInput) (*ec2.DeleteRouteOutp
 }
 
 func (m *MockEC2) AssociateVpcCidrBlock(input *ec2.AssociateVpcCidrBlockInput) (*ec2.AssociateVpcCidrBlockOutput, error) {
+	if input.CidrBlock == nil {
+		return m.associateIPv6CIDRBlock(input)
+	}
 	if m.VPCCIDRBlocksAssociated == nil {
 		m.VPCCIDRBlocksAssociated = make(map[string][]string)
 	}
@@ -478,6 +612,87 @@ func (m *MockEC2) AssociateVpcCidrBlock(input *ec2.AssociateVpcCidrBlockInput) (
 	}, nil
 }
 
+func (m *MockEC2) associateIPv6CIDRBlock(input *ec2.AssociateVpcCidrBlockInput) (*ec2.AssociateVpcCidrBlockOutput, error) {
+	cidr := aws.StringValue(input.Ipv6CidrBlock)
+	if aws.BoolValue(input.AmazonProvidedIpv6CidrBlock) {
+		cidr = m.NextAmazonProvidedIPv6CIDR
+	}
+	if cidr == "" {
+		return nil, fmt.Errorf("No IPv6 CIDR available for VPC %s", *input.VpcId)
+	}
+	assoc := &ec2.VpcIpv6CidrBlockAssociation{
+		AssociationId: aws.String(*input.VpcId + "_" + cidr),
+		Ipv6CidrBlock: aws.String(cidr),
+		Ipv6CidrBlockState: &ec2.VpcCidrBlockState{
+			State: aws.String(ec2.VpcCidrBlockStateCodeAssociated),
+		},
+		Ipv6Pool: input.Ipv6Pool,
+	}
+	m.IPv6CIDRBlockAssociationSet = append(m.IPv6CIDRBlockAssociationSet, assoc)
+	return &ec2.AssociateVpcCidrBlockOutput{
+		Ipv6CidrBlockAssociation: assoc,
+	}, nil
+}
+
+func (m *MockEC2) AssociateSubnetCidrBlock(input *ec2.AssociateSubnetCidrBlockInput) (*ec2.AssociateSubnetCidrBlockOutput, error) {
+	if m.SubnetIPv6CIDRsAssociated == nil {
+		m.SubnetIPv6CIDRsAssociated = make(map[string]string)
+	}
+	m.SubnetIPv6CIDRsAssociated[*input.SubnetId] = *input.Ipv6CidrBlock
+	return &ec2.AssociateSubnetCidrBlockOutput{
+		SubnetId: input.SubnetId,
+		Ipv6CidrBlockAssociation: &ec2.SubnetIpv6CidrBlockAssociation{
+			Ipv6CidrBlock: input.Ipv6CidrBlock,
+		},
+	}, nil
+}
+
+func (m *MockEC2) CreateEgressOnlyInternetGateway(input *ec2.CreateEgressOnlyInternetGatewayInput) (*ec2.CreateEgressOnlyInternetGatewayOutput, error) {
+	m.eigwID++
+	eigw := &ec2.EgressOnlyInternetGateway{
+		EgressOnlyInternetGatewayId: aws.String(fmt.Sprintf("eigw-%d", m.eigwID)),
+		Attachments: []*ec2.InternetGatewayAttachment{
+			{
+				VpcId: input.VpcId,
+				State: aws.String(ec2.AttachmentStatusAttached),
+			},
+		},
+	}
+	m.EgressOnlyInternetGateways = append(m.EgressOnlyInternetGateways, eigw)
+	return &ec2.CreateEgressOnlyInternetGatewayOutput{EgressOnlyInternetGateway: eigw}, nil
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGateways(input *ec2.DescribeEgressOnlyInternetGatewaysInput) (*ec2.DescribeEgressOnlyInternetGatewaysOutput, error) {
+	out := &ec2.DescribeEgressOnlyInternetGatewaysOutput{}
+	for _, eigw := range m.EgressOnlyInternetGateways {
+		if len(input.EgressOnlyInternetGatewayIds) == 0 || contains(input.EgressOnlyInternetGatewayIds, *eigw.EgressOnlyInternetGatewayId) {
+			out.EgressOnlyInternetGateways = append(out.EgressOnlyInternetGateways, eigw)
+		}
+	}
+	return out, nil
+}
+
+func (m *MockEC2) DescribeEgressOnlyInternetGatewaysPages(input *ec2.DescribeEgressOnlyInternetGatewaysInput, fn func(*ec2.DescribeEgressOnlyInternetGatewaysOutput, bool) bool) error {
+	out, err := m.DescribeEgressOnlyInternetGateways(input)
+	if err != nil {
+		return err
+	}
+	fn(out, true)
+	return nil
+}
+
+func (m *MockEC2) DeleteEgressOnlyInternetGateway(input *ec2.DeleteEgressOnlyInternetGatewayInput) (*ec2.DeleteEgressOnlyInternetGatewayOutput, error) {
+	id := *input.EgressOnlyInternetGatewayId
+	for idx, eigw := range m.EgressOnlyInternetGateways {
+		if *eigw.EgressOnlyInternetGatewayId == id {
+			m.EgressOnlyInternetGateways = append(m.EgressOnlyInternetGateways[:idx], m.EgressOnlyInternetGateways[idx+1:]...)
+			break
+		}
+	}
+	m.EgressOnlyInternetGatewaysDeleted = append(m.EgressOnlyInternetGatewaysDeleted, id)
+	return nil, nil
+}
+
 func (m *MockEC2) hasSubnet(subnetID string) bool {
 	for _, subnet := range m.SubnetsCreated {
 		if subnetID == *subnet.SubnetId {
@@ -505,6 +720,16 @@ func (m *MockEC2) CreateSubnet(input *ec2.CreateSubnetInput) (*ec2.CreateSubnetO
 		VpcId:            input.VpcId,
 		SubnetId:         &newID,
 	}
+	if input.Ipv6CidrBlock != nil {
+		subnet.Ipv6CidrBlockAssociationSet = []*ec2.SubnetIpv6CidrBlockAssociation{
+			{
+				Ipv6CidrBlock: input.Ipv6CidrBlock,
+				Ipv6CidrBlockState: &ec2.SubnetCidrBlockState{
+					State: aws.String(ec2.SubnetCidrBlockStateCodeAssociated),
+				},
+			},
+		}
+	}
 	for _, spec := range input.TagSpecifications {
 		subnet.Tags = append(subnet.Tags, spec.Tags...)
 	}
@@ -578,6 +803,17 @@ func (m *MockEC2) DescribeRouteTables(input *ec2.DescribeRouteTablesInput) (*ec2
 			return false
 		}
 	} else if len(input.Filters) == 1 && aws.StringValue(input.Filters[0].Name) == "vpc-id" && len(input.Filters[0].Values) == 1 {
+		// Route tables without a VpcId are in every VPC, but only the first one is returned
+		vpcID := aws.StringValue(input.Filters[0].Values[0])
+		matching := []*ec2.RouteTable{}
+		for _, rt := range m.RouteTables {
+			if aws.StringValue(rt.VpcId) == vpcID {
+				matching = append(matching, rt)
+			}
+		}
+		if len(matching) > 0 {
+			return &ec2.DescribeRouteTablesOutput{RouteTables: matching}, nil
+		}
 		filter = func(rt *ec2.RouteTable) bool {
 			return true
 		}
@@ -698,12 +934,14 @@ func (m *MockEC2) DescribeAddresses(input *ec2.DescribeAddressesInput) (*ec2.Des
 	allocID := *input.Filters[0].Values[0]
 	for _, existing := range m.EIPsAllocated {
 		if existing == allocID {
+			address := &ec2.Address{
+				AllocationId: &allocID,
+			}
+			if ip, ok := m.EIPPublicIPs[allocID]; ok {
+				address.PublicIp = aws.String(ip)
+			}
 			return &ec2.DescribeAddressesOutput{
-				Addresses: []*ec2.Address{
-					{
-						AllocationId: &allocID,
-					},
-				},
+				Addresses: []*ec2.Address{address},
 			}, nil
 		}
 	}
@@ -826,7 +1064,29 @@ func (m *MockEC2) DeleteTags(input *ec2.DeleteTagsInput) (*ec2.DeleteTagsOutput,
 }
 
 func (m *MockEC2) ReplaceRoute(input *ec2.ReplaceRouteInput) (*ec2.ReplaceRouteOutput, error) {
-	return nil, fmt.Errorf("Replace Route Not Implemented")
+	if m.RoutesReplaced == nil {
+		m.RoutesReplaced = make(map[string][]*database.RouteInfo)
+	}
+
+	var destination *string
+	if aws.StringValue(input.DestinationCidrBlock) != "" {
+		destination = input.DestinationCidrBlock
+	} else if aws.StringValue(input.DestinationIpv6CidrBlock) != "" {
+		destination = input.DestinationIpv6CidrBlock
+	} else {
+		destination = input.DestinationPrefixListId
+	}
+
+	m.RoutesReplaced[*input.RouteTableId] = append(m.RoutesReplaced[*input.RouteTableId], &database.RouteInfo{
+		Destination:                 aws.StringValue(destination),
+		NATGatewayID:                aws.StringValue(input.NatGatewayId),
+		InternetGatewayID:           aws.StringValue(input.GatewayId),
+		EgressOnlyInternetGatewayID: aws.StringValue(input.EgressOnlyInternetGatewayId),
+		VPCEndpointID:               aws.StringValue(input.VpcEndpointId),
+		TransitGatewayID:            aws.StringValue(input.TransitGatewayId),
+		PeeringConnectionID:         aws.StringValue(input.VpcPeeringConnectionId),
+	})
+	return nil, nil
 }
 
 func (m *MockEC2) ReplaceRouteTableAssociation(input *ec2.ReplaceRouteTableAssociationInput) (*ec2.ReplaceRouteTableAssociationOutput, error) {
@@ -853,3 +1113,286 @@ func (m *MockEC2) ReplaceRouteTableAssociation(input *ec2.ReplaceRouteTableAssoc
 	}
 	return nil, fmt.Errorf("could not find association to update")
 }
+
+func (m *MockEC2) CreateVpcEndpoint(input *ec2.CreateVpcEndpointInput) (*ec2.CreateVpcEndpointOutput, error) {
+	m.vpceID++
+	ep := &ec2.VpcEndpoint{
+		VpcEndpointId:     aws.String(fmt.Sprintf("vpce-%d", m.vpceID)),
+		VpcId:             input.VpcId,
+		ServiceName:       input.ServiceName,
+		VpcEndpointType:   input.VpcEndpointType,
+		RouteTableIds:     input.RouteTableIds,
+		SubnetIds:         input.SubnetIds,
+		PrivateDnsEnabled: input.PrivateDnsEnabled,
+		State:             aws.String("available"),
+	}
+	for _, id := range input.SecurityGroupIds {
+		ep.Groups = append(ep.Groups, &ec2.SecurityGroupIdentifier{GroupId: id})
+	}
+	m.VPCEndpoints = append(m.VPCEndpoints, ep)
+	return &ec2.CreateVpcEndpointOutput{VpcEndpoint: ep}, nil
+}
+
+func (m *MockEC2) findVPCEndpoint(id string) *ec2.VpcEndpoint {
+	for _, ep := range m.VPCEndpoints {
+		if aws.StringValue(ep.VpcEndpointId) == id {
+			return ep
+		}
+	}
+	return nil
+}
+
+func (m *MockEC2) DescribeVpcEndpoints(input *ec2.DescribeVpcEndpointsInput) (*ec2.DescribeVpcEndpointsOutput, error) {
+	ids := input.VpcEndpointIds
+	if len(ids) == 0 && len(input.Filters) == 1 && aws.StringValue(input.Filters[0].Name) == "vpc-endpoint-id" {
+		ids = input.Filters[0].Values
+	}
+	out := &ec2.DescribeVpcEndpointsOutput{}
+	for _, id := range ids {
+		if ep := m.findVPCEndpoint(aws.StringValue(id)); ep != nil {
+			out.VpcEndpoints = append(out.VpcEndpoints, ep)
+		}
+	}
+	return out, nil
+}
+
+func removeStrings(list []*string, remove []*string) []*string {
+	kept := []*string{}
+	for _, s := range list {
+		found := false
+		for _, r := range remove {
+			if aws.StringValue(s) == aws.StringValue(r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func (m *MockEC2) ModifyVpcEndpoint(input *ec2.ModifyVpcEndpointInput) (*ec2.ModifyVpcEndpointOutput, error) {
+	ep := m.findVPCEndpoint(aws.StringValue(input.VpcEndpointId))
+	if ep == nil {
+		return nil, awserr.New("InvalidVpcEndpointId.NotFound", "not found", nil)
+	}
+	ep.RouteTableIds = append(removeStrings(ep.RouteTableIds, input.RemoveRouteTableIds), input.AddRouteTableIds...)
+	ep.SubnetIds = append(removeStrings(ep.SubnetIds, input.RemoveSubnetIds), input.AddSubnetIds...)
+	groups := []*ec2.SecurityGroupIdentifier{}
+	for _, g := range ep.Groups {
+		if len(removeStrings([]*string{g.GroupId}, input.RemoveSecurityGroupIds)) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	for _, id := range input.AddSecurityGroupIds {
+		groups = append(groups, &ec2.SecurityGroupIdentifier{GroupId: id})
+	}
+	ep.Groups = groups
+	if input.PrivateDnsEnabled != nil {
+		ep.PrivateDnsEnabled = input.PrivateDnsEnabled
+	}
+	return &ec2.ModifyVpcEndpointOutput{Return: aws.Bool(true)}, nil
+}
+
+func (m *MockEC2) DeleteVpcEndpoints(input *ec2.DeleteVpcEndpointsInput) (*ec2.DeleteVpcEndpointsOutput, error) {
+	kept := []*ec2.VpcEndpoint{}
+	for _, ep := range m.VPCEndpoints {
+		if len(removeStrings([]*string{ep.VpcEndpointId}, input.VpcEndpointIds)) > 0 {
+			kept = append(kept, ep)
+		}
+	}
+	m.VPCEndpoints = kept
+	m.VPCEndpointsDeleted = append(m.VPCEndpointsDeleted, aws.StringValueSlice(input.VpcEndpointIds)...)
+	return &ec2.DeleteVpcEndpointsOutput{}, nil
+}
+
+func (m *MockEC2) CreateCustomerGateway(input *ec2.CreateCustomerGatewayInput) (*ec2.CreateCustomerGatewayOutput, error) {
+	m.cgwID++
+	cgw := &ec2.CustomerGateway{
+		CustomerGatewayId: aws.String(fmt.Sprintf("cgw-%d", m.cgwID)),
+		BgpAsn:            aws.String(fmt.Sprintf("%d", aws.Int64Value(input.BgpAsn))),
+		IpAddress:         input.PublicIp,
+		DeviceName:        input.DeviceName,
+		Type:              input.Type,
+		State:             aws.String("available"),
+	}
+	m.CustomerGateways = append(m.CustomerGateways, cgw)
+	return &ec2.CreateCustomerGatewayOutput{CustomerGateway: cgw}, nil
+}
+
+func (m *MockEC2) DescribeCustomerGateways(input *ec2.DescribeCustomerGatewaysInput) (*ec2.DescribeCustomerGatewaysOutput, error) {
+	out := &ec2.DescribeCustomerGatewaysOutput{}
+	for _, cgw := range m.CustomerGateways {
+		if len(removeStrings([]*string{cgw.CustomerGatewayId}, input.CustomerGatewayIds)) == 0 {
+			out.CustomerGateways = append(out.CustomerGateways, cgw)
+		}
+	}
+	return out, nil
+}
+
+func (m *MockEC2) DeleteCustomerGateway(input *ec2.DeleteCustomerGatewayInput) (*ec2.DeleteCustomerGatewayOutput, error) {
+	id := aws.StringValue(input.CustomerGatewayId)
+	for _, vpn := range m.VPNConnections {
+		if aws.StringValue(vpn.CustomerGatewayId) == id && aws.StringValue(vpn.State) != ec2.VpnStateDeleted {
+			return nil, fmt.Errorf("Customer gateway %s is in use by %s", id, aws.StringValue(vpn.VpnConnectionId))
+		}
+	}
+	kept := []*ec2.CustomerGateway{}
+	for _, cgw := range m.CustomerGateways {
+		if aws.StringValue(cgw.CustomerGatewayId) != id {
+			kept = append(kept, cgw)
+		}
+	}
+	m.CustomerGateways = kept
+	m.CustomerGatewaysDeleted = append(m.CustomerGatewaysDeleted, id)
+	return &ec2.DeleteCustomerGatewayOutput{}, nil
+}
+
+func (m *MockEC2) findVPNConnection(id string) *ec2.VpnConnection {
+	for _, vpn := range m.VPNConnections {
+		if aws.StringValue(vpn.VpnConnectionId) == id {
+			return vpn
+		}
+	}
+	return nil
+}
+
+// Both tunnels of a created VPN connection are up
+func (m *MockEC2) CreateVpnConnection(input *ec2.CreateVpnConnectionInput) (*ec2.CreateVpnConnectionOutput, error) {
+	m.vpnID++
+	vpn := &ec2.VpnConnection{
+		VpnConnectionId:   aws.String(fmt.Sprintf("vpn-%d", m.vpnID)),
+		CustomerGatewayId: input.CustomerGatewayId,
+		TransitGatewayId:  input.TransitGatewayId,
+		Type:              input.Type,
+		State:             aws.String(ec2.VpnStateAvailable),
+		Options:           &ec2.VpnConnectionOptions{StaticRoutesOnly: aws.Bool(false)},
+	}
+	specs := []*ec2.VpnTunnelOptionsSpecification{}
+	if input.Options != nil {
+		vpn.Options.StaticRoutesOnly = input.Options.StaticRoutesOnly
+		specs = input.Options.TunnelOptions
+	}
+	for idx := 0; idx < 2; idx++ {
+		outsideIP := fmt.Sprintf("203.0.113.%d", m.vpnID*2+idx)
+		tunnel := &ec2.TunnelOption{OutsideIpAddress: aws.String(outsideIP)}
+		if idx < len(specs) {
+			tunnel.TunnelInsideCidr = specs[idx].TunnelInsideCidr
+			tunnel.DpdTimeoutAction = specs[idx].DPDTimeoutAction
+			tunnel.StartupAction = specs[idx].StartupAction
+			for _, v := range specs[idx].IKEVersions {
+				tunnel.IkeVersions = append(tunnel.IkeVersions, &ec2.IKEVersionsListValue{Value: v.Value})
+			}
+		}
+		vpn.Options.TunnelOptions = append(vpn.Options.TunnelOptions, tunnel)
+		vpn.VgwTelemetry = append(vpn.VgwTelemetry, &ec2.VgwTelemetry{
+			OutsideIpAddress: aws.String(outsideIP),
+			Status:           aws.String(ec2.TelemetryStatusUp),
+		})
+	}
+	m.VPNConnections = append(m.VPNConnections, vpn)
+	return &ec2.CreateVpnConnectionOutput{VpnConnection: vpn}, nil
+}
+
+func (m *MockEC2) DescribeVpnConnections(input *ec2.DescribeVpnConnectionsInput) (*ec2.DescribeVpnConnectionsOutput, error) {
+	out := &ec2.DescribeVpnConnectionsOutput{}
+	for _, id := range input.VpnConnectionIds {
+		vpn := m.findVPNConnection(aws.StringValue(id))
+		if vpn == nil {
+			return nil, awserr.New("InvalidVpnConnectionID.NotFound", fmt.Sprintf("Unknown VPN connection %s", aws.StringValue(id)), nil)
+		}
+		out.VpnConnections = append(out.VpnConnections, vpn)
+	}
+	return out, nil
+}
+
+func (m *MockEC2) ModifyVpnConnection(input *ec2.ModifyVpnConnectionInput) (*ec2.ModifyVpnConnectionOutput, error) {
+	vpn := m.findVPNConnection(aws.StringValue(input.VpnConnectionId))
+	if vpn == nil {
+		return nil, awserr.New("InvalidVpnConnectionID.NotFound", "Unknown VPN connection", nil)
+	}
+	if input.CustomerGatewayId != nil {
+		vpn.CustomerGatewayId = input.CustomerGatewayId
+	}
+	return &ec2.ModifyVpnConnectionOutput{VpnConnection: vpn}, nil
+}
+
+func (m *MockEC2) ModifyVpnTunnelOptions(input *ec2.ModifyVpnTunnelOptionsInput) (*ec2.ModifyVpnTunnelOptionsOutput, error) {
+	vpn := m.findVPNConnection(aws.StringValue(input.VpnConnectionId))
+	if vpn == nil {
+		return nil, awserr.New("InvalidVpnConnectionID.NotFound", "Unknown VPN connection", nil)
+	}
+	for _, tunnel := range vpn.Options.TunnelOptions {
+		if aws.StringValue(tunnel.OutsideIpAddress) != aws.StringValue(input.VpnTunnelOutsideIpAddress) {
+			continue
+		}
+		spec := input.TunnelOptions
+		if spec.TunnelInsideCidr != nil {
+			tunnel.TunnelInsideCidr = spec.TunnelInsideCidr
+		}
+		if spec.DPDTimeoutAction != nil {
+			tunnel.DpdTimeoutAction = spec.DPDTimeoutAction
+		}
+		if spec.StartupAction != nil {
+			tunnel.StartupAction = spec.StartupAction
+		}
+		if len(spec.IKEVersions) > 0 {
+			tunnel.IkeVersions = nil
+			for _, v := range spec.IKEVersions {
+				tunnel.IkeVersions = append(tunnel.IkeVersions, &ec2.IKEVersionsListValue{Value: v.Value})
+			}
+		}
+		m.VPNTunnelsModified = append(m.VPNTunnelsModified, aws.StringValue(tunnel.OutsideIpAddress))
+		return &ec2.ModifyVpnTunnelOptionsOutput{VpnConnection: vpn}, nil
+	}
+	return nil, fmt.Errorf("Unknown tunnel %s", aws.StringValue(input.VpnTunnelOutsideIpAddress))
+}
+
+func (m *MockEC2) DeleteVpnConnection(input *ec2.DeleteVpnConnectionInput) (*ec2.DeleteVpnConnectionOutput, error) {
+	vpn := m.findVPNConnection(aws.StringValue(input.VpnConnectionId))
+	if vpn == nil {
+		return nil, awserr.New("InvalidVpnConnectionID.NotFound", "Unknown VPN connection", nil)
+	}
+	vpn.State = aws.String(ec2.VpnStateDeleted)
+	return &ec2.DeleteVpnConnectionOutput{}, nil
+}
+
+func (m *MockEC2) CreateTransitGatewayRoute(input *ec2.CreateTransitGatewayRouteInput) (*ec2.CreateTransitGatewayRouteOutput, error) {
+	if m.TransitGatewayRoutes == nil {
+		m.TransitGatewayRoutes = make(map[string][]string)
+	}
+	rtID := aws.StringValue(input.TransitGatewayRouteTableId)
+	m.TransitGatewayRoutes[rtID] = append(m.TransitGatewayRoutes[rtID], aws.StringValue(input.DestinationCidrBlock))
+	return &ec2.CreateTransitGatewayRouteOutput{}, nil
+}
+
+func (m *MockEC2) DeleteTransitGatewayRoute(input *ec2.DeleteTransitGatewayRouteInput) (*ec2.DeleteTransitGatewayRouteOutput, error) {
+	rtID := aws.StringValue(input.TransitGatewayRouteTableId)
+	kept := []string{}
+	for _, cidr := range m.TransitGatewayRoutes[rtID] {
+		if cidr != aws.StringValue(input.DestinationCidrBlock) {
+			kept = append(kept, cidr)
+		}
+	}
+	m.TransitGatewayRoutes[rtID] = kept
+	return &ec2.DeleteTransitGatewayRouteOutput{}, nil
+}
+
+func (m *MockEC2) DescribeManagedPrefixLists(input *ec2.DescribeManagedPrefixListsInput) (*ec2.DescribeManagedPrefixListsOutput, error) {
+	out := &ec2.DescribeManagedPrefixListsOutput{}
+	for _, id := range input.PrefixListIds {
+		found := false
+		for _, pl := range m.PrefixLists {
+			if aws.StringValue(pl.PrefixListId) == aws.StringValue(id) {
+				out.PrefixLists = append(out.PrefixLists, pl)
+				found = true
+			}
+		}
+		if !found {
+			return nil, awserr.New("InvalidPrefixListID.NotFound", "Unknown prefix list", nil)
+		}
+	}
+	return out, nil
+}
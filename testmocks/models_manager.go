@@ -2,8 +2,10 @@ package testmocks
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/gob"
 	"fmt"
+	"time"
 
 	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
 )
@@ -54,6 +56,21 @@ type MockModelsManager struct {
 	SecondaryCIDRs                   []string            // Should be removed once all tests use VPCsSecondaryCIDRs
 	TestRegion                       database.Region
 	ManagedTransitGatewayAttachments []*database.ManagedTransitGatewayAttachment
+	VPCEndpointSets                  []*database.VPCEndpointSet
+	FirewallPolicyTemplates          []*database.FirewallPolicyTemplate
+	VPNConnections                   []*database.VPNConnection
+	SecurityGroupSets                []*database.SecurityGroupSet
+	SecurityGroupUsages              []*database.SecurityGroupUsage
+	RouteTableSnapshots              []*database.RouteTableSnapshot
+	RouteTableCapacities             []*database.VPCRouteTableCapacity
+	SharedServiceEndpoints           []*database.SharedServiceEndpoint
+	ConnectivityTestResults          []*database.ConnectivityTestResult
+	EgressIPs                        map[string][]*database.EgressIP // region+vpcID -> IPs
+	EgressIPEvents                   []*database.EgressIPEvent
+	VPCSelectorInfos                 []*database.VPCSelectorInfo
+	VPCSelectors                     []*database.VPCSelector
+	VPCRequests                      []*database.VPCRequest
+	VPCRequestComments               []*database.VPCRequestComment
 }
 
 func (m *MockModelsManager) CreateOrUpdateAWSAccount(account *database.AWSAccount) (databaseID uint64, err error) {
@@ -109,6 +126,19 @@ func (m *MockModelsManager) ListAutomatedVPCs() ([]*database.VPC, error) {
 	return nil, fmt.Errorf("Not implemented")
 }
 
+func (m *MockModelsManager) ListVPCsWithIssueType(issueType database.VerifyTypes) ([]*database.VPC, error) {
+	vpcs := []*database.VPC{}
+	for _, vpc := range m.VPCs {
+		for _, issue := range vpc.Issues {
+			if issue.Type == issueType {
+				vpcs = append(vpcs, vpc)
+				break
+			}
+		}
+	}
+	return vpcs, nil
+}
+
 func (m *MockModelsManager) ListExceptionVPCs() ([]*database.VPC, error) {
 	return nil, fmt.Errorf("Not implemented")
 }
@@ -131,7 +161,7 @@ func (m *MockModelsManager) GetVPCRequests(accountID string) ([]*database.VPCReq
 }
 
 func (m *MockModelsManager) GetAllVPCRequests() ([]*database.VPCRequest, error) {
-	return nil, fmt.Errorf("Not implemented yet")
+	return m.VPCRequests, nil
 }
 
 func (m *MockModelsManager) SetVPCRequestApprovedConfig(id uint64, approvedConfig *database.AllocateConfig) error {
@@ -186,6 +216,48 @@ func (m *MockModelsManager) ListBatchVPCLabels() ([]*database.VPCLabel, error) {
 	return nil, fmt.Errorf("Not implemented yet")
 }
 
+func (m *MockModelsManager) ListVPCSelectorInfo() ([]*database.VPCSelectorInfo, error) {
+	return m.VPCSelectorInfos, nil
+}
+
+func (m *MockModelsManager) GetVPCSelectors() ([]*database.VPCSelector, error) {
+	return m.VPCSelectors, nil
+}
+
+func (m *MockModelsManager) GetVPCSelector(name string) (*database.VPCSelector, error) {
+	for _, selector := range m.VPCSelectors {
+		if selector.Name == name {
+			return selector, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockModelsManager) CreateOrUpdateVPCSelector(selector *database.VPCSelector) error {
+	selector.UpdatedAt = time.Now()
+	for idx, existing := range m.VPCSelectors {
+		if existing.Name == selector.Name {
+			selector.ID = existing.ID
+			m.VPCSelectors[idx] = selector
+			return nil
+		}
+	}
+	selector.ID = uint64(len(m.VPCSelectors) + 1)
+	m.VPCSelectors = append(m.VPCSelectors, selector)
+	return nil
+}
+
+func (m *MockModelsManager) DeleteVPCSelector(name string) error {
+	kept := []*database.VPCSelector{}
+	for _, selector := range m.VPCSelectors {
+		if selector.Name != name {
+			kept = append(kept, selector)
+		}
+	}
+	m.VPCSelectors = kept
+	return nil
+}
+
 func (m *MockModelsManager) CreateManagedTransitGatewayAttachment(*database.ManagedTransitGatewayAttachment) error {
 	return fmt.Errorf("Not implemented yet")
 }
@@ -208,7 +280,7 @@ func (m *MockModelsManager) DeleteTransitGatewayResourceShare(region database.Re
 	return fmt.Errorf("Not implemented yet")
 }
 func (m *MockModelsManager) GetSecurityGroupSets() ([]*database.SecurityGroupSet, error) {
-	return nil, fmt.Errorf("Not implemented yet")
+	return m.SecurityGroupSets, nil
 }
 func (m *MockModelsManager) CreateSecurityGroupSet(*database.SecurityGroupSet) error {
 	return fmt.Errorf("Not implemented yet")
@@ -219,6 +291,260 @@ func (m *MockModelsManager) UpdateSecurityGroupSet(id uint64, set *database.Secu
 func (m *MockModelsManager) DeleteSecurityGroupSet(id uint64) error {
 	return fmt.Errorf("Not implemented yet")
 }
+func (m *MockModelsManager) GetVPCEndpointSets() ([]*database.VPCEndpointSet, error) {
+	return m.VPCEndpointSets, nil
+}
+func (m *MockModelsManager) SaveSecurityGroupUsage(usage *database.SecurityGroupUsage) error {
+	m.SecurityGroupUsages = append(m.SecurityGroupUsages, usage)
+	return nil
+}
+
+func (m *MockModelsManager) GetSecurityGroupUsage(templateID uint64) ([]*database.SecurityGroupUsage, error) {
+	usages := []*database.SecurityGroupUsage{}
+	for _, usage := range m.SecurityGroupUsages {
+		if usage.TemplateID == templateID {
+			usages = append(usages, usage)
+		}
+	}
+	return usages, nil
+}
+
+func (m *MockModelsManager) CreateRouteTableSnapshot(snapshot *database.RouteTableSnapshot) error {
+	snapshot.ID = 1
+	if len(m.RouteTableSnapshots) > 0 {
+		snapshot.ID = m.RouteTableSnapshots[len(m.RouteTableSnapshots)-1].ID + 1
+	}
+	snapshot.CreatedAt = time.Now()
+	m.RouteTableSnapshots = append(m.RouteTableSnapshots, snapshot)
+	return nil
+}
+
+func (m *MockModelsManager) GetRouteTableSnapshots(region database.Region, vpcID string) ([]*database.RouteTableSnapshot, error) {
+	snapshots := []*database.RouteTableSnapshot{}
+	for idx := len(m.RouteTableSnapshots) - 1; idx >= 0; idx-- {
+		snapshot := m.RouteTableSnapshots[idx]
+		if snapshot.Region == region && snapshot.VPCID == vpcID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
+
+func (m *MockModelsManager) GetRouteTableSnapshot(id uint64) (*database.RouteTableSnapshot, error) {
+	for _, snapshot := range m.RouteTableSnapshots {
+		if snapshot.ID == id {
+			return snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("No route table snapshot with id %d", id)
+}
+
+func (m *MockModelsManager) PruneRouteTableSnapshots(region database.Region, vpcID string, keep int) (int64, error) {
+	kept := []*database.RouteTableSnapshot{}
+	seen := 0
+	for idx := len(m.RouteTableSnapshots) - 1; idx >= 0; idx-- {
+		snapshot := m.RouteTableSnapshots[idx]
+		if snapshot.Region == region && snapshot.VPCID == vpcID {
+			seen++
+			if seen > keep {
+				continue
+			}
+		}
+		kept = append([]*database.RouteTableSnapshot{snapshot}, kept...)
+	}
+	deleted := int64(len(m.RouteTableSnapshots) - len(kept))
+	m.RouteTableSnapshots = kept
+	return deleted, nil
+}
+
+func (m *MockModelsManager) SaveRouteTableCapacity(capacity *database.VPCRouteTableCapacity) error {
+	capacity.UpdatedAt = time.Now()
+	for idx, existing := range m.RouteTableCapacities {
+		if existing.Region == capacity.Region && existing.VPCID == capacity.VPCID {
+			m.RouteTableCapacities[idx] = capacity
+			return nil
+		}
+	}
+	m.RouteTableCapacities = append(m.RouteTableCapacities, capacity)
+	return nil
+}
+
+func (m *MockModelsManager) GetRouteTableCapacities() ([]*database.VPCRouteTableCapacity, error) {
+	return m.RouteTableCapacities, nil
+}
+
+func (m *MockModelsManager) GetSharedServiceEndpoints() ([]*database.SharedServiceEndpoint, error) {
+	return m.SharedServiceEndpoints, nil
+}
+
+func (m *MockModelsManager) GetSharedServiceEndpointByName(name string) (*database.SharedServiceEndpoint, error) {
+	for _, endpoint := range m.SharedServiceEndpoints {
+		if endpoint.Name == name {
+			return endpoint, nil
+		}
+	}
+	return nil, fmt.Errorf("No shared service endpoint named %q", name)
+}
+
+func (m *MockModelsManager) CreateSharedServiceEndpoint(endpoint *database.SharedServiceEndpoint) error {
+	endpoint.ID = uint64(len(m.SharedServiceEndpoints) + 1)
+	m.SharedServiceEndpoints = append(m.SharedServiceEndpoints, endpoint)
+	return nil
+}
+
+func (m *MockModelsManager) DeleteSharedServiceEndpoint(id uint64) error {
+	kept := []*database.SharedServiceEndpoint{}
+	for _, endpoint := range m.SharedServiceEndpoints {
+		if endpoint.ID != id {
+			kept = append(kept, endpoint)
+		}
+	}
+	m.SharedServiceEndpoints = kept
+	return nil
+}
+
+func (m *MockModelsManager) CreateConnectivityTestResult(result *database.ConnectivityTestResult) error {
+	result.ID = uint64(len(m.ConnectivityTestResults) + 1)
+	result.CreatedAt = time.Now()
+	m.ConnectivityTestResults = append(m.ConnectivityTestResults, result)
+	return nil
+}
+
+func (m *MockModelsManager) GetConnectivityTestResults(region database.Region, vpcID string) ([]*database.ConnectivityTestResult, error) {
+	results := []*database.ConnectivityTestResult{}
+	for idx := len(m.ConnectivityTestResults) - 1; idx >= 0; idx-- {
+		result := m.ConnectivityTestResults[idx]
+		if result.Region == region && result.VPCID == vpcID {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func (m *MockModelsManager) UpdateEgressIPs(region database.Region, vpcID string, ips []*database.EgressIP, taskID *uint64) ([]*database.EgressIPEvent, error) {
+	if m.EgressIPs == nil {
+		m.EgressIPs = map[string][]*database.EgressIP{}
+	}
+	key := string(region) + vpcID
+	added, removed := database.DiffEgressIPs(m.EgressIPs[key], ips)
+	events := []*database.EgressIPEvent{}
+	record := func(ip *database.EgressIP, eventType database.EgressIPEventType) {
+		events = append(events, &database.EgressIPEvent{
+			ID:           uint64(len(m.EgressIPEvents) + len(events) + 1),
+			CreatedAt:    time.Now(),
+			Type:         eventType,
+			VPCID:        vpcID,
+			Region:       region,
+			AccountID:    ip.AccountID,
+			AZName:       ip.AZName,
+			AllocationID: ip.AllocationID,
+			PublicIP:     ip.PublicIP,
+			TaskID:       taskID,
+		})
+	}
+	for _, ip := range removed {
+		record(ip, database.EgressIPEventTypeRemoved)
+	}
+	for _, ip := range added {
+		record(ip, database.EgressIPEventTypeAdded)
+	}
+	m.EgressIPs[key] = ips
+	m.EgressIPEvents = append(m.EgressIPEvents, events...)
+	return events, nil
+}
+
+func egressIPFilterMatches(filter *database.EgressIPFilter, region database.Region, vpcID, accountID string) bool {
+	return (filter.Region == "" || filter.Region == region) &&
+		(filter.VPCID == "" || filter.VPCID == vpcID) &&
+		(filter.AccountID == "" || filter.AccountID == accountID)
+}
+
+func (m *MockModelsManager) GetEgressIPs(filter *database.EgressIPFilter) ([]*database.EgressIP, error) {
+	ips := []*database.EgressIP{}
+	for _, vpcIPs := range m.EgressIPs {
+		for _, ip := range vpcIPs {
+			if egressIPFilterMatches(filter, ip.Region, ip.VPCID, ip.AccountID) {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips, nil
+}
+
+func (m *MockModelsManager) GetEgressIPEvents(filter *database.EgressIPFilter) ([]*database.EgressIPEvent, error) {
+	events := []*database.EgressIPEvent{}
+	for idx := len(m.EgressIPEvents) - 1; idx >= 0; idx-- {
+		event := m.EgressIPEvents[idx]
+		if egressIPFilterMatches(filter, event.Region, event.VPCID, event.AccountID) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *MockModelsManager) GetFirewallPolicyTemplates() ([]*database.FirewallPolicyTemplate, error) {
+	return m.FirewallPolicyTemplates, nil
+}
+func (m *MockModelsManager) GetFirewallPolicyTemplate(id uint64) (*database.FirewallPolicyTemplate, error) {
+	for _, template := range m.FirewallPolicyTemplates {
+		if template.ID == id {
+			return template, nil
+		}
+	}
+	return nil, fmt.Errorf("No firewall policy template with id %d", id)
+}
+func (m *MockModelsManager) CreateFirewallPolicyTemplate(template *database.FirewallPolicyTemplate) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) UpdateFirewallPolicyTemplate(id uint64, template *database.FirewallPolicyTemplate) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) DeleteFirewallPolicyTemplate(id uint64) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) GetVPNConnections() ([]*database.VPNConnection, error) {
+	return m.VPNConnections, nil
+}
+func (m *MockModelsManager) GetVPNConnection(id uint64) (*database.VPNConnection, error) {
+	for _, vpn := range m.VPNConnections {
+		if vpn.ID == id {
+			return vpn, nil
+		}
+	}
+	return nil, fmt.Errorf("No VPN connection with id %d", id)
+}
+func (m *MockModelsManager) CreateVPNConnection(vpn *database.VPNConnection) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) UpdateVPNConnection(id uint64, vpn *database.VPNConnection) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) UpdateVPNConnectionState(id uint64, state *database.VPNConnectionState) error {
+	vpn, err := m.GetVPNConnection(id)
+	if err != nil {
+		return err
+	}
+	vpn.State = state
+	return nil
+}
+func (m *MockModelsManager) DeleteVPNConnection(id uint64) error {
+	for idx, vpn := range m.VPNConnections {
+		if vpn.ID == id {
+			m.VPNConnections = append(m.VPNConnections[:idx], m.VPNConnections[idx+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("No VPN connection with id %d", id)
+}
+func (m *MockModelsManager) CreateVPCEndpointSet(*database.VPCEndpointSet) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) UpdateVPCEndpointSet(id uint64, set *database.VPCEndpointSet) error {
+	return fmt.Errorf("Not implemented yet")
+}
+func (m *MockModelsManager) DeleteVPCEndpointSet(id uint64) error {
+	return fmt.Errorf("Not implemented yet")
+}
 func (m *MockModelsManager) GetManagedResolverRuleSets() ([]*database.ManagedResolverRuleSet, error) {
 	return nil, fmt.Errorf("Not implemented yet")
 }
@@ -290,6 +616,94 @@ func (m *MockModelsManager) SetVPCRequestProvisionedVPC(requestID uint64, region
 	return fmt.Errorf("Not implemented yet")
 }
 
+func (m *MockModelsManager) SetVPCRequestJIRAFields(id uint64, assignee, rejectionReason string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) SetVPCRequestTaskStatusNotified(id uint64, status database.TaskStatus) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) SetVPCRequestPolicyViolations(id uint64, violations []string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) GetVPCRequestTemplates() ([]*database.VPCRequestTemplate, error) {
+	return nil, fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) GetVPCRequestTemplate(id uint64) (*database.VPCRequestTemplate, error) {
+	return nil, fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) CreateVPCRequestTemplate(template *database.VPCRequestTemplate) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) UpdateVPCRequestTemplate(id uint64, template *database.VPCRequestTemplate) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) DeleteVPCRequestTemplate(id uint64) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) GetProjectQuotas() ([]*database.ProjectQuota, error) {
+	return nil, fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) GetProjectQuota(projectName string) (*database.ProjectQuota, error) {
+	return nil, fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) SetProjectQuota(quota *database.ProjectQuota) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) DeleteProjectQuota(projectName string) error {
+	return fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) GetProjectUsage(projectName string) (*database.ProjectUsage, error) {
+	return nil, fmt.Errorf("Not implemented yet")
+}
+
+func (m *MockModelsManager) GetVPCRequestComments(vpcRequestID uint64) ([]*database.VPCRequestComment, error) {
+	comments := []*database.VPCRequestComment{}
+	for _, comment := range m.VPCRequestComments {
+		if comment.VPCRequestID == vpcRequestID {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+func (m *MockModelsManager) GetUnsyncedVPCRequestComments() ([]*database.VPCRequestComment, error) {
+	comments := []*database.VPCRequestComment{}
+	for _, comment := range m.VPCRequestComments {
+		if !comment.FromJIRA && comment.JIRACommentID == nil {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+func (m *MockModelsManager) InsertVPCRequestComment(comment *database.VPCRequestComment) (uint64, error) {
+	comment.ID = uint64(len(m.VPCRequestComments) + 1)
+	m.VPCRequestComments = append(m.VPCRequestComments, comment)
+	return comment.ID, nil
+}
+
+func (m *MockModelsManager) SetVPCRequestCommentJIRAID(id uint64, jiraCommentID string) error {
+	for _, comment := range m.VPCRequestComments {
+		if comment.ID == id {
+			comment.JIRACommentID = &jiraCommentID
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
 func (m *MockModelsManager) DeleteVPCCIDRs(vpcID string, region database.Region) error {
 	m.VPCsPrimaryCIDR[string(region)+vpcID] = nil
 	m.VPCsSecondaryCIDRs[string(region)+vpcID] = []string{}
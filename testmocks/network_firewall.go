@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/networkfirewall"
 	"github.com/aws/aws-sdk-go/service/networkfirewall/networkfirewalliface"
 )
@@ -28,9 +29,13 @@ type MockNetworkFirewall struct {
 	SubnetIDToAZ  map[string]string // subnetID -> AZ
 
 	FirewallPolicies             []*networkfirewall.FirewallPolicyMetadata
-	FirewallPoliciesCreated      []string          // policy name
-	Firewalls                    map[string]string // firewall name -> vpcID
-	FirewallsCreated             map[string]string // firewall name -> vpcID
+	FirewallPoliciesCreated      []string            // policy name
+	FirewallPoliciesUpdated      []string            // policy name
+	FirewallPoliciesDeleted      []string            // policy ARN
+	FirewallPolicyAssociations   map[string]string   // firewall name -> policy ARN
+	RuleGroupsCreated            map[string][]string // rule group name -> domains
+	Firewalls                    map[string]string   // firewall name -> vpcID
+	FirewallsCreated             map[string]string   // firewall name -> vpcID
 	RuleGroups                   []*networkfirewall.RuleGroupMetadata
 	CreatedPolicyToRuleGroupARNs map[string][]string // policy name -> [configured rule group ARNs]
 
@@ -39,6 +44,15 @@ type MockNetworkFirewall struct {
 	AssociatedSubnetToEndpoint map[string]string // subnetID -> endpointID
 
 	PreDefinedVPCEQueue []string // VPCE ID
+
+	FirewallPolicyARNsByName  bool                // give created policies distinct ARNs instead of TestARN
+	CreateFirewallPolicyError error               // returned by CreateFirewallPolicy if set
+	StatefulRuleOrders        map[string]string   // created policy or rule group name -> stateful rule order
+	StatefulDefaultActions    map[string][]string // created policy name -> stateful default actions
+}
+
+func PolicyARN(name string) string {
+	return "arn:aws:network-firewall:us-east-1:123456:firewall-policy/" + name
 }
 
 func (m *MockNetworkFirewall) ListFirewalls(input *networkfirewall.ListFirewallsInput) (*networkfirewall.ListFirewallsOutput, error) {
@@ -55,11 +69,21 @@ func (m *MockNetworkFirewall) ListFirewalls(input *networkfirewall.ListFirewalls
 	return &fwOutput, nil
 }
 
-func (m *MockNetworkFirewall) ListFirewallPolicies(*networkfirewall.ListFirewallPoliciesInput) (*networkfirewall.ListFirewallPoliciesOutput, error) {
-	output := &networkfirewall.ListFirewallPoliciesOutput{
-		FirewallPolicies: m.FirewallPolicies,
+// Returns one policy per page so callers have to page through them
+func (m *MockNetworkFirewall) ListFirewallPoliciesPages(input *networkfirewall.ListFirewallPoliciesInput, fn func(*networkfirewall.ListFirewallPoliciesOutput, bool) bool) error {
+	if len(m.FirewallPolicies) == 0 {
+		fn(&networkfirewall.ListFirewallPoliciesOutput{}, true)
+		return nil
 	}
-	return output, nil
+	for idx, fp := range m.FirewallPolicies {
+		output := &networkfirewall.ListFirewallPoliciesOutput{
+			FirewallPolicies: []*networkfirewall.FirewallPolicyMetadata{fp},
+		}
+		if !fn(output, idx == len(m.FirewallPolicies)-1) {
+			break
+		}
+	}
+	return nil
 }
 
 func (m *MockNetworkFirewall) ListRuleGroupsPages(input *networkfirewall.ListRuleGroupsInput, fn func(*networkfirewall.ListRuleGroupsOutput, bool) bool) error {
@@ -71,11 +95,26 @@ func (m *MockNetworkFirewall) ListRuleGroupsPages(input *networkfirewall.ListRul
 }
 
 func (m *MockNetworkFirewall) CreateFirewallPolicy(input *networkfirewall.CreateFirewallPolicyInput) (*networkfirewall.CreateFirewallPolicyOutput, error) {
+	if m.CreateFirewallPolicyError != nil {
+		return nil, m.CreateFirewallPolicyError
+	}
 	m.FirewallPoliciesCreated = append(m.FirewallPoliciesCreated, aws.StringValue(input.FirewallPolicyName))
+	if opts := input.FirewallPolicy.StatefulEngineOptions; opts != nil {
+		m.recordRuleOrder(input.FirewallPolicyName, opts.RuleOrder)
+	}
+	if actions := input.FirewallPolicy.StatefulDefaultActions; len(actions) > 0 {
+		if m.StatefulDefaultActions == nil {
+			m.StatefulDefaultActions = make(map[string][]string)
+		}
+		m.StatefulDefaultActions[aws.StringValue(input.FirewallPolicyName)] = aws.StringValueSlice(actions)
+	}
 	data := &networkfirewall.FirewallPolicyMetadata{
 		Name: input.FirewallPolicyName,
 		Arn:  aws.String(TestARN),
 	}
+	if m.FirewallPolicyARNsByName {
+		data.Arn = aws.String(PolicyARN(aws.StringValue(input.FirewallPolicyName)))
+	}
 	m.FirewallPolicies = append(m.FirewallPolicies, data)
 	if m.CreatedPolicyToRuleGroupARNs == nil {
 		m.CreatedPolicyToRuleGroupARNs = make(map[string][]string)
@@ -87,7 +126,19 @@ func (m *MockNetworkFirewall) CreateFirewallPolicy(input *networkfirewall.Create
 	for _, rg := range input.FirewallPolicy.StatelessRuleGroupReferences {
 		m.CreatedPolicyToRuleGroupARNs[firewallName] = append(m.CreatedPolicyToRuleGroupARNs[firewallName], aws.StringValue(rg.ResourceArn))
 	}
-	return &networkfirewall.CreateFirewallPolicyOutput{}, nil
+	return &networkfirewall.CreateFirewallPolicyOutput{
+		FirewallPolicyResponse: &networkfirewall.FirewallPolicyResponse{
+			FirewallPolicyArn:  data.Arn,
+			FirewallPolicyName: data.Name,
+		},
+	}, nil
+}
+
+func (m *MockNetworkFirewall) recordRuleOrder(name *string, order *string) {
+	if m.StatefulRuleOrders == nil {
+		m.StatefulRuleOrders = make(map[string]string)
+	}
+	m.StatefulRuleOrders[aws.StringValue(name)] = aws.StringValue(order)
 }
 
 func (m *MockNetworkFirewall) AssociateSubnets(input *networkfirewall.AssociateSubnetsInput) (*networkfirewall.AssociateSubnetsOutput, error) {
@@ -125,6 +176,11 @@ func (m *MockNetworkFirewall) CreateFirewall(input *networkfirewall.CreateFirewa
 	}
 	m.Firewalls[aws.StringValue(input.FirewallName)] = aws.StringValue(input.VpcId)
 
+	if m.FirewallPolicyAssociations == nil {
+		m.FirewallPolicyAssociations = make(map[string]string)
+	}
+	m.FirewallPolicyAssociations[aws.StringValue(input.FirewallName)] = aws.StringValue(input.FirewallPolicyArn)
+
 	if m.AssociatedSubnetToEndpoint == nil {
 		m.AssociatedSubnetToEndpoint = make(map[string]string)
 	}
@@ -152,9 +208,10 @@ func (m *MockNetworkFirewall) DescribeFirewall(input *networkfirewall.DescribeFi
 
 	output := &networkfirewall.DescribeFirewallOutput{
 		Firewall: &networkfirewall.Firewall{
-			FirewallName: input.FirewallName,
-			FirewallArn:  aws.String(fmt.Sprintf("arn:aws:network-firewall:%s:%s:firewall/%s", m.Region, m.AccountID, *input.FirewallName)),
-			VpcId:        aws.String(m.Firewalls[aws.StringValue(input.FirewallName)]),
+			FirewallName:      input.FirewallName,
+			FirewallArn:       aws.String(fmt.Sprintf("arn:aws:network-firewall:%s:%s:firewall/%s", m.Region, m.AccountID, *input.FirewallName)),
+			VpcId:             aws.String(m.Firewalls[aws.StringValue(input.FirewallName)]),
+			FirewallPolicyArn: aws.String(m.FirewallPolicyAssociations[aws.StringValue(input.FirewallName)]),
 		},
 		FirewallStatus: &networkfirewall.FirewallStatus{
 			SyncStates: make(map[string]*networkfirewall.SyncState),
@@ -176,3 +233,126 @@ func (m *MockNetworkFirewall) DescribeFirewall(input *networkfirewall.DescribeFi
 	}
 	return output, nil
 }
+
+func (m *MockNetworkFirewall) policyByARN(arn string) *networkfirewall.FirewallPolicyMetadata {
+	for _, fp := range m.FirewallPolicies {
+		if aws.StringValue(fp.Arn) == arn {
+			return fp
+		}
+	}
+	return nil
+}
+
+func (m *MockNetworkFirewall) DescribeFirewallPolicy(input *networkfirewall.DescribeFirewallPolicyInput) (*networkfirewall.DescribeFirewallPolicyOutput, error) {
+	fp := m.policyByARN(aws.StringValue(input.FirewallPolicyArn))
+	if fp == nil {
+		return nil, awserr.New(networkfirewall.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	return &networkfirewall.DescribeFirewallPolicyOutput{
+		FirewallPolicyResponse: &networkfirewall.FirewallPolicyResponse{
+			FirewallPolicyArn:  fp.Arn,
+			FirewallPolicyName: fp.Name,
+		},
+		UpdateToken: aws.String("token"),
+	}, nil
+}
+
+func (m *MockNetworkFirewall) UpdateFirewallPolicy(input *networkfirewall.UpdateFirewallPolicyInput) (*networkfirewall.UpdateFirewallPolicyOutput, error) {
+	fp := m.policyByARN(aws.StringValue(input.FirewallPolicyArn))
+	if fp == nil {
+		return nil, awserr.New(networkfirewall.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	m.FirewallPoliciesUpdated = append(m.FirewallPoliciesUpdated, aws.StringValue(fp.Name))
+	return &networkfirewall.UpdateFirewallPolicyOutput{}, nil
+}
+
+func (m *MockNetworkFirewall) DeleteFirewallPolicy(input *networkfirewall.DeleteFirewallPolicyInput) (*networkfirewall.DeleteFirewallPolicyOutput, error) {
+	arn := aws.StringValue(input.FirewallPolicyArn)
+	for _, associated := range m.FirewallPolicyAssociations {
+		if associated == arn {
+			return nil, awserr.New(networkfirewall.ErrCodeInvalidOperationException, "policy in use", nil)
+		}
+	}
+	kept := []*networkfirewall.FirewallPolicyMetadata{}
+	for _, fp := range m.FirewallPolicies {
+		if aws.StringValue(fp.Arn) != arn {
+			kept = append(kept, fp)
+		}
+	}
+	m.FirewallPolicies = kept
+	m.FirewallPoliciesDeleted = append(m.FirewallPoliciesDeleted, arn)
+	return &networkfirewall.DeleteFirewallPolicyOutput{}, nil
+}
+
+func (m *MockNetworkFirewall) AssociateFirewallPolicy(input *networkfirewall.AssociateFirewallPolicyInput) (*networkfirewall.AssociateFirewallPolicyOutput, error) {
+	if _, ok := m.Firewalls[aws.StringValue(input.FirewallName)]; !ok {
+		return nil, awserr.New(networkfirewall.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	if m.FirewallPolicyAssociations == nil {
+		m.FirewallPolicyAssociations = make(map[string]string)
+	}
+	m.FirewallPolicyAssociations[aws.StringValue(input.FirewallName)] = aws.StringValue(input.FirewallPolicyArn)
+	return &networkfirewall.AssociateFirewallPolicyOutput{}, nil
+}
+
+func (m *MockNetworkFirewall) DescribeRuleGroup(input *networkfirewall.DescribeRuleGroupInput) (*networkfirewall.DescribeRuleGroupOutput, error) {
+	name := aws.StringValue(input.RuleGroupName)
+	for _, rg := range m.RuleGroups {
+		if aws.StringValue(rg.Name) == name {
+			return &networkfirewall.DescribeRuleGroupOutput{
+				RuleGroupResponse: &networkfirewall.RuleGroupResponse{
+					RuleGroupArn:  rg.Arn,
+					RuleGroupName: rg.Name,
+				},
+				UpdateToken: aws.String("token"),
+			}, nil
+		}
+	}
+	return nil, awserr.New(networkfirewall.ErrCodeResourceNotFoundException, "not found", nil)
+}
+
+func (m *MockNetworkFirewall) CreateRuleGroup(input *networkfirewall.CreateRuleGroupInput) (*networkfirewall.CreateRuleGroupOutput, error) {
+	name := aws.StringValue(input.RuleGroupName)
+	arn := aws.String("arn:aws:network-firewall:us-east-1:123456:stateful-rulegroup/" + name)
+	m.RuleGroups = append(m.RuleGroups, &networkfirewall.RuleGroupMetadata{Name: input.RuleGroupName, Arn: arn})
+	if m.RuleGroupsCreated == nil {
+		m.RuleGroupsCreated = make(map[string][]string)
+	}
+	m.RuleGroupsCreated[name] = aws.StringValueSlice(input.RuleGroup.RulesSource.RulesSourceList.Targets)
+	if opts := input.RuleGroup.StatefulRuleOptions; opts != nil {
+		m.recordRuleOrder(input.RuleGroupName, opts.RuleOrder)
+	}
+	return &networkfirewall.CreateRuleGroupOutput{
+		RuleGroupResponse: &networkfirewall.RuleGroupResponse{RuleGroupArn: arn, RuleGroupName: input.RuleGroupName},
+	}, nil
+}
+
+func (m *MockNetworkFirewall) DeleteRuleGroup(input *networkfirewall.DeleteRuleGroupInput) (*networkfirewall.DeleteRuleGroupOutput, error) {
+	kept := []*networkfirewall.RuleGroupMetadata{}
+	found := false
+	for _, rg := range m.RuleGroups {
+		if aws.StringValue(rg.Name) == aws.StringValue(input.RuleGroupName) || aws.StringValue(rg.Arn) == aws.StringValue(input.RuleGroupArn) {
+			found = true
+		} else {
+			kept = append(kept, rg)
+		}
+	}
+	if !found {
+		return nil, awserr.New(networkfirewall.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	m.RuleGroups = kept
+	return &networkfirewall.DeleteRuleGroupOutput{}, nil
+}
+
+func (m *MockNetworkFirewall) UpdateRuleGroup(input *networkfirewall.UpdateRuleGroupInput) (*networkfirewall.UpdateRuleGroupOutput, error) {
+	for _, rg := range m.RuleGroups {
+		if aws.StringValue(rg.Arn) == aws.StringValue(input.RuleGroupArn) {
+			if m.RuleGroupsCreated == nil {
+				m.RuleGroupsCreated = make(map[string][]string)
+			}
+			m.RuleGroupsCreated[aws.StringValue(rg.Name)] = aws.StringValueSlice(input.RuleGroup.RulesSource.RulesSourceList.Targets)
+			return &networkfirewall.UpdateRuleGroupOutput{}, nil
+		}
+	}
+	return nil, awserr.New(networkfirewall.ErrCodeResourceNotFoundException, "not found", nil)
+}
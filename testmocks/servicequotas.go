@@ -0,0 +1,38 @@
+package testmocks
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
+)
+
+type MockServiceQuotas struct {
+	servicequotasiface.ServiceQuotasAPI
+
+	AppliedQuotas map[string]float64 // quota code -> value
+	DefaultQuotas map[string]float64 // quota code -> value
+	Err           error              // returned by every call if set
+}
+
+func (m *MockServiceQuotas) GetServiceQuota(input *servicequotas.GetServiceQuotaInput) (*servicequotas.GetServiceQuotaOutput, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	value, ok := m.AppliedQuotas[aws.StringValue(input.QuotaCode)]
+	if !ok {
+		return nil, awserr.New(servicequotas.ErrCodeNoSuchResourceException, "No applied quota", nil)
+	}
+	return &servicequotas.GetServiceQuotaOutput{Quota: &servicequotas.ServiceQuota{QuotaCode: input.QuotaCode, Value: aws.Float64(value)}}, nil
+}
+
+func (m *MockServiceQuotas) GetAWSDefaultServiceQuota(input *servicequotas.GetAWSDefaultServiceQuotaInput) (*servicequotas.GetAWSDefaultServiceQuotaOutput, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	value, ok := m.DefaultQuotas[aws.StringValue(input.QuotaCode)]
+	if !ok {
+		return nil, awserr.New(servicequotas.ErrCodeNoSuchResourceException, "No such quota", nil)
+	}
+	return &servicequotas.GetAWSDefaultServiceQuotaOutput{Quota: &servicequotas.ServiceQuota{QuotaCode: input.QuotaCode, Value: aws.Float64(value)}}, nil
+}
@@ -0,0 +1,151 @@
+package ticketing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// A Provider is a ticketing system that VPC requests are tracked in.
+type Provider interface {
+	Name() string
+	// Owns reports whether the issue key was issued by this provider
+	Owns(issueKey string) bool
+	// IssueKeyFromURL returns "" if the URL isn't an issue in this provider
+	IssueKeyFromURL(url string) string
+	IssueURL(issueKey string) string
+	// ServiceAccount is the user vpc-conf creates issues and comments as
+	ServiceAccount() string
+
+	CreateIssue(details *IssueDetails) (string, error)
+	// SearchIssues fetches all of the given issues in as few calls as possible.
+	// Keys that don't exist are skipped.
+	SearchIssues(keys []string) ([]*Issue, error)
+	// AddComment returns the provider's ID for the new comment, or "" if the
+	// comment was posted but its ID couldn't be found.
+	AddComment(issueKey, comment string) (string, error)
+	PostComment(issueKey, comment string) error
+	// SetIssueStatus moves the issue to the provider's state for the status
+	SetIssueStatus(issueKey string, status database.VPCRequestStatus) error
+}
+
+type IssueDetails struct {
+	Reporter    string
+	Summary     string
+	Description string
+	Labels      []string
+}
+
+// Issue is the subset of an issue that gets synced back to VPC requests.
+type Issue struct {
+	Key        string
+	Status     database.VPCRequestStatus // StatusUnknown if the provider's state isn't mapped
+	Assignee   string                    // "" if unassigned
+	Resolution string                    // "" if unresolved
+	Updated    time.Time                 // last change to the issue, including comments
+	Comments   []*Comment
+}
+
+type Comment struct {
+	ID      string
+	Author  string
+	Body    string
+	Created time.Time
+}
+
+// Routes chooses the provider for new issues by name. Account overrides
+// take precedence over project overrides, which take precedence over Default.
+type Routes struct {
+	Default  string
+	Projects map[string]string // project name -> provider name
+	Accounts map[string]string // AWS account ID -> provider name
+}
+
+type Router struct {
+	Routes
+	providers map[string]Provider
+}
+
+func NewRouter(routes Routes, providers ...Provider) (*Router, error) {
+	r := &Router{
+		Routes:    routes,
+		providers: map[string]Provider{},
+	}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	names := []string{routes.Default}
+	for _, name := range routes.Projects {
+		names = append(names, name)
+	}
+	for _, name := range routes.Accounts {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if r.providers[name] == nil {
+			return nil, fmt.Errorf("No ticketing provider named %q", name)
+		}
+	}
+	return r, nil
+}
+
+// Providers returns all configured providers, sorted by name.
+func (r *Router) Providers() []Provider {
+	providers := []Provider{}
+	for _, p := range r.providers {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name() < providers[j].Name()
+	})
+	return providers
+}
+
+// For returns the provider that new issues for the given account and project go to.
+func (r *Router) For(accountID, projectName string) Provider {
+	if name, ok := r.Accounts[accountID]; ok {
+		return r.providers[name]
+	}
+	if name, ok := r.Projects[projectName]; ok {
+		return r.providers[name]
+	}
+	return r.providers[r.Default]
+}
+
+// ForIssue returns the provider that owns the issue, or nil if none of them
+// do (e.g. issues in a retired JIRA project).
+func (r *Router) ForIssue(issueKey string) Provider {
+	for _, p := range r.Providers() {
+		if p.Owns(issueKey) {
+			return p
+		}
+	}
+	return nil
+}
+
+// IssueURL links to an issue given either its key or a full URL.
+func (r *Router) IssueURL(issue string) string {
+	if strings.HasPrefix(issue, "https://") {
+		return issue
+	}
+	p := r.ForIssue(issue)
+	if p == nil {
+		p = r.providers[r.Default]
+	}
+	return p.IssueURL(issue)
+}
+
+// IssueKeyFromURLs returns the key of the first URL that is an issue in any provider.
+func (r *Router) IssueKeyFromURLs(urls []string) string {
+	for _, url := range urls {
+		for _, p := range r.Providers() {
+			if key := p.IssueKeyFromURL(url); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package ticketing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+type fakeProvider struct {
+	name, prefix, baseURL string
+}
+
+func (p *fakeProvider) Name() string              { return p.name }
+func (p *fakeProvider) Owns(issueKey string) bool { return strings.HasPrefix(issueKey, p.prefix) }
+func (p *fakeProvider) IssueKeyFromURL(url string) string {
+	if !strings.HasPrefix(url, p.baseURL) {
+		return ""
+	}
+	return strings.TrimPrefix(url, p.baseURL)
+}
+func (p *fakeProvider) IssueURL(issueKey string) string                   { return p.baseURL + issueKey }
+func (p *fakeProvider) ServiceAccount() string                            { return p.name + "-bot" }
+func (p *fakeProvider) CreateIssue(details *IssueDetails) (string, error) { return p.prefix + "1", nil }
+func (p *fakeProvider) SearchIssues(keys []string) ([]*Issue, error)      { return nil, nil }
+func (p *fakeProvider) AddComment(issueKey, comment string) (string, error) {
+	return "", nil
+}
+func (p *fakeProvider) PostComment(issueKey, comment string) error { return nil }
+func (p *fakeProvider) SetIssueStatus(issueKey string, status database.VPCRequestStatus) error {
+	return nil
+}
+
+func TestRouter(t *testing.T) {
+	jira := &fakeProvider{name: "jira", prefix: "VPCAPP-", baseURL: "https://jira.example.com/browse/"}
+	snow := &fakeProvider{name: "servicenow", prefix: "RITM", baseURL: "https://snow.example.com/ritm/"}
+
+	_, err := NewRouter(Routes{Default: "github"}, jira, snow)
+	if err == nil {
+		t.Errorf("Expected an error routing to an unconfigured provider")
+	}
+
+	r, err := NewRouter(Routes{
+		Default:  "jira",
+		Projects: map[string]string{"Snow Project": "servicenow"},
+		Accounts: map[string]string{"111111111111": "servicenow", "222222222222": "jira"},
+	}, jira, snow)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, tc := range []struct {
+		accountID, project string
+		expected           Provider
+	}{
+		{"333333333333", "Other Project", jira},
+		{"333333333333", "Snow Project", snow},
+		{"111111111111", "Other Project", snow},
+		{"222222222222", "Snow Project", jira},
+	} {
+		if p := r.For(tc.accountID, tc.project); p != tc.expected {
+			t.Errorf("For(%q, %q): expected %s but got %s", tc.accountID, tc.project, tc.expected.Name(), p.Name())
+		}
+	}
+
+	if p := r.ForIssue("RITM0001"); p != snow {
+		t.Errorf("Expected RITM0001 to belong to servicenow")
+	}
+	if p := r.ForIssue("OLDPROJ-1"); p != nil {
+		t.Errorf("Expected OLDPROJ-1 to belong to no provider but got %s", p.Name())
+	}
+
+	for issue, expected := range map[string]string{
+		"VPCAPP-1":                  "https://jira.example.com/browse/VPCAPP-1",
+		"RITM0001":                  "https://snow.example.com/ritm/RITM0001",
+		"OLDPROJ-1":                 "https://jira.example.com/browse/OLDPROJ-1",
+		"https://example.com/RITM2": "https://example.com/RITM2",
+	} {
+		if url := r.IssueURL(issue); url != expected {
+			t.Errorf("IssueURL(%q): expected %q but got %q", issue, expected, url)
+		}
+	}
+
+	key := r.IssueKeyFromURLs([]string{"https://confluence.example.com/x", "https://snow.example.com/ritm/RITM0002"})
+	if key != "RITM0002" {
+		t.Errorf("Expected RITM0002 but got %q", key)
+	}
+}
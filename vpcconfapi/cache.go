@@ -0,0 +1,88 @@
+package vpcconfapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeCacheKeyCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FileCache keeps JSON responses on local disk between runs
+type FileCache struct {
+	Dir string
+}
+
+type fileCacheEntry struct {
+	ExpiresAt time.Time
+	Value     json.RawMessage
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.Dir, unsafeCacheKeyCharacters.ReplaceAllString(key, "_")+".json")
+}
+
+// Get fills in value from the cache and returns true if key is cached and
+// hasn't expired
+func (c *FileCache) Get(key string, value interface{}) bool {
+	buf, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+	entry := &fileCacheEntry{}
+	err = json.Unmarshal(buf, entry)
+	if err != nil || time.Now().After(entry.ExpiresAt) {
+		return false
+	}
+	return json.Unmarshal(entry.Value, value) == nil
+}
+
+// Set caches value under key for ttl
+func (c *FileCache) Set(key string, value interface{}, ttl time.Duration) error {
+	valueBuf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(&fileCacheEntry{ExpiresAt: time.Now().Add(ttl), Value: valueBuf})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(c.Dir, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create cache directory %q - %s", c.Dir, err)
+	}
+	// Write then rename so that concurrent runs never read a partial entry
+	tmp, err := ioutil.TempFile(c.Dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(buf)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+// Clear removes every entry from the cache
+func (c *FileCache) Clear() error {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		err = os.Remove(f)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package vpcconfapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// RouteTableCapacityReportEntry same as vpc-conf
+type RouteTableCapacityReportEntry struct {
+	VPCID        string
+	Region       database.Region
+	AccountID    string
+	VPCName      string
+	RouteTableID string
+	SubnetType   database.SubnetType
+	Routes       int
+	Quota        int
+	UpdatedAt    time.Time
+}
+
+func vpcURL(baseURL string, vpc VPC) string {
+	return fmt.Sprintf("%s/%s/vpc/%s/%s", baseURL, vpc.Region, vpc.AccountID, vpc.ID)
+}
+
+// GetRouteTables returns every route table in the VPC as it is in AWS now,
+// keyed by route table ID
+func (api *VPCConfAPI) GetRouteTables(vpc VPC) (map[string]*database.RouteTableSnapshotEntry, error) {
+	routeTablesURL := vpcURL(api.BaseURL, vpc) + "/routeTables.json"
+
+	req, err := http.NewRequest("GET", routeTablesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %q - %s", routeTablesURL, err)
+	}
+
+	routeTables := map[string]*database.RouteTableSnapshotEntry{}
+
+	err = api.doRequest(req, &routeTables)
+	if err != nil {
+		return nil, err
+	}
+
+	return routeTables, nil
+}
+
+// SnapshotRouteTables queues a task to back up the VPC's route tables on
+// the server and returns the task ID
+func (api *VPCConfAPI) SnapshotRouteTables(vpc VPC) (uint64, error) {
+	snapshotURL := vpcURL(api.BaseURL, vpc) + "/routeSnapshots"
+
+	req, err := http.NewRequest("POST", snapshotURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to create request for %q - %s", snapshotURL, err)
+	}
+
+	result := &struct {
+		TaskID uint64
+	}{}
+
+	err = api.doRequest(req, result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.TaskID, nil
+}
+
+// GetRouteTableCapacityReport returns the route tables with at least
+// threshold of their routes per route table quota, fullest first
+func (api *VPCConfAPI) GetRouteTableCapacityReport(threshold float64) ([]*RouteTableCapacityReportEntry, error) {
+	reportURL := fmt.Sprintf("%s/routeTableCapacity.json?threshold=%g", api.BaseURL, threshold)
+
+	req, err := http.NewRequest("GET", reportURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %q - %s", reportURL, err)
+	}
+
+	report := []*RouteTableCapacityReportEntry{}
+
+	err = api.doRequest(req, &report)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// RouteTarget returns whatever the route points at
+func RouteTarget(route *database.SnapshotRoute) string {
+	for _, id := range []string{
+		route.TransitGatewayID,
+		route.NATGatewayID,
+		route.InternetGatewayID,
+		route.EgressOnlyInternetGatewayID,
+		route.PeeringConnectionID,
+		route.VPCEndpointID,
+		route.OtherTargetID,
+	} {
+		if id != "" {
+			return id
+		}
+	}
+	return "blackhole"
+}
+
+// cidrIsWithin returns whether needle is a strictly smaller block inside
+// haystack
+func cidrIsWithin(needle, haystack string) (bool, error) {
+	needleIP, needleBlock, err := net.ParseCIDR(needle)
+	if err != nil {
+		return false, err
+	}
+	_, haystackBlock, err := net.ParseCIDR(haystack)
+	if err != nil {
+		return false, err
+	}
+	needleOnes, _ := needleBlock.Mask.Size()
+	haystackOnes, _ := haystackBlock.Mask.Size()
+	return haystackBlock.Contains(needleIP) && needleOnes > haystackOnes, nil
+}
+
+// FindSupernets returns the routes that are less specific than any of cidrs
+// and so could send that traffic somewhere else if the more specific route
+// goes away. Routes to NAT and internet gateways are assumed to go off-net
+// and prefix list routes can't be compared, so both are skipped.
+func FindSupernets(routes []*database.SnapshotRoute, cidrs []string) ([]*database.SnapshotRoute, error) {
+	supernets := []*database.SnapshotRoute{}
+	for _, r := range routes {
+		if r.NATGatewayID != "" || r.InternetGatewayID != "" || isPrefixListID(r.Destination) {
+			continue
+		}
+		for _, cidr := range cidrs {
+			within, err := cidrIsWithin(cidr, r.Destination)
+			if err != nil {
+				return nil, fmt.Errorf("Error comparing %s to %s: %s", cidr, r.Destination, err)
+			}
+			if within {
+				supernets = append(supernets, r)
+				break
+			}
+		}
+	}
+	return supernets, nil
+}
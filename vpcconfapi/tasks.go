@@ -0,0 +1,74 @@
+package vpcconfapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+)
+
+// TaskDetails is a single task and its log so far
+type TaskDetails struct {
+	ID          uint64
+	Description string
+	Status      database.TaskStatus
+	Log         []*database.LogEntry
+}
+
+// IsDone returns whether the task has finished, one way or another
+func (t *TaskDetails) IsDone() bool {
+	return t.Status == database.TaskStatusSuccessful ||
+		t.Status == database.TaskStatusFailed ||
+		t.Status == database.TaskStatusCancelled
+}
+
+// GetTask retrieves a task and its log
+func (api *VPCConfAPI) GetTask(taskID uint64) (*TaskDetails, error) {
+	taskURL := fmt.Sprintf("%s/task/%d.json", api.BaseURL, taskID)
+
+	req, err := http.NewRequest("GET", taskURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %q - %s", taskURL, err)
+	}
+
+	task := &TaskDetails{}
+
+	err = api.doRequest(req, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// TailTask polls the task every interval, passing each new log entry to
+// onLog, until the task is done or ctx is cancelled. The finished task is
+// returned.
+func (api *VPCConfAPI) TailTask(ctx context.Context, taskID uint64, interval time.Duration, onLog func(*database.LogEntry)) (*TaskDetails, error) {
+	seen := 0
+	for {
+		task, err := api.GetTask(taskID)
+		if err != nil {
+			return nil, err
+		}
+		// A shorter log means it couldn't all be read this time, so only
+		// entries past the ones already passed on are new
+		if len(task.Log) > seen {
+			for _, entry := range task.Log[seen:] {
+				onLog(entry)
+			}
+			seen = len(task.Log)
+		}
+		if task.IsDone() {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,6 +23,10 @@ type VPCConfAPI struct {
 	SessionID          string
 	BaseURL            string
 	Expiry             *time.Time
+	// Cache, if set, keeps slow-changing lists such as the automated VPCs
+	// between runs
+	Cache    *FileCache
+	CacheTTL time.Duration
 }
 
 //VPC exceptions from VPC Conf
@@ -31,16 +38,125 @@ type VPC struct {
 	Region    database.Region
 }
 
-// VerifyBatchTask container
-type VerifyBatchTask struct {
+// BatchTaskRequest is a batch task submission. The template IDs are added to
+// or removed from each VPC's config before the tasks are queued.
+type BatchTaskRequest struct {
 	TaskTypes  database.TaskTypes
 	VerifySpec database.VerifySpec
 	VPCs       []VPC
+
+	// Selector or SelectorName pick the VPCs instead of VPCs. The server
+	// resolves them and records the VPCs they matched on the batch task.
+	Selector     string `json:",omitempty"`
+	SelectorName string `json:",omitempty"`
+
+	AddSecurityGroupSets    []uint64 `json:",omitempty"`
+	RemoveSecurityGroupSets []uint64 `json:",omitempty"`
+
+	AddManagedTransitGatewayAttachments    []uint64 `json:",omitempty"`
+	RemoveManagedTransitGatewayAttachments []uint64 `json:",omitempty"`
+
+	AddResolverRuleSets    []uint64 `json:",omitempty"`
+	RemoveResolverRuleSets []uint64 `json:",omitempty"`
+
+	AddVPCEndpointSets    []uint64 `json:",omitempty"`
+	RemoveVPCEndpointSets []uint64 `json:",omitempty"`
+}
+
+// TaskTypeNames are the names of the batch task types for command lines
+var TaskTypeNames = map[string]database.TaskTypes{
+	"networking":      database.TaskTypeNetworking,
+	"repair":          database.TaskTypeRepair,
+	"security-groups": database.TaskTypeSecurityGroups,
+	"resolver-rules":  database.TaskTypeResolverRules,
+	"verify":          database.TaskTypeVerifyState,
+	"logging":         database.TaskTypeLogging,
+	"sync-routes":     database.TaskTypeSyncRoutes,
+}
+
+// ParseTaskTypes parses a comma separated list of task type names
+func ParseTaskTypes(s string) (database.TaskTypes, error) {
+	var taskTypes database.TaskTypes
+	for _, name := range strings.Split(s, ",") {
+		taskType, ok := TaskTypeNames[strings.TrimSpace(name)]
+		if !ok {
+			names := []string{}
+			for name := range TaskTypeNames {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return 0, fmt.Errorf("Invalid task type %q; valid types are %s", name, strings.Join(names, ", "))
+		}
+		taskTypes |= taskType
+	}
+	return taskTypes, nil
+}
+
+// FormatTaskTypes is the reverse of ParseTaskTypes
+func FormatTaskTypes(taskTypes database.TaskTypes) string {
+	names := []string{}
+	for name, taskType := range TaskTypeNames {
+		if taskTypes.Includes(taskType) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// verifyFields are the names of the VerifySpec fields for command lines
+var verifyFields = map[string]func(spec *database.VerifySpec) *bool{
+	"networking":      func(spec *database.VerifySpec) *bool { return &spec.VerifyNetworking },
+	"logging":         func(spec *database.VerifySpec) *bool { return &spec.VerifyLogging },
+	"resolver-rules":  func(spec *database.VerifySpec) *bool { return &spec.VerifyResolverRules },
+	"security-groups": func(spec *database.VerifySpec) *bool { return &spec.VerifySecurityGroups },
+	"cidrs":           func(spec *database.VerifySpec) *bool { return &spec.VerifyCIDRs },
+	"cmsnet":          func(spec *database.VerifySpec) *bool { return &spec.VerifyCMSNet },
+	"vpc-endpoints":   func(spec *database.VerifySpec) *bool { return &spec.VerifyVPCEndpoints },
+	"firewall-policy": func(spec *database.VerifySpec) *bool { return &spec.VerifyFirewallPolicy },
+}
+
+// ParseVerifySpec parses a comma separated list of the parts of a VPC to
+// verify, or "all"
+func ParseVerifySpec(s string) (database.VerifySpec, error) {
+	if strings.TrimSpace(s) == "all" {
+		return database.VerifyAllSpec(), nil
+	}
+	spec := database.VerifySpec{}
+	for _, name := range strings.Split(s, ",") {
+		field, ok := verifyFields[strings.TrimSpace(name)]
+		if !ok {
+			names := []string{"all"}
+			for name := range verifyFields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return spec, fmt.Errorf("Invalid verify type %q; valid types are %s", name, strings.Join(names, ", "))
+		}
+		*field(&spec) = true
+	}
+	return spec, nil
+}
+
+// FormatVerifySpec is the reverse of ParseVerifySpec
+func FormatVerifySpec(spec database.VerifySpec) string {
+	if spec == database.VerifyAllSpec() {
+		return "all"
+	}
+	names := []string{}
+	for name, field := range verifyFields {
+		if *field(&spec) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
 }
 
 // BatchTaskResult returns the ID
 type BatchTaskResult struct {
 	BatchTaskID int
+	Description string
 }
 
 // BatchTaskInfo same as vpc-conf
@@ -49,6 +165,7 @@ type BatchTaskInfo struct {
 	Description string
 	AddedAt     time.Time
 	Tasks       []*Task
+	Selector    *database.BatchTaskSelector
 }
 
 // GetBatchTaskProgress returns the current state of this BatchTaskInfo
@@ -115,6 +232,34 @@ func (api *VPCConfAPI) setHeader(req *http.Request) {
 	}
 }
 
+// HTTPError is a vpc-conf response with a status other than 200 OK
+type HTTPError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Message    string // the start of the response body
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("ERROR: response for %q - %s: %s", e.URL, e.Status, e.Message)
+	}
+	return fmt.Sprintf("ERROR: response for %q - %s", e.URL, e.Status)
+}
+
+// IsPermanent returns whether err is a response that retrying the same
+// request won't change, like asking for a batch task that doesn't exist
+func IsPermanent(err error) bool {
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		return false
+	}
+	if httpErr.StatusCode == http.StatusRequestTimeout || httpErr.StatusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return httpErr.StatusCode >= 400 && httpErr.StatusCode < 500
+}
+
 func (api *VPCConfAPI) doRequest(req *http.Request, jsonStruct interface{}) error {
 	err := api.VerifySession()
 	if err != nil {
@@ -133,7 +278,8 @@ func (api *VPCConfAPI) doRequest(req *http.Request, jsonStruct interface{}) erro
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("ERROR: response for %q - %s", req.RequestURI, resp.Status)
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &HTTPError{URL: req.URL.String(), StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(body))}
 	}
 
 	if jsonStruct != nil {
@@ -146,6 +292,31 @@ func (api *VPCConfAPI) doRequest(req *http.Request, jsonStruct interface{}) erro
 	return nil
 }
 
+// getCached fetches the JSON at path into jsonStruct, using the cache if
+// there is one
+func (api *VPCConfAPI) getCached(path string, jsonStruct interface{}) error {
+	if api.Cache != nil && api.Cache.Get(api.BaseURL+path, jsonStruct) {
+		return nil
+	}
+
+	req, err := http.NewRequest("GET", api.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create request for %q - %s", api.BaseURL+path, err)
+	}
+	err = api.doRequest(req, jsonStruct)
+	if err != nil {
+		return err
+	}
+
+	if api.Cache != nil {
+		err = api.Cache.Set(api.BaseURL+path, jsonStruct, api.CacheTTL)
+		if err != nil {
+			log.Printf("Failed to cache %q - %s", api.BaseURL+path, err)
+		}
+	}
+	return nil
+}
+
 // Login to VPC Conf and store the sessionID
 func (api *VPCConfAPI) Login() error {
 	loginURL := api.BaseURL + "/login"
@@ -195,26 +366,24 @@ func (api *VPCConfAPI) GetAutomatedVPCsAndRegions() ([]VPC, []string, error) {
 
 	log.Printf("Fetch vpcs from %q", vpcsURL)
 
-	req, err := http.NewRequest("GET", vpcsURL, nil)
-	if err != nil {
-		return []VPC{}, []string{}, fmt.Errorf("Failed to create request for %q - %s", vpcsURL, err)
-	}
-
 	automatedRequest := &struct {
 		Regions []string
 		VPCs    []VPC
 	}{}
 
-	err = api.doRequest(req, automatedRequest)
+	err := api.getCached("/batch/vpcs.json", automatedRequest)
 
 	return automatedRequest.VPCs, automatedRequest.Regions, err
 }
 
 // SubmitVerifyBatchTask ...
 func (api *VPCConfAPI) SubmitVerifyBatchTask(vpcs []VPC, verifySpec database.VerifySpec) (*BatchTaskResult, error) {
-	verifyTaskURL := api.BaseURL + "/batch"
+	return api.SubmitBatchTask(&BatchTaskRequest{TaskTypes: database.TaskTypeVerifyState, VPCs: vpcs, VerifySpec: verifySpec})
+}
 
-	batch := &VerifyBatchTask{TaskTypes: database.TaskTypeVerifyState, VPCs: vpcs, VerifySpec: verifySpec}
+// SubmitBatchTask queues the requested task types on every VPC in the batch
+func (api *VPCConfAPI) SubmitBatchTask(batch *BatchTaskRequest) (*BatchTaskResult, error) {
+	batchTaskURL := api.BaseURL + "/batch"
 
 	buf := new(bytes.Buffer)
 	err := json.NewEncoder(buf).Encode(batch)
@@ -222,12 +391,13 @@ func (api *VPCConfAPI) SubmitVerifyBatchTask(vpcs []VPC, verifySpec database.Ver
 		return nil, err
 	}
 
-	log.Printf("Create verify batch task for %q", verifyTaskURL)
+	log.Printf("Create batch task for %q", batchTaskURL)
 
-	req, err := http.NewRequest("POST", verifyTaskURL, buf)
+	req, err := http.NewRequest("POST", batchTaskURL, buf)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to create request for %q - %s", verifyTaskURL, err)
+		return nil, fmt.Errorf("Failed to create request for %q - %s", batchTaskURL, err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
 	result := &BatchTaskResult{}
 
@@ -277,20 +447,20 @@ func isPrefixListID(dest string) bool {
 	return strings.HasPrefix(dest, "pl-")
 }
 
+// GetTGWTemplates returns every managed transit gateway attachment template
+func (api *VPCConfAPI) GetTGWTemplates() ([]*TGWTemplate, error) {
+	templates := []*TGWTemplate{}
+	err := api.getCached("/mtgas.json", &templates)
+	return templates, err
+}
+
 // GetTGWTemplate returns the template for the given name or an error
 func (api *VPCConfAPI) GetTGWTemplate(targetTemplateName string) (*TGWTemplate, error) {
 	mtgasURL := api.BaseURL + "/mtgas.json"
 
 	log.Printf("Fetch transit gateway template '%s' from %s", targetTemplateName, mtgasURL)
 
-	req, err := http.NewRequest("GET", mtgasURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to create request for %s - %s", mtgasURL, err)
-	}
-
-	templates := []*TGWTemplate{}
-
-	err = api.doRequest(req, &templates)
+	templates, err := api.GetTGWTemplates()
 	if err != nil {
 		return nil, err
 	}
@@ -374,6 +544,118 @@ func (api *VPCConfAPI) allowWorkers(allow *string, allowAll bool) error {
 	return api.doRequest(req, nil)
 }
 
+// VPCLabel is a label on an automated VPC
+type VPCLabel struct {
+	Region database.Region
+	ID     string
+	Label  string
+}
+
+// GetBatchVPCLabels returns the labels of every automated VPC
+func (api *VPCConfAPI) GetBatchVPCLabels() ([]*VPCLabel, error) {
+	labelsURL := api.BaseURL + "/batch/labels.json"
+
+	req, err := http.NewRequest("GET", labelsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %s - %s", labelsURL, err)
+	}
+
+	labels := []*VPCLabel{}
+
+	err = api.doRequest(req, &labels)
+
+	return labels, err
+}
+
+// GetVPCSelectors returns the saved VPC selectors
+func (api *VPCConfAPI) GetVPCSelectors() ([]*database.VPCSelector, error) {
+	selectorsURL := api.BaseURL + "/batch/selectors.json"
+
+	req, err := http.NewRequest("GET", selectorsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %s - %s", selectorsURL, err)
+	}
+
+	selectors := []*database.VPCSelector{}
+
+	err = api.doRequest(req, &selectors)
+
+	return selectors, err
+}
+
+// SaveVPCSelector creates or replaces the saved VPC selector with the same name
+func (api *VPCConfAPI) SaveVPCSelector(selector *database.VPCSelector) error {
+	selectorsURL := api.BaseURL + "/batch/selectors/"
+
+	buf := new(bytes.Buffer)
+	err := json.NewEncoder(buf).Encode(selector)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", selectorsURL, buf)
+	if err != nil {
+		return fmt.Errorf("Failed to create request for %s - %s", selectorsURL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return api.doRequest(req, nil)
+}
+
+// DeleteVPCSelector deletes the saved VPC selector with the given name
+func (api *VPCConfAPI) DeleteVPCSelector(name string) error {
+	selectorURL := api.BaseURL + "/batch/selectors/" + url.PathEscape(name)
+
+	req, err := http.NewRequest("DELETE", selectorURL, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to create request for %s - %s", selectorURL, err)
+	}
+
+	return api.doRequest(req, nil)
+}
+
+// SelectVPCs returns the automated VPCs matched by either the saved selector
+// with the given name or the given selector expression
+func (api *VPCConfAPI) SelectVPCs(name, expression string) (*database.BatchTaskSelector, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	if expression != "" {
+		query.Set("selector", expression)
+	}
+	selectURL := api.BaseURL + "/batch/select.json?" + query.Encode()
+
+	req, err := http.NewRequest("GET", selectURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %s - %s", selectURL, err)
+	}
+
+	resolved := &database.BatchTaskSelector{}
+
+	err = api.doRequest(req, resolved)
+
+	return resolved, err
+}
+
+// GetVPCIssues returns the issues found by the VPC's last verify or repair
+func (api *VPCConfAPI) GetVPCIssues(vpc VPC) ([]*database.Issue, error) {
+	issuesURL := fmt.Sprintf("%s/accounts/%s/vpc/%s/%s.json?dbOnly", api.BaseURL, vpc.AccountID, vpc.Region, vpc.ID)
+
+	req, err := http.NewRequest("GET", issuesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request for %s - %s", issuesURL, err)
+	}
+
+	info := &struct {
+		Issues []*database.Issue
+	}{}
+
+	err = api.doRequest(req, info)
+
+	return info.Issues, err
+}
+
 // GetExceptionVPCs returns the VPCs not managed by VPC Conf
 func (api *VPCConfAPI) GetExceptionVPCs() ([]VPC, error) {
 	exceptionsURL := api.BaseURL + "/exception.json"
@@ -1,7 +1,18 @@
 package vpcconfapi
 
 import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/CMSgov/CMS-AWS-West-Network-Architecture/vpc-automation/database"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestBatchTaskProgress(t *testing.T) {
@@ -46,3 +57,147 @@ func TestBatchTaskProgress(t *testing.T) {
 		t.Errorf("Expected there be 7 remaining statuses, but got %d", progress.Remaining())
 	}
 }
+
+func TestParseTaskTypes(t *testing.T) {
+	taskTypes, err := ParseTaskTypes("networking, security-groups")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if taskTypes != database.TaskTypeNetworking|database.TaskTypeSecurityGroups {
+		t.Errorf("Expected networking and security groups but got %d", taskTypes)
+	}
+	if s := FormatTaskTypes(taskTypes); s != "networking,security-groups" {
+		t.Errorf("Expected networking,security-groups but got %q", s)
+	}
+	_, err = ParseTaskTypes("networking,bogus")
+	if err == nil {
+		t.Errorf("Expected an error for an invalid task type")
+	}
+}
+
+func TestParseVerifySpec(t *testing.T) {
+	spec, err := ParseVerifySpec("networking,cidrs")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(database.VerifySpec{VerifyNetworking: true, VerifyCIDRs: true}, spec); diff != "" {
+		t.Errorf("Expected spec did not match actual: \n%s", diff)
+	}
+	if s := FormatVerifySpec(spec); s != "cidrs,networking" {
+		t.Errorf("Expected cidrs,networking but got %q", s)
+	}
+	spec, err = ParseVerifySpec("all")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if s := FormatVerifySpec(spec); s != "all" {
+		t.Errorf("Expected all but got %q", s)
+	}
+	_, err = ParseVerifySpec("bogus")
+	if err == nil {
+		t.Errorf("Expected an error for an invalid verify type")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vpcconfapi-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := &FileCache{Dir: filepath.Join(dir, "dev")}
+
+	vpcs := []VPC{{ID: "vpc-1", Region: "us-east-1"}}
+	var got []VPC
+	if cache.Get("https://example.com/batch/vpcs.json", &got) {
+		t.Fatalf("Expected nothing cached yet")
+	}
+	err = cache.Set("https://example.com/batch/vpcs.json", vpcs, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !cache.Get("https://example.com/batch/vpcs.json", &got) {
+		t.Fatalf("Expected cached value")
+	}
+	if diff := cmp.Diff(vpcs, got); diff != "" {
+		t.Errorf("Expected cached value did not match actual: \n%s", diff)
+	}
+
+	err = cache.Set("expired", vpcs, -time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if cache.Get("expired", &got) {
+		t.Errorf("Expected expired entry to be ignored")
+	}
+
+	err = cache.Clear()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if cache.Get("https://example.com/batch/vpcs.json", &got) {
+		t.Errorf("Expected cache to be cleared")
+	}
+}
+
+func TestFindSupernets(t *testing.T) {
+	routes := []*database.SnapshotRoute{
+		{RouteInfo: database.RouteInfo{Destination: "10.128.0.0/16", TransitGatewayID: "tgw-1"}, Managed: true},
+		{RouteInfo: database.RouteInfo{Destination: "10.0.0.0/8", PeeringConnectionID: "pcx-1"}},
+		{RouteInfo: database.RouteInfo{Destination: "10.128.0.0/16", TransitGatewayID: "tgw-2"}},
+		{RouteInfo: database.RouteInfo{Destination: "0.0.0.0/0", NATGatewayID: "nat-1"}},
+		{RouteInfo: database.RouteInfo{Destination: "pl-123", TransitGatewayID: "tgw-1"}},
+		{RouteInfo: database.RouteInfo{Destination: "172.16.0.0/12", TransitGatewayID: "tgw-1"}},
+	}
+	supernets, err := FindSupernets(routes, []string{"10.128.0.0/16"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([]*database.SnapshotRoute{routes[1]}, supernets); diff != "" {
+		t.Errorf("Expected supernets did not match actual: \n%s", diff)
+	}
+
+	_, err = FindSupernets(routes, []string{"not a cidr"})
+	if err == nil {
+		t.Errorf("Expected an error for an invalid CIDR")
+	}
+}
+
+func TestTailTask(t *testing.T) {
+	logs := []*database.LogEntry{
+		{Time: time.Unix(1, 0).UTC(), Message: "Starting"},
+		{Time: time.Unix(2, 0).UTC(), Message: "Working"},
+		{Time: time.Unix(3, 0).UTC(), Message: "Done"},
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/task/42.json" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		requests++
+		// The second poll fails to read the log
+		reads := []int{1, 0, 2, 3}
+		task := &TaskDetails{ID: 42, Status: database.TaskStatusInProgress, Log: logs[:reads[requests-1]]}
+		if requests == len(reads) {
+			task.Status = database.TaskStatusSuccessful
+		}
+		json.NewEncoder(w).Encode(task)
+	}))
+	defer server.Close()
+
+	api := &VPCConfAPI{APIKey: "key", BaseURL: server.URL}
+	seen := []*database.LogEntry{}
+	task, err := api.TailTask(context.Background(), 42, time.Millisecond, func(entry *database.LogEntry) {
+		seen = append(seen, entry)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if task.Status != database.TaskStatusSuccessful {
+		t.Errorf("Expected task to be successful but got %s", task.Status)
+	}
+	if diff := cmp.Diff(logs, seen); diff != "" {
+		t.Errorf("Expected each log entry once: \n%s", diff)
+	}
+}